@@ -5,9 +5,15 @@
 //
 // 组件边界：
 //   - 提供双 JWT 令牌模型，不依赖外部存储。
-//   - GinMiddleware 只接受 access token。
+//   - GinMiddleware 只接受 access token；JWT 放在 cookie 时可开启 double-submit CSRF 校验。
 //   - RefreshToken 只接受 refresh token，并返回一对新的 token。
-//   - 不提供 token 撤销、会话管理、黑名单、重放检测、OAuth2/OIDC 能力。
+//   - 可选的验证结果缓存（ValidationCacheTTL），命中时跳过验签。
+//   - 可选的设备/会话绑定（WithDeviceBinding），token 只能在签发时的设备上使用。
+//   - 密钥可通过 ReloadKeys / WatchKeys 热加载，被移出的旧密钥保留宽限期用于验证。
+//   - refresh token 可通过 Config.Refresh 使用独立的签名方法（HS256 / RS256）与密钥。
+//   - InjectClaims / ClaimsFromMetadata 通过带签名的 gRPC metadata 把已验证的 claims 透传给下游。
+//   - 客户端辅助 TokenManager 缓存 access token，临近过期时自动调用刷新函数。
+//   - Revoke 只在当前进程内生效，不提供分布式撤销、会话管理、重放检测、OAuth2/OIDC 能力。
 //
 // 典型用法：
 //
@@ -26,9 +32,12 @@ import (
 	"net/http"
 	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/config"
 	"github.com/ceyewan/genesis/metrics"
 	"github.com/ceyewan/genesis/xerrors"
 
@@ -60,13 +69,45 @@ type Authenticator interface {
 	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
 
 	// GinMiddleware 返回 Gin 认证中间件。
-	GinMiddleware() gin.HandlerFunc
+	//
+	// 可通过 WithCSRFProtection 开启 double-submit CSRF 校验。
+	GinMiddleware(opts ...MiddlewareOption) gin.HandlerFunc
+
+	// IssueCSRFToken 生成随机 CSRF token。
+	//
+	// 调用方将其写入 CSRFCookieName cookie（不能设置 HttpOnly，前端需要读取），
+	// 前端在非安全请求中通过 CSRFHeaderName 请求头回传。
+	IssueCSRFToken() (string, error)
+
+	// ReloadKeys 热加载新的签名密钥列表，是运行期更换密钥的唯一入口。
+	//
+	// 新 token 使用 Active 密钥签发。当前密钥集合中不在 keys 里的旧密钥（包括单密钥模式的 SecretKey）
+	// 不会立即失效，而是降为仅验证并保留 Config.KeyGracePeriod，期间仍可验证它签发的 token；
+	// 需要立即作废某个旧密钥时，将它保留在 keys 中并把 ExpiresAt 设为过去的时间。
+	ReloadKeys(keys []KeyEntry) error
+
+	// WatchKeys 监听配置中 key 对应的密钥列表，变化时自动调用 ReloadKeys。
+	//
+	// loader 必须已成功 Load；监听在 ctx 取消时结束。热加载失败只记录日志，保留当前密钥。
+	WatchKeys(ctx context.Context, loader config.Loader, key string) error
+
+	// Revoke 在当前进程内撤销 token，并使其验证缓存失效。
+	//
+	// 撤销记录保留到 token 过期，之后再验证该 token 返回 ErrRevokedToken。
+	// 多实例部署时需要在每个实例上调用，组件不提供分布式黑名单。
+	Revoke(ctx context.Context, token string) error
 }
 
 // jwtAuth JWT 认证实现。
 type jwtAuth struct {
 	config         *Config
 	options        *options
+	keys           atomic.Pointer[keyring]
+	keysMu         sync.Mutex    // 串行化密钥更新，避免并发 Reload 丢失保留的旧密钥
+	refresh        *staticSigner // refresh token 独立签名器，nil 表示与 access 共用 keyring
+	cache          *validationCache
+	revoked        *revocationList
+	verify         func(tokenString string, claims *Claims) (*jwt.Token, error) // 验签入口，默认 parseToken
 	validatedCount metrics.Counter
 	refreshedCount metrics.Counter
 }
@@ -87,11 +128,24 @@ func New(cfg *Config, opts ...Option) (Authenticator, error) {
 	auth := &jwtAuth{
 		config:  cfg,
 		options: o,
+		revoked: newRevocationList(),
 	}
 
 	if err := auth.config.validate(); err != nil {
 		return nil, err
 	}
+	if cfg.Refresh != nil {
+		signer, err := newStaticSigner(cfg.Refresh)
+		if err != nil {
+			return nil, err
+		}
+		auth.refresh = signer
+	}
+	auth.keys.Store(newKeyring(cfg))
+	auth.verify = auth.parseToken
+	if cfg.ValidationCacheTTL > 0 {
+		auth.cache = newValidationCache(cfg.ValidationCacheTTL, cfg.ValidationCacheSize)
+	}
 
 	auth.validatedCount = auth.initCounter(
 		MetricTokensValidated,
@@ -126,6 +180,9 @@ func (a *jwtAuth) GenerateTokenPair(ctx context.Context, claims *Claims) (*Token
 		return nil, ErrInvalidClaims
 	}
 
+	claims = cloneClaims(claims)
+	a.bindClaims(ctx, claims)
+
 	now := time.Now()
 	accessClaims := cloneClaims(claims)
 	accessClaims.TokenType = TokenTypeAccess
@@ -184,8 +241,20 @@ func (a *jwtAuth) signClaims(claims *Claims) (string, error) {
 		return "", ErrInvalidConfig
 	}
 
+	if claims.TokenType == TokenTypeRefresh && a.refresh != nil {
+		tokenString, err := jwt.NewWithClaims(a.refresh.method, claims).SignedString(a.refresh.signKey)
+		if err != nil {
+			return "", xerrors.Wrap(err, "failed to sign token")
+		}
+		return tokenString, nil
+	}
+
+	kr := a.keys.Load()
 	token := jwt.NewWithClaims(method, claims)
-	tokenString, err := token.SignedString([]byte(a.config.SecretKey))
+	if kr.activeID != "" {
+		token.Header["kid"] = kr.activeID
+	}
+	tokenString, err := token.SignedString(kr.active)
 	if err != nil {
 		return "", xerrors.Wrap(err, "failed to sign token")
 	}
@@ -203,8 +272,25 @@ func (a *jwtAuth) ValidateRefreshToken(ctx context.Context, tokenString string)
 }
 
 func (a *jwtAuth) validateTypedToken(ctx context.Context, tokenString string, expected TokenType) (*Claims, error) {
+	key := digestToken(tokenString)
+	if a.revoked.contains(key) {
+		a.validatedCount.Add(ctx, 1, metrics.L("status", "error"), metrics.L("error_type", "revoked"))
+		return nil, ErrRevokedToken
+	}
+
+	if a.cache != nil {
+		if cached, ok := a.cache.get(key, time.Now()); ok && cached.TokenType == expected {
+			if err := a.verifyBinding(ctx, cached); err != nil {
+				a.validatedCount.Add(ctx, 1, metrics.L("status", "error"), metrics.L("error_type", "binding_mismatch"))
+				return nil, err
+			}
+			a.validatedCount.Add(ctx, 1, metrics.L("status", "success"))
+			return cloneClaims(cached), nil
+		}
+	}
+
 	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, a.keyFunc(), a.validationParserOptions()...)
+	token, err := a.verify(tokenString, claims)
 	if err != nil {
 		var errType string
 		if xerrors.Is(err, jwt.ErrTokenExpired) {
@@ -227,15 +313,64 @@ func (a *jwtAuth) validateTypedToken(ctx context.Context, tokenString string, ex
 		return nil, ErrInvalidToken
 	}
 
+	if err := a.verifyAudience(claims); err != nil {
+		a.validatedCount.Add(ctx, 1, metrics.L("status", "error"), metrics.L("error_type", "invalid_audience"))
+		return nil, err
+	}
+
+	if err := a.verifyBinding(ctx, claims); err != nil {
+		a.validatedCount.Add(ctx, 1, metrics.L("status", "error"), metrics.L("error_type", "binding_mismatch"))
+		a.options.logger.Warn("token binding mismatch",
+			clog.String("user_id", claims.Subject),
+			clog.String("token_type", string(claims.TokenType)),
+		)
+		return nil, err
+	}
+
 	a.options.logger.Info("token validated",
 		clog.String("user_id", claims.Subject),
 		clog.String("token_type", string(claims.TokenType)),
 	)
 
+	if a.cache != nil {
+		a.cache.put(key, claims, time.Now())
+	}
+
 	a.validatedCount.Add(ctx, 1, metrics.L("status", "success"))
 	return claims, nil
 }
 
+// parseToken 验签并解析 token，验证缓存命中时不会调用。
+func (a *jwtAuth) parseToken(tokenString string, claims *Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, a.keyFunc(), a.validationParserOptions()...)
+}
+
+// Revoke 在当前进程内撤销 token。
+func (a *jwtAuth) Revoke(ctx context.Context, tokenString string) error {
+	claims, err := a.parseClaimsWithoutTimeValidation(tokenString)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(a.config.RefreshTokenTTL)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+
+	key := digestToken(tokenString)
+	a.revoked.add(key, expiresAt, now)
+	if a.cache != nil {
+		a.cache.remove(key)
+	}
+
+	a.options.logger.InfoContext(ctx, "token revoked",
+		clog.String("user_id", claims.Subject),
+		clog.String("token_type", string(claims.TokenType)),
+	)
+	return nil
+}
+
 // RefreshToken 使用 refresh token 换发新双令牌。
 func (a *jwtAuth) RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error) {
 	claims, err := a.ValidateRefreshToken(ctx, refreshToken)
@@ -330,22 +465,103 @@ func (a *jwtAuth) extractFromSource(r *http.Request, source, key string) (string
 const ClaimsKey = "auth:claims"
 
 func (a *jwtAuth) validationParserOptions() []jwt.ParserOption {
+	methods := []string{a.config.SigningMethod}
+	if a.refresh != nil && a.refresh.method.Alg() != a.config.SigningMethod {
+		methods = append(methods, a.refresh.method.Alg())
+	}
 	opts := []jwt.ParserOption{
-		jwt.WithValidMethods([]string{a.config.SigningMethod}),
+		jwt.WithValidMethods(methods),
 	}
 	if a.config.Issuer != "" {
 		opts = append(opts, jwt.WithIssuer(a.config.Issuer))
 	}
-	if len(a.config.Audience) > 0 {
-		opts = append(opts, jwt.WithAudience(a.config.Audience...))
-	}
+	// audience 由 verifyAudience 单独校验，以便返回明确错误并支持宽松模式
 	return opts
 }
 
+// verifyAudience 校验 token 的 aud 是否包含本服务的任一 Audience。
+func (a *jwtAuth) verifyAudience(claims *Claims) error {
+	if len(a.config.Audience) == 0 {
+		return nil
+	}
+	if len(claims.Audience) == 0 {
+		if a.config.AllowMissingAudience {
+			return nil
+		}
+		return xerrors.Wrap(ErrInvalidAudience, "token has no aud claim")
+	}
+	if !hasAnyAudience(claims.Audience, a.config.Audience) {
+		return xerrors.Wrapf(ErrInvalidAudience, "aud %v does not match %v", []string(claims.Audience), a.config.Audience)
+	}
+	return nil
+}
+
+// keyFunc 按 token 类型选择验证密钥。
+//
+// 配置了独立 refresh 签名时，refresh token 只用 refresh 密钥验证，其余 token 只用 access keyring；
+// 验签通过后 validateTypedToken 仍会校验 token 类型，篡改 typ 的 token 会因签名不匹配被拒绝。
 func (a *jwtAuth) keyFunc() jwt.Keyfunc {
+	kr := a.keys.Load()
 	return func(token *jwt.Token) (any, error) {
-		return []byte(a.config.SecretKey), nil
+		if a.refresh != nil {
+			if claims, ok := token.Claims.(*Claims); ok && claims.TokenType == TokenTypeRefresh {
+				return a.refresh.lookup(token)
+			}
+			if token.Method.Alg() != a.config.SigningMethod {
+				return nil, xerrors.Wrapf(ErrInvalidToken, "unexpected signing method: %s", token.Method.Alg())
+			}
+		}
+		return kr.lookup(token, time.Now())
+	}
+}
+
+// ReloadKeys 热加载签名密钥列表，被移出的旧密钥保留宽限期。
+func (a *jwtAuth) ReloadKeys(keys []KeyEntry) error {
+	if err := validateKeys(keys); err != nil {
+		return err
+	}
+
+	a.keysMu.Lock()
+	defer a.keysMu.Unlock()
+
+	merged := retainRetired(a.keys.Load(), keys, time.Now(), a.config.KeyGracePeriod)
+	kr := newKeyring(&Config{SecretKeys: merged})
+	a.keys.Store(kr)
+	// 被移除或过期的密钥签发的 token 不应再通过缓存放行
+	if a.cache != nil {
+		a.cache.purge()
 	}
+	a.options.logger.Info("signing keys reloaded",
+		clog.String("active_kid", kr.activeID),
+		clog.Int("key_count", len(merged)),
+	)
+	return nil
+}
+
+// WatchKeys 监听配置变化并自动热加载密钥。
+func (a *jwtAuth) WatchKeys(ctx context.Context, loader config.Loader, key string) error {
+	if loader == nil || key == "" {
+		return xerrors.Wrapf(ErrInvalidConfig, "watch keys: loader and key are required")
+	}
+
+	ch, err := loader.Watch(ctx, key)
+	if err != nil {
+		return xerrors.Wrapf(err, "watch keys: %s", key)
+	}
+
+	go func() {
+		for range ch {
+			var keys []KeyEntry
+			if err := loader.UnmarshalKey(key, &keys); err != nil {
+				a.options.logger.Warn("decode signing keys failed", clog.String("key", key), clog.Error(err))
+				continue
+			}
+			if err := a.ReloadKeys(keys); err != nil {
+				a.options.logger.Warn("reload signing keys failed", clog.String("key", key), clog.Error(err))
+			}
+		}
+	}()
+	return nil
 }
 
 func (a *jwtAuth) parseClaimsWithoutTimeValidation(tokenString string) (*Claims, error) {
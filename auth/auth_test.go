@@ -2,8 +2,15 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -13,6 +20,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/config"
 	"github.com/ceyewan/genesis/metrics"
 )
 
@@ -478,6 +486,61 @@ func TestGinMiddleware_InvalidToken(t *testing.T) {
 	assert.JSONEq(t, `{"error":"unauthorized"}`, w.Body.String())
 }
 
+func TestIssueCSRFToken(t *testing.T) {
+	auth := createTestAuthenticator(t)
+
+	first, err := auth.IssueCSRFToken()
+	require.NoError(t, err)
+	second, err := auth.IssueCSRFToken()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 43)
+	assert.NotEqual(t, first, second)
+}
+
+func TestGinMiddleware_CSRFProtection(t *testing.T) {
+	auth := createTestAuthenticator(t)
+	pair := createTokenPair(t, auth, context.Background())
+	csrf, err := auth.IssueCSRFToken()
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(auth.GinMiddleware(WithCSRFProtection()))
+	router.GET("/orders", func(c *gin.Context) { c.JSON(200, gin.H{"status": "ok"}) })
+	router.POST("/orders", func(c *gin.Context) { c.JSON(200, gin.H{"status": "ok"}) })
+
+	newRequest := func(method, header, cookie string) *http.Request {
+		req := httptest.NewRequest(method, "/orders", nil)
+		req.AddCookie(&http.Cookie{Name: "jwt", Value: pair.AccessToken})
+		if cookie != "" {
+			req.AddCookie(&http.Cookie{Name: CSRFCookieName, Value: cookie})
+		}
+		if header != "" {
+			req.Header.Set(CSRFHeaderName, header)
+		}
+		return req
+	}
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"POST 缺少 CSRF token", newRequest("POST", "", ""), 403},
+		{"POST 只有 cookie", newRequest("POST", "", csrf), 403},
+		{"POST header 与 cookie 不一致", newRequest("POST", "other", csrf), 403},
+		{"POST header 与 cookie 一致", newRequest("POST", csrf, csrf), 200},
+		{"GET 不校验", newRequest("GET", "", ""), 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.req)
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
 func TestRequireRoles(t *testing.T) {
 	router := gin.New()
 	router.Use(func(c *gin.Context) {
@@ -534,6 +597,690 @@ func TestGetClaims_TypeMismatch(t *testing.T) {
 	assert.Nil(t, claims)
 }
 
+func TestAuthenticator_KeyRotation(t *testing.T) {
+	const (
+		secretA = "secret-a-this-is-a-valid-secret-key-32"
+		secretB = "secret-b-this-is-a-valid-secret-key-32"
+	)
+	ctx := context.Background()
+
+	authenticator, err := New(&Config{
+		SecretKeys: []KeyEntry{{ID: "a", Secret: secretA, Active: true}},
+	}, WithLogger(clog.Discard()), WithMeter(metrics.Discard()))
+	require.NoError(t, err)
+
+	pairA := createTokenPair(t, authenticator, ctx)
+	require.Equal(t, "a", tokenKID(t, pairA.AccessToken))
+
+	// 轮换到 B，A 保留用于验证
+	require.NoError(t, authenticator.ReloadKeys([]KeyEntry{
+		{ID: "a", Secret: secretA},
+		{ID: "b", Secret: secretB, Active: true},
+	}))
+
+	claims, err := authenticator.ValidateAccessToken(ctx, pairA.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, "user-123", claims.Subject)
+
+	pairB := createTokenPair(t, authenticator, ctx)
+	require.Equal(t, "b", tokenKID(t, pairB.AccessToken))
+	_, err = authenticator.ValidateAccessToken(ctx, pairB.AccessToken)
+	require.NoError(t, err)
+
+	// 立即作废 A
+	require.NoError(t, authenticator.ReloadKeys([]KeyEntry{
+		{ID: "a", Secret: secretA, ExpiresAt: time.Now().Add(-time.Second)},
+		{ID: "b", Secret: secretB, Active: true},
+	}))
+
+	_, err = authenticator.ValidateAccessToken(ctx, pairA.AccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = authenticator.ValidateAccessToken(ctx, pairB.AccessToken)
+	require.NoError(t, err)
+}
+
+func TestAuthenticator_KeyRotation_GracePeriodEnded(t *testing.T) {
+	const (
+		secretA = "secret-a-this-is-a-valid-secret-key-32"
+		secretB = "secret-b-this-is-a-valid-secret-key-32"
+	)
+	ctx := context.Background()
+
+	authenticator, err := New(&Config{
+		SecretKeys: []KeyEntry{{ID: "a", Secret: secretA, Active: true}},
+	})
+	require.NoError(t, err)
+	pairA := createTokenPair(t, authenticator, ctx)
+
+	require.NoError(t, authenticator.ReloadKeys([]KeyEntry{
+		{ID: "a", Secret: secretA, ExpiresAt: time.Now().Add(-time.Second)},
+		{ID: "b", Secret: secretB, Active: true},
+	}))
+
+	_, err = authenticator.ValidateAccessToken(ctx, pairA.AccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthenticator_ReloadKeys(t *testing.T) {
+	const (
+		secretA = "secret-a-this-is-a-valid-secret-key-32"
+		secretB = "secret-b-this-is-a-valid-secret-key-32"
+	)
+	ctx := context.Background()
+
+	authenticator, err := New(&Config{
+		SecretKeys:     []KeyEntry{{ID: "a", Secret: secretA, Active: true}},
+		KeyGracePeriod: time.Hour,
+	}, WithLogger(clog.Discard()), WithMeter(metrics.Discard()))
+	require.NoError(t, err)
+	pairA := createTokenPair(t, authenticator, ctx)
+
+	// 新列表只包含 B，A 自动保留宽限期
+	require.NoError(t, authenticator.ReloadKeys([]KeyEntry{
+		{ID: "b", Secret: secretB, Active: true},
+	}))
+
+	pairB := createTokenPair(t, authenticator, ctx)
+	require.Equal(t, "b", tokenKID(t, pairB.AccessToken))
+	_, err = authenticator.ValidateAccessToken(ctx, pairB.AccessToken)
+	require.NoError(t, err)
+
+	claims, err := authenticator.ValidateAccessToken(ctx, pairA.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, "user-123", claims.Subject)
+	_, err = authenticator.ValidateRefreshToken(ctx, pairA.RefreshToken)
+	require.NoError(t, err)
+
+	// 再次 Reload 不会延长 A 的宽限期
+	kr := authenticator.(*jwtAuth).keys.Load()
+	deadline := kr.keys["a"].ExpiresAt
+	require.False(t, kr.keys["a"].Active)
+	require.WithinDuration(t, time.Now().Add(time.Hour), deadline, time.Minute)
+	require.NoError(t, authenticator.ReloadKeys([]KeyEntry{
+		{ID: "b", Secret: secretB, Active: true},
+	}))
+	require.Equal(t, deadline, authenticator.(*jwtAuth).keys.Load().keys["a"].ExpiresAt)
+
+	// 显式设置过去的 ExpiresAt 后 A 立即失效
+	require.NoError(t, authenticator.ReloadKeys([]KeyEntry{
+		{ID: "a", Secret: secretA, ExpiresAt: time.Now().Add(-time.Second)},
+		{ID: "b", Secret: secretB, Active: true},
+	}))
+	_, err = authenticator.ValidateAccessToken(ctx, pairA.AccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	_, err = authenticator.ValidateAccessToken(ctx, pairB.AccessToken)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, authenticator.ReloadKeys(nil), ErrInvalidConfig)
+}
+
+func TestAuthenticator_ReloadKeys_GracePeriodEnded(t *testing.T) {
+	const (
+		secretA = "secret-a-this-is-a-valid-secret-key-32"
+		secretB = "secret-b-this-is-a-valid-secret-key-32"
+	)
+	ctx := context.Background()
+
+	authenticator, err := New(&Config{
+		SecretKeys:     []KeyEntry{{ID: "a", Secret: secretA, Active: true}},
+		KeyGracePeriod: 50 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	pairA := createTokenPair(t, authenticator, ctx)
+
+	require.NoError(t, authenticator.ReloadKeys([]KeyEntry{
+		{ID: "b", Secret: secretB, Active: true},
+	}))
+	_, err = authenticator.ValidateAccessToken(ctx, pairA.AccessToken)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	_, err = authenticator.ValidateAccessToken(ctx, pairA.AccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// 过期的旧密钥在下一次 Reload 时被丢弃
+	require.NoError(t, authenticator.ReloadKeys([]KeyEntry{
+		{ID: "b", Secret: secretB, Active: true},
+	}))
+	require.NotContains(t, authenticator.(*jwtAuth).keys.Load().keys, "a")
+}
+
+func TestAuthenticator_ReloadKeys_FromSecretKey(t *testing.T) {
+	const (
+		secret  = "this-is-a-valid-secret-key-at-least-32-chars"
+		secretB = "secret-b-this-is-a-valid-secret-key-32"
+	)
+	ctx := context.Background()
+
+	authenticator, err := New(&Config{
+		SecretKey:      secret,
+		KeyGracePeriod: 50 * time.Millisecond,
+	}, WithLogger(clog.Discard()), WithMeter(metrics.Discard()))
+	require.NoError(t, err)
+	pair := createTokenPair(t, authenticator, ctx)
+	require.Empty(t, tokenKID(t, pair.AccessToken))
+
+	require.NoError(t, authenticator.ReloadKeys([]KeyEntry{
+		{ID: "b", Secret: secretB, Active: true},
+	}))
+
+	// 单密钥模式签发的 token 不带 kid，宽限期内仍可验证
+	claims, err := authenticator.ValidateAccessToken(ctx, pair.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, "user-123", claims.Subject)
+
+	pairB := createTokenPair(t, authenticator, ctx)
+	require.Equal(t, "b", tokenKID(t, pairB.AccessToken))
+	_, err = authenticator.ValidateAccessToken(ctx, pairB.AccessToken)
+	require.NoError(t, err)
+
+	// 宽限期结束后不带 kid 的 token 只按当前签发密钥验证
+	time.Sleep(100 * time.Millisecond)
+	_, err = authenticator.ValidateAccessToken(ctx, pair.AccessToken)
+	require.ErrorIs(t, err, ErrInvalidSignature)
+
+	// 过期的旧 SecretKey 在下一次 Reload 时被丢弃
+	require.NoError(t, authenticator.ReloadKeys([]KeyEntry{
+		{ID: "b", Secret: secretB, Active: true},
+	}))
+	require.NotContains(t, authenticator.(*jwtAuth).keys.Load().keys, legacyKeyID)
+}
+
+func TestAuthenticator_WatchKeys(t *testing.T) {
+	const (
+		secretA = "secret-a-this-is-a-valid-secret-key-32"
+		secretB = "secret-b-this-is-a-valid-secret-key-32"
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "auth.yaml")
+	writeKeys := func(id, secret string) {
+		content := fmt.Sprintf("auth:\n  secret_keys:\n    - id: %s\n      secret: %s\n      active: true\n", id, secret)
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o644))
+	}
+	writeKeys("a", secretA)
+
+	loader, err := config.New(&config.Config{Name: "auth", Paths: []string{dir}, FileType: "yaml"})
+	require.NoError(t, err)
+	require.NoError(t, loader.Load(ctx))
+
+	var cfg Config
+	require.NoError(t, loader.UnmarshalKey("auth", &cfg))
+	authenticator, err := New(&cfg)
+	require.NoError(t, err)
+	pairA := createTokenPair(t, authenticator, ctx)
+
+	require.NoError(t, authenticator.WatchKeys(ctx, loader, "auth.secret_keys"))
+	writeKeys("b", secretB)
+
+	require.Eventually(t, func() bool {
+		pair, err := authenticator.GenerateTokenPair(ctx, &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: "user-123"},
+		})
+		return err == nil && tokenKID(t, pair.AccessToken) == "b"
+	}, 5*time.Second, 50*time.Millisecond)
+
+	_, err = authenticator.ValidateAccessToken(ctx, pairA.AccessToken)
+	require.NoError(t, err, "旧密钥在宽限期内仍可验证")
+
+	require.Error(t, authenticator.WatchKeys(ctx, nil, "auth.secret_keys"))
+}
+
+func TestAuthenticator_ReloadKeys_Invalid(t *testing.T) {
+	authenticator := createTestAuthenticator(t)
+	const secret = "this-is-a-valid-secret-key-at-least-32-chars"
+
+	tests := []struct {
+		name string
+		keys []KeyEntry
+	}{
+		{name: "empty", keys: nil},
+		{name: "no active key", keys: []KeyEntry{{ID: "a", Secret: secret}}},
+		{name: "multiple active keys", keys: []KeyEntry{
+			{ID: "a", Secret: secret, Active: true},
+			{ID: "b", Secret: secret, Active: true},
+		}},
+		{name: "duplicate id", keys: []KeyEntry{
+			{ID: "a", Secret: secret, Active: true},
+			{ID: "a", Secret: secret},
+		}},
+		{name: "missing id", keys: []KeyEntry{{Secret: secret, Active: true}}},
+		{name: "secret too short", keys: []KeyEntry{{ID: "a", Secret: "short", Active: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorIs(t, authenticator.ReloadKeys(tt.keys), ErrInvalidConfig)
+		})
+	}
+}
+
+func TestAuthenticator_Audience(t *testing.T) {
+	ctx := context.Background()
+	const secret = "this-is-a-valid-secret-key-at-least-32-chars"
+	newAuth := func(audience []string, allowMissing bool) Authenticator {
+		a, err := New(&Config{
+			SecretKey:            secret,
+			Audience:             audience,
+			AllowMissingAudience: allowMissing,
+		}, WithLogger(clog.Discard()), WithMeter(metrics.Discard()))
+		require.NoError(t, err)
+		return a
+	}
+
+	serviceA := newAuth([]string{"service-a"}, false)
+	serviceB := newAuth([]string{"service-b"}, false)
+
+	pair, err := serviceA.GenerateTokenPair(ctx, &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "user-123"},
+	})
+	require.NoError(t, err)
+
+	t.Run("matching audience passes", func(t *testing.T) {
+		claims, err := serviceA.ValidateAccessToken(ctx, pair.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, jwt.ClaimStrings{"service-a"}, claims.Audience)
+	})
+
+	t.Run("token for another service is rejected", func(t *testing.T) {
+		_, err := serviceB.ValidateAccessToken(ctx, pair.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidAudience)
+	})
+
+	t.Run("multiple audiences", func(t *testing.T) {
+		multi, err := serviceA.GenerateTokenPair(ctx, &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:  "user-123",
+				Audience: jwt.ClaimStrings{"service-a", "service-b"},
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = serviceB.ValidateAccessToken(ctx, multi.AccessToken)
+		require.NoError(t, err)
+
+		gateway := newAuth([]string{"gateway", "service-b"}, false)
+		_, err = gateway.ValidateAccessToken(ctx, pair.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidAudience)
+	})
+
+	t.Run("missing aud strict and lenient", func(t *testing.T) {
+		noAud, err := newAuth(nil, false).GenerateTokenPair(ctx, &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: "user-123"},
+		})
+		require.NoError(t, err)
+
+		_, err = serviceA.ValidateAccessToken(ctx, noAud.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidAudience)
+
+		_, err = newAuth([]string{"service-a"}, true).ValidateAccessToken(ctx, noAud.AccessToken)
+		require.NoError(t, err)
+	})
+}
+
+func TestAuthenticator_ValidationCache(t *testing.T) {
+	ctx := context.Background()
+
+	newCachedAuth := func(t *testing.T, ttl time.Duration) (*jwtAuth, *int) {
+		t.Helper()
+		a, err := New(&Config{
+			SecretKey:          "this-is-a-valid-secret-key-at-least-32-chars",
+			ValidationCacheTTL: ttl,
+		}, WithLogger(clog.Discard()), WithMeter(metrics.Discard()))
+		require.NoError(t, err)
+
+		impl := a.(*jwtAuth)
+		calls := 0
+		verify := impl.verify
+		impl.verify = func(tokenString string, claims *Claims) (*jwt.Token, error) {
+			calls++
+			return verify(tokenString, claims)
+		}
+		return impl, &calls
+	}
+
+	t.Run("同一 token 第二次验证走缓存", func(t *testing.T) {
+		a, calls := newCachedAuth(t, time.Minute)
+		pair := createTokenPair(t, a, ctx)
+
+		first, err := a.ValidateAccessToken(ctx, pair.AccessToken)
+		require.NoError(t, err)
+		require.Equal(t, 1, *calls)
+
+		second, err := a.ValidateAccessToken(ctx, pair.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, 1, *calls, "cache hit should skip signature verification")
+		assert.Equal(t, first.Subject, second.Subject)
+		assert.Equal(t, first.Roles, second.Roles)
+
+		// 返回副本，调用方修改不影响缓存
+		second.Roles[0] = "guest"
+		third, err := a.ValidateAccessToken(ctx, pair.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"admin"}, third.Roles)
+	})
+
+	t.Run("缓存不跨 token 类型", func(t *testing.T) {
+		a, _ := newCachedAuth(t, time.Minute)
+		pair := createTokenPair(t, a, ctx)
+
+		_, err := a.ValidateAccessToken(ctx, pair.AccessToken)
+		require.NoError(t, err)
+		_, err = a.ValidateRefreshToken(ctx, pair.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("缓存过期后重新验签", func(t *testing.T) {
+		a, calls := newCachedAuth(t, 50*time.Millisecond)
+		pair := createTokenPair(t, a, ctx)
+
+		_, err := a.ValidateAccessToken(ctx, pair.AccessToken)
+		require.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = a.ValidateAccessToken(ctx, pair.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("缓存时长不超过 token 剩余有效期", func(t *testing.T) {
+		a, calls := newCachedAuth(t, time.Minute)
+		token := signTestClaims(t, a, &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-123",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Second)),
+			},
+			TokenType: TokenTypeAccess,
+		})
+
+		_, err := a.ValidateAccessToken(ctx, token)
+		require.NoError(t, err)
+		time.Sleep(1100 * time.Millisecond)
+
+		_, err = a.ValidateAccessToken(ctx, token)
+		assert.ErrorIs(t, err, ErrExpiredToken)
+		assert.Equal(t, 2, *calls)
+	})
+
+	t.Run("Revoke 后缓存失效", func(t *testing.T) {
+		a, calls := newCachedAuth(t, time.Minute)
+		pair := createTokenPair(t, a, ctx)
+
+		_, err := a.ValidateAccessToken(ctx, pair.AccessToken)
+		require.NoError(t, err)
+		require.NoError(t, a.Revoke(ctx, pair.AccessToken))
+
+		_, err = a.ValidateAccessToken(ctx, pair.AccessToken)
+		assert.ErrorIs(t, err, ErrRevokedToken)
+		assert.Equal(t, 1, *calls)
+
+		// 被撤销的 refresh token 不能换发
+		require.NoError(t, a.Revoke(ctx, pair.RefreshToken))
+		_, err = a.RefreshToken(ctx, pair.RefreshToken)
+		assert.ErrorIs(t, err, ErrRevokedToken)
+
+		assert.ErrorIs(t, a.Revoke(ctx, "invalid.token.string"), ErrInvalidToken)
+	})
+
+	t.Run("ReloadKeys 清空缓存", func(t *testing.T) {
+		a, calls := newCachedAuth(t, time.Minute)
+		pair := createTokenPair(t, a, ctx)
+
+		_, err := a.ValidateAccessToken(ctx, pair.AccessToken)
+		require.NoError(t, err)
+		require.NoError(t, a.ReloadKeys([]KeyEntry{
+			{ID: "b", Secret: "secret-b-this-is-a-valid-secret-key-32", Active: true},
+		}))
+
+		// 旧 SecretKey 仍在宽限期内，token 重新验签后通过
+		_, err = a.ValidateAccessToken(ctx, pair.AccessToken)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, *calls)
+	})
+}
+
+func TestValidationCache_MaxSize(t *testing.T) {
+	c := newValidationCache(time.Minute, 2)
+	now := time.Now()
+	claims := &Claims{TokenType: TokenTypeAccess}
+
+	c.put(digestToken("a"), claims, now)
+	c.put(digestToken("b"), claims, now)
+	c.put(digestToken("c"), claims, now)
+
+	_, ok := c.get(digestToken("c"), now)
+	assert.False(t, ok, "full cache should not accept new entries")
+
+	// 过期条目被清理后可再次写入
+	later := now.Add(2 * time.Minute)
+	c.put(digestToken("c"), claims, later)
+	_, ok = c.get(digestToken("c"), later)
+	assert.True(t, ok)
+}
+
+func TestAuthenticator_DeviceBinding(t *testing.T) {
+	type deviceKey struct{}
+	deviceCtx := func(id string) context.Context {
+		return context.WithValue(context.Background(), deviceKey{}, id)
+	}
+
+	newBoundAuth := func(t *testing.T, cacheTTL time.Duration) Authenticator {
+		t.Helper()
+		a, err := New(&Config{
+			SecretKey:          "this-is-a-valid-secret-key-at-least-32-chars",
+			ValidationCacheTTL: cacheTTL,
+		}, WithLogger(clog.Discard()), WithMeter(metrics.Discard()), WithDeviceBinding(func(ctx context.Context) string {
+			id, _ := ctx.Value(deviceKey{}).(string)
+			return id
+		}))
+		require.NoError(t, err)
+		return a
+	}
+
+	t.Run("只能在签发设备上使用", func(t *testing.T) {
+		a := newBoundAuth(t, 0)
+		claims := &Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "user-123"}}
+		pair, err := a.GenerateTokenPair(deviceCtx("device-a"), claims)
+		require.NoError(t, err)
+		assert.Empty(t, claims.Binding, "输入 claims 不被修改")
+
+		got, err := a.ValidateAccessToken(deviceCtx("device-a"), pair.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "device-a", got.Binding)
+
+		_, err = a.ValidateAccessToken(deviceCtx("device-b"), pair.AccessToken)
+		assert.ErrorIs(t, err, ErrBindingMismatch)
+		_, err = a.RefreshToken(deviceCtx("device-b"), pair.RefreshToken)
+		assert.ErrorIs(t, err, ErrBindingMismatch)
+
+		// 换发的新令牌沿用原绑定
+		next, err := a.RefreshToken(deviceCtx("device-a"), pair.RefreshToken)
+		require.NoError(t, err)
+		_, err = a.ValidateAccessToken(deviceCtx("device-b"), next.AccessToken)
+		assert.ErrorIs(t, err, ErrBindingMismatch)
+	})
+
+	t.Run("验证缓存命中时同样校验绑定", func(t *testing.T) {
+		a := newBoundAuth(t, time.Minute)
+		pair, err := a.GenerateTokenPair(deviceCtx("device-a"), &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: "user-123"},
+		})
+		require.NoError(t, err)
+
+		_, err = a.ValidateAccessToken(deviceCtx("device-a"), pair.AccessToken)
+		require.NoError(t, err)
+		_, err = a.ValidateAccessToken(deviceCtx("device-b"), pair.AccessToken)
+		assert.ErrorIs(t, err, ErrBindingMismatch)
+	})
+
+	t.Run("未绑定的 token 不做检查", func(t *testing.T) {
+		a := newBoundAuth(t, 0)
+		pair, err := a.GenerateTokenPair(context.Background(), &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: "user-123"},
+		})
+		require.NoError(t, err)
+
+		_, err = a.ValidateAccessToken(deviceCtx("device-b"), pair.AccessToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("GinMiddleware 通过请求读取绑定值", func(t *testing.T) {
+		gin.SetMode(gin.TestMode)
+		a, err := New(&Config{
+			SecretKey: "this-is-a-valid-secret-key-at-least-32-chars",
+		}, WithLogger(clog.Discard()), WithMeter(metrics.Discard()), WithDeviceBinding(func(ctx context.Context) string {
+			if r, ok := RequestFromContext(ctx); ok {
+				return r.UserAgent()
+			}
+			id, _ := ctx.Value(deviceKey{}).(string)
+			return id
+		}))
+		require.NoError(t, err)
+		pair, err := a.GenerateTokenPair(deviceCtx("agent-a"), &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: "user-123"},
+		})
+		require.NoError(t, err)
+
+		router := gin.New()
+		router.Use(a.GinMiddleware())
+		router.GET("/me", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		for agent, want := range map[string]int{"agent-a": http.StatusOK, "agent-b": http.StatusUnauthorized} {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+			req.Header.Set("User-Agent", agent)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, want, w.Code, agent)
+		}
+	})
+}
+
+func TestAuthenticator_SeparateRefreshSigning(t *testing.T) {
+	ctx := context.Background()
+	accessSecret := "this-is-a-valid-secret-key-at-least-32-chars"
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privatePEM := string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(rsaKey),
+	}))
+
+	newAuth := func(t *testing.T, refresh *SigningConfig) *jwtAuth {
+		t.Helper()
+		a, err := New(&Config{
+			SecretKey: accessSecret,
+			Refresh:   refresh,
+		}, WithLogger(clog.Discard()), WithMeter(metrics.Discard()))
+		require.NoError(t, err)
+		return a.(*jwtAuth)
+	}
+
+	t.Run("access 用 access 密钥、refresh 用 refresh 密钥验证", func(t *testing.T) {
+		a := newAuth(t, &SigningConfig{SigningMethod: "RS256", PrivateKey: privatePEM})
+		pair := createTokenPair(t, a, ctx)
+
+		access, _, err := jwt.NewParser().ParseUnverified(pair.AccessToken, &Claims{})
+		require.NoError(t, err)
+		assert.Equal(t, "HS256", access.Method.Alg())
+		refresh, _, err := jwt.NewParser().ParseUnverified(pair.RefreshToken, &Claims{})
+		require.NoError(t, err)
+		assert.Equal(t, "RS256", refresh.Method.Alg())
+
+		_, err = a.ValidateAccessToken(ctx, pair.AccessToken)
+		require.NoError(t, err)
+		_, err = a.ValidateRefreshToken(ctx, pair.RefreshToken)
+		require.NoError(t, err)
+
+		newPair, err := a.RefreshToken(ctx, pair.RefreshToken)
+		require.NoError(t, err)
+		_, err = a.ValidateAccessToken(ctx, newPair.AccessToken)
+		require.NoError(t, err)
+	})
+
+	t.Run("交叉验证失败", func(t *testing.T) {
+		refreshSecret := "another-valid-refresh-secret-at-least-32-chars"
+		a := newAuth(t, &SigningConfig{SecretKey: refreshSecret})
+		pair := createTokenPair(t, a, ctx)
+
+		_, err := a.ValidateAccessToken(ctx, pair.RefreshToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		_, err = a.ValidateRefreshToken(ctx, pair.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+
+		// 用 access 密钥签发的 refresh token 不被接受
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-123",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			TokenType: TokenTypeRefresh,
+		})
+		forgedString, err := forged.SignedString([]byte(accessSecret))
+		require.NoError(t, err)
+		_, err = a.ValidateRefreshToken(ctx, forgedString)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+
+		// 用 refresh 密钥签发的 access token 不被接受
+		forged = jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-123",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			TokenType: TokenTypeAccess,
+		})
+		forgedString, err = forged.SignedString([]byte(refreshSecret))
+		require.NoError(t, err)
+		_, err = a.ValidateAccessToken(ctx, forgedString)
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("混用签名方法被拒绝", func(t *testing.T) {
+		a := newAuth(t, &SigningConfig{SigningMethod: "RS256", PrivateKey: privatePEM})
+
+		// refresh 配置为 RS256，HS256 签发的 refresh token 即使密钥正确也被拒绝
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-123",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			TokenType: TokenTypeRefresh,
+		})
+		forgedString, err := forged.SignedString([]byte(accessSecret))
+		require.NoError(t, err)
+		_, err = a.ValidateRefreshToken(ctx, forgedString)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+
+		// access 配置为 HS256，RS256 签发的 access token 被拒绝
+		forged = jwt.NewWithClaims(jwt.SigningMethodRS256, &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-123",
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			TokenType: TokenTypeAccess,
+		})
+		forgedString, err = forged.SignedString(rsaKey)
+		require.NoError(t, err)
+		_, err = a.ValidateAccessToken(ctx, forgedString)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("非法 refresh 配置", func(t *testing.T) {
+		for _, refresh := range []*SigningConfig{
+			{SecretKey: "short"},
+			{SigningMethod: "RS256"},
+			{SigningMethod: "RS256", PrivateKey: "not a pem"},
+			{SigningMethod: "ES256", SecretKey: accessSecret},
+		} {
+			_, err := New(&Config{SecretKey: accessSecret, Refresh: refresh})
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		}
+	})
+}
+
 func BenchmarkGenerateTokenPair(b *testing.B) {
 	auth := createBenchmarkAuthenticator()
 	ctx := context.Background()
@@ -562,6 +1309,22 @@ func BenchmarkValidateAccessToken(b *testing.B) {
 	}
 }
 
+func BenchmarkValidateAccessToken_Cached(b *testing.B) {
+	auth, _ := New(&Config{
+		SecretKey:          "this-is-a-valid-secret-key-at-least-32-chars",
+		ValidationCacheTTL: time.Minute,
+	}, WithLogger(clog.Discard()), WithMeter(metrics.Discard()))
+	ctx := context.Background()
+	pair, _ := auth.GenerateTokenPair(ctx, &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "user-123"},
+	})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = auth.ValidateAccessToken(ctx, pair.AccessToken)
+	}
+}
+
 func BenchmarkRefreshToken(b *testing.B) {
 	auth := createBenchmarkAuthenticator()
 	ctx := context.Background()
@@ -637,3 +1400,12 @@ func signTestClaims(t *testing.T, auth *jwtAuth, claims *Claims) string {
 	require.NoError(t, err)
 	return token
 }
+
+func tokenKID(t *testing.T, tokenString string) string {
+	t.Helper()
+
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+	require.NoError(t, err)
+	kid, _ := token.Header["kid"].(string)
+	return kid
+}
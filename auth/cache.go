@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// defaultValidationCacheSize 验证缓存默认最大条目数
+const defaultValidationCacheSize = 10000
+
+// tokenDigest token 字符串的 SHA-256 摘要，用作缓存与撤销表的 key，避免在内存中保留原始 token。
+type tokenDigest [sha256.Size]byte
+
+func digestToken(token string) tokenDigest {
+	return sha256.Sum256([]byte(token))
+}
+
+// cachedValidation 一条已通过验证的 token 结果。
+type cachedValidation struct {
+	claims    *Claims
+	expiresAt time.Time
+}
+
+// validationCache 近期验证通过的 token 缓存，命中时跳过验签。
+//
+// 缓存项的有效期取 ttl 与 token 剩余有效期的较小值；条目数达到上限时先清理过期项，
+// 仍然满则不再写入，保证内存有界。
+type validationCache struct {
+	ttl     time.Duration
+	maxSize int
+
+	mu      sync.Mutex
+	entries map[tokenDigest]cachedValidation
+}
+
+func newValidationCache(ttl time.Duration, maxSize int) *validationCache {
+	if maxSize <= 0 {
+		maxSize = defaultValidationCacheSize
+	}
+	return &validationCache{
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[tokenDigest]cachedValidation),
+	}
+}
+
+// get 返回未过期的缓存结果。
+func (c *validationCache) get(key tokenDigest, now time.Time) (*Claims, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.claims, true
+}
+
+// put 缓存验证结果，有效期不超过 token 的 exp。
+func (c *validationCache) put(key tokenDigest, claims *Claims, now time.Time) {
+	expiresAt := now.Add(c.ttl)
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Before(expiresAt) {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if !now.Before(expiresAt) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= c.maxSize {
+		c.evictExpired(now)
+		if len(c.entries) >= c.maxSize {
+			return
+		}
+	}
+	c.entries[key] = cachedValidation{claims: cloneClaims(claims), expiresAt: expiresAt}
+}
+
+// remove 删除单个 token 的缓存。
+func (c *validationCache) remove(key tokenDigest) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
+
+// purge 清空缓存，密钥变更后调用。
+func (c *validationCache) purge() {
+	c.mu.Lock()
+	c.entries = make(map[tokenDigest]cachedValidation)
+	c.mu.Unlock()
+}
+
+func (c *validationCache) evictExpired(now time.Time) {
+	for key, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// revocationList 进程内的 token 撤销表，条目保留到 token 自身过期。
+type revocationList struct {
+	mu      sync.Mutex
+	entries map[tokenDigest]time.Time
+}
+
+func newRevocationList() *revocationList {
+	return &revocationList{entries: make(map[tokenDigest]time.Time)}
+}
+
+// add 记录撤销，并顺带清理已过期的条目。
+func (r *revocationList) add(key tokenDigest, expiresAt, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, exp := range r.entries {
+		if !now.Before(exp) {
+			delete(r.entries, k)
+		}
+	}
+	r.entries[key] = expiresAt
+}
+
+// contains 判断 token 是否已被撤销。
+func (r *revocationList) contains(key tokenDigest) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.entries[key]
+	return ok
+}
@@ -25,6 +25,7 @@ const (
 //   - Username: 用户名 (对应 uname)
 //   - Roles: 角色列表 (对应 roles)
 //   - Extra: 扩展字段 (对应 extra)
+//   - Binding: 设备指纹或会话 ID (对应 bind)，配合 WithDeviceBinding 使用
 type Claims struct {
 	// 标准声明 (包含 Subject, Issuer, ExpiresAt 等)
 	jwt.RegisteredClaims
@@ -34,4 +35,5 @@ type Claims struct {
 	Username  string         `json:"uname,omitempty"` // 用户名
 	Roles     []string       `json:"roles,omitempty"` // 角色列表
 	Extra     map[string]any `json:"extra,omitempty"` // 扩展信息
+	Binding   string         `json:"bind,omitempty"`  // 绑定的设备指纹或会话 ID
 }
@@ -12,20 +12,34 @@ import (
 // Config Auth 配置
 type Config struct {
 	// JWT 配置
-	SecretKey     string   `mapstructure:"secret_key"`     // 签名密钥（至少 32 字符）
-	SigningMethod string   `mapstructure:"signing_method"` // 签名方法: HS256（目前只支持）
-	Issuer        string   `mapstructure:"issuer"`         // 签发者
-	Audience      []string `mapstructure:"audience"`       // 接收者
+	SecretKey     string     `mapstructure:"secret_key"`     // 签名密钥（至少 32 字符）
+	SecretKeys    []KeyEntry `mapstructure:"secret_keys"`    // 多密钥轮换，配置后优先于 SecretKey
+	SigningMethod string     `mapstructure:"signing_method"` // 签名方法: HS256（目前只支持）
+	Issuer        string     `mapstructure:"issuer"`         // 签发者
+	Audience      []string   `mapstructure:"audience"`       // 本服务受众，签发时写入 aud，校验时要求 token 的 aud 至少包含其一
+
+	// Refresh refresh token 的独立签名配置，留空时与 access token 共用上面的密钥
+	Refresh *SigningConfig `mapstructure:"refresh"`
+
+	// AllowMissingAudience 配置了 Audience 时，是否接受不带 aud 的 token（默认严格拒绝）
+	AllowMissingAudience bool `mapstructure:"allow_missing_audience"`
 
 	// Token 有效期
 	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl"`  // Access Token TTL，默认 15m
 	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"` // Refresh Token TTL，默认 7d
 
+	// KeyGracePeriod ReloadKeys 时从列表中移除的旧密钥继续用于验证的时长，默认等于 RefreshTokenTTL
+	KeyGracePeriod time.Duration `mapstructure:"key_grace_period"`
+
 	// Token 提取配置（可选，覆盖默认查找顺序）
 	// 默认顺序: header:Authorization -> query:token -> cookie:jwt
 	// 可指定单一来源如 "header:Authorization" 或 "query:token"
 	TokenLookup   string `mapstructure:"token_lookup"`    // 提取方式，留空使用默认多源查找
 	TokenHeadName string `mapstructure:"token_head_name"` // Header 前缀，默认 Bearer
+
+	// 验证缓存（可选）：近期验证通过的 token 在 TTL 内跳过验签，缓存时长不超过 token 剩余有效期
+	ValidationCacheTTL  time.Duration `mapstructure:"validation_cache_ttl"`  // 缓存时长，0 表示不缓存
+	ValidationCacheSize int           `mapstructure:"validation_cache_size"` // 最大缓存条目数，默认 10000
 }
 
 // setDefaults 设置默认值
@@ -39,6 +53,9 @@ func (c *Config) setDefaults() {
 	if c.RefreshTokenTTL == 0 {
 		c.RefreshTokenTTL = 7 * 24 * time.Hour
 	}
+	if c.KeyGracePeriod == 0 {
+		c.KeyGracePeriod = c.RefreshTokenTTL
+	}
 	if c.TokenHeadName == "" {
 		c.TokenHeadName = "Bearer"
 	}
@@ -47,12 +64,18 @@ func (c *Config) setDefaults() {
 
 // validate 验证配置
 func (c *Config) validate() error {
-	if c.SecretKey == "" {
-		return ErrInvalidConfig
-	}
+	if len(c.SecretKeys) > 0 {
+		if err := validateKeys(c.SecretKeys); err != nil {
+			return err
+		}
+	} else {
+		if c.SecretKey == "" {
+			return ErrInvalidConfig
+		}
 
-	if len(c.SecretKey) < 32 {
-		return xerrors.Wrapf(ErrInvalidConfig, "secret_key must be at least 32 characters")
+		if len(c.SecretKey) < 32 {
+			return xerrors.Wrapf(ErrInvalidConfig, "secret_key must be at least 32 characters")
+		}
 	}
 
 	if c.SigningMethod != jwt.SigningMethodHS256.Alg() {
@@ -67,6 +90,18 @@ func (c *Config) validate() error {
 		return xerrors.Wrapf(ErrInvalidConfig, "refresh_token_ttl must be positive")
 	}
 
+	if c.KeyGracePeriod < 0 {
+		return xerrors.Wrapf(ErrInvalidConfig, "key_grace_period must not be negative")
+	}
+
+	if c.ValidationCacheTTL < 0 {
+		return xerrors.Wrapf(ErrInvalidConfig, "validation_cache_ttl must not be negative")
+	}
+
+	if c.ValidationCacheSize < 0 {
+		return xerrors.Wrapf(ErrInvalidConfig, "validation_cache_size must not be negative")
+	}
+
 	if c.TokenLookup != "" {
 		parts := strings.Split(c.TokenLookup, ":")
 		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
@@ -9,4 +9,8 @@ var (
 	ErrInvalidClaims    = xerrors.New("auth: invalid claims")
 	ErrInvalidSignature = xerrors.New("auth: invalid signature")
 	ErrInvalidConfig    = xerrors.New("auth: invalid config")
+	ErrInvalidAudience  = xerrors.New("auth: invalid audience")
+	ErrRevokedToken     = xerrors.New("auth: token revoked")
+	ErrCSRFTokenInvalid = xerrors.New("auth: csrf token missing or mismatched")
+	ErrBindingMismatch  = xerrors.New("auth: token bound to another device")
 )
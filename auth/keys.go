@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// KeyEntry 对称签名密钥条目，用于密钥轮换。
+type KeyEntry struct {
+	// ID 密钥标识，签发时写入 JWT header 的 kid
+	ID string `mapstructure:"id"`
+	// Secret HMAC 密钥（至少 32 字符）
+	Secret string `mapstructure:"secret"`
+	// Active 是否为当前签发密钥，有且仅有一个
+	Active bool `mapstructure:"active"`
+	// ExpiresAt 宽限期结束时间，之后该密钥签发的 token 不再被接受；零值表示不限
+	ExpiresAt time.Time `mapstructure:"expires_at"`
+}
+
+// legacyKeyID 单密钥模式切换到 SecretKeys 后，旧 SecretKey 在 keyring 中保留使用的保留 kid。
+//
+// 单密钥模式签发的 token 不带 kid，宽限期内按该条目验证；validateKeys 拒绝空 id，不会与配置冲突。
+const legacyKeyID = ""
+
+// keyring 一组可用于验证的密钥及当前签发密钥。
+type keyring struct {
+	activeID string
+	active   []byte
+	keys     map[string]KeyEntry
+}
+
+// newKeyring 根据配置构建 keyring。
+//
+// 未配置 SecretKeys 时退化为单密钥模式：签发不写 kid，验证只使用 SecretKey。
+func newKeyring(cfg *Config) *keyring {
+	if len(cfg.SecretKeys) == 0 {
+		return &keyring{active: []byte(cfg.SecretKey)}
+	}
+
+	kr := &keyring{keys: make(map[string]KeyEntry, len(cfg.SecretKeys))}
+	for _, entry := range cfg.SecretKeys {
+		kr.keys[entry.ID] = entry
+		if entry.Active {
+			kr.activeID = entry.ID
+			kr.active = []byte(entry.Secret)
+		}
+	}
+	return kr
+}
+
+// lookup 按 kid 查找验证密钥，宽限期已结束的密钥视为不存在。
+//
+// 不带 kid 的 token 使用当前签发密钥验证；从单密钥模式轮换过来且旧 SecretKey 仍在宽限期内时，
+// 同时接受旧 SecretKey 签发的 token。
+func (kr *keyring) lookup(token *jwt.Token, now time.Time) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kr.keys == nil {
+		return kr.active, nil
+	}
+	if kid == legacyKeyID {
+		legacy, ok := kr.keys[legacyKeyID]
+		if !ok || (!legacy.ExpiresAt.IsZero() && now.After(legacy.ExpiresAt)) {
+			return kr.active, nil
+		}
+		return jwt.VerificationKeySet{Keys: []jwt.VerificationKey{[]byte(legacy.Secret), kr.active}}, nil
+	}
+
+	entry, ok := kr.keys[kid]
+	if !ok {
+		return nil, xerrors.Wrapf(ErrInvalidToken, "unknown kid: %s", kid)
+	}
+	if !entry.ExpiresAt.IsZero() && now.After(entry.ExpiresAt) {
+		return nil, xerrors.Wrapf(ErrInvalidToken, "kid %s grace period ended", kid)
+	}
+	return []byte(entry.Secret), nil
+}
+
+// validateKeys 校验密钥列表。
+func validateKeys(keys []KeyEntry) error {
+	seen := make(map[string]struct{}, len(keys))
+	active := 0
+	for _, entry := range keys {
+		if entry.ID == "" {
+			return xerrors.Wrapf(ErrInvalidConfig, "secret_keys: id is required")
+		}
+		if _, ok := seen[entry.ID]; ok {
+			return xerrors.Wrapf(ErrInvalidConfig, "secret_keys: duplicate id %s", entry.ID)
+		}
+		seen[entry.ID] = struct{}{}
+
+		if len(entry.Secret) < 32 {
+			return xerrors.Wrapf(ErrInvalidConfig, "secret_keys: secret of %s must be at least 32 characters", entry.ID)
+		}
+		if entry.Active {
+			active++
+		}
+	}
+
+	if active != 1 {
+		return xerrors.Wrapf(ErrInvalidConfig, "secret_keys: exactly one active key required, got %d", active)
+	}
+	return nil
+}
+
+// retainRetired 返回新密钥列表加上被移出列表、仍需保留宽限期的旧密钥。
+//
+// 旧密钥降为非 active，宽限期终点取 now+grace 与其原有 ExpiresAt 中较早者；
+// 已过宽限期的旧密钥直接丢弃。单密钥模式的 SecretKey 没有 kid，以保留 kid legacyKeyID 保留宽限期。
+func retainRetired(prev *keyring, keys []KeyEntry, now time.Time, grace time.Duration) []KeyEntry {
+	merged := slices.Clone(keys)
+	if prev == nil {
+		return merged
+	}
+
+	deadline := now.Add(grace)
+	if prev.keys == nil {
+		if len(prev.active) > 0 {
+			merged = append(merged, KeyEntry{ID: legacyKeyID, Secret: string(prev.active), ExpiresAt: deadline})
+		}
+		return merged
+	}
+
+	current := make(map[string]struct{}, len(keys))
+	for _, entry := range keys {
+		current[entry.ID] = struct{}{}
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(prev.keys)) {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		entry := prev.keys[id]
+		if !entry.ExpiresAt.IsZero() && !entry.ExpiresAt.After(now) {
+			continue
+		}
+		if entry.ExpiresAt.IsZero() || deadline.Before(entry.ExpiresAt) {
+			entry.ExpiresAt = deadline
+		}
+		entry.Active = false
+		merged = append(merged, entry)
+	}
+	return merged
+}
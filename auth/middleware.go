@@ -9,8 +9,22 @@ import (
 
 // GinMiddleware 返回 Gin 认证中间件，将验证请求中的 JWT Token
 // 并将 Claims 存入 Context（ClaimsKey），可通过 GetClaims 获取
-func (a *jwtAuth) GinMiddleware() gin.HandlerFunc {
+func (a *jwtAuth) GinMiddleware(opts ...MiddlewareOption) gin.HandlerFunc {
+	var o middlewareOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return func(c *gin.Context) {
+		if o.csrf {
+			if err := verifyCSRF(c.Request); err != nil {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "forbidden",
+				})
+				return
+			}
+		}
+
 		token, err := a.ExtractToken(c.Request)
 		if err != nil {
 			// Token 缺失不计入指标（用户未提供 token，不属于验证失败）
@@ -20,7 +34,11 @@ func (a *jwtAuth) GinMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := a.ValidateAccessToken(c.Request.Context(), token)
+		ctx := c.Request.Context()
+		if a.options.deviceBinding != nil {
+			ctx = ContextWithRequest(ctx, c.Request)
+		}
+		claims, err := a.ValidateAccessToken(ctx, token)
 		// 指标已在 ValidateToken 内部记录
 		if err != nil {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"context"
+
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/metrics"
 )
@@ -10,8 +12,9 @@ type Option func(*options)
 
 // options 内部选项结构
 type options struct {
-	logger clog.Logger
-	meter  metrics.Meter
+	logger        clog.Logger
+	meter         metrics.Meter
+	deviceBinding func(ctx context.Context) string // 从请求 ctx 提取设备/会话绑定值
 }
 
 // defaultOptions 创建默认选项，使用 Discard() 作为空实现
@@ -39,3 +42,21 @@ func WithMeter(m metrics.Meter) Option {
 		}
 	}
 }
+
+// MiddlewareOption GinMiddleware 选项
+type MiddlewareOption func(*middlewareOptions)
+
+// middlewareOptions GinMiddleware 内部选项
+type middlewareOptions struct {
+	csrf bool
+}
+
+// WithCSRFProtection 开启 double-submit CSRF 校验
+//
+// 对非安全方法（除 GET、HEAD、OPTIONS、TRACE 外）要求请求头 X-CSRF-Token
+// 与 Cookie csrf_token 同时存在且一致，否则返回 403。适用于把 JWT 放在 cookie 的场景。
+func WithCSRFProtection() MiddlewareOption {
+	return func(o *middlewareOptions) {
+		o.csrf = true
+	}
+}
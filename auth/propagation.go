@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+const (
+	// ClaimsMetadataKey 透传 claims 的 gRPC metadata 键，值为 base64url 编码的 JSON
+	ClaimsMetadataKey = "x-genesis-claims"
+
+	// ClaimsSignatureMetadataKey 透传 claims 签名的 gRPC metadata 键，值为 base64url 编码的 HMAC-SHA256
+	ClaimsSignatureMetadataKey = "x-genesis-claims-sig"
+)
+
+// PropagationOption claims 透传选项
+type PropagationOption func(*propagationOptions)
+
+// propagationOptions claims 透传内部选项
+type propagationOptions struct {
+	secret []byte
+}
+
+// WithPropagationSecret 设置 claims 透传的签名密钥
+//
+// 网关与下游必须使用同一密钥，密钥只在信任边界内共享，不要与 JWT 签名密钥复用。
+func WithPropagationSecret(secret []byte) PropagationOption {
+	return func(o *propagationOptions) {
+		o.secret = secret
+	}
+}
+
+// InjectClaims 把已验证的 claims 写入 ctx 的 gRPC outgoing metadata，供下游服务直接使用
+//
+// 网关验证 access token 后调用，下游无需重新验签 JWT。配置了 WithPropagationSecret 时
+// 同时写入 HMAC-SHA256 签名；claims 为 nil 或序列化失败时原样返回 ctx。
+// 已存在的同名 metadata 会被覆盖，避免上游伪造的值继续向下传递。
+func InjectClaims(ctx context.Context, claims *Claims, opts ...PropagationOption) context.Context {
+	if claims == nil {
+		return ctx
+	}
+	o := applyPropagationOptions(opts)
+
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return ctx
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set(ClaimsMetadataKey, payload)
+	md.Delete(ClaimsSignatureMetadataKey)
+	if len(o.secret) > 0 {
+		md.Set(ClaimsSignatureMetadataKey, signClaims(o.secret, payload))
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// ClaimsFromMetadata 从 ctx 的 gRPC incoming metadata 中取出 InjectClaims 写入的 claims
+//
+// 必须通过 WithPropagationSecret 提供与网关相同的密钥：
+//   - 未配置密钥返回 ErrInvalidConfig；
+//   - metadata 中没有 claims 返回 ErrMissingToken；
+//   - 签名缺失或不匹配（claims 被篡改）返回 ErrInvalidSignature；
+//   - claims 已过期返回 ErrExpiredToken。
+func ClaimsFromMetadata(ctx context.Context, opts ...PropagationOption) (*Claims, error) {
+	o := applyPropagationOptions(opts)
+	if len(o.secret) == 0 {
+		return nil, xerrors.Wrapf(ErrInvalidConfig, "propagation secret is required")
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, ErrMissingToken
+	}
+	payloads := md.Get(ClaimsMetadataKey)
+	if len(payloads) == 0 || payloads[0] == "" {
+		return nil, ErrMissingToken
+	}
+	sigs := md.Get(ClaimsSignatureMetadataKey)
+	if len(payloads) != 1 || len(sigs) != 1 {
+		return nil, ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(sigs[0]), []byte(signClaims(o.secret, payloads[0]))) {
+		return nil, ErrInvalidSignature
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payloads[0])
+	if err != nil {
+		return nil, xerrors.Wrapf(ErrInvalidClaims, "decode propagated claims: %v", err)
+	}
+	var claims Claims
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, xerrors.Wrapf(ErrInvalidClaims, "unmarshal propagated claims: %v", err)
+	}
+	if claims.ExpiresAt != nil && !claims.ExpiresAt.After(time.Now()) {
+		return nil, ErrExpiredToken
+	}
+	return &claims, nil
+}
+
+// applyPropagationOptions 应用透传选项
+func applyPropagationOptions(opts []PropagationOption) *propagationOptions {
+	o := &propagationOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// signClaims 计算 payload 的 HMAC-SHA256 签名
+func signClaims(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+// forwardMetadata 模拟 gRPC 传输：把网关的 outgoing metadata 变为下游的 incoming metadata
+func forwardMetadata(t *testing.T, ctx context.Context) context.Context {
+	t.Helper()
+	md, ok := metadata.FromOutgoingContext(ctx)
+	require.True(t, ok)
+	return metadata.NewIncomingContext(context.Background(), md.Copy())
+}
+
+func TestClaimsPropagation(t *testing.T) {
+	secret := WithPropagationSecret([]byte("trusted-boundary-secret"))
+	claims := &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "user-123",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Username: "alice",
+		Roles:    []string{"admin", "editor"},
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		ctx := InjectClaims(context.Background(), claims, secret)
+
+		got, err := ClaimsFromMetadata(forwardMetadata(t, ctx), secret)
+		require.NoError(t, err)
+		require.Equal(t, "user-123", got.Subject)
+		require.Equal(t, "alice", got.Username)
+		require.Equal(t, []string{"admin", "editor"}, got.Roles)
+	})
+
+	t.Run("tampered claims", func(t *testing.T) {
+		ctx := forwardMetadata(t, InjectClaims(context.Background(), claims, secret))
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		forged := *claims
+		forged.Roles = []string{"root"}
+		data, err := json.Marshal(&forged)
+		require.NoError(t, err)
+		md.Set(ClaimsMetadataKey, base64.RawURLEncoding.EncodeToString(data))
+
+		_, err = ClaimsFromMetadata(metadata.NewIncomingContext(context.Background(), md), secret)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		ctx := InjectClaims(context.Background(), claims, secret)
+
+		_, err := ClaimsFromMetadata(forwardMetadata(t, ctx), WithPropagationSecret([]byte("other-secret")))
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("missing signature", func(t *testing.T) {
+		ctx := InjectClaims(context.Background(), claims)
+
+		_, err := ClaimsFromMetadata(forwardMetadata(t, ctx), secret)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("missing claims", func(t *testing.T) {
+		_, err := ClaimsFromMetadata(metadata.NewIncomingContext(context.Background(), metadata.MD{}), secret)
+		require.ErrorIs(t, err, ErrMissingToken)
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		ctx := InjectClaims(context.Background(), claims, secret)
+
+		_, err := ClaimsFromMetadata(forwardMetadata(t, ctx))
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	t.Run("expired claims", func(t *testing.T) {
+		expired := *claims
+		expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		ctx := InjectClaims(context.Background(), &expired, secret)
+
+		_, err := ClaimsFromMetadata(forwardMetadata(t, ctx), secret)
+		require.ErrorIs(t, err, ErrExpiredToken)
+	})
+
+	t.Run("overrides upstream metadata", func(t *testing.T) {
+		upstream := metadata.AppendToOutgoingContext(context.Background(),
+			ClaimsMetadataKey, "forged", ClaimsSignatureMetadataKey, "forged")
+		ctx := InjectClaims(upstream, claims, secret)
+
+		got, err := ClaimsFromMetadata(forwardMetadata(t, ctx), secret)
+		require.NoError(t, err)
+		require.Equal(t, "user-123", got.Subject)
+	})
+}
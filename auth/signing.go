@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// SigningConfig 独立的签名配置，用于让 refresh token 与 access token 使用不同的签名方法或密钥。
+type SigningConfig struct {
+	SigningMethod string `mapstructure:"signing_method"` // 签名方法: HS256 或 RS256，默认 HS256
+	SecretKey     string `mapstructure:"secret_key"`     // HS256 签名密钥（至少 32 字符）
+	PrivateKey    string `mapstructure:"private_key"`    // RS256 PEM 私钥，用于签发
+	PublicKey     string `mapstructure:"public_key"`     // RS256 PEM 公钥，用于验证；留空时由私钥推导
+}
+
+// staticSigner 由 SigningConfig 构建的单密钥签名器，不参与密钥轮换。
+type staticSigner struct {
+	method    jwt.SigningMethod
+	signKey   any
+	verifyKey any
+}
+
+// newStaticSigner 解析签名配置，密钥不合法时返回 ErrInvalidConfig。
+func newStaticSigner(cfg *SigningConfig) (*staticSigner, error) {
+	method := cfg.SigningMethod
+	if method == "" {
+		method = jwt.SigningMethodHS256.Alg()
+	}
+
+	switch method {
+	case jwt.SigningMethodHS256.Alg():
+		if len(cfg.SecretKey) < 32 {
+			return nil, xerrors.Wrapf(ErrInvalidConfig, "refresh secret_key must be at least 32 characters")
+		}
+		key := []byte(cfg.SecretKey)
+		return &staticSigner{method: jwt.SigningMethodHS256, signKey: key, verifyKey: key}, nil
+
+	case jwt.SigningMethodRS256.Alg():
+		if cfg.PrivateKey == "" {
+			return nil, xerrors.Wrapf(ErrInvalidConfig, "refresh private_key is required for RS256")
+		}
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cfg.PrivateKey))
+		if err != nil {
+			return nil, xerrors.Wrapf(ErrInvalidConfig, "parse refresh private_key: %v", err)
+		}
+		publicKey := &privateKey.PublicKey
+		if cfg.PublicKey != "" {
+			publicKey, err = jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.PublicKey))
+			if err != nil {
+				return nil, xerrors.Wrapf(ErrInvalidConfig, "parse refresh public_key: %v", err)
+			}
+		}
+		return &staticSigner{method: jwt.SigningMethodRS256, signKey: privateKey, verifyKey: publicKey}, nil
+
+	default:
+		return nil, xerrors.Wrapf(ErrInvalidConfig, "unsupported refresh signing_method: %s", method)
+	}
+}
+
+// lookup 返回验证密钥，签名方法与配置不一致的 token 直接拒绝。
+func (s *staticSigner) lookup(token *jwt.Token) (any, error) {
+	if token.Method.Alg() != s.method.Alg() {
+		return nil, xerrors.Wrapf(ErrInvalidToken, "unexpected signing method: %s", token.Method.Alg())
+	}
+	return s.verifyKey, nil
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// TokenManager 客户端 token 管理器
+//
+// 缓存当前 access token，按 token 中的 exp 判断是否临近过期，在过期前 skew 内自动调用
+// refreshFn 换取新 token。多个 goroutine 同时发现需要刷新时只调用一次 refreshFn，其余等待结果。
+// token 只做解析、不验签，过期时间以客户端本地时钟为准；没有 exp 的 token 视为永不过期。
+type TokenManager struct {
+	refreshFn func(ctx context.Context) (string, error)
+	skew      time.Duration
+	now       func() time.Time
+
+	mu         sync.Mutex
+	token      string
+	expiresAt  time.Time // 零值表示没有 exp
+	refreshing *tokenRefresh
+}
+
+// tokenRefresh 一次进行中的刷新，done 关闭后 token / err 可读
+type tokenRefresh struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// NewTokenManager 创建客户端 token 管理器
+//
+// refreshFn 返回新的 access token（如调用服务端的刷新接口），skew 为提前刷新的时间窗口，
+// 小于 0 时按 0 处理。首次调用 Token 时会立即刷新。
+func NewTokenManager(refreshFn func(ctx context.Context) (token string, err error), skew time.Duration) *TokenManager {
+	return &TokenManager{
+		refreshFn: refreshFn,
+		skew:      max(skew, 0),
+		now:       time.Now,
+	}
+}
+
+// Token 返回有效的 token，临近过期（skew 内）时先刷新
+//
+// 刷新失败时，旧 token 尚未真正过期则返回旧 token，否则返回错误；下一次调用会重新尝试刷新。
+func (m *TokenManager) Token(ctx context.Context) (string, error) {
+	m.mu.Lock()
+	if m.fresh(m.now()) {
+		token := m.token
+		m.mu.Unlock()
+		return token, nil
+	}
+
+	call := m.refreshing
+	if call == nil {
+		call = &tokenRefresh{done: make(chan struct{})}
+		m.refreshing = call
+		m.mu.Unlock()
+		m.refresh(ctx, call)
+	} else {
+		m.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return m.fallback(ctx.Err())
+		}
+	}
+
+	if call.err != nil {
+		return m.fallback(call.err)
+	}
+	return call.token, nil
+}
+
+// Remaining 返回当前 token 距过期的剩余时间，没有 token 或已过期时返回 0
+//
+// token 没有 exp 时返回 -1，表示永不过期。
+func (m *TokenManager) Remaining() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.token == "" {
+		return 0
+	}
+	if m.expiresAt.IsZero() {
+		return -1
+	}
+	return max(m.expiresAt.Sub(m.now()), 0)
+}
+
+// refresh 调用 refreshFn 并更新缓存，结束后唤醒等待者
+func (m *TokenManager) refresh(ctx context.Context, call *tokenRefresh) {
+	token, err := m.refreshFn(ctx)
+	var expiresAt time.Time
+	if err == nil {
+		expiresAt, err = tokenExpiry(token)
+	}
+
+	m.mu.Lock()
+	if err == nil {
+		m.token, m.expiresAt = token, expiresAt
+	}
+	call.token, call.err = token, err
+	m.refreshing = nil
+	m.mu.Unlock()
+	close(call.done)
+}
+
+// fresh 判断缓存的 token 在 now 时是否仍在 skew 之外（调用方持有 mu）
+func (m *TokenManager) fresh(now time.Time) bool {
+	if m.token == "" {
+		return false
+	}
+	return m.expiresAt.IsZero() || now.Before(m.expiresAt.Add(-m.skew))
+}
+
+// fallback 刷新失败时，旧 token 未过期则继续使用，否则返回错误
+func (m *TokenManager) fallback(err error) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.token != "" && (m.expiresAt.IsZero() || m.now().Before(m.expiresAt)) {
+		return m.token, nil
+	}
+	return "", xerrors.Wrap(err, "refresh token")
+}
+
+// tokenExpiry 解析 token 的 exp（不验签），没有 exp 时返回零值
+func tokenExpiry(token string) (time.Time, error) {
+	if token == "" {
+		return time.Time{}, xerrors.Wrap(ErrMissingToken, "refresh returned empty token")
+	}
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(token, claims); err != nil {
+		return time.Time{}, xerrors.Wrapf(ErrInvalidToken, "parse refreshed token: %v", err)
+	}
+	if claims.ExpiresAt == nil {
+		return time.Time{}, nil
+	}
+	return claims.ExpiresAt.Time, nil
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// signedToken 生成指定过期时间的测试 token，exp 为零值时不带 exp
+func signedToken(t *testing.T, subject string, exp time.Time) string {
+	t.Helper()
+	claims := jwt.RegisteredClaims{Subject: subject}
+	if !exp.IsZero() {
+		claims.ExpiresAt = jwt.NewNumericDate(exp)
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("token-manager-test-secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func TestTokenManager(t *testing.T) {
+	ctx := context.Background()
+	base := time.Now().Truncate(time.Second)
+
+	newManager := func(refreshFn func(ctx context.Context) (string, error)) (*TokenManager, *atomic.Int64) {
+		var clock atomic.Int64
+		clock.Store(base.UnixNano())
+		m := NewTokenManager(refreshFn, 30*time.Second)
+		m.now = func() time.Time { return time.Unix(0, clock.Load()) }
+		return m, &clock
+	}
+
+	t.Run("临近过期时触发一次刷新", func(t *testing.T) {
+		var refreshes atomic.Int32
+		m, clock := newManager(func(ctx context.Context) (string, error) {
+			n := refreshes.Add(1)
+			return signedToken(t, string(rune('a'+n)), base.Add(time.Duration(n)*time.Minute)), nil
+		})
+
+		first, err := m.Token(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, refreshes.Load(), "首次调用立即刷新")
+		require.Equal(t, time.Minute, m.Remaining())
+
+		// skew 之外直接返回缓存
+		clock.Add(int64(20 * time.Second))
+		got, err := m.Token(ctx)
+		require.NoError(t, err)
+		require.Equal(t, first, got)
+		require.EqualValues(t, 1, refreshes.Load())
+
+		// 进入 skew 窗口后刷新
+		clock.Add(int64(15 * time.Second))
+		got, err = m.Token(ctx)
+		require.NoError(t, err)
+		require.NotEqual(t, first, got)
+		require.EqualValues(t, 2, refreshes.Load())
+	})
+
+	t.Run("并发调用只刷一次", func(t *testing.T) {
+		var refreshes atomic.Int32
+		release := make(chan struct{})
+		m, _ := newManager(func(ctx context.Context) (string, error) {
+			refreshes.Add(1)
+			<-release
+			return signedToken(t, "user", base.Add(time.Hour)), nil
+		})
+
+		var wg sync.WaitGroup
+		tokens := make([]string, 20)
+		errs := make([]error, len(tokens))
+		for i := range tokens {
+			wg.Go(func() {
+				tokens[i], errs[i] = m.Token(ctx)
+			})
+		}
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+
+		require.EqualValues(t, 1, refreshes.Load())
+		for _, token := range tokens {
+			require.Equal(t, tokens[0], token)
+		}
+	})
+
+	t.Run("刷新失败时返回未过期的旧 token 或错误", func(t *testing.T) {
+		refreshErr := errors.New("auth server down")
+		var fail atomic.Bool
+		m, clock := newManager(func(ctx context.Context) (string, error) {
+			if fail.Load() {
+				return "", refreshErr
+			}
+			return signedToken(t, "user", base.Add(time.Minute)), nil
+		})
+		old, err := m.Token(ctx)
+		require.NoError(t, err)
+
+		fail.Store(true)
+		clock.Add(int64(45 * time.Second)) // 进入 skew，但尚未过期
+		got, err := m.Token(ctx)
+		require.NoError(t, err)
+		require.Equal(t, old, got)
+
+		clock.Add(int64(20 * time.Second)) // 已过期
+		_, err = m.Token(ctx)
+		require.ErrorIs(t, err, refreshErr)
+		require.Zero(t, m.Remaining())
+	})
+
+	t.Run("无效 token 与无 exp 的 token", func(t *testing.T) {
+		m, _ := newManager(func(ctx context.Context) (string, error) { return "not-a-jwt", nil })
+		_, err := m.Token(ctx)
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		var refreshes atomic.Int32
+		m, clock := newManager(func(ctx context.Context) (string, error) {
+			refreshes.Add(1)
+			return signedToken(t, "service", time.Time{}), nil
+		})
+		_, err = m.Token(ctx)
+		require.NoError(t, err)
+		clock.Add(int64(24 * time.Hour))
+		_, err = m.Token(ctx)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, refreshes.Load(), "没有 exp 的 token 不会刷新")
+		require.Equal(t, time.Duration(-1), m.Remaining())
+	})
+}
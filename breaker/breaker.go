@@ -6,6 +6,8 @@
 //
 // 当前组件的定位比较克制：
 //   - 核心能力是 Execute、State 和 gRPC UnaryClientInterceptor
+//   - ForceOpen / ForceClose / Reset 支持演练和紧急止血时手动干预
+//   - RecoveryStrategy 让半开探测成功后按阶梯逐步放大流量，恢复期间失败立即重新熔断
 //   - 默认以 cc.Target() 作为服务级熔断 key，也支持通过 WithKeyFunc 自定义粒度
 //   - gRPC 拦截器会区分系统性错误与业务错误，避免把 InvalidArgument、NotFound
 //     等明显业务错误直接计入熔断统计
@@ -45,6 +47,18 @@ type Breaker interface {
 
 	// State 获取指定键的熔断器状态
 	State(key string) (State, error)
+
+	// ForceOpen 强制打开指定键的熔断器
+	// 之后无视统计一律拒绝（返回 ErrOpenState，Fallback 同样生效），直到调用 Reset。
+	// 用于故障演练或紧急止血。
+	ForceOpen(key string) error
+
+	// ForceClose 强制关闭指定键的熔断器
+	// 之后请求全部放行且不计入统计，直到调用 Reset。仅用于调试。
+	ForceClose(key string) error
+
+	// Reset 解除强制状态并清空统计，恢复正常的熔断判断
+	Reset(key string) error
 }
 
 // State 熔断器状态
@@ -57,6 +71,12 @@ const (
 	StateHalfOpen
 	// StateOpen 打开状态（熔断中）
 	StateOpen
+	// StateForcedOpen 被 ForceOpen 强制打开，Reset 前一律拒绝
+	StateForcedOpen
+	// StateForcedClosed 被 ForceClose 强制关闭，Reset 前不做熔断
+	StateForcedClosed
+	// StateRecovering 半开探测成功后的渐进恢复期，按 RecoveryStrategy 逐步放大流量
+	StateRecovering
 )
 
 // String 返回状态的字符串表示
@@ -68,6 +88,12 @@ func (s State) String() string {
 		return "half_open"
 	case StateOpen:
 		return "open"
+	case StateForcedOpen:
+		return "forced_open"
+	case StateForcedClosed:
+		return "forced_closed"
+	case StateRecovering:
+		return "recovering"
 	default:
 		return "unknown"
 	}
@@ -98,6 +124,10 @@ type Config struct {
 	// MinimumRequests 触发熔断的最小请求数（默认：10）
 	// 请求数少于此值时不会触发熔断
 	MinimumRequests uint32 `json:"minimum_requests" yaml:"minimum_requests"`
+
+	// RecoveryStrategy 半开探测成功后的渐进恢复策略（默认：nil，探测成功后立即全部放行）
+	// 设置后按阶梯逐步放大放行比例，恢复期间任意失败立即重新熔断
+	RecoveryStrategy *RecoveryStrategy `json:"recovery_strategy" yaml:"recovery_strategy"`
 }
 
 // validate 验证配置并设置默认值（内部使用）。
@@ -123,6 +153,9 @@ func (c *Config) validate() error {
 	if c.MinimumRequests == 0 {
 		c.MinimumRequests = 10
 	}
+	if c.RecoveryStrategy != nil {
+		return c.RecoveryStrategy.validate()
+	}
 	return nil
 }
 
@@ -270,6 +270,8 @@ func TestStateString(t *testing.T) {
 		{"StateClosed", StateClosed, "closed"},
 		{"StateHalfOpen", StateHalfOpen, "half_open"},
 		{"StateOpen", StateOpen, "open"},
+		{"StateForcedOpen", StateForcedOpen, "forced_open"},
+		{"StateForcedClosed", StateForcedClosed, "forced_closed"},
 		{"UnknownState", State(999), "unknown"},
 	}
 
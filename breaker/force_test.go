@@ -0,0 +1,118 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestForceOpen_RejectsUntilReset(t *testing.T) {
+	brk, err := New(&Config{
+		Timeout:         time.Minute,
+		FailureRatio:    0.6,
+		MinimumRequests: 3,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	calls := 0
+	ok := func() (any, error) {
+		calls++
+		return "ok", nil
+	}
+
+	require.NoError(t, brk.ForceOpen("svc-force"))
+	state, err := brk.State("svc-force")
+	require.NoError(t, err)
+	require.Equal(t, StateForcedOpen, state)
+
+	for range 5 {
+		_, execErr := brk.Execute(ctx, "svc-force", ok)
+		require.ErrorIs(t, execErr, ErrOpenState)
+	}
+	require.Zero(t, calls, "强制打开后 fn 不应被调用")
+
+	_, err = brk.Execute(ctx, "svc-other", ok)
+	require.NoError(t, err, "其他 key 不受影响")
+
+	require.NoError(t, brk.Reset("svc-force"))
+	state, err = brk.State("svc-force")
+	require.NoError(t, err)
+	require.Equal(t, StateClosed, state)
+
+	result, err := brk.Execute(ctx, "svc-force", ok)
+	require.NoError(t, err)
+	require.Equal(t, "ok", result)
+
+	// Reset 后恢复按统计熔断
+	testErr := errors.New("downstream failed")
+	for range 2 {
+		_, execErr := brk.Execute(ctx, "svc-force", func() (any, error) { return nil, testErr })
+		require.ErrorIs(t, execErr, testErr)
+	}
+	state, err = brk.State("svc-force")
+	require.NoError(t, err)
+	require.Equal(t, StateOpen, state)
+}
+
+func TestForceOpen_UsesFallback(t *testing.T) {
+	var rejection error
+	brk, err := New(nil, WithFallback(func(ctx context.Context, key string, err error) error {
+		rejection = err
+		return nil
+	}))
+	require.NoError(t, err)
+
+	require.NoError(t, brk.ForceOpen("svc-fallback"))
+	result, err := brk.Execute(context.Background(), "svc-fallback", func() (any, error) {
+		return "unexpected", nil
+	})
+	require.NoError(t, err)
+	require.Nil(t, result)
+	require.ErrorIs(t, rejection, ErrOpenState)
+}
+
+func TestForceClose_DisablesBreaking(t *testing.T) {
+	brk, err := New(&Config{
+		Timeout:         time.Minute,
+		FailureRatio:    0.5,
+		MinimumRequests: 2,
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	testErr := errors.New("downstream failed")
+	for range 2 {
+		_, _ = brk.Execute(ctx, "svc-debug", func() (any, error) { return nil, testErr })
+	}
+	state, err := brk.State("svc-debug")
+	require.NoError(t, err)
+	require.Equal(t, StateOpen, state)
+
+	require.NoError(t, brk.ForceClose("svc-debug"))
+	state, err = brk.State("svc-debug")
+	require.NoError(t, err)
+	require.Equal(t, StateForcedClosed, state)
+
+	for range 5 {
+		_, execErr := brk.Execute(ctx, "svc-debug", func() (any, error) { return nil, testErr })
+		require.ErrorIs(t, execErr, testErr, "强制关闭后错误原样返回，不被拒绝")
+	}
+
+	require.NoError(t, brk.Reset("svc-debug"))
+	state, err = brk.State("svc-debug")
+	require.NoError(t, err)
+	require.Equal(t, StateClosed, state, "Reset 清空之前的统计")
+}
+
+func TestForce_EmptyKey(t *testing.T) {
+	brk, err := New(nil)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, brk.ForceOpen(""), ErrKeyEmpty)
+	require.ErrorIs(t, brk.ForceClose(""), ErrKeyEmpty)
+	require.ErrorIs(t, brk.Reset(""), ErrKeyEmpty)
+}
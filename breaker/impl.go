@@ -3,6 +3,7 @@ package breaker
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/xerrors"
@@ -17,7 +18,18 @@ type circuitBreaker struct {
 	fallback FallbackFunc
 
 	// 服务级熔断器管理
-	breakers sync.Map // map[string]*gobreaker.CircuitBreaker[interface{}]
+	breakers sync.Map // map[string]*keyBreaker
+
+	// 手动强制状态，优先于统计结果
+	forced sync.Map // map[string]State（StateForcedOpen / StateForcedClosed）
+
+	now func() time.Time // 渐进恢复使用的时钟，测试中可替换
+}
+
+// keyBreaker 单个熔断键的 gobreaker 实例及其渐进恢复状态
+type keyBreaker struct {
+	cb       *gobreaker.CircuitBreaker[any]
+	recovery *recovery // 未配置 RecoveryStrategy 时为 nil
 }
 
 // newBreaker 创建熔断器实例（内部函数）
@@ -31,6 +43,7 @@ func newBreaker(
 		cfg:      cfg,
 		logger:   logger,
 		fallback: fallback,
+		now:      time.Now,
 	}
 
 	logger.Info("circuit breaker created",
@@ -48,30 +61,88 @@ func (cb *circuitBreaker) Execute(ctx context.Context, key string, fn func() (an
 		return nil, ErrKeyEmpty
 	}
 
+	switch cb.forcedState(key) {
+	case StateForcedOpen:
+		return cb.reject(ctx, key, xerrors.Wrap(ErrOpenState, "forced open"))
+	case StateForcedClosed:
+		return fn()
+	}
+
 	// 获取或创建熔断器
 	breaker := cb.getOrCreateBreaker(key)
 
+	// 渐进恢复期间按当前阶梯比例限流
+	if breaker.recovery != nil && !breaker.recovery.admit(cb.now()) {
+		return cb.reject(ctx, key, xerrors.Wrap(ErrTooManyRequests, "recovering"))
+	}
+
 	// 执行熔断保护的函数
-	result, err := breaker.Execute(fn)
+	result, err := breaker.cb.Execute(fn)
 
 	rejectionErr, rejected := mapBreakerError(err)
 	if rejected {
-		cb.logger.Info("Circuit breaker rejected request",
-			clog.String("key", key),
-			clog.Error(rejectionErr))
-
-		if cb.fallback != nil {
-			fallbackErr := cb.fallback(ctx, key, rejectionErr)
-			if fallbackErr == nil {
-				return nil, nil
-			}
-			return nil, fallbackErr
+		return cb.reject(ctx, key, rejectionErr)
+	}
+
+	return result, err
+}
+
+// reject 处理被熔断器拒绝的请求，配置了 Fallback 时交由 Fallback 处理
+func (cb *circuitBreaker) reject(ctx context.Context, key string, rejectionErr error) (any, error) {
+	cb.logger.Info("Circuit breaker rejected request",
+		clog.String("key", key),
+		clog.Error(rejectionErr))
+
+	if cb.fallback != nil {
+		fallbackErr := cb.fallback(ctx, key, rejectionErr)
+		if fallbackErr == nil {
+			return nil, nil
 		}
+		return nil, fallbackErr
+	}
+
+	return nil, rejectionErr
+}
+
+// ForceOpen 强制打开指定键的熔断器
+func (cb *circuitBreaker) ForceOpen(key string) error {
+	return cb.force(key, StateForcedOpen)
+}
+
+// ForceClose 强制关闭指定键的熔断器
+func (cb *circuitBreaker) ForceClose(key string) error {
+	return cb.force(key, StateForcedClosed)
+}
 
-		return nil, rejectionErr
+// force 设置强制状态
+func (cb *circuitBreaker) force(key string, state State) error {
+	if key == "" {
+		return ErrKeyEmpty
 	}
+	cb.forced.Store(key, state)
+	cb.logger.Warn("circuit breaker forced",
+		clog.String("key", key),
+		clog.String("state", state.String()))
+	return nil
+}
 
-	return result, err
+// Reset 解除强制状态并丢弃已有统计，下次请求时按配置重新创建熔断器
+func (cb *circuitBreaker) Reset(key string) error {
+	if key == "" {
+		return ErrKeyEmpty
+	}
+	cb.forced.Delete(key)
+	cb.breakers.Delete(key)
+	cb.logger.Info("circuit breaker reset", clog.String("key", key))
+	return nil
+}
+
+// forcedState 返回指定键的强制状态，未强制时返回 StateClosed
+func (cb *circuitBreaker) forcedState(key string) State {
+	if val, ok := cb.forced.Load(key); ok {
+		return val.(State)
+	}
+	return StateClosed
 }
 
 // State 获取指定键的熔断器状态
@@ -80,16 +151,23 @@ func (cb *circuitBreaker) State(key string) (State, error) {
 		return StateClosed, ErrKeyEmpty
 	}
 
+	if state := cb.forcedState(key); state != StateClosed {
+		return state, nil
+	}
+
 	val, ok := cb.breakers.Load(key)
 	if !ok {
 		return StateClosed, nil
 	}
 
-	breaker := val.(*gobreaker.CircuitBreaker[any])
-	state := breaker.State()
+	breaker := val.(*keyBreaker)
+	state := breaker.cb.State()
 
 	switch state {
 	case gobreaker.StateClosed:
+		if breaker.recovery != nil && breaker.recovery.active(cb.now()) {
+			return StateRecovering, nil
+		}
 		return StateClosed, nil
 	case gobreaker.StateHalfOpen:
 		return StateHalfOpen, nil
@@ -101,10 +179,15 @@ func (cb *circuitBreaker) State(key string) (State, error) {
 }
 
 // getOrCreateBreaker 获取或创建指定键的熔断器
-func (cb *circuitBreaker) getOrCreateBreaker(key string) *gobreaker.CircuitBreaker[any] {
+func (cb *circuitBreaker) getOrCreateBreaker(key string) *keyBreaker {
 	val, ok := cb.breakers.Load(key)
 	if ok {
-		return val.(*gobreaker.CircuitBreaker[any])
+		return val.(*keyBreaker)
+	}
+
+	breaker := &keyBreaker{}
+	if cb.cfg.RecoveryStrategy != nil {
+		breaker.recovery = &recovery{strategy: cb.cfg.RecoveryStrategy}
 	}
 
 	// 创建新的熔断器
@@ -113,17 +196,31 @@ func (cb *circuitBreaker) getOrCreateBreaker(key string) *gobreaker.CircuitBreak
 		MaxRequests: cb.cfg.MaxRequests,
 		Interval:    cb.cfg.Interval,
 		Timeout:     cb.cfg.Timeout,
-		ReadyToTrip: cb.readyToTrip,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			// 恢复期内任意失败立即重新熔断
+			if breaker.recovery != nil && counts.TotalFailures > 0 && breaker.recovery.active(cb.now()) {
+				return true
+			}
+			return cb.readyToTrip(counts)
+		},
 		OnStateChange: func(name string, from, to gobreaker.State) {
+			if breaker.recovery != nil {
+				switch {
+				case from == gobreaker.StateHalfOpen && to == gobreaker.StateClosed:
+					breaker.recovery.start(cb.now())
+				case to == gobreaker.StateOpen:
+					breaker.recovery.stop()
+				}
+			}
 			cb.onStateChange(name, from, to)
 		},
 	}
 
-	breaker := gobreaker.NewCircuitBreaker[any](settings)
+	breaker.cb = gobreaker.NewCircuitBreaker[any](settings)
 
 	// 存储熔断器（可能有并发创建，使用 LoadOrStore）
 	actual, _ := cb.breakers.LoadOrStore(key, breaker)
-	return actual.(*gobreaker.CircuitBreaker[any])
+	return actual.(*keyBreaker)
 }
 
 // readyToTrip 判断是否应该触发熔断
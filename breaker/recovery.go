@@ -0,0 +1,119 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// 渐进恢复默认值
+const (
+	defaultRecoveryStepDuration = 10 * time.Second
+)
+
+// defaultRecoverySteps 默认放行比例阶梯：10% → 50% → 全部放行
+var defaultRecoverySteps = []float64{0.1, 0.5}
+
+// RecoveryStrategy 半开探测成功后的渐进恢复策略
+//
+// 探测成功后熔断器不立即全开，而是按 Steps 依次放大允许通过的流量比例，每一阶持续
+// StepDuration，全部阶梯走完后才完全放行。恢复期间：
+//   - 超出当前比例的请求被拒绝，返回 ErrTooManyRequests（Fallback 同样生效）；
+//   - 任意一次失败立即重新熔断（回到 open），不受 MinimumRequests / FailureRatio 限制；
+//   - State 返回 StateRecovering。
+type RecoveryStrategy struct {
+	// Steps 放行比例阶梯，取值 (0, 1) 且严格递增（默认：[0.1, 0.5]）
+	Steps []float64 `json:"steps" yaml:"steps"`
+
+	// StepDuration 每一阶的持续时间（默认：10s）
+	StepDuration time.Duration `json:"step_duration" yaml:"step_duration"`
+}
+
+// validate 验证恢复策略并设置默认值（内部使用）
+func (s *RecoveryStrategy) validate() error {
+	if len(s.Steps) == 0 {
+		s.Steps = defaultRecoverySteps
+	}
+	for i, ratio := range s.Steps {
+		if ratio <= 0 || ratio >= 1 {
+			return xerrors.Wrap(ErrInvalidConfig, "recovery steps must be within (0, 1)")
+		}
+		if i > 0 && ratio <= s.Steps[i-1] {
+			return xerrors.Wrap(ErrInvalidConfig, "recovery steps must be strictly increasing")
+		}
+	}
+	if s.StepDuration == 0 {
+		s.StepDuration = defaultRecoveryStepDuration
+	}
+	if s.StepDuration < 0 {
+		return xerrors.Wrap(ErrInvalidConfig, "recovery step_duration must be greater than 0")
+	}
+	return nil
+}
+
+// recovery 单个熔断键的渐进恢复状态
+type recovery struct {
+	strategy *RecoveryStrategy
+
+	mu       sync.Mutex
+	since    time.Time // 进入恢复期的时间，零值表示不在恢复期
+	step     int       // 当前阶梯下标
+	total    uint64    // 当前阶梯内的请求数
+	admitted uint64    // 当前阶梯内放行的请求数
+}
+
+// start 半开探测成功、熔断器关闭时进入恢复期
+func (r *recovery) start(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.since = now
+	r.step, r.total, r.admitted = 0, 0, 0
+}
+
+// stop 重新熔断时退出恢复期
+func (r *recovery) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.since = time.Time{}
+}
+
+// active 判断当前是否处于恢复期，阶梯走完时自动退出
+func (r *recovery) active(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.advance(now)
+}
+
+// admit 按当前阶梯的比例决定是否放行请求，不在恢复期时一律放行
+//
+// 采用确定性的配额计数而非随机数：放行数始终不超过 比例 × 请求数，流量更平滑且便于验证。
+func (r *recovery) admit(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.advance(now) {
+		return true
+	}
+	r.total++
+	if float64(r.admitted) < r.strategy.Steps[r.step]*float64(r.total) {
+		r.admitted++
+		return true
+	}
+	return false
+}
+
+// advance 根据经过的时间推进阶梯，返回是否仍在恢复期（调用方持有锁）
+func (r *recovery) advance(now time.Time) bool {
+	if r.since.IsZero() {
+		return false
+	}
+	step := int(now.Sub(r.since) / r.strategy.StepDuration)
+	if step >= len(r.strategy.Steps) {
+		r.since = time.Time{}
+		return false
+	}
+	if step != r.step {
+		r.step, r.total, r.admitted = step, 0, 0
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// newRecoveringBreaker 创建配置了渐进恢复的熔断器，并让 key 进入恢复期
+func newRecoveringBreaker(t *testing.T, key string) (Breaker, *time.Time) {
+	t.Helper()
+
+	brk, err := New(&Config{
+		MaxRequests:     1,
+		Timeout:         50 * time.Millisecond,
+		FailureRatio:    0.5,
+		MinimumRequests: 1,
+		RecoveryStrategy: &RecoveryStrategy{
+			Steps:        []float64{0.1, 0.5},
+			StepDuration: time.Minute,
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	clock := time.Now()
+	brk.(*circuitBreaker).now = func() time.Time { return clock }
+
+	ctx := context.Background()
+	_, _ = brk.Execute(ctx, key, func() (any, error) { return nil, errors.New("boom") })
+	if state, _ := brk.State(key); state != StateOpen {
+		t.Fatalf("state = %v, want open", state)
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if _, err := brk.Execute(ctx, key, func() (any, error) { return "ok", nil }); err != nil {
+		t.Fatalf("half-open probe error = %v", err)
+	}
+	if state, _ := brk.State(key); state != StateRecovering {
+		t.Fatalf("state after probe = %v, want recovering", state)
+	}
+	return brk, &clock
+}
+
+// admitted 连续发起 n 次成功请求，返回被放行的次数
+func admitted(t *testing.T, brk Breaker, key string, n int) int {
+	t.Helper()
+	count := 0
+	for range n {
+		_, err := brk.Execute(context.Background(), key, func() (any, error) { return nil, nil })
+		switch {
+		case err == nil:
+			count++
+		case !errors.Is(err, ErrTooManyRequests):
+			t.Fatalf("Execute() error = %v, want nil or ErrTooManyRequests", err)
+		}
+	}
+	return count
+}
+
+// TestRecoveryStrategyRampsUp 测试探测成功后按阶梯放大流量
+func TestRecoveryStrategyRampsUp(t *testing.T) {
+	brk, clock := newRecoveringBreaker(t, "svc")
+
+	if got := admitted(t, brk, "svc", 100); got != 10 {
+		t.Fatalf("step 1 admitted = %d, want 10", got)
+	}
+
+	*clock = clock.Add(time.Minute)
+	if got := admitted(t, brk, "svc", 100); got != 50 {
+		t.Fatalf("step 2 admitted = %d, want 50", got)
+	}
+
+	*clock = clock.Add(time.Minute)
+	if got := admitted(t, brk, "svc", 100); got != 100 {
+		t.Fatalf("after recovery admitted = %d, want 100", got)
+	}
+	if state, _ := brk.State("svc"); state != StateClosed {
+		t.Fatalf("state = %v, want closed", state)
+	}
+}
+
+// TestRecoveryStrategyFailureReopens 测试恢复期间失败立即重新熔断
+func TestRecoveryStrategyFailureReopens(t *testing.T) {
+	brk, clock := newRecoveringBreaker(t, "svc")
+	*clock = clock.Add(time.Minute) // 进入 50% 阶梯，保证下一次请求被放行
+
+	_, err := brk.Execute(context.Background(), "svc", func() (any, error) { return nil, errors.New("boom") })
+	if err == nil || errors.Is(err, ErrTooManyRequests) {
+		t.Fatalf("Execute() error = %v, want downstream error", err)
+	}
+	if state, _ := brk.State("svc"); state != StateOpen {
+		t.Fatalf("state = %v, want open", state)
+	}
+	if _, err := brk.Execute(context.Background(), "svc", func() (any, error) { return nil, nil }); !errors.Is(err, ErrOpenState) {
+		t.Fatalf("Execute() error = %v, want ErrOpenState", err)
+	}
+}
+
+// TestRecoveryStrategyValidate 测试恢复策略配置校验
+func TestRecoveryStrategyValidate(t *testing.T) {
+	cfg := &Config{RecoveryStrategy: &RecoveryStrategy{}}
+	if _, err := New(cfg); err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if len(cfg.RecoveryStrategy.Steps) != 2 || cfg.RecoveryStrategy.StepDuration != 10*time.Second {
+		t.Fatalf("defaults not applied: %+v", cfg.RecoveryStrategy)
+	}
+
+	for _, s := range []*RecoveryStrategy{
+		{Steps: []float64{0}},
+		{Steps: []float64{1}},
+		{Steps: []float64{0.5, 0.2}},
+		{Steps: []float64{0.5}, StepDuration: -time.Second},
+	} {
+		if _, err := New(&Config{RecoveryStrategy: s}); !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("New(%+v) error = %v, want ErrInvalidConfig", s, err)
+		}
+	}
+}
@@ -1,15 +1,20 @@
 // Package cache 提供 Genesis L2 业务层的缓存组件族，支持分布式缓存、本地缓存和多级缓存。
 //
 // 组件分类：
-//   - Distributed: 基于 Redis 的分布式缓存，支持 KV / Hash / Sorted Set / Batch。
+//   - Distributed: 基于 Redis 的分布式缓存，支持 KV / Hash / Sorted Set / Batch / HyperLogLog / Semaphore。
 //   - Local: 基于进程内存的本地缓存，提供稳定的 KV 语义。
 //   - Multi: 组合 Local + Distributed 的两级缓存。
 //
 // 语义约定：
 //   - Get 等读取操作未命中时返回 ErrMiss。
 //   - Has 不返回 ErrMiss，而是通过 bool 表达存在性。
+//   - 缓存值无法反序列化时默认返回错误，WithOnDeserializeError 可改为视为未命中（可选删除坏数据）。
 //   - Set 和 Expire 在 ttl<=0 时使用组件配置中的 DefaultTTL。
-//   - Local 与 Multi 仅提供 KV 能力；Hash、Sorted Set、Batch 仅由 Distributed 提供。
+//   - TTL 对永不过期的 key 返回 TTLPersistent（-1），对不存在的 key 返回 TTLNotFound（-2）。
+//   - Local 与 Multi 仅提供 KV 能力；TTL 查询、延迟双删、Hash、Sorted Set、Batch、CAS、Tag、HyperLogLog、GetOrSet / MGetOrSet、Semaphore 仅由 Distributed 提供。
+//   - 带附加语义的写入以独立方法提供（SetWithCAS、SetWithTags），而不是给 Set 增加可变参数选项：
+//     Set 属于 Local / Multi / Distributed 共享的 KV，改签名会波及所有实现，也会让 cache 不再满足
+//     db.QueryCache 等按 Set(ctx, key, value, ttl) 声明的消费方接口。
 //   - RawClient 用于 Pipeline、Lua 脚本等高级场景，不保证跨后端兼容。
 //
 // 示例：
@@ -35,6 +40,14 @@ import (
 	"github.com/ceyewan/genesis/xerrors"
 )
 
+// TTL 查询结果中的约定值，与 Redis PTTL 的返回值保持一致。
+const (
+	// TTLPersistent 表示 key 存在但未设置过期时间。
+	TTLPersistent time.Duration = -1
+	// TTLNotFound 表示 key 不存在。
+	TTLNotFound time.Duration = -2
+)
+
 // KV 定义缓存组件的稳定 KV 能力。
 //
 // 这是 Local、Distributed 和 Multi 共享的最小公共语义。调用方可以依赖如下约定：
@@ -59,7 +72,7 @@ type KV interface {
 
 // Distributed 定义分布式缓存能力。
 //
-// 当前唯一实现基于 Redis。除 KV 语义外，Distributed 还提供 Hash、Sorted Set、Batch 和 RawClient 等 Redis 导向能力。
+// 当前唯一实现基于 Redis。除 KV 语义外，Distributed 还提供 Hash、Sorted Set、Batch、CAS、Tag、HyperLogLog、Semaphore 和 RawClient 等 Redis 导向能力。
 type Distributed interface {
 	KV
 	// HSet 设置 Hash 字段。
@@ -88,6 +101,37 @@ type Distributed interface {
 	MGet(ctx context.Context, keys []string, destSlice any) error
 	// MSet 批量设置多个 key-value。
 	MSet(ctx context.Context, items map[string]any, ttl time.Duration) error
+	// MGetOrSet 批量读取多个 key，未命中的 key 合并为一次 load 回源并写回缓存（ttl 语义同 Set）。
+	// destSlice 与 keys 按下标对齐，回源结果中不存在的 key 保持元素零值。
+	MGetOrSet(ctx context.Context, keys []string, destSlice any, ttl time.Duration, load MLoadFunc) error
+	// TTL 返回 key 的剩余存活时间；永不过期返回 TTLPersistent，key 不存在返回 TTLNotFound。
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// ExpireBatch 通过 Pipeline 批量更新多个 key 的 TTL（语义同 Expire），不存在的 key 被忽略。
+	ExpireBatch(ctx context.Context, keys []string, ttl time.Duration) error
+	// GetWithVersion 读取带版本号的缓存值；未命中时返回 ErrMiss，version 为 0。
+	GetWithVersion(ctx context.Context, key string, dest any) (int64, error)
+	// SetWithCAS 仅当当前版本等于 expectedVersion 时写入并递增版本；expectedVersion=0 表示 key 必须不存在。
+	// 版本不匹配返回 ok=false，调用方应重新 GetWithVersion 后重试。
+	SetWithCAS(ctx context.Context, key string, value any, expectedVersion int64, ttl time.Duration) (bool, error)
+	// SetWithTags 设置缓存值，并把 key 记入各 tag 的集合，供 InvalidateByTag 批量失效。
+	// 与 SetWithCAS 一样作为独立方法提供，KV.Set 的签名保持不变。
+	SetWithTags(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) error
+	// InvalidateByTag 删除 tag 集合中记录的所有 key 以及集合本身，tag 不存在时不视为错误。
+	InvalidateByTag(ctx context.Context, tag string) error
+	// PFAdd 向 HyperLogLog 中加入元素，用于 UV 等基数统计。
+	PFAdd(ctx context.Context, key string, items ...string) error
+	// PFCount 返回一个或多个 HyperLogLog 并集的基数估算值，标准误差约 0.81%。
+	PFCount(ctx context.Context, keys ...string) (int64, error)
+	// PFMerge 将多个 HyperLogLog 合并写入 dest。
+	PFMerge(ctx context.Context, dest string, keys ...string) error
+	// GetOrSet 读取 key，未命中时调用 load 回源并写回缓存（ttl 语义同 Set）。
+	// 返回的 stale 为 true 表示 Redis 读取失败、dest 来自 WithStaleOnError 保存的本地旧值。
+	// 传入 WithDistributedLock 时由分布式锁保证集群内同一 key 只有一个实例回源。
+	GetOrSet(ctx context.Context, key string, dest any, ttl time.Duration, load LoadFunc, opts ...GetOrSetOption) (stale bool, err error)
+	// DelayedDoubleDelete 立即删除 key，并在 delay 后于后台再删除一次，用于缩小"更新 DB 后删缓存"时并发读回填旧值的窗口。
+	DelayedDoubleDelete(ctx context.Context, key string, delay time.Duration) error
+	// Semaphore 返回跨实例共享的分布式信号量，最多允许 max 个持有者同时持有令牌。
+	Semaphore(name string, max int, opts ...SemaphoreOption) Semaphore
 	// RawClient 返回底层客户端，用于 Pipeline、Lua 脚本等高级场景。
 	RawClient() any
 }
@@ -128,10 +172,13 @@ func NewDistributed(cfg *DistributedConfig, opts ...Option) (Distributed, error)
 	if opt.RedisConn == nil {
 		return nil, ErrRedisConnectorRequired
 	}
+	if err := opt.OnDeserializeError.validate(); err != nil {
+		return nil, err
+	}
 
 	switch cfg.Driver {
 	case DriverRedis:
-		return newRedis(opt.RedisConn, cfg, opt.Logger, opt.Meter)
+		return newRedis(opt.RedisConn, cfg, opt.Logger, opt.Meter, opt.OnDeserializeError)
 	default:
 		return nil, xerrors.New("cache: unsupported distributed driver: " + string(cfg.Driver))
 	}
@@ -151,7 +198,10 @@ func NewLocal(cfg *LocalConfig, opts ...Option) (Local, error) {
 	}
 
 	opt := buildOptions(opts...)
-	return newLocal(cfg, opt.Logger, opt.Meter)
+	if err := opt.OnDeserializeError.validate(); err != nil {
+		return nil, err
+	}
+	return newLocal(cfg, opt.Logger, opt.Meter, opt.OnDeserializeError)
 }
 
 // NewMulti 根据配置创建多级缓存实例。
@@ -131,6 +131,17 @@ func (m *mockDistributed) Expire(ctx context.Context, key string, ttl time.Durat
 	return ok, nil
 }
 
+func (m *mockDistributed) TTL(ctx context.Context, key string) (time.Duration, error) {
+	if _, ok := m.data[key]; !ok {
+		return TTLNotFound, nil
+	}
+	return TTLPersistent, nil
+}
+
+func (m *mockDistributed) ExpireBatch(ctx context.Context, keys []string, ttl time.Duration) error {
+	return nil
+}
+
 func (m *mockDistributed) Close() error { return nil }
 func (m *mockDistributed) HSet(ctx context.Context, key, field string, value any) error {
 	return ErrNotSupported
@@ -183,4 +194,48 @@ func (m *mockDistributed) MGet(ctx context.Context, keys []string, destSlice any
 func (m *mockDistributed) MSet(ctx context.Context, items map[string]any, ttl time.Duration) error {
 	return ErrNotSupported
 }
+
+func (m *mockDistributed) GetWithVersion(ctx context.Context, key string, dest any) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+func (m *mockDistributed) SetWithCAS(ctx context.Context, key string, value any, expectedVersion int64, ttl time.Duration) (bool, error) {
+	return false, ErrNotSupported
+}
+
+func (m *mockDistributed) SetWithTags(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) error {
+	return ErrNotSupported
+}
+
+func (m *mockDistributed) InvalidateByTag(ctx context.Context, tag string) error {
+	return ErrNotSupported
+}
+
+func (m *mockDistributed) PFAdd(ctx context.Context, key string, items ...string) error {
+	return ErrNotSupported
+}
+
+func (m *mockDistributed) PFCount(ctx context.Context, keys ...string) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+func (m *mockDistributed) PFMerge(ctx context.Context, dest string, keys ...string) error {
+	return ErrNotSupported
+}
+
+func (m *mockDistributed) Semaphore(name string, max int, opts ...SemaphoreOption) Semaphore {
+	return nil
+}
+
+func (m *mockDistributed) DelayedDoubleDelete(ctx context.Context, key string, delay time.Duration) error {
+	return ErrNotSupported
+}
+
+func (m *mockDistributed) MGetOrSet(ctx context.Context, keys []string, destSlice any, ttl time.Duration, load MLoadFunc) error {
+	return ErrNotSupported
+}
+
+func (m *mockDistributed) GetOrSet(ctx context.Context, key string, dest any, ttl time.Duration, load LoadFunc, opts ...GetOrSetOption) (bool, error) {
+	return false, ErrNotSupported
+}
 func (m *mockDistributed) RawClient() any { return nil }
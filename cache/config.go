@@ -33,6 +33,13 @@ type DistributedConfig struct {
 
 	// DefaultTTL 默认 TTL，当 Set 或 Expire 传入 ttl<=0 时使用。默认 24 小时。
 	DefaultTTL time.Duration `json:"default_ttl" yaml:"default_ttl"`
+
+	// TTLJitter TTL 随机抖动上限。大于 0 时 Set / MSet 实际写入的 TTL 在 [ttl, ttl+TTLJitter]
+	// 内随机，用于分散大量 key 的过期时刻，防止缓存雪崩。默认 0 表示不抖动。
+	TTLJitter time.Duration `json:"ttl_jitter" yaml:"ttl_jitter"`
+
+	// StaleSnapshotSize GetOrSet 旧值兜底（WithStaleOnError）在进程内保存的快照数量上限。默认 10000。
+	StaleSnapshotSize int `json:"stale_snapshot_size" yaml:"stale_snapshot_size"`
 }
 
 // LocalConfig 本地缓存配置。
@@ -48,6 +55,9 @@ type LocalConfig struct {
 
 	// DefaultTTL 默认 TTL，当 Set 或 Expire 传入 ttl<=0 时使用。默认 1 小时。
 	DefaultTTL time.Duration `json:"default_ttl" yaml:"default_ttl"`
+
+	// TTLJitter TTL 随机抖动上限，语义同 DistributedConfig.TTLJitter。默认 0 表示不抖动。
+	TTLJitter time.Duration `json:"ttl_jitter" yaml:"ttl_jitter"`
 }
 
 // MultiConfig 多级缓存配置。
@@ -76,12 +86,18 @@ func (c *DistributedConfig) setDefaults() {
 	if c.DefaultTTL <= 0 {
 		c.DefaultTTL = 24 * time.Hour
 	}
+	if c.StaleSnapshotSize <= 0 {
+		c.StaleSnapshotSize = defaultStaleSnapshotSize
+	}
 }
 
 func (c *DistributedConfig) validate() error {
 	if c == nil {
 		return xerrors.New("cache: distributed config is nil")
 	}
+	if c.TTLJitter < 0 {
+		return xerrors.New("cache: ttl_jitter must be greater than or equal to 0")
+	}
 	switch c.Driver {
 	case DriverRedis:
 		return nil
@@ -112,6 +128,9 @@ func (c *LocalConfig) validate() error {
 	if c == nil {
 		return xerrors.New("cache: local config is nil")
 	}
+	if c.TTLJitter < 0 {
+		return xerrors.New("cache: ttl_jitter must be greater than or equal to 0")
+	}
 	switch c.Driver {
 	case DriverOtter:
 		return nil
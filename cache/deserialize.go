@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// DeserializeErrorStrategy 缓存值无法反序列化到 dest 时的处理策略。
+//
+// 常见于结构体演进后读到旧格式数据，策略只作用于 Get、MGet、MGetOrSet 以及基于 Get 的 GetOrSet。
+type DeserializeErrorStrategy string
+
+const (
+	// DeserializeErrorReturn 直接返回反序列化错误（默认）。
+	DeserializeErrorReturn DeserializeErrorStrategy = "error"
+	// DeserializeErrorTreatAsMiss 视为未命中：Get 返回 ErrMiss，GetOrSet / MGetOrSet 回源后覆盖坏数据。
+	DeserializeErrorTreatAsMiss DeserializeErrorStrategy = "treat_as_miss"
+	// DeserializeErrorDeleteAndMiss 先删除坏数据再视为未命中，删除失败只记录日志。
+	DeserializeErrorDeleteAndMiss DeserializeErrorStrategy = "delete_and_miss"
+)
+
+func (s DeserializeErrorStrategy) validate() error {
+	switch s {
+	case "", DeserializeErrorReturn, DeserializeErrorTreatAsMiss, DeserializeErrorDeleteAndMiss:
+		return nil
+	default:
+		return xerrors.New("cache: unsupported deserialize error strategy: " + string(s))
+	}
+}
+
+// deserializeFallback 按策略处理反序列化失败（内部使用）
+type deserializeFallback struct {
+	strategy DeserializeErrorStrategy
+	logger   clog.Logger
+}
+
+// asMiss 报告反序列化失败的 key 是否按未命中处理
+func (f deserializeFallback) asMiss() bool {
+	return f.strategy == DeserializeErrorTreatAsMiss || f.strategy == DeserializeErrorDeleteAndMiss
+}
+
+// handle 处理 keys 的反序列化失败：按 error 策略原样返回 err，否则按需调用 del 删除坏数据后返回 ErrMiss
+func (f deserializeFallback) handle(ctx context.Context, keys []string, err error, del func(ctx context.Context, keys []string) error) error {
+	if !f.asMiss() {
+		return err
+	}
+	f.logger.WarnContext(ctx, "Cache deserialize failed, treated as miss",
+		clog.Any("keys", keys),
+		clog.String("strategy", string(f.strategy)),
+		clog.Error(err))
+	if f.strategy == DeserializeErrorDeleteAndMiss {
+		if derr := del(ctx, keys); derr != nil {
+			f.logger.WarnContext(ctx, "Cache delete undecodable value failed", clog.Any("keys", keys), clog.Error(derr))
+		}
+	}
+	return ErrMiss
+}
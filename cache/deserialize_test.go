@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+// legacyValue 旧格式数据：JSON 字符串无法反序列化到 staleUser
+const legacyValue = "legacy"
+
+func TestOnDeserializeError(t *testing.T) {
+	ctx := context.Background()
+
+	newLocal := func(t *testing.T, strategy DeserializeErrorStrategy) Local {
+		local, err := NewLocal(&LocalConfig{}, WithOnDeserializeError(strategy))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = local.Close() })
+		require.NoError(t, local.Set(ctx, "user:1", legacyValue, time.Minute))
+		return local
+	}
+
+	t.Run("error 返回反序列化错误并保留数据", func(t *testing.T) {
+		local := newLocal(t, DeserializeErrorReturn)
+		var got staleUser
+		err := local.Get(ctx, "user:1", &got)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrMiss)
+
+		ok, _ := local.Has(ctx, "user:1")
+		require.True(t, ok)
+	})
+
+	t.Run("treat_as_miss 返回 ErrMiss 并保留数据", func(t *testing.T) {
+		local := newLocal(t, DeserializeErrorTreatAsMiss)
+		var got staleUser
+		require.ErrorIs(t, local.Get(ctx, "user:1", &got), ErrMiss)
+
+		ok, _ := local.Has(ctx, "user:1")
+		require.True(t, ok)
+	})
+
+	t.Run("delete_and_miss 删除坏数据后返回 ErrMiss", func(t *testing.T) {
+		local := newLocal(t, DeserializeErrorDeleteAndMiss)
+		var got staleUser
+		require.ErrorIs(t, local.Get(ctx, "user:1", &got), ErrMiss)
+
+		ok, _ := local.Has(ctx, "user:1")
+		require.False(t, ok)
+	})
+
+	t.Run("treat_as_miss 时 GetOrSet 回源覆盖坏数据", func(t *testing.T) {
+		local := newLocal(t, DeserializeErrorTreatAsMiss)
+		store, err := newStaleStore(local.(*localCache).serializer, clog.Discard(), 0)
+		require.NoError(t, err)
+		t.Cleanup(store.close)
+
+		var got staleUser
+		stale, err := store.getOrSet(ctx, local, "user:1", &got, time.Minute, func(ctx context.Context) (any, error) {
+			return staleUser{Name: "alice"}, nil
+		})
+		require.NoError(t, err)
+		require.False(t, stale)
+		require.Equal(t, "alice", got.Name)
+
+		var again staleUser
+		require.NoError(t, local.Get(ctx, "user:1", &again), "坏数据已被新值覆盖")
+		require.Equal(t, "alice", again.Name)
+	})
+
+	t.Run("无效策略", func(t *testing.T) {
+		_, err := NewLocal(&LocalConfig{}, WithOnDeserializeError("ignore"))
+		require.Error(t, err)
+	})
+}
+
+func TestMGetOrSetOnDeserializeError(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) *fakeBatchKV {
+		kv := newFakeBatchKV(t)
+		require.NoError(t, kv.MSet(ctx, map[string]any{
+			"user:1": staleUser{Name: "alice"},
+			"user:2": legacyValue,
+			"user:3": legacyValue,
+		}, time.Minute))
+		return kv
+	}
+	// load 只能回源 user:2，user:3 在数据源中已不存在
+	load := func(ctx context.Context, missing []string) (map[string]any, error) {
+		return map[string]any{"user:2": staleUser{Name: "bob"}}, nil
+	}
+	keys := []string{"user:1", "user:2", "user:3"}
+
+	t.Run("error", func(t *testing.T) {
+		kv := setup(t)
+		var got []*staleUser
+		fallback := deserializeFallback{strategy: DeserializeErrorReturn, logger: clog.Discard()}
+		require.Error(t, mgetOrSet(ctx, kv, kv.s, clog.Discard(), fallback, keys, &got, time.Minute, load))
+	})
+
+	for _, strategy := range []DeserializeErrorStrategy{DeserializeErrorTreatAsMiss, DeserializeErrorDeleteAndMiss} {
+		t.Run(string(strategy), func(t *testing.T) {
+			kv := setup(t)
+			var got []*staleUser
+			fallback := deserializeFallback{strategy: strategy, logger: clog.Discard()}
+			require.NoError(t, mgetOrSet(ctx, kv, kv.s, clog.Discard(), fallback, keys, &got, time.Minute, load))
+
+			require.Len(t, got, 3)
+			require.Equal(t, "alice", got[0].Name)
+			require.Equal(t, "bob", got[1].Name, "坏数据视为未命中并回源")
+			require.Nil(t, got[2])
+			require.JSONEq(t, `{"name":"bob"}`, string(kv.data["user:2"]), "回源结果覆盖坏数据")
+
+			if strategy == DeserializeErrorDeleteAndMiss {
+				require.NotContains(t, kv.data, "user:3", "无法回源的坏数据已删除")
+			} else {
+				require.Contains(t, kv.data, "user:3")
+			}
+		})
+	}
+}
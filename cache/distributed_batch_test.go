@@ -133,3 +133,29 @@ func TestDistributed_Batch_Integration(t *testing.T) {
 		require.Equal(t, "updated", got["name"])
 	})
 }
+
+// TestDistributed_MGetOrSet_Integration 测试批量读取与未命中回填
+func TestDistributed_MGetOrSet_Integration(t *testing.T) {
+	cache := setupTestDistributed(t, "test:dist:mgetorset:")
+	ctx := context.Background()
+
+	require.NoError(t, cache.Set(ctx, "user:1", map[string]string{"name": "alice"}, time.Minute))
+
+	var missing []string
+	load := func(ctx context.Context, keys []string) (map[string]any, error) {
+		missing = append(missing, keys...)
+		return map[string]any{"user:2": map[string]string{"name": "bob"}}, nil
+	}
+
+	var got []map[string]string
+	require.NoError(t, cache.MGetOrSet(ctx, []string{"user:1", "user:2"}, &got, time.Minute, load))
+	require.Equal(t, []string{"user:2"}, missing)
+	require.Equal(t, "alice", got[0]["name"])
+	require.Equal(t, "bob", got[1]["name"])
+
+	// 回填后全部命中，不再回源
+	missing = nil
+	require.NoError(t, cache.MGetOrSet(ctx, []string{"user:1", "user:2"}, &got, time.Minute, load))
+	require.Empty(t, missing)
+	require.Equal(t, "bob", got[1]["name"])
+}
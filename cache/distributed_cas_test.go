@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type casCounter struct {
+	Value int `json:"value"`
+}
+
+// TestDistributed_CAS_Integration 测试基于版本号的乐观并发控制
+func TestDistributed_CAS_Integration(t *testing.T) {
+	cache := setupTestDistributed(t, "test:dist:cas:")
+	ctx := context.Background()
+
+	t.Run("GetWithVersion miss", func(t *testing.T) {
+		var got casCounter
+		version, err := cache.GetWithVersion(ctx, "missing", &got)
+		require.ErrorIs(t, err, ErrMiss)
+		require.Zero(t, version)
+	})
+
+	t.Run("create and update", func(t *testing.T) {
+		ok, err := cache.SetWithCAS(ctx, "counter:1", casCounter{Value: 1}, 0, time.Minute)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		var got casCounter
+		version, err := cache.GetWithVersion(ctx, "counter:1", &got)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), version)
+		require.Equal(t, 1, got.Value)
+
+		// 已存在的 key 不能再以版本 0 创建
+		ok, err = cache.SetWithCAS(ctx, "counter:1", casCounter{Value: 9}, 0, time.Minute)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = cache.SetWithCAS(ctx, "counter:1", casCounter{Value: 2}, version, time.Minute)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		version, err = cache.GetWithVersion(ctx, "counter:1", &got)
+		require.NoError(t, err)
+		require.Equal(t, int64(2), version)
+		require.Equal(t, 2, got.Value)
+	})
+
+	t.Run("concurrent writers on same version", func(t *testing.T) {
+		key := "counter:concurrent"
+		ok, err := cache.SetWithCAS(ctx, key, casCounter{Value: 0}, 0, time.Minute)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		var base casCounter
+		version, err := cache.GetWithVersion(ctx, key, &base)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		results := make([]bool, 2)
+		errs := make([]error, 2)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], errs[i] = cache.SetWithCAS(ctx, key, casCounter{Value: base.Value + 1}, version, time.Minute)
+			}(i)
+		}
+		wg.Wait()
+
+		require.NoError(t, errs[0])
+		require.NoError(t, errs[1])
+		require.NotEqual(t, results[0], results[1], "exactly one writer should succeed")
+
+		// 失败的一方重读后基于新版本重试
+		var current casCounter
+		latest, err := cache.GetWithVersion(ctx, key, &current)
+		require.NoError(t, err)
+		require.Equal(t, version+1, latest)
+
+		ok, err = cache.SetWithCAS(ctx, key, casCounter{Value: current.Value + 1}, latest, time.Minute)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		_, err = cache.GetWithVersion(ctx, key, &current)
+		require.NoError(t, err)
+		require.Equal(t, 2, current.Value)
+	})
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+// TestDistributed_OnDeserializeError_Integration 测试 Redis 缓存 Get / MGet 的反序列化失败策略
+func TestDistributed_OnDeserializeError_Integration(t *testing.T) {
+	redisConn := newRedisConnectorOrSkip(t)
+	ctx := context.Background()
+
+	setup := func(t *testing.T, strategy DeserializeErrorStrategy) Distributed {
+		prefix := "test:dist:deserialize:" + string(strategy) + ":"
+		dist, err := NewDistributed(&DistributedConfig{KeyPrefix: prefix},
+			WithRedisConnector(redisConn), WithLogger(clog.Discard()), WithOnDeserializeError(strategy))
+		require.NoError(t, err)
+		require.NoError(t, dist.MSet(ctx, map[string]any{
+			"user:1": staleUser{Name: "alice"},
+			"user:2": legacyValue,
+		}, time.Minute))
+		return dist
+	}
+
+	t.Run("error", func(t *testing.T) {
+		dist := setup(t, DeserializeErrorReturn)
+		var got staleUser
+		err := dist.Get(ctx, "user:2", &got)
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrMiss)
+
+		var many []staleUser
+		require.Error(t, dist.MGet(ctx, []string{"user:1", "user:2"}, &many))
+	})
+
+	t.Run("treat_as_miss", func(t *testing.T) {
+		dist := setup(t, DeserializeErrorTreatAsMiss)
+		var got staleUser
+		require.ErrorIs(t, dist.Get(ctx, "user:2", &got), ErrMiss)
+
+		var many []staleUser
+		require.NoError(t, dist.MGet(ctx, []string{"user:1", "user:2"}, &many))
+		require.Equal(t, []staleUser{{Name: "alice"}, {}}, many)
+
+		ok, err := dist.Has(ctx, "user:2")
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("delete_and_miss", func(t *testing.T) {
+		dist := setup(t, DeserializeErrorDeleteAndMiss)
+		var many []staleUser
+		require.NoError(t, dist.MGet(ctx, []string{"user:1", "user:2"}, &many))
+		require.Equal(t, []staleUser{{Name: "alice"}, {}}, many)
+
+		ok, err := dist.Has(ctx, "user:2")
+		require.NoError(t, err)
+		require.False(t, ok, "MGet 删除了坏数据")
+
+		require.NoError(t, dist.Set(ctx, "user:3", legacyValue, time.Minute))
+		var got staleUser
+		require.ErrorIs(t, dist.Get(ctx, "user:3", &got), ErrMiss)
+		ok, err = dist.Has(ctx, "user:3")
+		require.NoError(t, err)
+		require.False(t, ok, "Get 删除了坏数据")
+	})
+}
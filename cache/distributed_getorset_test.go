@@ -0,0 +1,79 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/dlock"
+)
+
+// TestDistributed_GetOrSet_Integration 测试 GetOrSet 回源与命中
+func TestDistributed_GetOrSet_Integration(t *testing.T) {
+	cache := setupTestDistributed(t, "test:dist:getorset:")
+	ctx := context.Background()
+
+	loads := 0
+	load := func(ctx context.Context) (any, error) {
+		loads++
+		return map[string]string{"name": "alice"}, nil
+	}
+
+	for range 2 {
+		var got map[string]string
+		stale, err := cache.GetOrSet(ctx, "user:1", &got, time.Minute, load, WithStaleOnError(time.Minute))
+		require.NoError(t, err)
+		require.False(t, stale)
+		require.Equal(t, "alice", got["name"])
+	}
+	require.Equal(t, 1, loads, "第二次读取命中 Redis，不再回源")
+
+	var got map[string]string
+	require.NoError(t, cache.Get(ctx, "user:1", &got), "回源结果已写回 Redis")
+	require.Equal(t, "alice", got["name"])
+}
+
+// TestDistributed_GetOrSetWithDistributedLock_Integration 多个实例共享 Redis 与 dlock，只有一个实例回源
+func TestDistributed_GetOrSetWithDistributedLock_Integration(t *testing.T) {
+	redisConn := newRedisConnectorOrSkip(t)
+	ctx := context.Background()
+
+	var loads atomic.Int32
+	load := func(ctx context.Context) (any, error) {
+		loads.Add(1)
+		time.Sleep(200 * time.Millisecond)
+		return map[string]string{"name": "alice"}, nil
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		dist, err := NewDistributed(&DistributedConfig{
+			Driver:     DriverRedis,
+			KeyPrefix:  "test:dist:getorset:lock:",
+			Serializer: "json",
+			DefaultTTL: time.Hour,
+		}, WithRedisConnector(redisConn), WithLogger(clog.Discard()))
+		require.NoError(t, err)
+		locker, err := dlock.New(&dlock.Config{Driver: dlock.DriverRedis, Prefix: "test:dlock:"},
+			dlock.WithRedisConnector(redisConn))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = locker.Close() })
+
+		for range 3 {
+			wg.Go(func() {
+				var got map[string]string
+				_, err := dist.GetOrSet(ctx, "user:1", &got, time.Minute, load,
+					WithDistributedLock(locker, 2*time.Second))
+				require.NoError(t, err)
+				require.Equal(t, "alice", got["name"])
+			})
+		}
+	}
+	wg.Wait()
+	require.EqualValues(t, 1, loads.Load(), "只有拿到锁的实例回源")
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDistributed_HyperLogLog_Integration 测试 HyperLogLog 基数统计
+func TestDistributed_HyperLogLog_Integration(t *testing.T) {
+	cache := setupTestDistributed(t, "test:dist:hll:")
+	ctx := context.Background()
+
+	// addUsers 分批写入 user:{from} 到 user:{to-1}
+	addUsers := func(key string, from, to int) {
+		t.Helper()
+		batch := make([]string, 0, 1000)
+		for i := from; i < to; i++ {
+			batch = append(batch, fmt.Sprintf("user:%d", i))
+			if len(batch) == cap(batch) || i == to-1 {
+				require.NoError(t, cache.PFAdd(ctx, key, batch...))
+				batch = batch[:0]
+			}
+		}
+	}
+	// requireEstimate 断言估算值与真实基数的相对误差在 3% 以内（标准误差约 0.81%）
+	requireEstimate := func(want int, got int64) {
+		t.Helper()
+		errRate := math.Abs(float64(got)-float64(want)) / float64(want)
+		require.Less(t, errRate, 0.03, "want ~%d, got %d", want, got)
+	}
+
+	t.Run("PFCount estimate within error range", func(t *testing.T) {
+		addUsers("uv:day1", 0, 50000)
+		// 重复元素不影响基数
+		addUsers("uv:day1", 0, 1000)
+
+		count, err := cache.PFCount(ctx, "uv:day1")
+		require.NoError(t, err)
+		requireEstimate(50000, count)
+	})
+
+	t.Run("PFCount multiple keys returns union", func(t *testing.T) {
+		// day2 与 day1 重叠 [25000, 50000)
+		addUsers("uv:day2", 25000, 75000)
+
+		count, err := cache.PFCount(ctx, "uv:day1", "uv:day2")
+		require.NoError(t, err)
+		requireEstimate(75000, count)
+	})
+
+	t.Run("PFMerge", func(t *testing.T) {
+		require.NoError(t, cache.PFMerge(ctx, "uv:week", "uv:day1", "uv:day2"))
+
+		merged, err := cache.PFCount(ctx, "uv:week")
+		require.NoError(t, err)
+		union, err := cache.PFCount(ctx, "uv:day1", "uv:day2")
+		require.NoError(t, err)
+		require.Equal(t, union, merged)
+	})
+
+	t.Run("PFCount non-existent key returns 0", func(t *testing.T) {
+		count, err := cache.PFCount(ctx, "uv:none")
+		require.NoError(t, err)
+		require.Zero(t, count)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		require.NoError(t, cache.PFAdd(ctx, "uv:empty"))
+		_, err := cache.PFCount(ctx)
+		require.Error(t, err)
+		require.Error(t, cache.PFMerge(ctx, "uv:week"))
+	})
+}
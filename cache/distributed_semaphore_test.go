@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDistributed_Semaphore_Integration 测试分布式信号量
+func TestDistributed_Semaphore_Integration(t *testing.T) {
+	cache := setupTestDistributed(t, "test:dist:sem:")
+	ctx := context.Background()
+
+	t.Run("max holders", func(t *testing.T) {
+		sem := cache.Semaphore("limit", 3)
+		tokens := make([]string, 0, 3)
+		for range 3 {
+			token, err := sem.Acquire(ctx)
+			require.NoError(t, err)
+			tokens = append(tokens, token)
+		}
+
+		// 第 4 个获取失败
+		_, ok, err := sem.TryAcquire(ctx)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// 第 4 个 Acquire 阻塞到 ctx 超时
+		waitCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+		defer cancel()
+		_, err = sem.Acquire(waitCtx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		// Release 后可再获取
+		require.NoError(t, sem.Release(ctx, tokens[0]))
+		require.ErrorIs(t, sem.Release(ctx, tokens[0]), ErrSemaphoreNotHeld)
+		token, ok, err := sem.TryAcquire(ctx)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		for _, tk := range append(tokens[1:], token) {
+			require.NoError(t, sem.Release(ctx, tk))
+		}
+	})
+
+	t.Run("blocked acquire wakes after release", func(t *testing.T) {
+		sem := cache.Semaphore("wake", 1, WithSemaphoreRetryInterval(10*time.Millisecond))
+		token, err := sem.Acquire(ctx)
+		require.NoError(t, err)
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			_ = sem.Release(ctx, token)
+		}()
+
+		waitCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		defer cancel()
+		next, err := sem.Acquire(waitCtx)
+		require.NoError(t, err)
+		require.NoError(t, sem.Release(ctx, next))
+	})
+
+	t.Run("crashed holder reclaimed by timeout", func(t *testing.T) {
+		sem := cache.Semaphore("zombie", 3, WithSemaphoreTimeout(500*time.Millisecond))
+		for range 3 {
+			_, err := sem.Acquire(ctx) // 模拟持有者崩溃，不 Release
+			require.NoError(t, err)
+		}
+		_, ok, err := sem.TryAcquire(ctx)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		time.Sleep(600 * time.Millisecond)
+		token, ok, err := sem.TryAcquire(ctx)
+		require.NoError(t, err)
+		require.True(t, ok, "超时的僵尸令牌应被回收")
+		require.NoError(t, sem.Release(ctx, token))
+	})
+
+	t.Run("refresh keeps token alive", func(t *testing.T) {
+		sem := cache.Semaphore("refresh", 1, WithSemaphoreTimeout(300*time.Millisecond))
+		token, err := sem.Acquire(ctx)
+		require.NoError(t, err)
+
+		for range 3 {
+			time.Sleep(150 * time.Millisecond)
+			require.NoError(t, sem.Refresh(ctx, token))
+		}
+		_, ok, err := sem.TryAcquire(ctx)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		time.Sleep(400 * time.Millisecond)
+		require.ErrorIs(t, sem.Refresh(ctx, token), ErrSemaphoreNotHeld)
+	})
+
+	t.Run("invalid max", func(t *testing.T) {
+		_, err := cache.Semaphore("invalid", 0).Acquire(ctx)
+		require.ErrorIs(t, err, ErrInvalidSemaphore)
+	})
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDistributed_Tag_Integration 测试基于 tag 的批量失效
+func TestDistributed_Tag_Integration(t *testing.T) {
+	cache := setupTestDistributed(t, "test:dist:tag:")
+	ctx := context.Background()
+
+	t.Run("invalidate all keys of a tag", func(t *testing.T) {
+		require.NoError(t, cache.SetWithTags(ctx, "user:123:profile", "alice", time.Minute, "user:123"))
+		require.NoError(t, cache.SetWithTags(ctx, "user:123:orders", []int{1, 2}, time.Minute, "user:123", "orders"))
+		require.NoError(t, cache.SetWithTags(ctx, "user:456:profile", "bob", time.Minute, "user:456"))
+		require.NoError(t, cache.Set(ctx, "user:123:plain", "untagged", time.Minute))
+
+		require.NoError(t, cache.InvalidateByTag(ctx, "user:123"))
+
+		for _, key := range []string{"user:123:profile", "user:123:orders"} {
+			ok, err := cache.Has(ctx, key)
+			require.NoError(t, err)
+			require.False(t, ok, key)
+		}
+		for _, key := range []string{"user:456:profile", "user:123:plain"} {
+			ok, err := cache.Has(ctx, key)
+			require.NoError(t, err)
+			require.True(t, ok, key)
+		}
+
+		// tag 集合本身也被删除，再次失效不报错
+		require.NoError(t, cache.InvalidateByTag(ctx, "user:123"))
+	})
+
+	t.Run("tag set outlives tagged keys", func(t *testing.T) {
+		require.NoError(t, cache.SetWithTags(ctx, "short", 1, time.Minute, "ttl"))
+		require.NoError(t, cache.SetWithTags(ctx, "long", 1, time.Hour, "ttl"))
+		require.NoError(t, cache.SetWithTags(ctx, "shorter", 1, time.Second, "ttl"))
+
+		client := cache.RawClient().(*redis.Client)
+		ttl, err := client.PTTL(ctx, "test:dist:tag:"+tagKeyPrefix+"ttl").Result()
+		require.NoError(t, err)
+		require.Greater(t, ttl, 59*time.Minute)
+	})
+
+	t.Run("large tag", func(t *testing.T) {
+		for i := range invalidateBatchSize + 10 {
+			require.NoError(t, cache.SetWithTags(ctx, "bulk:"+strconv.Itoa(i), i, time.Minute, "bulk"))
+		}
+		require.NoError(t, cache.InvalidateByTag(ctx, "bulk"))
+
+		ok, err := cache.Has(ctx, "bulk:"+strconv.Itoa(invalidateBatchSize))
+		require.NoError(t, err)
+		require.False(t, ok)
+	})
+
+	t.Run("empty tag", func(t *testing.T) {
+		require.Error(t, cache.SetWithTags(ctx, "k", 1, time.Minute, ""))
+		require.Error(t, cache.InvalidateByTag(ctx, ""))
+	})
+}
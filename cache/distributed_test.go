@@ -3,6 +3,7 @@ package cache
 import (
 	"context"
 	"os/exec"
+	"strconv"
 	"testing"
 	"time"
 
@@ -150,3 +151,40 @@ func TestDistributed_ErrorHandling_Integration(t *testing.T) {
 		require.False(t, ok)
 	})
 }
+
+// TestDistributed_TTLJitter_Integration 验证 TTL 抖动使过期时刻分散
+func TestDistributed_TTLJitter_Integration(t *testing.T) {
+	redisConn := newRedisConnectorOrSkip(t)
+	ctx := context.Background()
+	prefix := "test:dist:jitter:" + testkit.NewID() + ":"
+
+	dist, err := NewDistributed(&DistributedConfig{
+		Driver:     DriverRedis,
+		KeyPrefix:  prefix,
+		DefaultTTL: time.Hour,
+		TTLJitter:  30 * time.Second,
+	}, WithRedisConnector(redisConn), WithLogger(clog.Discard()))
+	require.NoError(t, err)
+
+	client := redisConn.GetClient()
+	seen := make(map[time.Duration]struct{})
+	for i := range 20 {
+		key := "k" + strconv.Itoa(i)
+		require.NoError(t, dist.Set(ctx, key, i, time.Minute))
+
+		pttl, err := client.PTTL(ctx, prefix+key).Result()
+		require.NoError(t, err)
+		require.Greater(t, pttl, time.Minute-time.Second)
+		require.LessOrEqual(t, pttl, time.Minute+30*time.Second)
+		seen[pttl.Truncate(time.Millisecond)] = struct{}{}
+	}
+	require.Greater(t, len(seen), 1, "keys should not share the exact same ttl")
+
+	items := map[string]any{"m1": 1, "m2": 2, "m3": 3}
+	require.NoError(t, dist.MSet(ctx, items, time.Minute))
+	for k := range items {
+		pttl, err := client.PTTL(ctx, prefix+k).Result()
+		require.NoError(t, err)
+		require.LessOrEqual(t, pttl, time.Minute+30*time.Second)
+	}
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDistributed_TTL_Integration 测试 TTL 查询与批量续期
+func TestDistributed_TTL_Integration(t *testing.T) {
+	cache := setupTestDistributed(t, "test:dist:ttl:")
+	ctx := context.Background()
+
+	t.Run("TTL close to the value set", func(t *testing.T) {
+		require.NoError(t, cache.Set(ctx, "session:1", "v", time.Minute))
+
+		ttl, err := cache.TTL(ctx, "session:1")
+		require.NoError(t, err)
+		require.Greater(t, ttl, 55*time.Second)
+		require.LessOrEqual(t, ttl, time.Minute)
+	})
+
+	t.Run("TTL for non-existent key", func(t *testing.T) {
+		ttl, err := cache.TTL(ctx, "nonexistent")
+		require.NoError(t, err)
+		require.Equal(t, TTLNotFound, ttl)
+	})
+
+	t.Run("TTL for persistent key", func(t *testing.T) {
+		client := cache.RawClient().(*redis.Client)
+		require.NoError(t, client.Set(ctx, "test:dist:ttl:persistent", "v", 0).Err())
+
+		ttl, err := cache.TTL(ctx, "persistent")
+		require.NoError(t, err)
+		require.Equal(t, TTLPersistent, ttl)
+	})
+
+	t.Run("ExpireBatch refreshes multiple keys", func(t *testing.T) {
+		keys := []string{"batch:1", "batch:2", "batch:3"}
+		for _, key := range keys {
+			require.NoError(t, cache.Set(ctx, key, "v", 10*time.Second))
+		}
+
+		require.NoError(t, cache.ExpireBatch(ctx, append(keys, "batch:missing"), time.Hour))
+		for _, key := range keys {
+			ttl, err := cache.TTL(ctx, key)
+			require.NoError(t, err)
+			require.Greater(t, ttl, 59*time.Minute, key)
+		}
+
+		ttl, err := cache.TTL(ctx, "batch:missing")
+		require.NoError(t, err)
+		require.Equal(t, TTLNotFound, ttl, "ExpireBatch 不会创建不存在的 key")
+	})
+
+	t.Run("ExpireBatch with empty keys", func(t *testing.T) {
+		require.NoError(t, cache.ExpireBatch(ctx, nil, time.Minute))
+	})
+}
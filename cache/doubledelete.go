@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// doubleDeleter 实现延迟双删（内部使用）
+type doubleDeleter struct {
+	logger    clog.Logger
+	afterFunc func(d time.Duration, f func()) // 延迟调度，测试中可替换为假时钟
+}
+
+func newDoubleDeleter(logger clog.Logger) *doubleDeleter {
+	return &doubleDeleter{
+		logger: logger,
+		afterFunc: func(d time.Duration, f func()) {
+			time.AfterFunc(d, f)
+		},
+	}
+}
+
+// run 立即删除 key，并在 delay 后于后台再删除一次
+//
+// 第一次删除失败直接返回错误，不再调度第二次；第二次删除不受 ctx 取消影响，失败只记录日志。
+func (d *doubleDeleter) run(ctx context.Context, kv KV, key string, delay time.Duration) error {
+	if delay <= 0 {
+		return xerrors.New("cache: double delete delay must be positive")
+	}
+	if err := kv.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	bg := context.WithoutCancel(ctx)
+	d.afterFunc(delay, func() {
+		if err := kv.Delete(bg, key); err != nil {
+			d.logger.WarnContext(bg, "Cache delayed delete failed", clog.String("key", key), clog.Error(err))
+		}
+	})
+	return nil
+}
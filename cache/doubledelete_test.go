@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+// countingKV 记录 Delete 调用次数与时刻
+type countingKV struct {
+	*mockKVForMulti
+	now     func() time.Time
+	deletes []time.Time
+}
+
+func (c *countingKV) Delete(ctx context.Context, key string) error {
+	c.deletes = append(c.deletes, c.now())
+	return c.mockKVForMulti.Delete(ctx, key)
+}
+
+// fakeTimer 可手动推进的假时钟，到期的回调在 advance 中同步执行
+type fakeTimer struct {
+	now     time.Time
+	pending []fakeTask
+}
+
+type fakeTask struct {
+	at time.Time
+	f  func()
+}
+
+func (t *fakeTimer) afterFunc(d time.Duration, f func()) {
+	t.pending = append(t.pending, fakeTask{at: t.now.Add(d), f: f})
+}
+
+func (t *fakeTimer) advance(d time.Duration) {
+	t.now = t.now.Add(d)
+	remaining := t.pending[:0]
+	var due []fakeTask
+	for _, task := range t.pending {
+		if task.at.After(t.now) {
+			remaining = append(remaining, task)
+		} else {
+			due = append(due, task)
+		}
+	}
+	t.pending = remaining
+	for _, task := range due {
+		task.f()
+	}
+}
+
+func TestDelayedDoubleDelete(t *testing.T) {
+	ctx := context.Background()
+
+	newDeleter := func() (*doubleDeleter, *fakeTimer, *countingKV) {
+		clock := &fakeTimer{now: time.Unix(1700000000, 0)}
+		d := newDoubleDeleter(clog.Discard())
+		d.afterFunc = clock.afterFunc
+		kv := &countingKV{mockKVForMulti: newMockKVForMulti(), now: func() time.Time { return clock.now }}
+		return d, clock, kv
+	}
+
+	t.Run("立即删除并在 delay 后再删一次", func(t *testing.T) {
+		d, clock, kv := newDeleter()
+		start := clock.now
+		require.NoError(t, kv.Set(ctx, "user:1", "v1", 0))
+
+		require.NoError(t, d.run(ctx, kv, "user:1", 500*time.Millisecond))
+		require.Len(t, kv.deletes, 1)
+		require.Equal(t, start, kv.deletes[0])
+
+		// 模拟并发读在两次删除之间回填了旧值
+		require.NoError(t, kv.Set(ctx, "user:1", "stale", 0))
+
+		clock.advance(499 * time.Millisecond)
+		require.Len(t, kv.deletes, 1, "second delete must not run before delay")
+
+		clock.advance(time.Millisecond)
+		require.Len(t, kv.deletes, 2)
+		require.Equal(t, 500*time.Millisecond, kv.deletes[1].Sub(start))
+
+		ok, err := kv.Has(ctx, "user:1")
+		require.NoError(t, err)
+		require.False(t, ok, "stale backfill should be removed by the second delete")
+	})
+
+	t.Run("第一次删除失败不调度第二次", func(t *testing.T) {
+		d, clock, kv := newDeleter()
+		kv.failDel.Store(true)
+
+		err := d.run(ctx, kv, "user:1", time.Second)
+		require.Error(t, err)
+		require.Empty(t, clock.pending)
+	})
+
+	t.Run("第二次删除不受 ctx 取消影响", func(t *testing.T) {
+		d, clock, kv := newDeleter()
+		cctx, cancel := context.WithCancel(ctx)
+		require.NoError(t, d.run(cctx, kv, "user:1", time.Second))
+		cancel()
+
+		clock.advance(time.Second)
+		require.Len(t, kv.deletes, 2)
+	})
+
+	t.Run("delay 非正数返回错误", func(t *testing.T) {
+		d, _, kv := newDeleter()
+		require.Error(t, d.run(ctx, kv, "user:1", 0))
+		require.Empty(t, kv.deletes)
+	})
+}
@@ -3,12 +3,18 @@ package cache
 import "github.com/ceyewan/genesis/xerrors"
 
 var (
-	// ErrMiss 表示缓存未命中。
-	ErrMiss = xerrors.New("cache: miss")
+	// ErrMiss 表示缓存未命中，同时匹配 xerrors.ErrNotFound，便于消费方不依赖 cache 包识别未命中。
+	ErrMiss = xerrors.Wrap(xerrors.ErrNotFound, "cache: miss")
 
 	// ErrNotSupported 表示当前缓存实现不支持该操作。
 	ErrNotSupported = xerrors.New("cache: operation not supported")
 
+	// ErrInvalidSemaphore 表示信号量参数无效。
+	ErrInvalidSemaphore = xerrors.New("cache: invalid semaphore")
+
+	// ErrSemaphoreNotHeld 表示信号量令牌未被持有（已释放或已超时回收）。
+	ErrSemaphoreNotHeld = xerrors.New("cache: semaphore token not held")
+
 	// ErrRedisConnectorRequired 表示分布式缓存缺少 Redis 连接器。
 	ErrRedisConnectorRequired = xerrors.New("cache: redis connector is required")
 
@@ -0,0 +1,264 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/maypok86/otter/v2"
+
+	"github.com/ceyewan/genesis/cache/serializer"
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/dlock"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+const (
+	// rebuildLockPrefix 缓存重建锁的 key 前缀
+	rebuildLockPrefix = "cache:rebuild:"
+	// defaultRebuildWait 未拿到重建锁时等待新值的默认时长
+	defaultRebuildWait = 3 * time.Second
+	// rebuildPollInterval 等待新值时轮询缓存的间隔
+	rebuildPollInterval = 50 * time.Millisecond
+	// defaultStaleSnapshotSize 本地旧值快照的默认容量
+	defaultStaleSnapshotSize = 10000
+)
+
+// LoadFunc GetOrSet 未命中时的回源函数。
+type LoadFunc func(ctx context.Context) (any, error)
+
+// GetOrSetOption GetOrSet 选项。
+type GetOrSetOption func(*getOrSetOptions)
+
+type getOrSetOptions struct {
+	maxStale time.Duration
+
+	locker      dlock.Locker
+	rebuildWait time.Duration
+}
+
+// WithStaleOnError 开启本地旧值兜底。
+//
+// 每次回源（含后台刷新）成功后在进程内保存一份快照，命中缓存时不保存；之后 Redis
+// 读取失败（不含未命中）时，若快照写入距今未超过 maxStale，则返回快照并标记 stale，
+// 同时在后台回源刷新。超过 maxStale 或没有快照时照常返回 Redis 错误。
+// 快照数量受 DistributedConfig.StaleSnapshotSize 限制，超出时按访问频率淘汰，
+// 超过 maxStale 的快照会被定期清理。
+func WithStaleOnError(maxStale time.Duration) GetOrSetOption {
+	return func(o *getOrSetOptions) {
+		if maxStale > 0 {
+			o.maxStale = maxStale
+		}
+	}
+}
+
+// WithDistributedLock 未命中时先获取分布式锁再回源，避免多实例同时重建同一个 key。
+//
+// 锁 key 为 "cache:rebuild:" + key（再由 locker 自身的 Prefix 修饰）。拿到锁的实例
+// 先复查缓存，仍未命中才回源并写回；没拿到锁的实例在 wait 内轮询缓存，读到新值即返回，
+// 超时后自行回源。wait<=0 时使用默认的 3s。加锁出错时记录日志并直接回源。
+func WithDistributedLock(locker dlock.Locker, wait time.Duration) GetOrSetOption {
+	return func(o *getOrSetOptions) {
+		o.locker = locker
+		o.rebuildWait = wait
+		if o.rebuildWait <= 0 {
+			o.rebuildWait = defaultRebuildWait
+		}
+	}
+}
+
+// staleSnapshot 本地旧值快照
+type staleSnapshot struct {
+	data     []byte
+	at       time.Time
+	maxStale time.Duration
+}
+
+// staleStore 实现 GetOrSet 与本地旧值兜底（内部使用）
+type staleStore struct {
+	serializer serializer.Serializer
+	logger     clog.Logger
+	now        func() time.Time
+
+	snapshots  *otter.Cache[string, staleSnapshot] // 有容量上限，超过 maxStale 后过期
+	refreshing sync.Map                            // map[string]struct{}，同一 key 同时只有一个后台刷新
+}
+
+func newStaleStore(s serializer.Serializer, logger clog.Logger, size int) (*staleStore, error) {
+	if size <= 0 {
+		size = defaultStaleSnapshotSize
+	}
+	snapshots, err := otter.New(&otter.Options[string, staleSnapshot]{
+		MaximumSize: size,
+		ExpiryCalculator: otter.ExpiryWritingFunc(func(entry otter.Entry[string, staleSnapshot]) time.Duration {
+			return entry.Value.maxStale
+		}),
+	})
+	if err != nil {
+		return nil, xerrors.Wrap(err, "failed to build stale snapshot cache")
+	}
+	return &staleStore{serializer: s, logger: logger, now: time.Now, snapshots: snapshots}, nil
+}
+
+// close 停止快照缓存的后台清理
+func (s *staleStore) close() {
+	s.snapshots.StopAllGoroutines()
+}
+
+// getOrSet 读取 key，未命中时回源并写回 kv，返回值表示 dest 是否来自旧值快照
+func (s *staleStore) getOrSet(ctx context.Context, kv KV, key string, dest any, ttl time.Duration, load LoadFunc, opts ...GetOrSetOption) (bool, error) {
+	if load == nil {
+		return false, xerrors.New("cache: load func is nil")
+	}
+	var o getOrSetOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	err := kv.Get(ctx, key, dest)
+	switch {
+	case err == nil:
+		return false, nil
+	case xerrors.Is(err, ErrMiss):
+		if o.locker != nil {
+			return false, s.lockedLoad(ctx, kv, key, dest, ttl, load, o)
+		}
+		return false, s.load(ctx, kv, key, dest, ttl, load, o.maxStale)
+	}
+
+	if o.maxStale <= 0 {
+		return false, err
+	}
+	snap, ok := s.snapshot(key, o.maxStale)
+	if !ok {
+		return false, err
+	}
+	if uerr := s.serializer.Unmarshal(snap.data, dest); uerr != nil {
+		return false, err
+	}
+
+	s.logger.WarnContext(ctx, "Cache read failed, serving stale value",
+		clog.String("key", key),
+		clog.Duration("age", s.now().Sub(snap.at)),
+		clog.Error(err))
+	s.refresh(context.WithoutCancel(ctx), kv, key, ttl, load, o.maxStale)
+	return true, nil
+}
+
+// load 回源并写回缓存，写回失败只记录日志，不影响本次读取；maxStale>0 时保存快照
+func (s *staleStore) load(ctx context.Context, kv KV, key string, dest any, ttl time.Duration, load LoadFunc, maxStale time.Duration) error {
+	value, err := load(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := s.serializer.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := s.serializer.Unmarshal(data, dest); err != nil {
+		return err
+	}
+	s.saveSnapshot(key, data, maxStale)
+	if err := kv.Set(ctx, key, value, ttl); err != nil {
+		s.logger.WarnContext(ctx, "Cache backfill failed", clog.String("key", key), clog.Error(err))
+	}
+	return nil
+}
+
+// lockedLoad 在分布式锁保护下回源；未拿到锁时等待其它实例写回
+func (s *staleStore) lockedLoad(ctx context.Context, kv KV, key string, dest any, ttl time.Duration, load LoadFunc, o getOrSetOptions) error {
+	lockKey := rebuildLockPrefix + key
+
+	acquired, err := o.locker.TryLock(ctx, lockKey)
+	switch {
+	case err != nil && !xerrors.Is(err, dlock.ErrLockAlreadyHeld):
+		s.logger.WarnContext(ctx, "Cache rebuild lock failed, loading without lock",
+			clog.String("key", key), clog.Error(err))
+		return s.load(ctx, kv, key, dest, ttl, load, o.maxStale)
+	case acquired:
+		defer func() {
+			if err := o.locker.Unlock(context.WithoutCancel(ctx), lockKey); err != nil {
+				s.logger.WarnContext(ctx, "Cache rebuild unlock failed", clog.String("key", key), clog.Error(err))
+			}
+		}()
+		// 等锁期间其它实例可能已完成重建
+		if err := kv.Get(ctx, key, dest); err == nil {
+			return nil
+		}
+		return s.load(ctx, kv, key, dest, ttl, load, o.maxStale)
+	}
+
+	// 其它实例（或本实例的其它请求，同一 Locker 不可重入）正在重建
+	if err := s.waitRebuild(ctx, kv, key, dest, o.rebuildWait); err == nil {
+		return nil
+	} else if ctx.Err() != nil {
+		return err
+	}
+	s.logger.WarnContext(ctx, "Cache rebuild wait timeout, loading without lock",
+		clog.String("key", key), clog.Duration("wait", o.rebuildWait))
+	return s.load(ctx, kv, key, dest, ttl, load, o.maxStale)
+}
+
+// waitRebuild 在 wait 内轮询 key，读到值返回 nil，超时返回 ErrMiss
+func (s *staleStore) waitRebuild(ctx context.Context, kv KV, key string, dest any, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	ticker := time.NewTicker(rebuildPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return ErrMiss
+		case <-ticker.C:
+			if err := kv.Get(ctx, key, dest); err == nil {
+				return nil
+			}
+		}
+	}
+}
+
+// refresh 在后台回源刷新快照与缓存
+func (s *staleStore) refresh(ctx context.Context, kv KV, key string, ttl time.Duration, load LoadFunc, maxStale time.Duration) {
+	if _, running := s.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+	go func() {
+		defer s.refreshing.Delete(key)
+
+		value, err := load(ctx)
+		if err != nil {
+			s.logger.WarnContext(ctx, "Cache stale refresh failed", clog.String("key", key), clog.Error(err))
+			return
+		}
+		if data, err := s.serializer.Marshal(value); err == nil {
+			s.saveSnapshot(key, data, maxStale)
+		}
+		if err := kv.Set(ctx, key, value, ttl); err != nil {
+			s.logger.WarnContext(ctx, "Cache backfill failed", clog.String("key", key), clog.Error(err))
+		}
+	}()
+}
+
+// saveSnapshot 保存 key 的快照，maxStale<=0 表示未开启兜底
+func (s *staleStore) saveSnapshot(key string, data []byte, maxStale time.Duration) {
+	if maxStale <= 0 {
+		return
+	}
+	s.snapshots.Set(key, staleSnapshot{data: data, at: s.now(), maxStale: maxStale})
+}
+
+// snapshot 返回未超过 maxStale 的快照，过期快照顺带删除
+func (s *staleStore) snapshot(key string, maxStale time.Duration) (staleSnapshot, bool) {
+	snap, ok := s.snapshots.GetIfPresent(key)
+	if !ok {
+		return staleSnapshot{}, false
+	}
+	if s.now().Sub(snap.at) > maxStale {
+		s.snapshots.Invalidate(key)
+		return staleSnapshot{}, false
+	}
+	return snap, true
+}
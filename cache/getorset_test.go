@@ -0,0 +1,321 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/cache/serializer"
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/dlock"
+)
+
+// newTestStaleStore 创建时钟可控的 staleStore
+func newTestStaleStore(t *testing.T) (*staleStore, *atomic.Int64) {
+	t.Helper()
+	s, err := serializer.New("json")
+	require.NoError(t, err)
+
+	var clock atomic.Int64
+	clock.Store(time.Now().UnixNano())
+	store, err := newStaleStore(s, clog.Discard(), 0)
+	require.NoError(t, err)
+	t.Cleanup(store.close)
+	store.now = func() time.Time { return time.Unix(0, clock.Load()) }
+	return store, &clock
+}
+
+type staleUser struct {
+	Name string `json:"name"`
+}
+
+func TestGetOrSet(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("未命中时回源并写回", func(t *testing.T) {
+		store, _ := newTestStaleStore(t)
+		remote := newMockKVForMulti()
+
+		loads := 0
+		load := func(ctx context.Context) (any, error) {
+			loads++
+			return staleUser{Name: "alice"}, nil
+		}
+
+		var got staleUser
+		stale, err := store.getOrSet(ctx, remote, "user:1", &got, time.Minute, load)
+		require.NoError(t, err)
+		require.False(t, stale)
+		require.Equal(t, "alice", got.Name)
+
+		got = staleUser{}
+		stale, err = store.getOrSet(ctx, remote, "user:1", &got, time.Minute, load)
+		require.NoError(t, err)
+		require.False(t, stale)
+		require.Equal(t, "alice", got.Name)
+		require.Equal(t, 1, loads, "命中缓存时不回源")
+	})
+
+	t.Run("回源失败返回错误", func(t *testing.T) {
+		store, _ := newTestStaleStore(t)
+		loadErr := errors.New("db down")
+
+		var got staleUser
+		_, err := store.getOrSet(ctx, newMockKVForMulti(), "user:1", &got, time.Minute, func(ctx context.Context) (any, error) {
+			return nil, loadErr
+		})
+		require.ErrorIs(t, err, loadErr)
+	})
+
+	t.Run("未开启兜底时直接返回 Redis 错误", func(t *testing.T) {
+		store, _ := newTestStaleStore(t)
+		remote := newMockKVForMulti()
+		require.NoError(t, remote.Set(ctx, "user:1", staleUser{Name: "alice"}, time.Minute))
+
+		var got staleUser
+		_, err := store.getOrSet(ctx, remote, "user:1", &got, time.Minute, nil)
+		require.Error(t, err, "load 为 nil")
+
+		_, err = store.getOrSet(ctx, remote, "user:1", &got, time.Minute, func(ctx context.Context) (any, error) {
+			return staleUser{Name: "bob"}, nil
+		})
+		require.NoError(t, err)
+
+		remote.failGet.Store(true)
+		_, err = store.getOrSet(ctx, remote, "user:1", &got, time.Minute, func(ctx context.Context) (any, error) {
+			return staleUser{Name: "bob"}, nil
+		})
+		require.Error(t, err)
+	})
+}
+
+func TestGetOrSet_StaleOnError(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestStaleStore(t)
+	remote := newMockKVForMulti()
+
+	var (
+		mu       sync.Mutex
+		loads    int
+		loadErr  = errors.New("db down")
+		loadFail atomic.Bool
+		name     = "alice"
+	)
+	load := func(ctx context.Context) (any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		loads++
+		if loadFail.Load() {
+			return nil, loadErr
+		}
+		return staleUser{Name: name}, nil
+	}
+	waitRefreshed := func() {
+		require.Eventually(t, func() bool {
+			_, running := store.refreshing.Load("user:1")
+			return !running
+		}, time.Second, 5*time.Millisecond)
+	}
+	opt := WithStaleOnError(time.Minute)
+
+	var got staleUser
+	stale, err := store.getOrSet(ctx, remote, "user:1", &got, time.Minute, load, opt)
+	require.NoError(t, err)
+	require.False(t, stale)
+	require.Equal(t, "alice", got.Name)
+
+	// 命中缓存时不覆盖快照
+	require.NoError(t, remote.Set(ctx, "user:1", staleUser{Name: "bob"}, time.Minute))
+	stale, err = store.getOrSet(ctx, remote, "user:1", &got, time.Minute, load, opt)
+	require.NoError(t, err)
+	require.False(t, stale)
+	require.Equal(t, "bob", got.Name)
+
+	// Redis 故障，maxStale 内返回旧值并在后台刷新
+	remote.failGet.Store(true)
+	loadFail.Store(true)
+	clock.Add(int64(30 * time.Second))
+
+	got = staleUser{}
+	stale, err = store.getOrSet(ctx, remote, "user:1", &got, time.Minute, load, opt)
+	require.NoError(t, err)
+	require.True(t, stale)
+	require.Equal(t, "alice", got.Name, "返回回源时保存的快照")
+	waitRefreshed()
+	mu.Lock()
+	require.Equal(t, 2, loads, "返回旧值时触发一次后台刷新")
+	mu.Unlock()
+
+	// 超过 maxStale 后返回 Redis 错误
+	clock.Add(int64(31 * time.Second))
+	_, err = store.getOrSet(ctx, remote, "user:1", &got, time.Minute, load, opt)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, loadErr)
+
+	// 后台刷新成功时更新快照并写回
+	remote.failGet.Store(false)
+	loadFail.Store(false)
+	mu.Lock()
+	name = "carol"
+	mu.Unlock()
+	_, err = store.getOrSet(ctx, remote, "user:2", &got, time.Minute, load, opt)
+	require.NoError(t, err)
+	mu.Lock()
+	name = "carol-v2"
+	mu.Unlock()
+
+	remote.failGet.Store(true)
+	clock.Add(int64(50 * time.Second))
+	stale, err = store.getOrSet(ctx, remote, "user:2", &got, time.Minute, load, opt)
+	require.NoError(t, err)
+	require.True(t, stale)
+	require.Equal(t, "carol", got.Name)
+	require.Eventually(t, func() bool {
+		_, running := store.refreshing.Load("user:2")
+		return !running
+	}, time.Second, 5*time.Millisecond)
+
+	clock.Add(int64(50 * time.Second))
+	stale, err = store.getOrSet(ctx, remote, "user:2", &got, time.Minute, load, opt)
+	require.NoError(t, err, "刷新后的快照重新计时")
+	require.True(t, stale)
+	require.Equal(t, "carol-v2", got.Name)
+}
+
+func TestGetOrSet_StaleSnapshotSize(t *testing.T) {
+	ctx := context.Background()
+	s, err := serializer.New("json")
+	require.NoError(t, err)
+	store, err := newStaleStore(s, clog.Discard(), 10)
+	require.NoError(t, err)
+	t.Cleanup(store.close)
+
+	remote := newMockKVForMulti()
+	opt := WithStaleOnError(time.Minute)
+	for i := range 100 {
+		var got staleUser
+		_, err := store.getOrSet(ctx, remote, fmt.Sprintf("user:%d", i), &got, time.Minute, func(ctx context.Context) (any, error) {
+			return staleUser{Name: "alice"}, nil
+		}, opt)
+		require.NoError(t, err)
+	}
+
+	store.snapshots.CleanUp()
+	require.LessOrEqual(t, store.snapshots.EstimatedSize(), 10, "快照数量不超过容量上限")
+}
+
+// syncKV 为非并发安全的 KV 加锁，模拟多实例共享的 Redis
+type syncKV struct {
+	KV
+	mu sync.Mutex
+}
+
+func (k *syncKV) Get(ctx context.Context, key string, dest any) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.KV.Get(ctx, key, dest)
+}
+
+func (k *syncKV) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.KV.Set(ctx, key, value, ttl)
+}
+
+// fakeLocker 多个实例共享 held 模拟同一个锁服务
+type fakeLocker struct {
+	mu     *sync.Mutex
+	held   map[string]bool
+	tryErr error
+}
+
+func (l *fakeLocker) Lock(ctx context.Context, key string, opts ...dlock.LockOption) error {
+	return errors.New("not implemented")
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, key string, opts ...dlock.LockOption) (bool, error) {
+	if l.tryErr != nil {
+		return false, l.tryErr
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[key] {
+		return false, nil
+	}
+	l.held[key] = true
+	return true, nil
+}
+
+func (l *fakeLocker) Unlock(ctx context.Context, key string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.held, key)
+	return nil
+}
+
+func (l *fakeLocker) Close() error { return nil }
+
+func TestGetOrSetWithDistributedLock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("多实例并发未命中只回源一次", func(t *testing.T) {
+		remote := &syncKV{KV: newMockKVForMulti()}
+		var lockMu sync.Mutex
+		held := make(map[string]bool)
+
+		var loads atomic.Int32
+		load := func(ctx context.Context) (any, error) {
+			loads.Add(1)
+			time.Sleep(100 * time.Millisecond)
+			return staleUser{Name: "alice"}, nil
+		}
+
+		var wg sync.WaitGroup
+		for range 5 {
+			store, _ := newTestStaleStore(t)
+			locker := &fakeLocker{mu: &lockMu, held: held}
+			for range 2 {
+				wg.Go(func() {
+					var got staleUser
+					_, err := store.getOrSet(ctx, remote, "user:1", &got, time.Minute, load,
+						WithDistributedLock(locker, time.Second))
+					require.NoError(t, err)
+					require.Equal(t, "alice", got.Name)
+				})
+			}
+		}
+		wg.Wait()
+		require.EqualValues(t, 1, loads.Load())
+		require.Empty(t, held, "重建完成后释放锁")
+	})
+
+	t.Run("等待超时后自行回源", func(t *testing.T) {
+		store, _ := newTestStaleStore(t)
+		locker := &fakeLocker{mu: &sync.Mutex{}, held: map[string]bool{rebuildLockPrefix + "user:1": true}}
+
+		var got staleUser
+		_, err := store.getOrSet(ctx, newMockKVForMulti(), "user:1", &got, time.Minute, func(ctx context.Context) (any, error) {
+			return staleUser{Name: "bob"}, nil
+		}, WithDistributedLock(locker, 100*time.Millisecond))
+		require.NoError(t, err)
+		require.Equal(t, "bob", got.Name)
+	})
+
+	t.Run("加锁失败时直接回源", func(t *testing.T) {
+		store, _ := newTestStaleStore(t)
+		locker := &fakeLocker{mu: &sync.Mutex{}, held: map[string]bool{}, tryErr: errors.New("redis down")}
+
+		var got staleUser
+		_, err := store.getOrSet(ctx, newMockKVForMulti(), "user:1", &got, time.Minute, func(ctx context.Context) (any, error) {
+			return staleUser{Name: "carol"}, nil
+		}, WithDistributedLock(locker, time.Second))
+		require.NoError(t, err)
+		require.Equal(t, "carol", got.Name)
+	})
+}
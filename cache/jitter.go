@@ -0,0 +1,15 @@
+package cache
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// applyTTLJitter 在 [ttl, ttl+jitter] 区间内随机化 TTL，用于分散大量 key 的过期时刻。
+// jitter<=0 时原样返回 ttl。
+func applyTTLJitter(ttl, jitter time.Duration) time.Duration {
+	if jitter <= 0 || ttl <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int64N(int64(jitter)+1))
+}
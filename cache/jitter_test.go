@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+func TestApplyTTLJitter(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, time.Minute, applyTTLJitter(time.Minute, 0))
+	require.Equal(t, time.Duration(0), applyTTLJitter(0, time.Second))
+
+	seen := make(map[time.Duration]struct{})
+	for range 100 {
+		ttl := applyTTLJitter(time.Minute, 10*time.Second)
+		require.GreaterOrEqual(t, ttl, time.Minute)
+		require.LessOrEqual(t, ttl, time.Minute+10*time.Second)
+		seen[ttl] = struct{}{}
+	}
+	require.Greater(t, len(seen), 1, "jittered ttl should not be constant")
+}
+
+func TestLocal_TTLJitter(t *testing.T) {
+	t.Parallel()
+
+	local, err := NewLocal(&LocalConfig{
+		MaxEntries: 1000,
+		DefaultTTL: time.Hour,
+		TTLJitter:  10 * time.Second,
+	}, WithLogger(clog.Discard()))
+	require.NoError(t, err)
+	defer local.Close()
+
+	ctx := context.Background()
+	lc := local.(*localCache)
+	seen := make(map[time.Duration]struct{})
+	for i := range 50 {
+		key := "jitter:" + strconv.Itoa(i)
+		require.NoError(t, local.Set(ctx, key, i, time.Minute))
+
+		entry, ok := lc.cache.GetIfPresent(key)
+		require.True(t, ok)
+		require.GreaterOrEqual(t, entry.ttl, time.Minute)
+		require.LessOrEqual(t, entry.ttl, time.Minute+10*time.Second)
+		seen[entry.ttl] = struct{}{}
+	}
+	require.Greater(t, len(seen), 1)
+}
+
+func TestConfig_NegativeTTLJitter(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewLocal(&LocalConfig{TTLJitter: -time.Second})
+	require.Error(t, err)
+
+	require.Error(t, (&DistributedConfig{Driver: DriverRedis, TTLJitter: -time.Second}).validate())
+}
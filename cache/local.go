@@ -23,11 +23,13 @@ type localCache struct {
 	cache      *otter.Cache[string, localEntry]
 	serializer serializer.Serializer
 	defaultTTL time.Duration
+	ttlJitter  time.Duration
 	logger     clog.Logger
 	meter      metrics.Meter
+	fallback   deserializeFallback
 }
 
-func newLocal(cfg *LocalConfig, logger clog.Logger, meter metrics.Meter) (Local, error) {
+func newLocal(cfg *LocalConfig, logger clog.Logger, meter metrics.Meter, onDeserializeError DeserializeErrorStrategy) (Local, error) {
 	if cfg == nil {
 		return nil, xerrors.New("cache: local config is nil")
 	}
@@ -53,8 +55,10 @@ func newLocal(cfg *LocalConfig, logger clog.Logger, meter metrics.Meter) (Local,
 		cache:      cache,
 		serializer: s,
 		defaultTTL: cfg.DefaultTTL,
+		ttlJitter:  cfg.TTLJitter,
 		logger:     logger,
 		meter:      meter,
+		fallback:   deserializeFallback{strategy: onDeserializeError, logger: logger},
 	}, nil
 }
 
@@ -66,6 +70,7 @@ func (c *localCache) Set(ctx context.Context, key string, value any, ttl time.Du
 	if ttl <= 0 {
 		ttl = c.defaultTTL
 	}
+	ttl = applyTTLJitter(ttl, c.ttlJitter)
 	// 单次 Set 同时写入数据与 TTL，避免两步操作之间的竞态。
 	c.cache.Set(key, localEntry{data: data, ttl: ttl})
 	return nil
@@ -76,7 +81,18 @@ func (c *localCache) Get(ctx context.Context, key string, dest any) error {
 	if !ok {
 		return ErrMiss
 	}
-	return c.serializer.Unmarshal(entry.data, dest)
+	if err := c.serializer.Unmarshal(entry.data, dest); err != nil {
+		return c.fallback.handle(ctx, []string{key}, err, c.deleteRaw)
+	}
+	return nil
+}
+
+// deleteRaw 删除无法反序列化的坏数据
+func (c *localCache) deleteRaw(_ context.Context, keys []string) error {
+	for _, key := range keys {
+		c.cache.Invalidate(key)
+	}
+	return nil
 }
 
 func (c *localCache) Delete(ctx context.Context, key string) error {
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/ceyewan/genesis/cache/serializer"
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// MLoadFunc MGetOrSet 的批量回源函数，返回 key 到值的映射；未出现在结果中的 key 视为不存在，不写回缓存。
+type MLoadFunc func(ctx context.Context, missingKeys []string) (map[string]any, error)
+
+// rawBatchKV 支持按 key 批量读取原始字节的后端（内部使用）
+type rawBatchKV interface {
+	// mgetRaw 按 keys 顺序返回原始值，未命中的位置为 nil
+	mgetRaw(ctx context.Context, keys []string) ([][]byte, error)
+	// deleteRaw 删除无法反序列化的坏数据
+	deleteRaw(ctx context.Context, keys []string) error
+	MSet(ctx context.Context, items map[string]any, ttl time.Duration) error
+}
+
+// mgetOrSet 批量读取 keys，未命中的 key 合并为一次 load 回源，结果写入 destSlice 并回填缓存
+//
+// destSlice 与 keys 按下标对齐；回源后仍不存在的 key 保持元素零值。回填失败只记录日志，不影响本次读取。
+// 无法反序列化的值按 fallback 策略返回错误，或与未命中的 key 一起回源。
+func mgetOrSet(ctx context.Context, kv rawBatchKV, s serializer.Serializer, logger clog.Logger, fallback deserializeFallback, keys []string, destSlice any, ttl time.Duration, load MLoadFunc) error {
+	if load == nil {
+		return xerrors.New("cache: load func is nil")
+	}
+	v := reflect.ValueOf(destSlice)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Slice {
+		return xerrors.New("destSlice must be a pointer to slice")
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	raws, err := kv.mgetRaw(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	sliceVal := v.Elem()
+	newSlice := reflect.MakeSlice(sliceVal.Type(), len(keys), len(keys))
+	if err := decodeHits(ctx, s, fallback, kv, keys, raws, newSlice); err != nil {
+		return err
+	}
+
+	// 收集未命中的 key，重复 key 只回源一次
+	var missing []string
+	seen := make(map[string]struct{})
+	for i, raw := range raws {
+		if raw != nil {
+			continue
+		}
+		if _, ok := seen[keys[i]]; !ok {
+			seen[keys[i]] = struct{}{}
+			missing = append(missing, keys[i])
+		}
+	}
+
+	if len(missing) > 0 {
+		loaded, err := load(ctx, missing)
+		if err != nil {
+			return err
+		}
+		items := make(map[string]any, len(loaded))
+		encoded := make(map[string][]byte, len(loaded))
+		for _, key := range missing {
+			value, ok := loaded[key]
+			if !ok {
+				continue
+			}
+			data, err := s.Marshal(value)
+			if err != nil {
+				return err
+			}
+			items[key] = value
+			encoded[key] = data
+		}
+		for i, raw := range raws {
+			data, ok := encoded[keys[i]]
+			if raw != nil || !ok {
+				continue
+			}
+			if err := unmarshalElem(s, newSlice.Index(i), data); err != nil {
+				return err
+			}
+		}
+		if len(items) > 0 {
+			if err := kv.MSet(ctx, items, ttl); err != nil {
+				logger.WarnContext(ctx, "Cache batch backfill failed", clog.Int("keys", len(items)), clog.Error(err))
+			}
+		}
+	}
+
+	sliceVal.Set(newSlice)
+	return nil
+}
+
+// decodeHits 把命中的原始值解码到 slice 对应元素
+//
+// 解码失败时按 fallback 策略返回错误，或把该元素置零、raws 对应位置置 nil 视为未命中。
+func decodeHits(ctx context.Context, s serializer.Serializer, fallback deserializeFallback, kv rawBatchKV, keys []string, raws [][]byte, slice reflect.Value) error {
+	var bad []string
+	var decodeErr error
+	for i, raw := range raws {
+		if raw == nil {
+			continue
+		}
+		err := unmarshalElem(s, slice.Index(i), raw)
+		if err == nil {
+			continue
+		}
+		if !fallback.asMiss() {
+			return err
+		}
+		slice.Index(i).SetZero()
+		raws[i] = nil
+		bad = append(bad, keys[i])
+		decodeErr = err
+	}
+	if len(bad) > 0 {
+		// 视为未命中时 handle 只返回 ErrMiss，由调用方按未命中处理
+		_ = fallback.handle(ctx, bad, decodeErr, kv.deleteRaw)
+	}
+	return nil
+}
+
+// unmarshalElem 把 data 反序列化到切片元素，元素为指针类型时分配新值
+func unmarshalElem(s serializer.Serializer, elem reflect.Value, data []byte) error {
+	if elem.Kind() == reflect.Pointer {
+		val := reflect.New(elem.Type().Elem())
+		if err := s.Unmarshal(data, val.Interface()); err != nil {
+			return err
+		}
+		elem.Set(val)
+		return nil
+	}
+	return s.Unmarshal(data, elem.Addr().Interface())
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/cache/serializer"
+	"github.com/ceyewan/genesis/clog"
+)
+
+// fakeBatchKV 以序列化字节存储的内存批量后端
+type fakeBatchKV struct {
+	s       serializer.Serializer
+	data    map[string][]byte
+	failSet bool
+}
+
+func newFakeBatchKV(t *testing.T) *fakeBatchKV {
+	t.Helper()
+	s, err := serializer.New("json")
+	require.NoError(t, err)
+	return &fakeBatchKV{s: s, data: make(map[string][]byte)}
+}
+
+func (f *fakeBatchKV) mgetRaw(ctx context.Context, keys []string) ([][]byte, error) {
+	raws := make([][]byte, len(keys))
+	for i, key := range keys {
+		raws[i] = f.data[key]
+	}
+	return raws, nil
+}
+
+func (f *fakeBatchKV) deleteRaw(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		delete(f.data, key)
+	}
+	return nil
+}
+
+func (f *fakeBatchKV) MSet(ctx context.Context, items map[string]any, ttl time.Duration) error {
+	if f.failSet {
+		return errors.New("mset error")
+	}
+	for key, value := range items {
+		data, err := f.s.Marshal(value)
+		if err != nil {
+			return err
+		}
+		f.data[key] = data
+	}
+	return nil
+}
+
+func TestMGetOrSet(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("只对未命中的 key 回源并回填", func(t *testing.T) {
+		kv := newFakeBatchKV(t)
+		require.NoError(t, kv.MSet(ctx, map[string]any{"user:1": staleUser{Name: "alice"}}, time.Minute))
+
+		var loaded [][]string
+		load := func(ctx context.Context, missing []string) (map[string]any, error) {
+			loaded = append(loaded, missing)
+			return map[string]any{"user:2": staleUser{Name: "bob"}}, nil
+		}
+
+		var got []*staleUser
+		err := mgetOrSet(ctx, kv, kv.s, clog.Discard(), deserializeFallback{}, []string{"user:1", "user:2", "user:3", "user:2"}, &got, time.Minute, load)
+		require.NoError(t, err)
+		require.Equal(t, [][]string{{"user:2", "user:3"}}, loaded, "loader 只收到去重后的 miss key")
+		require.Len(t, got, 4)
+		require.Equal(t, "alice", got[0].Name)
+		require.Equal(t, "bob", got[1].Name)
+		require.Nil(t, got[2], "回源结果中不存在的 key 保持零值")
+		require.Equal(t, "bob", got[3].Name)
+
+		require.Contains(t, kv.data, "user:2", "回源结果写回缓存")
+		require.NotContains(t, kv.data, "user:3")
+	})
+
+	t.Run("全部命中时不回源", func(t *testing.T) {
+		kv := newFakeBatchKV(t)
+		require.NoError(t, kv.MSet(ctx, map[string]any{"a": 1, "b": 2}, time.Minute))
+
+		load := func(ctx context.Context, missing []string) (map[string]any, error) {
+			t.Fatalf("unexpected load: %v", missing)
+			return nil, nil
+		}
+
+		var got []int
+		require.NoError(t, mgetOrSet(ctx, kv, kv.s, clog.Discard(), deserializeFallback{}, []string{"a", "b"}, &got, time.Minute, load))
+		require.Equal(t, []int{1, 2}, got)
+	})
+
+	t.Run("回源失败返回错误，回填失败不影响结果", func(t *testing.T) {
+		kv := newFakeBatchKV(t)
+		loadErr := errors.New("db down")
+
+		var got []int
+		err := mgetOrSet(ctx, kv, kv.s, clog.Discard(), deserializeFallback{}, []string{"a"}, &got, time.Minute,
+			func(ctx context.Context, missing []string) (map[string]any, error) { return nil, loadErr })
+		require.ErrorIs(t, err, loadErr)
+
+		kv.failSet = true
+		err = mgetOrSet(ctx, kv, kv.s, clog.Discard(), deserializeFallback{}, []string{"a"}, &got, time.Minute,
+			func(ctx context.Context, missing []string) (map[string]any, error) {
+				return map[string]any{"a": 7}, nil
+			})
+		require.NoError(t, err)
+		require.Equal(t, []int{7}, got)
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		kv := newFakeBatchKV(t)
+		var got []int
+		require.Error(t, mgetOrSet(ctx, kv, kv.s, clog.Discard(), deserializeFallback{}, []string{"a"}, &got, time.Minute, nil))
+		require.Error(t, mgetOrSet(ctx, kv, kv.s, clog.Discard(), deserializeFallback{}, []string{"a"}, got, time.Minute,
+			func(ctx context.Context, missing []string) (map[string]any, error) { return nil, nil }))
+	})
+}
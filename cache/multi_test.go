@@ -204,6 +204,58 @@ func (m *mockKVForMulti) MSet(ctx context.Context, items map[string]any, ttl tim
 	return ErrNotSupported
 }
 
+func (m *mockKVForMulti) GetWithVersion(ctx context.Context, key string, dest any) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+func (m *mockKVForMulti) SetWithCAS(ctx context.Context, key string, value any, expectedVersion int64, ttl time.Duration) (bool, error) {
+	return false, ErrNotSupported
+}
+
+func (m *mockKVForMulti) SetWithTags(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) error {
+	return ErrNotSupported
+}
+
+func (m *mockKVForMulti) InvalidateByTag(ctx context.Context, tag string) error {
+	return ErrNotSupported
+}
+
+func (m *mockKVForMulti) PFAdd(ctx context.Context, key string, items ...string) error {
+	return ErrNotSupported
+}
+
+func (m *mockKVForMulti) PFCount(ctx context.Context, keys ...string) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+func (m *mockKVForMulti) PFMerge(ctx context.Context, dest string, keys ...string) error {
+	return ErrNotSupported
+}
+
+func (m *mockKVForMulti) Semaphore(name string, max int, opts ...SemaphoreOption) Semaphore {
+	return nil
+}
+
+func (m *mockKVForMulti) DelayedDoubleDelete(ctx context.Context, key string, delay time.Duration) error {
+	return ErrNotSupported
+}
+
+func (m *mockKVForMulti) MGetOrSet(ctx context.Context, keys []string, destSlice any, ttl time.Duration, load MLoadFunc) error {
+	return ErrNotSupported
+}
+
+func (m *mockKVForMulti) GetOrSet(ctx context.Context, key string, dest any, ttl time.Duration, load LoadFunc, opts ...GetOrSetOption) (bool, error) {
+	return false, ErrNotSupported
+}
+
+func (m *mockKVForMulti) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return 0, ErrNotSupported
+}
+
+func (m *mockKVForMulti) ExpireBatch(ctx context.Context, keys []string, ttl time.Duration) error {
+	return ErrNotSupported
+}
+
 func (m *mockKVForMulti) RawClient() any {
 	return nil
 }
@@ -13,6 +13,8 @@ type options struct {
 	Logger    clog.Logger
 	Meter     metrics.Meter
 	RedisConn connector.RedisConnector
+
+	OnDeserializeError DeserializeErrorStrategy
 }
 
 // WithLogger 注入日志记录器。
@@ -39,3 +41,13 @@ func WithRedisConnector(conn connector.RedisConnector) Option {
 		}
 	}
 }
+
+// WithOnDeserializeError 设置缓存值无法反序列化时的处理策略（默认 DeserializeErrorReturn）。
+//
+// 结构体演进后读到旧格式数据时，可改为 DeserializeErrorTreatAsMiss 或 DeserializeErrorDeleteAndMiss，
+// 让 Get / MGet 按未命中处理、GetOrSet / MGetOrSet 回源重建，平滑过渡。
+func WithOnDeserializeError(strategy DeserializeErrorStrategy) Option {
+	return func(o *options) {
+		o.OnDeserializeError = strategy
+	}
+}
@@ -20,12 +20,16 @@ type redisCache struct {
 	serializer serializer.Serializer
 	prefix     string
 	defaultTTL time.Duration
+	ttlJitter  time.Duration
 	logger     clog.Logger
 	meter      metrics.Meter
+	stale      *staleStore
+	deleter    *doubleDeleter
+	fallback   deserializeFallback
 }
 
 // newRedis 创建 Redis 缓存实例
-func newRedis(conn connector.RedisConnector, cfg *DistributedConfig, logger clog.Logger, meter metrics.Meter) (Distributed, error) {
+func newRedis(conn connector.RedisConnector, cfg *DistributedConfig, logger clog.Logger, meter metrics.Meter, onDeserializeError DeserializeErrorStrategy) (Distributed, error) {
 	if conn == nil {
 		return nil, ErrRedisConnectorRequired
 	}
@@ -43,13 +47,22 @@ func newRedis(conn connector.RedisConnector, cfg *DistributedConfig, logger clog
 		return nil, err
 	}
 
+	stale, err := newStaleStore(s, logger, cfg.StaleSnapshotSize)
+	if err != nil {
+		return nil, err
+	}
+
 	return &redisCache{
 		client:     conn.GetClient(),
 		serializer: s,
 		prefix:     cfg.KeyPrefix,
 		defaultTTL: cfg.DefaultTTL,
+		ttlJitter:  cfg.TTLJitter,
 		logger:     logger,
 		meter:      meter,
+		stale:      stale,
+		deleter:    newDoubleDeleter(logger),
+		fallback:   deserializeFallback{strategy: onDeserializeError, logger: logger},
 	}, nil
 }
 
@@ -57,6 +70,14 @@ func (c *redisCache) getKey(key string) string {
 	return c.prefix + key
 }
 
+func (c *redisCache) getKeys(keys []string) []string {
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = c.getKey(key)
+	}
+	return prefixed
+}
+
 func (c *redisCache) marshal(value any) ([]byte, error) {
 	return c.serializer.Marshal(value)
 }
@@ -75,6 +96,7 @@ func (c *redisCache) Set(ctx context.Context, key string, value any, ttl time.Du
 	if ttl <= 0 {
 		ttl = c.defaultTTL
 	}
+	ttl = applyTTLJitter(ttl, c.ttlJitter)
 	if err := c.client.Set(ctx, c.getKey(key), data, ttl).Err(); err != nil {
 		c.logger.ErrorContext(ctx, "Cache set failed", clog.String("key", key), clog.Error(err))
 		return err
@@ -91,13 +113,29 @@ func (c *redisCache) Get(ctx context.Context, key string, dest any) error {
 		}
 		return err
 	}
-	return c.unmarshal(data, dest)
+	if err := c.unmarshal(data, dest); err != nil {
+		return c.fallback.handle(ctx, []string{key}, err, c.deleteRaw)
+	}
+	return nil
+}
+
+func (c *redisCache) GetOrSet(ctx context.Context, key string, dest any, ttl time.Duration, load LoadFunc, opts ...GetOrSetOption) (bool, error) {
+	return c.stale.getOrSet(ctx, c, key, dest, ttl, load, opts...)
 }
 
 func (c *redisCache) Delete(ctx context.Context, key string) error {
 	return c.client.Del(ctx, c.getKey(key)).Err()
 }
 
+// deleteRaw 删除无法反序列化的坏数据
+func (c *redisCache) deleteRaw(ctx context.Context, keys []string) error {
+	return c.client.Del(ctx, c.getKeys(keys)...).Err()
+}
+
+func (c *redisCache) DelayedDoubleDelete(ctx context.Context, key string, delay time.Duration) error {
+	return c.deleter.run(ctx, c, key, delay)
+}
+
 func (c *redisCache) Has(ctx context.Context, key string) (bool, error) {
 	n, err := c.client.Exists(ctx, c.getKey(key)).Result()
 	if err != nil {
@@ -117,6 +155,21 @@ func (c *redisCache) Expire(ctx context.Context, key string, ttl time.Duration)
 	return ok, nil
 }
 
+// TTL 基于 PTTL 查询剩余时间。go-redis 对 -1/-2 原样返回（未乘以精度单位），这里映射为约定常量。
+func (c *redisCache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.client.PTTL(ctx, c.getKey(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+	switch ttl {
+	case -1:
+		return TTLPersistent, nil
+	case -2:
+		return TTLNotFound, nil
+	}
+	return ttl, nil
+}
+
 // --- 哈希（Hash） ---
 
 func (c *redisCache) HSet(ctx context.Context, key, field string, value any) error {
@@ -246,6 +299,23 @@ func (c *redisCache) MGet(ctx context.Context, keys []string, destSlice any) err
 		return xerrors.New("destSlice must be a pointer to slice")
 	}
 
+	raws, err := c.mgetRaw(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	sliceVal := v.Elem()
+	newSlice := reflect.MakeSlice(sliceVal.Type(), len(raws), len(raws))
+	if err := decodeHits(ctx, c.serializer, c.fallback, c, keys, raws, newSlice); err != nil {
+		return err
+	}
+
+	sliceVal.Set(newSlice)
+	return nil
+}
+
+// mgetRaw 通过 MGET 按 keys 顺序读取原始值，未命中的位置为 nil
+func (c *redisCache) mgetRaw(ctx context.Context, keys []string) ([][]byte, error) {
 	prefixedKeys := make([]string, len(keys))
 	for i, k := range keys {
 		prefixedKeys[i] = c.getKey(k)
@@ -253,42 +323,25 @@ func (c *redisCache) MGet(ctx context.Context, keys []string, destSlice any) err
 
 	results, err := c.client.MGet(ctx, prefixedKeys...).Result()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	sliceVal := v.Elem()
-	elemType := sliceVal.Type().Elem()
-	newSlice := reflect.MakeSlice(sliceVal.Type(), len(results), len(results))
-
+	raws := make([][]byte, len(results))
 	for i, result := range results {
-		elem := newSlice.Index(i)
 		if result == nil {
 			continue
 		}
-
 		data, ok := result.(string)
 		if !ok {
-			return xerrors.New("unexpected result type from MGET")
-		}
-
-		var target any
-		if elemType.Kind() == reflect.Pointer {
-			val := reflect.New(elemType.Elem())
-			target = val.Interface()
-			if err := c.unmarshal([]byte(data), target); err != nil {
-				return err
-			}
-			elem.Set(val)
-		} else {
-			target = elem.Addr().Interface()
-			if err := c.unmarshal([]byte(data), target); err != nil {
-				return err
-			}
+			return nil, xerrors.New("unexpected result type from MGET")
 		}
+		raws[i] = []byte(data)
 	}
+	return raws, nil
+}
 
-	sliceVal.Set(newSlice)
-	return nil
+func (c *redisCache) MGetOrSet(ctx context.Context, keys []string, destSlice any, ttl time.Duration, load MLoadFunc) error {
+	return mgetOrSet(ctx, c, c.serializer, c.logger, c.fallback, keys, destSlice, ttl, load)
 }
 
 func (c *redisCache) MSet(ctx context.Context, items map[string]any, ttl time.Duration) error {
@@ -306,13 +359,212 @@ func (c *redisCache) MSet(ctx context.Context, items map[string]any, ttl time.Du
 		if err != nil {
 			return err
 		}
-		pipe.Set(ctx, c.getKey(k), data, ttl)
+		pipe.Set(ctx, c.getKey(k), data, applyTTLJitter(ttl, c.ttlJitter))
 	}
 
 	_, err := pipe.Exec(ctx)
 	return err
 }
 
+// ExpireBatch 在一次 Pipeline 中为多个 key 续期，ttl<=0 时使用 DefaultTTL。
+func (c *redisCache) ExpireBatch(ctx context.Context, keys []string, ttl time.Duration) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+
+	pipe := c.client.Pipeline()
+	for _, key := range keys {
+		pipe.PExpire(ctx, c.getKey(key), ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logger.ErrorContext(ctx, "Cache expire batch failed", clog.Int("keys", len(keys)), clog.Error(err))
+		return err
+	}
+	return nil
+}
+
+// --- 乐观并发（CAS） ---
+
+// 带版本号的值以 Hash 存储：ver 为版本号，val 为序列化后的数据。
+// 同一个 key 只能通过 GetWithVersion / SetWithCAS 访问，不要与 Set / Get 混用。
+const (
+	casVersionField = "ver"
+	casValueField   = "val"
+)
+
+// casScript 比较当前版本与期望版本，一致时写入新值、递增版本并刷新 TTL。
+// 返回新版本号；版本不匹配时返回 0。
+var casScript = redis.NewScript(`
+	local current = tonumber(redis.call("HGET", KEYS[1], ARGV[1]) or "0")
+	if current ~= tonumber(ARGV[3]) then
+		return 0
+	end
+	local next = current + 1
+	redis.call("HSET", KEYS[1], ARGV[1], next, ARGV[2], ARGV[4])
+	redis.call("PEXPIRE", KEYS[1], ARGV[5])
+	return next
+`)
+
+func (c *redisCache) GetWithVersion(ctx context.Context, key string, dest any) (int64, error) {
+	values, err := c.client.HMGet(ctx, c.getKey(key), casVersionField, casValueField).Result()
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Cache get with version failed", clog.String("key", key), clog.Error(err))
+		return 0, err
+	}
+
+	rawVersion, ok1 := values[0].(string)
+	rawValue, ok2 := values[1].(string)
+	if !ok1 || !ok2 {
+		return 0, ErrMiss
+	}
+
+	version, err := strconv.ParseInt(rawVersion, 10, 64)
+	if err != nil {
+		return 0, xerrors.Wrapf(err, "cache: invalid version for key %s", key)
+	}
+	if err := c.unmarshal([]byte(rawValue), dest); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+func (c *redisCache) SetWithCAS(ctx context.Context, key string, value any, expectedVersion int64, ttl time.Duration) (bool, error) {
+	if expectedVersion < 0 {
+		return false, xerrors.New("cache: expected version must be >= 0")
+	}
+
+	data, err := c.marshal(value)
+	if err != nil {
+		return false, err
+	}
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	ttl = applyTTLJitter(ttl, c.ttlJitter)
+
+	next, err := casScript.Run(ctx, c.client, []string{c.getKey(key)},
+		casVersionField, casValueField, expectedVersion, data, ttl.Milliseconds()).Int64()
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Cache set with cas failed", clog.String("key", key), clog.Error(err))
+		return false, err
+	}
+	return next > 0, nil
+}
+
+// --- 标签失效（Tag） ---
+
+// tagKeyPrefix tag 集合的 key 前缀（位于 KeyPrefix 之后），集合成员为带 KeyPrefix 的完整 key。
+const tagKeyPrefix = "__tag__:"
+
+// invalidateBatchSize InvalidateByTag 单次 DEL 的 key 数量，避免 unpack 超出 Lua 栈限制
+const invalidateBatchSize = 500
+
+// setWithTagsScript 写入值并把 key 加入各 tag 集合。
+// tag 集合的 TTL 只延长不缩短，保证不早于其中的 key 过期。
+// KEYS[1] 为数据 key，KEYS[2..] 为 tag 集合；ARGV[1] 为值，ARGV[2] 为 TTL（毫秒）。
+var setWithTagsScript = redis.NewScript(`
+	local ttl = tonumber(ARGV[2])
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ttl)
+	for i = 2, #KEYS do
+		redis.call("SADD", KEYS[i], KEYS[1])
+		-- 新建集合 PTTL 为 -1，同样需要设置过期时间
+		if redis.call("PTTL", KEYS[i]) < ttl then
+			redis.call("PEXPIRE", KEYS[i], ttl)
+		end
+	end
+	return 1
+`)
+
+// invalidateTagScript 原子地删除 tag 集合中的所有 key 及集合本身，返回删除的 key 数量。
+var invalidateTagScript = redis.NewScript(`
+	local members = redis.call("SMEMBERS", KEYS[1])
+	local deleted = 0
+	local batch = tonumber(ARGV[1])
+	for i = 1, #members, batch do
+		deleted = deleted + redis.call("DEL", unpack(members, i, math.min(i + batch - 1, #members)))
+	end
+	redis.call("DEL", KEYS[1])
+	return deleted
+`)
+
+func (c *redisCache) getTagKey(tag string) string {
+	return c.getKey(tagKeyPrefix + tag)
+}
+
+func (c *redisCache) SetWithTags(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) error {
+	if len(tags) == 0 {
+		return c.Set(ctx, key, value, ttl)
+	}
+
+	data, err := c.marshal(value)
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		ttl = c.defaultTTL
+	}
+	ttl = applyTTLJitter(ttl, c.ttlJitter)
+
+	keys := make([]string, 0, len(tags)+1)
+	keys = append(keys, c.getKey(key))
+	for _, tag := range tags {
+		if tag == "" {
+			return xerrors.New("cache: tag must not be empty")
+		}
+		keys = append(keys, c.getTagKey(tag))
+	}
+
+	if err := setWithTagsScript.Run(ctx, c.client, keys, data, ttl.Milliseconds()).Err(); err != nil {
+		c.logger.ErrorContext(ctx, "Cache set with tags failed", clog.String("key", key), clog.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (c *redisCache) InvalidateByTag(ctx context.Context, tag string) error {
+	if tag == "" {
+		return xerrors.New("cache: tag must not be empty")
+	}
+
+	deleted, err := invalidateTagScript.Run(ctx, c.client, []string{c.getTagKey(tag)}, invalidateBatchSize).Int64()
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Cache invalidate by tag failed", clog.String("tag", tag), clog.Error(err))
+		return err
+	}
+	c.logger.DebugContext(ctx, "Cache invalidated by tag", clog.String("tag", tag), clog.Int64("deleted", deleted))
+	return nil
+}
+
+// --- 基数统计（HyperLogLog） ---
+
+func (c *redisCache) PFAdd(ctx context.Context, key string, items ...string) error {
+	if len(items) == 0 {
+		return nil
+	}
+	elements := make([]any, len(items))
+	for i, item := range items {
+		elements[i] = item
+	}
+	return c.client.PFAdd(ctx, c.getKey(key), elements...).Err()
+}
+
+func (c *redisCache) PFCount(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, xerrors.New("cache: at least one key is required")
+	}
+	return c.client.PFCount(ctx, c.getKeys(keys)...).Result()
+}
+
+func (c *redisCache) PFMerge(ctx context.Context, dest string, keys ...string) error {
+	if len(keys) == 0 {
+		return xerrors.New("cache: at least one source key is required")
+	}
+	return c.client.PFMerge(ctx, c.getKey(dest), c.getKeys(keys)...).Err()
+}
+
 // --- 高级操作（Advanced） ---
 
 // RawClient 返回底层 Redis 客户端，用于执行 Pipeline、Lua 脚本等高级操作。
@@ -322,8 +574,9 @@ func (c *redisCache) RawClient() any {
 
 // --- 工具与辅助函数 ---
 
-// Close 是 no-op：Cache 不拥有 Redis 连接，由 Connector 管理。
+// Close 只停止本地旧值快照的后台清理：Cache 不拥有 Redis 连接，由 Connector 管理。
 func (c *redisCache) Close() error {
+	c.stale.close()
 	return nil
 }
 
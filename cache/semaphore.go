@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+const (
+	// semaphoreKeyPrefix 信号量 ZSet 的 key 前缀（位于 KeyPrefix 之后）
+	semaphoreKeyPrefix = "__sem__:"
+
+	defaultSemaphoreTimeout       = 30 * time.Second
+	defaultSemaphoreRetryInterval = 50 * time.Millisecond
+)
+
+// Semaphore 基于 Redis 的分布式信号量（令牌池），限制跨实例同时持有资源的总数。
+//
+// 每个持有者对应 ZSet 中的一个 token，分数为获取（或续期）时的 Redis 服务器时间。
+// 持有超过 timeout 未续期的 token 视为僵尸持有，在下一次获取时被清理。
+type Semaphore interface {
+	// Acquire 获取一个令牌，已满时按重试间隔轮询等待，直到获取成功或 ctx 结束。
+	Acquire(ctx context.Context) (token string, err error)
+	// TryAcquire 尝试获取一个令牌，不等待；已满时返回 ok=false。
+	TryAcquire(ctx context.Context) (token string, ok bool, err error)
+	// Release 归还令牌；token 已超时被回收或不存在时返回 ErrSemaphoreNotHeld。
+	Release(ctx context.Context, token string) error
+	// Refresh 把令牌的持有时间重置为当前时间，用于持有时间可能超过 timeout 的长任务；
+	// token 已超时被回收或不存在时返回 ErrSemaphoreNotHeld。
+	Refresh(ctx context.Context, token string) error
+}
+
+// SemaphoreOption 信号量选项。
+type SemaphoreOption func(*semaphoreOptions)
+
+type semaphoreOptions struct {
+	timeout       time.Duration
+	retryInterval time.Duration
+}
+
+// WithSemaphoreTimeout 设置令牌的最长持有时间，默认 30s。
+//
+// 持有者崩溃未 Release 时，令牌在超时后自动回收；正常持有超过该时间需调用 Refresh 续期。
+func WithSemaphoreTimeout(timeout time.Duration) SemaphoreOption {
+	return func(o *semaphoreOptions) {
+		if timeout > 0 {
+			o.timeout = timeout
+		}
+	}
+}
+
+// WithSemaphoreRetryInterval 设置 Acquire 已满时的轮询间隔，默认 50ms。
+func WithSemaphoreRetryInterval(interval time.Duration) SemaphoreOption {
+	return func(o *semaphoreOptions) {
+		if interval > 0 {
+			o.retryInterval = interval
+		}
+	}
+}
+
+// semaphoreAcquireScript 清理超时令牌后，在未满时加入新令牌。
+// KEYS[1] 为 ZSet；ARGV[1] 为 token，ARGV[2] 为上限，ARGV[3] 为超时（毫秒）。
+var semaphoreAcquireScript = redis.NewScript(`
+	local t = redis.call("TIME")
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	local timeout = tonumber(ARGV[3])
+	redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - timeout)
+	if redis.call("ZCARD", KEYS[1]) >= tonumber(ARGV[2]) then
+		return 0
+	end
+	redis.call("ZADD", KEYS[1], now, ARGV[1])
+	redis.call("PEXPIRE", KEYS[1], timeout)
+	return 1
+`)
+
+// semaphoreRefreshScript 仅当令牌未超时时更新其分数。
+// KEYS[1] 为 ZSet；ARGV[1] 为 token，ARGV[2] 为超时（毫秒）。
+var semaphoreRefreshScript = redis.NewScript(`
+	local t = redis.call("TIME")
+	local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+	local timeout = tonumber(ARGV[2])
+	local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+	if not score or tonumber(score) <= now - timeout then
+		redis.call("ZREM", KEYS[1], ARGV[1])
+		return 0
+	end
+	redis.call("ZADD", KEYS[1], now, ARGV[1])
+	redis.call("PEXPIRE", KEYS[1], timeout)
+	return 1
+`)
+
+// redisSemaphore Semaphore 的 Redis 实现
+type redisSemaphore struct {
+	client *redis.Client
+	logger clog.Logger
+	key    string
+	name   string
+	max    int
+	opts   semaphoreOptions
+}
+
+// Semaphore 返回名为 name、最多 max 个并发持有者的分布式信号量。
+//
+// 同名信号量在所有实例间共享同一个令牌池，各实例应使用相同的 max 与 timeout。
+// max<=0 时 Acquire / TryAcquire 返回错误。
+func (c *redisCache) Semaphore(name string, max int, opts ...SemaphoreOption) Semaphore {
+	o := semaphoreOptions{
+		timeout:       defaultSemaphoreTimeout,
+		retryInterval: defaultSemaphoreRetryInterval,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &redisSemaphore{
+		client: c.client,
+		logger: c.logger,
+		key:    c.getKey(semaphoreKeyPrefix + name),
+		name:   name,
+		max:    max,
+		opts:   o,
+	}
+}
+
+func (s *redisSemaphore) Acquire(ctx context.Context) (string, error) {
+	for {
+		token, ok, err := s.TryAcquire(ctx)
+		if err != nil || ok {
+			return token, err
+		}
+
+		timer := time.NewTimer(s.opts.retryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", xerrors.Wrapf(ctx.Err(), "cache: acquire semaphore %s", s.name)
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *redisSemaphore) TryAcquire(ctx context.Context) (string, bool, error) {
+	if s.max <= 0 {
+		return "", false, xerrors.Wrapf(ErrInvalidSemaphore, "max must be positive, got %d", s.max)
+	}
+
+	token, err := newSemaphoreToken()
+	if err != nil {
+		return "", false, err
+	}
+	acquired, err := semaphoreAcquireScript.Run(ctx, s.client, []string{s.key},
+		token, s.max, s.opts.timeout.Milliseconds()).Int()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Cache semaphore acquire failed", clog.String("name", s.name), clog.Error(err))
+		return "", false, err
+	}
+	if acquired == 0 {
+		return "", false, nil
+	}
+	return token, true, nil
+}
+
+func (s *redisSemaphore) Release(ctx context.Context, token string) error {
+	removed, err := s.client.ZRem(ctx, s.key, token).Result()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Cache semaphore release failed", clog.String("name", s.name), clog.Error(err))
+		return err
+	}
+	if removed == 0 {
+		return ErrSemaphoreNotHeld
+	}
+	return nil
+}
+
+func (s *redisSemaphore) Refresh(ctx context.Context, token string) error {
+	refreshed, err := semaphoreRefreshScript.Run(ctx, s.client, []string{s.key},
+		token, s.opts.timeout.Milliseconds()).Int()
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Cache semaphore refresh failed", clog.String("name", s.name), clog.Error(err))
+		return err
+	}
+	if refreshed == 0 {
+		return ErrSemaphoreNotHeld
+	}
+	return nil
+}
+
+// newSemaphoreToken 生成随机令牌
+func newSemaphoreToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", xerrors.Wrap(err, "cache: generate semaphore token")
+	}
+	return hex.EncodeToString(b[:]), nil
+}
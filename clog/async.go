@@ -0,0 +1,205 @@
+package clog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultAsyncBufferSize 异步缓冲默认容量（条）
+	defaultAsyncBufferSize = 1024
+	// defaultAsyncFlushInterval 异步模式默认刷盘间隔
+	defaultAsyncFlushInterval = time.Second
+	// asyncWriteBufferSize 后台批量写入的字节缓冲大小，写满即落盘
+	asyncWriteBufferSize = 64 * 1024
+)
+
+// AsyncConfig 异步写入配置
+//
+// 开启后日志在调用方 goroutine 完成格式化，随后进入有界缓冲立即返回，
+// 由后台 goroutine 批量写入 Output，避免慢速文件或网络 I/O 阻塞业务。
+type AsyncConfig struct {
+	BufferSize    int           `json:"bufferSize" yaml:"bufferSize"`       // 缓冲容量（条），默认 1024
+	DropWhenFull  bool          `json:"dropWhenFull" yaml:"dropWhenFull"`   // 缓冲满时丢弃并计数，false 时阻塞等待
+	FlushInterval time.Duration `json:"flushInterval" yaml:"flushInterval"` // 后台刷盘间隔，默认 1s
+}
+
+// FullPolicy 异步缓冲已满时的处理策略
+type FullPolicy int
+
+const (
+	// BlockOnFull 阻塞调用方直到缓冲有空位，保证不丢日志（默认）
+	BlockOnFull FullPolicy = iota
+	// DropOnFull 立即丢弃并计数，保证业务 goroutine 不被日志阻塞
+	DropOnFull
+)
+
+// String 返回策略名称
+func (p FullPolicy) String() string {
+	switch p {
+	case BlockOnFull:
+		return "block"
+	case DropOnFull:
+		return "drop"
+	default:
+		return fmt.Sprintf("FullPolicy(%d)", int(p))
+	}
+}
+
+// validate 设置默认值并检查取值范围（内部使用）
+func (c *AsyncConfig) validate() error {
+	if c.BufferSize < 0 {
+		return fmt.Errorf("invalid async config: bufferSize %d must not be negative", c.BufferSize)
+	}
+	if c.FlushInterval < 0 {
+		return fmt.Errorf("invalid async config: flushInterval %s must not be negative", c.FlushInterval)
+	}
+	if c.BufferSize == 0 {
+		c.BufferSize = defaultAsyncBufferSize
+	}
+	if c.FlushInterval == 0 {
+		c.FlushInterval = defaultAsyncFlushInterval
+	}
+	return nil
+}
+
+// DroppedCount 返回异步模式下因缓冲已满或 Logger 已关闭而丢弃的日志条数
+//
+// 未开启异步写入的 Logger 始终返回 0。
+func DroppedCount(l Logger) uint64 {
+	impl, ok := l.(*loggerImpl)
+	if !ok {
+		return 0
+	}
+	h, ok := impl.handler.(*clogHandler)
+	if !ok || h.async == nil {
+		return 0
+	}
+	return h.async.dropped.Load()
+}
+
+// asyncWriter 基于有界 channel 的异步 writer。
+//
+// Write 只复制数据并入队；后台 goroutine 把条目写入 bufio.Writer，
+// 缓冲写满、到达刷盘间隔、Flush 或 Close 时落盘。
+//
+// Write 在读锁内完成"检查关闭 + 入队"，Close 持写锁关闭 closing，
+// 保证 closing 关闭后不会再有条目入队，后台 drain 能写完全部已入队日志。
+type asyncWriter struct {
+	buf          *bufio.Writer
+	entries      chan []byte
+	flushReq     chan chan struct{}
+	mu           sync.RWMutex // 读锁：Write 检查关闭并入队；写锁：Close 关闭 closing
+	closing      chan struct{}
+	done         chan struct{}
+	dropWhenFull bool
+	dropped      atomic.Uint64
+	closeOnce    sync.Once
+}
+
+// newAsyncWriter 创建异步 writer，policy 非 nil 时覆盖 cfg.DropWhenFull
+func newAsyncWriter(out io.Writer, cfg *AsyncConfig, policy *FullPolicy) *asyncWriter {
+	dropWhenFull := cfg.DropWhenFull
+	if policy != nil {
+		dropWhenFull = *policy == DropOnFull
+	}
+	w := &asyncWriter{
+		buf:          bufio.NewWriterSize(out, asyncWriteBufferSize),
+		entries:      make(chan []byte, cfg.BufferSize),
+		flushReq:     make(chan chan struct{}),
+		closing:      make(chan struct{}),
+		done:         make(chan struct{}),
+		dropWhenFull: dropWhenFull,
+	}
+	go w.run(cfg.FlushInterval)
+	return w
+}
+
+// Write 将一条日志放入缓冲，始终返回 len(p)，丢弃通过 DroppedCount 观察。
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	select {
+	case <-w.closing:
+		w.dropped.Add(1)
+		return len(p), nil
+	default:
+	}
+
+	// slog 会复用格式化缓冲区，入队前必须复制
+	entry := append([]byte(nil), p...)
+	if w.dropWhenFull {
+		select {
+		case w.entries <- entry:
+		default:
+			w.dropped.Add(1)
+		}
+		return len(p), nil
+	}
+
+	// 持有读锁期间 closing 不会关闭，后台 goroutine 仍在消费，阻塞终会解除
+	w.entries <- entry
+	return len(p), nil
+}
+
+// Flush 等待后台 goroutine 写完当前缓冲并落盘
+func (w *asyncWriter) Flush() {
+	ack := make(chan struct{})
+	select {
+	case w.flushReq <- ack:
+		<-ack
+	case <-w.done:
+	}
+}
+
+// Close 停止接收新日志，写完缓冲中的全部日志后返回，可重复调用
+//
+// 写锁等待进行中的 Write 入队完成，之后的 Write 都会看到 closing 并计入丢弃。
+func (w *asyncWriter) Close() {
+	w.closeOnce.Do(func() {
+		w.mu.Lock()
+		close(w.closing)
+		w.mu.Unlock()
+	})
+	<-w.done
+}
+
+func (w *asyncWriter) run(interval time.Duration) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case entry := <-w.entries:
+			_, _ = w.buf.Write(entry)
+		case <-ticker.C:
+			_ = w.buf.Flush()
+		case ack := <-w.flushReq:
+			w.drain()
+			close(ack)
+		case <-w.closing:
+			w.drain()
+			return
+		}
+	}
+}
+
+// drain 写出 channel 中已有的全部条目并落盘
+func (w *asyncWriter) drain() {
+	for {
+		select {
+		case entry := <-w.entries:
+			_, _ = w.buf.Write(entry)
+		default:
+			_ = w.buf.Flush()
+			return
+		}
+	}
+}
@@ -2,14 +2,23 @@ package clog
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"errors"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // TestNew 测试 Logger 创建
@@ -340,6 +349,51 @@ func TestLoggerWithNamespace_DerivedLoggerDoesNotMutateSiblings(t *testing.T) {
 	}
 }
 
+func TestLoggerWithNamespaceFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(&Config{
+		Level:  "debug",
+		Format: "json",
+		Output: "buffer",
+	},
+		withBuffer(&buf),
+		WithNamespace("service"),
+	)
+
+	payment := logger.WithNamespaceFields("payment", String("component", "payment"))
+	sibling := logger.WithNamespace("order")
+
+	payment.Info("charge")
+	payment.WithNamespace("refund").Info("refund")
+	sibling.Info("create")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 log lines, got %d: %q", len(lines), buf.String())
+	}
+
+	tests := []struct {
+		namespace string
+		component any
+	}{
+		{"service.payment", "payment"},
+		{"service.payment.refund", "payment"},
+		{"service.order", nil},
+	}
+	for i, tt := range tests {
+		var logEntry map[string]any
+		if err := json.Unmarshal([]byte(lines[i]), &logEntry); err != nil {
+			t.Fatalf("Failed to parse log entry: %v", err)
+		}
+		if logEntry["namespace"] != tt.namespace {
+			t.Errorf("line %d: Expected namespace = %s, got %v", i, tt.namespace, logEntry["namespace"])
+		}
+		if logEntry["component"] != tt.component {
+			t.Errorf("line %d: Expected component = %v, got %v", i, tt.component, logEntry["component"])
+		}
+	}
+}
+
 // TestLoggerWith 测试 With 功能
 func TestLoggerWith(t *testing.T) {
 	var buf bytes.Buffer
@@ -409,6 +463,98 @@ func TestLoggerWith_DerivedLoggerDoesNotMutateSiblings(t *testing.T) {
 	}
 }
 
+// TestLoggerWith_Preencoded 预编码的 With 字段与逐条编码的输出一致
+func TestLoggerWith_Preencoded(t *testing.T) {
+	timePattern := regexp.MustCompile(`"time":"[^"]*",?|time=\S+ ?`)
+
+	render := func(t *testing.T, cfg Config, preencode bool, emit func(Logger)) string {
+		t.Helper()
+		var buf bytes.Buffer
+		cfg.Level, cfg.Output = "debug", "buffer"
+		logger, err := New(&cfg, withBuffer(&buf))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		logger.(*loggerImpl).preencode = preencode
+		emit(logger)
+		return timePattern.ReplaceAllString(buf.String(), "")
+	}
+
+	emit := func(logger Logger) {
+		base := logger.WithNamespace("svc").With(String("service", "order"), Group("req", String("id", "r-1"), Int("n", 2)))
+		child := base.With(Error(errors.New("boom")), Int("uid", 7))
+		child.Info("first", String("k", "v"))
+		_ = base.With(String("uid", "sibling"))
+		child.WithNamespace("repo").Warn("second")
+		base.Debug("third")
+	}
+
+	for name, cfg := range map[string]Config{
+		"json":    {Format: "json"},
+		"console": {Format: "console"},
+		"flatten": {Format: "json", FieldKeys: &FieldKeys{Flatten: true}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			want := render(t, cfg, false, emit)
+			if want == "" {
+				t.Fatal("Expected log output")
+			}
+			got := render(t, cfg, true, emit)
+			if got != want {
+				t.Fatalf("preencoded output differs\n got: %s\nwant: %s", got, want)
+			}
+		})
+	}
+
+	t.Run("Lazy 字段仍按条求值", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, _ := New(&Config{Level: "info", Format: "json", Output: "buffer"}, withBuffer(&buf))
+		calls := 0
+		child := logger.With(String("a", "1")).With(Lazy("lazy", func() any {
+			calls++
+			return calls
+		}), String("b", "2"))
+
+		child.Debug("skipped")
+		child.Info("one")
+		child.Info("two")
+		if calls != 2 {
+			t.Fatalf("Lazy evaluated %d times, want 2", calls)
+		}
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if !strings.Contains(lines[1], `"a":"1","lazy":2,"b":"2"`) {
+			t.Fatalf("Unexpected field order or value: %s", lines[1])
+		}
+	})
+
+	t.Run("派生 Logger 共享级别与刷新", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, _ := New(&Config{Level: "info", Format: "json", Output: "buffer"}, withBuffer(&buf))
+		child := logger.With(String("k", "v"))
+		if err := child.SetLevel(ErrorLevel); err != nil {
+			t.Fatalf("SetLevel() error = %v", err)
+		}
+		logger.Warn("dropped")
+		child.Warn("dropped")
+		if buf.Len() != 0 {
+			t.Fatalf("SetLevel on derived logger should affect root, got %q", buf.String())
+		}
+	})
+
+	t.Run("开启去重时不预编码", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, _ := New(&Config{Level: "info", Format: "json", Output: "buffer"},
+			withBuffer(&buf), WithDedup(time.Hour, "user_id"))
+		logger.With(String("user_id", "u1")).Error("failed")
+		logger.With(String("user_id", "u2")).Error("failed")
+		logger.With(String("user_id", "u1")).Error("failed")
+		if n := strings.Count(buf.String(), "\n"); n != 2 {
+			t.Fatalf("Expected 2 lines (distinct user_id), got %d: %s", n, buf.String())
+		}
+	})
+}
+
 // TestConfigValidation 测试配置验证
 func TestConfigValidation(t *testing.T) {
 	tests := []struct {
@@ -462,6 +608,34 @@ func TestConfigValidation(t *testing.T) {
 			},
 			wantOk: true,
 		},
+		{
+			name: "custom time format and zone",
+			config: Config{
+				Level:      "info",
+				Format:     "json",
+				TimeFormat: time.DateTime,
+				TimeZone:   "Asia/Shanghai",
+			},
+			wantOk: true,
+		},
+		{
+			name: "invalid time format",
+			config: Config{
+				Level:      "info",
+				Format:     "json",
+				TimeFormat: "yyyy-MM-dd HH:mm:ss",
+			},
+			wantOk: false,
+		},
+		{
+			name: "invalid time zone",
+			config: Config{
+				Level:    "info",
+				Format:   "json",
+				TimeZone: "Mars/Olympus",
+			},
+			wantOk: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -584,6 +758,156 @@ func TestFieldFunctions(t *testing.T) {
 	}
 }
 
+// TestLazyField 测试延迟求值字段只在级别启用时求值一次
+func TestLazyField(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(&Config{
+		Level:  "info",
+		Format: "json",
+		Output: "buffer",
+	}, withBuffer(&buf))
+
+	calls := 0
+	lazy := Lazy("payload", func() any {
+		calls++
+		return map[string]int{"size": 3}
+	})
+
+	logger.Debug("skipped", lazy)
+	if calls != 0 {
+		t.Errorf("Lazy fn called %d times for disabled level, want 0", calls)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no output for debug log, got %q", buf.String())
+	}
+
+	logger.Info("emitted", lazy, Group("req", Lazy("id", func() any {
+		calls++
+		return "r-1"
+	})))
+	if calls != 2 {
+		t.Errorf("Lazy fn called %d times, want 2 (once per field)", calls)
+	}
+
+	var logEntry map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &logEntry); err != nil {
+		t.Fatalf("Failed to parse log entry: %v", err)
+	}
+	if payload, ok := logEntry["payload"].(map[string]any); !ok || payload["size"] != float64(3) {
+		t.Errorf("payload = %v, want map with size=3", logEntry["payload"])
+	}
+	if req, ok := logEntry["req"].(map[string]any); !ok || req["id"] != "r-1" {
+		t.Errorf("req = %v, want group with id=r-1", logEntry["req"])
+	}
+}
+
+// TestFieldKeys 测试 JSON 字段名映射、预设与扁平化
+func TestFieldKeys(t *testing.T) {
+	// logOnce 按 keys 输出一条日志并解析为 map
+	logOnce := func(t *testing.T, keys *FieldKeys, fields ...Field) map[string]any {
+		t.Helper()
+		var buf bytes.Buffer
+		logger, err := New(&Config{
+			Level:     "info",
+			Format:    "json",
+			Output:    "buffer",
+			AddSource: true,
+			FieldKeys: keys,
+		}, withBuffer(&buf))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		logger.Info("hello", fields...)
+
+		var logEntry map[string]any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &logEntry); err != nil {
+			t.Fatalf("Failed to parse log entry: %v", err)
+		}
+		return logEntry
+	}
+	// requireKeys 校验 want 中的键都存在、absent 中的键都不存在
+	requireKeys := func(t *testing.T, entry map[string]any, want, absent []string) {
+		t.Helper()
+		for _, k := range want {
+			if _, ok := entry[k]; !ok {
+				t.Errorf("Expected key %q in %v", k, entry)
+			}
+		}
+		for _, k := range absent {
+			if _, ok := entry[k]; ok {
+				t.Errorf("Unexpected key %q in %v", k, entry)
+			}
+		}
+	}
+
+	t.Run("custom keys", func(t *testing.T) {
+		entry := logOnce(t, &FieldKeys{Time: "timestamp", Message: "message", Level: "severity", Caller: "src"},
+			Error(errors.New("boom")))
+		requireKeys(t, entry, []string{"timestamp", "message", "severity", "src"}, []string{"time", "msg", "level", "caller"})
+		if entry["message"] != "hello" || entry["severity"] != "INFO" {
+			t.Errorf("Unexpected values: %v", entry)
+		}
+		if errGroup, ok := entry["error"].(map[string]any); !ok || errGroup["msg"] != "boom" {
+			t.Errorf("error group should keep its own keys, got %v", entry["error"])
+		}
+	})
+
+	t.Run("ecs preset", func(t *testing.T) {
+		entry := logOnce(t, &FieldKeys{Preset: FieldKeysPresetECS})
+		requireKeys(t, entry,
+			[]string{"@timestamp", "log.level", "message", "log.origin.file.name", "log.origin.file.line", "ecs.version"},
+			[]string{"time", "level", "msg", "caller"})
+		if file, _ := entry["log.origin.file.name"].(string); !strings.HasSuffix(file, "clog_test.go") {
+			t.Errorf("log.origin.file.name = %v, want file path only", entry["log.origin.file.name"])
+		}
+		if _, ok := entry["log.origin.file.line"].(float64); !ok {
+			t.Errorf("log.origin.file.line = %v, want number", entry["log.origin.file.line"])
+		}
+		if entry["ecs.version"] != ecsVersion {
+			t.Errorf("ecs.version = %v, want %s", entry["ecs.version"], ecsVersion)
+		}
+	})
+
+	t.Run("logstash preset with override", func(t *testing.T) {
+		entry := logOnce(t, &FieldKeys{Preset: FieldKeysPresetLogstash, Level: "severity"})
+		requireKeys(t, entry, []string{"@timestamp", "severity", "message", "caller", "@version"}, []string{"level", "msg"})
+	})
+
+	t.Run("flatten", func(t *testing.T) {
+		entry := logOnce(t, &FieldKeys{Flatten: true},
+			Group("req", String("id", "r-1"), Group("user", Int("uid", 7))),
+			Error(errors.New("boom")))
+		requireKeys(t, entry, []string{"req.id", "req.user.uid", "error.msg"}, []string{"req", "error"})
+		if entry["req.user.uid"] != float64(7) {
+			t.Errorf("req.user.uid = %v, want 7", entry["req.user.uid"])
+		}
+	})
+
+	t.Run("console ignores mapping", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(&Config{
+			Level:     "info",
+			Format:    "console",
+			Output:    "buffer",
+			FieldKeys: &FieldKeys{Preset: FieldKeysPresetECS},
+		}, withBuffer(&buf))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		logger.Info("hello")
+		if out := buf.String(); !strings.Contains(out, "msg=hello") || strings.Contains(out, "ecs.version") {
+			t.Errorf("Unexpected console output: %q", out)
+		}
+	})
+
+	t.Run("invalid preset", func(t *testing.T) {
+		_, err := New(&Config{Format: "json", FieldKeys: &FieldKeys{Preset: "gelf"}})
+		if err == nil || !strings.Contains(err.Error(), "invalid field keys preset") {
+			t.Fatalf("Expected invalid preset error, got %v", err)
+		}
+	})
+}
+
 // TestErrorField 测试轻量级错误字段
 func TestErrorField(t *testing.T) {
 	var buf bytes.Buffer
@@ -782,6 +1106,139 @@ func TestErrorFieldWithNil(t *testing.T) {
 	}
 }
 
+// parseStackFrames 把日志中的栈帧数组解析为 StackFrame
+func parseStackFrames(t *testing.T, v any) []StackFrame {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Failed to marshal stack: %v", err)
+	}
+	var frames []StackFrame
+	if err := json.Unmarshal(data, &frames); err != nil {
+		t.Fatalf("Stack is not a frame array: %v, raw=%s", err, data)
+	}
+	if len(frames) == 0 {
+		t.Fatal("Stack has no frames")
+	}
+	for _, f := range frames {
+		if f.Function == "" || f.File == "" || f.Line <= 0 {
+			t.Fatalf("Incomplete stack frame: %+v", f)
+		}
+	}
+	return frames
+}
+
+// TestStackField 测试当前 goroutine 堆栈字段
+func TestStackField(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(&Config{
+		Level:  "debug",
+		Format: "json",
+		Output: "buffer",
+	}, withBuffer(&buf))
+
+	logger.Warn("unexpected state", Stack("stack"))
+
+	var logEntry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &logEntry); err != nil {
+		t.Fatalf("Failed to parse log entry: %v", err)
+	}
+	frames := parseStackFrames(t, logEntry["stack"])
+	if !strings.HasSuffix(frames[0].Function, "clog.TestStackField") {
+		t.Errorf("First frame = %s, want the caller of Stack", frames[0].Function)
+	}
+	if !strings.HasSuffix(frames[0].File, "clog_test.go") {
+		t.Errorf("First frame file = %s, want clog_test.go", frames[0].File)
+	}
+}
+
+// panicInHandler 模拟业务代码中的 panic
+func panicInHandler(v any) {
+	panic(v)
+}
+
+// TestPanicValueField 测试在 recover 中结构化记录 panic 值与堆栈
+func TestPanicValueField(t *testing.T) {
+	recoverAndLog := func(logger Logger, v any) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("panic recovered", PanicValue(r))
+			}
+		}()
+		panicInHandler(v)
+	}
+
+	tests := []struct {
+		name      string
+		value     any
+		wantValue string
+		wantType  string
+	}{
+		{name: "string", value: "order not found", wantValue: "order not found", wantType: "string"},
+		{name: "error", value: errors.New("nil map"), wantValue: "nil map", wantType: "*errors.errorString"},
+		{name: "int", value: 42, wantValue: "42", wantType: "int"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, _ := New(&Config{
+				Level:  "debug",
+				Format: "json",
+				Output: "buffer",
+			}, withBuffer(&buf))
+
+			recoverAndLog(logger, tt.value)
+
+			var logEntry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &logEntry); err != nil {
+				t.Fatalf("Failed to parse log entry: %v", err)
+			}
+			panicGroup, ok := logEntry["panic"].(map[string]any)
+			if !ok {
+				t.Fatalf("Expected panic field to be a group, got %T", logEntry["panic"])
+			}
+			if panicGroup["value"] != tt.wantValue {
+				t.Errorf("panic.value = %v, want %s", panicGroup["value"], tt.wantValue)
+			}
+			if panicGroup["type"] != tt.wantType {
+				t.Errorf("panic.type = %v, want %s", panicGroup["type"], tt.wantType)
+			}
+
+			// 堆栈从 panic 位置开始，不含 recover 所在的 defer 函数和 runtime.gopanic
+			frames := parseStackFrames(t, panicGroup["stack"])
+			if !strings.HasSuffix(frames[0].Function, "clog.panicInHandler") {
+				t.Errorf("First frame = %s, want clog.panicInHandler", frames[0].Function)
+			}
+			for _, f := range frames {
+				if f.Function == "runtime.gopanic" {
+					t.Errorf("Stack should start after runtime.gopanic: %+v", frames)
+				}
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		if attr := PanicValue(nil); !attr.Equal(Field{}) {
+			t.Errorf("PanicValue(nil) = %v, want empty field", attr)
+		}
+	})
+
+	t.Run("console", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, _ := New(&Config{
+			Level:  "debug",
+			Format: "console",
+			Output: "buffer",
+		}, withBuffer(&buf))
+
+		recoverAndLog(logger, "boom")
+		if !strings.Contains(buf.String(), "clog.panicInHandler (") {
+			t.Errorf("Console output should contain readable frames, got %s", buf.String())
+		}
+	})
+}
+
 // TestConsoleFormat 测试控制台格式
 func TestConsoleFormat(t *testing.T) {
 	var buf bytes.Buffer
@@ -926,3 +1383,1524 @@ func TestLoggerClose(t *testing.T) {
 		}
 	})
 }
+
+// TestTimeFormatAndZone 测试自定义时间格式与时区
+func TestTimeFormatAndZone(t *testing.T) {
+	const layout = "2006-01-02 15:04:05 MST"
+
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("tzdata not available: %v", err)
+	}
+
+	// checkTime 校验时间字符串按 layout 输出且位于指定时区
+	checkTime := func(t *testing.T, value string, loc *time.Location) {
+		t.Helper()
+		parsed, err := time.ParseInLocation(layout, value, loc)
+		if err != nil {
+			t.Fatalf("time %q does not match layout %q: %v", value, layout, err)
+		}
+		wantZone, _ := time.Now().In(loc).Zone()
+		if gotZone, _ := parsed.Zone(); gotZone != wantZone {
+			t.Errorf("Expected zone %s, got %s", wantZone, gotZone)
+		}
+		if d := time.Since(parsed); d < -time.Minute || d > time.Minute {
+			t.Errorf("Parsed time %v too far from now", parsed)
+		}
+	}
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger, err := New(&Config{
+			Level:      "info",
+			Format:     "json",
+			Output:     "buffer",
+			TimeFormat: layout,
+			TimeZone:   "Asia/Shanghai",
+		}, withBuffer(&buf))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+
+		logger.Info("json time")
+
+		var logEntry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &logEntry); err != nil {
+			t.Fatalf("Failed to parse JSON: %v", err)
+		}
+		value, _ := logEntry["time"].(string)
+		checkTime(t, value, shanghai)
+	})
+
+	for _, enableColor := range []bool{false, true} {
+		name := "console"
+		if enableColor {
+			name = "console with color"
+		}
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(&Config{
+				Level:       "info",
+				Format:      "console",
+				Output:      "buffer",
+				EnableColor: enableColor,
+				TimeFormat:  layout,
+				TimeZone:    "UTC",
+			}, withBuffer(&buf))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			logger.Info("console time")
+
+			output := buf.String()
+			now := time.Now().UTC()
+			prefix := now.Format("2006-01-02 ")
+			idx := strings.Index(output, prefix)
+			if idx == -1 || len(output) < idx+len(layout) {
+				t.Fatalf("Output doesn't contain formatted time: %q", output)
+			}
+			checkTime(t, output[idx:idx+len(layout)], time.UTC)
+		})
+	}
+
+	t.Run("invalid time zone", func(t *testing.T) {
+		_, err := New(&Config{Format: "json", TimeZone: "Mars/Olympus"})
+		if err == nil || !strings.Contains(err.Error(), "invalid time zone") {
+			t.Fatalf("Expected invalid time zone error, got %v", err)
+		}
+	})
+}
+
+// TestDedup 测试重复日志去重与抑制汇总
+func TestDedup(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&Config{
+		Level:  "info",
+		Format: "json",
+		Output: "buffer",
+	}, withBuffer(&buf), WithDedup(time.Minute, "user_id"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i := 0; i < 1000; i++ {
+		logger.Error("db query failed", Int("attempt", i), String("user_id", "u1"))
+	}
+	// 不同的指纹字段值不会被去重
+	logger.Error("db query failed", String("user_id", "u2"))
+	// 不同的级别不会被去重
+	logger.Warn("db query failed", String("user_id", "u1"))
+
+	logger.Flush()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) >= 1000 {
+		t.Fatalf("Expected far fewer than 1000 lines, got %d", len(lines))
+	}
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines (3 firsts + 1 summary), got %d:\n%s", len(lines), buf.String())
+	}
+
+	var summaries []map[string]any
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Failed to parse log entry: %v", err)
+		}
+		if _, ok := entry[dedupSuppressedKey]; ok {
+			summaries = append(summaries, entry)
+		}
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("Expected 1 summary, got %d", len(summaries))
+	}
+	summary := summaries[0]
+	if summary[dedupSuppressedKey] != float64(999) {
+		t.Errorf("Expected suppressed = 999, got %v", summary[dedupSuppressedKey])
+	}
+	if summary["msg"] != "db query failed" || summary["user_id"] != "u1" || summary["level"] != "ERROR" {
+		t.Errorf("Summary should keep level, message and fields of the first entry, got %v", summary)
+	}
+
+	// Flush 后窗口重新开始
+	buf.Reset()
+	logger.Error("db query failed", String("user_id", "u1"))
+	logger.Flush()
+	if got := strings.Count(buf.String(), "\n"); got != 1 {
+		t.Errorf("Expected 1 line after flush, got %d", got)
+	}
+}
+
+// TestDedupWindowExpire 测试窗口结束时自动输出汇总
+func TestDedupWindowExpire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedup.log")
+	logger, err := New(&Config{
+		Level:  "info",
+		Format: "json",
+		Output: path,
+	}, WithDedup(50*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer logger.Close()
+
+	for i := 0; i < 10; i++ {
+		logger.Info("tick")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if strings.Contains(string(data), `"suppressed":9`) {
+			if got := strings.Count(string(data), "\n"); got != 2 {
+				t.Errorf("Expected 2 lines, got %d:\n%s", got, data)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Summary not emitted after window expired:\n%s", data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// slowWriter 每次写入都耗时 delay 的 writer，模拟慢速磁盘或网络
+type slowWriter struct {
+	delay time.Duration
+	mu    sync.Mutex
+	buf   bytes.Buffer
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *slowWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// TestAsyncLogger 测试异步模式下 Close 后全部日志落盘
+func TestAsyncLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "async.log")
+	logger, err := New(&Config{
+		Level:  "info",
+		Format: "json",
+		Output: path,
+		Async:  &AsyncConfig{BufferSize: 16},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				logger.Info("async", Int("i", i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got := strings.Count(string(data), "\n"); got != 1000 {
+		t.Errorf("Expected 1000 lines after Close, got %d", got)
+	}
+	if got := DroppedCount(logger); got != 0 {
+		t.Errorf("DroppedCount() = %d, want 0 in blocking mode", got)
+	}
+
+	// 关闭后的日志被丢弃并计数
+	logger.Info("after close")
+	if got := DroppedCount(logger); got != 1 {
+		t.Errorf("DroppedCount() after Close = %d, want 1", got)
+	}
+}
+
+// TestAsyncWriterSlowOutput 测试慢速输出不阻塞写入方，Flush 后全部落盘
+func TestAsyncWriterSlowOutput(t *testing.T) {
+	out := &slowWriter{delay: 50 * time.Millisecond}
+	w := newAsyncWriter(out, &AsyncConfig{BufferSize: 100, FlushInterval: time.Hour}, nil)
+	defer w.Close()
+
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		if _, err := w.Write([]byte("line\n")); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("20 writes took %v, want fast return", elapsed)
+	}
+
+	w.Flush()
+	if got := strings.Count(out.String(), "\n"); got != 20 {
+		t.Errorf("Expected 20 lines after Flush, got %d", got)
+	}
+}
+
+// TestAsyncWriterDropWhenFull 测试缓冲满时丢弃计数且不阻塞
+func TestAsyncWriterDropWhenFull(t *testing.T) {
+	out := &slowWriter{delay: 200 * time.Millisecond}
+	w := newAsyncWriter(out, &AsyncConfig{BufferSize: 4, DropWhenFull: true, FlushInterval: time.Hour}, nil)
+
+	// 单条超过后台字节缓冲，直接写入慢速输出，使后台 goroutine 阻塞
+	entry := append(bytes.Repeat([]byte("x"), asyncWriteBufferSize+1), '\n')
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		_, _ = w.Write(entry)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("100 writes took %v, want non-blocking", elapsed)
+	}
+
+	dropped := w.dropped.Load()
+	if dropped == 0 {
+		t.Fatalf("Expected dropped count to increase when buffer is full")
+	}
+
+	w.Close()
+	if got := uint64(strings.Count(out.String(), "\n")); got+dropped != 100 {
+		t.Errorf("written(%d) + dropped(%d) = %d, want 100", got, dropped, got+dropped)
+	}
+}
+
+// TestAsyncWriterCloseConcurrent 测试 Write 与 Close 并发时日志要么落盘要么计入丢弃（配合 -race 运行）
+func TestAsyncWriterCloseConcurrent(t *testing.T) {
+	for _, dropWhenFull := range []bool{false, true} {
+		for round := range 50 {
+			out := &slowWriter{}
+			w := newAsyncWriter(out, &AsyncConfig{BufferSize: 1, DropWhenFull: dropWhenFull, FlushInterval: time.Hour}, nil)
+
+			const writers, perWriter = 16, 1000
+			var wg sync.WaitGroup
+			for range writers {
+				wg.Go(func() {
+					for range perWriter {
+						_, _ = w.Write([]byte("line\n"))
+					}
+				})
+			}
+			// 在写入进行中关闭
+			time.Sleep(100 * time.Microsecond)
+			w.Close()
+			wg.Wait()
+
+			written := uint64(strings.Count(out.String(), "\n"))
+			dropped := w.dropped.Load()
+			if written+dropped != writers*perWriter {
+				t.Fatalf("dropWhenFull=%v round=%d: written(%d) + dropped(%d) = %d, want %d",
+					dropWhenFull, round, written, dropped, written+dropped, writers*perWriter)
+			}
+		}
+	}
+}
+
+// TestAsyncConfigValidate 测试异步配置默认值与校验
+func TestAsyncConfigValidate(t *testing.T) {
+	cfg := &AsyncConfig{}
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+	if cfg.BufferSize != defaultAsyncBufferSize || cfg.FlushInterval != defaultAsyncFlushInterval {
+		t.Errorf("defaults = %+v, want BufferSize=%d FlushInterval=%v", cfg, defaultAsyncBufferSize, defaultAsyncFlushInterval)
+	}
+
+	if _, err := New(&Config{Async: &AsyncConfig{BufferSize: -1}}); err == nil {
+		t.Errorf("New() with negative BufferSize should fail")
+	}
+
+	logger, _ := New(&Config{Level: "info", Format: "json", Output: "stdout"})
+	if got := DroppedCount(logger); got != 0 {
+		t.Errorf("DroppedCount() for sync logger = %d, want 0", got)
+	}
+	if got := DroppedCount(Discard()); got != 0 {
+		t.Errorf("DroppedCount() for Discard = %d, want 0", got)
+	}
+}
+
+// TestAsyncFullPolicy 测试 WithAsyncFullPolicy 覆盖配置中的缓冲满策略
+func TestAsyncFullPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		dropWhenFull bool
+		opts         []Option
+		want         bool
+	}{
+		{"默认沿用配置-阻塞", false, nil, false},
+		{"默认沿用配置-丢弃", true, nil, true},
+		{"DropOnFull 覆盖配置", false, []Option{WithAsyncFullPolicy(DropOnFull)}, true},
+		{"BlockOnFull 覆盖配置", true, []Option{WithAsyncFullPolicy(BlockOnFull)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := New(&Config{
+				Level:  "info",
+				Format: "json",
+				Output: filepath.Join(t.TempDir(), "async.log"),
+				Async:  &AsyncConfig{DropWhenFull: tt.dropWhenFull},
+			}, tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			defer logger.Close()
+
+			if got := logger.(*loggerImpl).handler.(*clogHandler).async.dropWhenFull; got != tt.want {
+				t.Errorf("dropWhenFull = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if DropOnFull.String() != "drop" || BlockOnFull.String() != "block" {
+		t.Errorf("FullPolicy.String() = %q/%q, want drop/block", DropOnFull, BlockOnFull)
+	}
+}
+
+// TestAsyncSetLevelConcurrent 测试异步模式下并发写日志与 SetLevel 的线程安全（配合 -race 运行）
+func TestAsyncSetLevelConcurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "async.log")
+	logger, err := New(&Config{
+		Level:  "info",
+		Format: "json",
+		Output: path,
+		Async:  &AsyncConfig{BufferSize: 64},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			child := logger.WithNamespace("worker")
+			for i := 0; i < 500; i++ {
+				child.Debug("debug", Int("i", i))
+				child.Warn("warn", Int("i", i))
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if err := logger.SetLevel([]Level{DebugLevel, WarnLevel}[i%2]); err != nil {
+				t.Errorf("SetLevel() error = %v", err)
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	// 调整后的级别对后续日志立即生效
+	if err := logger.SetLevel(ErrorLevel); err != nil {
+		t.Fatalf("SetLevel() error = %v", err)
+	}
+	logger.Warn("filtered")
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if got := strings.Count(string(data), `"msg":"warn"`); got != 2000 {
+		t.Errorf("warn lines = %d, want 2000", got)
+	}
+	if strings.Contains(string(data), "filtered") {
+		t.Errorf("Warn after SetLevel(ErrorLevel) should be filtered")
+	}
+}
+
+// latencyWriter 每次写入耗时 delay 并丢弃数据，模拟抖动的磁盘或网络 sink
+type latencyWriter struct {
+	delay time.Duration
+}
+
+func (w latencyWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return len(p), nil
+}
+
+// BenchmarkAsyncLogger 对比慢速 sink 下同步与异步写入时业务 goroutine 的单条日志耗时
+func BenchmarkAsyncLogger(b *testing.B) {
+	for _, bc := range []struct {
+		name   string
+		async  *AsyncConfig
+		policy []Option
+	}{
+		{"sync", nil, nil},
+		{"async_block", &AsyncConfig{BufferSize: 4096}, nil},
+		{"async_drop", &AsyncConfig{BufferSize: 4096}, []Option{WithAsyncFullPolicy(DropOnFull)}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			opts := append([]Option{withWriter(latencyWriter{delay: 20 * time.Microsecond})}, bc.policy...)
+			logger, err := New(&Config{Level: "info", Format: "json", Output: "buffer", Async: bc.async}, opts...)
+			if err != nil {
+				b.Fatalf("New() error = %v", err)
+			}
+			defer logger.Close()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for b.Loop() {
+				logger.Info("request handled", String("path", "/v1/users"), Int("status", 200))
+			}
+		})
+	}
+}
+
+// TestRequestBuffer 测试请求级日志缓冲
+func TestRequestBuffer(t *testing.T) {
+	newLogger := func(t *testing.T) (Logger, *bytes.Buffer) {
+		t.Helper()
+		var buf bytes.Buffer
+		logger, err := New(&Config{
+			Level:  "info",
+			Format: "json",
+			Output: "buffer",
+		}, withBuffer(&buf), WithContextField("request_id", "request_id"))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		return logger, &buf
+	}
+	messages := func(t *testing.T, buf *bytes.Buffer) []string {
+		t.Helper()
+		var msgs []string
+		for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("invalid json line %q: %v", line, err)
+			}
+			if entry["request_id"] != "req-1" {
+				t.Errorf("request_id = %v, want req-1", entry["request_id"])
+			}
+			msgs = append(msgs, entry["msg"].(string))
+		}
+		return msgs
+	}
+	handle := func(logger Logger, ctx context.Context) {
+		logger.DebugContext(ctx, "load user")
+		logger.InfoContext(ctx, "order created")
+		logger.WithNamespace("payment").DebugContext(ctx, "call gateway")
+		logger.WarnContext(ctx, "slow gateway")
+	}
+	baseCtx := context.WithValue(context.Background(), "request_id", "req-1")
+
+	t.Run("成功请求只输出 info 及以上", func(t *testing.T) {
+		logger, buf := newLogger(t)
+		ctx, rb := NewRequestBuffer(baseCtx)
+		handle(logger, ctx)
+
+		if buf.Len() != 0 {
+			t.Fatalf("请求结束前不应输出，got %q", buf.String())
+		}
+		if rb.Len() != 4 {
+			t.Errorf("Len() = %d, want 4", rb.Len())
+		}
+
+		rb.Finish(nil)
+		got := messages(t, buf)
+		want := []string{"order created", "slow gateway"}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("messages = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("失败请求输出缓冲的 debug", func(t *testing.T) {
+		logger, buf := newLogger(t)
+		ctx, rb := NewRequestBuffer(baseCtx)
+		handle(logger, ctx)
+
+		rb.Finish(errors.New("payment failed"))
+		got := messages(t, buf)
+		want := []string{"load user", "order created", "call gateway", "slow gateway"}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("messages = %v, want %v", got, want)
+		}
+
+		// 重复 Finish 不会再次输出；结束后的日志直接按级别输出
+		rb.Finish(errors.New("again"))
+		buf.Reset()
+		logger.DebugContext(ctx, "after finish")
+		logger.InfoContext(ctx, "after finish info")
+		if got := messages(t, buf); len(got) != 1 || got[0] != "after finish info" {
+			t.Errorf("messages after finish = %v", got)
+		}
+	})
+
+	t.Run("未绑定缓冲时直接输出", func(t *testing.T) {
+		logger, buf := newLogger(t)
+		handle(logger, baseCtx)
+		if got := messages(t, buf); len(got) != 2 {
+			t.Errorf("messages = %v, want 2 entries", got)
+		}
+		if RequestBufferFromContext(baseCtx) != nil {
+			t.Error("RequestBufferFromContext() should be nil")
+		}
+	})
+}
+
+// BenchmarkLoggerWith 对比 With 字段预编码与逐条编码的单条日志开销
+func BenchmarkLoggerWith(b *testing.B) {
+	for _, bc := range []struct {
+		name      string
+		preencode bool
+	}{
+		{"preencoded", true},
+		{"per_record", false},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			logger, _ := New(&Config{Level: "info", Format: "json", Output: "buffer"}, withBuffer(&bytes.Buffer{}))
+			logger.(*loggerImpl).preencode = bc.preencode
+			child := logger.With(
+				String("service", "order"),
+				String("region", "cn-east-1"),
+				Int("shard", 3),
+				Group("build", String("version", "v1.2.3"), String("commit", "abc123")),
+			)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for b.Loop() {
+				child.Info("request handled", Int("status", 200))
+			}
+		})
+	}
+}
+
+// TestConditional 测试按条件输出的 Logger
+func TestConditional(t *testing.T) {
+	var buf bytes.Buffer
+	inner, _ := New(&Config{
+		Level:     "debug",
+		Format:    "json",
+		Output:    "buffer",
+		AddSource: true,
+	}, withBuffer(&buf))
+
+	var enabled atomic.Bool
+	logger := Conditional(enabled.Load, inner).With(String("component", "verbose"))
+
+	lazyCalls := 0
+	logger.Debug("hidden", Lazy("dump", func() any {
+		lazyCalls++
+		return "x"
+	}))
+	logger.InfoContext(context.Background(), "hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("cond=false 时不应写入 inner，got %q", buf.String())
+	}
+	if lazyCalls != 0 {
+		t.Fatalf("cond=false 时不应求值 Lazy 字段，calls = %d", lazyCalls)
+	}
+
+	// 切换条件即时生效
+	enabled.Store(true)
+	logger.Info("visible", Int("n", 1))
+
+	var entry map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &entry); err != nil {
+		t.Fatalf("Failed to parse log entry: %v", err)
+	}
+	if entry["msg"] != "visible" || entry["component"] != "verbose" || entry["n"] != float64(1) {
+		t.Fatalf("unexpected entry: %v", entry)
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "clog_test.go") {
+		t.Fatalf("caller = %q, want clog_test.go", caller)
+	}
+
+	enabled.Store(false)
+	buf.Reset()
+	logger.WithNamespace("sub").Error("hidden again")
+	if buf.Len() != 0 {
+		t.Fatalf("派生 Logger 应共享 cond，got %q", buf.String())
+	}
+}
+
+// TestNop 测试共享的空 Logger
+func TestNop(t *testing.T) {
+	if Nop() != Nop() {
+		t.Fatal("Nop 应返回同一实例")
+	}
+	if Conditional(nil, Nop()) != Nop() {
+		t.Fatal("cond 为 nil 时应返回 Nop")
+	}
+
+	logger := Nop()
+	allocs := testing.AllocsPerRun(100, func() {
+		logger.With().Info("msg")
+		_ = Nop()
+	})
+	if allocs != 0 {
+		t.Fatalf("Nop allocs = %v, want 0", allocs)
+	}
+}
+
+// TestSampledLevel 测试按 trace 采样决策动态选择日志级别
+func TestSampledLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := New(&Config{
+		Level:  "info",
+		Format: "json",
+		Output: "buffer",
+	}, withBuffer(&buf), WithSampledLevel(DebugLevel, WarnLevel))
+
+	spanCtx := func(flags trace.TraceFlags) context.Context {
+		sc := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    trace.TraceID{1},
+			SpanID:     trace.SpanID{1},
+			TraceFlags: flags,
+		})
+		return trace.ContextWithSpanContext(context.Background(), sc)
+	}
+	messages := func() []string {
+		defer buf.Reset()
+		var msgs []string
+		for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+			var entry map[string]any
+			if line == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("Failed to parse log entry: %v", err)
+			}
+			msgs = append(msgs, entry["msg"].(string))
+		}
+		return msgs
+	}
+	logAll := func(ctx context.Context) {
+		logger.DebugContext(ctx, "debug")
+		logger.InfoContext(ctx, "info")
+		logger.WarnContext(ctx, "warn")
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want []string
+	}{
+		{"sampled span 全量输出", spanCtx(trace.FlagsSampled), []string{"debug", "info", "warn"}},
+		{"非 sampled span 只输出 warn+", spanCtx(0), []string{"warn"}},
+		{"无 span 按默认级别", context.Background(), []string{"info", "warn"}},
+		{"显式标志优先于 span", ContextWithSampled(spanCtx(0), true), []string{"debug", "info", "warn"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logAll(tt.ctx)
+			if got := messages(); !slices.Equal(got, tt.want) {
+				t.Fatalf("messages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("非标准级别与 SetLevel 使用相同映射", func(t *testing.T) {
+		var custom bytes.Buffer
+		customLogger, _ := New(&Config{Level: "info", Format: "json", Output: "buffer"},
+			withBuffer(&custom), WithSampledLevel(DebugLevel, Level(6)))
+		customLogger.InfoContext(spanCtx(0), "info")
+		customLogger.WarnContext(spanCtx(0), "warn")
+		// 未知级别按 InfoLevel 处理，与 SetLevel(Level(6)) 的行为一致
+		if got := strings.Count(custom.String(), "\n"); got != 2 {
+			t.Fatalf("非 sampled span 应输出 info 与 warn，got %q", custom.String())
+		}
+	})
+
+	t.Run("未开启时忽略采样决策", func(t *testing.T) {
+		var plain bytes.Buffer
+		plainLogger, _ := New(&Config{Level: "info", Format: "json", Output: "buffer"}, withBuffer(&plain))
+		plainLogger.DebugContext(spanCtx(trace.FlagsSampled), "debug")
+		if plain.Len() != 0 {
+			t.Fatalf("未开启 WithSampledLevel 时 debug 不应输出，got %q", plain.String())
+		}
+	})
+}
+
+// TestFieldLimits 测试字段值截断与字段数超限丢弃
+func TestFieldLimits(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&Config{Level: "info", Format: "json", Output: "buffer"},
+		withBuffer(&buf), WithMaxFieldBytes(16), WithMaxFields(3))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	decode := func() map[string]any {
+		t.Helper()
+		var entry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse log entry: %v", err)
+		}
+		buf.Reset()
+		return entry
+	}
+
+	t.Run("超大字段值被截断并带标记", func(t *testing.T) {
+		body := strings.Repeat("x", 1<<20)
+		logger.Info("request", String("body", body), Any("payload", []string{body}), String("short", "ok"))
+		entry := decode()
+
+		want := strings.Repeat("x", 16) + truncatedSuffix
+		if entry["body"] != want {
+			t.Errorf("body = %.40q, want %q", entry["body"], want)
+		}
+		if got, _ := entry["payload"].(string); len(got) != 16+len(truncatedSuffix) || !strings.HasSuffix(got, truncatedSuffix) {
+			t.Errorf("payload = %.40q, want 16 bytes with truncated marker", entry["payload"])
+		}
+		if entry["short"] != "ok" {
+			t.Errorf("short = %v, want unchanged", entry["short"])
+		}
+	})
+
+	t.Run("Group 内字段与多字节字符", func(t *testing.T) {
+		logger.Info("nested", Group("req", String("name", strings.Repeat("中", 10))))
+		entry := decode()
+
+		got := entry["req"].(map[string]any)["name"]
+		// 16 字节回退到字符边界：5 个汉字共 15 字节
+		if want := strings.Repeat("中", 5) + truncatedSuffix; got != want {
+			t.Errorf("req.name = %q, want %q", got, want)
+		}
+	})
+
+	t.Run("字段数超限时丢弃多余字段并计数", func(t *testing.T) {
+		logger.With(String("service", "api")).Info("many",
+			Int("a", 1), Int("b", 2), Int("c", 3), Int("d", 4))
+		entry := decode()
+
+		for _, k := range []string{"service", "a", "b"} {
+			if _, ok := entry[k]; !ok {
+				t.Errorf("field %q should be kept, got %v", k, entry)
+			}
+		}
+		for _, k := range []string{"c", "d"} {
+			if _, ok := entry[k]; ok {
+				t.Errorf("field %q should be dropped, got %v", k, entry)
+			}
+		}
+		if entry[droppedFieldsKey] != float64(2) {
+			t.Errorf("%s = %v, want 2", droppedFieldsKey, entry[droppedFieldsKey])
+		}
+	})
+
+	truncated, dropped := FieldLimitStats(logger)
+	if truncated != 3 || dropped != 2 {
+		t.Errorf("FieldLimitStats() = (%d, %d), want (3, 2)", truncated, dropped)
+	}
+	if truncated, dropped := FieldLimitStats(Nop()); truncated != 0 || dropped != 0 {
+		t.Errorf("FieldLimitStats(Nop()) = (%d, %d), want (0, 0)", truncated, dropped)
+	}
+}
+
+// TestNamespaceLevel 测试按命名空间设置独立日志级别与前缀继承
+func TestNamespaceLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&Config{Level: "info", Format: "json", Output: "buffer"},
+		withBuffer(&buf), WithNamespace("user-service"),
+		WithNamespaceLevels(map[string]string{"user-service.repo": "debug"}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	repo := logger.WithNamespace("repo")
+	handler := logger.WithNamespace("handler")
+	repoCache := repo.WithNamespace("cache").With(String("k", "v"))
+
+	// logged 返回各 logger 输出 debug/warn 后实际写出的命名空间
+	logged := func(level Level) []string {
+		t.Helper()
+		buf.Reset()
+		for _, l := range []Logger{logger, repo, handler, repoCache} {
+			if level == DebugLevel {
+				l.Debug("m")
+			} else {
+				l.Warn("m")
+			}
+		}
+		var got []string
+		for line := range strings.SplitSeq(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("Failed to parse log entry: %v", err)
+			}
+			got = append(got, entry["namespace"].(string))
+		}
+		return got
+	}
+
+	t.Run("初始化批量设置，子级继承父级", func(t *testing.T) {
+		want := []string{"user-service.repo", "user-service.repo.cache"}
+		if got := logged(DebugLevel); !slices.Equal(got, want) {
+			t.Fatalf("debug namespaces = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("父级设置不覆盖子级显式设置", func(t *testing.T) {
+		if err := logger.SetNamespaceLevel("user-service", ErrorLevel); err != nil {
+			t.Fatalf("SetNamespaceLevel() error = %v", err)
+		}
+		if got, want := logged(WarnLevel), []string{"user-service.repo", "user-service.repo.cache"}; !slices.Equal(got, want) {
+			t.Fatalf("warn namespaces = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("派生 logger 上的设置对兄弟 logger 生效", func(t *testing.T) {
+		if err := repoCache.SetNamespaceLevel("user-service.repo.cache", WarnLevel); err != nil {
+			t.Fatalf("SetNamespaceLevel() error = %v", err)
+		}
+		if got, want := logged(DebugLevel), []string{"user-service.repo"}; !slices.Equal(got, want) {
+			t.Fatalf("debug namespaces = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("只匹配完整的命名空间段", func(t *testing.T) {
+		other := logger.WithNamespace("repository")
+		buf.Reset()
+		other.Warn("m")
+		if buf.Len() != 0 {
+			t.Fatalf("user-service.repository 应继承 user-service 的 error 级别，got %q", buf.String())
+		}
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		if err := logger.SetNamespaceLevel("", DebugLevel); err == nil {
+			t.Errorf("SetNamespaceLevel(\"\") should fail")
+		}
+		if _, err := New(&Config{Level: "info"}, WithNamespaceLevels(map[string]string{"a": "verbose"})); err == nil {
+			t.Errorf("New() with invalid namespace level should fail")
+		}
+	})
+}
+
+// TestNamespaceLevelConcurrent 测试并发设置命名空间级别与写日志（配合 -race 运行）
+func TestNamespaceLevelConcurrent(t *testing.T) {
+	logger, err := New(&Config{Level: "info", Format: "json", Output: "buffer"}, withBuffer(&bytes.Buffer{}))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	// bytes.Buffer 非并发安全，只验证级别判断，日志级别保持在输出阈值之下
+	child := logger.WithNamespace("svc", "repo")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			_ = logger.SetNamespaceLevel("svc", []Level{WarnLevel, ErrorLevel}[i%2])
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			child.Debug("m")
+		}
+	}()
+	wg.Wait()
+}
+
+// TestRotation 测试按大小轮转、备份命名与按个数清理
+func TestRotation(t *testing.T) {
+	backupPattern := regexp.MustCompile(`^app-\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}\.log$`)
+	line := strings.Repeat("x", 2000)
+
+	// writeLogs 从 4 个 goroutine 并发写入约 5MB 日志，返回写入条数
+	writeLogs := func(t *testing.T, logger Logger) int {
+		t.Helper()
+		var wg sync.WaitGroup
+		for g := 0; g < 4; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 600; i++ {
+					logger.Info("rotate", Int("g", g), Int("i", i), String("payload", line))
+				}
+			}()
+		}
+		wg.Wait()
+		if err := logger.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+		return 4 * 600
+	}
+
+	t.Run("轮转期间不丢日志且 AddSource 不受影响", func(t *testing.T) {
+		dir := t.TempDir()
+		logger, err := New(&Config{
+			Level:     "info",
+			Format:    "json",
+			Output:    filepath.Join(dir, "app.log"),
+			AddSource: true,
+			Rotation:  &RotationConfig{MaxSizeMB: 1},
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		written := writeLogs(t, logger)
+
+		entries, _ := os.ReadDir(dir)
+		var backups, lines int
+		for _, e := range entries {
+			if e.Name() != "app.log" {
+				if !backupPattern.MatchString(e.Name()) {
+					t.Errorf("unexpected backup name %q", e.Name())
+				}
+				backups++
+			}
+			data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				t.Fatalf("ReadFile() error = %v", err)
+			}
+			if e.Name() != "app.log" && len(data) > megabyte {
+				t.Errorf("backup %s size %d exceeds 1MB", e.Name(), len(data))
+			}
+			for l := range strings.SplitSeq(strings.TrimSpace(string(data)), "\n") {
+				if !strings.Contains(l, `"caller":"`) || !strings.Contains(l, "clog_test.go") {
+					t.Fatalf("caller should point to test file, got %.200s", l)
+				}
+				lines++
+			}
+		}
+		if backups < 3 {
+			t.Errorf("backups = %d, want at least 3 for ~5MB with 1MB limit", backups)
+		}
+		if lines != written {
+			t.Errorf("lines across files = %d, want %d", lines, written)
+		}
+	})
+
+	t.Run("超过 MaxBackups 时删除最老的备份", func(t *testing.T) {
+		dir := t.TempDir()
+		oldest := filepath.Join(dir, "app-2020-01-01T00-00-00.000.log")
+		unrelated := filepath.Join(dir, "other-2020-01-01T00-00-00.000.log")
+		for _, p := range []string{oldest, unrelated} {
+			if err := os.WriteFile(p, []byte("old\n"), 0o666); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+		}
+
+		logger, err := New(&Config{
+			Level:    "info",
+			Format:   "json",
+			Output:   filepath.Join(dir, "app.log"),
+			Rotation: &RotationConfig{MaxSizeMB: 1, MaxBackups: 2},
+		})
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		writeLogs(t, logger)
+
+		var backups []string
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if backupPattern.MatchString(e.Name()) {
+				backups = append(backups, e.Name())
+			}
+		}
+		if len(backups) != 2 {
+			t.Fatalf("backups = %v, want 2", backups)
+		}
+		if exists(oldest) {
+			t.Errorf("oldest backup %s should be removed", oldest)
+		}
+		if !exists(unrelated) {
+			t.Errorf("unrelated file %s should be kept", unrelated)
+		}
+	})
+}
+
+// TestRotatingFileAgeAndCompress 测试按天数清理与压缩备份
+func TestRotatingFileAgeAndCompress(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	expired := filepath.Join(dir, "app-"+time.Now().AddDate(0, 0, -10).Format(backupTimeFormat)+".log")
+	if err := os.WriteFile(expired, []byte("expired\n"), 0o666); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	r, err := newRotatingFile(path, &RotationConfig{MaxSizeMB: 1, MaxAgeDays: 7, Compress: true})
+	if err != nil {
+		t.Fatalf("newRotatingFile() error = %v", err)
+	}
+	r.maxBytes = 10 // 测试中缩小阈值，第二次写入即触发轮转
+	for _, p := range []string{"first line\n", "second line\n"} {
+		if _, err := r.Write([]byte(p)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, err := r.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("Write() after Close error = %v, want os.ErrClosed", err)
+	}
+
+	if exists(expired) {
+		t.Errorf("expired backup should be removed")
+	}
+	current, _ := os.ReadFile(path)
+	if string(current) != "second line\n" {
+		t.Errorf("current file = %q, want second line", current)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	if len(matches) != 1 {
+		t.Fatalf("compressed backups = %v, want 1", matches)
+	}
+	f, err := os.Open(matches[0])
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() error = %v", err)
+	}
+	data, _ := io.ReadAll(gz)
+	if string(data) != "first line\n" {
+		t.Errorf("compressed backup = %q, want first line", data)
+	}
+	if plain := strings.TrimSuffix(matches[0], compressSuffix); exists(plain) {
+		t.Errorf("uncompressed backup %s should be removed after compression", plain)
+	}
+}
+
+// TestRotationConfigValidate 测试轮转配置校验
+
+func TestRotatingFileRenameFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	r, err := newRotatingFile(path, &RotationConfig{MaxSizeMB: 1})
+	if err != nil {
+		t.Fatalf("newRotatingFile() error = %v", err)
+	}
+	defer r.Close()
+	r.maxBytes = 30 // 第三次写入触发轮转
+	var renames int
+	r.rename = func(string, string) error {
+		renames++
+		return errors.New("rename denied")
+	}
+
+	// 轮转失败后继续写入原文件，写满下一个 maxBytes 前不再重试
+	lines := []string{"first line\n", "second line\n", "third line\n", "fourth line\n"}
+	for _, p := range lines {
+		if _, err := r.Write([]byte(p)); err != nil {
+			t.Fatalf("Write() error = %v, want rotation failure to be recovered", err)
+		}
+	}
+	if renames != 1 {
+		t.Errorf("rename attempts = %d, want 1", renames)
+	}
+	current, _ := os.ReadFile(path)
+	if want := strings.Join(lines, ""); string(current) != want {
+		t.Errorf("current file = %q, want %q", current, want)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(dir, "app-*.log")); len(matches) != 0 {
+		t.Errorf("backups = %v, want none after failed rename", matches)
+	}
+
+	// 重命名恢复后再次轮转，之前写入的日志全部进入备份
+	r.rename = os.Rename
+	if _, err := r.Write([]byte("fifth line\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	current, _ = os.ReadFile(path)
+	if string(current) != "fifth line\n" {
+		t.Errorf("current file = %q, want fifth line", current)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	if len(matches) != 1 {
+		t.Fatalf("backups = %v, want 1", matches)
+	}
+	backup, _ := os.ReadFile(matches[0])
+	if want := strings.Join(lines, ""); string(backup) != want {
+		t.Errorf("backup = %q, want %q", backup, want)
+	}
+}
+func TestRotationConfigValidate(t *testing.T) {
+	if _, err := New(&Config{Output: "stdout", Rotation: &RotationConfig{}}); err == nil {
+		t.Errorf("New() with rotation on stdout should fail")
+	}
+	if _, err := New(&Config{Output: filepath.Join(t.TempDir(), "a.log"), Rotation: &RotationConfig{MaxBackups: -1}}); err == nil {
+		t.Errorf("New() with negative MaxBackups should fail")
+	}
+	cfg := &RotationConfig{}
+	if err := cfg.validate(); err != nil || cfg.MaxSizeMB != defaultRotationMaxSizeMB {
+		t.Errorf("validate() = %v, MaxSizeMB = %d, want default %d", err, cfg.MaxSizeMB, defaultRotationMaxSizeMB)
+	}
+}
+
+// TestExtraSinks 测试多路输出：各自的格式与级别、单个输出失败的隔离以及关闭时的错误合并
+func TestExtraSinks(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "collect.json")
+	brokenPath := filepath.Join(dir, "broken.log")
+
+	var console bytes.Buffer
+	logger, err := New(&Config{Level: "info", Format: "console", Output: "buffer"}, withBuffer(&console),
+		WithNamespace("svc"),
+		WithExtraSinks(
+			SinkConfig{Output: brokenPath, Format: "json", Level: "debug"},
+			SinkConfig{Output: jsonPath, Format: "json", Level: "debug"},
+		))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	logger.Debug("debug only to json", Int("n", 1))
+	logger.With(String("user_id", "u1")).Info("both sinks")
+
+	if strings.Contains(console.String(), "debug only") {
+		t.Errorf("console sink at info level should not output debug, got %q", console.String())
+	}
+	if !strings.Contains(console.String(), "both sinks") || strings.Contains(console.String(), `{"`) {
+		t.Errorf("console sink should render text format, got %q", console.String())
+	}
+
+	readJSON := func() []map[string]any {
+		t.Helper()
+		data, err := os.ReadFile(jsonPath)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		var entries []map[string]any
+		for line := range strings.SplitSeq(strings.TrimSpace(string(data)), "\n") {
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("json sink line %q: %v", line, err)
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+	entries := readJSON()
+	if len(entries) != 2 || entries[0]["msg"] != "debug only to json" || entries[1]["user_id"] != "u1" || entries[1]["namespace"] != "svc" {
+		t.Fatalf("json sink entries = %v, want debug + info with fields", entries)
+	}
+
+	t.Run("单个输出失败不影响其他输出", func(t *testing.T) {
+		h := logger.(*loggerImpl).handler.(*clogHandler)
+		if err := h.sinks[0].Close(); err != nil {
+			t.Fatalf("close broken sink: %v", err)
+		}
+		console.Reset()
+
+		r := slog.NewRecord(time.Now(), slog.LevelWarn, "after broken", 0)
+		if err := h.Handle(context.Background(), r); err == nil {
+			t.Errorf("Handle() should report the broken sink error")
+		}
+		if !strings.Contains(console.String(), "after broken") {
+			t.Errorf("primary sink should still be written, got %q", console.String())
+		}
+		if got := readJSON(); got[len(got)-1]["msg"] != "after broken" {
+			t.Errorf("healthy json sink should still be written, last = %v", got[len(got)-1])
+		}
+	})
+
+	t.Run("Close 逐个关闭并合并错误", func(t *testing.T) {
+		err := logger.Close()
+		if !errors.Is(err, os.ErrClosed) {
+			t.Fatalf("Close() error = %v, want the already closed broken sink", err)
+		}
+		h := logger.(*loggerImpl).handler.(*clogHandler)
+		if err := h.sinks[1].Close(); !errors.Is(err, os.ErrClosed) {
+			t.Errorf("healthy sink should be closed, second Close() = %v", err)
+		}
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		for _, s := range []SinkConfig{
+			{Output: ""},
+			{Output: "stdout", Format: "xml"},
+			{Output: "stdout", Level: "verbose"},
+			{Output: filepath.Join(dir, "missing", "x.log")},
+		} {
+			if _, err := New(&Config{Level: "info", Output: "stdout"}, WithExtraSinks(s)); err == nil {
+				t.Errorf("New() with sink %+v should fail", s)
+			}
+		}
+	})
+}
+
+// TestRedact 测试敏感字段脱敏
+func TestRedact(t *testing.T) {
+	type credential struct {
+		User     string `json:"user"`
+		Password string `json:"password"`
+	}
+	type ctxKey struct{}
+
+	newLogger := func(t *testing.T, opts ...Option) (Logger, *bytes.Buffer) {
+		t.Helper()
+		var buf bytes.Buffer
+		opts = append([]Option{withBuffer(&buf), WithContextField(ctxKey{}, "id_card")}, opts...)
+		logger, err := New(&Config{Level: "info", Format: "json", Output: "buffer"}, opts...)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		return logger, &buf
+	}
+	decode := func(t *testing.T, buf *bytes.Buffer) map[string]any {
+		t.Helper()
+		var entry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("Failed to parse log entry: %v", err)
+		}
+		buf.Reset()
+		return entry
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "110101199001011234")
+
+	t.Run("预设、传入与 Context 字段", func(t *testing.T) {
+		logger, buf := newLogger(t, WithRedactKeys("password", "Token", "id_card"))
+		logger.With(String("password", "p@ss")).InfoContext(ctx, "login",
+			String("TOKEN", "abc"), String("user", "alice"),
+			Group("req", String("token", "xyz"), Int("size", 1)))
+		entry := decode(t, buf)
+
+		for _, k := range []string{"password", "TOKEN", "id_card"} {
+			if entry[k] != defaultRedactValue {
+				t.Errorf("%s = %v, want %s", k, entry[k], defaultRedactValue)
+			}
+		}
+		if entry["user"] != "alice" {
+			t.Errorf("user = %v, want unchanged", entry["user"])
+		}
+		req := entry["req"].(map[string]any)
+		if req["token"] != defaultRedactValue || req["size"] != float64(1) {
+			t.Errorf("req = %v, want token redacted and size kept", req)
+		}
+	})
+
+	t.Run("嵌套 map 与 struct", func(t *testing.T) {
+		logger, buf := newLogger(t, WithRedactKeys("password"))
+		logger.Info("nested",
+			Any("cred", credential{User: "alice", Password: "p@ss"}),
+			Any("list", []map[string]any{{"password": "x", "n": 1}}),
+			Any("plain", map[string]int{"count": 1}))
+		entry := decode(t, buf)
+
+		cred := entry["cred"].(map[string]any)
+		if cred["password"] != defaultRedactValue || cred["user"] != "alice" {
+			t.Errorf("cred = %v, want password redacted", cred)
+		}
+		item := entry["list"].([]any)[0].(map[string]any)
+		if item["password"] != defaultRedactValue || item["n"] != float64(1) {
+			t.Errorf("list[0] = %v, want password redacted", item)
+		}
+		if entry["plain"].(map[string]any)["count"] != float64(1) {
+			t.Errorf("plain = %v, want unchanged", entry["plain"])
+		}
+	})
+
+	t.Run("自定义脱敏函数", func(t *testing.T) {
+		logger, buf := newLogger(t, WithRedactKeys("phone"), WithRedactFunc(func(key string, val any) any {
+			s, _ := val.(string)
+			if len(s) < 4 {
+				return defaultRedactValue
+			}
+			return "****" + s[len(s)-4:]
+		}))
+		logger.Info("sms", String("phone", "13800138000"))
+		if got := decode(t, buf)["phone"]; got != "****8000" {
+			t.Errorf("phone = %v, want ****8000", got)
+		}
+	})
+
+	t.Run("关闭嵌套脱敏只检查字段名", func(t *testing.T) {
+		logger, buf := newLogger(t, WithRedactKeys("password"), WithRedactNested(false))
+		logger.Info("shallow", String("password", "p@ss"), Any("cred", credential{Password: "p@ss"}))
+		entry := decode(t, buf)
+		if entry["password"] != defaultRedactValue {
+			t.Errorf("password = %v, want redacted", entry["password"])
+		}
+		if entry["cred"].(map[string]any)["password"] != "p@ss" {
+			t.Errorf("nested password should be kept when nested redaction is off, got %v", entry["cred"])
+		}
+	})
+}
+
+// BenchmarkRedact 对比未开启、只检查字段名与深入嵌套结构脱敏的单条日志开销
+func BenchmarkRedact(b *testing.B) {
+	payload := map[string]any{"user": "alice", "items": []int{1, 2, 3}}
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"off", nil},
+		{"keys_only", []Option{WithRedactKeys("password"), WithRedactNested(false)}},
+		{"nested", []Option{WithRedactKeys("password")}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			opts := append([]Option{withBuffer(&bytes.Buffer{})}, bc.opts...)
+			logger, _ := New(&Config{Level: "info", Format: "json", Output: "buffer"}, opts...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for b.Loop() {
+				logger.Info("request", String("path", "/v1/users"), Any("payload", payload))
+			}
+		})
+	}
+}
+
+// lineCounter 并发安全地统计写入行数并丢弃内容
+type lineCounter struct {
+	lines atomic.Int64
+}
+
+func (w *lineCounter) Write(p []byte) (int, error) {
+	w.lines.Add(int64(bytes.Count(p, []byte("\n"))))
+	return len(p), nil
+}
+
+// TestSampling 测试按 level + message 采样、Error 豁免与窗口重置
+func TestSampling(t *testing.T) {
+	newLogger := func(t *testing.T, cfg *SamplingConfig) (Logger, *lineCounter) {
+		t.Helper()
+		out := &lineCounter{}
+		logger, err := New(&Config{Level: "info", Format: "json", Output: "buffer", Sampling: cfg}, withWriter(out))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		return logger, out
+	}
+
+	t.Run("前 Initial 条全记，之后每 Thereafter 条记 1 条", func(t *testing.T) {
+		logger, out := newLogger(t, &SamplingConfig{Initial: 3, Thereafter: 5})
+		for i := 0; i < 20; i++ {
+			logger.Info("storm", Int("i", i))
+		}
+		// 第 1-3 条与第 8、13、18 条
+		if got := out.lines.Load(); got != 6 {
+			t.Errorf("lines = %d, want 6", got)
+		}
+		if got := SamplingDroppedCount(logger); got != 14 {
+			t.Errorf("SamplingDroppedCount() = %d, want 14", got)
+		}
+
+		// 不同的消息与级别分别计数，派生 logger 共享计数
+		logger.Info("other")
+		logger.Warn("storm")
+		logger.WithNamespace("child").Info("storm")
+		if got := out.lines.Load(); got != 8 {
+			t.Errorf("lines = %d, want 8 after distinct keys", got)
+		}
+	})
+
+	t.Run("Thereafter 为 0 时窗口内只记前 Initial 条", func(t *testing.T) {
+		logger, out := newLogger(t, &SamplingConfig{Initial: 2})
+		for i := 0; i < 10; i++ {
+			logger.Info("storm")
+		}
+		if got := out.lines.Load(); got != 2 {
+			t.Errorf("lines = %d, want 2", got)
+		}
+	})
+
+	t.Run("Error 与 Fatal 可豁免采样", func(t *testing.T) {
+		for _, exempt := range []bool{true, false} {
+			logger, out := newLogger(t, &SamplingConfig{Initial: 1, ExemptErrors: exempt})
+			for i := 0; i < 10; i++ {
+				logger.Error("db down")
+				logger.Fatal("panic")
+			}
+			want := int64(2)
+			if exempt {
+				want = 20
+			}
+			if got := out.lines.Load(); got != want {
+				t.Errorf("ExemptErrors=%v lines = %d, want %d", exempt, got, want)
+			}
+		}
+	})
+
+	t.Run("窗口结束后重新计数", func(t *testing.T) {
+		logger, out := newLogger(t, &SamplingConfig{Initial: 2})
+		logger.(*loggerImpl).options.sampler.window = 50 * time.Millisecond
+		for i := 0; i < 5; i++ {
+			logger.Info("storm")
+		}
+		time.Sleep(60 * time.Millisecond)
+		for i := 0; i < 5; i++ {
+			logger.Info("storm")
+		}
+		if got := out.lines.Load(); got != 4 {
+			t.Errorf("lines = %d, want 2 per window", got)
+		}
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		for _, cfg := range []*SamplingConfig{{}, {Initial: -1, Thereafter: 1}} {
+			if _, err := New(&Config{Output: "stdout", Sampling: cfg}); err == nil {
+				t.Errorf("New() with sampling %+v should fail", cfg)
+			}
+		}
+	})
+}
+
+// TestSamplingConcurrent 并发压测：错误风暴下写入量受采样上限控制
+func TestSamplingConcurrent(t *testing.T) {
+	out := &lineCounter{}
+	cfg := &SamplingConfig{Initial: 100, Thereafter: 100}
+	logger, err := New(&Config{Level: "info", Format: "json", Output: "buffer", Sampling: cfg}, withWriter(out))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	const goroutines, perGoroutine = 16, 5000
+	start := time.Now()
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perGoroutine; i++ {
+				logger.Error("connection refused", Int("g", g), Int("i", i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := int64(goroutines * perGoroutine)
+	written := out.lines.Load()
+	dropped := int64(SamplingDroppedCount(logger))
+	if written+dropped != total {
+		t.Errorf("written(%d) + dropped(%d) = %d, want %d", written, dropped, written+dropped, total)
+	}
+
+	// 每个窗口最多输出 Initial + (窗口内条数 - Initial) / Thereafter 条
+	windows := int64(time.Since(start)/samplingWindow) + 1
+	limit := windows*int64(cfg.Initial) + total/int64(cfg.Thereafter)
+	if written > limit {
+		t.Errorf("written = %d, want <= %d (%d windows)", written, limit, windows)
+	}
+	if written < int64(cfg.Initial) {
+		t.Errorf("written = %d, want at least Initial %d", written, cfg.Initial)
+	}
+	t.Logf("total=%d written=%d dropped=%d windows=%d", total, written, dropped, windows)
+}
@@ -0,0 +1,138 @@
+package clog
+
+import "context"
+
+// nop 是 Nop 返回的共享实例
+var nop Logger = &noopLogger{}
+
+// Nop 返回共享的空 Logger
+//
+// 与 Discard 行为一致，但每次返回同一个实例，不产生任何分配，适合作为字段默认值或热路径上的占位。
+func Nop() Logger {
+	return nop
+}
+
+// depthLogger 支持指定额外栈帧数输出日志的 Logger（内部使用），包装 Logger 借此保留正确的 caller
+type depthLogger interface {
+	logDepth(ctx context.Context, skip int, level Level, msg string, fields ...Field)
+}
+
+// conditionalLogger 按运行时条件决定是否转发到内部 Logger
+type conditionalLogger struct {
+	cond  func() bool
+	inner Logger
+}
+
+// Conditional 创建按条件输出的 Logger
+//
+// 每条日志输出前调用 cond，返回 false 时直接丢弃，不会写入 inner，也不会求值 Lazy 字段；
+// 返回 true 时与直接使用 inner 完全一致。cond 每条日志都会被调用，切换即时生效，
+// 因此应保持廉价且并发安全，例如读取 feature flag 或 atomic.Bool：
+//
+//	var verbose atomic.Bool
+//	debugLog := clog.Conditional(verbose.Load, logger)
+//
+// With / WithNamespace 派生的子 Logger 共享同一个 cond。SetLevel、Flush、Close 直接作用于 inner。
+// cond 或 inner 为 nil 时返回 Nop()。
+func Conditional(cond func() bool, inner Logger) Logger {
+	if cond == nil || inner == nil {
+		return Nop()
+	}
+	return &conditionalLogger{cond: cond, inner: inner}
+}
+
+func (l *conditionalLogger) Debug(msg string, fields ...Field) {
+	l.logDepth(context.Background(), 0, DebugLevel, msg, fields...)
+}
+
+func (l *conditionalLogger) Info(msg string, fields ...Field) {
+	l.logDepth(context.Background(), 0, InfoLevel, msg, fields...)
+}
+
+func (l *conditionalLogger) Warn(msg string, fields ...Field) {
+	l.logDepth(context.Background(), 0, WarnLevel, msg, fields...)
+}
+
+func (l *conditionalLogger) Error(msg string, fields ...Field) {
+	l.logDepth(context.Background(), 0, ErrorLevel, msg, fields...)
+}
+
+func (l *conditionalLogger) Fatal(msg string, fields ...Field) {
+	l.logDepth(context.Background(), 0, FatalLevel, msg, fields...)
+}
+
+func (l *conditionalLogger) DebugContext(ctx context.Context, msg string, fields ...Field) {
+	l.logDepth(ctx, 0, DebugLevel, msg, fields...)
+}
+
+func (l *conditionalLogger) InfoContext(ctx context.Context, msg string, fields ...Field) {
+	l.logDepth(ctx, 0, InfoLevel, msg, fields...)
+}
+
+func (l *conditionalLogger) WarnContext(ctx context.Context, msg string, fields ...Field) {
+	l.logDepth(ctx, 0, WarnLevel, msg, fields...)
+}
+
+func (l *conditionalLogger) ErrorContext(ctx context.Context, msg string, fields ...Field) {
+	l.logDepth(ctx, 0, ErrorLevel, msg, fields...)
+}
+
+func (l *conditionalLogger) FatalContext(ctx context.Context, msg string, fields ...Field) {
+	l.logDepth(ctx, 0, FatalLevel, msg, fields...)
+}
+
+// logDepth 条件成立时转发到 inner；inner 不支持指定栈帧时退化为普通方法调用
+func (l *conditionalLogger) logDepth(ctx context.Context, skip int, level Level, msg string, fields ...Field) {
+	if !l.cond() {
+		return
+	}
+	if d, ok := l.inner.(depthLogger); ok {
+		d.logDepth(ctx, skip+1, level, msg, fields...)
+		return
+	}
+
+	switch level {
+	case DebugLevel:
+		l.inner.DebugContext(ctx, msg, fields...)
+	case WarnLevel:
+		l.inner.WarnContext(ctx, msg, fields...)
+	case ErrorLevel:
+		l.inner.ErrorContext(ctx, msg, fields...)
+	case FatalLevel:
+		l.inner.FatalContext(ctx, msg, fields...)
+	default:
+		l.inner.InfoContext(ctx, msg, fields...)
+	}
+}
+
+func (l *conditionalLogger) With(fields ...Field) Logger {
+	return &conditionalLogger{cond: l.cond, inner: l.inner.With(fields...)}
+}
+
+func (l *conditionalLogger) WithNamespace(parts ...string) Logger {
+	return &conditionalLogger{cond: l.cond, inner: l.inner.WithNamespace(parts...)}
+}
+
+func (l *conditionalLogger) WithNamespaceFields(ns string, fields ...Field) Logger {
+	return &conditionalLogger{cond: l.cond, inner: l.inner.WithNamespaceFields(ns, fields...)}
+}
+
+// SetLevel 调整 inner 的日志级别
+func (l *conditionalLogger) SetLevel(level Level) error {
+	return l.inner.SetLevel(level)
+}
+
+// SetNamespaceLevel 调整 inner 的命名空间级别
+func (l *conditionalLogger) SetNamespaceLevel(namespace string, level Level) error {
+	return l.inner.SetNamespaceLevel(namespace, level)
+}
+
+// Flush 同步 inner 的缓冲区
+func (l *conditionalLogger) Flush() {
+	l.inner.Flush()
+}
+
+// Close 释放 inner 持有的资源
+func (l *conditionalLogger) Close() error {
+	return l.inner.Close()
+}
@@ -3,8 +3,10 @@ package clog
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
+// timeFormat 默认时间格式，RFC3339 毫秒精度
 const timeFormat = "2006-01-02T15:04:05.000Z07:00"
 
 // Config 日志配置结构
@@ -18,6 +20,22 @@ type Config struct {
 	EnableColor bool   `json:"enableColor" yaml:"enableColor"` // 仅在 console 格式下有效，开发环境可启用彩色输出
 	AddSource   bool   `json:"addSource" yaml:"addSource"`     // 是否添加调用源信息
 	SourceRoot  string `json:"sourceRoot" yaml:"sourceRoot"`   // 用于裁剪文件路径，推荐设置为你的项目根目录，获取相对路径
+	TimeFormat  string `json:"timeFormat" yaml:"timeFormat"`   // Go time layout，默认 RFC3339 毫秒精度
+	TimeZone    string `json:"timeZone" yaml:"timeZone"`       // IANA 时区名，如 Asia/Shanghai、UTC；为空时使用进程本地时区
+
+	// Async 异步写入配置，为 nil 时同步写入。
+	// 开关与缓冲容量复用 AsyncConfig（非 nil 即开启，容量为 AsyncConfig.BufferSize），
+	// 不再单独提供 Async bool / AsyncBufferSize 字段。
+	Async *AsyncConfig `json:"async,omitempty" yaml:"async,omitempty"`
+
+	// Sampling 日志采样配置，为 nil 时不采样
+	Sampling *SamplingConfig `json:"sampling,omitempty" yaml:"sampling,omitempty"`
+
+	// Rotation 文件输出的轮转配置，为 nil 时一直追加写同一文件；Output 为 stdout/stderr 时不可配置
+	Rotation *RotationConfig `json:"rotation,omitempty" yaml:"rotation,omitempty"`
+
+	// FieldKeys JSON 输出的字段名映射与扁平化，为 nil 时使用默认字段名
+	FieldKeys *FieldKeys `json:"fieldKeys,omitempty" yaml:"fieldKeys,omitempty"`
 }
 
 // NewDevDefaultConfig 创建开发环境的默认日志配置
@@ -53,6 +71,12 @@ func NewProdDefaultConfig(sourceRoot string) *Config {
 // 返回的错误：
 //   - invalid log level: 不支持的日志级别
 //   - invalid format: 不支持的输出格式
+//   - invalid time format: 时间格式不包含任何时间布局元素
+//   - invalid time zone: 无法加载的时区名
+//   - invalid async config: 异步缓冲容量或刷盘间隔为负数
+//   - invalid sampling config: 采样参数为负数或同时为 0
+//   - invalid rotation config: 轮转参数为负数，或 Output 不是文件路径
+//   - invalid field keys preset: 不支持的字段名预设
 func (c *Config) validate() error {
 	// 设置默认值
 	if c.Level == "" {
@@ -64,6 +88,9 @@ func (c *Config) validate() error {
 	if c.Output == "" {
 		c.Output = "stdout"
 	}
+	if c.TimeFormat == "" {
+		c.TimeFormat = timeFormat
+	}
 
 	if _, err := ParseLevel(c.Level); err != nil {
 		return err
@@ -72,6 +99,57 @@ func (c *Config) validate() error {
 	if format != "json" && format != "console" {
 		return fmt.Errorf("invalid format: %s, must be json or console", c.Format)
 	}
+	// 不含任何布局元素的格式（如 "yyyy-MM-dd"）按原样输出，视为配置错误
+	if time.Unix(0, 0).UTC().Format(c.TimeFormat) == c.TimeFormat {
+		return fmt.Errorf("invalid time format: %q contains no Go time layout elements", c.TimeFormat)
+	}
+	if _, err := c.location(); err != nil {
+		return err
+	}
+	if c.Async != nil {
+		if err := c.Async.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Sampling != nil {
+		if err := c.Sampling.validate(); err != nil {
+			return err
+		}
+	}
+	if c.Rotation != nil {
+		switch strings.ToLower(c.Output) {
+		case "stdout", "stderr", "buffer":
+			return fmt.Errorf("invalid rotation config: output %s is not a file path", c.Output)
+		}
+		if err := c.Rotation.validate(); err != nil {
+			return err
+		}
+	}
+	if c.FieldKeys != nil {
+		if err := c.FieldKeys.validate(); err != nil {
+			return err
+		}
+	}
 	// Output 字段可以是 stdout, stderr 或文件路径，不做严格校验
 	return nil
 }
+
+// location 解析 TimeZone，为空时返回 nil 表示保持记录自带的时区。
+func (c *Config) location() (*time.Location, error) {
+	if c.TimeZone == "" {
+		return nil, nil
+	}
+	loc, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone: %s: %w", c.TimeZone, err)
+	}
+	return loc, nil
+}
+
+// fieldKeys 返回 JSON 输出使用的字段名，console 格式始终使用默认字段名。
+func (c *Config) fieldKeys() fieldKeys {
+	if !strings.EqualFold(c.Format, "json") {
+		return (*FieldKeys)(nil).resolve()
+	}
+	return c.FieldKeys.resolve()
+}
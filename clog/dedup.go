@@ -0,0 +1,148 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dedupSuppressedKey 汇总日志中记录抑制次数的字段名
+const dedupSuppressedKey = "suppressed"
+
+// dedupHandler 基于内容指纹对重复日志去重。
+//
+// 指纹由 level + message + namespace 以及 WithDedup 指定的字段值组成。
+// 相同指纹在窗口内只输出第一条，窗口结束时若有被抑制的日志，
+// 补一条带 suppressed=N 字段的汇总日志。
+type dedupHandler struct {
+	inner slog.Handler
+	state *dedupState
+}
+
+// dedupState 在派生 handler 之间共享的去重状态
+type dedupState struct {
+	window time.Duration
+	keys   map[string]struct{}
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+// dedupEntry 一个指纹在当前窗口内的状态
+type dedupEntry struct {
+	first      slog.Record
+	handler    slog.Handler
+	suppressed int
+	timer      *time.Timer
+}
+
+func newDedupHandler(inner slog.Handler, window time.Duration, keys []string) *dedupHandler {
+	keySet := map[string]struct{}{"namespace": {}}
+	for _, k := range keys {
+		keySet[k] = struct{}{}
+	}
+	return &dedupHandler{
+		inner: inner,
+		state: &dedupState{
+			window:  window,
+			keys:    keySet,
+			entries: make(map[string]*dedupEntry),
+		},
+	}
+}
+
+// Enabled 检查日志级别是否启用。
+func (h *dedupHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle 窗口内首次出现的指纹直接输出，重复的只计数。
+func (h *dedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	fp := h.state.fingerprint(r)
+
+	h.state.mu.Lock()
+	if e, ok := h.state.entries[fp]; ok {
+		e.suppressed++
+		h.state.mu.Unlock()
+		return nil
+	}
+	e := &dedupEntry{first: r.Clone(), handler: h.inner}
+	h.state.entries[fp] = e
+	e.timer = time.AfterFunc(h.state.window, func() {
+		h.state.expire(fp, e)
+	})
+	h.state.mu.Unlock()
+
+	return h.inner.Handle(ctx, r)
+}
+
+// WithAttrs 返回带有附加属性的新 handler，去重状态共享。
+func (h *dedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &dedupHandler{inner: h.inner.WithAttrs(attrs), state: h.state}
+}
+
+// WithGroup 返回带有分组的新 handler，去重状态共享。
+func (h *dedupHandler) WithGroup(name string) slog.Handler {
+	return &dedupHandler{inner: h.inner.WithGroup(name), state: h.state}
+}
+
+// flush 立即结束所有窗口并输出汇总，用于 Flush/Close。
+func (h *dedupHandler) flush() {
+	h.state.mu.Lock()
+	entries := h.state.entries
+	h.state.entries = make(map[string]*dedupEntry)
+	h.state.mu.Unlock()
+
+	for _, e := range entries {
+		e.timer.Stop()
+		e.emitSummary()
+	}
+}
+
+// expire 窗口结束，移除指纹并输出汇总。
+func (s *dedupState) expire(fp string, e *dedupEntry) {
+	s.mu.Lock()
+	if s.entries[fp] != e {
+		// 已被 flush 处理
+		s.mu.Unlock()
+		return
+	}
+	delete(s.entries, fp)
+	s.mu.Unlock()
+
+	e.emitSummary()
+}
+
+// fingerprint 计算日志的内容指纹。
+func (s *dedupState) fingerprint(r slog.Record) string {
+	var b strings.Builder
+	b.WriteString(r.Level.String())
+	b.WriteByte(0)
+	b.WriteString(r.Message)
+	r.Attrs(func(a slog.Attr) bool {
+		if _, ok := s.keys[a.Key]; ok {
+			b.WriteByte(0)
+			b.WriteString(a.Key)
+			b.WriteByte('=')
+			b.WriteString(a.Value.String())
+		}
+		return true
+	})
+	return b.String()
+}
+
+// emitSummary 输出"该日志被抑制 N 次"的汇总，沿用首条日志的级别、消息与字段。
+func (e *dedupEntry) emitSummary() {
+	if e.suppressed == 0 {
+		return
+	}
+	r := slog.NewRecord(time.Now(), e.first.Level, e.first.Message, e.first.PC)
+	e.first.Attrs(func(a slog.Attr) bool {
+		r.AddAttrs(a)
+		return true
+	})
+	r.AddAttrs(slog.Int(dedupSuppressedKey, e.suppressed))
+	_ = e.handler.Handle(context.Background(), r)
+}
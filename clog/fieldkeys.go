@@ -0,0 +1,133 @@
+package clog
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// 字段名预设
+const (
+	// FieldKeysPresetECS Elastic Common Schema 风格：@timestamp / log.level / message / log.origin.file.*，附带 ecs.version
+	FieldKeysPresetECS = "ecs"
+	// FieldKeysPresetLogstash Logstash JSON 风格：@timestamp / level / message / caller，附带 @version
+	FieldKeysPresetLogstash = "logstash"
+)
+
+const (
+	// callerKey 调用源的默认字段名
+	callerKey = "caller"
+	// ecsVersion ECS 预设输出的 ecs.version
+	ecsVersion = "8.11.0"
+)
+
+// FieldKeys JSON 输出的字段名映射
+//
+// 为空的键名沿用预设或默认值（time / level / msg / caller）。
+// 只对 json 格式生效，console 格式保持默认字段名。
+type FieldKeys struct {
+	Preset     string `json:"preset" yaml:"preset"`         // ecs|logstash，为空时不使用预设
+	Time       string `json:"time" yaml:"time"`             // 时间字段名
+	Level      string `json:"level" yaml:"level"`           // 级别字段名
+	Message    string `json:"message" yaml:"message"`       // 消息字段名
+	Caller     string `json:"caller" yaml:"caller"`         // 调用源字段名
+	CallerLine string `json:"callerLine" yaml:"callerLine"` // 非空时行号单独输出到该字段，Caller 只保留文件路径
+	Flatten    bool   `json:"flatten" yaml:"flatten"`       // 把 Group、Error 等嵌套字段展开为点分键名，如 error.msg
+}
+
+// fieldKeys 合并预设后的字段名（内部使用）
+type fieldKeys struct {
+	time       string
+	level      string
+	message    string
+	caller     string
+	callerLine string
+	flatten    bool
+	static     []slog.Attr // 预设要求的固定字段
+}
+
+// validate 检查预设名称
+func (k *FieldKeys) validate() error {
+	switch strings.ToLower(k.Preset) {
+	case "", FieldKeysPresetECS, FieldKeysPresetLogstash:
+		return nil
+	default:
+		return fmt.Errorf("invalid field keys preset: %s, must be ecs or logstash", k.Preset)
+	}
+}
+
+// resolve 按 预设 < 显式配置 的优先级得到最终字段名，k 为 nil 时返回默认字段名
+func (k *FieldKeys) resolve() fieldKeys {
+	keys := fieldKeys{
+		time:    slog.TimeKey,
+		level:   slog.LevelKey,
+		message: slog.MessageKey,
+		caller:  callerKey,
+	}
+	if k == nil {
+		return keys
+	}
+
+	switch strings.ToLower(k.Preset) {
+	case FieldKeysPresetECS:
+		keys.time = "@timestamp"
+		keys.level = "log.level"
+		keys.message = "message"
+		keys.caller = "log.origin.file.name"
+		keys.callerLine = "log.origin.file.line"
+		keys.static = []slog.Attr{slog.String("ecs.version", ecsVersion)}
+	case FieldKeysPresetLogstash:
+		keys.time = "@timestamp"
+		keys.message = "message"
+		keys.static = []slog.Attr{slog.String("@version", "1")}
+	}
+
+	override := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	override(&keys.time, k.Time)
+	override(&keys.level, k.Level)
+	override(&keys.message, k.Message)
+	override(&keys.caller, k.Caller)
+	override(&keys.callerLine, k.CallerLine)
+	keys.flatten = k.Flatten
+	return keys
+}
+
+// flattenAttrs 把嵌套分组展开为点分键名，key 为空的分组直接内联
+func flattenAttrs(attrs []slog.Attr) []slog.Attr {
+	if !hasGroup(attrs) {
+		return attrs
+	}
+	flat := make([]slog.Attr, 0, len(attrs))
+	return appendFlattened(flat, "", attrs)
+}
+
+func appendFlattened(dst []slog.Attr, prefix string, attrs []slog.Attr) []slog.Attr {
+	for _, a := range attrs {
+		key := a.Key
+		if prefix != "" && key != "" {
+			key = prefix + "." + key
+		} else if key == "" {
+			key = prefix
+		}
+		if a.Value.Kind() == slog.KindGroup {
+			dst = appendFlattened(dst, key, a.Value.Group())
+			continue
+		}
+		dst = append(dst, slog.Attr{Key: key, Value: a.Value})
+	}
+	return dst
+}
+
+// hasGroup 判断 attrs 中是否含有分组
+func hasGroup(attrs []slog.Attr) bool {
+	for _, a := range attrs {
+		if a.Value.Kind() == slog.KindGroup {
+			return true
+		}
+	}
+	return false
+}
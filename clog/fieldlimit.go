@@ -0,0 +1,106 @@
+package clog
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+const (
+	// truncatedSuffix 被截断的字段值末尾追加的标记
+	truncatedSuffix = "...truncated"
+	// droppedFieldsKey 记录单条日志因字段数超限被丢弃字段个数的字段名
+	droppedFieldsKey = "dropped_fields"
+)
+
+// fieldLimits 单条日志的字段数量与字段值大小限制，在派生 logger 之间共享计数
+type fieldLimits struct {
+	maxFieldBytes int // 单个字段值的最大字节数，<= 0 不限制
+	maxFields     int // 单条日志的最大业务字段数，<= 0 不限制
+
+	truncated atomic.Uint64 // 累计被截断的字段值个数
+	dropped   atomic.Uint64 // 累计被丢弃的字段个数
+}
+
+// FieldLimitStats 返回因 WithMaxFieldBytes 截断的字段值个数与因 WithMaxFields 丢弃的字段个数
+//
+// 未配置字段限制的 Logger 始终返回 0，0。计数在 With / WithNamespace 派生的 logger 之间共享。
+func FieldLimitStats(l Logger) (truncated, dropped uint64) {
+	impl, ok := l.(*loggerImpl)
+	if !ok || impl.options.fieldLimits == nil {
+		return 0, 0
+	}
+	return impl.options.fieldLimits.truncated.Load(), impl.options.fieldLimits.dropped.Load()
+}
+
+// apply 对一条日志的业务字段执行限制，attrs 为本条日志独占的切片，可原地修改
+//
+// 字段数超限时保留前 maxFields 个字段（With 绑定的字段在前），并追加 dropped_fields=N；
+// 字段值超限时截断到 maxFieldBytes 字节并追加 ...truncated 标记，Group 内的字段逐个检查。
+func (f *fieldLimits) apply(attrs []slog.Attr) []slog.Attr {
+	var dropped int
+	if f.maxFields > 0 && len(attrs) > f.maxFields {
+		dropped = len(attrs) - f.maxFields
+		attrs = attrs[:f.maxFields]
+		f.dropped.Add(uint64(dropped))
+	}
+
+	if f.maxFieldBytes > 0 {
+		for i := range attrs {
+			if v, ok := f.truncate(attrs[i].Value); ok {
+				attrs[i].Value = v
+			}
+		}
+	}
+
+	if dropped > 0 {
+		attrs = append(attrs, slog.Int(droppedFieldsKey, dropped))
+	}
+	return attrs
+}
+
+// truncate 返回截断后的字段值，未超限时返回 false
+//
+// 字符串按原值计算长度；Any 类型按 fmt 格式化后的文本计算，超限时以截断后的字符串输出。
+func (f *fieldLimits) truncate(v slog.Value) (slog.Value, bool) {
+	var s string
+	switch v.Kind() {
+	case slog.KindString:
+		s = v.String()
+	case slog.KindAny:
+		s = v.String()
+	case slog.KindLogValuer:
+		return f.truncate(v.Resolve())
+	case slog.KindGroup:
+		group := v.Group()
+		var resolved []slog.Attr
+		for i, a := range group {
+			tv, ok := f.truncate(a.Value)
+			if !ok {
+				continue
+			}
+			if resolved == nil {
+				// 不能原地修改：Group 的成员切片可能被调用方复用
+				resolved = append([]slog.Attr(nil), group...)
+			}
+			resolved[i].Value = tv
+		}
+		if resolved == nil {
+			return v, false
+		}
+		return slog.GroupValue(resolved...), true
+	default:
+		return v, false
+	}
+
+	if len(s) <= f.maxFieldBytes {
+		return v, false
+	}
+	n := f.maxFieldBytes
+	// 回退到完整的 UTF-8 字符边界，避免输出半个字符
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	f.truncated.Add(1)
+	return slog.StringValue(s[:n] + truncatedSuffix), true
+}
@@ -61,6 +61,62 @@ func Group(k string, fields ...any) Field {
 	return slog.Group(k, fields...)
 }
 
+// Lazy 创建延迟求值字段
+//
+// fn 只在日志级别启用、该条日志确实输出时才调用，且每条日志只调用一次，
+// 适合序列化大对象、拼接调试信息等开销较大的字段：
+//
+//	logger.Debug("request", clog.Lazy("body", func() any { return dump(req) }))
+func Lazy(k string, fn func() any) Field {
+	return slog.Any(k, lazyValue(fn))
+}
+
+// lazyValue 实现 slog.LogValuer，由 logger 在级别检查通过后求值
+type lazyValue func() any
+
+// LogValue 实现 slog.LogValuer
+func (f lazyValue) LogValue() slog.Value {
+	return slog.AnyValue(f())
+}
+
+// resolveLazy 原地求值 attrs 中的 Lazy 字段（含 Group 内嵌套的字段）
+//
+// 在进入 handler 之前求值一次，去重等 handler 读取字段时不会重复调用 fn。
+func resolveLazy(attrs []slog.Attr) {
+	for i, a := range attrs {
+		switch a.Value.Kind() {
+		case slog.KindLogValuer:
+			if _, ok := a.Value.Any().(lazyValue); ok {
+				attrs[i].Value = a.Value.Resolve()
+			}
+		case slog.KindGroup:
+			group := a.Value.Group()
+			if hasLazy(group) {
+				resolved := append([]slog.Attr(nil), group...)
+				resolveLazy(resolved)
+				attrs[i].Value = slog.GroupValue(resolved...)
+			}
+		}
+	}
+}
+
+// hasLazy 判断 attrs 中是否含有 Lazy 字段
+func hasLazy(attrs []slog.Attr) bool {
+	for _, a := range attrs {
+		switch a.Value.Kind() {
+		case slog.KindLogValuer:
+			if _, ok := a.Value.Any().(lazyValue); ok {
+				return true
+			}
+		case slog.KindGroup:
+			if hasLazy(a.Value.Group()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 const (
 	errorKey      = "error"
 	errorMsgKey   = "msg"
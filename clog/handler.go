@@ -10,6 +10,9 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/ceyewan/genesis/xerrors"
 )
 
 // clogHandler 封装 slog.Handler，提供动态级别和 Flush 能力。
@@ -17,24 +20,68 @@ type clogHandler struct {
 	slog.Handler
 	levelVar *slog.LevelVar
 	closer   io.Closer
+	dedup    *dedupHandler
+	async    *asyncWriter
+	extras   []slog.Handler // WithExtraSinks 配置的附加输出，各自按自身级别过滤
+	sinks    []io.Closer    // 附加输出持有的文件句柄
 }
 
 // newHandler 创建并返回一个适配 clog 配置的 slog.Handler（内部使用）。
 //
-// 构造顺序：writer -> handler options -> base handler -> (optional) color handler -> wrapper。
+// 构造顺序：writer (optional rotation) -> (optional) async writer -> handler options -> base handler -> (optional) color handler
+// -> (optional) dedup handler -> wrapper (optional extra sinks)。
 func newHandler(config *Config, options *options) (slog.Handler, error) {
 	w, closer, err := resolveWriter(config, options)
 	if err != nil {
 		return nil, err
 	}
 
+	var async *asyncWriter
+	if config.Async != nil {
+		async = newAsyncWriter(w, config.Async, options.asyncFullPolicy)
+		w = async
+	}
+
 	levelVar := new(slog.LevelVar)
 	levelVar.Set(slogLevelFromConfig(config.Level))
 
-	replaceAttr := newReplaceAttr(config)
+	handler, err := newFormatHandler(config, w, levelVar)
+	if err != nil {
+		return nil, err
+	}
+
+	var dedup *dedupHandler
+	if options.dedupWindow > 0 {
+		dedup = newDedupHandler(handler, options.dedupWindow, options.dedupKeys)
+		handler = dedup
+	}
+
+	extras, sinks, err := newExtraSinks(config, options)
+	if err != nil {
+		if async != nil {
+			async.Close()
+		}
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return nil, err
+	}
+
+	return &clogHandler{Handler: handler, levelVar: levelVar, closer: closer, dedup: dedup, async: async, extras: extras, sinks: sinks}, nil
+}
+
+// newFormatHandler 按 config 的格式、时间与字段名配置创建写入 w 的 handler，级别由 level 控制。
+func newFormatHandler(config *Config, w io.Writer, level slog.Leveler) (slog.Handler, error) {
+	loc, err := config.location()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := config.fieldKeys()
+	replaceAttr := newReplaceAttr(config, loc, keys)
 	opts := &slog.HandlerOptions{
 		AddSource:   config.AddSource,
-		Level:       levelVar,
+		Level:       level,
 		ReplaceAttr: replaceAttr,
 	}
 
@@ -42,19 +89,22 @@ func newHandler(config *Config, options *options) (slog.Handler, error) {
 	var handler slog.Handler
 	if format == "json" {
 		handler = slog.NewJSONHandler(w, opts)
+		if len(keys.static) > 0 {
+			handler = handler.WithAttrs(keys.static)
+		}
 	} else {
 		textFactory := func(writer io.Writer) slog.Handler {
 			return slog.NewTextHandler(writer, opts)
 		}
 
 		if config.EnableColor {
-			handler = newColoredTextHandler(textFactory, w)
+			// 自定义时间格式时不再截取时分秒，避免错位
+			handler = newColoredTextHandler(textFactory, w, config.TimeFormat == timeFormat)
 		} else {
 			handler = textFactory(w)
 		}
 	}
-
-	return &clogHandler{Handler: handler, levelVar: levelVar, closer: closer}, nil
+	return handler, nil
 }
 
 // resolveWriter 根据配置创建输出 writer。
@@ -70,6 +120,13 @@ func resolveWriter(config *Config, options *options) (io.Writer, io.Closer, erro
 		}
 		return nil, nil, fmt.Errorf("buffer output requires options.buffer to be set")
 	default:
+		if config.Rotation != nil {
+			r, err := newRotatingFile(config.Output, config.Rotation)
+			if err != nil {
+				return nil, nil, err
+			}
+			return r, r, nil
+		}
 		f, err := os.OpenFile(config.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
 		if err != nil {
 			return nil, nil, err
@@ -80,25 +137,22 @@ func resolveWriter(config *Config, options *options) (io.Writer, io.Closer, erro
 
 // slogLevelFromConfig 将配置的 Level 映射为 slog.Level。
 func slogLevelFromConfig(level string) slog.Level {
-	switch strings.ToLower(level) {
-	case "debug":
-		return slog.LevelDebug
-	case "info":
-		return slog.LevelInfo
-	case "warn":
-		return slog.LevelWarn
-	case "error":
-		return slog.LevelError
-	case "fatal":
-		return slog.LevelError + 4
-	default:
-		return slog.LevelInfo
-	}
+	// 无法解析时 ParseLevel 返回 InfoLevel
+	lvl, _ := ParseLevel(level)
+	return slogLevel(lvl)
 }
 
-// newReplaceAttr 统一处理 Level/Time/Source 等字段。
-func newReplaceAttr(config *Config) func(groups []string, a slog.Attr) slog.Attr {
+// newReplaceAttr 统一处理 Level/Time/Source 等字段，并按 keys 重命名。
+//
+// loc 非 nil 时，时间字段会先转换到该时区，再按 config.TimeFormat 格式化。
+func newReplaceAttr(config *Config, loc *time.Location, keys fieldKeys) func(groups []string, a slog.Attr) slog.Attr {
 	return func(groups []string, a slog.Attr) slog.Attr {
+		// 只重命名顶层的内置字段，Error 等分组内的同名字段（如 error.msg）保持不变
+		rename := func(key string) {
+			if len(groups) == 0 {
+				a.Key = key
+			}
+		}
 		switch a.Key {
 		case slog.LevelKey:
 			level := a.Value.Any().(slog.Level)
@@ -116,15 +170,27 @@ func newReplaceAttr(config *Config) func(groups []string, a slog.Attr) slog.Attr
 				levelStr = "FATAL"
 			}
 			a.Value = slog.StringValue(levelStr)
+			rename(keys.level)
+		case slog.MessageKey:
+			rename(keys.message)
 		case slog.TimeKey:
 			if a.Value.Kind() == slog.KindTime {
-				a.Value = slog.StringValue(a.Value.Time().Format(timeFormat))
+				t := a.Value.Time()
+				if loc != nil {
+					t = t.In(loc)
+				}
+				a.Value = slog.StringValue(t.Format(config.TimeFormat))
 			}
+			rename(keys.time)
 		case slog.SourceKey:
 			if source, ok := a.Value.Any().(*slog.Source); ok {
 				fileName := trimSourcePath(source.File, config.SourceRoot)
+				if keys.callerLine != "" {
+					// key 为空的分组会被内联，文件与行号输出为两个顶层字段
+					return slog.Group("", slog.String(keys.caller, fileName), slog.Int(keys.callerLine, source.Line))
+				}
 				caller := fmt.Sprintf("%s:%d", fileName, source.Line)
-				return slog.String("caller", caller)
+				return slog.String(keys.caller, caller)
 			}
 		}
 		return a
@@ -145,39 +211,67 @@ func trimSourcePath(fileName, sourceRoot string) string {
 	return fileName
 }
 
-// SetLevel 动态调整日志级别。
-func (h *clogHandler) SetLevel(level Level) error {
-	var slogLevel slog.Level
-	switch level {
-	case DebugLevel:
-		slogLevel = slog.LevelDebug
-	case InfoLevel:
-		slogLevel = slog.LevelInfo
-	case WarnLevel:
-		slogLevel = slog.LevelWarn
-	case ErrorLevel:
-		slogLevel = slog.LevelError
-	case FatalLevel:
-		slogLevel = slog.LevelError + 4
-	default:
-		slogLevel = slog.LevelInfo
+// WithAttrs 返回预编码了 attrs 的新 handler，级别、去重、异步写入等状态与原 handler 共享。
+func (h *clogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
 	}
+	clone := *h
+	clone.Handler = h.Handler.WithAttrs(attrs)
+	clone.extras = make([]slog.Handler, len(h.extras))
+	for i, e := range h.extras {
+		clone.extras[i] = e.WithAttrs(attrs)
+	}
+	return &clone
+}
 
-	h.levelVar.Set(slogLevel)
+// WithGroup 返回带有分组的新 handler，状态与原 handler 共享。
+func (h *clogHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.Handler = h.Handler.WithGroup(name)
+	clone.extras = make([]slog.Handler, len(h.extras))
+	for i, e := range h.extras {
+		clone.extras[i] = e.WithGroup(name)
+	}
+	return &clone
+}
+
+// SetLevel 动态调整日志级别。
+func (h *clogHandler) SetLevel(level Level) error {
+	h.levelVar.Set(slogLevel(level))
 	return nil
 }
 
-// Flush 强制同步所有缓冲区的日志 (slog 默认是同步的，这里留空)。
+// Flush 强制同步所有缓冲区的日志 (slog 默认是同步的)。
+//
+// 开启去重时会立即输出所有窗口内的抑制汇总；开启异步写入时会等待缓冲中的日志落盘。
 func (h *clogHandler) Flush() {
-	// No-op for standard slog handlers
+	if h.dedup != nil {
+		h.dedup.flush()
+	}
+	if h.async != nil {
+		h.async.Flush()
+	}
 }
 
 // Close 释放 handler 关联的底层资源。
+//
+// 开启异步写入时先写完缓冲中的全部日志，再关闭底层文件；附加输出逐个关闭，错误合并返回。
 func (h *clogHandler) Close() error {
+	if h.dedup != nil {
+		h.dedup.flush()
+	}
+	if h.async != nil {
+		h.async.Close()
+	}
+	var errs []error
 	if h.closer != nil {
-		return h.closer.Close()
+		errs = append(errs, h.closer.Close())
 	}
-	return nil
+	for _, c := range h.sinks {
+		errs = append(errs, c.Close())
+	}
+	return xerrors.Combine(errs...)
 }
 
 // ANSI 颜色常量
@@ -206,13 +300,15 @@ type coloredTextHandler struct {
 	attrs       []slog.Attr
 	groups      []string
 	mu          *sync.Mutex
+	shortTime   bool // 是否将默认格式的时间戳截短为时分秒
 }
 
-func newColoredTextHandler(textFactory func(io.Writer) slog.Handler, writer io.Writer) slog.Handler {
+func newColoredTextHandler(textFactory func(io.Writer) slog.Handler, writer io.Writer, shortTime bool) slog.Handler {
 	return &coloredTextHandler{
 		textFactory: textFactory,
 		writer:      writer,
 		mu:          &sync.Mutex{},
+		shortTime:   shortTime,
 	}
 }
 
@@ -247,6 +343,7 @@ func (h *coloredTextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 		attrs:       append(append([]slog.Attr(nil), h.attrs...), attrs...),
 		groups:      append([]string(nil), h.groups...),
 		mu:          h.mu,
+		shortTime:   h.shortTime,
 	}
 }
 
@@ -258,6 +355,7 @@ func (h *coloredTextHandler) WithGroup(name string) slog.Handler {
 		attrs:       append([]slog.Attr(nil), h.attrs...),
 		groups:      append(append([]string(nil), h.groups...), name),
 		mu:          h.mu,
+		shortTime:   h.shortTime,
 	}
 }
 
@@ -305,7 +403,7 @@ func (h *coloredTextHandler) colorizeOutput(output string, level slog.Level) str
 			// 缩短时间戳，只显示时间部分
 			// 原始: 2025-12-24T15:48:17.340+08:00
 			// 截取: 15:48:17.340
-			if len(val) > 23 {
+			if h.shortTime && len(val) > 23 {
 				timeStr = val[11:23]
 			} else {
 				timeStr = val
@@ -2,6 +2,7 @@ package clog
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"runtime"
 	"time"
@@ -12,11 +13,28 @@ type loggerImpl struct {
 	handler   slog.Handler
 	config    *Config
 	options   *options
-	baseAttrs []slog.Attr
+	baseAttrs []slog.Attr // 待每条日志编码的预设字段，仅在无法预编码时使用
+	flatten   bool        // JSON 输出时把嵌套字段展开为点分键名
+	preencode bool        // With 字段是否交给 handler.WithAttrs 预编码
 }
 
 // newLogger 创建Logger实例（内部使用）
 func newLogger(config *Config, options *options) (Logger, error) {
+	options.redactor = newRedactor(options)
+	if config.Sampling != nil {
+		options.sampler = newSampler(config.Sampling)
+	}
+	options.levelRegistry = newLevelRegistry()
+	for ns, s := range options.namespaceLevels {
+		level, err := ParseLevel(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace level for %q: %w", ns, err)
+		}
+		if err := options.levelRegistry.set(ns, level); err != nil {
+			return nil, fmt.Errorf("invalid namespace level: %w", err)
+		}
+	}
+
 	handler, err := newHandler(config, options)
 	if err != nil {
 		return nil, err
@@ -26,6 +44,9 @@ func newLogger(config *Config, options *options) (Logger, error) {
 		handler: handler,
 		config:  config,
 		options: options,
+		flatten: config.fieldKeys().flatten,
+		// 去重指纹与字段限制都作用于每条日志的字段，预编码的字段对其不可见
+		preencode: options.dedupWindow <= 0 && options.fieldLimits == nil,
 	}
 
 	logger.setupBaseAttrs()
@@ -83,12 +104,42 @@ func (l *loggerImpl) WithNamespace(parts ...string) Logger {
 		config:    l.config,
 		options:   &newOptions,
 		baseAttrs: append([]slog.Attr(nil), l.baseAttrs...),
+		flatten:   l.flatten,
+		preencode: l.preencode,
 	}
 
 	return newLogger
 }
 
+func (l *loggerImpl) WithNamespaceFields(ns string, fields ...Field) Logger {
+	// WithNamespace 与 With 都会复制 options 和 baseAttrs，派生链上的兄弟 Logger 互不影响
+	return l.WithNamespace(ns).With(fields...)
+}
+
 func (l *loggerImpl) With(fields ...Field) Logger {
+	// 预设字段交给 handler.WithAttrs 预编码：slog 内置 handler 会把字段格式化成字节片段缓存在
+	// 派生 handler 中，之后每条日志直接拼接，不再重复编码。
+	//
+	// 为保持字段顺序与 Lazy 的按条求值语义，只有在尚无待编码字段且本次字段不含 Lazy 时才预编码；
+	// 一旦退回 baseAttrs，后续 With 的字段也留在 baseAttrs 中，始终排在预编码字段之后。
+	if l.preencode && len(l.baseAttrs) == 0 && !hasLazy(fields) {
+		attrs := append([]slog.Attr(nil), fields...)
+		if l.options.redactor != nil {
+			// 预编码前脱敏一次，之后每条日志直接拼接已脱敏的字段
+			l.options.redactor.apply(attrs)
+		}
+		if l.flatten {
+			attrs = flattenAttrs(attrs)
+		}
+		return &loggerImpl{
+			handler:   l.handler.WithAttrs(attrs),
+			config:    l.config,
+			options:   l.options,
+			flatten:   l.flatten,
+			preencode: l.preencode,
+		}
+	}
+
 	// 直接将 slog.Attr 字段追加到 baseAttrs。
 	//
 	// 注意：这里必须复制 baseAttrs，避免派生 Logger 之间共享底层数组导致字段互相覆盖。
@@ -104,6 +155,8 @@ func (l *loggerImpl) With(fields ...Field) Logger {
 		config:    l.config,
 		options:   l.options,
 		baseAttrs: baseAttrs,
+		flatten:   l.flatten,
+		preencode: l.preencode,
 	}
 
 	return newLogger
@@ -111,41 +164,68 @@ func (l *loggerImpl) With(fields ...Field) Logger {
 
 // 内部方法
 func (l *loggerImpl) log(ctx context.Context, level Level, msg string, fields ...Field) {
+	l.logDepth(ctx, 1, level, msg, fields...)
+}
+
+// logDepth 输出一条日志，skip 为日志方法与 logDepth 之间转发的栈帧数，用于定位正确的 caller。
+// 包装 Logger（如 Conditional）通过它转发，源码位置仍指向业务代码。
+func (l *loggerImpl) logDepth(ctx context.Context, skip int, level Level, msg string, fields ...Field) {
+	// 将 Level 映射为 slog.Level，避免直接按数字转换导致不一致
+	slevel := slogLevel(level)
+
+	// 使用 handler.Enabled 进行级别检查，避免直接调用 Handle 绕过过滤逻辑；
+	// 先于字段处理执行，级别未启用时不求值 Lazy 字段。
+	// 请求级缓冲需要暂存未启用的日志，供请求失败时一并输出
+	enabled := l.enabled(ctx, slevel)
+	buf := RequestBufferFromContext(ctx)
+	// 主输出未启用该级别时，附加输出仍可能按自身级别输出
+	h, _ := l.handler.(*clogHandler)
+	extras := !enabled && h != nil && h.extrasEnabled(ctx, slevel)
+	if !enabled && buf == nil && !extras {
+		return
+	}
+	// 采样在构造字段之前判断，被丢弃的日志不产生编码开销；只对将要输出的日志计数
+	if (enabled || extras) && l.options.sampler != nil && !l.options.sampler.allow(slevel, msg) {
+		return
+	}
+
 	// 准备属性切片：baseAttrs + fields + contextFields + namespaceFields
 	attrs := make([]slog.Attr, 0, len(l.baseAttrs)+len(fields)+4)
 	attrs = append(attrs, l.baseAttrs...)
 	attrs = append(attrs, fields...)
+	resolveLazy(attrs)
+	// 先脱敏再限制大小，避免截断把嵌套结构转成字符串后漏掉敏感键
+	if l.options.redactor != nil {
+		l.options.redactor.apply(attrs)
+	}
+	if l.options.fieldLimits != nil {
+		attrs = l.options.fieldLimits.apply(attrs)
+	}
 
 	// 提取Context字段、处理命名空间等
+	n := len(attrs)
 	extractContextFields(ctx, l.options, &attrs)
+	if l.options.redactor != nil {
+		l.options.redactor.apply(attrs[n:])
+	}
 	addNamespaceFields(l.options, &attrs) // 只在log方法中添加一次
-
-	// 将 Level 映射为 slog.Level，避免直接按数字转换导致不一致
-	var slogLevel slog.Level
-	switch level {
-	case DebugLevel:
-		slogLevel = slog.LevelDebug
-	case InfoLevel:
-		slogLevel = slog.LevelInfo
-	case WarnLevel:
-		slogLevel = slog.LevelWarn
-	case ErrorLevel:
-		slogLevel = slog.LevelError
-	case FatalLevel:
-		// Fatal 在 slog 中没有显式常量，使用 Error 的更高值
-		slogLevel = slog.LevelError + 4
-	default:
-		slogLevel = slog.LevelInfo
+	if l.flatten {
+		attrs = flattenAttrs(attrs)
 	}
 
 	// 获取正确的程序计数器(PC)值，用于准确的源码位置
 	var pcs [1]uintptr
-	runtime.Callers(3, pcs[:]) // skip: runtime.Callers, logger.log, Debug/Info/Error等
-	record := slog.NewRecord(time.Now(), slogLevel, msg, pcs[0])
+	runtime.Callers(3+skip, pcs[:]) // skip: runtime.Callers, logger.logDepth, Debug/Info/Error等以及 skip 个转发帧
+	record := slog.NewRecord(time.Now(), slevel, msg, pcs[0])
 	record.AddAttrs(attrs...)
 
-	// 使用 handler.Enabled 进行级别检查，避免直接调用 Handle 绕过过滤逻辑
-	if enabled := l.handler.Enabled(ctx, slogLevel); !enabled {
+	if buf != nil && buf.add(ctx, l.handler, record, enabled) {
+		return
+	}
+	if !enabled {
+		if extras {
+			_ = h.handleExtras(ctx, record)
+		}
 		return
 	}
 
@@ -166,6 +246,13 @@ func (l *loggerImpl) SetLevel(level Level) error {
 	return nil // 无法动态调整，忽略错误
 }
 
+// SetNamespaceLevel 为命名空间设置独立的日志级别
+//
+// 级别登记在同一 New 创建的所有 logger 共享的 registry 中，对已派生和之后派生的 logger 都立即生效。
+func (l *loggerImpl) SetNamespaceLevel(namespace string, level Level) error {
+	return l.options.levelRegistry.set(namespace, level)
+}
+
 // Flush 强制同步所有缓冲区的日志
 func (l *loggerImpl) Flush() {
 	if h, ok := l.handler.(interface{ Flush() }); ok {
@@ -2,6 +2,7 @@ package clog
 
 import (
 	"fmt"
+	"log/slog"
 	"strings"
 )
 
@@ -67,3 +68,24 @@ func ParseLevel(s string) (Level, error) {
 		return InfoLevel, fmt.Errorf("unknown log level: %s", s)
 	}
 }
+
+// slogLevel 将 Level 映射为 slog.Level，未知级别按 InfoLevel 处理
+//
+// 各级别数值与 slog 一致，这里显式映射以免自定义数值被原样透传。
+func slogLevel(level Level) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case InfoLevel:
+		return slog.LevelInfo
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	case FatalLevel:
+		// Fatal 在 slog 中没有显式常量，使用 Error 的更高值
+		return slog.LevelError + 4
+	default:
+		return slog.LevelInfo
+	}
+}
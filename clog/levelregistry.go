@@ -0,0 +1,59 @@
+package clog
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// levelRegistry 按命名空间记录独立的日志级别，在同一 New 派生出的所有 logger 之间共享
+//
+// 查找按最长前缀匹配：设置 "user-service" 会影响 "user-service.repo" 等所有子级，
+// 除非子级自己也设置了级别。只匹配完整的命名空间段，"user-service" 不影响 "user-service2"。
+type levelRegistry struct {
+	mu     sync.RWMutex
+	levels map[string]slog.Level
+	size   atomic.Int32 // 已设置的命名空间个数，为 0 时跳过查找
+}
+
+func newLevelRegistry() *levelRegistry {
+	return &levelRegistry{levels: make(map[string]slog.Level)}
+}
+
+// set 设置命名空间的级别
+func (r *levelRegistry) set(namespace string, level Level) error {
+	if namespace == "" {
+		return fmt.Errorf("namespace must not be empty, use SetLevel for the root logger")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.levels[namespace] = slogLevel(level)
+	r.size.Store(int32(len(r.levels)))
+	return nil
+}
+
+// lookup 返回 namespace 生效的级别，没有任何前缀设置过级别时 ok 为 false
+func (r *levelRegistry) lookup(namespace string) (level slog.Level, ok bool) {
+	if namespace == "" {
+		return 0, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for ns := namespace; ; {
+		if level, ok = r.levels[ns]; ok {
+			return level, true
+		}
+		i := strings.LastIndexByte(ns, '.')
+		if i < 0 {
+			return 0, false
+		}
+		ns = ns[:i]
+	}
+}
+
+// empty 判断是否未设置任何命名空间级别，供热路径无锁跳过查找
+func (r *levelRegistry) empty() bool {
+	return r.size.Load() == 0
+}
@@ -8,6 +8,15 @@
 //   - 采用函数式选项模式，符合 Genesis 标准
 //   - Field 直接映射到 slog.Attr，减少字段适配成本
 //   - 支持统一的 error 结构化字段输出
+//   - 支持按 level + message 的日志采样（Config.Sampling），防止错误风暴
+//   - 支持请求级日志缓冲（NewRequestBuffer），请求失败时才输出 debug 日志
+//   - 支持按命名空间设置独立级别（SetNamespaceLevel、WithNamespaceLevels），子命名空间继承父级
+//   - 支持按 trace 采样决策选择日志级别（WithSampledLevel），采样请求全量、非采样请求精简
+//   - 支持多路输出（WithExtraSinks），各输出独立的格式与级别
+//   - 支持文件输出按大小轮转（Config.Rotation），按个数与天数清理备份
+//   - 支持异步写入（Config.Async），缓冲满时按 WithAsyncFullPolicy 选择阻塞或丢弃
+//   - 支持敏感字段脱敏（WithRedactKeys、WithRedactFunc），覆盖预设、传入、Context 字段及嵌套结构
+//   - 支持限制字段值大小与字段数量（WithMaxFieldBytes、WithMaxFields），防止大对象产生巨型日志行
 //
 // 基本使用：
 //
@@ -67,9 +76,22 @@ type Logger interface {
 	// WithNamespace 创建一个扩展命名空间的子 Logger
 	WithNamespace(parts ...string) Logger
 
+	// WithNamespaceFields 创建一个扩展命名空间并绑定默认字段的子 Logger
+	//
+	// 等价于 WithNamespace(ns).With(fields...)：该命名空间下的日志自动带上 fields，
+	// 由它派生的子 Logger（包括更深的命名空间）继续继承，兄弟命名空间不受影响。
+	WithNamespaceFields(ns string, fields ...Field) Logger
+
 	// SetLevel 动态调整日志级别
 	SetLevel(level Level) error
 
+	// SetNamespaceLevel 为命名空间单独设置日志级别
+	//
+	// namespace 为完整的点分命名空间（如 "user-service.repo"），设置后该命名空间及其子级的
+	// logger 按此级别过滤，子级显式设置的级别优先；未设置的命名空间沿用 SetLevel 的全局级别。
+	// 对同一 New 创建的所有派生 logger 生效，可并发调用。
+	SetNamespaceLevel(namespace string, level Level) error
+
 	// Flush 强制同步所有缓冲区的日志
 	Flush()
 
@@ -34,11 +34,21 @@ func (l *noopLogger) WithNamespace(parts ...string) Logger {
 	return l
 }
 
+// WithNamespaceFields 返回自身（noopLogger 不记录字段）
+func (l *noopLogger) WithNamespaceFields(ns string, fields ...Field) Logger {
+	return l
+}
+
 // SetLevel 是空操作（noopLogger 不需要处理级别）
 func (l *noopLogger) SetLevel(level Level) error {
 	return nil
 }
 
+// SetNamespaceLevel 是空操作（noopLogger 不需要处理级别）
+func (l *noopLogger) SetNamespaceLevel(namespace string, level Level) error {
+	return nil
+}
+
 // Flush 是空操作（noopLogger 没有缓冲区）
 func (l *noopLogger) Flush() {}
 
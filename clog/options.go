@@ -1,6 +1,9 @@
 package clog
 
-import "bytes"
+import (
+	"io"
+	"time"
+)
 
 // ContextField 定义从 Context 中提取字段的规则
 type ContextField struct {
@@ -15,8 +18,27 @@ type Option func(*options)
 type options struct {
 	namespaceParts        []string
 	contextFields         []ContextField
-	buffer                *bytes.Buffer // 测试用缓冲区
+	buffer                io.Writer // 测试用输出，Output 为 "buffer" 时使用
 	enableTraceExtraction bool
+	dedupWindow           time.Duration
+	dedupKeys             []string
+	sampledLevels         *sampledLevels
+	fieldLimits           *fieldLimits
+	asyncFullPolicy       *FullPolicy
+	namespaceLevels       map[string]string // 初始化时批量设置的命名空间级别
+	levelRegistry         *levelRegistry    // 由 newLogger 创建，派生 logger 共享
+	extraSinks            []SinkConfig
+	redactKeys            []string
+	redactFunc            func(key string, val any) any
+	redactShallow         bool      // 不深入 Any 字段的嵌套结构
+	redactor              *redactor // 由 newLogger 创建
+	sampler               *sampler  // 由 newLogger 根据 Config.Sampling 创建，派生 logger 共享
+}
+
+// sampledLevels 按采样决策选择的最低日志级别
+type sampledLevels struct {
+	sampled   Level
+	unsampled Level
 }
 
 // WithNamespace 设置日志命名空间，支持多级命名空间
@@ -52,6 +74,128 @@ func WithTraceContext() Option {
 	}
 }
 
+// WithDedup 开启重复日志去重
+//
+// 以 level + message + namespace（以及 keys 指定字段的值）作为内容指纹，
+// 相同指纹在 window 内只输出第一条；窗口结束时若有被抑制的日志，
+// 会补一条带 suppressed=N 字段的汇总日志。window <= 0 时不开启。
+//
+// 未列入 keys 的字段不参与指纹，例如 WithDedup(time.Minute, "user_id")
+// 会按用户分别去重，而忽略 error 字段的具体内容。
+func WithDedup(window time.Duration, keys ...string) Option {
+	return func(o *options) {
+		o.dedupWindow = window
+		o.dedupKeys = append([]string(nil), keys...)
+	}
+}
+
+// WithSampledLevel 按 trace 采样决策动态选择日志级别
+//
+// 开启后 XxxContext 方法根据 ctx 的采样信息决定最低输出级别：被采样的请求使用 sampled
+// （如 DebugLevel，关键链路全量日志），未被采样的使用 unsampled（如 WarnLevel，只记告警）。
+// 采样信息优先取 ContextWithSampled 设置的显式标志，其次取 OTel span 的 sampled 位；
+// ctx 不带采样信息（无 span）以及不带 ctx 的 Debug/Info 等方法沿用 Config.Level 与 SetLevel。
+func WithSampledLevel(sampled, unsampled Level) Option {
+	return func(o *options) {
+		o.sampledLevels = &sampledLevels{sampled: sampled, unsampled: unsampled}
+	}
+}
+
+// WithNamespaceLevels 在创建时批量设置命名空间的独立日志级别
+//
+// 键为完整的命名空间（如 "user-service.repo"），值为 debug|info|warn|error|fatal，
+// 效果等同于逐个调用 Logger.SetNamespaceLevel。级别字符串不合法时 New 返回错误。
+func WithNamespaceLevels(levels map[string]string) Option {
+	return func(o *options) {
+		if o.namespaceLevels == nil {
+			o.namespaceLevels = make(map[string]string, len(levels))
+		}
+		for ns, level := range levels {
+			o.namespaceLevels[ns] = level
+		}
+	}
+}
+
+// WithRedactKeys 设置需要脱敏的字段名，不区分大小写
+//
+// 匹配的字段在写入前被替换为 "***"（或 WithRedactFunc 的返回值），作用于 With 预设字段、
+// 调用时传入的字段、从 Context 提取的字段以及 Group 内的字段。默认还会深入 Any 字段中的
+// map、struct、slice 查找匹配的键（struct 按 json tag 命名），可用 WithRedactNested(false) 关闭。
+func WithRedactKeys(keys ...string) Option {
+	return func(o *options) {
+		o.redactKeys = append(o.redactKeys, keys...)
+	}
+}
+
+// WithRedactFunc 自定义脱敏值，fn 接收字段名与原值，返回替换后的值
+//
+// 只对 WithRedactKeys 匹配的字段调用，例如保留手机号后四位。未设置时替换为 "***"。
+func WithRedactFunc(fn func(key string, val any) any) Option {
+	return func(o *options) {
+		o.redactFunc = fn
+	}
+}
+
+// WithRedactNested 设置是否深入 Any 字段中的 map、struct、slice 脱敏，默认开启
+//
+// 深入检查需要把值按 JSON 编码后逐层查找，每条带此类字段的日志都有额外开销；
+// 性能敏感且确认不会记录含敏感字段的结构体时可以关闭，只检查字段名与 Group。
+func WithRedactNested(enabled bool) Option {
+	return func(o *options) {
+		o.redactShallow = !enabled
+	}
+}
+
+// WithExtraSinks 添加附加输出，每条日志只记录一次，按各输出的格式分别渲染
+//
+// 每个附加输出有独立的 Output、Format 与 Level，其余配置沿用主 Config。附加输出的级别固定为配置值，
+// SetLevel、SetNamespaceLevel、WithSampledLevel 只作用于主输出；异步写入、轮转与去重也只作用于主输出。
+// 任一输出写入失败不影响其他输出；Close 逐个关闭并合并错误。
+func WithExtraSinks(sinks ...SinkConfig) Option {
+	return func(o *options) {
+		o.extraSinks = append(o.extraSinks, sinks...)
+	}
+}
+
+// WithAsyncFullPolicy 设置异步缓冲已满时的处理策略，覆盖 AsyncConfig.DropWhenFull
+//
+// BlockOnFull 阻塞到缓冲有空位，DropOnFull 丢弃并计入 DroppedCount。
+// 仅在 Config.Async 非 nil 时生效。
+func WithAsyncFullPolicy(policy FullPolicy) Option {
+	return func(o *options) {
+		o.asyncFullPolicy = &policy
+	}
+}
+
+// WithMaxFieldBytes 限制单个字段值的最大字节数
+//
+// 超限的字符串、Any 等字段值（含 Group 内的字段）被截断到 n 字节，并在末尾追加 "...truncated" 标记，
+// 防止误传整个 HTTP body 等大对象产生巨型日志行。Any 字段按 fmt 格式化后的文本计算长度。
+// n <= 0 时不限制。
+func WithMaxFieldBytes(n int) Option {
+	return func(o *options) {
+		o.limits().maxFieldBytes = n
+	}
+}
+
+// WithMaxFields 限制单条日志的最大业务字段数
+//
+// 超出的字段（按 With 绑定字段在前、调用时字段在后的顺序）被丢弃，并追加 dropped_fields=N 字段；
+// Context 提取字段与 namespace 不计入。n <= 0 时不限制。
+func WithMaxFields(n int) Option {
+	return func(o *options) {
+		o.limits().maxFields = n
+	}
+}
+
+// limits 返回字段限制配置，首次调用时创建
+func (o *options) limits() *fieldLimits {
+	if o.fieldLimits == nil {
+		o.fieldLimits = &fieldLimits{}
+	}
+	return o.fieldLimits
+}
+
 // applyOptions 应用所有选项并返回配置（内部使用）
 func applyOptions(opts ...Option) *options {
 	o := &options{
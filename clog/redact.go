@@ -0,0 +1,166 @@
+package clog
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// defaultRedactValue 未设置 WithRedactFunc 时敏感字段的替换值
+const defaultRedactValue = "***"
+
+// redactor 在字段写入前替换敏感字段的值
+type redactor struct {
+	keys   map[string]struct{} // 小写的敏感字段名
+	fn     func(key string, val any) any
+	nested bool // 是否深入 Any 字段中的 map、struct、slice
+}
+
+// newRedactor 根据选项创建 redactor，未配置敏感字段名时返回 nil
+func newRedactor(o *options) *redactor {
+	if len(o.redactKeys) == 0 {
+		return nil
+	}
+	r := &redactor{
+		keys:   make(map[string]struct{}, len(o.redactKeys)),
+		fn:     o.redactFunc,
+		nested: !o.redactShallow,
+	}
+	for _, k := range o.redactKeys {
+		r.keys[strings.ToLower(k)] = struct{}{}
+	}
+	if r.fn == nil {
+		r.fn = func(string, any) any { return defaultRedactValue }
+	}
+	return r
+}
+
+// apply 原地替换 attrs 中的敏感字段，attrs 必须为调用方独占的切片
+//
+// Group 的成员切片可能被多条日志复用，有字段需要替换时复制后再修改。
+func (r *redactor) apply(attrs []slog.Attr) {
+	for i, a := range attrs {
+		if v, ok := r.redact(a.Key, a.Value); ok {
+			attrs[i].Value = v
+		}
+	}
+}
+
+// redact 返回替换后的字段值，无需替换时返回 false
+func (r *redactor) redact(key string, v slog.Value) (slog.Value, bool) {
+	if r.match(key) {
+		if v.Kind() == slog.KindLogValuer {
+			v = v.Resolve()
+		}
+		return slog.AnyValue(r.fn(key, v.Any())), true
+	}
+
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		var redacted []slog.Attr
+		for i, a := range group {
+			rv, ok := r.redact(a.Key, a.Value)
+			if !ok {
+				continue
+			}
+			if redacted == nil {
+				redacted = append([]slog.Attr(nil), group...)
+			}
+			redacted[i].Value = rv
+		}
+		if redacted == nil {
+			return v, false
+		}
+		return slog.GroupValue(redacted...), true
+	case slog.KindLogValuer:
+		return r.redact(key, v.Resolve())
+	case slog.KindAny:
+		if !r.nested {
+			return v, false
+		}
+		if tree, ok := r.redactNested(v.Any()); ok {
+			return slog.AnyValue(tree), true
+		}
+	}
+	return v, false
+}
+
+// match 判断字段名是否敏感，不区分大小写
+func (r *redactor) match(key string) bool {
+	_, ok := r.keys[strings.ToLower(key)]
+	return ok
+}
+
+// redactNested 处理 Any 字段中的 map、struct、slice
+//
+// 值先按 JSON 编码为通用结构（与 json 格式的输出一致，遵循 json tag），再逐层替换敏感键；
+// 没有敏感键时返回 false，保持原值与原有编码。
+func (r *redactor) redactNested(val any) (any, bool) {
+	if !nestedKind(val) {
+		return nil, false
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return nil, false
+	}
+	if !r.redactTree(tree) {
+		return nil, false
+	}
+	return tree, true
+}
+
+// redactTree 原地替换 JSON 通用结构中的敏感键，返回是否有替换
+func (r *redactor) redactTree(node any) bool {
+	changed := false
+	switch n := node.(type) {
+	case map[string]any:
+		for k, v := range n {
+			if r.match(k) {
+				n[k] = r.fn(k, v)
+				changed = true
+				continue
+			}
+			if r.redactTree(v) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, v := range n {
+			if r.redactTree(v) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// nestedKind 判断值是否可能包含嵌套的敏感键：map、struct 及其切片，错误与 []byte 除外
+func nestedKind(val any) bool {
+	if val == nil {
+		return false
+	}
+	if _, ok := val.(error); ok {
+		return false
+	}
+	t := reflect.TypeOf(val)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Map, reflect.Struct:
+		return true
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() != reflect.Uint8
+	default:
+		return false
+	}
+}
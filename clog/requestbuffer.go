@@ -0,0 +1,106 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// defaultRequestBufferLimit 单个请求最多缓冲的日志条数
+const defaultRequestBufferLimit = 1000
+
+// requestBufferKey context 中 RequestBuffer 的键
+type requestBufferKey struct{}
+
+// RequestBuffer 请求级日志缓冲
+//
+// 通过 NewRequestBuffer 绑定到 context 后，使用该 context 调用 XxxContext 方法记录的日志
+// （包括低于当前级别的 debug 日志）先暂存在缓冲中，请求结束时由 Finish 统一输出：
+//   - 请求成功：只输出达到 Logger 级别的日志（如 info 及以上），低级别日志丢弃
+//   - 请求失败：按记录顺序输出全部日志，包括原本会被过滤的 debug 日志
+//
+// 不带 context 的 Debug/Info 等方法不经过缓冲。缓冲满（默认 1000 条）或 Finish 之后，
+// 日志按正常流程直接输出。
+type RequestBuffer struct {
+	mu       sync.Mutex
+	entries  []bufferedRecord
+	limit    int
+	finished bool
+}
+
+// bufferedRecord 一条暂存的日志
+type bufferedRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+	enabled bool // 记录时是否达到 Logger 级别
+}
+
+// NewRequestBuffer 创建请求级日志缓冲并绑定到 context
+//
+// 通常在 HTTP/gRPC 中间件入口调用，把返回的 ctx 传给后续处理链，请求结束时调用 Finish：
+//
+//	ctx, buf := clog.NewRequestBuffer(r.Context())
+//	err := next(ctx)
+//	buf.Finish(err)
+func NewRequestBuffer(ctx context.Context) (context.Context, *RequestBuffer) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	buf := &RequestBuffer{limit: defaultRequestBufferLimit}
+	return context.WithValue(ctx, requestBufferKey{}, buf), buf
+}
+
+// RequestBufferFromContext 返回 ctx 绑定的 RequestBuffer，未绑定时返回 nil
+func RequestBufferFromContext(ctx context.Context) *RequestBuffer {
+	if ctx == nil {
+		return nil
+	}
+	buf, _ := ctx.Value(requestBufferKey{}).(*RequestBuffer)
+	return buf
+}
+
+// Len 返回当前缓冲的日志条数
+func (b *RequestBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.entries)
+}
+
+// Finish 结束缓冲并输出日志，可重复调用，只有第一次生效
+//
+// err 为 nil 表示请求成功，只输出达到 Logger 级别的日志；否则输出全部缓冲的日志。
+func (b *RequestBuffer) Finish(err error) {
+	b.mu.Lock()
+	if b.finished {
+		b.mu.Unlock()
+		return
+	}
+	b.finished = true
+	entries := b.entries
+	b.entries = nil
+	b.mu.Unlock()
+
+	for _, e := range entries {
+		if err == nil && !e.enabled {
+			continue
+		}
+		_ = e.handler.Handle(e.ctx, e.record)
+	}
+}
+
+// add 暂存一条日志，缓冲已结束或已满时返回 false
+func (b *RequestBuffer) add(ctx context.Context, handler slog.Handler, record slog.Record, enabled bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.finished || len(b.entries) >= b.limit {
+		return false
+	}
+	b.entries = append(b.entries, bufferedRecord{
+		ctx:     context.WithoutCancel(ctx),
+		handler: handler,
+		record:  record,
+		enabled: enabled,
+	})
+	return true
+}
@@ -0,0 +1,308 @@
+package clog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRotationMaxSizeMB 单个日志文件默认的最大大小（MB）
+	defaultRotationMaxSizeMB = 100
+	// backupTimeFormat 备份文件名中的时间戳格式，不含冒号以兼容各平台文件名
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	// compressSuffix 压缩备份的文件后缀
+	compressSuffix = ".gz"
+	megabyte       = 1024 * 1024
+)
+
+// RotationConfig 文件输出的轮转配置，仅在 Output 为文件路径时有效
+//
+// 当前文件写满 MaxSizeMB 后重命名为带时间戳的备份（如 app-2025-01-02T15-04-05.000.log），
+// 再新建同名文件继续写入。备份按 MaxBackups 与 MaxAgeDays 清理，可选 gzip 压缩。
+type RotationConfig struct {
+	MaxSizeMB  int  `json:"maxSizeMB" yaml:"maxSizeMB"`   // 单个文件的最大大小（MB），默认 100
+	MaxBackups int  `json:"maxBackups" yaml:"maxBackups"` // 保留的备份个数，0 表示不按个数清理
+	MaxAgeDays int  `json:"maxAgeDays" yaml:"maxAgeDays"` // 备份保留天数，0 表示不按时间清理
+	Compress   bool `json:"compress" yaml:"compress"`     // 是否用 gzip 压缩备份
+}
+
+// validate 设置默认值并检查取值范围（内部使用）
+func (c *RotationConfig) validate() error {
+	if c.MaxSizeMB < 0 || c.MaxBackups < 0 || c.MaxAgeDays < 0 {
+		return fmt.Errorf("invalid rotation config: maxSizeMB %d, maxBackups %d and maxAgeDays %d must not be negative",
+			c.MaxSizeMB, c.MaxBackups, c.MaxAgeDays)
+	}
+	if c.MaxSizeMB == 0 {
+		c.MaxSizeMB = defaultRotationMaxSizeMB
+	}
+	return nil
+}
+
+// rotatingFile 按大小轮转的文件 writer
+//
+// Write 在互斥锁内完成大小检查、轮转与写入，轮转期间并发写入的日志等待新文件打开后继续写入，不会丢失。
+// 轮转失败时回到原文件继续追加写入，写满下一个 maxBytes 后再重试。
+// 备份的清理与压缩由后台 goroutine 执行，不阻塞写入方。
+type rotatingFile struct {
+	path     string
+	maxBytes int64
+	cfg      RotationConfig
+	rename   func(oldpath, newpath string) error
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	deferred int64 // 轮转失败时的文件大小，之后写满 deferred+maxBytes 再重试
+	closed   bool
+
+	millCh    chan struct{}
+	millDone  chan struct{}
+	closeOnce sync.Once
+}
+
+// newRotatingFile 打开（或创建）path 并启动后台清理
+func newRotatingFile(path string, cfg *RotationConfig) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:     path,
+		maxBytes: int64(cfg.MaxSizeMB) * megabyte,
+		cfg:      *cfg,
+		rename:   os.Rename,
+		millCh:   make(chan struct{}, 1),
+		millDone: make(chan struct{}),
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	go r.runMill()
+	// 清理上次运行遗留的过期备份
+	r.triggerMill()
+	return r, nil
+}
+
+// Write 写入一条日志，写入后超过 maxBytes 前先轮转
+//
+// 单条日志超过 maxBytes 时写入新文件，不截断。
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.deferred+r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close 关闭当前文件，等待后台清理结束，可重复调用
+func (r *rotatingFile) Close() error {
+	var err error
+	r.closeOnce.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		err = r.file.Close()
+		close(r.millCh)
+		r.mu.Unlock()
+		<-r.millDone
+	})
+	return err
+}
+
+// open 以追加方式打开当前文件并记录已有大小
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o666)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+// rotate 将当前文件重命名为备份并打开新文件，调用方需持有 mu
+//
+// 重命名失败时以追加方式重新打开原文件继续写入，并输出一行提示到 stderr；
+// 只有原文件也无法打开时才返回错误。
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("close log file for rotation: %w", err)
+	}
+	if err := r.rename(r.path, r.backupName(time.Now())); err != nil {
+		if openErr := r.open(); openErr != nil {
+			return fmt.Errorf("rename log file for rotation: %w; reopen log file: %w", err, openErr)
+		}
+		r.deferred = r.size
+		fmt.Fprintf(os.Stderr, "clog: rotate %s failed, continue writing to it: %v\n", r.path, err)
+		return nil
+	}
+	if err := r.open(); err != nil {
+		return fmt.Errorf("open log file after rotation: %w", err)
+	}
+	r.deferred = 0
+	r.triggerMill()
+	return nil
+}
+
+// backupName 返回 t 对应的备份文件名，同一毫秒内多次轮转时顺延时间戳避免覆盖已有备份
+func (r *rotatingFile) backupName(t time.Time) string {
+	dir, prefix, ext := r.nameParts()
+	for {
+		name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+		if !exists(name) && !exists(name+compressSuffix) {
+			return name
+		}
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// nameParts 将 /var/log/app.log 拆为 /var/log、"app-" 与 ".log"
+func (r *rotatingFile) nameParts() (dir, prefix, ext string) {
+	dir = filepath.Dir(r.path)
+	base := filepath.Base(r.path)
+	ext = filepath.Ext(base)
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// triggerMill 通知后台执行一次清理，已有待执行的清理时直接返回
+func (r *rotatingFile) triggerMill() {
+	select {
+	case r.millCh <- struct{}{}:
+	default:
+	}
+}
+
+func (r *rotatingFile) runMill() {
+	defer close(r.millDone)
+	for range r.millCh {
+		r.mill()
+	}
+}
+
+// backupFile 一个备份文件及其时间戳
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+// mill 按 MaxBackups 与 MaxAgeDays 删除多余的备份，并压缩未压缩的备份
+//
+// 清理失败不影响日志写入，留待下次轮转重试。
+func (r *rotatingFile) mill() {
+	backups, err := r.listBackups()
+	if err != nil {
+		return
+	}
+
+	var remove []backupFile
+	if r.cfg.MaxBackups > 0 && len(backups) > r.cfg.MaxBackups {
+		remove = append(remove, backups[r.cfg.MaxBackups:]...)
+		backups = backups[:r.cfg.MaxBackups]
+	}
+	if r.cfg.MaxAgeDays > 0 {
+		cutoff := time.Now().Add(-time.Duration(r.cfg.MaxAgeDays) * 24 * time.Hour)
+		kept := backups[:0]
+		for _, b := range backups {
+			if b.time.Before(cutoff) {
+				remove = append(remove, b)
+			} else {
+				kept = append(kept, b)
+			}
+		}
+		backups = kept
+	}
+
+	for _, b := range remove {
+		_ = os.Remove(b.path)
+	}
+	if r.cfg.Compress {
+		for _, b := range backups {
+			if !strings.HasSuffix(b.path, compressSuffix) {
+				_ = compressFile(b.path)
+			}
+		}
+	}
+}
+
+// listBackups 列出当前文件的所有备份，按时间戳从新到旧排序
+func (r *rotatingFile) listBackups() ([]backupFile, error) {
+	dir, prefix, ext := r.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []backupFile
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), compressSuffix)
+		if !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		t, err := time.ParseInLocation(backupTimeFormat, ts, time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupFile{path: filepath.Join(dir, e.Name()), time: t})
+	}
+	slices.SortFunc(backups, func(a, b backupFile) int { return b.time.Compare(a.time) })
+	return backups, nil
+}
+
+// compressFile 将 path 压缩为 path.gz，成功后删除原文件
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o666)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(path + compressSuffix)
+		}
+	}()
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// exists 判断文件是否存在
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,124 @@
+package clog
+
+import (
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// samplingWindow 采样计数窗口，每个窗口开始时计数清零
+	samplingWindow = time.Second
+	// samplerSlots 采样计数槽个数，不同日志按哈希分散到各槽
+	samplerSlots = 4096
+)
+
+// SamplingConfig 日志采样配置，用于防止同一条日志在短时间内刷屏
+//
+// 以 level + message 作为采样键，每秒窗口内同一个键的前 Initial 条全部输出，
+// 之后每 Thereafter 条输出 1 条。字段不参与采样键，同一条消息携带不同字段仍按同一个键计数。
+type SamplingConfig struct {
+	Initial      int  `json:"initial" yaml:"initial"`           // 每秒内每个键全部输出的条数
+	Thereafter   int  `json:"thereafter" yaml:"thereafter"`     // 超过 Initial 后每 N 条输出 1 条，0 表示窗口内不再输出
+	ExemptErrors bool `json:"exemptErrors" yaml:"exemptErrors"` // Error 与 Fatal 级别不参与采样，全部输出
+}
+
+// validate 检查取值范围（内部使用）
+func (c *SamplingConfig) validate() error {
+	if c.Initial < 0 || c.Thereafter < 0 {
+		return fmt.Errorf("invalid sampling config: initial %d and thereafter %d must not be negative", c.Initial, c.Thereafter)
+	}
+	if c.Initial == 0 && c.Thereafter == 0 {
+		return fmt.Errorf("invalid sampling config: initial and thereafter must not both be zero")
+	}
+	return nil
+}
+
+// SamplingDroppedCount 返回因 Config.Sampling 采样而丢弃的日志条数
+//
+// 未开启采样的 Logger 始终返回 0。计数在 With / WithNamespace 派生的 logger 之间共享。
+func SamplingDroppedCount(l Logger) uint64 {
+	impl, ok := l.(*loggerImpl)
+	if !ok || impl.options.sampler == nil {
+		return 0
+	}
+	return impl.options.sampler.dropped.Load()
+}
+
+// sampler 基于固定槽位计数器的采样器，无锁并发安全
+//
+// 采样键按哈希映射到 samplerSlots 个计数槽，哈希冲突的键共享计数；
+// 每个槽记录当前窗口的结束时间，首个超过该时间的写入者负责清零并开启新窗口。
+type sampler struct {
+	initial      uint64
+	thereafter   uint64
+	exemptErrors bool
+	window       time.Duration
+	slots        [samplerSlots]samplerSlot
+	dropped      atomic.Uint64
+}
+
+// samplerSlot 一个采样键在当前窗口内的计数
+type samplerSlot struct {
+	resetAt atomic.Int64 // 当前窗口结束的 UnixNano
+	count   atomic.Uint64
+}
+
+func newSampler(cfg *SamplingConfig) *sampler {
+	return &sampler{
+		initial:      uint64(cfg.Initial),
+		thereafter:   uint64(cfg.Thereafter),
+		exemptErrors: cfg.ExemptErrors,
+		window:       samplingWindow,
+	}
+}
+
+// allow 判断这条日志是否输出，不输出时计入 dropped
+func (s *sampler) allow(level slog.Level, msg string) bool {
+	if s.exemptErrors && level >= slog.LevelError {
+		return true
+	}
+
+	n := s.slots[samplingKey(level, msg)%samplerSlots].inc(time.Now(), s.window)
+	if n <= s.initial {
+		return true
+	}
+	if s.thereafter > 0 && (n-s.initial)%s.thereafter == 0 {
+		return true
+	}
+	s.dropped.Add(1)
+	return false
+}
+
+// inc 计数加一并返回当前窗口内的计数，窗口已结束时开启新窗口
+func (c *samplerSlot) inc(now time.Time, window time.Duration) uint64 {
+	tn := now.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > tn {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, tn+window.Nanoseconds()) {
+		// 另一个 goroutine 同时开启了新窗口并同样把计数置为 1，这里重新计入本条
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+// samplingKey 计算 level + message 的 FNV-1a 哈希，不产生内存分配
+func samplingKey(level slog.Level, msg string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	h ^= uint32(level)
+	h *= prime32
+	for i := 0; i < len(msg); i++ {
+		h ^= uint32(msg[i])
+		h *= prime32
+	}
+	return h
+}
@@ -0,0 +1,59 @@
+package clog
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sampledKey context 中显式采样标志的键
+type sampledKey struct{}
+
+// ContextWithSampled 在 ctx 中显式标记本次请求是否被采样
+//
+// 用于没有 OTel span、但上游已经做出采样决策的场景（如网关透传的采样头）。
+// 显式标志优先于 span 的 sampled 位，只在开启 WithSampledLevel 时生效。
+func ContextWithSampled(ctx context.Context, sampled bool) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, sampledKey{}, sampled)
+}
+
+// samplingDecision 返回 ctx 中的采样决策，ok 为 false 表示 ctx 没有采样信息
+func samplingDecision(ctx context.Context) (sampled, ok bool) {
+	if ctx == nil {
+		return false, false
+	}
+	if v, exists := ctx.Value(sampledKey{}).(bool); exists {
+		return v, true
+	}
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return false, false
+	}
+	return sc.IsSampled(), true
+}
+
+// enabled 判断该级别的日志是否输出
+//
+// 优先级：SetNamespaceLevel 为当前命名空间（或其父级）设置的级别 > 开启 WithSampledLevel 且 ctx
+// 带采样信息时按采样决策选择的级别 > Logger 的级别。
+func (l *loggerImpl) enabled(ctx context.Context, level slog.Level) bool {
+	if r := l.options.levelRegistry; r != nil && !r.empty() {
+		if minLevel, ok := r.lookup(getNamespaceString(l.options)); ok {
+			return level >= minLevel
+		}
+	}
+	if l.options.sampledLevels != nil {
+		if sampled, ok := samplingDecision(ctx); ok {
+			minLevel := l.options.sampledLevels.unsampled
+			if sampled {
+				minLevel = l.options.sampledLevels.sampled
+			}
+			return level >= slogLevel(minLevel)
+		}
+	}
+	return l.handler.Enabled(ctx, level)
+}
@@ -0,0 +1,119 @@
+package clog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// SinkConfig 附加输出配置
+//
+// 附加输出与主输出共享 AddSource、TimeFormat、TimeZone、FieldKeys 等配置，只有输出目标、格式与级别独立。
+// 典型用法是主输出为 stdout 彩色 console，附加一个 JSON 文件供日志采集。
+type SinkConfig struct {
+	Output      string `json:"output" yaml:"output"`           // stdout|stderr|<file path>
+	Format      string `json:"format" yaml:"format"`           // json|console，为空时沿用主输出
+	Level       string `json:"level" yaml:"level"`             // debug|info|warn|error|fatal，为空时沿用主输出的初始级别
+	EnableColor bool   `json:"enableColor" yaml:"enableColor"` // 仅在 console 格式下有效
+}
+
+// toConfig 以主输出配置为模板派生附加输出的完整配置，异步、轮转等只作用于主输出
+func (s SinkConfig) toConfig(base *Config) (*Config, error) {
+	cfg := *base
+	cfg.Output = s.Output
+	cfg.EnableColor = s.EnableColor
+	cfg.Async = nil
+	cfg.Rotation = nil
+	if s.Format != "" {
+		cfg.Format = s.Format
+	}
+	if s.Level != "" {
+		cfg.Level = s.Level
+	}
+
+	if s.Output == "" {
+		return nil, fmt.Errorf("invalid sink config: output must not be empty")
+	}
+	if _, err := ParseLevel(cfg.Level); err != nil {
+		return nil, fmt.Errorf("invalid sink config: %w", err)
+	}
+	if f := strings.ToLower(cfg.Format); f != "json" && f != "console" {
+		return nil, fmt.Errorf("invalid sink config: format %s, must be json or console", cfg.Format)
+	}
+	return &cfg, nil
+}
+
+// newExtraSinks 创建 WithExtraSinks 配置的附加输出 handler，失败时关闭已打开的文件
+func newExtraSinks(config *Config, options *options) ([]slog.Handler, []io.Closer, error) {
+	var (
+		handlers []slog.Handler
+		closers  []io.Closer
+	)
+	fail := func(err error) ([]slog.Handler, []io.Closer, error) {
+		for _, c := range closers {
+			_ = c.Close()
+		}
+		return nil, nil, err
+	}
+
+	for _, s := range options.extraSinks {
+		cfg, err := s.toConfig(config)
+		if err != nil {
+			return fail(err)
+		}
+		w, closer, err := resolveWriter(cfg, options)
+		if err != nil {
+			return fail(fmt.Errorf("open sink %s: %w", s.Output, err))
+		}
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+
+		// 附加输出的级别固定为配置值，不受 SetLevel 影响
+		level := new(slog.LevelVar)
+		level.Set(slogLevelFromConfig(cfg.Level))
+		h, err := newFormatHandler(cfg, w, level)
+		if err != nil {
+			return fail(err)
+		}
+		handlers = append(handlers, h)
+	}
+	return handlers, closers, nil
+}
+
+// Handle 写入主输出，再分发到级别启用的附加输出
+//
+// 调用方已按主输出的级别（含命名空间、采样级别）决定输出。各输出独立渲染，
+// 任一输出写入失败不影响其他输出，错误合并返回。
+func (h *clogHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.Handler.Handle(ctx, r)
+	if len(h.extras) == 0 {
+		return err
+	}
+	return xerrors.Combine(err, h.handleExtras(ctx, r))
+}
+
+// extrasEnabled 判断是否有附加输出启用了该级别
+func (h *clogHandler) extrasEnabled(ctx context.Context, level slog.Level) bool {
+	for _, e := range h.extras {
+		if e.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleExtras 只写入级别启用的附加输出，用于主输出未启用该级别的日志
+func (h *clogHandler) handleExtras(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, e := range h.extras {
+		if e.Enabled(ctx, r.Level) {
+			errs = append(errs, e.Handle(ctx, r))
+		}
+	}
+	return xerrors.Combine(errs...)
+}
@@ -0,0 +1,106 @@
+package clog
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+)
+
+const (
+	panicKey      = "panic"
+	panicValueKey = "value"
+	panicTypeKey  = "type"
+	panicStackKey = "stack"
+
+	// maxStackDepth 捕获的最大栈帧数
+	maxStackDepth = 64
+)
+
+// StackFrame 堆栈中的一帧
+//
+// json 格式输出为 {"func":"...","file":"...","line":42}，console 格式输出为 "func (file:line)"。
+type StackFrame struct {
+	Function string `json:"func"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// String 返回 "func (file:line)" 形式的可读文本
+func (f StackFrame) String() string {
+	return f.Function + " (" + f.File + ":" + strconv.Itoa(f.Line) + ")"
+}
+
+// Stack 捕获当前 goroutine 的堆栈，输出为栈帧数组
+//
+// 第一帧为调用 Stack 的函数，runtime.main / runtime.goexit 等入口帧被忽略。
+//
+//	logger.Warn("unexpected state", clog.Stack("stack"))
+func Stack(k string) Field {
+	return slog.Any(k, captureStack(1))
+}
+
+// PanicValue 把 recover() 的返回值与 panic 现场的堆栈结构化为 panic={value, type, stack}
+//
+// 应在 defer 的 recover 中调用，堆栈从触发 panic 的位置开始，不含 recover 所在的 defer 函数；
+// recovered 为 nil 时返回空字段（不记录）。
+//
+//	defer func() {
+//	    if r := recover(); r != nil {
+//	        logger.Error("panic recovered", clog.PanicValue(r))
+//	    }
+//	}()
+func PanicValue(recovered any) Field {
+	if recovered == nil {
+		return slog.Attr{}
+	}
+	frames := captureStack(1)
+	// recover 时调用栈为 defer 函数 -> runtime.gopanic -> panic 位置，去掉 gopanic 及之前的帧
+	for i, f := range frames {
+		if f.Function == "runtime.gopanic" {
+			frames = frames[i+1:]
+			break
+		}
+	}
+
+	value := fmt.Sprint(recovered)
+	if err, ok := recovered.(error); ok {
+		value = err.Error()
+	}
+	return slog.Group(panicKey,
+		slog.String(panicValueKey, value),
+		slog.String(panicTypeKey, fmt.Sprintf("%T", recovered)),
+		slog.Any(panicStackKey, frames),
+	)
+}
+
+// captureStack 捕获调用栈，skip 为 captureStack 的调用方中需要跳过的帧数（内部使用）
+//
+// 如 Stack 传入 1 跳过自身，第一帧即为业务代码。
+func captureStack(skip int) []StackFrame {
+	var pcs [maxStackDepth]uintptr
+	// +2: 跳过 runtime.Callers 与 captureStack
+	n := runtime.Callers(skip+2, pcs[:])
+	if n == 0 {
+		return nil
+	}
+
+	stack := make([]StackFrame, 0, n)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		// 忽略 Go 运行时的入口点，保持堆栈信息清晰
+		if frame.Function == "runtime.main" || frame.Function == "runtime.goexit" {
+			break
+		}
+		stack = append(stack, StackFrame{
+			Function: frame.Function,
+			File:     frame.File,
+			Line:     frame.Line,
+		})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
@@ -2,6 +2,7 @@ package clog
 
 import (
 	"bytes"
+	"io"
 )
 
 // withBuffer 是一个测试专用选项，用于将日志输出写入指定的缓冲区
@@ -20,3 +21,10 @@ func withBuffer(buf *bytes.Buffer) Option {
 		o.buffer = buf
 	}
 }
+
+// withWriter 是一个测试专用选项，用于将日志输出写入任意 writer（如模拟慢速 sink）
+func withWriter(w io.Writer) Option {
+	return func(o *options) {
+		o.buffer = w
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// ValidateAgainstSchema 检查 required 中的 key 是否都已配置
+func (l *loader) ValidateAgainstSchema(required []string) error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if !l.loaded {
+		return xerrors.Wrapf(ErrNotLoaded, "call Load before ValidateAgainstSchema")
+	}
+
+	var missing []string
+	for _, key := range required {
+		if !l.v.IsSet(key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return xerrors.Wrapf(ErrValidationFailed, "missing required keys: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Diff 比较两个环境的配置文件，列出缺失与多余的 key
+func (l *loader) Diff(envA, envB string) (DiffResult, error) {
+	keysA, err := l.fileKeys(envA)
+	if err != nil {
+		return DiffResult{}, err
+	}
+	keysB, err := l.fileKeys(envB)
+	if err != nil {
+		return DiffResult{}, err
+	}
+
+	return DiffResult{
+		EnvA:    envA,
+		EnvB:    envB,
+		Missing: subtractKeys(keysA, keysB),
+		Extra:   subtractKeys(keysB, keysA),
+	}, nil
+}
+
+// fileKeys 返回指定环境合并后的全部叶子 key，不包含 .env 与环境变量
+func (l *loader) fileKeys(env string) (map[string]struct{}, error) {
+	v := viper.New()
+	_, envFile, err := l.loadConfigFiles(v, env)
+	if err != nil {
+		return nil, err
+	}
+	if env != "" && envFile == "" {
+		return nil, xerrors.Wrapf(ErrValidationFailed, "environment config %s.%s not found", l.cfg.Name, env)
+	}
+
+	keys := make(map[string]struct{})
+	for _, key := range v.AllKeys() {
+		keys[key] = struct{}{}
+	}
+	return keys, nil
+}
+
+// subtractKeys 返回在 a 中而不在 b 中的 key，按字典序排列
+func subtractKeys(a, b map[string]struct{}) []string {
+	var out []string
+	for key := range a {
+		if _, ok := b[key]; !ok {
+			out = append(out, key)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
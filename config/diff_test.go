@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderDiff(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(tmpDir, "config.yaml"), `
+app:
+  name: base
+  port: 8080
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "config.dev.yaml"), `
+app:
+  debug: true
+redis:
+  addr: 127.0.0.1:6379
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "config.prod.yaml"), `
+app:
+  port: 80
+metrics:
+  enabled: true
+`)
+
+	loader, err := New(&Config{Name: "config", Paths: []string{tmpDir}, EnvPrefix: "DIFF_TEST"})
+	require.NoError(t, err)
+
+	t.Run("报告缺失与多余的 key", func(t *testing.T) {
+		result, err := loader.Diff("dev", "prod")
+		require.NoError(t, err)
+		require.Equal(t, []string{"app.debug", "redis.addr"}, result.Missing)
+		require.Equal(t, []string{"metrics.enabled"}, result.Extra)
+		require.False(t, result.Empty())
+	})
+
+	t.Run("基础配置的 key 在各环境中都存在", func(t *testing.T) {
+		result, err := loader.Diff("", "prod")
+		require.NoError(t, err)
+		require.Empty(t, result.Missing)
+		require.Equal(t, []string{"metrics.enabled"}, result.Extra)
+	})
+
+	t.Run("相同环境无差异", func(t *testing.T) {
+		result, err := loader.Diff("dev", "dev")
+		require.NoError(t, err)
+		require.True(t, result.Empty())
+	})
+
+	t.Run("环境配置不存在", func(t *testing.T) {
+		_, err := loader.Diff("dev", "staging")
+		require.ErrorIs(t, err, ErrValidationFailed)
+	})
+}
+
+func TestLoaderValidateAgainstSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(tmpDir, "config.yaml"), `
+app:
+  name: demo
+mysql:
+  host: 127.0.0.1
+`)
+
+	loader, err := New(&Config{Name: "config", Paths: []string{tmpDir}, EnvPrefix: "SCHEMA_TEST"})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, loader.ValidateAgainstSchema([]string{"app.name"}), ErrNotLoaded)
+
+	t.Setenv("SCHEMA_TEST_MYSQL_PASSWORD", "secret")
+	require.NoError(t, loader.Load(context.Background()))
+
+	require.NoError(t, loader.ValidateAgainstSchema([]string{"app.name", "mysql.host", "mysql.password"}),
+		"环境变量提供的值同样视为已配置")
+
+	err = loader.ValidateAgainstSchema([]string{"app.name", "redis.addr", "mysql.port"})
+	require.ErrorIs(t, err, ErrValidationFailed)
+	require.Contains(t, err.Error(), "redis.addr, mysql.port")
+}
@@ -15,9 +15,16 @@
 //   - 环境特定配置文件，例如 config.dev.yaml
 //   - 基础配置文件，例如 config.yaml
 //
+// 基础配置之后还可以通过 WithConfigFiles 按顺序合并额外文件；基础配置、额外文件与
+// 环境特定配置中的 include: [other.yaml] 指令会递归加载被引用的文件，当前文件的值
+// 优先于被 include 的文件，include 指令本身不会出现在最终配置中。
+//
 // 其中 .env 的语义是“补齐缺失项”：只有当前进程中不存在同名环境变量时，才会从
 // .env 注入值。这比“无条件覆盖环境变量”更符合常见实践，也更容易解释部署时的最终结果。
 //
+// 排查"某个值从哪来"时，DebugSource 返回单个 key 的最终值与来源，Dump 返回全部
+// 文件 key 的值、来源类型与具体位置（环境变量名或文件路径）。
+//
 // 热更新当前只覆盖配置文件本身：
 //
 //   - Load 负责加载配置，不会自动启动 watcher
@@ -97,6 +104,43 @@ type Loader interface {
 
 	// Validate 验证当前配置的有效性
 	Validate() error
+
+	// ValidateAgainstSchema 检查 required 中的 key 是否都已配置
+	//
+	// 配置文件、.env 与环境变量中任一来源提供了值即视为已配置；
+	// 有缺失时返回 ErrValidationFailed，错误信息列出全部缺失的 key。调用前必须先成功 Load。
+	ValidateAgainstSchema(required []string) error
+
+	// Diff 比较两个环境的配置文件，列出缺失与多余的 key
+	//
+	// 每个环境的 key 集合为基础配置（含 include 与 WithConfigFiles）合并 config.{env}
+	// 文件后的全部叶子 key，env 为空表示只有基础配置。只读取配置文件，不受 .env 与
+	// 环境变量影响，也不要求先调用 Load，适合上线前核对。
+	Diff(envA, envB string) (DiffResult, error)
+
+	// DebugSource 返回 key 的最终值及其来源类型（env / dotenv / file）
+	//
+	// 同一个 key 被多个来源设置时报告优先级最高的来源；未配置或非叶子 key 的来源为空字符串。
+	// 需要具体的文件路径或环境变量名时使用 Dump。调用前必须先成功 Load。
+	DebugSource(key string) (value any, source string)
+
+	// Dump 返回配置文件中全部叶子 key 的最终值与来源，便于启动时打印排查
+	//
+	// 只出现在环境变量中、配置文件里没有的 key 无法可靠地反推，不包含在结果中。
+	Dump() map[string]SourceInfo
+}
+
+// DiffResult 两个环境配置的差异
+type DiffResult struct {
+	EnvA    string
+	EnvB    string
+	Missing []string // envA 有而 envB 缺失的 key，已排序
+	Extra   []string // envB 有而 envA 没有的 key，已排序
+}
+
+// Empty 两个环境的 key 集合完全一致时返回 true
+func (r DiffResult) Empty() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0
 }
 
 // Event 配置变更事件
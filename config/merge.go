@@ -0,0 +1,181 @@
+package config
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/viper"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// includeKey 配置文件中声明 include 指令的 key
+const includeKey = "include"
+
+// SliceMergeMode 定义多文件合并时切片的处理方式。
+type SliceMergeMode string
+
+const (
+	// SliceMergeReplace 后加载的文件整体替换同名切片（默认）。
+	SliceMergeReplace SliceMergeMode = "replace"
+	// SliceMergeAppend 后加载的文件追加到同名切片末尾。
+	SliceMergeAppend SliceMergeMode = "append"
+)
+
+// fileSet 记录一次加载涉及的配置文件，以及每个叶子 key 最终来自哪个文件。
+type fileSet struct {
+	files   []string          // 额外文件与 include 文件，按加载顺序
+	sources map[string]string // 叶子 key → 提供最终值的文件
+}
+
+func newFileSet() *fileSet {
+	return &fileSet{sources: make(map[string]string)}
+}
+
+// record 按加载顺序记录 file 提供的叶子 key，后记录的文件覆盖先前的来源
+func (s *fileSet) record(file string, keys []string) {
+	for _, key := range keys {
+		if key != includeKey {
+			s.sources[key] = file
+		}
+	}
+}
+
+// loadFileTree 读取单个配置文件，并递归展开其中的 include 指令。
+//
+// include 中的相对路径相对于声明它的文件所在目录解析。被 include 的文件按声明顺序
+// 先合并，声明 include 的文件本身最后合并，因此当前文件的值优先。visiting 用于检测
+// 循环 include，loaded 记录本次展开涉及的全部文件路径与 key 来源。
+func (l *loader) loadFileTree(path string, visiting map[string]struct{}, loaded *fileSet) (map[string]any, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "failed to resolve config file %s", path)
+	}
+	if _, ok := visiting[abs]; ok {
+		return nil, xerrors.New("config include cycle detected at " + abs)
+	}
+	visiting[abs] = struct{}{}
+	defer delete(visiting, abs)
+
+	fv := viper.New()
+	fv.SetConfigFile(abs)
+	if err := fv.ReadInConfig(); err != nil {
+		return nil, xerrors.Wrapf(err, "failed to read config file %s", abs)
+	}
+	loaded.files = append(loaded.files, abs)
+
+	merged := map[string]any{}
+	for _, include := range fv.GetStringSlice(includeKey) {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(abs), include)
+		}
+		included, err := l.loadFileTree(include, visiting, loaded)
+		if err != nil {
+			return nil, err
+		}
+		merged = mergeSettings(merged, included, l.sliceMerge)
+	}
+
+	loaded.record(abs, fv.AllKeys())
+	self := fv.AllSettings()
+	delete(self, includeKey)
+	return mergeSettings(merged, self, l.sliceMerge), nil
+}
+
+// findConfigFile 在 Paths 中查找名为 name 的配置文件，不存在时返回空字符串
+func (l *loader) findConfigFile(name string) (string, error) {
+	fv := viper.New()
+	fv.SetConfigName(name)
+	fv.SetConfigType(l.cfg.FileType)
+	for _, path := range l.cfg.Paths {
+		fv.AddConfigPath(path)
+	}
+	if err := fv.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			return "", nil
+		}
+		return "", xerrors.Wrapf(err, "failed to read config file %s", name)
+	}
+	return fv.ConfigFileUsed(), nil
+}
+
+// loadConfigFiles 按顺序把基础配置、WithConfigFiles 指定的额外文件与环境配置 config.{env}
+// 深度合并进 v，返回本次加载涉及的文件列表、key 来源以及环境配置文件路径（不存在时为空）。
+//
+// 每个文件都经 loadFileTree 展开 include，v 中只保存展开后的结果，include 指令本身
+// 不会出现在最终配置里。env 为空时不加载环境配置。
+func (l *loader) loadConfigFiles(v *viper.Viper, env string) (*fileSet, string, error) {
+	loaded := newFileSet()
+
+	baseFile, err := l.findConfigFile(l.cfg.Name)
+	if err != nil {
+		return nil, "", err
+	}
+	if baseFile != "" {
+		tree, err := l.loadFileTree(baseFile, map[string]struct{}{}, loaded)
+		if err != nil {
+			return nil, "", err
+		}
+		// 基础文件按 Name/Paths 监听，不计入额外监听列表
+		loaded.files = loaded.files[1:]
+		if err := v.MergeConfigMap(tree); err != nil {
+			return nil, "", xerrors.Wrapf(err, "failed to merge config file %s", baseFile)
+		}
+	}
+
+	for _, file := range l.configFiles {
+		tree, err := l.loadFileTree(file, map[string]struct{}{}, loaded)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := v.MergeConfigMap(mergeSettings(v.AllSettings(), tree, l.sliceMerge)); err != nil {
+			return nil, "", xerrors.Wrapf(err, "failed to merge config file %s", file)
+		}
+	}
+
+	if env == "" {
+		return loaded, "", nil
+	}
+	envFile, err := l.findConfigFile(l.cfg.Name + "." + env)
+	if err != nil || envFile == "" {
+		return loaded, "", err
+	}
+	tree, err := l.loadFileTree(envFile, map[string]struct{}{}, loaded)
+	if err != nil {
+		return nil, "", err
+	}
+	// 环境配置与此前一致：map 深度合并，切片整体替换
+	if err := v.MergeConfigMap(tree); err != nil {
+		return nil, "", xerrors.Wrapf(err, "failed to merge environment config %s", envFile)
+	}
+	return loaded, envFile, nil
+}
+
+// mergeSettings 将 src 深度合并到 dst 的副本上：map 递归合并，切片按 mode 替换或追加，
+// 其他类型由 src 覆盖。
+func mergeSettings(dst, src map[string]any, mode SliceMergeMode) map[string]any {
+	out := make(map[string]any, len(dst)+len(src))
+	for k, v := range dst {
+		out[k] = v
+	}
+	for k, sv := range src {
+		dv, exists := out[k]
+		if !exists {
+			out[k] = sv
+			continue
+		}
+		switch s := sv.(type) {
+		case map[string]any:
+			if d, ok := dv.(map[string]any); ok {
+				out[k] = mergeSettings(d, s, mode)
+				continue
+			}
+		case []any:
+			if d, ok := dv.([]any); ok && mode == SliceMergeAppend {
+				out[k] = append(append([]any(nil), d...), s...)
+				continue
+			}
+		}
+		out[k] = sv
+	}
+	return out
+}
@@ -0,0 +1,191 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestLoaderWithConfigFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(tmpDir, "config.yaml"), `
+app:
+  name: base
+  server:
+    host: 127.0.0.1
+    port: 8080
+  tags: [a]
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "secrets.yaml"), `
+app:
+  server:
+    port: 9090
+  password: secret
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "features.json"), `{"app": {"name": "features", "tags": ["b"]}}`)
+
+	loader, err := New(&Config{Name: "config", Paths: []string{tmpDir}, EnvPrefix: "MERGE_TEST"},
+		WithConfigFiles([]string{
+			filepath.Join(tmpDir, "secrets.yaml"),
+			filepath.Join(tmpDir, "features.json"),
+		}))
+	require.NoError(t, err)
+	require.NoError(t, loader.Load(context.Background()))
+
+	// 后加载的文件覆盖前者
+	require.Equal(t, "features", loader.Get("app.name"))
+	// 嵌套 map 深合并
+	require.Equal(t, "127.0.0.1", loader.Get("app.server.host"))
+	require.Equal(t, 9090, loader.Get("app.server.port"))
+	require.Equal(t, "secret", loader.Get("app.password"))
+	// 默认切片替换
+	require.Equal(t, []any{"b"}, loader.Get("app.tags"))
+}
+
+func TestLoaderWithConfigFilesSliceAppend(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(tmpDir, "config.yaml"), "app:\n  tags: [a]\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "extra.yaml"), "app:\n  tags: [b, c]\n")
+
+	loader, err := New(&Config{Name: "config", Paths: []string{tmpDir}, EnvPrefix: "MERGE_TEST"},
+		WithConfigFiles([]string{filepath.Join(tmpDir, "extra.yaml")}),
+		WithSliceMerge(SliceMergeAppend))
+	require.NoError(t, err)
+	require.NoError(t, loader.Load(context.Background()))
+
+	require.Equal(t, []any{"a", "b", "c"}, loader.Get("app.tags"))
+}
+
+func TestLoaderInclude(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(tmpDir, "config.yaml"), `
+include: [conf.d/db.yaml]
+app:
+  name: base
+mysql:
+  port: 3307
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "conf.d", "db.yaml"), `
+include: [redis.yaml]
+mysql:
+  host: db.local
+  port: 3306
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "conf.d", "redis.yaml"), `
+redis:
+  addr: redis.local:6379
+`)
+
+	loader, err := New(&Config{Name: "config", Paths: []string{tmpDir}, EnvPrefix: "MERGE_TEST"})
+	require.NoError(t, err)
+	require.NoError(t, loader.Load(context.Background()))
+
+	require.Equal(t, "base", loader.Get("app.name"))
+	require.Equal(t, "db.local", loader.Get("mysql.host"))
+	// 声明 include 的文件优先于被 include 的文件
+	require.Equal(t, 3307, loader.Get("mysql.port"))
+	// 递归 include
+	require.Equal(t, "redis.local:6379", loader.Get("redis.addr"))
+}
+
+func TestLoaderIncludeCycle(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(tmpDir, "config.yaml"), "include: [a.yaml]\napp:\n  name: base\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "a.yaml"), "include: [config.yaml]\n")
+
+	loader, err := New(&Config{Name: "config", Paths: []string{tmpDir}, EnvPrefix: "MERGE_TEST"})
+	require.NoError(t, err)
+	require.Error(t, loader.Load(context.Background()))
+}
+
+func TestLoaderWithConfigFilesMissing(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeConfigFile(t, filepath.Join(tmpDir, "config.yaml"), "app:\n  name: base\n")
+
+	loader, err := New(&Config{Name: "config", Paths: []string{tmpDir}, EnvPrefix: "MERGE_TEST"},
+		WithConfigFiles([]string{filepath.Join(tmpDir, "missing.yaml")}))
+	require.NoError(t, err)
+	require.Error(t, loader.Load(context.Background()))
+}
+
+func TestLoaderIncludeInEnvironmentConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("MERGE_ENV_TEST_ENV", "dev")
+
+	writeConfigFile(t, filepath.Join(tmpDir, "config.yaml"), "app:\n  name: base\nmysql:\n  host: db.prod\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "config.dev.yaml"), `
+include: [dev/db.yaml]
+mysql:
+  port: 3307
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "dev", "db.yaml"), `
+mysql:
+  host: db.dev
+  port: 3306
+`)
+
+	loader, err := New(&Config{Name: "config", Paths: []string{tmpDir}, EnvPrefix: "MERGE_ENV_TEST"})
+	require.NoError(t, err)
+	require.NoError(t, loader.Load(context.Background()))
+
+	// 环境配置中的 include 同样展开，且环境配置本身优先
+	require.Equal(t, "base", loader.Get("app.name"))
+	require.Equal(t, "db.dev", loader.Get("mysql.host"))
+	require.Equal(t, 3307, loader.Get("mysql.port"))
+	require.Nil(t, loader.Get(includeKey))
+
+	value, source := loader.DebugSource("mysql.host")
+	require.Equal(t, "db.dev", value)
+	require.Equal(t, string(SourceFile), source)
+
+	diff, err := loader.Diff("dev", "dev")
+	require.NoError(t, err)
+	require.Empty(t, diff.Missing)
+	require.Empty(t, diff.Extra)
+}
+
+func TestLoaderIncludeKeyStripped(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(tmpDir, "config.yaml"), "include: [a.yaml]\napp:\n  name: base\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "a.yaml"), "app:\n  port: 8080\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "extra.yaml"), "include: [b.yaml]\napp:\n  debug: true\n")
+	writeConfigFile(t, filepath.Join(tmpDir, "b.yaml"), "app:\n  region: cn\n")
+
+	loader, err := New(&Config{Name: "config", Paths: []string{tmpDir}, EnvPrefix: "MERGE_TEST"},
+		WithConfigFiles([]string{filepath.Join(tmpDir, "extra.yaml")}))
+	require.NoError(t, err)
+	require.NoError(t, loader.Load(context.Background()))
+
+	require.Nil(t, loader.Get(includeKey))
+	require.NotContains(t, loader.Dump(), includeKey)
+
+	var cfg struct {
+		Include []string `mapstructure:"include"`
+		App     struct {
+			Name   string `mapstructure:"name"`
+			Port   int    `mapstructure:"port"`
+			Debug  bool   `mapstructure:"debug"`
+			Region string `mapstructure:"region"`
+		} `mapstructure:"app"`
+	}
+	require.NoError(t, loader.Unmarshal(&cfg))
+	require.Empty(t, cfg.Include)
+	require.Equal(t, "base", cfg.App.Name)
+	require.Equal(t, 8080, cfg.App.Port)
+	require.True(t, cfg.App.Debug)
+	require.Equal(t, "cn", cfg.App.Region)
+}
@@ -16,3 +16,26 @@ func WithLogger(logger clog.Logger) Option {
 		}
 	}
 }
+
+// WithConfigFiles 指定在基础配置之后按顺序加载的额外配置文件。
+//
+// 适合把大型配置拆成 config.yaml + secrets.yaml + features.yaml 等多个文件。
+// 额外文件按顺序深度合并：map 递归合并，后加载的文件覆盖前者的同名标量；
+// 环境特定配置（config.{env}.yaml）和环境变量仍然拥有更高优先级。
+// 文件格式由扩展名决定；指定的文件不存在时 Load 返回错误。
+// 额外文件及其 include 的文件在 Watch 时同样会被监听。
+func WithConfigFiles(files []string) Option {
+	return func(l *loader) {
+		l.configFiles = append(l.configFiles, files...)
+	}
+}
+
+// WithSliceMerge 设置多文件合并（WithConfigFiles 与 include）时切片的处理方式。
+// 默认 SliceMergeReplace，即后加载的切片整体替换先前的值。
+func WithSliceMerge(mode SliceMergeMode) Option {
+	return func(l *loader) {
+		if mode == SliceMergeReplace || mode == SliceMergeAppend {
+			l.sliceMerge = mode
+		}
+	}
+}
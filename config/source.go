@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Source 表示配置值的来源类型。
+type Source string
+
+const (
+	// SourceEnv 值来自进程环境变量。
+	SourceEnv Source = "env"
+	// SourceDotEnv 值来自 .env 文件注入的环境变量。
+	SourceDotEnv Source = "dotenv"
+	// SourceFile 值来自配置文件（基础配置、include、WithConfigFiles 或环境特定配置）。
+	SourceFile Source = "file"
+)
+
+// SourceInfo 描述一个配置 key 的最终值及其来源。
+type SourceInfo struct {
+	Value    any    // 最终生效的值
+	Source   Source // 来源类型，未配置时为空
+	Location string // 来源位置：环境变量名、.env 文件路径或配置文件路径
+}
+
+// String 返回形如 "file(/etc/app/config.yaml)" 的可读描述
+func (s SourceInfo) String() string {
+	if s.Source == "" {
+		return "unset"
+	}
+	return fmt.Sprintf("%s(%s)", s.Source, s.Location)
+}
+
+// DebugSource 返回 key 的最终值及其来源类型
+func (l *loader) DebugSource(key string) (any, string) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	info := l.sourceInfo(strings.ToLower(key))
+	return info.Value, string(info.Source)
+}
+
+// Dump 返回配置文件中全部叶子 key 的最终值与来源
+func (l *loader) Dump() map[string]SourceInfo {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	keys := l.v.AllKeys()
+	out := make(map[string]SourceInfo, len(keys))
+	for _, key := range keys {
+		out[key] = l.sourceInfo(key)
+	}
+	return out
+}
+
+// sourceInfo 按优先级从高到低判断 key 的来源（调用方持有读锁）
+//
+// 环境变量按 Viper 的映射规则（前缀 + 大写 + "." / "-" 替换为 "_"）查找，
+// 空值与 Viper 一样视为未设置；.env 注入的变量报告为 SourceDotEnv。
+func (l *loader) sourceInfo(key string) SourceInfo {
+	info := SourceInfo{Value: l.v.Get(key)}
+
+	envName := l.envVarName(key)
+	if value, ok := os.LookupEnv(envName); ok && value != "" {
+		if path, ok := l.dotenvVars[envName]; ok {
+			info.Source, info.Location = SourceDotEnv, path
+		} else {
+			info.Source, info.Location = SourceEnv, envName
+		}
+		return info
+	}
+
+	if file, ok := l.keySources[key]; ok {
+		info.Source, info.Location = SourceFile, file
+	}
+	return info
+}
+
+// envVarName 返回 key 对应的环境变量名，与 newConfiguredViper 的映射规则一致
+func (l *loader) envVarName(key string) string {
+	replacer := strings.NewReplacer(".", "_", "-", "_")
+	return l.cfg.EnvPrefix + "_" + strings.ToUpper(replacer.Replace(key))
+}
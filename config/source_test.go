@@ -0,0 +1,92 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoaderDebugSource(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	writeConfigFile(t, filepath.Join(tmpDir, "config.yaml"), `
+include: [common.yaml]
+app:
+  name: base
+  port: 8080
+db:
+  host: localhost
+  user: root
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "common.yaml"), `
+app:
+  name: common
+  timezone: UTC
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "secrets.yaml"), `
+db:
+  password: s3cret
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, "config.dev.yaml"), `
+app:
+  port: 9090
+`)
+	writeConfigFile(t, filepath.Join(tmpDir, ".env"), "SRC_TEST_DB_USER=dotenv-user\n")
+
+	t.Setenv("SRC_TEST_ENV", "dev")
+	t.Setenv("SRC_TEST_DB_HOST", "db.internal")
+	// 由 .env 注入，测试结束后恢复为未设置
+	t.Setenv("SRC_TEST_DB_USER", "")
+	require.NoError(t, os.Unsetenv("SRC_TEST_DB_USER"))
+
+	loader, err := New(&Config{Name: "config", Paths: []string{tmpDir}, EnvPrefix: "SRC_TEST"},
+		WithConfigFiles([]string{filepath.Join(tmpDir, "secrets.yaml")}))
+	require.NoError(t, err)
+	require.NoError(t, loader.Load(context.Background()))
+
+	base := filepath.Join(tmpDir, "config.yaml")
+	tests := []struct {
+		key      string
+		value    any
+		source   Source
+		location string
+	}{
+		{key: "app.name", value: "base", source: SourceFile, location: base},
+		{key: "app.timezone", value: "UTC", source: SourceFile, location: filepath.Join(tmpDir, "common.yaml")},
+		{key: "app.port", value: 9090, source: SourceFile, location: filepath.Join(tmpDir, "config.dev.yaml")},
+		{key: "db.password", value: "s3cret", source: SourceFile, location: filepath.Join(tmpDir, "secrets.yaml")},
+		{key: "db.host", value: "db.internal", source: SourceEnv, location: "SRC_TEST_DB_HOST"},
+		{key: "db.user", value: "dotenv-user", source: SourceDotEnv, location: filepath.Join(tmpDir, ".env")},
+	}
+
+	dump := loader.Dump()
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			value, source := loader.DebugSource(tt.key)
+			require.EqualValues(t, tt.value, value)
+			require.Equal(t, string(tt.source), source)
+
+			info, ok := dump[tt.key]
+			require.True(t, ok, "Dump 缺少 %s", tt.key)
+			require.EqualValues(t, tt.value, info.Value)
+			require.Equal(t, tt.source, info.Source)
+			require.Equal(t, tt.location, info.Location)
+		})
+	}
+
+	t.Run("未配置的 key", func(t *testing.T) {
+		value, source := loader.DebugSource("app.missing")
+		require.Nil(t, value)
+		require.Empty(t, source)
+		require.Equal(t, "unset", SourceInfo{}.String())
+	})
+
+	t.Run("Dump 不包含 include 指令", func(t *testing.T) {
+		_, ok := dump[includeKey]
+		require.False(t, ok)
+		require.Equal(t, "file("+base+")", dump["app.name"].String())
+	})
+}
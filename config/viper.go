@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -32,6 +33,12 @@ type loader struct {
 	watches   map[string][]chan Event
 	oldValues map[string]any
 
+	configFiles []string          // WithConfigFiles 指定的额外配置文件
+	sliceMerge  SliceMergeMode    // 多文件合并时的切片策略
+	sourceFiles []string          // 最近一次加载涉及的额外文件（含 include），用于监听
+	keySources  map[string]string // 最近一次加载中每个叶子 key 的来源文件
+	dotenvVars  map[string]string // 由 .env 注入的环境变量 → .env 文件路径
+
 	watchOnce sync.Once
 	watchErr  error
 }
@@ -39,11 +46,13 @@ type loader struct {
 // newLoader 创建一个新的配置加载器（内部使用）
 func newLoader(cfg *Config, opts ...Option) (Loader, error) {
 	l := &loader{
-		v:         viper.New(),
-		cfg:       cfg,
-		logger:    clog.Discard(),
-		watches:   make(map[string][]chan Event),
-		oldValues: make(map[string]any),
+		v:          viper.New(),
+		cfg:        cfg,
+		logger:     clog.Discard(),
+		watches:    make(map[string][]chan Event),
+		oldValues:  make(map[string]any),
+		sliceMerge: SliceMergeReplace,
+		dotenvVars: make(map[string]string),
 	}
 	for _, opt := range opts {
 		if opt != nil {
@@ -55,13 +64,6 @@ func newLoader(cfg *Config, opts ...Option) (Loader, error) {
 
 func (l *loader) newConfiguredViper() *viper.Viper {
 	v := viper.New()
-	v.SetConfigName(l.cfg.Name)
-	v.SetConfigType(l.cfg.FileType)
-
-	for _, path := range l.cfg.Paths {
-		v.AddConfigPath(path)
-	}
-
 	v.SetEnvPrefix(l.cfg.EnvPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
 	v.AutomaticEnv()
@@ -80,15 +82,12 @@ func (l *loader) Load(ctx context.Context) error {
 		return err
 	}
 
-	if err := l.v.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return xerrors.Wrapf(err, "failed to read config file %s", l.cfg.Name)
-		}
-	}
-
-	if err := l.loadEnvironmentConfig(l.v); err != nil {
+	loaded, _, err := l.loadConfigFiles(l.v, l.currentEnv())
+	if err != nil {
 		return err
 	}
+	l.sourceFiles = loaded.files
+	l.keySources = loaded.sources
 
 	if err := l.validateViper(l.v); err != nil {
 		return err
@@ -129,31 +128,16 @@ func (l *loader) loadDotEnv() error {
 			if err := os.Setenv(key, value); err != nil {
 				return xerrors.Wrapf(err, "failed to set env from .env file %s", envPath)
 			}
+			l.dotenvVars[key] = envPath
 		}
 	}
 
 	return nil
 }
 
-// loadEnvironmentConfig 加载环境特定配置文件
-func (l *loader) loadEnvironmentConfig(v *viper.Viper) error {
-	env := os.Getenv(fmt.Sprintf("%s_ENV", l.cfg.EnvPrefix))
-	if env == "" {
-		return nil
-	}
-
-	originalName := l.cfg.Name
-	envConfigName := fmt.Sprintf("%s.%s", l.cfg.Name, env)
-	v.SetConfigName(envConfigName)
-
-	if err := v.MergeInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return xerrors.Wrapf(err, "failed to merge environment config %s", envConfigName)
-		}
-	}
-
-	v.SetConfigName(originalName)
-	return nil
+// currentEnv 返回 {EnvPrefix}_ENV 指定的环境名
+func (l *loader) currentEnv() string {
+	return os.Getenv(fmt.Sprintf("%s_ENV", l.cfg.EnvPrefix))
 }
 
 // captureCurrentValues 保存当前配置值用于变更检测
@@ -286,6 +270,17 @@ func (l *loader) startFileWatch() error {
 		watchDirs = append(watchDirs, abs)
 	}
 
+	// 额外配置文件与 include 文件所在目录也需要监听
+	l.mu.RLock()
+	sourceFiles := append([]string(nil), l.sourceFiles...)
+	l.mu.RUnlock()
+	for _, file := range sourceFiles {
+		dir := filepath.Dir(file)
+		if !slices.Contains(watchDirs, dir) {
+			watchDirs = append(watchDirs, dir)
+		}
+	}
+
 	if len(watchDirs) == 0 {
 		return nil
 	}
@@ -305,12 +300,16 @@ func (l *loader) startFileWatch() error {
 		watchFiles[filepath.Clean(filepath.Join(dir, l.cfg.Name+"."+l.cfg.FileType))] = struct{}{}
 
 		// 环境特定配置文件：config.{env}.yaml
-		if env := os.Getenv(fmt.Sprintf("%s_ENV", l.cfg.EnvPrefix)); env != "" {
+		if env := l.currentEnv(); env != "" {
 			envConfigName := fmt.Sprintf("%s.%s.%s", l.cfg.Name, env, l.cfg.FileType)
 			watchFiles[filepath.Clean(filepath.Join(dir, envConfigName))] = struct{}{}
 		}
 	}
 
+	for _, file := range sourceFiles {
+		watchFiles[filepath.Clean(file)] = struct{}{}
+	}
+
 	go l.watchLoop(watcher, watchFiles)
 	return nil
 }
@@ -399,19 +398,9 @@ func (l *loader) reloadAndNotify(event fsnotify.Event) {
 		return
 	}
 
-	if err := next.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			l.logger.Warn("配置热更新失败：读取基础配置失败",
-				clog.String("event", event.Op.String()),
-				clog.String("path", event.Name),
-				clog.Error(err),
-			)
-			return
-		}
-	}
-
-	if err := l.loadEnvironmentConfig(next); err != nil {
-		l.logger.Warn("配置热更新失败：合并环境配置失败",
+	loaded, _, err := l.loadConfigFiles(next, l.currentEnv())
+	if err != nil {
+		l.logger.Warn("配置热更新失败：加载配置文件失败",
 			clog.String("event", event.Op.String()),
 			clog.String("path", event.Name),
 			clog.Error(err),
@@ -429,6 +418,7 @@ func (l *loader) reloadAndNotify(event fsnotify.Event) {
 	}
 
 	l.v = next
+	l.keySources = loaded.sources
 	l.notifyWatches()
 }
 
@@ -48,7 +48,9 @@ func (l *spyLogger) ErrorContext(ctx context.Context, msg string, fields ...clog
 func (l *spyLogger) FatalContext(ctx context.Context, msg string, fields ...clog.Field) {}
 func (l *spyLogger) With(fields ...clog.Field) clog.Logger                              { return l }
 func (l *spyLogger) WithNamespace(parts ...string) clog.Logger                          { return l }
+func (l *spyLogger) WithNamespaceFields(ns string, fields ...clog.Field) clog.Logger    { return l }
 func (l *spyLogger) SetLevel(level clog.Level) error                                    { return nil }
+func (l *spyLogger) SetNamespaceLevel(namespace string, level clog.Level) error         { return nil }
 func (l *spyLogger) Flush()                                                             {}
 func (l *spyLogger) Close() error                                                       { return nil }
 
@@ -0,0 +1,140 @@
+package connector
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// Container 按类型与名称管理多个连接器实例。
+//
+// 一个服务同时连接多个同类型后端（如缓存 Redis 与队列 Redis）时，为每个连接器配置不同的 Name，
+// 加入 Container 后按名获取。键为 Stats().Type 与 Name() 的组合，不同类型的连接器可以同名。
+// Container 的方法均为并发安全。
+type Container struct {
+	mu    sync.RWMutex
+	conns map[containerKey]Connector
+	order []containerKey // 加入顺序，Close 时逆序关闭
+}
+
+type containerKey struct {
+	typ  string
+	name string
+}
+
+// NewContainer 创建空的连接器容器。
+func NewContainer() *Container {
+	return &Container{conns: make(map[containerKey]Connector)}
+}
+
+// Add 加入连接器，之后由 Container 负责关闭。
+//
+// 同类型下名称已存在时返回 ErrDuplicateName。
+func (c *Container) Add(conn Connector) error {
+	if conn == nil {
+		return xerrors.Wrap(ErrConfig, "connector is nil")
+	}
+	key := containerKey{typ: conn.Stats().Type, name: conn.Name()}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.conns[key]; exists {
+		return xerrors.Wrapf(ErrDuplicateName, "%s connector %q", key.typ, key.name)
+	}
+	c.conns[key] = conn
+	c.order = append(c.order, key)
+	return nil
+}
+
+// Get 按类型与名称获取连接器，不存在时返回 ErrNotFound。
+func (c *Container) Get(typ, name string) (Connector, error) {
+	c.mu.RLock()
+	conn, ok := c.conns[containerKey{typ: typ, name: name}]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, xerrors.Wrapf(ErrNotFound, "%s connector %q", typ, name)
+	}
+	return conn, nil
+}
+
+// GetRedis 按名称获取 Redis 连接器。
+func (c *Container) GetRedis(name string) (RedisConnector, error) {
+	return getTyped[RedisConnector](c, TypeRedis, name)
+}
+
+// GetMySQL 按名称获取 MySQL 连接器。
+func (c *Container) GetMySQL(name string) (MySQLConnector, error) {
+	return getTyped[MySQLConnector](c, TypeMySQL, name)
+}
+
+// GetPostgreSQL 按名称获取 PostgreSQL 连接器。
+func (c *Container) GetPostgreSQL(name string) (PostgreSQLConnector, error) {
+	return getTyped[PostgreSQLConnector](c, TypePostgreSQL, name)
+}
+
+// GetSQLite 按名称获取 SQLite 连接器。
+func (c *Container) GetSQLite(name string) (SQLiteConnector, error) {
+	return getTyped[SQLiteConnector](c, TypeSQLite, name)
+}
+
+// GetEtcd 按名称获取 Etcd 连接器。
+func (c *Container) GetEtcd(name string) (EtcdConnector, error) {
+	return getTyped[EtcdConnector](c, TypeEtcd, name)
+}
+
+// GetNATS 按名称获取 NATS 连接器。
+func (c *Container) GetNATS(name string) (NATSConnector, error) {
+	return getTyped[NATSConnector](c, TypeNATS, name)
+}
+
+// GetKafka 按名称获取 Kafka 连接器。
+func (c *Container) GetKafka(name string) (KafkaConnector, error) {
+	return getTyped[KafkaConnector](c, TypeKafka, name)
+}
+
+// All 按加入顺序返回全部连接器，可直接传给 StatsHandler、ReadinessHandler。
+func (c *Container) All() []Connector {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	conns := make([]Connector, 0, len(c.order))
+	for _, key := range c.order {
+		conns = append(conns, c.conns[key])
+	}
+	return conns
+}
+
+// Close 按加入顺序的逆序关闭全部连接器并清空容器。
+//
+// 单个连接器关闭失败不影响其余连接器，所有错误合并返回。
+func (c *Container) Close() error {
+	c.mu.Lock()
+	order := c.order
+	conns := c.conns
+	c.order = nil
+	c.conns = make(map[containerKey]Connector)
+	c.mu.Unlock()
+
+	var errs []error
+	for _, key := range slices.Backward(order) {
+		if err := conns[key].Close(); err != nil {
+			errs = append(errs, xerrors.Wrapf(err, "close %s connector %q", key.typ, key.name))
+		}
+	}
+	return xerrors.Combine(errs...)
+}
+
+// getTyped 获取连接器并断言为具体接口。
+func getTyped[T Connector](c *Container, typ, name string) (T, error) {
+	var zero T
+	conn, err := c.Get(typ, name)
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := conn.(T)
+	if !ok {
+		return zero, xerrors.Wrapf(ErrNotFound, "%s connector %q has unexpected type %T", typ, name, conn)
+	}
+	return typed, nil
+}
@@ -0,0 +1,92 @@
+package connector
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// closeRecorder 记录 Close 顺序的连接器
+type closeRecorder struct {
+	stubConnector
+	closed *[]string
+	err    error
+}
+
+func (c *closeRecorder) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+func TestContainer(t *testing.T) {
+	t.Parallel()
+
+	newRedis := func(t *testing.T, name string, db int) RedisConnector {
+		t.Helper()
+		conn, err := NewRedis(&RedisConfig{Name: name, Addr: "127.0.0.1:6379", DB: db})
+		require.NoError(t, err)
+		return conn
+	}
+
+	t.Run("按名获取同类型的多个实例", func(t *testing.T) {
+		t.Parallel()
+		c := NewContainer()
+		require.NoError(t, c.Add(newRedis(t, "cache", 0)))
+		require.NoError(t, c.Add(newRedis(t, "queue", 1)))
+
+		cache, err := c.GetRedis("cache")
+		require.NoError(t, err)
+		require.Equal(t, "cache", cache.Name())
+		require.Equal(t, 0, cache.(*redisConnector).cfg.DB)
+
+		queue, err := c.GetRedis("queue")
+		require.NoError(t, err)
+		require.Equal(t, "queue", queue.Name())
+		require.Equal(t, 1, queue.(*redisConnector).cfg.DB)
+
+		_, err = c.GetRedis("missing")
+		require.ErrorIs(t, err, ErrNotFound)
+		_, err = c.GetMySQL("cache")
+		require.ErrorIs(t, err, ErrNotFound)
+
+		require.Len(t, c.All(), 2)
+		require.NoError(t, c.Close())
+	})
+
+	t.Run("同类型重复名报错，不同类型可同名", func(t *testing.T) {
+		t.Parallel()
+		c := NewContainer()
+		require.NoError(t, c.Add(newRedis(t, "default", 0)))
+
+		err := c.Add(newRedis(t, "default", 2))
+		require.ErrorIs(t, err, ErrDuplicateName)
+
+		sqlite, err := NewSQLite(&SQLiteConfig{Path: "file::memory:"})
+		require.NoError(t, err)
+		require.NoError(t, c.Add(sqlite))
+
+		got, err := c.GetSQLite("default")
+		require.NoError(t, err)
+		require.Same(t, sqlite, got)
+		require.NoError(t, c.Close())
+	})
+
+	t.Run("逆序关闭全部并合并错误", func(t *testing.T) {
+		t.Parallel()
+		var closed []string
+		errBoom := errors.New("boom")
+		c := NewContainer()
+		require.NoError(t, c.Add(&closeRecorder{stubConnector: stubConnector{name: "a"}, closed: &closed}))
+		require.NoError(t, c.Add(&closeRecorder{stubConnector: stubConnector{name: "b"}, closed: &closed, err: errBoom}))
+		require.NoError(t, c.Add(&closeRecorder{stubConnector: stubConnector{name: "c"}, closed: &closed}))
+
+		err := c.Close()
+		require.ErrorIs(t, err, errBoom)
+		require.Equal(t, []string{"c", "b", "a"}, closed)
+
+		require.Empty(t, c.All())
+		require.NoError(t, c.Close())
+		require.Equal(t, []string{"c", "b", "a"}, closed)
+	})
+}
@@ -15,4 +15,19 @@ var (
 
 	// ErrClientNil 客户端为空（未初始化或已关闭）
 	ErrClientNil = xerrors.New("connector: client is nil")
+
+	// ErrUnknownType 连接器类型未注册
+	ErrUnknownType = xerrors.New("connector: unknown type")
+
+	// ErrNotFound Container 中不存在指定类型与名称的连接器
+	ErrNotFound = xerrors.New("connector: not found")
+
+	// ErrDuplicateName Container 中已存在同类型同名的连接器
+	ErrDuplicateName = xerrors.New("connector: duplicate name")
+
+	// ErrClosing 连接器正在优雅关闭，不再接受新操作
+	ErrClosing = xerrors.New("connector: closing")
+
+	// ErrReadOnly 只读包装下执行了写操作
+	ErrReadOnly = xerrors.New("connector: read-only")
 )
@@ -9,6 +9,7 @@ import (
 	"github.com/ceyewan/genesis/xerrors"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"google.golang.org/grpc"
 )
 
 type etcdConnector struct {
@@ -17,6 +18,10 @@ type etcdConnector struct {
 	logger  clog.Logger
 	healthy atomic.Bool
 	mu      sync.RWMutex
+	stats   statsTracker
+	slow    slowLog
+	// inflight 统计进行中的一元调用，供 Shutdown 等待
+	inflight inflight
 }
 
 // NewEtcd 创建 Etcd 连接器
@@ -36,12 +41,15 @@ func NewEtcd(cfg *EtcdConfig, opts ...Option) (EtcdConnector, error) {
 		cfg:    cfg,
 		logger: opt.logger.With(clog.String("connector", "etcd"), clog.String("name", cfg.Name)),
 	}
+	c.slow = slowLog{threshold: opt.slowThreshold, logger: c.logger}
 
 	return c, nil
 }
 
 // Connect 建立连接
-func (c *etcdConnector) Connect(ctx context.Context) error {
+func (c *etcdConnector) Connect(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -64,6 +72,13 @@ func (c *etcdConnector) Connect(ctx context.Context) error {
 		clientConfig.Password = c.cfg.Password
 	}
 
+	clientConfig.DialOptions = append(clientConfig.DialOptions,
+		grpc.WithChainUnaryInterceptor(inflightUnaryInterceptor(&c.inflight)))
+	if c.slow.threshold > 0 {
+		clientConfig.DialOptions = append(clientConfig.DialOptions,
+			grpc.WithChainUnaryInterceptor(slowUnaryInterceptor(&c.slow)))
+	}
+
 	// 创建客户端
 	client, err := clientv3.New(clientConfig)
 	if err != nil {
@@ -84,7 +99,9 @@ func (c *etcdConnector) Connect(ctx context.Context) error {
 	}
 
 	c.client = client
+	c.inflight.reset()
 	c.healthy.Store(true)
+	c.stats.connected()
 	c.logger.Info("successfully connected to etcd", clog.Any("endpoints", c.cfg.Endpoints))
 	return nil
 }
@@ -96,6 +113,7 @@ func (c *etcdConnector) Close() error {
 
 	c.logger.Info("closing etcd connection")
 	c.healthy.Store(false)
+	c.stats.disconnected()
 
 	if c.client == nil {
 		return nil
@@ -111,8 +129,17 @@ func (c *etcdConnector) Close() error {
 	return nil
 }
 
+// Shutdown 优雅关闭：拒绝新的一元调用，等待进行中的调用在 ctx 内完成后关闭连接
+//
+// Watch、KeepAlive 等流式调用不计入，关闭时直接中断。
+func (c *etcdConnector) Shutdown(ctx context.Context) error {
+	return shutdownTracked(ctx, "etcd", c.cfg.Name, c.logger, &c.inflight, c.Close)
+}
+
 // HealthCheck 检查连接健康状态
-func (c *etcdConnector) HealthCheck(ctx context.Context) error {
+func (c *etcdConnector) HealthCheck(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.RLock()
 	client := c.client
 	c.mu.RUnlock()
@@ -125,7 +152,7 @@ func (c *etcdConnector) HealthCheck(ctx context.Context) error {
 	testCtx, cancel := context.WithTimeout(ctx, c.cfg.DialTimeout)
 	defer cancel()
 
-	_, err := client.Get(testCtx, "health-check")
+	_, err = client.Get(testCtx, "health-check")
 	// etcd v3 对于不存在的键返回空响应，不返回错误
 	if err != nil {
 		c.healthy.Store(false)
@@ -147,6 +174,16 @@ func (c *etcdConnector) Name() string {
 	return c.cfg.Name
 }
 
+// Stats 返回连接器运行时统计
+func (c *etcdConnector) Stats() ConnStats {
+	return c.stats.snapshot(TypeEtcd, c.cfg.Name, c.IsHealthy())
+}
+
+// slowLogger 返回慢操作日志配置，供 ObserveSlow 使用
+func (c *etcdConnector) slowLogger() *slowLog {
+	return &c.slow
+}
+
 // GetClient 返回 Etcd 客户端
 func (c *etcdConnector) GetClient() *clientv3.Client {
 	c.mu.RLock()
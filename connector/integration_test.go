@@ -109,6 +109,29 @@ func TestRedisConnectorIntegration(t *testing.T) {
 		assert.False(t, conn.IsHealthy())
 	})
 
+	t.Run("连接池预热", func(t *testing.T) {
+		container, cfg := setupRedisContainer(t)
+		defer container.Terminate(context.Background())
+
+		conn, err := NewRedis(cfg, WithLogger(getTestLogger()))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx := context.Background()
+		require.NoError(t, conn.Connect(ctx))
+		require.NoError(t, Warmup(ctx, conn, 8))
+
+		client := conn.GetClient()
+		stats := client.PoolStats()
+		assert.GreaterOrEqual(t, stats.IdleConns, uint32(8))
+
+		// 首次业务请求直接复用空闲连接，不新建连接
+		require.NoError(t, client.Set(ctx, "test:warmup:"+newTestID(), "v", time.Minute).Err())
+		after := client.PoolStats()
+		assert.Equal(t, stats.Misses, after.Misses)
+		assert.Equal(t, stats.TotalConns, after.TotalConns)
+	})
+
 	t.Run("健康检查失败场景", func(t *testing.T) {
 		cfg := &RedisConfig{
 			Name: "test-redis-fail",
@@ -218,6 +241,29 @@ func TestMySQLConnectorIntegration(t *testing.T) {
 		assert.False(t, conn.IsHealthy())
 	})
 
+	t.Run("连接池预热", func(t *testing.T) {
+		container, cfg := setupMySQLContainer(t)
+		defer container.Terminate(context.Background())
+
+		conn, err := NewMySQL(cfg, WithLogger(getTestLogger()), WithWarmup(5))
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx := context.Background()
+		require.NoError(t, conn.Connect(ctx))
+
+		pool := conn.Stats().Pool
+		require.NotNil(t, pool)
+		assert.Equal(t, 5, pool.Idle)
+
+		// 首次业务请求直接复用空闲连接，不新建连接
+		var result int
+		require.NoError(t, conn.GetClient().Raw("SELECT 1").Scan(&result).Error)
+		after := conn.Stats().Pool
+		assert.Equal(t, pool.Open, after.Open)
+		assert.Equal(t, 5, after.Idle)
+	})
+
 	t.Run("GORM 基本操作", func(t *testing.T) {
 		container, cfg := setupMySQLContainer(t)
 		defer container.Terminate(context.Background())
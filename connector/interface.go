@@ -7,6 +7,7 @@
 //   - 健康检查：提供主动探活和缓存态读取，但不负责统一重连或故障恢复
 //   - 并发安全：所有公开方法均为并发安全，支持多协程同时访问
 //   - 资源管理：遵循"谁创建，谁负责释放"原则，Close() 应在应用层调用
+//   - 多实例：Container 按类型与 Name 管理多个同类型连接器，按名获取、统一关闭
 //
 // 设计理念：
 //   - 接口优先：定义清晰的接口契约，实现细节可替换
@@ -36,6 +37,39 @@
 //	client := conn.GetClient()
 //	result, err := client.Get(ctx, "key").Result()
 //
+// 按类型创建：
+//
+//	conn, err := connector.Create(connector.TypeRedis, map[string]any{"addr": "127.0.0.1:6379"})
+//
+// 自定义类型可通过 Register 注册工厂后使用 Create 创建。
+//
+// 只读包装（Redis、gorm 系、Etcd）：
+//
+//	roConn, err := connector.ReadOnly(mysqlConn)
+//
+// K8s 探针：
+//
+//	mux.Handle("/livez", connector.LivenessHandler())
+//	mux.Handle("/readyz", connector.ReadinessHandler(redisConn, mysqlConn))
+//
+// 运行时统计：
+//
+//	mux.Handle("/admin/connectors", connector.StatsHandler(redisConn, mysqlConn))
+//
+// 连接池预热（Redis、gorm 系）：
+//
+//	err := connector.Warmup(ctx, mysqlConn, 10)
+//
+// 慢操作日志（Redis、Etcd 自动接入，NATS、Kafka 通过 ObserveSlow 包装）：
+//
+//	conn, err := connector.NewRedis(cfg, connector.WithSlowThreshold(50*time.Millisecond))
+//
+// 优雅关闭（等待进行中的操作完成，超时强制关闭）：
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	err := connector.Shutdown(ctx, mysqlConn)
+//
 // 资源所有权：
 //
 //	Connector 拥有底层连接的生命周期，应通过 defer 确保 Close() 被调用。
@@ -100,6 +134,12 @@ type Connector interface {
 	//
 	// 名称用于日志记录和指标标识，应在配置中唯一标识此连接器实例。
 	Name() string
+
+	// Stats 返回连接器运行时统计。
+	//
+	// 包含连接状态、连接池使用、已连接时长、累计错误数与最近错误，
+	// 只读取内存状态，不产生 I/O。可通过 StatsHandler 聚合成 admin 端点。
+	Stats() ConnStats
 }
 
 // =============================================================================
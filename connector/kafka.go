@@ -19,6 +19,8 @@ type kafkaConnector struct {
 	logger  clog.Logger
 	healthy atomic.Bool
 	mu      sync.RWMutex
+	stats   statsTracker
+	slow    slowLog
 }
 
 // NewKafka 创建 Kafka 连接器
@@ -34,14 +36,19 @@ func NewKafka(cfg *KafkaConfig, opts ...Option) (KafkaConnector, error) {
 	}
 	opt.applyDefaults()
 
-	return &kafkaConnector{
+	c := &kafkaConnector{
 		cfg:    cfg,
 		logger: opt.logger.With(clog.String("connector", "kafka"), clog.String("name", cfg.Name)),
-	}, nil
+	}
+	c.slow = slowLog{threshold: opt.slowThreshold, logger: c.logger}
+
+	return c, nil
 }
 
 // Connect 建立连接
-func (c *kafkaConnector) Connect(ctx context.Context) error {
+func (c *kafkaConnector) Connect(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -86,6 +93,7 @@ func (c *kafkaConnector) Connect(ctx context.Context) error {
 
 	c.client = client
 	c.healthy.Store(true)
+	c.stats.connected()
 	c.logger.Info("successfully connected to kafka")
 
 	return nil
@@ -97,6 +105,7 @@ func (c *kafkaConnector) Close() error {
 	defer c.mu.Unlock()
 
 	c.healthy.Store(false)
+	c.stats.disconnected()
 
 	if c.client == nil {
 		return nil
@@ -108,8 +117,35 @@ func (c *kafkaConnector) Close() error {
 	return nil
 }
 
+// Shutdown 优雅关闭：等待缓冲中的消息在 ctx 内发送完成后关闭连接
+//
+// Kafka 客户端没有"拒绝新操作"的状态，Shutdown 期间仍可继续 Produce，应先停止上游生产者。
+// ctx 结束时强制关闭，未发送的消息会以错误回调给 Produce 的 promise。
+func (c *kafkaConnector) Shutdown(ctx context.Context) error {
+	c.mu.RLock()
+	client := c.client
+	c.mu.RUnlock()
+	if client == nil {
+		return c.Close()
+	}
+
+	flushErr := client.Flush(ctx)
+	pending := client.BufferedProduceRecords()
+	closeErr := c.Close()
+	if flushErr == nil {
+		return closeErr
+	}
+	c.logger.Warn("shutdown timed out, force closed", clog.Int64("buffered", pending), clog.Error(flushErr))
+	return xerrors.Combine(
+		xerrors.Wrapf(flushErr, "kafka connector[%s]: %d records still buffered, force closed", c.cfg.Name, pending),
+		closeErr,
+	)
+}
+
 // HealthCheck 检查连接健康状态
-func (c *kafkaConnector) HealthCheck(ctx context.Context) error {
+func (c *kafkaConnector) HealthCheck(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.RLock()
 	client := c.client
 	c.mu.RUnlock()
@@ -148,6 +184,16 @@ func (c *kafkaConnector) Name() string {
 	return c.cfg.Name
 }
 
+// Stats 返回连接器运行时统计
+func (c *kafkaConnector) Stats() ConnStats {
+	return c.stats.snapshot(TypeKafka, c.cfg.Name, c.IsHealthy())
+}
+
+// slowLogger 返回慢操作日志配置，供 ObserveSlow 使用
+func (c *kafkaConnector) slowLogger() *slowLog {
+	return &c.slow
+}
+
 // GetClient 返回 Kafka 客户端
 func (c *kafkaConnector) GetClient() *kgo.Client {
 	c.mu.RLock()
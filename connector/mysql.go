@@ -21,6 +21,10 @@ type mysqlConnector struct {
 	logger  clog.Logger
 	healthy atomic.Bool
 	mu      sync.RWMutex
+	stats   statsTracker
+	warmup  int
+	// inflight 统计进行中的语句，供 Shutdown 等待
+	inflight inflight
 }
 
 // NewMySQL 创建 MySQL 连接器
@@ -39,13 +43,16 @@ func NewMySQL(cfg *MySQLConfig, opts ...Option) (MySQLConnector, error) {
 	c := &mysqlConnector{
 		cfg:    cfg,
 		logger: opt.logger.With(clog.String("connector", "mysql"), clog.String("name", cfg.Name)),
+		warmup: opt.warmup,
 	}
 
 	return c, nil
 }
 
 // Connect 建立连接
-func (c *mysqlConnector) Connect(ctx context.Context) error {
+func (c *mysqlConnector) Connect(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -90,6 +97,11 @@ func (c *mysqlConnector) Connect(ctx context.Context) error {
 		return xerrors.Wrapf(ErrConnection, "mysql connector[%s]: failed to get db instance: %v", c.cfg.Name, err)
 	}
 
+	if err := registerInflightCallbacks(db, &c.inflight); err != nil {
+		sqlDB.Close()
+		return xerrors.Wrapf(ErrConnection, "mysql connector[%s]: register inflight callbacks: %v", c.cfg.Name, err)
+	}
+
 	// 配置连接池
 	sqlDB.SetMaxIdleConns(c.cfg.MaxIdleConns)
 	sqlDB.SetMaxOpenConns(c.cfg.MaxOpenConns)
@@ -103,11 +115,19 @@ func (c *mysqlConnector) Connect(ctx context.Context) error {
 	}
 
 	c.db = db
+	c.inflight.reset()
 	c.healthy.Store(true)
+	c.stats.connected()
 	c.logger.Info("successfully connected to mysql",
 		clog.String("host", c.cfg.Host),
 		clog.String("database", c.cfg.Database))
 
+	if c.warmup > 0 {
+		if err := warmupGorm(ctx, c.cfg.Name, db, c.warmup); err != nil {
+			c.logger.Warn("connection pool warmup failed", clog.Int("conns", c.warmup), clog.Error(err))
+		}
+	}
+
 	return nil
 }
 
@@ -118,6 +138,7 @@ func (c *mysqlConnector) Close() error {
 
 	c.logger.Info("closing mysql connection")
 	c.healthy.Store(false)
+	c.stats.disconnected()
 
 	if c.db == nil {
 		return nil
@@ -142,8 +163,15 @@ func (c *mysqlConnector) Close() error {
 	return nil
 }
 
+// Shutdown 优雅关闭：拒绝新语句，等待进行中的语句在 ctx 内完成后关闭连接
+func (c *mysqlConnector) Shutdown(ctx context.Context) error {
+	return shutdownTracked(ctx, "mysql", c.cfg.Name, c.logger, &c.inflight, c.Close)
+}
+
 // HealthCheck 检查连接健康状态
-func (c *mysqlConnector) HealthCheck(ctx context.Context) error {
+func (c *mysqlConnector) HealthCheck(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.RLock()
 	db := c.db
 	c.mu.RUnlock()
@@ -179,6 +207,13 @@ func (c *mysqlConnector) Name() string {
 	return c.cfg.Name
 }
 
+// Stats 返回连接器运行时统计
+func (c *mysqlConnector) Stats() ConnStats {
+	s := c.stats.snapshot(TypeMySQL, c.cfg.Name, c.IsHealthy())
+	s.Pool = gormPoolStats(c.GetClient())
+	return s
+}
+
 // GetClient 返回 GORM 客户端
 func (c *mysqlConnector) GetClient() *gorm.DB {
 	c.mu.RLock()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/xerrors"
@@ -17,6 +18,8 @@ type natsConnector struct {
 	logger  clog.Logger
 	healthy atomic.Bool
 	mu      sync.RWMutex
+	stats   statsTracker
+	slow    slowLog
 }
 
 // NewNATS 创建 NATS 连接器
@@ -36,12 +39,15 @@ func NewNATS(cfg *NATSConfig, opts ...Option) (NATSConnector, error) {
 		cfg:    cfg,
 		logger: opt.logger.With(clog.String("connector", "nats"), clog.String("name", cfg.Name)),
 	}
+	c.slow = slowLog{threshold: opt.slowThreshold, logger: c.logger}
 
 	return c, nil
 }
 
 // Connect 建立连接
-func (c *natsConnector) Connect(ctx context.Context) error {
+func (c *natsConnector) Connect(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -79,6 +85,7 @@ func (c *natsConnector) Connect(ctx context.Context) error {
 
 	c.conn = conn
 	c.healthy.Store(true)
+	c.stats.connected()
 	c.logger.Info("successfully connected to nats", clog.String("url", c.cfg.URL))
 
 	return nil
@@ -90,6 +97,7 @@ func (c *natsConnector) Close() error {
 	defer c.mu.Unlock()
 
 	c.healthy.Store(false)
+	c.stats.disconnected()
 
 	if c.conn == nil {
 		return nil
@@ -111,8 +119,43 @@ func (c *natsConnector) Close() error {
 	return nil
 }
 
+// drainPollInterval Shutdown 等待 Drain 完成时检查连接状态的间隔
+const drainPollInterval = 10 * time.Millisecond
+
+// Shutdown 优雅关闭：Drain 处理完已投递的消息并发出缓冲中的消息，在 ctx 内完成后关闭连接
+//
+// Drain 期间不再接收新消息、不允许新的订阅与发布；ctx 结束时强制关闭。
+func (c *natsConnector) Shutdown(ctx context.Context) error {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+	if conn == nil || conn.Status() != nats.CONNECTED {
+		return c.Close()
+	}
+
+	if err := conn.Drain(); err != nil {
+		c.logger.Warn("failed to drain nats connection", clog.Error(err))
+		return c.Close()
+	}
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for !conn.IsClosed() {
+		select {
+		case <-ctx.Done():
+			closeErr := c.Close()
+			c.logger.Warn("shutdown timed out, force closed", clog.Error(ctx.Err()))
+			return xerrors.Combine(xerrors.Wrapf(ctx.Err(), "nats connector[%s]: drain not finished, force closed", c.cfg.Name), closeErr)
+		case <-ticker.C:
+		}
+	}
+	return c.Close()
+}
+
 // HealthCheck 检查连接健康状态
-func (c *natsConnector) HealthCheck(ctx context.Context) error {
+func (c *natsConnector) HealthCheck(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.RLock()
 	conn := c.conn
 	c.mu.RUnlock()
@@ -145,6 +188,16 @@ func (c *natsConnector) Name() string {
 	return c.cfg.Name
 }
 
+// Stats 返回连接器运行时统计
+func (c *natsConnector) Stats() ConnStats {
+	return c.stats.snapshot(TypeNATS, c.cfg.Name, c.IsHealthy())
+}
+
+// slowLogger 返回慢操作日志配置，供 ObserveSlow 使用
+func (c *natsConnector) slowLogger() *slowLog {
+	return &c.slow
+}
+
 // GetClient 返回 NATS 连接
 func (c *natsConnector) GetClient() *nats.Conn {
 	c.mu.RLock()
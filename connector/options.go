@@ -1,9 +1,15 @@
 package connector
 
-import "github.com/ceyewan/genesis/clog"
+import (
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+)
 
 type options struct {
-	logger clog.Logger
+	logger        clog.Logger
+	warmup        int
+	slowThreshold time.Duration
 }
 
 // Option 配置连接器的选项
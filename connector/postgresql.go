@@ -20,6 +20,10 @@ type postgresqlConnector struct {
 	logger  clog.Logger
 	healthy atomic.Bool
 	mu      sync.RWMutex
+	stats   statsTracker
+	warmup  int
+	// inflight 统计进行中的语句，供 Shutdown 等待
+	inflight inflight
 }
 
 // NewPostgreSQL 创建 PostgreSQL 连接器
@@ -38,13 +42,16 @@ func NewPostgreSQL(cfg *PostgreSQLConfig, opts ...Option) (PostgreSQLConnector,
 	c := &postgresqlConnector{
 		cfg:    cfg,
 		logger: opt.logger.With(clog.String("connector", "postgresql"), clog.String("name", cfg.Name)),
+		warmup: opt.warmup,
 	}
 
 	return c, nil
 }
 
 // Connect 建立连接
-func (c *postgresqlConnector) Connect(ctx context.Context) error {
+func (c *postgresqlConnector) Connect(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -88,6 +95,11 @@ func (c *postgresqlConnector) Connect(ctx context.Context) error {
 		return xerrors.Wrapf(ErrConnection, "postgresql connector[%s]: failed to get db instance: %v", c.cfg.Name, err)
 	}
 
+	if err := registerInflightCallbacks(db, &c.inflight); err != nil {
+		sqlDB.Close()
+		return xerrors.Wrapf(ErrConnection, "postgresql connector[%s]: register inflight callbacks: %v", c.cfg.Name, err)
+	}
+
 	// 配置连接池
 	sqlDB.SetMaxIdleConns(c.cfg.MaxIdleConns)
 	sqlDB.SetMaxOpenConns(c.cfg.MaxOpenConns)
@@ -101,11 +113,19 @@ func (c *postgresqlConnector) Connect(ctx context.Context) error {
 	}
 
 	c.db = db
+	c.inflight.reset()
 	c.healthy.Store(true)
+	c.stats.connected()
 	c.logger.Info("successfully connected to postgresql",
 		clog.String("host", c.cfg.Host),
 		clog.String("database", c.cfg.Database))
 
+	if c.warmup > 0 {
+		if err := warmupGorm(ctx, c.cfg.Name, db, c.warmup); err != nil {
+			c.logger.Warn("connection pool warmup failed", clog.Int("conns", c.warmup), clog.Error(err))
+		}
+	}
+
 	return nil
 }
 
@@ -116,6 +136,7 @@ func (c *postgresqlConnector) Close() error {
 
 	c.logger.Info("closing postgresql connection")
 	c.healthy.Store(false)
+	c.stats.disconnected()
 
 	if c.db == nil {
 		return nil
@@ -140,8 +161,15 @@ func (c *postgresqlConnector) Close() error {
 	return nil
 }
 
+// Shutdown 优雅关闭：拒绝新语句，等待进行中的语句在 ctx 内完成后关闭连接
+func (c *postgresqlConnector) Shutdown(ctx context.Context) error {
+	return shutdownTracked(ctx, "postgresql", c.cfg.Name, c.logger, &c.inflight, c.Close)
+}
+
 // HealthCheck 检查连接健康状态
-func (c *postgresqlConnector) HealthCheck(ctx context.Context) error {
+func (c *postgresqlConnector) HealthCheck(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.RLock()
 	db := c.db
 	c.mu.RUnlock()
@@ -177,6 +205,13 @@ func (c *postgresqlConnector) Name() string {
 	return c.cfg.Name
 }
 
+// Stats 返回连接器运行时统计
+func (c *postgresqlConnector) Stats() ConnStats {
+	s := c.stats.snapshot(TypePostgreSQL, c.cfg.Name, c.IsHealthy())
+	s.Pool = gormPoolStats(c.GetClient())
+	return s
+}
+
 // GetClient 返回 GORM 客户端
 func (c *postgresqlConnector) GetClient() *gorm.DB {
 	c.mu.RLock()
@@ -0,0 +1,50 @@
+package connector
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// probeResponse 探针响应体
+type probeResponse struct {
+	Status  string   `json:"status"`
+	Pending []string `json:"pending,omitempty"`
+}
+
+// LivenessHandler 返回存活探针 handler。
+//
+// 只要进程能够响应请求就返回 200，不检查任何外部依赖，
+// 避免依赖抖动导致 K8s 反复重启 Pod。
+func LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeProbe(w, http.StatusOK, probeResponse{Status: "alive"})
+	})
+}
+
+// ReadinessHandler 返回就绪探针 handler。
+//
+// conns 为应用的关键依赖。所有连接器 IsHealthy() 为 true 时返回 200，
+// 否则返回 503，并在响应体 pending 字段中列出未就绪的连接器名称。
+// 探针只读取缓存的健康状态，不产生 I/O：延迟连接的连接器在 Connect 成功前会保持 503，
+// 运行期间的状态刷新依赖调用方定期执行 HealthCheck。
+func ReadinessHandler(conns ...Connector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		var pending []string
+		for _, conn := range conns {
+			if !conn.IsHealthy() {
+				pending = append(pending, conn.Name())
+			}
+		}
+		if len(pending) > 0 {
+			writeProbe(w, http.StatusServiceUnavailable, probeResponse{Status: "not_ready", Pending: pending})
+			return
+		}
+		writeProbe(w, http.StatusOK, probeResponse{Status: "ready"})
+	})
+}
+
+func writeProbe(w http.ResponseWriter, status int, body probeResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,51 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestProbeHandlers 测试存活与就绪探针
+func TestProbeHandlers(t *testing.T) {
+	conn, err := NewSQLite(&SQLiteConfig{Name: "probe-sqlite", Path: "file:probe?mode=memory&cache=shared"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	liveness := LivenessHandler()
+	readiness := ReadinessHandler(conn)
+
+	probe := func(h http.Handler) (int, probeResponse) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		var body probeResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec.Code, body
+	}
+
+	// 启动阶段尚未连接：未就绪但存活
+	code, body := probe(readiness)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, []string{"probe-sqlite"}, body.Pending)
+	code, _ = probe(liveness)
+	require.Equal(t, http.StatusOK, code)
+
+	// 关键连接建立后就绪
+	require.NoError(t, conn.Connect(context.Background()))
+	code, body = probe(readiness)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ready", body.Status)
+	code, _ = probe(liveness)
+	require.Equal(t, http.StatusOK, code)
+
+	// 连接关闭后重新变为未就绪，存活不受影响
+	require.NoError(t, conn.Close())
+	code, _ = probe(readiness)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	code, _ = probe(liveness)
+	require.Equal(t, http.StatusOK, code)
+}
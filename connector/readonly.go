@@ -0,0 +1,353 @@
+package connector
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/ceyewan/genesis/xerrors"
+
+	"github.com/redis/go-redis/v9"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"gorm.io/gorm"
+)
+
+// =============================================================================
+// 只读包装
+// =============================================================================
+
+// ReadOnly 返回连接器的只读包装，用于强制执行读写权限边界。
+//
+// 包装后的连接器与原连接器共享底层连接与生命周期（Connect/Close/HealthCheck 均透传），
+// 只是 GetClient() 返回的客户端会拒绝写操作并返回 ErrReadOnly：
+//   - Redis：拦截 SET、DEL、HSET、EVAL 等写命令（含 Pipeline 中的写命令）
+//   - MySQL/PostgreSQL/SQLite：通过 gorm callback 拒绝 Create/Update/Delete 与非 SELECT 的原生 SQL
+//   - Etcd：拒绝 Put、Delete、Compact 以及包含写操作的 Txn
+//
+// 其他类型的连接器（NATS、Kafka）返回 ErrConfig。
+//
+// 使用示例：
+//
+//	roConn, err := connector.ReadOnly(mysqlConn)
+//	if err != nil {
+//	    return err
+//	}
+//	db := roConn.GetClient()
+//	db.Find(&users)           // 正常
+//	db.Create(&user).Error    // ErrReadOnly
+func ReadOnly[T any](conn TypedConnector[T]) (TypedConnector[T], error) {
+	if conn == nil {
+		return nil, xerrors.Wrap(ErrConfig, "read-only: connector is nil")
+	}
+
+	var zero T
+	var wrap func(T) T
+	switch any(zero).(type) {
+	case *redis.Client:
+		wrap = func(c T) T { return any(readOnlyRedis(any(c).(*redis.Client))).(T) }
+	case *gorm.DB:
+		wrap = func(c T) T { return any(readOnlyGorm(any(c).(*gorm.DB))).(T) }
+	case *clientv3.Client:
+		wrap = func(c T) T { return any(readOnlyEtcd(any(c).(*clientv3.Client))).(T) }
+	default:
+		return nil, xerrors.Wrapf(ErrConfig, "read-only: unsupported connector %s", conn.Name())
+	}
+
+	return &readOnlyConnector[T]{TypedConnector: conn, wrap: wrap}, nil
+}
+
+// readOnlyConnector 只读连接器包装。
+//
+// 底层客户端可能在 Close 后重新 Connect 而变化，因此按底层客户端缓存包装结果。
+type readOnlyConnector[T any] struct {
+	TypedConnector[T]
+	wrap func(T) T
+
+	mu      sync.Mutex
+	source  any
+	wrapped T
+}
+
+// GetClient 返回只读客户端，未连接时返回零值。
+func (c *readOnlyConnector[T]) GetClient() T {
+	client := c.TypedConnector.GetClient()
+	if isNilClient(client) {
+		var zero T
+		return zero
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.source != any(client) {
+		c.wrapped = c.wrap(client)
+		c.source = any(client)
+	}
+	return c.wrapped
+}
+
+// isNilClient 判断指针类型的客户端是否为 nil。
+func isNilClient[T any](client T) bool {
+	switch v := any(client).(type) {
+	case *redis.Client:
+		return v == nil
+	case *gorm.DB:
+		return v == nil
+	case *clientv3.Client:
+		return v == nil
+	}
+	return false
+}
+
+// -----------------------------------------------------------------------------
+// Redis
+// -----------------------------------------------------------------------------
+
+// redisWriteCommands Redis 写命令集合（小写）。
+//
+// EVAL/EVALSHA/FCALL 无法静态判断脚本是否写入，一律视为写命令；只读脚本请使用 EVAL_RO/EVALSHA_RO。
+var redisWriteCommands = map[string]struct{}{
+	"set": {}, "setnx": {}, "setex": {}, "psetex": {}, "mset": {}, "msetnx": {}, "getset": {},
+	"getdel": {}, "getex": {}, "append": {}, "setrange": {}, "setbit": {}, "bitop": {}, "bitfield": {},
+	"incr": {}, "incrby": {}, "incrbyfloat": {}, "decr": {}, "decrby": {},
+	"del": {}, "unlink": {}, "expire": {}, "pexpire": {}, "expireat": {}, "pexpireat": {}, "persist": {},
+	"rename": {}, "renamenx": {}, "move": {}, "copy": {}, "restore": {}, "migrate": {},
+	"hset": {}, "hsetnx": {}, "hmset": {}, "hdel": {}, "hincrby": {}, "hincrbyfloat": {},
+	"hexpire": {}, "hpexpire": {}, "hexpireat": {}, "hpexpireat": {}, "hpersist": {}, "hgetdel": {}, "hgetex": {}, "hsetex": {},
+	"lpush": {}, "rpush": {}, "lpushx": {}, "rpushx": {}, "lpop": {}, "rpop": {}, "lset": {}, "lrem": {},
+	"ltrim": {}, "linsert": {}, "lmove": {}, "blmove": {}, "rpoplpush": {}, "brpoplpush": {},
+	"blpop": {}, "brpop": {}, "lmpop": {}, "blmpop": {},
+	"sadd": {}, "srem": {}, "spop": {}, "smove": {}, "sinterstore": {}, "sunionstore": {}, "sdiffstore": {},
+	"zadd": {}, "zrem": {}, "zincrby": {}, "zpopmin": {}, "zpopmax": {}, "bzpopmin": {}, "bzpopmax": {},
+	"zmpop": {}, "bzmpop": {}, "zremrangebyscore": {}, "zremrangebyrank": {}, "zremrangebylex": {},
+	"zunionstore": {}, "zinterstore": {}, "zdiffstore": {}, "zrangestore": {},
+	"xadd": {}, "xdel": {}, "xtrim": {}, "xack": {}, "xclaim": {}, "xautoclaim": {}, "xgroup": {},
+	"xreadgroup": {}, "xsetid": {},
+	"pfadd": {}, "pfmerge": {}, "geoadd": {}, "geosearchstore": {}, "georadius": {}, "georadiusbymember": {},
+	"eval": {}, "evalsha": {}, "fcall": {}, "function": {}, "script": {},
+	"publish": {}, "spublish": {}, "flushdb": {}, "flushall": {}, "swapdb": {},
+}
+
+// isRedisWriteCommand 判断命令是否为写命令。
+func isRedisWriteCommand(cmd redis.Cmder) bool {
+	_, ok := redisWriteCommands[strings.ToLower(cmd.Name())]
+	return ok
+}
+
+// readOnlyRedis 基于原客户端克隆一个共享连接池、带只读 Hook 的客户端。
+func readOnlyRedis(client *redis.Client) *redis.Client {
+	ro := client.WithTimeout(client.Options().ReadTimeout)
+	ro.AddHook(readOnlyRedisHook{})
+	return ro
+}
+
+// readOnlyRedisHook 拦截写命令的 Redis Hook。
+type readOnlyRedisHook struct{}
+
+func (readOnlyRedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (readOnlyRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if isRedisWriteCommand(cmd) {
+			return xerrors.Wrapf(ErrReadOnly, "redis command %s", cmd.Name())
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (readOnlyRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if isRedisWriteCommand(cmd) {
+				err := xerrors.Wrapf(ErrReadOnly, "redis pipeline command %s", cmd.Name())
+				for _, c := range cmds {
+					c.SetErr(err)
+				}
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// GORM (MySQL / PostgreSQL / SQLite)
+// -----------------------------------------------------------------------------
+
+const (
+	readOnlySettingKey   = "genesis:connector:read_only"
+	readOnlyCallbackName = "genesis:read_only"
+)
+
+// readOnlyCallbackMu 保护 callback 的注册，避免并发包装同一 DB 时重复注册
+var readOnlyCallbackMu sync.Mutex
+
+// readOnlyGorm 返回带只读标记的 Session。
+//
+// callback 注册在共享的 gorm 配置上，但只对带只读标记的 Session 生效，原 DB 不受影响。
+// 注册失败时返回一个始终报错的 Session，确保失败时仍然拒绝访问。
+func readOnlyGorm(db *gorm.DB) *gorm.DB {
+	if err := registerReadOnlyCallbacks(db); err != nil {
+		tx := db.Session(&gorm.Session{})
+		_ = tx.AddError(xerrors.Wrap(err, "register read-only callbacks"))
+		return tx
+	}
+	return db.Set(readOnlySettingKey, true).Session(&gorm.Session{})
+}
+
+func registerReadOnlyCallbacks(db *gorm.DB) error {
+	readOnlyCallbackMu.Lock()
+	defer readOnlyCallbackMu.Unlock()
+
+	cb := db.Callback()
+	if cb.Create().Get(readOnlyCallbackName) != nil {
+		return nil
+	}
+
+	rejectAll := func(tx *gorm.DB) {
+		if isReadOnlySession(tx) {
+			_ = tx.AddError(xerrors.Wrapf(ErrReadOnly, "write to table %s", tx.Statement.Table))
+		}
+	}
+	rejectNonSelect := func(tx *gorm.DB) {
+		if !isReadOnlySession(tx) {
+			return
+		}
+		// Query/Row 仅在原生 SQL 时才有 SQL 文本，Find/First 等构建的查询一定是 SELECT
+		if sql := tx.Statement.SQL.String(); sql != "" && !isReadSQL(sql) {
+			_ = tx.AddError(xerrors.Wrap(ErrReadOnly, "non-select statement"))
+		}
+	}
+
+	return xerrors.Combine(
+		cb.Create().Before("gorm:create").Register(readOnlyCallbackName, rejectAll),
+		cb.Update().Before("gorm:update").Register(readOnlyCallbackName, rejectAll),
+		cb.Delete().Before("gorm:delete").Register(readOnlyCallbackName, rejectAll),
+		cb.Raw().Before("gorm:raw").Register(readOnlyCallbackName, func(tx *gorm.DB) {
+			if isReadOnlySession(tx) && !isReadSQL(tx.Statement.SQL.String()) {
+				_ = tx.AddError(xerrors.Wrap(ErrReadOnly, "non-select statement"))
+			}
+		}),
+		cb.Query().Before("gorm:query").Register(readOnlyCallbackName, rejectNonSelect),
+		cb.Row().Before("gorm:row").Register(readOnlyCallbackName, rejectNonSelect),
+	)
+}
+
+func isReadOnlySession(tx *gorm.DB) bool {
+	v, ok := tx.Get(readOnlySettingKey)
+	return ok && v == true
+}
+
+// isReadSQL 判断原生 SQL 是否为只读语句。
+func isReadSQL(sql string) bool {
+	sql = strings.TrimLeft(sql, " \t\r\n(")
+	end := strings.IndexFunc(sql, func(r rune) bool {
+		return r == ' ' || r == '\t' || r == '\r' || r == '\n' || r == '('
+	})
+	if end >= 0 {
+		sql = sql[:end]
+	}
+	switch strings.ToUpper(sql) {
+	case "SELECT", "WITH", "SHOW", "EXPLAIN", "DESCRIBE", "DESC":
+		return true
+	}
+	return false
+}
+
+// -----------------------------------------------------------------------------
+// Etcd
+// -----------------------------------------------------------------------------
+
+// readOnlyEtcd 返回共享原连接的只读客户端。
+//
+// 返回的客户端基于 clientv3.NewCtxClient 构造，仅替换 KV 接口，其他接口复用原客户端。
+// 它不拥有底层连接，不应调用其 Close()。
+func readOnlyEtcd(client *clientv3.Client) *clientv3.Client {
+	ro := clientv3.NewCtxClient(client.Ctx())
+	ro.Cluster = client.Cluster
+	ro.KV = &readOnlyKV{KV: client.KV}
+	ro.Lease = client.Lease
+	ro.Watcher = client.Watcher
+	ro.Auth = client.Auth
+	ro.Maintenance = client.Maintenance
+	return ro
+}
+
+// readOnlyKV 拒绝写操作的 KV 包装。
+type readOnlyKV struct {
+	clientv3.KV
+}
+
+func (kv *readOnlyKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	return nil, xerrors.Wrapf(ErrReadOnly, "etcd put %s", key)
+}
+
+func (kv *readOnlyKV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	return nil, xerrors.Wrapf(ErrReadOnly, "etcd delete %s", key)
+}
+
+func (kv *readOnlyKV) Compact(ctx context.Context, rev int64, opts ...clientv3.CompactOption) (*clientv3.CompactResponse, error) {
+	return nil, xerrors.Wrap(ErrReadOnly, "etcd compact")
+}
+
+func (kv *readOnlyKV) Do(ctx context.Context, op clientv3.Op) (clientv3.OpResponse, error) {
+	if isEtcdWriteOp(op) {
+		return clientv3.OpResponse{}, xerrors.Wrapf(ErrReadOnly, "etcd op on %s", string(op.KeyBytes()))
+	}
+	return kv.KV.Do(ctx, op)
+}
+
+func (kv *readOnlyKV) Txn(ctx context.Context) clientv3.Txn {
+	return &readOnlyTxn{Txn: kv.KV.Txn(ctx)}
+}
+
+// readOnlyTxn 在 Commit 时拒绝包含写操作的事务。
+type readOnlyTxn struct {
+	clientv3.Txn
+	write bool
+}
+
+func (t *readOnlyTxn) If(cs ...clientv3.Cmp) clientv3.Txn {
+	t.Txn = t.Txn.If(cs...)
+	return t
+}
+
+func (t *readOnlyTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.write = t.write || hasEtcdWriteOp(ops)
+	t.Txn = t.Txn.Then(ops...)
+	return t
+}
+
+func (t *readOnlyTxn) Else(ops ...clientv3.Op) clientv3.Txn {
+	t.write = t.write || hasEtcdWriteOp(ops)
+	t.Txn = t.Txn.Else(ops...)
+	return t
+}
+
+func (t *readOnlyTxn) Commit() (*clientv3.TxnResponse, error) {
+	if t.write {
+		return nil, xerrors.Wrap(ErrReadOnly, "etcd txn with write ops")
+	}
+	return t.Txn.Commit()
+}
+
+func isEtcdWriteOp(op clientv3.Op) bool {
+	if op.IsPut() || op.IsDelete() {
+		return true
+	}
+	if op.IsTxn() {
+		_, thenOps, elseOps := op.Txn()
+		return hasEtcdWriteOp(thenOps) || hasEtcdWriteOp(elseOps)
+	}
+	return false
+}
+
+func hasEtcdWriteOp(ops []clientv3.Op) bool {
+	for _, op := range ops {
+		if isEtcdWriteOp(op) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+type readOnlyUser struct {
+	ID   uint
+	Name string
+}
+
+// TestReadOnly_SQLite 测试 gorm 只读包装
+func TestReadOnly_SQLite(t *testing.T) {
+	conn, err := NewSQLite(&SQLiteConfig{Name: "ro-sqlite", Path: "file:readonly?mode=memory&cache=shared"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ro, err := ReadOnly(conn)
+	require.NoError(t, err)
+	require.Nil(t, ro.GetClient(), "未连接时应返回 nil")
+
+	ctx := context.Background()
+	require.NoError(t, ro.Connect(ctx))
+	require.True(t, conn.IsHealthy(), "Connect 应透传到原连接器")
+
+	// 原连接器可写
+	db := conn.GetClient()
+	require.NoError(t, db.AutoMigrate(&readOnlyUser{}))
+	require.NoError(t, db.Create(&readOnlyUser{Name: "alice"}).Error)
+
+	roDB := ro.GetClient()
+	require.NotNil(t, roDB)
+	require.Same(t, roDB, ro.GetClient(), "同一底层客户端应复用包装结果")
+
+	// 读操作正常
+	var users []readOnlyUser
+	require.NoError(t, roDB.Find(&users).Error)
+	require.Len(t, users, 1)
+
+	var count int64
+	require.NoError(t, roDB.Raw("SELECT COUNT(*) FROM read_only_users").Scan(&count).Error)
+	require.Equal(t, int64(1), count)
+
+	// 写操作返回 ErrReadOnly
+	require.ErrorIs(t, roDB.Create(&readOnlyUser{Name: "bob"}).Error, ErrReadOnly)
+	require.ErrorIs(t, roDB.Model(&readOnlyUser{}).Where("id = ?", 1).Update("name", "eve").Error, ErrReadOnly)
+	require.ErrorIs(t, roDB.Delete(&readOnlyUser{}, 1).Error, ErrReadOnly)
+	require.ErrorIs(t, roDB.Exec("INSERT INTO read_only_users (name) VALUES ('mallory')").Error, ErrReadOnly)
+	require.ErrorIs(t, roDB.Raw("DELETE FROM read_only_users").Scan(&users).Error, ErrReadOnly)
+
+	// 原连接器不受只读 callback 影响
+	require.NoError(t, db.Create(&readOnlyUser{Name: "carol"}).Error)
+	require.NoError(t, db.Model(&readOnlyUser{}).Count(&count).Error)
+	require.Equal(t, int64(2), count)
+}
+
+// TestReadOnly_Unsupported 测试不支持只读包装的连接器
+func TestReadOnly_Unsupported(t *testing.T) {
+	conn, err := NewNATS(&NATSConfig{Name: "ro-nats", URL: "nats://localhost:4222"})
+	require.NoError(t, err)
+
+	_, err = ReadOnly(conn)
+	require.ErrorIs(t, err, ErrConfig)
+}
+
+// TestReadOnly_RedisHook 测试 Redis 写命令在发出前即被拦截
+func TestReadOnly_RedisHook(t *testing.T) {
+	// 不依赖真实 Redis：写命令在 Hook 中被拒绝，不会发起网络请求
+	client := readOnlyRedis(redis.NewClient(&redis.Options{Addr: "localhost:6379"}))
+	defer client.Close()
+	ctx := context.Background()
+
+	require.ErrorIs(t, client.Set(ctx, "k", "v", time.Minute).Err(), ErrReadOnly)
+	require.ErrorIs(t, client.Del(ctx, "k").Err(), ErrReadOnly)
+	require.ErrorIs(t, client.Eval(ctx, "return 1", nil).Err(), ErrReadOnly)
+
+	pipe := client.Pipeline()
+	pipe.Get(ctx, "k")
+	pipe.HSet(ctx, "h", "f", "v")
+	_, err := pipe.Exec(ctx)
+	require.ErrorIs(t, err, ErrReadOnly)
+}
+
+// TestReadOnly_EtcdKV 测试 Etcd KV 只读包装
+func TestReadOnly_EtcdKV(t *testing.T) {
+	fake := &fakeKV{}
+	kv := &readOnlyKV{KV: fake}
+	ctx := context.Background()
+
+	_, err := kv.Get(ctx, "/k")
+	require.NoError(t, err)
+	require.Equal(t, 1, fake.gets)
+
+	_, err = kv.Put(ctx, "/k", "v")
+	require.ErrorIs(t, err, ErrReadOnly)
+	_, err = kv.Delete(ctx, "/k")
+	require.ErrorIs(t, err, ErrReadOnly)
+	_, err = kv.Compact(ctx, 1)
+	require.ErrorIs(t, err, ErrReadOnly)
+	_, err = kv.Do(ctx, clientv3.OpPut("/k", "v"))
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	_, err = kv.Txn(ctx).Then(clientv3.OpGet("/k")).Else(clientv3.OpDelete("/k")).Commit()
+	require.ErrorIs(t, err, ErrReadOnly)
+	_, err = kv.Txn(ctx).Then(clientv3.OpTxn(nil, []clientv3.Op{clientv3.OpPut("/k", "v")}, nil)).Commit()
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	_, err = kv.Txn(ctx).Then(clientv3.OpGet("/k")).Commit()
+	require.NoError(t, err)
+	require.Equal(t, 0, fake.puts, "写操作不应到达底层 KV")
+}
+
+// TestReadOnly_Integration 测试 Redis/Etcd 只读包装下读操作正常
+func TestReadOnly_Integration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+	ctx := context.Background()
+
+	t.Run("Redis", func(t *testing.T) {
+		container, cfg := setupRedisContainer(t)
+		defer container.Terminate(ctx)
+
+		conn, err := NewRedis(cfg, WithLogger(getTestLogger()))
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.Connect(ctx))
+
+		key := "test:readonly:" + newTestID()
+		require.NoError(t, conn.GetClient().Set(ctx, key, "v", time.Minute).Err())
+
+		ro, err := ReadOnly(conn)
+		require.NoError(t, err)
+		client := ro.GetClient()
+
+		val, err := client.Get(ctx, key).Result()
+		require.NoError(t, err)
+		require.Equal(t, "v", val)
+		require.ErrorIs(t, client.Set(ctx, key, "x", time.Minute).Err(), ErrReadOnly)
+
+		val, err = conn.GetClient().Get(ctx, key).Result()
+		require.NoError(t, err)
+		require.Equal(t, "v", val)
+	})
+
+	t.Run("Etcd", func(t *testing.T) {
+		container, cfg := setupEtcdContainer(t)
+		defer container.Terminate(ctx)
+
+		conn, err := NewEtcd(cfg, WithLogger(getTestLogger()))
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.Connect(ctx))
+
+		key := "/test/readonly/" + newTestID()
+		_, err = conn.GetClient().Put(ctx, key, "v")
+		require.NoError(t, err)
+
+		ro, err := ReadOnly(conn)
+		require.NoError(t, err)
+		client := ro.GetClient()
+
+		resp, err := client.Get(ctx, key)
+		require.NoError(t, err)
+		require.Len(t, resp.Kvs, 1)
+		require.Equal(t, "v", string(resp.Kvs[0].Value))
+
+		_, err = client.Put(ctx, key, "x")
+		require.ErrorIs(t, err, ErrReadOnly)
+	})
+}
+
+// fakeKV 记录调用次数的 KV 实现
+type fakeKV struct {
+	clientv3.KV
+	gets int
+	puts int
+}
+
+func (f *fakeKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	f.gets++
+	return &clientv3.GetResponse{}, nil
+}
+
+func (f *fakeKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	f.puts++
+	return &clientv3.PutResponse{}, nil
+}
+
+func (f *fakeKV) Txn(ctx context.Context) clientv3.Txn {
+	return &fakeTxn{}
+}
+
+type fakeTxn struct{}
+
+func (t *fakeTxn) If(cs ...clientv3.Cmp) clientv3.Txn     { return t }
+func (t *fakeTxn) Then(ops ...clientv3.Op) clientv3.Txn   { return t }
+func (t *fakeTxn) Else(ops ...clientv3.Op) clientv3.Txn   { return t }
+func (t *fakeTxn) Commit() (*clientv3.TxnResponse, error) { return &clientv3.TxnResponse{}, nil }
@@ -19,6 +19,11 @@ type redisConnector struct {
 	logger  clog.Logger
 	healthy atomic.Bool
 	mu      sync.RWMutex
+	stats   statsTracker
+	slow    slowLog
+	warmup  int
+	// inflight 统计进行中的命令，供 Shutdown 等待
+	inflight inflight
 }
 
 // NewRedis 创建 Redis 连接器
@@ -37,13 +42,17 @@ func NewRedis(cfg *RedisConfig, opts ...Option) (RedisConnector, error) {
 	c := &redisConnector{
 		cfg:    cfg,
 		logger: opt.logger.With(clog.String("connector", "redis"), clog.String("name", cfg.Name)),
+		warmup: opt.warmup,
 	}
+	c.slow = slowLog{threshold: opt.slowThreshold, logger: c.logger}
 
 	return c, nil
 }
 
 // Connect 建立连接
-func (c *redisConnector) Connect(ctx context.Context) error {
+func (c *redisConnector) Connect(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -69,6 +78,11 @@ func (c *redisConnector) Connect(ctx context.Context) error {
 		},
 	})
 
+	client.AddHook(inflightRedisHook{f: &c.inflight})
+	if c.slow.threshold > 0 {
+		client.AddHook(slowRedisHook{slow: &c.slow})
+	}
+
 	// 启用 Tracing
 	if c.cfg.EnableTracing {
 		if err := redisotel.InstrumentTracing(client); err != nil {
@@ -85,9 +99,17 @@ func (c *redisConnector) Connect(ctx context.Context) error {
 	}
 
 	c.client = client
+	c.inflight.reset()
 	c.healthy.Store(true)
+	c.stats.connected()
 	c.logger.Info("successfully connected to redis", clog.String("addr", c.cfg.Addr))
 
+	if c.warmup > 0 {
+		if err := warmupRedis(ctx, c.cfg.Name, client, c.warmup); err != nil {
+			c.logger.Warn("connection pool warmup failed", clog.Int("conns", c.warmup), clog.Error(err))
+		}
+	}
+
 	return nil
 }
 
@@ -97,6 +119,7 @@ func (c *redisConnector) Close() error {
 	defer c.mu.Unlock()
 
 	c.healthy.Store(false)
+	c.stats.disconnected()
 
 	if c.client == nil {
 		return nil
@@ -114,8 +137,15 @@ func (c *redisConnector) Close() error {
 	return nil
 }
 
+// Shutdown 优雅关闭：拒绝新命令，等待进行中的命令在 ctx 内完成后关闭连接
+func (c *redisConnector) Shutdown(ctx context.Context) error {
+	return shutdownTracked(ctx, "redis", c.cfg.Name, c.logger, &c.inflight, c.Close)
+}
+
 // HealthCheck 检查连接健康状态
-func (c *redisConnector) HealthCheck(ctx context.Context) error {
+func (c *redisConnector) HealthCheck(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.RLock()
 	client := c.client
 	c.mu.RUnlock()
@@ -145,6 +175,27 @@ func (c *redisConnector) Name() string {
 	return c.cfg.Name
 }
 
+// Stats 返回连接器运行时统计
+func (c *redisConnector) Stats() ConnStats {
+	s := c.stats.snapshot(TypeRedis, c.cfg.Name, c.IsHealthy())
+	if client := c.GetClient(); client != nil {
+		ps := client.PoolStats()
+		s.Pool = &PoolStats{
+			MaxOpen:   c.cfg.PoolSize,
+			Open:      int(ps.TotalConns),
+			InUse:     int(ps.TotalConns) - int(ps.IdleConns),
+			Idle:      int(ps.IdleConns),
+			WaitCount: int64(ps.WaitCount),
+		}
+	}
+	return s
+}
+
+// slowLogger 返回慢操作日志配置，供 ObserveSlow 使用
+func (c *redisConnector) slowLogger() *slowLog {
+	return &c.slow
+}
+
 // GetClient 返回 Redis 客户端
 func (c *redisConnector) GetClient() *redis.Client {
 	c.mu.RLock()
@@ -0,0 +1,121 @@
+package connector
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/go-viper/mapstructure/v2"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// 内置连接器类型，可直接用于 Create。
+const (
+	TypeRedis      = "redis"
+	TypeMySQL      = "mysql"
+	TypePostgreSQL = "postgresql"
+	TypeSQLite     = "sqlite"
+	TypeEtcd       = "etcd"
+	TypeNATS       = "nats"
+	TypeKafka      = "kafka"
+)
+
+// Factory 根据配置 map 创建连接器。
+//
+// cfg 的 key 与对应 XxxConfig 的 mapstructure 标签一致，
+// 例如 redis 使用 {"addr": "127.0.0.1:6379", "db": 1}。
+type Factory func(cfg map[string]any, opts ...Option) (Connector, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{
+		TypeRedis:      newFromMap(NewRedis),
+		TypeMySQL:      newFromMap(NewMySQL),
+		TypePostgreSQL: newFromMap(NewPostgreSQL),
+		TypeSQLite:     newFromMap(NewSQLite),
+		TypeEtcd:       newFromMap(NewEtcd),
+		TypeNATS:       newFromMap(NewNATS),
+		TypeKafka:      newFromMap(NewKafka),
+	}
+)
+
+// Register 注册指定类型的连接器工厂。
+//
+// 通常在 init 中调用。typ 为空、factory 为 nil 或 typ 已注册时 panic，
+// 与 database/sql.Register 的约定一致。
+func Register(typ string, factory Factory) {
+	if typ == "" {
+		panic("connector: Register type is empty")
+	}
+	if factory == nil {
+		panic("connector: Register factory is nil for type " + typ)
+	}
+
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if _, exists := factories[typ]; exists {
+		panic("connector: Register called twice for type " + typ)
+	}
+	factories[typ] = factory
+}
+
+// Create 按类型创建连接器。
+//
+// 与 NewXXX 一致，Create 只创建连接器而不建立连接，需要调用方再调用 Connect()。
+// 返回的 Connector 可通过类型断言转换为具体接口，例如 conn.(connector.RedisConnector)。
+//
+// 返回错误：
+//   - ErrUnknownType: typ 未注册
+//   - ErrConfig: 配置无法解析或校验失败
+func Create(typ string, cfg map[string]any, opts ...Option) (Connector, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[typ]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, xerrors.Wrapf(ErrUnknownType, "type: %s", typ)
+	}
+	return factory(cfg, opts...)
+}
+
+// Types 返回已注册的连接器类型（按字典序）。
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	types := make([]string, 0, len(factories))
+	for typ := range factories {
+		types = append(types, typ)
+	}
+	slices.Sort(types)
+	return types
+}
+
+// newFromMap 将 NewXXX 构造函数适配为 Factory。
+func newFromMap[C any, T Connector](newFn func(cfg *C, opts ...Option) (T, error)) Factory {
+	return func(raw map[string]any, opts ...Option) (Connector, error) {
+		cfg := new(C)
+		if err := decodeConfig(raw, cfg); err != nil {
+			return nil, err
+		}
+		return newFn(cfg, opts...)
+	}
+}
+
+// decodeConfig 将配置 map 解码到配置结构体，支持 "5s" 形式的时长和逗号分隔的字符串切片。
+func decodeConfig(raw map[string]any, out any) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		WeaklyTypedInput: true,
+		Result:           out,
+	})
+	if err != nil {
+		return xerrors.Wrap(err, "create config decoder failed")
+	}
+	if err := decoder.Decode(raw); err != nil {
+		return xerrors.Wrapf(ErrConfig, "decode config: %v", err)
+	}
+	return nil
+}
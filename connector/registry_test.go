@@ -0,0 +1,147 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate_BuiltinTypes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		typ    string
+		cfg    map[string]any
+		assert func(t *testing.T, conn Connector)
+	}{
+		{
+			typ: TypeRedis,
+			cfg: map[string]any{"name": "cache", "addr": "127.0.0.1:6379", "db": "1", "dial_timeout": "3s"},
+			assert: func(t *testing.T, conn Connector) {
+				redisConn, ok := conn.(*redisConnector)
+				require.True(t, ok)
+				require.Equal(t, 1, redisConn.cfg.DB)
+				require.Equal(t, "3s", redisConn.cfg.DialTimeout.String())
+			},
+		},
+		{
+			typ: TypeMySQL,
+			cfg: map[string]any{"host": "127.0.0.1", "username": "root", "database": "app"},
+			assert: func(t *testing.T, conn Connector) {
+				_, ok := conn.(MySQLConnector)
+				require.True(t, ok)
+			},
+		},
+		{
+			typ: TypePostgreSQL,
+			cfg: map[string]any{"host": "127.0.0.1", "username": "postgres", "database": "app"},
+			assert: func(t *testing.T, conn Connector) {
+				_, ok := conn.(PostgreSQLConnector)
+				require.True(t, ok)
+			},
+		},
+		{
+			typ: TypeSQLite,
+			cfg: map[string]any{"path": "file::memory:?cache=shared"},
+			assert: func(t *testing.T, conn Connector) {
+				_, ok := conn.(SQLiteConnector)
+				require.True(t, ok)
+			},
+		},
+		{
+			typ: TypeEtcd,
+			cfg: map[string]any{"endpoints": []string{"127.0.0.1:2379"}},
+			assert: func(t *testing.T, conn Connector) {
+				_, ok := conn.(EtcdConnector)
+				require.True(t, ok)
+			},
+		},
+		{
+			typ: TypeNATS,
+			cfg: map[string]any{"url": "nats://127.0.0.1:4222"},
+			assert: func(t *testing.T, conn Connector) {
+				_, ok := conn.(NATSConnector)
+				require.True(t, ok)
+			},
+		},
+		{
+			typ: TypeKafka,
+			cfg: map[string]any{"seed": "127.0.0.1:9092,127.0.0.1:9093"},
+			assert: func(t *testing.T, conn Connector) {
+				kafkaConn, ok := conn.(*kafkaConnector)
+				require.True(t, ok)
+				require.Equal(t, []string{"127.0.0.1:9092", "127.0.0.1:9093"}, kafkaConn.cfg.Seed)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ, func(t *testing.T) {
+			t.Parallel()
+
+			conn, err := Create(tt.typ, tt.cfg)
+			require.NoError(t, err)
+			require.NotNil(t, conn)
+			tt.assert(t, conn)
+			require.NoError(t, conn.Close())
+		})
+	}
+}
+
+func TestCreate_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unknown type", func(t *testing.T) {
+		t.Parallel()
+		conn, err := Create("mongodb", map[string]any{})
+		require.ErrorIs(t, err, ErrUnknownType)
+		require.Nil(t, conn)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		t.Parallel()
+		conn, err := Create(TypeRedis, map[string]any{})
+		require.ErrorIs(t, err, ErrConfig)
+		require.Nil(t, conn)
+	})
+
+	t.Run("undecodable config", func(t *testing.T) {
+		t.Parallel()
+		conn, err := Create(TypeRedis, map[string]any{"addr": "127.0.0.1:6379", "dial_timeout": "soon"})
+		require.ErrorIs(t, err, ErrConfig)
+		require.Nil(t, conn)
+	})
+}
+
+// stubConnector 自定义连接器类型，用于验证注册流程
+type stubConnector struct {
+	name string
+}
+
+func (s *stubConnector) Connect(context.Context) error     { return nil }
+func (s *stubConnector) Close() error                      { return nil }
+func (s *stubConnector) HealthCheck(context.Context) error { return nil }
+func (s *stubConnector) IsHealthy() bool                   { return true }
+func (s *stubConnector) Name() string                      { return s.name }
+func (s *stubConnector) Stats() ConnStats                  { return ConnStats{Name: s.name} }
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	Register("stub", func(cfg map[string]any, opts ...Option) (Connector, error) {
+		name, _ := cfg["name"].(string)
+		return &stubConnector{name: name}, nil
+	})
+	require.Contains(t, Types(), "stub")
+
+	conn, err := Create("stub", map[string]any{"name": "custom"})
+	require.NoError(t, err)
+	require.Equal(t, "custom", conn.Name())
+
+	require.Panics(t, func() {
+		Register("stub", func(map[string]any, ...Option) (Connector, error) { return nil, nil })
+	})
+	require.Panics(t, func() { Register("", nil) })
+	require.Panics(t, func() { Register("nil-factory", nil) })
+}
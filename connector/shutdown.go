@@ -0,0 +1,226 @@
+package connector
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// GracefulCloser 支持优雅关闭的连接器
+//
+// 内置连接器均实现该接口：
+//   - Redis、MySQL、PostgreSQL、SQLite、Etcd 统计进行中的操作，Shutdown 后新操作返回 ErrClosing；
+//   - NATS 使用 Drain 处理完已投递的消息、发出缓冲中的消息；
+//   - Kafka 使用 Flush 等待缓冲中的消息发送完成。
+type GracefulCloser interface {
+	// Shutdown 停止接受新操作，等待进行中的操作在 ctx 内完成后关闭连接。
+	//
+	// ctx 结束时强制关闭，返回的错误包含 ctx.Err() 与未完成的操作数，不会永久阻塞。
+	Shutdown(ctx context.Context) error
+}
+
+// Shutdown 优雅关闭连接器，conn 未实现 GracefulCloser 时直接调用 Close
+func Shutdown(ctx context.Context, conn Connector) error {
+	if gc, ok := conn.(GracefulCloser); ok {
+		return gc.Shutdown(ctx)
+	}
+	return conn.Close()
+}
+
+// Shutdown 按添加顺序的逆序优雅关闭所有连接器，共享同一个 ctx
+func (c *Container) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	order := c.order
+	conns := c.conns
+	c.order = nil
+	c.conns = make(map[containerKey]Connector)
+	c.mu.Unlock()
+
+	var errs []error
+	for _, key := range slices.Backward(order) {
+		if err := Shutdown(ctx, conns[key]); err != nil {
+			errs = append(errs, xerrors.Wrapf(err, "shutdown %s connector %q", key.typ, key.name))
+		}
+	}
+	return xerrors.Combine(errs...)
+}
+
+// inflight 统计连接器上进行中的操作（内部使用）
+type inflight struct {
+	mu       sync.Mutex
+	count    int
+	draining bool
+	idle     chan struct{} // draining 期间计数归零时关闭
+}
+
+// begin 登记一个新操作，正在关闭时返回 false
+func (f *inflight) begin() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.draining {
+		return false
+	}
+	f.count++
+	return true
+}
+
+// end 结束一个由 begin 登记的操作
+func (f *inflight) end() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.count--
+	if f.count == 0 && f.idle != nil {
+		close(f.idle)
+		f.idle = nil
+	}
+}
+
+// drain 停止接受新操作并等待进行中的操作完成，ctx 结束时返回剩余操作数
+func (f *inflight) drain(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	f.draining = true
+	if f.count == 0 {
+		f.mu.Unlock()
+		return 0, nil
+	}
+	if f.idle == nil {
+		f.idle = make(chan struct{})
+	}
+	idle := f.idle
+	f.mu.Unlock()
+
+	select {
+	case <-idle:
+		return 0, nil
+	case <-ctx.Done():
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		return f.count, ctx.Err()
+	}
+}
+
+// reset 重新接受新操作，在 Connect 成功后调用
+func (f *inflight) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.draining = false
+}
+
+// shutdownTracked 等待进行中的操作完成后调用 closeFn，超时则强制关闭并报告未完成数
+func shutdownTracked(ctx context.Context, typ, name string, logger clog.Logger, f *inflight, closeFn func() error) error {
+	pending, waitErr := f.drain(ctx)
+	closeErr := closeFn()
+	if waitErr == nil {
+		return closeErr
+	}
+	logger.Warn("shutdown timed out, force closed", clog.Int("inflight", pending), clog.Error(waitErr))
+	return xerrors.Combine(
+		xerrors.Wrapf(waitErr, "%s connector[%s]: %d operations still in flight, force closed", typ, name, pending),
+		closeErr,
+	)
+}
+
+// -----------------------------------------------------------------------------
+// Redis
+// -----------------------------------------------------------------------------
+
+// inflightRedisHook 统计进行中命令的 Redis Hook
+type inflightRedisHook struct {
+	f *inflight
+}
+
+func (h inflightRedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h inflightRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !h.f.begin() {
+			err := xerrors.Wrapf(ErrClosing, "redis command %s", cmd.Name())
+			cmd.SetErr(err)
+			return err
+		}
+		defer h.f.end()
+		return next(ctx, cmd)
+	}
+}
+
+func (h inflightRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !h.f.begin() {
+			err := xerrors.Wrap(ErrClosing, "redis pipeline")
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		defer h.f.end()
+		return next(ctx, cmds)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// GORM (MySQL / PostgreSQL / SQLite)
+// -----------------------------------------------------------------------------
+
+const (
+	inflightCallbackName = "genesis:inflight"
+	inflightInstanceKey  = "genesis:connector:inflight"
+)
+
+// registerInflightCallbacks 在每条语句前后登记进行中的操作
+//
+// 只统计单条语句，事务在语句之间不计入，Shutdown 可能在两条语句之间关闭连接；
+// Rows()、Raw().Scan() 在返回 *sql.Rows 后迭代结果，迭代期间同样不计入。
+func registerInflightCallbacks(db *gorm.DB, f *inflight) error {
+	before := func(tx *gorm.DB) {
+		if !f.begin() {
+			_ = tx.AddError(xerrors.Wrapf(ErrClosing, "statement on table %s", tx.Statement.Table))
+			return
+		}
+		tx.InstanceSet(inflightInstanceKey, true)
+	}
+	after := func(tx *gorm.DB) {
+		if _, ok := tx.InstanceGet(inflightInstanceKey); ok {
+			f.end()
+		}
+	}
+
+	cb := db.Callback()
+	return xerrors.Combine(
+		cb.Create().Before("*").Register(inflightCallbackName+":before", before),
+		cb.Create().After("*").Register(inflightCallbackName+":after", after),
+		cb.Query().Before("*").Register(inflightCallbackName+":before", before),
+		cb.Query().After("*").Register(inflightCallbackName+":after", after),
+		cb.Update().Before("*").Register(inflightCallbackName+":before", before),
+		cb.Update().After("*").Register(inflightCallbackName+":after", after),
+		cb.Delete().Before("*").Register(inflightCallbackName+":before", before),
+		cb.Delete().After("*").Register(inflightCallbackName+":after", after),
+		cb.Row().Before("*").Register(inflightCallbackName+":before", before),
+		cb.Row().After("*").Register(inflightCallbackName+":after", after),
+		cb.Raw().Before("*").Register(inflightCallbackName+":before", before),
+		cb.Raw().After("*").Register(inflightCallbackName+":after", after),
+	)
+}
+
+// -----------------------------------------------------------------------------
+// Etcd
+// -----------------------------------------------------------------------------
+
+// inflightUnaryInterceptor 统计进行中 gRPC 一元调用的客户端拦截器，Watch 等流式调用不计入
+func inflightUnaryInterceptor(f *inflight) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !f.begin() {
+			return xerrors.Wrapf(ErrClosing, "etcd %s", method)
+		}
+		defer f.end()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
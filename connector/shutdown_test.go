@@ -0,0 +1,146 @@
+package connector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// slowStatement 在 SQLite 中执行数百毫秒的递归查询并写表
+//
+// 使用 Exec 而非 Raw().Scan()：后者在 Row callback 返回 *sql.Rows 后才迭代结果，迭代期间不计入进行中的操作。
+const slowStatement = `CREATE TABLE slow AS WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c WHERE x < 1000000) SELECT count(*) AS n FROM c`
+
+// TestInflight 测试进行中操作的计数与等待
+func TestInflight(t *testing.T) {
+	t.Run("等待进行中的操作完成", func(t *testing.T) {
+		var f inflight
+		require.True(t, f.begin())
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			f.end()
+		}()
+
+		pending, err := f.drain(context.Background())
+		require.NoError(t, err)
+		require.Zero(t, pending)
+		require.False(t, f.begin(), "drain 后拒绝新操作")
+
+		f.reset()
+		require.True(t, f.begin(), "reset 后重新接受新操作")
+		f.end()
+	})
+
+	t.Run("超时返回剩余操作数", func(t *testing.T) {
+		var f inflight
+		require.True(t, f.begin())
+		require.True(t, f.begin())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		pending, err := f.drain(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, 2, pending)
+	})
+}
+
+// TestSQLiteShutdown 测试 SQLite 连接器在慢语句进行中时优雅关闭
+func TestSQLiteShutdown(t *testing.T) {
+	newConn := func(t *testing.T) *sqliteConnector {
+		conn, err := NewSQLite(&SQLiteConfig{Name: "shutdown", Path: t.TempDir() + "/shutdown.db"})
+		require.NoError(t, err)
+		require.NoError(t, conn.Connect(context.Background()))
+		return conn.(*sqliteConnector)
+	}
+
+	// startSlowStatement 启动慢语句，等待其进入执行后返回，语句结束时向 channel 写入错误
+	startSlowStatement := func(t *testing.T, conn *sqliteConnector) <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			done <- conn.GetClient().Exec(slowStatement).Error
+		}()
+		require.Eventually(t, func() bool {
+			conn.inflight.mu.Lock()
+			defer conn.inflight.mu.Unlock()
+			return conn.inflight.count == 1
+		}, time.Second, time.Millisecond)
+		return done
+	}
+
+	t.Run("等待慢语句完成后关闭", func(t *testing.T) {
+		conn := newConn(t)
+		db := conn.GetClient() // 业务方通常在启动时取出客户端并长期持有
+		done := startSlowStatement(t, conn)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		require.NoError(t, conn.Shutdown(ctx))
+
+		select {
+		case err := <-done:
+			require.NoError(t, err, "慢语句正常完成")
+		default:
+			t.Fatal("Shutdown 返回时慢语句应已完成")
+		}
+
+		var n int
+		err := db.Raw("SELECT 1").Scan(&n).Error
+		require.ErrorIs(t, err, ErrClosing, "关闭后新操作被拒绝")
+	})
+
+	t.Run("超时强制关闭并报告未完成数", func(t *testing.T) {
+		conn := newConn(t)
+		done := startSlowStatement(t, conn)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		err := conn.Shutdown(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Less(t, time.Since(start), 200*time.Millisecond, "超时后立即返回，不等待慢语句")
+		require.Contains(t, err.Error(), "1 operations still in flight")
+		<-done
+	})
+
+	t.Run("重新 Connect 后恢复", func(t *testing.T) {
+		conn := newConn(t)
+		require.NoError(t, conn.Shutdown(context.Background()))
+		require.NoError(t, conn.Connect(context.Background()))
+
+		var n int
+		require.NoError(t, conn.GetClient().Raw("SELECT 1").Scan(&n).Error)
+		require.NoError(t, conn.Close())
+	})
+}
+
+// closeOnlyConnector 未实现 GracefulCloser 的连接器
+type closeOnlyConnector struct {
+	Connector
+	closed bool
+}
+
+func (c *closeOnlyConnector) Close() error {
+	c.closed = true
+	return nil
+}
+
+// TestShutdownFallback 测试未实现 GracefulCloser 时退化为 Close，以及 Container 的优雅关闭
+func TestShutdownFallback(t *testing.T) {
+	c := &closeOnlyConnector{}
+	require.NoError(t, Shutdown(context.Background(), c))
+	require.True(t, c.closed)
+
+	container := NewContainer()
+	conn, err := NewSQLite(&SQLiteConfig{Name: "shutdown", Path: t.TempDir() + "/shutdown.db"})
+	require.NoError(t, err)
+	require.NoError(t, conn.Connect(context.Background()))
+	require.NoError(t, container.Add(conn))
+	require.NoError(t, container.Shutdown(context.Background()))
+
+	_, err = container.GetSQLite("shutdown")
+	require.True(t, xerrors.Is(err, ErrNotFound))
+}
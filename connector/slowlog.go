@@ -0,0 +1,125 @@
+package connector
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+)
+
+// =============================================================================
+// 慢操作日志
+// =============================================================================
+
+// WithSlowThreshold 记录耗时超过 d 的操作（Warn 日志，含操作名与耗时）
+//
+// 各连接器的接入方式：
+//   - Redis：go-redis Hook，覆盖单条命令与 Pipeline
+//   - Etcd：gRPC 客户端拦截器，覆盖 KV、Lease、Watch 建立等一元调用
+//   - NATS、Kafka：客户端没有通用 Hook，通过 ObserveSlow 包装需要观测的调用
+//
+// MySQL/PostgreSQL/SQLite 的慢 SQL 由 db 组件的日志负责，连接器忽略该选项。
+func WithSlowThreshold(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.slowThreshold = d
+		}
+	}
+}
+
+// ObserveSlow 执行 fn，耗时超过连接器的 WithSlowThreshold 时记录慢操作日志
+//
+// 用于没有 Hook 机制的客户端（如 NATS 的 Request、Flush），op 为日志中的操作名。
+// 连接器未设置阈值或不支持慢日志时只执行 fn。
+//
+// 使用示例：
+//
+//	err := connector.ObserveSlow(natsConn, "nats request orders.create", func() error {
+//	    _, err := natsConn.GetClient().RequestWithContext(ctx, "orders.create", data)
+//	    return err
+//	})
+func ObserveSlow(conn Connector, op string, fn func() error) error {
+	observer, ok := conn.(interface{ slowLogger() *slowLog })
+	if !ok {
+		return fn()
+	}
+	start := time.Now()
+	err := fn()
+	observer.slowLogger().observe(op, start, err)
+	return err
+}
+
+// slowLog 慢操作判定与日志输出，threshold 为 0 时不记录
+type slowLog struct {
+	threshold time.Duration
+	logger    clog.Logger
+}
+
+// observe 计算从 start 起的耗时，超过阈值时记录 Warn 日志
+func (s *slowLog) observe(op string, start time.Time, err error) {
+	if s.threshold <= 0 {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < s.threshold {
+		return
+	}
+	fields := []clog.Field{
+		clog.String("op", op),
+		clog.Duration("duration", elapsed),
+		clog.Duration("threshold", s.threshold),
+	}
+	if err != nil {
+		fields = append(fields, clog.Error(err))
+	}
+	s.logger.Warn("slow operation", fields...)
+}
+
+// -----------------------------------------------------------------------------
+// Redis
+// -----------------------------------------------------------------------------
+
+// slowRedisHook 记录慢命令的 Redis Hook
+type slowRedisHook struct {
+	slow *slowLog
+}
+
+func (h slowRedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h slowRedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.slow.observe("redis "+cmd.Name(), start, err)
+		return err
+	}
+}
+
+func (h slowRedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.slow.observe("redis pipeline("+strconv.Itoa(len(cmds))+" cmds)", start, err)
+		return err
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Etcd
+// -----------------------------------------------------------------------------
+
+// slowUnaryInterceptor 记录慢 gRPC 一元调用的客户端拦截器，op 为完整方法名
+func slowUnaryInterceptor(slow *slowLog) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		slow.observe("etcd "+method, start, err)
+		return err
+	}
+}
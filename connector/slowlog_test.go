@@ -0,0 +1,123 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+// newFileLogger 创建输出到临时文件的 JSON 日志，返回读取已写入日志的函数
+func newFileLogger(t *testing.T) (clog.Logger, func() string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "connector.log")
+	logger, err := clog.New(&clog.Config{Level: "info", Format: "json", Output: path})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = logger.Close() })
+
+	return logger, func() string {
+		logger.Flush()
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(data)
+	}
+}
+
+func TestSlowThreshold(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Redis Hook", func(t *testing.T) {
+		logger, logs := newFileLogger(t)
+		conn, err := NewRedis(&RedisConfig{Name: "slow-redis", Addr: "localhost:6379"},
+			WithLogger(logger), WithSlowThreshold(20*time.Millisecond))
+		require.NoError(t, err)
+		hook := slowRedisHook{slow: conn.(*redisConnector).slowLogger()}
+
+		process := hook.ProcessHook(func(context.Context, redis.Cmder) error {
+			time.Sleep(30 * time.Millisecond)
+			return nil
+		})
+		require.NoError(t, process(ctx, redis.NewStringCmd(ctx, "get", "k")))
+		fast := hook.ProcessHook(func(context.Context, redis.Cmder) error { return nil })
+		require.NoError(t, fast(ctx, redis.NewStringCmd(ctx, "set", "k", "v")))
+
+		pipeline := hook.ProcessPipelineHook(func(context.Context, []redis.Cmder) error {
+			time.Sleep(30 * time.Millisecond)
+			return nil
+		})
+		require.NoError(t, pipeline(ctx, []redis.Cmder{redis.NewStringCmd(ctx, "get", "a"), redis.NewStringCmd(ctx, "get", "b")}))
+
+		out := logs()
+		require.Equal(t, 2, strings.Count(out, "slow operation"))
+		require.Contains(t, out, `"op":"redis get"`)
+		require.Contains(t, out, `"op":"redis pipeline(2 cmds)"`)
+		require.Contains(t, out, `"duration"`)
+		require.NotContains(t, out, "redis set", "快操作不记录")
+	})
+
+	t.Run("Etcd 拦截器", func(t *testing.T) {
+		logger, logs := newFileLogger(t)
+		conn, err := NewEtcd(&EtcdConfig{Name: "slow-etcd", Endpoints: []string{"localhost:2379"}},
+			WithLogger(logger), WithSlowThreshold(20*time.Millisecond))
+		require.NoError(t, err)
+		interceptor := slowUnaryInterceptor(conn.(*etcdConnector).slowLogger())
+
+		invokeErr := errors.New("deadline exceeded")
+		slow := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			time.Sleep(30 * time.Millisecond)
+			return invokeErr
+		}
+		fast := func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error { return nil }
+
+		require.ErrorIs(t, interceptor(ctx, "/etcdserverpb.KV/Range", nil, nil, nil, slow), invokeErr)
+		require.NoError(t, interceptor(ctx, "/etcdserverpb.KV/Put", nil, nil, nil, fast))
+
+		out := logs()
+		require.Equal(t, 1, strings.Count(out, "slow operation"))
+		require.Contains(t, out, `"op":"etcd /etcdserverpb.KV/Range"`)
+		require.Contains(t, out, "deadline exceeded", "慢操作附带错误")
+		require.NotContains(t, out, "KV/Put")
+	})
+
+	t.Run("NATS 包装", func(t *testing.T) {
+		logger, logs := newFileLogger(t)
+		conn, err := NewNATS(&NATSConfig{Name: "slow-nats", URL: "nats://localhost:4222"},
+			WithLogger(logger), WithSlowThreshold(20*time.Millisecond))
+		require.NoError(t, err)
+
+		require.NoError(t, ObserveSlow(conn, "nats request orders.create", func() error {
+			time.Sleep(30 * time.Millisecond)
+			return nil
+		}))
+		require.NoError(t, ObserveSlow(conn, "nats publish orders.created", func() error { return nil }))
+
+		out := logs()
+		require.Equal(t, 1, strings.Count(out, "slow operation"))
+		require.Contains(t, out, `"op":"nats request orders.create"`)
+		require.NotContains(t, out, "orders.created")
+	})
+
+	t.Run("未设置阈值不记录", func(t *testing.T) {
+		logger, logs := newFileLogger(t)
+		conn, err := NewNATS(&NATSConfig{Name: "plain-nats", URL: "nats://localhost:4222"}, WithLogger(logger))
+		require.NoError(t, err)
+
+		called := false
+		require.NoError(t, ObserveSlow(conn, "nats request", func() error {
+			called = true
+			time.Sleep(10 * time.Millisecond)
+			return nil
+		}))
+		require.True(t, called)
+		require.NotContains(t, logs(), "slow operation")
+	})
+}
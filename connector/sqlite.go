@@ -18,6 +18,10 @@ type sqliteConnector struct {
 	logger  clog.Logger
 	healthy atomic.Bool
 	mu      sync.RWMutex
+	stats   statsTracker
+	warmup  int
+	// inflight 统计进行中的语句，供 Shutdown 等待
+	inflight inflight
 }
 
 // NewSQLite 创建 SQLite 连接器
@@ -36,13 +40,16 @@ func NewSQLite(cfg *SQLiteConfig, opts ...Option) (SQLiteConnector, error) {
 	c := &sqliteConnector{
 		cfg:    cfg,
 		logger: opt.logger.With(clog.String("connector", "sqlite"), clog.String("name", cfg.Name)),
+		warmup: opt.warmup,
 	}
 
 	return c, nil
 }
 
 // Connect 建立连接
-func (c *sqliteConnector) Connect(ctx context.Context) error {
+func (c *sqliteConnector) Connect(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -65,15 +72,28 @@ func (c *sqliteConnector) Connect(ctx context.Context) error {
 		return xerrors.Wrapf(ErrConnection, "sqlite connector[%s]: failed to get db instance: %v", c.cfg.Name, err)
 	}
 
+	if err := registerInflightCallbacks(db, &c.inflight); err != nil {
+		sqlDB.Close()
+		return xerrors.Wrapf(ErrConnection, "sqlite connector[%s]: register inflight callbacks: %v", c.cfg.Name, err)
+	}
+
 	if err := sqlDB.PingContext(ctx); err != nil {
 		c.logger.Error("failed to ping sqlite", clog.Error(err))
 		return xerrors.Wrapf(ErrConnection, "sqlite connector[%s]: ping failed: %v", c.cfg.Name, err)
 	}
 
 	c.db = db
+	c.inflight.reset()
 	c.healthy.Store(true)
+	c.stats.connected()
 	c.logger.Info("successfully connected to sqlite", clog.String("path", c.cfg.Path))
 
+	if c.warmup > 0 {
+		if err := warmupGorm(ctx, c.cfg.Name, db, c.warmup); err != nil {
+			c.logger.Warn("connection pool warmup failed", clog.Int("conns", c.warmup), clog.Error(err))
+		}
+	}
+
 	return nil
 }
 
@@ -84,6 +104,7 @@ func (c *sqliteConnector) Close() error {
 
 	c.logger.Info("closing sqlite connection")
 	c.healthy.Store(false)
+	c.stats.disconnected()
 
 	if c.db == nil {
 		return nil
@@ -108,8 +129,15 @@ func (c *sqliteConnector) Close() error {
 	return nil
 }
 
+// Shutdown 优雅关闭：拒绝新语句，等待进行中的语句在 ctx 内完成后关闭连接
+func (c *sqliteConnector) Shutdown(ctx context.Context) error {
+	return shutdownTracked(ctx, "sqlite", c.cfg.Name, c.logger, &c.inflight, c.Close)
+}
+
 // HealthCheck 检查连接健康状态
-func (c *sqliteConnector) HealthCheck(ctx context.Context) error {
+func (c *sqliteConnector) HealthCheck(ctx context.Context) (err error) {
+	defer func() { c.stats.observe(err) }()
+
 	c.mu.RLock()
 	db := c.db
 	c.mu.RUnlock()
@@ -145,6 +173,13 @@ func (c *sqliteConnector) Name() string {
 	return c.cfg.Name
 }
 
+// Stats 返回连接器运行时统计
+func (c *sqliteConnector) Stats() ConnStats {
+	s := c.stats.snapshot(TypeSQLite, c.cfg.Name, c.IsHealthy())
+	s.Pool = gormPoolStats(c.GetClient())
+	return s
+}
+
 // GetClient 返回 GORM 客户端
 func (c *sqliteConnector) GetClient() *gorm.DB {
 	c.mu.RLock()
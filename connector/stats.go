@@ -0,0 +1,143 @@
+package connector
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ConnStats 连接器运行时统计，用于 admin 端点自省。
+type ConnStats struct {
+	// Name 连接器名称
+	Name string `json:"name"`
+	// Type 连接器类型，与 Create 使用的类型一致（如 "redis"）
+	Type string `json:"type"`
+	// Connected 当前是否已建立连接
+	Connected bool `json:"connected"`
+	// Healthy 缓存的健康状态，同 IsHealthy()
+	Healthy bool `json:"healthy"`
+	// ConnectedAt 最近一次建立连接的时间，未连接时为零值
+	ConnectedAt time.Time `json:"connected_at,omitzero"`
+	// Uptime 已连接时长，未连接时为 0；JSON 中单位为纳秒
+	Uptime time.Duration `json:"uptime"`
+	// Pool 连接池使用情况，无连接池的客户端（Etcd、NATS、Kafka）为 nil
+	Pool *PoolStats `json:"pool,omitempty"`
+	// ErrorCount 累计错误数（Connect 与 HealthCheck 失败）
+	ErrorCount uint64 `json:"error_count"`
+	// LastError 最近一次错误信息
+	LastError string `json:"last_error,omitempty"`
+	// LastErrorAt 最近一次错误时间
+	LastErrorAt time.Time `json:"last_error_at,omitzero"`
+}
+
+// PoolStats 连接池使用情况。
+type PoolStats struct {
+	// MaxOpen 连接池上限，0 表示不限制
+	MaxOpen int `json:"max_open"`
+	// Open 当前连接总数
+	Open int `json:"open"`
+	// InUse 正在使用的连接数
+	InUse int `json:"in_use"`
+	// Idle 空闲连接数
+	Idle int `json:"idle"`
+	// WaitCount 累计等待空闲连接的次数
+	WaitCount int64 `json:"wait_count"`
+}
+
+// StatsHandler 返回连接器统计 handler。
+//
+// 以 JSON 数组输出 conns 中每个连接器的 Stats()，顺序与参数一致。
+// 只读取内存中的统计，不产生 I/O，适合挂在 admin 端点上。
+func StatsHandler(conns ...Connector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		stats := make([]ConnStats, 0, len(conns))
+		for _, conn := range conns {
+			stats = append(stats, conn.Stats())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(stats)
+	})
+}
+
+// statsTracker 记录连接时间与错误，供各连接器的 Stats() 使用。
+type statsTracker struct {
+	mu          sync.Mutex
+	connectedAt time.Time
+	errorCount  uint64
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// connected 记录连接建立
+func (t *statsTracker) connected() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.connectedAt = time.Now()
+}
+
+// disconnected 记录连接关闭
+func (t *statsTracker) disconnected() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.connectedAt = time.Time{}
+}
+
+// observe 记录非 nil 错误
+func (t *statsTracker) observe(err error) {
+	if err == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errorCount++
+	t.lastError = err.Error()
+	t.lastErrorAt = time.Now()
+}
+
+// snapshot 生成不含连接池信息的统计
+func (t *statsTracker) snapshot(typ, name string, healthy bool) ConnStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := ConnStats{
+		Name:        name,
+		Type:        typ,
+		Connected:   !t.connectedAt.IsZero(),
+		Healthy:     healthy,
+		ConnectedAt: t.connectedAt,
+		ErrorCount:  t.errorCount,
+		LastError:   t.lastError,
+		LastErrorAt: t.lastErrorAt,
+	}
+	if s.Connected {
+		s.Uptime = time.Since(t.connectedAt)
+	}
+	return s
+}
+
+// gormPoolStats 读取 gorm 底层 database/sql 连接池统计
+func gormPoolStats(db *gorm.DB) *PoolStats {
+	if db == nil {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil
+	}
+	return sqlPoolStats(sqlDB.Stats())
+}
+
+func sqlPoolStats(s sql.DBStats) *PoolStats {
+	return &PoolStats{
+		MaxOpen:   s.MaxOpenConnections,
+		Open:      s.OpenConnections,
+		InUse:     s.InUse,
+		Idle:      s.Idle,
+		WaitCount: s.WaitCount,
+	}
+}
@@ -0,0 +1,100 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestSQLiteStats 测试连接生命周期内的统计变化
+func TestSQLiteStats(t *testing.T) {
+	conn, err := NewSQLite(&SQLiteConfig{Name: "stats-sqlite", Path: "file:stats?mode=memory&cache=shared"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// 未连接
+	stats := conn.Stats()
+	require.Equal(t, "stats-sqlite", stats.Name)
+	require.Equal(t, TypeSQLite, stats.Type)
+	require.False(t, stats.Connected)
+	require.Nil(t, stats.Pool)
+	require.Zero(t, stats.Uptime)
+
+	// 未连接时健康检查失败计入错误
+	require.Error(t, conn.HealthCheck(context.Background()))
+	stats = conn.Stats()
+	require.EqualValues(t, 1, stats.ErrorCount)
+	require.NotEmpty(t, stats.LastError)
+	require.False(t, stats.LastErrorAt.IsZero())
+
+	// 已连接
+	require.NoError(t, conn.Connect(context.Background()))
+	require.NoError(t, conn.HealthCheck(context.Background()))
+	time.Sleep(time.Millisecond)
+	stats = conn.Stats()
+	require.True(t, stats.Connected)
+	require.True(t, stats.Healthy)
+	require.False(t, stats.ConnectedAt.IsZero())
+	require.Positive(t, stats.Uptime)
+	require.NotNil(t, stats.Pool)
+	require.GreaterOrEqual(t, stats.Pool.Open, 1)
+	require.EqualValues(t, 1, stats.ErrorCount)
+
+	// 关闭后
+	require.NoError(t, conn.Close())
+	stats = conn.Stats()
+	require.False(t, stats.Connected)
+	require.False(t, stats.Healthy)
+	require.Zero(t, stats.Uptime)
+}
+
+// TestRedisStats_ConnectError 测试连接失败累计错误数
+func TestRedisStats_ConnectError(t *testing.T) {
+	conn, err := NewRedis(&RedisConfig{Name: "stats-redis", Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	require.Error(t, conn.Connect(ctx))
+	require.Error(t, conn.Connect(ctx))
+
+	stats := conn.Stats()
+	require.Equal(t, TypeRedis, stats.Type)
+	require.False(t, stats.Connected)
+	require.EqualValues(t, 2, stats.ErrorCount)
+	require.NotEmpty(t, stats.LastError)
+}
+
+// TestStatsHandler 测试统计端点按参数顺序输出
+func TestStatsHandler(t *testing.T) {
+	first, err := NewSQLite(&SQLiteConfig{Name: "first", Path: "file:stats-first?mode=memory&cache=shared"})
+	require.NoError(t, err)
+	defer first.Close()
+	require.NoError(t, first.Connect(context.Background()))
+
+	second, err := NewEtcd(&EtcdConfig{Name: "second", Endpoints: []string{"localhost:2379"}})
+	require.NoError(t, err)
+	defer second.Close()
+
+	rec := httptest.NewRecorder()
+	StatsHandler(first, second).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var body []ConnStats
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body, 2)
+	require.Equal(t, "first", body[0].Name)
+	require.True(t, body[0].Connected)
+	require.NotNil(t, body[0].Pool)
+	require.Equal(t, "second", body[1].Name)
+	require.Equal(t, TypeEtcd, body[1].Type)
+	require.False(t, body[1].Connected)
+	require.Nil(t, body[1].Pool)
+}
@@ -0,0 +1,118 @@
+package connector
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// =============================================================================
+// 连接池预热
+// =============================================================================
+
+// Warmup 预先建立 n 个连接并放回连接池，避免冷启动后首批请求承担建连延迟。
+//
+// 应在 Connect 成功后调用。n 超过连接池上限时按上限预热：
+//   - Redis：上限为 PoolSize，预热后的连接作为空闲连接保留
+//   - MySQL/PostgreSQL/SQLite：上限为 MaxOpenConns，空闲连接最多保留 MaxIdleConns 个
+//
+// 其他类型的连接器（Etcd、NATS、Kafka）返回 ErrConfig；未连接时返回 ErrClientNil。
+// 也可以在创建连接器时传入 WithWarmup，由 Connect 自动预热。
+//
+// 使用示例：
+//
+//	if err := conn.Connect(ctx); err != nil {
+//	    return err
+//	}
+//	if err := connector.Warmup(ctx, conn, 10); err != nil {
+//	    logger.Warn("warmup failed", clog.Error(err))
+//	}
+func Warmup[T any](ctx context.Context, conn TypedConnector[T], n int) error {
+	if conn == nil {
+		return xerrors.Wrap(ErrConfig, "warmup: connector is nil")
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	switch client := any(conn.GetClient()).(type) {
+	case *redis.Client:
+		if client == nil {
+			return xerrors.Wrapf(ErrClientNil, "warmup: connector[%s]", conn.Name())
+		}
+		return warmupRedis(ctx, conn.Name(), client, n)
+	case *gorm.DB:
+		if client == nil {
+			return xerrors.Wrapf(ErrClientNil, "warmup: connector[%s]", conn.Name())
+		}
+		return warmupGorm(ctx, conn.Name(), client, n)
+	default:
+		return xerrors.Wrapf(ErrConfig, "warmup: unsupported connector %s", conn.Name())
+	}
+}
+
+// WithWarmup 在 Connect 成功后预先建立 n 个连接（仅 Redis、MySQL、PostgreSQL、SQLite 有效）
+//
+// 预热失败不影响 Connect 的结果，只记录 Warn 日志。
+func WithWarmup(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.warmup = n
+		}
+	}
+}
+
+// warmupRedis 同时占用 n 个连接并各 Ping 一次，释放后连接回到空闲池。
+func warmupRedis(ctx context.Context, name string, client *redis.Client, n int) error {
+	if size := client.Options().PoolSize; size > 0 {
+		n = min(n, size)
+	}
+
+	conns := make([]*redis.Conn, 0, n)
+	defer func() {
+		for _, cn := range conns {
+			_ = cn.Close()
+		}
+	}()
+	for range n {
+		cn := client.Conn()
+		conns = append(conns, cn)
+		if err := cn.Ping(ctx).Err(); err != nil {
+			return xerrors.Wrapf(ErrConnection, "warmup: redis connector[%s]: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// warmupGorm 同时占用 n 个 database/sql 连接并各 Ping 一次，释放后连接回到空闲池。
+func warmupGorm(ctx context.Context, name string, db *gorm.DB, n int) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return xerrors.Wrapf(ErrConnection, "warmup: connector[%s]: failed to get db instance: %v", name, err)
+	}
+	if maxOpen := sqlDB.Stats().MaxOpenConnections; maxOpen > 0 {
+		n = min(n, maxOpen)
+	}
+
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, cn := range conns {
+			_ = cn.Close()
+		}
+	}()
+	for range n {
+		cn, err := sqlDB.Conn(ctx)
+		if err != nil {
+			return xerrors.Wrapf(ErrConnection, "warmup: connector[%s]: %v", name, err)
+		}
+		conns = append(conns, cn)
+		if err := cn.PingContext(ctx); err != nil {
+			return xerrors.Wrapf(ErrConnection, "warmup: connector[%s]: %v", name, err)
+		}
+	}
+	return nil
+}
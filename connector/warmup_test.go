@@ -0,0 +1,71 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestWarmup 测试连接池预热后空闲连接数达到预期，首次查询不新建连接
+func TestWarmup(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("显式预热", func(t *testing.T) {
+		conn, err := NewSQLite(&SQLiteConfig{Name: "warmup", Path: t.TempDir() + "/warmup.db"})
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.Connect(ctx))
+
+		sqlDB, err := conn.GetClient().DB()
+		require.NoError(t, err)
+		sqlDB.SetMaxIdleConns(4)
+
+		require.NoError(t, Warmup(ctx, conn, 4))
+		pool := conn.Stats().Pool
+		require.Equal(t, 4, pool.Open)
+		require.Equal(t, 4, pool.Idle)
+
+		var n int
+		require.NoError(t, conn.GetClient().Raw("SELECT 1").Scan(&n).Error)
+		after := conn.Stats().Pool
+		require.Equal(t, 4, after.Open, "首次查询复用预热连接")
+		require.Equal(t, 4, after.Idle)
+	})
+
+	t.Run("超过连接池上限时按上限预热", func(t *testing.T) {
+		conn, err := NewSQLite(&SQLiteConfig{Name: "warmup-cap", Path: t.TempDir() + "/warmup.db"})
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.Connect(ctx))
+
+		sqlDB, err := conn.GetClient().DB()
+		require.NoError(t, err)
+		sqlDB.SetMaxOpenConns(2)
+
+		require.NoError(t, Warmup(ctx, conn, 10))
+		require.Equal(t, 2, conn.Stats().Pool.Idle)
+	})
+
+	t.Run("WithWarmup 在 Connect 时预热", func(t *testing.T) {
+		// database/sql 默认最多保留 2 个空闲连接
+		conn, err := NewSQLite(&SQLiteConfig{Name: "warmup-opt", Path: t.TempDir() + "/warmup.db"}, WithWarmup(2))
+		require.NoError(t, err)
+		defer conn.Close()
+		require.NoError(t, conn.Connect(ctx))
+		require.Equal(t, 2, conn.Stats().Pool.Idle)
+	})
+
+	t.Run("错误场景", func(t *testing.T) {
+		conn, err := NewSQLite(&SQLiteConfig{Path: t.TempDir() + "/warmup.db"})
+		require.NoError(t, err)
+		require.ErrorIs(t, Warmup(ctx, conn, 1), ErrClientNil)
+
+		natsConn, err := NewNATS(&NATSConfig{URL: "nats://127.0.0.1:4222"})
+		require.NoError(t, err)
+		require.ErrorIs(t, Warmup(ctx, natsConn, 1), ErrConfig)
+
+		var nilConn SQLiteConnector
+		require.ErrorIs(t, Warmup(ctx, nilConn, 1), ErrConfig)
+	})
+}
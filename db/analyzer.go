@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+const (
+	analyzerPluginName = "genesis:query_analyzer"
+	analyzerStartKey   = "genesis:query_analyzer:start"
+
+	// defaultRepeatThreshold 同一 SQL 模板在单个作用域内的默认告警次数
+	defaultRepeatThreshold = 5
+)
+
+// AnalyzerOption 配置查询分析器的选项
+type AnalyzerOption func(*analyzerOptions)
+
+type analyzerOptions struct {
+	repeatThreshold int
+	explainSlow     time.Duration
+}
+
+// WithRepeatThreshold 设置疑似 N+1 的告警阈值
+//
+// 同一 SQL 模板在单个查询作用域内执行次数达到 n 时输出告警，默认 5。
+func WithRepeatThreshold(n int) AnalyzerOption {
+	return func(o *analyzerOptions) {
+		if n > 1 {
+			o.repeatThreshold = n
+		}
+	}
+}
+
+// WithExplainSlowQuery 对耗时超过 threshold 的查询自动执行 EXPLAIN 并记录结果
+func WithExplainSlowQuery(threshold time.Duration) AnalyzerOption {
+	return func(o *analyzerOptions) {
+		if threshold > 0 {
+			o.explainSlow = threshold
+		}
+	}
+}
+
+// queryScopeKey 查询作用域在 context 中的 key
+type queryScopeKey struct{}
+
+// explainKey 标记分析器自身发起的 EXPLAIN 查询，避免递归分析
+type explainKey struct{}
+
+// queryScope 单个请求内的 SQL 模板执行计数
+type queryScope struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// WithQueryScope 为 ctx 开启一个查询作用域
+//
+// 查询分析器按作用域统计同一 SQL 模板的执行次数，通常在 HTTP/gRPC 入口为每个请求调用一次。
+// 未开启作用域的查询不参与 N+1 检测。
+func WithQueryScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, queryScopeKey{}, &queryScope{counts: make(map[string]int)})
+}
+
+// hit 记录一次执行，返回该模板在作用域内的累计次数
+func (s *queryScope) hit(sql string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[sql]++
+	return s.counts[sql]
+}
+
+// queryAnalyzer 调试用 GORM 插件：检测疑似 N+1 查询，并对慢查询执行 EXPLAIN
+type queryAnalyzer struct {
+	logger clog.Logger
+	opts   analyzerOptions
+}
+
+func newQueryAnalyzer(logger clog.Logger, opts ...AnalyzerOption) *queryAnalyzer {
+	o := analyzerOptions{repeatThreshold: defaultRepeatThreshold}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &queryAnalyzer{logger: logger, opts: o}
+}
+
+// Name 实现 gorm.Plugin
+func (a *queryAnalyzer) Name() string {
+	return analyzerPluginName
+}
+
+// Initialize 实现 gorm.Plugin，在查询类回调前后注册分析逻辑
+func (a *queryAnalyzer) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register(analyzerPluginName+":before_query", a.before); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().After("gorm:query").Register(analyzerPluginName+":after_query", a.after); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register(analyzerPluginName+":before_row", a.before); err != nil {
+		return err
+	}
+	return db.Callback().Row().After("gorm:row").Register(analyzerPluginName+":after_row", a.after)
+}
+
+func (a *queryAnalyzer) before(db *gorm.DB) {
+	db.InstanceSet(analyzerStartKey, time.Now())
+}
+
+func (a *queryAnalyzer) after(db *gorm.DB) {
+	if db.Error != nil || db.Statement == nil {
+		return
+	}
+	ctx := db.Statement.Context
+	if ctx == nil || ctx.Value(explainKey{}) != nil {
+		return
+	}
+
+	sql := db.Statement.SQL.String()
+	if sql == "" {
+		return
+	}
+
+	if scope, ok := ctx.Value(queryScopeKey{}).(*queryScope); ok {
+		// 只在首次达到阈值时告警，避免循环内刷屏
+		if count := scope.hit(sql); count == a.opts.repeatThreshold {
+			a.logger.WarnContext(ctx, "possible N+1 query detected",
+				clog.String("sql", sql),
+				clog.Int("count", count),
+			)
+		}
+	}
+
+	if a.opts.explainSlow <= 0 {
+		return
+	}
+	start, ok := db.InstanceGet(analyzerStartKey)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(start.(time.Time))
+	if elapsed < a.opts.explainSlow {
+		return
+	}
+	a.explain(ctx, db, sql, elapsed)
+}
+
+// explain 对慢查询执行 EXPLAIN 并记录结果，失败只记录 debug 日志
+func (a *queryAnalyzer) explain(ctx context.Context, db *gorm.DB, sql string, elapsed time.Duration) {
+	explainCtx := context.WithValue(ctx, explainKey{}, true)
+	rows, err := db.Session(&gorm.Session{NewDB: true, Context: explainCtx}).
+		Raw("EXPLAIN "+sql, db.Statement.Vars...).Rows()
+	if err != nil {
+		a.logger.DebugContext(ctx, "explain slow query failed", clog.String("sql", sql), clog.Error(err))
+		return
+	}
+	defer rows.Close()
+
+	plan, err := formatRows(rows)
+	if err != nil {
+		a.logger.DebugContext(ctx, "explain slow query failed", clog.String("sql", sql), clog.Error(err))
+		return
+	}
+
+	a.logger.WarnContext(ctx, "slow query explain",
+		clog.String("sql", sql),
+		clog.Duration("duration", elapsed),
+		clog.String("plan", plan),
+	)
+}
+
+// sqlRows 抽象 *sql.Rows，便于格式化 EXPLAIN 结果
+type sqlRows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+}
+
+// formatRows 将 EXPLAIN 结果格式化为逐行的 "col=value" 文本
+func formatRows(rows sqlRows) (string, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", err
+		}
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		for i, col := range columns {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			v := values[i]
+			if raw, ok := v.([]byte); ok {
+				v = string(raw)
+			}
+			fmt.Fprintf(&b, "%s=%v", col, v)
+		}
+	}
+	return b.String(), rows.Err()
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/testkit"
+)
+
+// AnalyzerOrder 查询分析器测试用的订单模型
+type AnalyzerOrder struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint
+}
+
+func newAnalyzerTestDB(t *testing.T, opts ...AnalyzerOption) (DB, func() []map[string]any) {
+	t.Helper()
+
+	logPath := filepath.Join(t.TempDir(), "db.log")
+	logger, err := clog.New(&clog.Config{Level: "warn", Format: "json", Output: logPath})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = logger.Close() })
+
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(testkit.NewSQLiteConnector(t)),
+		WithLogger(logger),
+		WithSilentMode(),
+		WithQueryAnalyzer(opts...),
+	)
+	require.NoError(t, err)
+
+	gormDB := database.DB(context.Background())
+	require.NoError(t, gormDB.Migrator().CreateTable(&AnalyzerOrder{}))
+	t.Cleanup(func() { _ = gormDB.Migrator().DropTable(&AnalyzerOrder{}) })
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, gormDB.Create(&AnalyzerOrder{UserID: uint(i)}).Error)
+	}
+
+	readLogs := func() []map[string]any {
+		logger.Flush()
+		data, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+
+		var entries []map[string]any
+		for line := range strings.SplitSeq(strings.TrimSpace(string(data)), "\n") {
+			if line == "" {
+				continue
+			}
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+	return database, readLogs
+}
+
+func countMessages(entries []map[string]any, msg string) int {
+	n := 0
+	for _, entry := range entries {
+		if entry["msg"] == msg {
+			n++
+		}
+	}
+	return n
+}
+
+func TestQueryAnalyzer_NPlusOne(t *testing.T) {
+	t.Run("同一作用域内重复查询触发告警", func(t *testing.T) {
+		database, readLogs := newAnalyzerTestDB(t, WithRepeatThreshold(3))
+		ctx := WithQueryScope(context.Background())
+
+		for i := 1; i <= 5; i++ {
+			var order AnalyzerOrder
+			require.NoError(t, database.DB(ctx).Where("user_id = ?", i).First(&order).Error)
+		}
+
+		entries := readLogs()
+		require.Equal(t, 1, countMessages(entries, "possible N+1 query detected"))
+		for _, entry := range entries {
+			if entry["msg"] == "possible N+1 query detected" {
+				require.Contains(t, entry["sql"], "user_id = ?")
+				require.EqualValues(t, 3, entry["count"])
+			}
+		}
+	})
+
+	t.Run("单次查询不触发告警", func(t *testing.T) {
+		database, readLogs := newAnalyzerTestDB(t, WithRepeatThreshold(3))
+		ctx := WithQueryScope(context.Background())
+
+		var orders []AnalyzerOrder
+		require.NoError(t, database.DB(ctx).Where("user_id IN ?", []int{1, 2, 3, 4, 5}).Find(&orders).Error)
+		require.Len(t, orders, 5)
+
+		require.Zero(t, countMessages(readLogs(), "possible N+1 query detected"))
+	})
+
+	t.Run("不同作用域分别计数", func(t *testing.T) {
+		database, readLogs := newAnalyzerTestDB(t, WithRepeatThreshold(3))
+
+		for i := 1; i <= 4; i++ {
+			ctx := WithQueryScope(context.Background())
+			var order AnalyzerOrder
+			require.NoError(t, database.DB(ctx).Where("user_id = ?", i).First(&order).Error)
+		}
+
+		require.Zero(t, countMessages(readLogs(), "possible N+1 query detected"))
+	})
+}
+
+func TestQueryAnalyzer_ExplainSlowQuery(t *testing.T) {
+	database, readLogs := newAnalyzerTestDB(t, WithExplainSlowQuery(time.Nanosecond))
+
+	var order AnalyzerOrder
+	require.NoError(t, database.DB(context.Background()).Where("user_id = ?", 1).First(&order).Error)
+
+	entries := readLogs()
+	require.Equal(t, 1, countMessages(entries, "slow query explain"))
+	for _, entry := range entries {
+		if entry["msg"] == "slow query explain" {
+			require.NotEmpty(t, entry["plan"])
+		}
+	}
+}
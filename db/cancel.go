@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+const (
+	cancelerPluginName = "genesis:query_canceler"
+	cancelerWatchKey   = "genesis:query_canceler:watch"
+
+	// cancelQueryTimeout 发送 KILL/cancel 语句的超时时间
+	cancelQueryTimeout = 5 * time.Second
+)
+
+// queryCanceler GORM 插件：ctx 取消或超时后在数据库端终止正在执行的查询
+//
+// database/sql 在 ctx 结束时只会中断客户端等待并丢弃连接，服务端的查询仍会继续执行。
+// 该插件在执行前固定一条连接并记录其服务端 ID，ctx 结束时通过另一条连接发送
+// KILL QUERY（MySQL）或 pg_cancel_backend（PostgreSQL）。SQLite 驱动本身会在
+// ctx 取消时中断查询，无需处理。
+type queryCanceler struct {
+	logger  clog.Logger
+	dialect cancelDialect
+}
+
+// cancelDialect 不同数据库获取连接 ID 与取消查询的语句
+type cancelDialect struct {
+	backendID string
+	cancel    string
+}
+
+var cancelDialects = map[string]cancelDialect{
+	"mysql":    {backendID: "SELECT CONNECTION_ID()", cancel: "KILL QUERY %d"},
+	"postgres": {backendID: "SELECT pg_backend_pid()", cancel: "SELECT pg_cancel_backend(%d)"},
+}
+
+// queryWatch 单次查询的连接与取消监听状态
+type queryWatch struct {
+	pool gorm.ConnPool
+	conn *sql.Conn
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newQueryCanceler(logger clog.Logger) *queryCanceler {
+	return &queryCanceler{logger: logger}
+}
+
+// Name 实现 gorm.Plugin
+func (c *queryCanceler) Name() string {
+	return cancelerPluginName
+}
+
+// Initialize 实现 gorm.Plugin，在查询与原生 SQL 回调前后注册取消逻辑
+//
+// Row 回调返回的 *sql.Rows 由调用方在回调之外读取，连接无法在 after 中归还，因此不处理。
+func (c *queryCanceler) Initialize(db *gorm.DB) error {
+	dialect, ok := cancelDialects[db.Dialector.Name()]
+	if !ok {
+		return nil
+	}
+	c.dialect = dialect
+
+	if err := db.Callback().Query().Before("gorm:query").Register(cancelerPluginName+":before_query", c.before); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().After("gorm:query").Register(cancelerPluginName+":after_query", c.after); err != nil {
+		return err
+	}
+	if err := db.Callback().Raw().Before("gorm:raw").Register(cancelerPluginName+":before_raw", c.before); err != nil {
+		return err
+	}
+	return db.Callback().Raw().After("gorm:raw").Register(cancelerPluginName+":after_raw", c.after)
+}
+
+func (c *queryCanceler) before(db *gorm.DB) {
+	if db.Error != nil || db.Statement == nil || db.DryRun {
+		return
+	}
+	ctx := db.Statement.Context
+	if ctx == nil || ctx.Done() == nil || ctx.Err() != nil {
+		return
+	}
+	// 事务或已固定连接的语句不处理
+	pool, ok := db.Statement.ConnPool.(*sql.DB)
+	if !ok {
+		return
+	}
+
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return
+	}
+	var id int64
+	if err := conn.QueryRowContext(ctx, c.dialect.backendID).Scan(&id); err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	w := &queryWatch{
+		pool: db.Statement.ConnPool,
+		conn: conn,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go c.watch(ctx, pool, id, w)
+
+	db.Statement.ConnPool = conn
+	db.InstanceSet(cancelerWatchKey, w)
+}
+
+func (c *queryCanceler) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(cancelerWatchKey)
+	if !ok {
+		return
+	}
+	w := v.(*queryWatch)
+	close(w.stop)
+	<-w.done
+
+	db.Statement.ConnPool = w.pool
+	_ = w.conn.Close()
+}
+
+// watch 等待查询结束或 ctx 结束，后者通过连接池中的另一条连接取消服务端查询
+func (c *queryCanceler) watch(ctx context.Context, pool *sql.DB, id int64, w *queryWatch) {
+	defer close(w.done)
+
+	select {
+	case <-w.stop:
+		return
+	case <-ctx.Done():
+	}
+
+	cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cancelQueryTimeout)
+	defer cancel()
+	if _, err := pool.ExecContext(cancelCtx, fmt.Sprintf(c.dialect.cancel, id)); err != nil {
+		c.logger.WarnContext(ctx, "cancel query on server failed", clog.Int64("backend_id", id), clog.Error(err))
+		return
+	}
+	c.logger.DebugContext(ctx, "query canceled on server", clog.Int64("backend_id", id), clog.Error(ctx.Err()))
+}
@@ -28,6 +28,78 @@
 //		return tx.Create(&Order{UserID: 1001}).Error
 //	})
 //
+// 只读报表可以使用只读事务并指定隔离级别，注入 WithReadReplica 时只读事务路由到从库：
+//
+//	err = database.Transaction(ctx, fn, db.WithReadOnly(), db.WithIsolation(sql.LevelRepeatableRead))
+//
+// # 查询取消
+//
+// DB(ctx) 会把 ctx 透传给 GORM，最终走 database/sql 的 QueryContext/ExecContext。
+// 默认情况下 ctx 超时只会中断客户端等待，启用 WithCancelOnTimeout 后还会在数据库端
+// 终止仍在执行的查询，避免慢 SQL 在超时后继续占用数据库资源。
+//
+// # 分片键
+//
+// WithShardKey 把分片键绑定到 ctx，DB(ctx) 执行的查询、创建、更新、删除会自动带上
+// 该分片键条件或字段值，避免漏写分片键导致跨分片访问：
+//
+//	ctx = db.WithShardKey(ctx, "user_id", uid)
+//	database.DB(ctx).Find(&orders) // WHERE user_id = uid
+//
+// 分页查询分片表（模型实现 Sharded）时，Paginate 要求 ctx 已绑定对应分片键：
+//
+//	res, err := db.Paginate(ctx, database.DB(ctx).Order("id"), page, 20, &orders)
+//
+// # 批量 Upsert
+//
+// Upsert 基于 clause.OnConflict 实现"存在则更新、不存在则插入"，MySQL 生成
+// ON DUPLICATE KEY UPDATE，PostgreSQL / SQLite 生成 ON CONFLICT；分片表未绑定分片键时
+// 按记录的分片键值分组路由：
+//
+//	err := database.Upsert(ctx, &users, []string{"id"}, []string{"name", "email"})
+//
+// # 租户隔离
+//
+// 多租户共享表时，模型用 `gorm:"tenant"` 标记租户列（或实现 Tenanted 接口），
+// WithTenant 把租户 ID 绑定到 ctx 后，查询、更新、删除自动追加租户条件，创建自动填充
+// 租户列；ctx 未绑定租户时访问这些模型返回 ErrTenantRequired：
+//
+//	ctx = db.WithTenant(ctx, tenantID)
+//	database.DB(ctx).Find(&orders) // WHERE tenant_id = tenantID
+//
+// # 迁移锁
+//
+// 多实例同时启动并执行 AutoMigrate 时，DDL 可能互相冲突。WithMigrationLock 注入
+// MigrationLocker 后，AutoMigrate 会在全局分布式锁内执行，同一时刻只有一个实例迁移：
+//
+//	database, _ := db.New(cfg, db.WithMySQLConnector(conn), db.WithMigrationLock(locker))
+//	err := database.AutoMigrate(ctx, &User{}, &Order{})
+//
+// # 查询缓存
+//
+// WithQueryCache 注入 QueryCache（如 cache.Distributed）后，Cached 先读缓存，未命中再查询数据库并回填；
+// 写操作提交后通过 InvalidateCache 按 tag 失效相关条目：
+//
+//	err := database.Cached(ctx, "user:1001", time.Minute, &user, func(tx *gorm.DB) *gorm.DB {
+//		return tx.Where("id = ?", 1001)
+//	}, db.WithCacheTags("users"))
+//
+// # 乐观锁
+//
+// 模型字段标记 `gorm:"optimistic_lock"`（或实现 Versioned 接口）后，按主键更新该记录时
+// 自动追加 `WHERE version = ?` 并把版本号加一；记录已被其他会话修改时返回 xerrors.ErrConflict：
+//
+//	type Account struct {
+//		ID      uint
+//		Balance int64
+//		Version int64 `gorm:"optimistic_lock"`
+//	}
+//
+//	err := database.DB(ctx).Save(&account).Error
+//	if errors.Is(err, xerrors.ErrConflict) {
+//		// 重新读取后重试
+//	}
+//
 // # 资源所有权
 //
 // db 采用借用模型：connector 负责连接生命周期，db.Close() 为 no-op。
@@ -44,6 +116,8 @@ package db
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/uptrace/opentelemetry-go-extra/otelgorm"
 	"go.opentelemetry.io/otel/trace"
@@ -55,15 +129,27 @@ import (
 
 // database 是 DB 接口的实现
 type database struct {
-	client *gorm.DB
-	logger clog.Logger
-	tracer trace.Tracer
+	client        *gorm.DB
+	logger        clog.Logger
+	tracer        trace.Tracer
+	migrationLock MigrationLocker
+	queryCache    *queryCache
+	replica       *gorm.DB // 只读事务使用的从库，未注入时为 nil
 }
 
 // DB 定义了数据库组件的核心能力
 type DB interface {
 	DB(ctx context.Context) *gorm.DB
-	Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error
+	// Transaction 在事务中执行 fn，opts 设置只读与隔离级别（WithReadOnly / WithIsolation）
+	Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error, opts ...TxOption) error
+	// AutoMigrate 迁移表结构，注入 WithMigrationLock 时在分布式锁内执行
+	AutoMigrate(ctx context.Context, models ...any) error
+	// Cached 带缓存的读查询，未命中时执行 query(...).Find(dest) 并写入缓存
+	Cached(ctx context.Context, key string, ttl time.Duration, dest any, query func(*gorm.DB) *gorm.DB, opts ...CacheOption) error
+	// InvalidateCache 按 tag 失效 Cached 写入的缓存条目
+	InvalidateCache(ctx context.Context, tags ...string) error
+	// Upsert 批量插入，与 conflictColumns 冲突时更新 updateColumns（为空时更新全部非主键列）
+	Upsert(ctx context.Context, records any, conflictColumns []string, updateColumns []string) error
 	Close() error
 }
 
@@ -108,9 +194,59 @@ func New(cfg *Config, opts ...Option) (DB, error) {
 		return nil, xerrors.Wrapf(ErrInvalidConfig, "unknown driver: %s", cfg.Driver)
 	}
 
+	gormDB, err := setupClient(gormDB, &opt)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只读事务路由到从库，从库注册与主库相同的插件
+	var replica *gorm.DB
+	if opt.readReplica != nil {
+		if replica, err = setupClient(opt.readReplica.GetClient(), &opt); err != nil {
+			return nil, xerrors.Wrap(err, "read replica")
+		}
+	}
+
+	// 获取 tracer（用于后续可能的 span 创建）
+	var tracer trace.Tracer
+	if opt.tracer != nil {
+		tracer = opt.tracer.Tracer("github.com/ceyewan/genesis/db")
+	}
+
+	d := &database{
+		client:        gormDB,
+		logger:        opt.logger,
+		tracer:        tracer,
+		migrationLock: opt.migrationLock,
+		replica:       replica,
+	}
+	if opt.queryCache != nil {
+		d.queryCache = newQueryCache(opt.queryCache)
+	}
+	return d, nil
+}
+
+// setupClient 为连接器提供的 *gorm.DB 配置日志并注册插件
+func setupClient(gormDB *gorm.DB, opt *options) (*gorm.DB, error) {
 	// 配置 GORM logger
 	gormDB = gormDB.Session(&gorm.Session{Logger: newGormLogger(opt.logger, opt.silentMode)})
 
+	// 添加分片键注入插件，未通过 WithShardKey 绑定分片键的语句不受影响；
+	// 同一连接器上多次 New 时插件已注册，直接复用
+	if err := gormDB.Use(&shardInjector{}); err != nil && !errors.Is(err, gorm.ErrRegistered) {
+		return nil, xerrors.Wrap(err, "failed to register shard key plugin")
+	}
+
+	// 添加租户隔离插件，只对声明了租户列的模型生效
+	if err := gormDB.Use(&tenantIsolation{}); err != nil && !errors.Is(err, gorm.ErrRegistered) {
+		return nil, xerrors.Wrap(err, "failed to register tenant plugin")
+	}
+
+	// 添加乐观锁插件，只对声明了版本列的模型生效
+	if err := gormDB.Use(&optimisticLock{}); err != nil && !errors.Is(err, gorm.ErrRegistered) {
+		return nil, xerrors.Wrap(err, "failed to register optimistic lock plugin")
+	}
+
 	// 添加 OpenTelemetry trace 插件
 	if opt.tracer != nil {
 		if err := gormDB.Use(otelgorm.NewPlugin(
@@ -120,17 +256,21 @@ func New(cfg *Config, opts ...Option) (DB, error) {
 		}
 	}
 
-	// 获取 tracer（用于后续可能的 span 创建）
-	var tracer trace.Tracer
-	if opt.tracer != nil {
-		tracer = opt.tracer.Tracer("github.com/ceyewan/genesis/db")
+	// 添加查询分析插件（调试模式）
+	if opt.analyzerEnabled {
+		if err := gormDB.Use(newQueryAnalyzer(opt.logger, opt.analyzer...)); err != nil {
+			return nil, xerrors.Wrap(err, "failed to register query analyzer plugin")
+		}
+	}
+
+	// 添加查询取消插件
+	if opt.cancelOnTimeout {
+		if err := gormDB.Use(newQueryCanceler(opt.logger)); err != nil {
+			return nil, xerrors.Wrap(err, "failed to register query canceler plugin")
+		}
 	}
 
-	return &database{
-		client: gormDB,
-		logger: opt.logger,
-		tracer: tracer,
-	}, nil
+	return gormDB, nil
 }
 
 // DB 获取底层的 *gorm.DB 实例
@@ -139,10 +279,18 @@ func (d *database) DB(ctx context.Context) *gorm.DB {
 }
 
 // Transaction 执行事务操作
-func (d *database) Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error) error {
-	return d.client.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
-		return fn(ctx, tx)
-	})
+//
+// 未传 opts 时使用驱动默认的事务选项；WithReadOnly 的事务在注入 WithReadReplica 时路由到从库。
+func (d *database) Transaction(ctx context.Context, fn func(ctx context.Context, tx *gorm.DB) error, opts ...TxOption) error {
+	client, txOpts := d.client, applyTxOptions(opts)
+	if txOpts != nil && txOpts.ReadOnly && d.replica != nil {
+		client = d.replica
+	}
+	run := func(tx *gorm.DB) error { return fn(ctx, tx) }
+	if txOpts == nil {
+		return client.WithContext(ctx).Transaction(run)
+	}
+	return client.WithContext(ctx).Transaction(run, txOpts)
 }
 
 // Close 关闭组件
@@ -14,4 +14,22 @@ var (
 
 	// ErrSQLiteConnectorRequired SQLite 连接器未提供
 	ErrSQLiteConnectorRequired = xerrors.New("db: sqlite connector is required")
+
+	// ErrShardKeyMismatch 创建时字段值与 ctx 中的分片键不一致
+	ErrShardKeyMismatch = xerrors.New("db: shard key mismatch")
+
+	// ErrShardKeyRequired 分页查询分片表时 ctx 中未绑定分片键
+	ErrShardKeyRequired = xerrors.New("db: shard key required")
+
+	// ErrTenantRequired 访问按租户隔离的模型时 ctx 中未绑定租户
+	ErrTenantRequired = xerrors.New("db: tenant required")
+
+	// ErrTenantMismatch 创建时租户列的值与 ctx 中的租户不一致
+	ErrTenantMismatch = xerrors.New("db: tenant mismatch")
+
+	// ErrInvalidPage 分页参数无效（页码或每页条数小于 1）
+	ErrInvalidPage = xerrors.New("db: invalid page")
+
+	// ErrCacheMiss QueryCache 未命中，同时匹配 xerrors.ErrNotFound；自定义 QueryCache 实现可直接返回
+	ErrCacheMiss = xerrors.Wrap(xerrors.ErrNotFound, "db: query cache miss")
 )
@@ -2,10 +2,13 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
 	"gorm.io/gorm"
 
 	"github.com/ceyewan/genesis/testkit"
@@ -267,6 +270,106 @@ func TestDBConfigValidation(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// 查询取消测试
+// =============================================================================
+
+func TestDBMySQL_CancelOnTimeout(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	conn := testkit.NewMySQLConnector(t)
+	defer conn.Close()
+
+	database, err := New(&Config{Driver: "mysql"},
+		WithMySQLConnector(conn),
+		WithSilentMode(),
+		WithCancelOnTimeout(),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	var n int
+	err = database.DB(ctx).Raw("SELECT SLEEP(5)").Scan(&n).Error
+	elapsed := time.Since(start)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, elapsed, 3*time.Second, "query should return shortly after ctx timeout")
+
+	// 数据库端的 SLEEP 已被 KILL，不再占用线程
+	bg := context.Background()
+	require.Eventually(t, func() bool {
+		var running int64
+		err := database.DB(bg).Raw(
+			"SELECT COUNT(*) FROM information_schema.PROCESSLIST WHERE INFO LIKE 'SELECT SLEEP(5)%'",
+		).Scan(&running).Error
+		return err == nil && running == 0
+	}, 2*time.Second, 100*time.Millisecond)
+
+	// 连接池可继续使用
+	require.NoError(t, database.DB(bg).Raw("SELECT 1").Scan(&n).Error)
+	assert.Equal(t, 1, n)
+}
+
+func TestDBPostgreSQL_CancelOnTimeout(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	conn := testkit.NewPostgreSQLConnector(t)
+	defer conn.Close()
+
+	database, err := New(&Config{Driver: "postgresql"},
+		WithPostgreSQLConnector(conn),
+		WithSilentMode(),
+		WithCancelOnTimeout(),
+	)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err = database.DB(ctx).Exec("SELECT pg_sleep(5)").Error
+	elapsed := time.Since(start)
+
+	require.Error(t, err)
+	assert.Less(t, elapsed, 3*time.Second, "query should return shortly after ctx timeout")
+
+	bg := context.Background()
+	require.Eventually(t, func() bool {
+		var running int64
+		err := database.DB(bg).Raw(
+			"SELECT COUNT(*) FROM pg_stat_activity WHERE state = 'active' AND query LIKE 'SELECT pg_sleep(5)%'",
+		).Scan(&running).Error
+		return err == nil && running == 0
+	}, 2*time.Second, 100*time.Millisecond)
+
+	var n int
+	require.NoError(t, database.DB(bg).Raw("SELECT 1").Scan(&n).Error)
+	assert.Equal(t, 1, n)
+}
+
+func TestDBSQLite_CancelOnTimeout(t *testing.T) {
+	conn := testkit.NewSQLiteConnector(t)
+	defer conn.Close()
+
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(conn),
+		WithSilentMode(),
+		WithCancelOnTimeout(),
+	)
+	require.NoError(t, err)
+
+	// SQLite 无需注册取消插件，带超时的查询照常执行
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var n int
+	require.NoError(t, database.DB(ctx).Raw("SELECT 1").Scan(&n).Error)
+	assert.Equal(t, 1, n)
+}
+
 // =============================================================================
 // Close 测试
 // =============================================================================
@@ -325,6 +428,89 @@ func TestDBPostgreSQL_TransactionRollback(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// 事务选项测试（只读事务与隔离级别）
+// =============================================================================
+
+func TestDBPostgreSQL_TransactionOptions(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	conn := testkit.NewPostgreSQLConnector(t)
+	defer conn.Close()
+
+	database, err := New(&Config{Driver: "postgresql"},
+		WithPostgreSQLConnector(conn),
+		WithSilentMode(),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	gormDB := database.DB(ctx)
+	require.NoError(t, gormDB.Migrator().CreateTable(&TestUser{}))
+	defer gormDB.Migrator().DropTable(&TestUser{})
+
+	testTransactionOptions(t, database, "SHOW transaction_isolation", "serializable")
+}
+
+func TestDBMySQL_TransactionOptions(t *testing.T) {
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	conn := testkit.NewMySQLConnector(t)
+	defer conn.Close()
+
+	database, err := New(&Config{Driver: "mysql"},
+		WithMySQLConnector(conn),
+		WithSilentMode(),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	gormDB := database.DB(ctx)
+	require.NoError(t, gormDB.Migrator().CreateTable(&TestUser{}))
+	defer gormDB.Migrator().DropTable(&TestUser{})
+
+	testTransactionOptions(t, database, "SELECT @@transaction_isolation", "SERIALIZABLE")
+}
+
+// testTransactionOptions 验证只读事务拒绝写入、隔离级别生效、普通事务不受影响
+func testTransactionOptions(t *testing.T, database DB, isolationQuery, wantIsolation string) {
+	t.Helper()
+	ctx := context.Background()
+
+	t.Run("只读事务内写操作报错", func(t *testing.T) {
+		err := database.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+			return tx.Create(&TestUser{Name: "ReadOnly", Age: 1}).Error
+		}, WithReadOnly())
+		require.Error(t, err)
+
+		var count int64
+		require.NoError(t, database.DB(ctx).Model(&TestUser{}).Where("name = ?", "ReadOnly").Count(&count).Error)
+		assert.Zero(t, count)
+	})
+
+	t.Run("隔离级别被设置", func(t *testing.T) {
+		var isolation string
+		err := database.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+			return tx.Raw(isolationQuery).Scan(&isolation).Error
+		}, WithIsolation(sql.LevelSerializable))
+		require.NoError(t, err)
+		assert.Equal(t, wantIsolation, isolation)
+	})
+
+	t.Run("普通事务不受影响", func(t *testing.T) {
+		err := database.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+			return tx.Create(&TestUser{Name: "ReadWrite", Age: 2}).Error
+		})
+		require.NoError(t, err)
+
+		var isolation string
+		require.NoError(t, database.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+			return tx.Raw(isolationQuery).Scan(&isolation).Error
+		}))
+		assert.NotEqual(t, wantIsolation, isolation, "未设置时使用数据库默认隔离级别")
+	})
+}
+
 // =============================================================================
 // SQLite 事务回滚测试（补充）
 // =============================================================================
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// migrationLockKey 迁移锁的 key，实际 key 会加上锁后端配置的前缀（如 dlock 的 Prefix）
+const migrationLockKey = "genesis:db:migrate"
+
+// MigrationLocker AutoMigrate 使用的全局锁
+//
+// Lock 阻塞直到获取锁或 ctx 结束；锁持有期间的续期由实现负责。
+// dlock.Locker 的 Lock 带有可变参数，需要一层简单适配，见 WithMigrationLock。
+type MigrationLocker interface {
+	Lock(ctx context.Context, key string) error
+	Unlock(ctx context.Context, key string) error
+}
+
+// AutoMigrate 执行 GORM AutoMigrate
+//
+// 通过 WithMigrationLock 注入分布式锁后，迁移前先获取全局锁，完成后释放：
+// 多个实例同时启动时只有一个实例实际执行 DDL，其余实例等待锁释放后再比对表结构，
+// 此时结构已是最新，GORM 不会再执行任何变更。未注入锁时直接迁移。
+func (d *database) AutoMigrate(ctx context.Context, models ...any) error {
+	if len(models) == 0 {
+		return nil
+	}
+
+	if d.migrationLock != nil {
+		start := time.Now()
+		if err := d.migrationLock.Lock(ctx, migrationLockKey); err != nil {
+			return xerrors.Wrap(err, "acquire migration lock")
+		}
+		defer func() {
+			// 迁移期间 ctx 可能已取消，释放锁不受其影响
+			if err := d.migrationLock.Unlock(context.WithoutCancel(ctx), migrationLockKey); err != nil {
+				d.logger.WarnContext(ctx, "release migration lock failed", clog.Error(err))
+			}
+		}()
+		d.logger.InfoContext(ctx, "migration lock acquired", clog.Duration("wait", time.Since(start)))
+	}
+
+	if err := d.client.WithContext(ctx).AutoMigrate(models...); err != nil {
+		return xerrors.Wrap(err, "auto migrate")
+	}
+	return nil
+}
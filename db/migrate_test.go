@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/testkit"
+)
+
+// MigrateAccount 迁移锁测试用的模型
+type MigrateAccount struct {
+	ID      uint   `gorm:"primaryKey"`
+	Email   string `gorm:"size:255;uniqueIndex"`
+	Balance int64
+}
+
+// memLocker 进程内的 MigrationLocker 实现，模拟多实例共享的分布式锁
+type memLocker struct {
+	mu      sync.Mutex
+	held    atomic.Bool
+	waited  atomic.Int32
+	unlocks atomic.Int32
+}
+
+func (l *memLocker) Lock(ctx context.Context, key string) error {
+	if l.held.Load() {
+		l.waited.Add(1)
+	}
+	l.mu.Lock()
+	l.held.Store(true)
+	return nil
+}
+
+func (l *memLocker) Unlock(ctx context.Context, key string) error {
+	l.unlocks.Add(1)
+	l.held.Store(false)
+	l.mu.Unlock()
+	return nil
+}
+
+// errLockTimeout failingLocker 返回的加锁错误
+var errLockTimeout = errors.New("lock timeout")
+
+// failingLocker 加锁总是失败
+type failingLocker struct{ memLocker }
+
+func (l *failingLocker) Lock(ctx context.Context, key string) error {
+	return errLockTimeout
+}
+
+func TestAutoMigrate_WithMigrationLock(t *testing.T) {
+	conn := testkit.NewPersistentSQLiteConnector(t)
+	locker := &memLocker{}
+
+	// 统计实际执行的 DDL 语句
+	var ddl atomic.Int32
+	require.NoError(t, conn.GetClient().Callback().Raw().After("gorm:raw").Register("test:count_ddl", func(tx *gorm.DB) {
+		sql := strings.ToUpper(strings.TrimSpace(tx.Statement.SQL.String()))
+		if strings.HasPrefix(sql, "CREATE") || strings.HasPrefix(sql, "ALTER") || strings.HasPrefix(sql, "DROP") {
+			// 第一条 DDL 执行时等待另一个实例阻塞在锁上，确保两者并发
+			if ddl.Add(1) == 1 {
+				for i := 0; locker.waited.Load() == 0 && i < 200; i++ {
+					time.Sleep(5 * time.Millisecond)
+				}
+			}
+		}
+	}))
+
+	newInstance := func() DB {
+		database, err := New(&Config{Driver: "sqlite"},
+			WithSQLiteConnector(conn),
+			WithSilentMode(),
+			WithMigrationLock(locker),
+		)
+		require.NoError(t, err)
+		return database
+	}
+	instances := []DB{newInstance(), newInstance()}
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	errs := make([]error, len(instances))
+	for i, database := range instances {
+		wg.Go(func() {
+			errs[i] = database.AutoMigrate(ctx, &MigrateAccount{})
+		})
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 1, locker.waited.Load(), "另一个实例应等待迁移锁")
+	require.EqualValues(t, 2, locker.unlocks.Load(), "两个实例都应释放锁")
+
+	// 只有一个实例真正建表建索引，另一个拿到锁后发现结构已是最新
+	created := ddl.Load()
+	require.EqualValues(t, 2, created, "CREATE TABLE + CREATE UNIQUE INDEX 各执行一次")
+
+	// 再次迁移不产生任何 DDL
+	require.NoError(t, instances[0].AutoMigrate(ctx, &MigrateAccount{}))
+	require.Equal(t, created, ddl.Load())
+
+	migrator := instances[1].DB(ctx).Migrator()
+	require.True(t, migrator.HasTable(&MigrateAccount{}))
+	require.True(t, migrator.HasColumn(&MigrateAccount{}, "balance"))
+	require.True(t, migrator.HasIndex(&MigrateAccount{}, "idx_migrate_accounts_email"))
+}
+
+func TestAutoMigrate_LockFailed(t *testing.T) {
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(testkit.NewPersistentSQLiteConnector(t)),
+		WithSilentMode(),
+		WithMigrationLock(&failingLocker{}),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	err = database.AutoMigrate(ctx, &MigrateAccount{})
+	require.ErrorIs(t, err, errLockTimeout)
+	require.False(t, database.DB(ctx).Migrator().HasTable(&MigrateAccount{}), "未拿到锁时不迁移")
+}
+
+func TestAutoMigrate_WithoutLock(t *testing.T) {
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(testkit.NewPersistentSQLiteConnector(t)),
+		WithSilentMode(),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, database.AutoMigrate(ctx))
+	require.NoError(t, database.AutoMigrate(ctx, &MigrateAccount{}))
+	require.True(t, database.DB(ctx).Migrator().HasTable(&MigrateAccount{}))
+}
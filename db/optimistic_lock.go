@@ -0,0 +1,130 @@
+package db
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+const (
+	optimisticLockPluginName = "genesis:optimistic_lock"
+	// optimisticLockTag 标记版本列的 gorm tag，如 `gorm:"optimistic_lock"`
+	optimisticLockTag = "OPTIMISTIC_LOCK"
+	// optimisticLockSetting 在 Statement.Settings 中记录更新前的版本号
+	optimisticLockSetting = optimisticLockPluginName + ":version"
+)
+
+// Versioned 由需要乐观锁的模型实现，返回版本列的字段名或列名
+//
+// 与在字段上标记 `gorm:"optimistic_lock"` 等价，二选一即可：
+//
+//	type Account struct {
+//		ID      uint
+//		Balance int64
+//		Version int64 `gorm:"optimistic_lock"`
+//	}
+type Versioned interface {
+	VersionField() string
+}
+
+// optimisticLock 对带版本列的模型自动应用乐观锁的 GORM 插件
+//
+// 按主键更新单条记录时追加 `WHERE version = 当前值` 并把版本号加一，
+// 影响行数为 0 说明记录已被其他会话修改，返回 xerrors.ErrConflict。
+type optimisticLock struct{}
+
+// Name 实现 gorm.Plugin
+func (o *optimisticLock) Name() string {
+	return optimisticLockPluginName
+}
+
+// Initialize 实现 gorm.Plugin，在更新前追加版本条件，更新后检查影响行数
+func (o *optimisticLock) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Update().Before("gorm:update").Register(optimisticLockPluginName+":before_update", o.before); err != nil {
+		return err
+	}
+	return db.Callback().Update().After("gorm:update").Register(optimisticLockPluginName+":after_update", o.after)
+}
+
+// before 追加版本条件并递增版本号
+//
+// 只处理按主键更新单个结构体的语句，按条件批量更新不受影响。
+func (o *optimisticLock) before(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	field := versionField(db.Statement.Schema)
+	if field == nil {
+		return
+	}
+	row := reflect.Indirect(db.Statement.ReflectValue)
+	if row.Kind() != reflect.Struct {
+		return
+	}
+	ctx := db.Statement.Context
+	if pk := db.Statement.Schema.PrioritizedPrimaryField; pk == nil {
+		return
+	} else if _, isZero := pk.ValueOf(ctx, row); isZero {
+		return
+	}
+
+	current, _ := field.ValueOf(ctx, row)
+	version, ok := toInt64(current)
+	if !ok {
+		_ = db.AddError(xerrors.Wrapf(ErrInvalidConfig, "optimistic lock: field %s must be an integer", field.Name))
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: current},
+	}})
+	db.Statement.SetColumn(field.DBName, version+1, true)
+	db.Statement.Settings.Store(optimisticLockSetting, current)
+}
+
+// after 影响行数为 0 时返回冲突，并把结构体中的版本号恢复为更新前的值
+func (o *optimisticLock) after(db *gorm.DB) {
+	previous, ok := db.Statement.Settings.Load(optimisticLockSetting)
+	if !ok {
+		return
+	}
+	db.Statement.Settings.Delete(optimisticLockSetting)
+	if db.Error != nil || db.RowsAffected > 0 || db.DryRun {
+		return
+	}
+
+	if field := versionField(db.Statement.Schema); field != nil {
+		_ = field.Set(db.Statement.Context, reflect.Indirect(db.Statement.ReflectValue), previous)
+	}
+	_ = db.AddError(xerrors.Wrapf(xerrors.ErrConflict, "db: optimistic lock on %s: version %v is stale", db.Statement.Table, previous))
+}
+
+// versionField 返回模型的版本列，未启用乐观锁时返回 nil
+func versionField(s *schema.Schema) *schema.Field {
+	if v, ok := reflect.New(s.ModelType).Interface().(Versioned); ok {
+		return s.LookUpField(v.VersionField())
+	}
+	for _, f := range s.Fields {
+		if _, ok := f.TagSettings[optimisticLockTag]; ok {
+			return f
+		}
+	}
+	return nil
+}
+
+// toInt64 把整数类型的版本号转换为 int64
+func toInt64(v any) (int64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true
+	default:
+		return 0, false
+	}
+}
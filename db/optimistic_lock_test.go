@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/testkit"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// LockedAccount 通过 tag 启用乐观锁的模型
+type LockedAccount struct {
+	ID      uint `gorm:"primaryKey"`
+	Owner   string
+	Balance int64
+	Version int64 `gorm:"optimistic_lock"`
+}
+
+// VersionedDoc 通过接口启用乐观锁的模型
+type VersionedDoc struct {
+	ID    uint `gorm:"primaryKey"`
+	Title string
+	Rev   uint32
+}
+
+func (VersionedDoc) VersionField() string { return "Rev" }
+
+func newOptimisticLockDB(t *testing.T) DB {
+	t.Helper()
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(testkit.NewPersistentSQLiteConnector(t)),
+		WithSilentMode(),
+	)
+	require.NoError(t, err)
+	require.NoError(t, database.DB(context.Background()).AutoMigrate(&LockedAccount{}, &VersionedDoc{}))
+	return database
+}
+
+func TestOptimisticLock(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("后提交的会话因版本不符冲突，重读后重试成功", func(t *testing.T) {
+		database := newOptimisticLockDB(t)
+		require.NoError(t, database.DB(ctx).Create(&LockedAccount{Owner: "alice", Balance: 100}).Error)
+
+		var first, second LockedAccount
+		require.NoError(t, database.DB(ctx).First(&first, "owner = ?", "alice").Error)
+		require.NoError(t, database.DB(ctx).First(&second, "owner = ?", "alice").Error)
+
+		first.Balance += 10
+		require.NoError(t, database.DB(ctx).Save(&first).Error)
+		require.EqualValues(t, 1, first.Version)
+
+		second.Balance -= 30
+		err := database.DB(ctx).Save(&second).Error
+		require.ErrorIs(t, err, xerrors.ErrConflict)
+		require.EqualValues(t, 0, second.Version, "冲突时版本号恢复为更新前的值")
+
+		var stored LockedAccount
+		require.NoError(t, database.DB(ctx).First(&stored, first.ID).Error)
+		require.EqualValues(t, 110, stored.Balance, "冲突的更新不生效")
+
+		// 重读后重试
+		require.NoError(t, database.DB(ctx).First(&second, first.ID).Error)
+		second.Balance -= 30
+		require.NoError(t, database.DB(ctx).Save(&second).Error)
+
+		require.NoError(t, database.DB(ctx).First(&stored, first.ID).Error)
+		require.EqualValues(t, 80, stored.Balance)
+		require.EqualValues(t, 2, stored.Version)
+	})
+
+	t.Run("Update 与 Updates 同样校验版本", func(t *testing.T) {
+		database := newOptimisticLockDB(t)
+		account := LockedAccount{Owner: "bob", Balance: 50}
+		require.NoError(t, database.DB(ctx).Create(&account).Error)
+		stale := account
+
+		require.NoError(t, database.DB(ctx).Model(&account).Update("balance", 60).Error)
+		require.EqualValues(t, 1, account.Version)
+		require.NoError(t, database.DB(ctx).Model(&account).Updates(map[string]any{"owner": "bobby"}).Error)
+		require.EqualValues(t, 2, account.Version)
+
+		err := database.DB(ctx).Model(&stale).Updates(LockedAccount{Balance: 1}).Error
+		require.ErrorIs(t, err, xerrors.ErrConflict)
+	})
+
+	t.Run("按条件批量更新不受影响", func(t *testing.T) {
+		database := newOptimisticLockDB(t)
+		require.NoError(t, database.DB(ctx).Create(&[]LockedAccount{{Owner: "a"}, {Owner: "b", Version: 5}}).Error)
+
+		result := database.DB(ctx).Model(&LockedAccount{}).Where("balance = ?", 0).Update("balance", 1)
+		require.NoError(t, result.Error)
+		require.EqualValues(t, 2, result.RowsAffected)
+	})
+
+	t.Run("实现 Versioned 接口的模型", func(t *testing.T) {
+		database := newOptimisticLockDB(t)
+		doc := VersionedDoc{Title: "draft"}
+		require.NoError(t, database.DB(ctx).Create(&doc).Error)
+		stale := doc
+
+		doc.Title = "v1"
+		require.NoError(t, database.DB(ctx).Save(&doc).Error)
+		require.EqualValues(t, 1, doc.Rev)
+
+		stale.Title = "other"
+		require.ErrorIs(t, database.DB(ctx).Save(&stale).Error, xerrors.ErrConflict)
+	})
+}
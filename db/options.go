@@ -2,6 +2,7 @@ package db
 
 import (
 	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/connector"
@@ -18,6 +19,12 @@ type options struct {
 	postgresqlConnector connector.PostgreSQLConnector
 	sqliteConnector     connector.SQLiteConnector
 	silentMode          bool // 静默模式，禁用 SQL 日志输出
+	analyzer            []AnalyzerOption
+	analyzerEnabled     bool
+	cancelOnTimeout     bool
+	migrationLock       MigrationLocker
+	queryCache          QueryCache
+	readReplica         connector.TypedConnector[*gorm.DB]
 }
 
 // WithLogger 注入日志记录器
@@ -64,3 +71,66 @@ func WithSilentMode() Option {
 		o.silentMode = true
 	}
 }
+
+// WithQueryAnalyzer 启用查询分析器（调试模式）
+//
+// 按 WithQueryScope 开启的作用域统计同一 SQL 模板的执行次数，达到阈值时告警疑似 N+1；
+// 配合 WithExplainSlowQuery 可对慢查询自动执行 EXPLAIN。
+// 分析器会额外消耗资源，建议仅在开发和测试环境启用。
+func WithQueryAnalyzer(opts ...AnalyzerOption) Option {
+	return func(o *options) {
+		o.analyzerEnabled = true
+		o.analyzer = append(o.analyzer, opts...)
+	}
+}
+
+// WithCancelOnTimeout 启用查询自动取消
+//
+// ctx 超时或取消后，除了中断客户端等待，还会在数据库端终止仍在执行的查询
+// （MySQL 发送 KILL QUERY，PostgreSQL 调用 pg_cancel_backend）。
+// 每次查询会额外获取一次连接 ID，且取消语句需要连接池中的另一条空闲连接。
+func WithCancelOnTimeout() Option {
+	return func(o *options) {
+		o.cancelOnTimeout = true
+	}
+}
+
+// WithMigrationLock 为 AutoMigrate 注入分布式锁
+//
+// 迁移前获取全局锁，确保多实例同时启动时同一时刻只有一个实例执行迁移。
+// 锁 key 固定为 "genesis:db:migrate"，多个服务共用锁后端时可通过 dlock 的 Prefix 隔离。
+// 使用 dlock.Locker 时嵌入后覆盖 Lock 即可：
+//
+//	type migrationLocker struct{ dlock.Locker }
+//
+//	func (l migrationLocker) Lock(ctx context.Context, key string) error {
+//		return l.Locker.Lock(ctx, key)
+//	}
+//
+//	db.WithMigrationLock(migrationLocker{locker})
+func WithMigrationLock(locker MigrationLocker) Option {
+	return func(o *options) {
+		o.migrationLock = locker
+	}
+}
+
+// WithQueryCache 为 Cached 注入查询结果缓存
+//
+// kv 可以是 cache.Local、cache.Distributed、cache.Multi 或任何实现 QueryCache 的后端。
+// 使用 cache.Distributed 时 tag 记录在 Redis 中，多实例共享失效；
+// 其他实现的 tag 只记录在当前进程内。缓存的生命周期由调用方负责。
+func WithQueryCache(kv QueryCache) Option {
+	return func(o *options) {
+		o.queryCache = kv
+	}
+}
+
+// WithReadReplica 注入从库连接器，Transaction 的只读事务（WithReadOnly）路由到从库
+//
+// conn 可以是 MySQL、PostgreSQL 或 SQLite 连接器，驱动应与 Config.Driver 一致；
+// 从库注册与主库相同的日志、trace 与分片 / 租户插件。DB(ctx) 与普通事务始终使用主库。
+func WithReadReplica(conn connector.TypedConnector[*gorm.DB]) Option {
+	return func(o *options) {
+		o.readReplica = conn
+	}
+}
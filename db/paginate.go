@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// PageResult 分页查询结果
+type PageResult struct {
+	Total      int64 `json:"total"`       // 满足条件的总记录数
+	Page       int   `json:"page"`        // 当前页码，从 1 开始
+	PageSize   int   `json:"page_size"`   // 每页条数
+	TotalPages int   `json:"total_pages"` // 总页数，Total 为 0 时为 0
+}
+
+// Sharded 由分片表模型实现，返回分片键的字段名或列名
+//
+// 分页查询分片表时要求 ctx 已通过 WithShardKey 绑定该分片键，避免 count 与 find 跨分片扫描。
+type Sharded interface {
+	ShardKey() string
+}
+
+// Paginate 在 query 的条件上执行 count + find，把第 page 页的数据写入 dest
+//
+// query 通常由 DB(ctx) 构造（可带 Where / Order / Joins 等），dest 为切片指针；
+// page 从 1 开始，越界页返回空切片和正确的 Total。page 或 pageSize 小于 1 时返回 ErrInvalidPage。
+// 模型实现 Sharded 时，ctx 必须通过 WithShardKey 绑定对应分片键，否则返回 ErrShardKeyRequired。
+//
+//	var users []User
+//	res, err := db.Paginate(ctx, database.DB(ctx).Where("status = ?", "active").Order("id"), 2, 20, &users)
+func Paginate(ctx context.Context, query *gorm.DB, page, pageSize int, dest any) (PageResult, error) {
+	if query == nil {
+		return PageResult{}, xerrors.Wrap(ErrInvalidConfig, "paginate: query is nil")
+	}
+	if page < 1 || pageSize < 1 {
+		return PageResult{}, xerrors.Wrapf(ErrInvalidPage, "page=%d, page_size=%d", page, pageSize)
+	}
+
+	query = query.WithContext(ctx)
+	model := query.Statement.Model
+	if model == nil {
+		model = dest
+	}
+	if err := requireShardKey(ctx, query, model); err != nil {
+		return PageResult{}, err
+	}
+
+	result := PageResult{Page: page, PageSize: pageSize}
+	if err := query.Session(&gorm.Session{}).Model(model).Count(&result.Total).Error; err != nil {
+		return PageResult{}, xerrors.Wrap(err, "paginate: count")
+	}
+	result.TotalPages = int((result.Total + int64(pageSize) - 1) / int64(pageSize))
+
+	offset := (page - 1) * pageSize
+	if err := query.Session(&gorm.Session{}).Offset(offset).Limit(pageSize).Find(dest).Error; err != nil {
+		return PageResult{}, xerrors.Wrap(err, "paginate: find")
+	}
+	return result, nil
+}
+
+// requireShardKey 模型实现 Sharded 时检查 ctx 中已绑定对应的分片键
+func requireShardKey(ctx context.Context, query *gorm.DB, model any) error {
+	sharded, ok := shardedModel(model)
+	if !ok {
+		return nil
+	}
+	column := sharded.ShardKey()
+
+	stmt := &gorm.Statement{DB: query}
+	if err := stmt.Parse(model); err != nil {
+		return xerrors.Wrap(err, "paginate: parse model")
+	}
+	field := stmt.Schema.LookUpField(column)
+	for _, k := range shardKeysFromContext(ctx) {
+		if k.column == column || (field != nil && (k.column == field.DBName || k.column == field.Name)) {
+			return nil
+		}
+	}
+	return xerrors.Wrapf(ErrShardKeyRequired, "table %s requires shard key %s", stmt.Schema.Table, column)
+}
+
+// shardedModel 从模型或切片元素类型中取出 Sharded 实现
+func shardedModel(model any) (Sharded, bool) {
+	if s, ok := model.(Sharded); ok {
+		return s, true
+	}
+	t := reflect.TypeOf(model)
+	for t != nil && (t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, false
+	}
+	s, ok := reflect.New(t).Interface().(Sharded)
+	return s, ok
+}
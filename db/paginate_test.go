@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/testkit"
+)
+
+// PageArticle 分页测试用的普通模型
+type PageArticle struct {
+	ID    uint `gorm:"primaryKey"`
+	Title string
+	Draft bool
+}
+
+// PageShardOrder 分页测试用的分片表模型，按 user_id 分片
+type PageShardOrder struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint
+	Item   string
+}
+
+func (PageShardOrder) ShardKey() string { return "user_id" }
+
+func newPaginateTestDB(t *testing.T) DB {
+	t.Helper()
+
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(testkit.NewSQLiteConnector(t)),
+		WithSilentMode(),
+	)
+	require.NoError(t, err)
+
+	gormDB := database.DB(context.Background())
+	require.NoError(t, gormDB.Migrator().CreateTable(&PageArticle{}, &PageShardOrder{}))
+	t.Cleanup(func() { _ = gormDB.Migrator().DropTable(&PageArticle{}, &PageShardOrder{}) })
+
+	articles := make([]PageArticle, 0, 25)
+	for i := range 25 {
+		// 第 21~25 条为草稿，不参与分页
+		articles = append(articles, PageArticle{Title: fmt.Sprintf("a%02d", i+1), Draft: i >= 20})
+	}
+	require.NoError(t, gormDB.Create(&articles).Error)
+
+	orders := make([]PageShardOrder, 0, 8)
+	for i := range 8 {
+		orders = append(orders, PageShardOrder{UserID: uint(i%2 + 1), Item: fmt.Sprintf("o%d", i)})
+	}
+	require.NoError(t, gormDB.Create(&orders).Error)
+	return database
+}
+
+func TestPaginate(t *testing.T) {
+	database := newPaginateTestDB(t)
+	ctx := context.Background()
+
+	t.Run("分页结果不重叠且总数正确", func(t *testing.T) {
+		seen := make(map[uint]bool)
+		for page := 1; page <= 3; page++ {
+			var articles []PageArticle
+			query := database.DB(ctx).Where("draft = ?", false).Order("id")
+			res, err := Paginate(ctx, query, page, 7, &articles)
+			require.NoError(t, err)
+			require.Equal(t, PageResult{Total: 20, Page: page, PageSize: 7, TotalPages: 3}, res)
+
+			for _, a := range articles {
+				require.False(t, seen[a.ID], "记录 %d 出现在多页", a.ID)
+				seen[a.ID] = true
+				require.False(t, a.Draft)
+			}
+			if page < 3 {
+				require.Len(t, articles, 7)
+			} else {
+				require.Len(t, articles, 6, "最后一页为剩余条数")
+			}
+		}
+		require.Len(t, seen, 20)
+	})
+
+	t.Run("越界页返回空", func(t *testing.T) {
+		articles := []PageArticle{{ID: 999}}
+		res, err := Paginate(ctx, database.DB(ctx).Where("draft = ?", false), 4, 7, &articles)
+		require.NoError(t, err)
+		require.Empty(t, articles)
+		require.EqualValues(t, 20, res.Total)
+		require.Equal(t, 3, res.TotalPages)
+	})
+
+	t.Run("无记录", func(t *testing.T) {
+		var articles []PageArticle
+		res, err := Paginate(ctx, database.DB(ctx).Where("title = ?", "none"), 1, 10, &articles)
+		require.NoError(t, err)
+		require.Empty(t, articles)
+		require.Equal(t, PageResult{Page: 1, PageSize: 10}, res)
+	})
+
+	t.Run("分片表要求分片键", func(t *testing.T) {
+		var orders []PageShardOrder
+		_, err := Paginate(ctx, database.DB(ctx), 1, 10, &orders)
+		require.ErrorIs(t, err, ErrShardKeyRequired)
+
+		shardCtx := WithShardKey(ctx, "user_id", 1)
+		res, err := Paginate(shardCtx, database.DB(shardCtx).Order("id"), 1, 3, &orders)
+		require.NoError(t, err)
+		require.EqualValues(t, 4, res.Total, "count 只统计当前分片")
+		require.Equal(t, 2, res.TotalPages)
+		require.Len(t, orders, 3)
+		for _, o := range orders {
+			require.EqualValues(t, 1, o.UserID)
+		}
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		var articles []PageArticle
+		_, err := Paginate(ctx, database.DB(ctx), 0, 10, &articles)
+		require.ErrorIs(t, err, ErrInvalidPage)
+		_, err = Paginate(ctx, database.DB(ctx), 1, 0, &articles)
+		require.ErrorIs(t, err, ErrInvalidPage)
+		_, err = Paginate(ctx, nil, 1, 10, &articles)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// CacheOption Cached 调用选项
+type CacheOption func(*cacheOptions)
+
+// cacheOptions Cached 调用选项（内部使用）
+type cacheOptions struct {
+	tags []string
+}
+
+// WithCacheTags 为本次缓存的查询结果打上 tag，之后可通过 InvalidateCache 按 tag 批量失效
+func WithCacheTags(tags ...string) CacheOption {
+	return func(o *cacheOptions) {
+		o.tags = append(o.tags, tags...)
+	}
+}
+
+// QueryCache Cached 使用的查询结果缓存后端
+//
+// cache.Local、cache.Distributed、cache.Multi 均满足该接口。Get 未命中时返回的错误需能被
+// xerrors.Is 识别为 xerrors.ErrNotFound（如 ErrCacheMiss 或 cache.ErrMiss），其他错误视为读取失败。
+type QueryCache interface {
+	Get(ctx context.Context, key string, dest any) error
+	Set(ctx context.Context, key string, value any, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// taggedKV 支持 tag 的缓存后端（cache.Distributed 满足该接口）
+type taggedKV interface {
+	SetWithTags(ctx context.Context, key string, value any, ttl time.Duration, tags ...string) error
+	InvalidateByTag(ctx context.Context, tag string) error
+}
+
+// queryCache 查询结果缓存
+//
+// 后端支持 tag 时直接使用后端的 tag 集合；否则在进程内记录 tag 与 key 的对应关系，
+// 仅对当前实例写入的 key 生效。
+type queryCache struct {
+	kv QueryCache
+
+	mu   sync.Mutex
+	tags map[string]map[string]struct{}
+}
+
+func newQueryCache(kv QueryCache) *queryCache {
+	return &queryCache{
+		kv:   kv,
+		tags: make(map[string]map[string]struct{}),
+	}
+}
+
+// set 写入查询结果并登记 tag
+func (c *queryCache) set(ctx context.Context, key string, value any, ttl time.Duration, tags []string) error {
+	if tagged, ok := c.kv.(taggedKV); ok && len(tags) > 0 {
+		return tagged.SetWithTags(ctx, key, value, ttl, tags...)
+	}
+	if err := c.kv.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tag := range tags {
+		keys, ok := c.tags[tag]
+		if !ok {
+			keys = make(map[string]struct{})
+			c.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	return nil
+}
+
+// invalidate 删除 tag 下的所有缓存条目
+func (c *queryCache) invalidate(ctx context.Context, tag string) error {
+	if tagged, ok := c.kv.(taggedKV); ok {
+		return tagged.InvalidateByTag(ctx, tag)
+	}
+
+	c.mu.Lock()
+	keys := c.tags[tag]
+	delete(c.tags, tag)
+	c.mu.Unlock()
+
+	for key := range keys {
+		if err := c.kv.Delete(ctx, key); err != nil {
+			return xerrors.Wrapf(err, "delete cached query %s", key)
+		}
+	}
+	return nil
+}
+
+// Cached 带缓存的读查询
+//
+// 先按 key 读取缓存，命中时直接反序列化到 dest；未命中时以 query 构造查询并执行 Find(dest)，
+// 成功后把结果写入缓存，ttl<=0 时使用缓存组件的 DefaultTTL。dest 需为指针，
+// 结果通过缓存组件的序列化器（默认 JSON）存储，空结果同样会被缓存。
+//
+// 缓存读写失败不影响查询：读失败时回源数据库，写失败只记录日志。
+// 未通过 WithQueryCache 注入缓存时直接查询数据库。
+func (d *database) Cached(ctx context.Context, key string, ttl time.Duration, dest any, query func(*gorm.DB) *gorm.DB, opts ...CacheOption) error {
+	if query == nil {
+		return xerrors.Wrap(ErrInvalidConfig, "query func is nil")
+	}
+	if d.queryCache == nil {
+		return d.find(ctx, dest, query)
+	}
+
+	o := cacheOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	err := d.queryCache.kv.Get(ctx, key, dest)
+	if err == nil {
+		return nil
+	}
+	if !xerrors.Is(err, xerrors.ErrNotFound) {
+		d.logger.WarnContext(ctx, "read query cache failed", clog.String("key", key), clog.Error(err))
+	}
+
+	if err := d.find(ctx, dest, query); err != nil {
+		return err
+	}
+	if err := d.queryCache.set(ctx, key, dest, ttl, o.tags); err != nil {
+		d.logger.WarnContext(ctx, "write query cache failed", clog.String("key", key), clog.Error(err))
+	}
+	return nil
+}
+
+// InvalidateCache 按 tag 失效 Cached 写入的缓存条目
+//
+// 写操作提交后调用，使相关查询下次回源数据库。未注入缓存时为 no-op。
+func (d *database) InvalidateCache(ctx context.Context, tags ...string) error {
+	if d.queryCache == nil {
+		return nil
+	}
+	for _, tag := range tags {
+		if err := d.queryCache.invalidate(ctx, tag); err != nil {
+			return xerrors.Wrapf(err, "invalidate query cache tag %s", tag)
+		}
+	}
+	return nil
+}
+
+// find 执行 query 构造的查询
+func (d *database) find(ctx context.Context, dest any, query func(*gorm.DB) *gorm.DB) error {
+	if err := query(d.client.WithContext(ctx)).Find(dest).Error; err != nil {
+		return xerrors.Wrap(err, "cached query")
+	}
+	return nil
+}
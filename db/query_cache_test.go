@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/cache"
+	"github.com/ceyewan/genesis/testkit"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// cache 组件的实现无需适配即可作为 QueryCache 使用
+var (
+	_ QueryCache = cache.Local(nil)
+	_ QueryCache = cache.Distributed(nil)
+)
+
+// memQueryCache 自定义 QueryCache 实现，未命中时返回 ErrCacheMiss
+type memQueryCache struct {
+	data map[string][]byte
+}
+
+func (c *memQueryCache) Get(_ context.Context, key string, dest any) error {
+	data, ok := c.data[key]
+	if !ok {
+		return ErrCacheMiss
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (c *memQueryCache) Set(_ context.Context, key string, value any, _ time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.data[key] = data
+	return nil
+}
+
+func (c *memQueryCache) Delete(_ context.Context, key string) error {
+	delete(c.data, key)
+	return nil
+}
+
+// CachedProduct 查询缓存测试用的模型
+type CachedProduct struct {
+	ID    uint `gorm:"primaryKey"`
+	Name  string
+	Price int64
+}
+
+// newCachedTestDB 创建带本地查询缓存的 DB，返回查询计数器
+func newCachedTestDB(t *testing.T) (DB, *atomic.Int32) {
+	t.Helper()
+
+	kv, err := cache.NewLocal(&cache.LocalConfig{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = kv.Close() })
+
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(testkit.NewPersistentSQLiteConnector(t)),
+		WithSilentMode(),
+		WithQueryCache(kv),
+	)
+	require.NoError(t, err)
+
+	gormDB := database.DB(context.Background())
+	require.NoError(t, gormDB.AutoMigrate(&CachedProduct{}))
+	require.NoError(t, gormDB.Create(&[]CachedProduct{
+		{Name: "apple", Price: 5},
+		{Name: "pear", Price: 3},
+	}).Error)
+
+	var queries atomic.Int32
+	require.NoError(t, gormDB.Callback().Query().After("gorm:query").Register("test:count_query", func(*gorm.DB) {
+		queries.Add(1)
+	}))
+	return database, &queries
+}
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+	byPrice := func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("price > ?", 1).Order("id")
+	}
+
+	t.Run("首次查库并写缓存，再次命中缓存", func(t *testing.T) {
+		database, queries := newCachedTestDB(t)
+
+		var first []CachedProduct
+		require.NoError(t, database.Cached(ctx, "products", time.Minute, &first, byPrice))
+		require.Len(t, first, 2)
+		require.EqualValues(t, 1, queries.Load())
+
+		var second []CachedProduct
+		require.NoError(t, database.Cached(ctx, "products", time.Minute, &second, byPrice))
+		require.Equal(t, first, second)
+		require.EqualValues(t, 1, queries.Load(), "命中缓存时不查询数据库")
+	})
+
+	t.Run("TTL 过期后重新查库", func(t *testing.T) {
+		database, queries := newCachedTestDB(t)
+
+		var products []CachedProduct
+		require.NoError(t, database.Cached(ctx, "products", 100*time.Millisecond, &products, byPrice))
+		require.NoError(t, database.Cached(ctx, "products", 100*time.Millisecond, &products, byPrice))
+		require.EqualValues(t, 1, queries.Load())
+
+		time.Sleep(200 * time.Millisecond)
+		require.NoError(t, database.Cached(ctx, "products", 100*time.Millisecond, &products, byPrice))
+		require.EqualValues(t, 2, queries.Load())
+	})
+
+	t.Run("按 tag 失效", func(t *testing.T) {
+		database, queries := newCachedTestDB(t)
+
+		var product CachedProduct
+		byName := func(tx *gorm.DB) *gorm.DB { return tx.Where("name = ?", "apple") }
+		require.NoError(t, database.Cached(ctx, "product:apple", time.Minute, &product, byName, WithCacheTags("products")))
+		require.EqualValues(t, 5, product.Price)
+
+		require.NoError(t, database.DB(ctx).Model(&CachedProduct{}).Where("name = ?", "apple").Update("price", 8).Error)
+		require.NoError(t, database.InvalidateCache(ctx, "products"))
+
+		product = CachedProduct{}
+		require.NoError(t, database.Cached(ctx, "product:apple", time.Minute, &product, byName, WithCacheTags("products")))
+		require.EqualValues(t, 8, product.Price)
+		require.EqualValues(t, 2, queries.Load())
+	})
+
+	t.Run("未注入缓存时直接查库", func(t *testing.T) {
+		database, err := New(&Config{Driver: "sqlite"},
+			WithSQLiteConnector(testkit.NewPersistentSQLiteConnector(t)),
+			WithSilentMode(),
+		)
+		require.NoError(t, err)
+		require.NoError(t, database.DB(ctx).AutoMigrate(&CachedProduct{}))
+		require.NoError(t, database.DB(ctx).Create(&CachedProduct{Name: "kiwi", Price: 2}).Error)
+
+		var products []CachedProduct
+		require.NoError(t, database.Cached(ctx, "products", time.Minute, &products, byPrice))
+		require.Len(t, products, 1)
+		require.NoError(t, database.InvalidateCache(ctx, "products"))
+	})
+	t.Run("自定义 QueryCache", func(t *testing.T) {
+		kv := &memQueryCache{data: make(map[string][]byte)}
+		database, err := New(&Config{Driver: "sqlite"},
+			WithSQLiteConnector(testkit.NewPersistentSQLiteConnector(t)),
+			WithSilentMode(),
+			WithQueryCache(kv),
+		)
+		require.NoError(t, err)
+		require.NoError(t, database.DB(ctx).AutoMigrate(&CachedProduct{}))
+		require.NoError(t, database.DB(ctx).Create(&CachedProduct{Name: "kiwi", Price: 2}).Error)
+
+		var first, second []CachedProduct
+		require.NoError(t, database.Cached(ctx, "products", time.Minute, &first, byPrice, WithCacheTags("products")))
+		require.NoError(t, database.Cached(ctx, "products", time.Minute, &second, byPrice, WithCacheTags("products")))
+		require.Equal(t, first, second)
+		require.Contains(t, kv.data, "products")
+
+		require.NoError(t, database.InvalidateCache(ctx, "products"))
+		require.NotContains(t, kv.data, "products")
+		require.True(t, xerrors.Is(cache.ErrMiss, xerrors.ErrNotFound))
+	})
+}
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+const shardPluginName = "genesis:shard_key"
+
+// shardKeyCtx 分片键在 context 中的 key
+type shardKeyCtx struct{}
+
+// shardKey 单个分片键条件
+type shardKey struct {
+	column string
+	value  any
+}
+
+// WithShardKey 在 ctx 上绑定分片键
+//
+// 之后通过 DB(ctx) / Transaction(ctx, ...) 执行的语句会自动带上该分片键：
+//   - 查询（Find / First / Count 等）追加 `column = value` 条件；
+//   - 更新、删除在已有条件的基础上追加 `column = value`；
+//   - 创建时字段为零值则自动填充，已赋值但与分片键不一致返回 ErrShardKeyMismatch。
+//
+// 只对模型中存在该列的表生效，Raw / Exec 原生 SQL 不处理。
+// 多次调用可绑定多个分片键，同名列以最后一次为准。
+func WithShardKey(ctx context.Context, column string, value any) context.Context {
+	keys := shardKeysFromContext(ctx)
+	next := make([]shardKey, 0, len(keys)+1)
+	for _, k := range keys {
+		if k.column != column {
+			next = append(next, k)
+		}
+	}
+	next = append(next, shardKey{column: column, value: value})
+	return context.WithValue(ctx, shardKeyCtx{}, next)
+}
+
+func shardKeysFromContext(ctx context.Context) []shardKey {
+	if ctx == nil {
+		return nil
+	}
+	keys, _ := ctx.Value(shardKeyCtx{}).([]shardKey)
+	return keys
+}
+
+// shardInjector 按 ctx 中的分片键改写语句的 GORM 插件
+type shardInjector struct{}
+
+// Name 实现 gorm.Plugin
+func (s *shardInjector) Name() string {
+	return shardPluginName
+}
+
+// Initialize 实现 gorm.Plugin，在查询、创建、更新、删除回调前注入分片键
+func (s *shardInjector) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register(shardPluginName+":query", s.where); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().Before("gorm:create").Register(shardPluginName+":create", s.fill); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register(shardPluginName+":update", s.guardedWhere); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register(shardPluginName+":delete", s.guardedWhere)
+}
+
+// where 为查询追加分片键条件
+func (s *shardInjector) where(db *gorm.DB) {
+	keys := s.keys(db)
+	if len(keys) == 0 {
+		return
+	}
+	s.addWhere(db, keys)
+}
+
+// guardedWhere 为更新、删除追加分片键条件
+//
+// 语句本身没有任何条件时不注入，保留 GORM 对全表更新/删除的 ErrMissingWhereClause 保护。
+func (s *shardInjector) guardedWhere(db *gorm.DB) {
+	keys := s.keys(db)
+	if len(keys) == 0 || !hasConditions(db) {
+		return
+	}
+	s.addWhere(db, keys)
+}
+
+// fill 为创建语句填充分片键字段
+func (s *shardInjector) fill(db *gorm.DB) {
+	keys := s.keys(db)
+	if len(keys) == 0 {
+		return
+	}
+	ctx := db.Statement.Context
+	for _, k := range keys {
+		field := db.Statement.Schema.LookUpField(k.column)
+		if field == nil {
+			continue
+		}
+		err := eachRow(db.Statement.ReflectValue, func(row reflect.Value) error {
+			current, isZero := field.ValueOf(ctx, row)
+			if isZero {
+				return field.Set(ctx, row, k.value)
+			}
+			if sameValue(current, k.value) {
+				return nil
+			}
+			return xerrors.Wrapf(ErrShardKeyMismatch, "%s: want %v, got %v", field.DBName, k.value, current)
+		})
+		if err != nil {
+			_ = db.AddError(err)
+			return
+		}
+	}
+}
+
+// keys 返回当前语句需要注入的分片键
+func (s *shardInjector) keys(db *gorm.DB) []shardKey {
+	if db.Error != nil || db.Statement == nil || db.Statement.Schema == nil {
+		return nil
+	}
+	return shardKeysFromContext(db.Statement.Context)
+}
+
+// addWhere 为模型中存在的分片键列追加等值条件
+func (s *shardInjector) addWhere(db *gorm.DB, keys []shardKey) {
+	exprs := make([]clause.Expression, 0, len(keys))
+	for _, k := range keys {
+		field := db.Statement.Schema.LookUpField(k.column)
+		if field == nil || field.DBName == "" {
+			continue
+		}
+		exprs = append(exprs, clause.Eq{
+			Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+			Value:  k.value,
+		})
+	}
+	if len(exprs) > 0 {
+		db.Statement.AddClause(clause.Where{Exprs: exprs})
+	}
+}
+
+// hasConditions 判断更新、删除语句是否已有条件（显式 WHERE 或非零主键）
+func hasConditions(db *gorm.DB) bool {
+	if db.AllowGlobalUpdate {
+		return true
+	}
+	if c, ok := db.Statement.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok && len(where.Exprs) > 0 {
+			return true
+		}
+	}
+
+	pk := db.Statement.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return false
+	}
+	found := false
+	_ = eachRow(db.Statement.ReflectValue, func(row reflect.Value) error {
+		if _, isZero := pk.ValueOf(db.Statement.Context, row); !isZero {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// sameValue 判断字段当前值与期望值是否相等，期望值会先转换为字段类型再比较
+func sameValue(current, want any) bool {
+	value := reflect.ValueOf(want)
+	return value.IsValid() && value.Type().ConvertibleTo(reflect.TypeOf(current)) &&
+		reflect.DeepEqual(current, value.Convert(reflect.TypeOf(current)).Interface())
+}
+
+// eachRow 遍历单个结构体或切片/数组中的每一行
+func eachRow(rv reflect.Value, fn func(row reflect.Value) error) error {
+	rv = reflect.Indirect(rv)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := range rv.Len() {
+			row := reflect.Indirect(rv.Index(i))
+			if row.Kind() != reflect.Struct {
+				continue
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return fn(rv)
+	}
+	return nil
+}
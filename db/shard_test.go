@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/testkit"
+)
+
+// ShardOrder 分片键测试用的订单模型，按 user_id 分片
+type ShardOrder struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint
+	Item   string
+}
+
+// ShardConfig 不含分片键列的模型
+type ShardConfig struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+func newShardTestDB(t *testing.T) DB {
+	t.Helper()
+
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(testkit.NewSQLiteConnector(t)),
+		WithSilentMode(),
+	)
+	require.NoError(t, err)
+
+	gormDB := database.DB(context.Background())
+	require.NoError(t, gormDB.Migrator().CreateTable(&ShardOrder{}, &ShardConfig{}))
+	t.Cleanup(func() { _ = gormDB.Migrator().DropTable(&ShardOrder{}, &ShardConfig{}) })
+
+	require.NoError(t, gormDB.Create(&[]ShardOrder{
+		{UserID: 1, Item: "a"},
+		{UserID: 1, Item: "b"},
+		{UserID: 2, Item: "c"},
+	}).Error)
+	require.NoError(t, gormDB.Create(&[]ShardConfig{{Name: "x"}, {Name: "y"}}).Error)
+	return database
+}
+
+func TestWithShardKey(t *testing.T) {
+	database := newShardTestDB(t)
+	ctx := WithShardKey(context.Background(), "user_id", 1)
+
+	t.Run("查询自动带上分片键", func(t *testing.T) {
+		var orders []ShardOrder
+		require.NoError(t, database.DB(ctx).Find(&orders).Error)
+		require.Len(t, orders, 2)
+		for _, o := range orders {
+			require.EqualValues(t, 1, o.UserID)
+		}
+
+		var count int64
+		require.NoError(t, database.DB(ctx).Model(&ShardOrder{}).Where("item = ?", "c").Count(&count).Error)
+		require.Zero(t, count, "其他分片的数据不可见")
+	})
+
+	t.Run("创建自动填充分片键", func(t *testing.T) {
+		order := ShardOrder{Item: "d"}
+		require.NoError(t, database.DB(ctx).Create(&order).Error)
+		require.EqualValues(t, 1, order.UserID)
+
+		batch := []ShardOrder{{Item: "e"}, {Item: "f", UserID: 1}}
+		require.NoError(t, database.DB(ctx).Create(&batch).Error)
+		require.EqualValues(t, 1, batch[0].UserID)
+
+		err := database.DB(ctx).Create(&ShardOrder{Item: "g", UserID: 2}).Error
+		require.ErrorIs(t, err, ErrShardKeyMismatch)
+	})
+
+	t.Run("更新删除只作用于当前分片", func(t *testing.T) {
+		require.NoError(t, database.DB(ctx).Model(&ShardOrder{}).Where("item IN ?", []string{"a", "c"}).Update("item", "z").Error)
+		require.NoError(t, database.DB(ctx).Where("item = ?", "c").Delete(&ShardOrder{}).Error)
+
+		var other ShardOrder
+		require.NoError(t, database.DB(context.Background()).Where("user_id = ?", 2).First(&other).Error)
+		require.Equal(t, "c", other.Item, "其他分片的数据不受影响")
+
+		var mine ShardOrder
+		require.NoError(t, database.DB(ctx).First(&mine).Error)
+		result := database.DB(ctx).Model(&mine).Update("item", "pk")
+		require.NoError(t, result.Error)
+		require.EqualValues(t, 1, result.RowsAffected, "按主键更新同样带上分片键")
+
+		err := database.DB(ctx).Model(&ShardOrder{}).Update("item", "all").Error
+		require.Error(t, err, "无条件更新仍被 GORM 拒绝")
+	})
+
+	t.Run("事务内同样生效", func(t *testing.T) {
+		err := database.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+			var orders []ShardOrder
+			if err := tx.Find(&orders).Error; err != nil {
+				return err
+			}
+			for _, o := range orders {
+				require.EqualValues(t, 1, o.UserID)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("不含分片键列的表不受影响", func(t *testing.T) {
+		var configs []ShardConfig
+		require.NoError(t, database.DB(ctx).Find(&configs).Error)
+		require.Len(t, configs, 2)
+	})
+
+	t.Run("未设置分片键时行为不变", func(t *testing.T) {
+		var count int64
+		require.NoError(t, database.DB(context.Background()).Model(&ShardOrder{}).Count(&count).Error)
+		require.EqualValues(t, 6, count)
+	})
+}
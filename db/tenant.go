@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+const (
+	tenantPluginName = "genesis:tenant"
+	// tenantTag 标记租户列的 gorm tag，如 `gorm:"tenant"`
+	tenantTag = "TENANT"
+)
+
+// tenantCtx 租户 ID 在 context 中的 key
+type tenantCtx struct{}
+
+// Tenanted 由按租户隔离的模型实现，返回租户列的字段名或列名
+//
+// 与在字段上标记 `gorm:"tenant"` 等价，二选一即可：
+//
+//	type Order struct {
+//		ID       uint
+//		TenantID string `gorm:"tenant"`
+//		Item     string
+//	}
+type Tenanted interface {
+	TenantField() string
+}
+
+// WithTenant 在 ctx 上绑定租户 ID
+//
+// 之后通过 DB(ctx) / Transaction(ctx, ...) 访问声明了租户列的模型时：
+//   - 查询、更新、删除自动追加 `tenant_column = tenantID` 条件；
+//   - 创建时租户列为零值则自动填充，已赋值但与 ctx 中的租户不一致返回 ErrTenantMismatch；
+//   - ctx 未绑定租户时直接返回 ErrTenantRequired，不会访问数据库。
+//
+// 未声明租户列的模型不受影响，Raw / Exec 原生 SQL 不处理。
+func WithTenant(ctx context.Context, tenantID any) context.Context {
+	return context.WithValue(ctx, tenantCtx{}, tenantID)
+}
+
+// TenantFromContext 返回 ctx 中绑定的租户 ID
+func TenantFromContext(ctx context.Context) (any, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tenantID := ctx.Value(tenantCtx{})
+	return tenantID, tenantID != nil
+}
+
+// tenantIsolation 对声明了租户列的模型做行级租户隔离的 GORM 插件
+type tenantIsolation struct{}
+
+// Name 实现 gorm.Plugin
+func (t *tenantIsolation) Name() string {
+	return tenantPluginName
+}
+
+// Initialize 实现 gorm.Plugin，在查询、创建、更新、删除回调前注入租户条件
+func (t *tenantIsolation) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Query().Before("gorm:query").Register(tenantPluginName+":query", t.where); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register(tenantPluginName+":row", t.where); err != nil {
+		return err
+	}
+	if err := db.Callback().Create().Before("gorm:create").Register(tenantPluginName+":create", t.fill); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register(tenantPluginName+":update", t.guardedWhere); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register(tenantPluginName+":delete", t.guardedWhere)
+}
+
+// where 为查询追加租户条件
+func (t *tenantIsolation) where(db *gorm.DB) {
+	field, tenantID, ok := t.resolve(db)
+	if !ok {
+		return
+	}
+	t.addWhere(db, field, tenantID)
+}
+
+// guardedWhere 为更新、删除追加租户条件
+//
+// 语句本身没有任何条件时不注入，保留 GORM 对全表更新/删除的 ErrMissingWhereClause 保护。
+func (t *tenantIsolation) guardedWhere(db *gorm.DB) {
+	field, tenantID, ok := t.resolve(db)
+	if !ok || !hasConditions(db) {
+		return
+	}
+	t.addWhere(db, field, tenantID)
+}
+
+// fill 为创建语句填充租户列
+func (t *tenantIsolation) fill(db *gorm.DB) {
+	field, tenantID, ok := t.resolve(db)
+	if !ok {
+		return
+	}
+	ctx := db.Statement.Context
+	err := eachRow(db.Statement.ReflectValue, func(row reflect.Value) error {
+		current, isZero := field.ValueOf(ctx, row)
+		if isZero {
+			return field.Set(ctx, row, tenantID)
+		}
+		if sameValue(current, tenantID) {
+			return nil
+		}
+		return xerrors.Wrapf(ErrTenantMismatch, "%s: want %v, got %v", field.DBName, tenantID, current)
+	})
+	if err != nil {
+		_ = db.AddError(err)
+	}
+}
+
+// resolve 返回当前语句的租户列与租户 ID
+//
+// 模型未声明租户列时返回 ok=false；声明了租户列但 ctx 未绑定租户时记录 ErrTenantRequired。
+func (t *tenantIsolation) resolve(db *gorm.DB) (*schema.Field, any, bool) {
+	if db.Error != nil || db.Statement == nil || db.Statement.Schema == nil {
+		return nil, nil, false
+	}
+	field := tenantField(db.Statement.Schema)
+	if field == nil {
+		return nil, nil, false
+	}
+	tenantID, ok := TenantFromContext(db.Statement.Context)
+	if !ok {
+		_ = db.AddError(xerrors.Wrapf(ErrTenantRequired, "table %s", db.Statement.Schema.Table))
+		return nil, nil, false
+	}
+	return field, tenantID, true
+}
+
+// addWhere 追加租户等值条件
+func (t *tenantIsolation) addWhere(db *gorm.DB, field *schema.Field, tenantID any) {
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantID},
+	}})
+}
+
+// tenantField 返回模型的租户列，未声明时返回 nil
+func tenantField(s *schema.Schema) *schema.Field {
+	if v, ok := reflect.New(s.ModelType).Interface().(Tenanted); ok {
+		return s.LookUpField(v.TenantField())
+	}
+	for _, f := range s.Fields {
+		if _, ok := f.TagSettings[tenantTag]; ok {
+			return f
+		}
+	}
+	return nil
+}
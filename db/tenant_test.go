@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/testkit"
+)
+
+// TenantNote 按 tenant 标签隔离的模型
+type TenantNote struct {
+	ID       uint   `gorm:"primaryKey"`
+	TenantID string `gorm:"tenant"`
+	Title    string
+}
+
+// TenantTask 通过 Tenanted 接口声明租户列的模型
+type TenantTask struct {
+	ID    uint `gorm:"primaryKey"`
+	OrgID int64
+	Name  string
+}
+
+func (TenantTask) TenantField() string { return "OrgID" }
+
+func newTenantTestDB(t *testing.T) DB {
+	t.Helper()
+
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(testkit.NewSQLiteConnector(t)),
+		WithSilentMode(),
+	)
+	require.NoError(t, err)
+
+	gormDB := database.DB(context.Background())
+	require.NoError(t, gormDB.Migrator().CreateTable(&TenantNote{}, &TenantTask{}, &ShardConfig{}))
+	t.Cleanup(func() { _ = gormDB.Migrator().DropTable(&TenantNote{}, &TenantTask{}, &ShardConfig{}) })
+
+	ctxA := WithTenant(context.Background(), "tenant-a")
+	ctxB := WithTenant(context.Background(), "tenant-b")
+	require.NoError(t, database.DB(ctxA).Create(&[]TenantNote{{Title: "a1"}, {Title: "a2"}}).Error)
+	require.NoError(t, database.DB(ctxB).Create(&TenantNote{Title: "b1"}).Error)
+	return database
+}
+
+func TestWithTenant(t *testing.T) {
+	database := newTenantTestDB(t)
+	ctxA := WithTenant(context.Background(), "tenant-a")
+	ctxB := WithTenant(context.Background(), "tenant-b")
+
+	t.Run("只能查到本租户数据", func(t *testing.T) {
+		var notes []TenantNote
+		require.NoError(t, database.DB(ctxA).Find(&notes).Error)
+		require.Len(t, notes, 2)
+		for _, n := range notes {
+			require.Equal(t, "tenant-a", n.TenantID)
+		}
+
+		var count int64
+		require.NoError(t, database.DB(ctxA).Model(&TenantNote{}).Where("title = ?", "b1").Count(&count).Error)
+		require.Zero(t, count, "其他租户的数据不可见")
+
+		var other TenantNote
+		require.NoError(t, database.DB(ctxB).First(&other).Error)
+		err := database.DB(ctxA).First(&TenantNote{}, other.ID).Error
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound, "按主键也无法跨租户读取")
+	})
+
+	t.Run("Create 自动填充租户", func(t *testing.T) {
+		note := TenantNote{Title: "a3"}
+		require.NoError(t, database.DB(ctxA).Create(&note).Error)
+		require.Equal(t, "tenant-a", note.TenantID)
+
+		task := TenantTask{Name: "t1"}
+		require.NoError(t, database.DB(WithTenant(context.Background(), 42)).Create(&task).Error)
+		require.EqualValues(t, 42, task.OrgID)
+
+		err := database.DB(ctxA).Create(&TenantNote{Title: "x", TenantID: "tenant-b"}).Error
+		require.ErrorIs(t, err, ErrTenantMismatch)
+	})
+
+	t.Run("更新删除无法跨租户", func(t *testing.T) {
+		require.NoError(t, database.DB(ctxA).Model(&TenantNote{}).Where("title = ?", "b1").Update("title", "hacked").Error)
+		require.NoError(t, database.DB(ctxA).Where("title = ?", "b1").Delete(&TenantNote{}).Error)
+
+		var notes []TenantNote
+		require.NoError(t, database.DB(ctxB).Find(&notes).Error)
+		require.Len(t, notes, 1)
+		require.Equal(t, "b1", notes[0].Title)
+	})
+
+	t.Run("事务内同样生效", func(t *testing.T) {
+		err := database.Transaction(ctxB, func(ctx context.Context, tx *gorm.DB) error {
+			var count int64
+			if err := tx.Model(&TenantNote{}).Count(&count).Error; err != nil {
+				return err
+			}
+			require.EqualValues(t, 1, count)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("无租户上下文访问隔离表报错", func(t *testing.T) {
+		ctx := context.Background()
+
+		var notes []TenantNote
+		require.ErrorIs(t, database.DB(ctx).Find(&notes).Error, ErrTenantRequired)
+		require.ErrorIs(t, database.DB(ctx).Create(&TenantNote{Title: "n"}).Error, ErrTenantRequired)
+		require.ErrorIs(t, database.DB(ctx).Model(&TenantNote{}).Where("id > 0").Update("title", "n").Error, ErrTenantRequired)
+		require.ErrorIs(t, database.DB(ctx).Where("id > 0").Delete(&TenantNote{}).Error, ErrTenantRequired)
+	})
+
+	t.Run("未声明租户列的表不受影响", func(t *testing.T) {
+		require.NoError(t, database.DB(context.Background()).Create(&ShardConfig{Name: "x"}).Error)
+
+		var configs []ShardConfig
+		require.NoError(t, database.DB(context.Background()).Find(&configs).Error)
+		require.Len(t, configs, 1)
+	})
+}
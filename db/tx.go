@@ -0,0 +1,37 @@
+package db
+
+import "database/sql"
+
+// TxOption 配置 Transaction 的事务选项
+type TxOption func(*sql.TxOptions)
+
+// WithReadOnly 开启只读事务
+//
+// 事务内的写语句由数据库拒绝（MySQL / PostgreSQL 返回错误）；注入 WithReadReplica 时
+// 只读事务路由到从库执行。报表等只读场景可借此让数据库省去写相关的开销。
+func WithReadOnly() TxOption {
+	return func(o *sql.TxOptions) {
+		o.ReadOnly = true
+	}
+}
+
+// WithIsolation 设置事务隔离级别，如 sql.LevelRepeatableRead、sql.LevelSerializable
+//
+// 驱动不支持的隔离级别会在开启事务时返回错误（如 SQLite 只支持 Serializable）。
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *sql.TxOptions) {
+		o.Isolation = level
+	}
+}
+
+// applyTxOptions 应用事务选项，未传选项时返回 nil，沿用驱动默认行为
+func applyTxOptions(opts []TxOption) *sql.TxOptions {
+	if len(opts) == 0 {
+		return nil
+	}
+	txOpts := &sql.TxOptions{}
+	for _, opt := range opts {
+		opt(txOpts)
+	}
+	return txOpts
+}
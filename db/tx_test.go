@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/connector"
+	"github.com/ceyewan/genesis/testkit"
+)
+
+// TxReport 事务选项测试用模型
+type TxReport struct {
+	ID   uint `gorm:"primaryKey"`
+	Name string
+}
+
+func TestApplyTxOptions(t *testing.T) {
+	require.Nil(t, applyTxOptions(nil), "未传选项时沿用驱动默认行为")
+	require.Equal(t, &sql.TxOptions{ReadOnly: true, Isolation: sql.LevelSerializable},
+		applyTxOptions([]TxOption{WithReadOnly(), WithIsolation(sql.LevelSerializable)}))
+}
+
+func TestTransactionReadReplica(t *testing.T) {
+	replicaConn, err := connector.NewSQLite(testkit.NewPersistentSQLiteConfig(t), connector.WithLogger(testkit.NewLogger()))
+	require.NoError(t, err)
+	require.NoError(t, replicaConn.Connect(context.Background()))
+	t.Cleanup(func() { _ = replicaConn.Close() })
+
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(testkit.NewSQLiteConnector(t)),
+		WithReadReplica(replicaConn),
+		WithSilentMode(),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	primary := database.DB(ctx)
+	require.NoError(t, primary.Migrator().CreateTable(&TxReport{}))
+	t.Cleanup(func() { _ = primary.Migrator().DropTable(&TxReport{}) })
+	require.NoError(t, primary.Create(&TxReport{Name: "primary"}).Error)
+
+	replica := replicaConn.GetClient()
+	require.NoError(t, replica.Migrator().CreateTable(&TxReport{}))
+	require.NoError(t, replica.Create(&TxReport{Name: "replica"}).Error)
+
+	readName := func(opts ...TxOption) string {
+		var got TxReport
+		require.NoError(t, database.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+			return tx.First(&got).Error
+		}, opts...))
+		return got.Name
+	}
+
+	require.Equal(t, "replica", readName(WithReadOnly()), "只读事务路由到从库")
+	require.Equal(t, "primary", readName(), "普通事务使用主库")
+	require.Equal(t, "primary", readName(WithIsolation(sql.LevelSerializable)), "只设隔离级别时使用主库")
+}
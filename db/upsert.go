@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"slices"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// Upsert 批量写入 records，与 conflictColumns 冲突的记录改为更新 updateColumns
+//
+// 底层使用 clause.OnConflict：MySQL 生成 ON DUPLICATE KEY UPDATE，PostgreSQL / SQLite
+// 生成 ON CONFLICT (...) DO UPDATE。records 为结构体指针或切片指针，插入时回填自增主键。
+//   - conflictColumns 为唯一约束或主键列，不能为空（MySQL 按表上的唯一索引判断冲突，但仍需声明）；
+//   - updateColumns 为空时更新除主键、冲突列、创建时间外的全部列；
+//   - 模型实现 Sharded 时分片键列不会被更新；ctx 未绑定分片键时按记录的分片键值分组，
+//     每组绑定对应分片键后在同一事务内写入，已绑定时记录的分片键必须与之一致。
+func (d *database) Upsert(ctx context.Context, records any, conflictColumns []string, updateColumns []string) error {
+	if records == nil {
+		return xerrors.Wrap(ErrInvalidConfig, "upsert: records is nil")
+	}
+	if len(conflictColumns) == 0 {
+		return xerrors.Wrap(ErrInvalidConfig, "upsert: conflict columns are required")
+	}
+	rv := reflect.Indirect(reflect.ValueOf(records))
+	if (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array) && rv.Len() == 0 {
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: d.client}
+	if err := stmt.Parse(records); err != nil {
+		return xerrors.Wrap(err, "upsert: parse model")
+	}
+
+	var shardField *schema.Field
+	if sharded, ok := shardedModel(records); ok {
+		shardField = stmt.Schema.LookUpField(sharded.ShardKey())
+		if shardField == nil {
+			return xerrors.Wrapf(ErrInvalidConfig, "upsert: shard key %s not found in %s", sharded.ShardKey(), stmt.Schema.Table)
+		}
+	}
+
+	onConflict := clause.OnConflict{
+		Columns:   make([]clause.Column, 0, len(conflictColumns)),
+		DoUpdates: clause.AssignmentColumns(upsertUpdateColumns(stmt.Schema, conflictColumns, updateColumns, shardField)),
+	}
+	for _, column := range conflictColumns {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	if len(onConflict.DoUpdates) == 0 {
+		onConflict.DoNothing = true
+	}
+
+	if shardField == nil || hasShardKey(ctx, shardField) || rv.Kind() == reflect.Struct {
+		if err := d.DB(ctx).Clauses(onConflict).Create(records).Error; err != nil {
+			return xerrors.Wrap(err, "upsert")
+		}
+		return nil
+	}
+
+	// 分片表且 ctx 未绑定分片键：按分片键值分组，逐组路由写入
+	return d.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		for _, group := range groupByShard(ctx, rv, shardField) {
+			shardCtx := WithShardKey(ctx, shardField.DBName, group.value)
+			if err := tx.WithContext(shardCtx).Clauses(onConflict).Create(group.rows.Interface()).Error; err != nil {
+				return xerrors.Wrapf(err, "upsert: shard %s=%v", shardField.DBName, group.value)
+			}
+			// 分组写入的是副本，回填主键等字段到原记录
+			for j, i := range group.indexes {
+				rv.Index(i).Set(group.rows.Elem().Index(j))
+			}
+		}
+		return nil
+	})
+}
+
+// upsertUpdateColumns 计算冲突时需要更新的列，分片键列始终排除
+func upsertUpdateColumns(s *schema.Schema, conflictColumns, updateColumns []string, shardField *schema.Field) []string {
+	skip := func(column string) bool {
+		return shardField != nil && (column == shardField.DBName || column == shardField.Name)
+	}
+	if len(updateColumns) > 0 {
+		return slices.DeleteFunc(slices.Clone(updateColumns), skip)
+	}
+
+	columns := make([]string, 0, len(s.DBNames))
+	for _, field := range s.Fields {
+		if field.DBName == "" || !field.Updatable || field.PrimaryKey || field.AutoCreateTime > 0 {
+			continue
+		}
+		if slices.Contains(conflictColumns, field.DBName) || skip(field.DBName) {
+			continue
+		}
+		columns = append(columns, field.DBName)
+	}
+	return columns
+}
+
+// hasShardKey 判断 ctx 中是否已绑定该分片键
+func hasShardKey(ctx context.Context, field *schema.Field) bool {
+	for _, k := range shardKeysFromContext(ctx) {
+		if k.column == field.DBName || k.column == field.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// shardGroup 同一分片键值的记录
+type shardGroup struct {
+	value   any
+	rows    reflect.Value // 指向与 records 同元素类型切片的指针
+	indexes []int         // 每条记录在 records 中的下标
+}
+
+// groupByShard 按分片键值把切片中的记录分组，保持记录的首次出现顺序
+func groupByShard(ctx context.Context, rv reflect.Value, field *schema.Field) []shardGroup {
+	var groups []shardGroup
+	index := make(map[any]int)
+	sliceType := reflect.SliceOf(rv.Type().Elem())
+	for i := range rv.Len() {
+		elem := rv.Index(i)
+		value, _ := field.ValueOf(ctx, reflect.Indirect(elem))
+		n, ok := index[value]
+		if !ok {
+			n = len(groups)
+			index[value] = n
+			groups = append(groups, shardGroup{value: value, rows: reflect.New(sliceType)})
+		}
+		rows := groups[n].rows.Elem()
+		rows.Set(reflect.Append(rows, elem))
+		groups[n].indexes = append(groups[n].indexes, i)
+	}
+	return groups
+}
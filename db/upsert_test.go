@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/testkit"
+)
+
+// UpsertUser Upsert 测试用模型
+type UpsertUser struct {
+	ID    uint `gorm:"primaryKey"`
+	Name  string
+	Email string
+}
+
+// UpsertOrder Upsert 测试用的分片表模型，按 user_id 分片
+type UpsertOrder struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint
+	Item   string
+}
+
+func (UpsertOrder) ShardKey() string { return "user_id" }
+
+func newUpsertTestDB(t *testing.T) DB {
+	t.Helper()
+
+	database, err := New(&Config{Driver: "sqlite"},
+		WithSQLiteConnector(testkit.NewSQLiteConnector(t)),
+		WithSilentMode(),
+	)
+	require.NoError(t, err)
+
+	gormDB := database.DB(context.Background())
+	require.NoError(t, gormDB.Migrator().CreateTable(&UpsertUser{}, &UpsertOrder{}))
+	t.Cleanup(func() { _ = gormDB.Migrator().DropTable(&UpsertUser{}, &UpsertOrder{}) })
+	return database
+}
+
+func TestUpsert(t *testing.T) {
+	database := newUpsertTestDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, database.DB(ctx).Create(&[]UpsertUser{
+		{ID: 1, Name: "alice", Email: "alice@old"},
+		{ID: 2, Name: "bob", Email: "bob@old"},
+	}).Error)
+
+	t.Run("存在则更新不存在则插入", func(t *testing.T) {
+		users := []UpsertUser{
+			{ID: 1, Name: "alice2", Email: "alice@new"},
+			{ID: 3, Name: "carol", Email: "carol@new"},
+		}
+		require.NoError(t, database.Upsert(ctx, &users, []string{"id"}, nil))
+
+		var got []UpsertUser
+		require.NoError(t, database.DB(ctx).Order("id").Find(&got).Error)
+		require.Equal(t, []UpsertUser{
+			{ID: 1, Name: "alice2", Email: "alice@new"},
+			{ID: 2, Name: "bob", Email: "bob@old"},
+			{ID: 3, Name: "carol", Email: "carol@new"},
+		}, got)
+	})
+
+	t.Run("只更新指定列", func(t *testing.T) {
+		user := UpsertUser{ID: 2, Name: "bobby", Email: "bob@new"}
+		require.NoError(t, database.Upsert(ctx, &user, []string{"id"}, []string{"email"}))
+
+		var got UpsertUser
+		require.NoError(t, database.DB(ctx).First(&got, 2).Error)
+		require.Equal(t, "bob", got.Name, "未指定的列保持不变")
+		require.Equal(t, "bob@new", got.Email)
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		require.ErrorIs(t, database.Upsert(ctx, nil, []string{"id"}, nil), ErrInvalidConfig)
+		require.ErrorIs(t, database.Upsert(ctx, &[]UpsertUser{{ID: 1}}, nil, nil), ErrInvalidConfig)
+		require.NoError(t, database.Upsert(ctx, &[]UpsertUser{}, []string{"id"}, nil))
+	})
+}
+
+func TestUpsertSharded(t *testing.T) {
+	database := newUpsertTestDB(t)
+	ctx := context.Background()
+
+	require.NoError(t, database.DB(ctx).Create(&UpsertOrder{ID: 1, UserID: 1, Item: "a"}).Error)
+
+	t.Run("未绑定分片键时按记录分组路由", func(t *testing.T) {
+		orders := []UpsertOrder{
+			{ID: 1, UserID: 1, Item: "a2"},
+			{UserID: 2, Item: "b"},
+			{UserID: 1, Item: "c"},
+		}
+		require.NoError(t, database.Upsert(ctx, &orders, []string{"id"}, nil))
+		for _, o := range orders {
+			require.NotZero(t, o.ID, "自增主键回填到原记录")
+		}
+
+		var count int64
+		require.NoError(t, database.DB(WithShardKey(ctx, "user_id", 1)).Model(&UpsertOrder{}).Count(&count).Error)
+		require.EqualValues(t, 2, count)
+
+		var got UpsertOrder
+		require.NoError(t, database.DB(ctx).First(&got, 1).Error)
+		require.Equal(t, "a2", got.Item)
+	})
+
+	t.Run("分片键列不会被更新", func(t *testing.T) {
+		order := UpsertOrder{ID: 1, UserID: 9, Item: "moved"}
+		require.NoError(t, database.Upsert(ctx, &order, []string{"id"}, []string{"user_id", "item"}))
+
+		var got UpsertOrder
+		require.NoError(t, database.DB(ctx).First(&got, 1).Error)
+		require.EqualValues(t, 1, got.UserID)
+		require.Equal(t, "moved", got.Item)
+	})
+
+	t.Run("已绑定分片键时校验一致性", func(t *testing.T) {
+		shardCtx := WithShardKey(ctx, "user_id", 2)
+		err := database.Upsert(shardCtx, &[]UpsertOrder{{UserID: 1, Item: "x"}}, []string{"id"}, nil)
+		require.ErrorIs(t, err, ErrShardKeyMismatch)
+
+		orders := []UpsertOrder{{Item: "d"}}
+		require.NoError(t, database.Upsert(shardCtx, &orders, []string{"id"}, nil))
+		require.EqualValues(t, 2, orders[0].UserID)
+	})
+}
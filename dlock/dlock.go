@@ -31,6 +31,9 @@ import (
 // 通过 cfg.Driver 选择后端，连接器通过 Option 注入：
 //   - DriverRedis: WithRedisConnector
 //   - DriverEtcd: WithEtcdConnector
+//
+// 通过 WithMeter 注入 Meter 后会上报锁持有时长与加锁等待时长；
+// 配置 HoldWarnThreshold 后，持有超过阈值的锁在释放时打 warn。
 func New(cfg *Config, opts ...Option) (Locker, error) {
 	if cfg == nil {
 		return nil, ErrConfigNil
@@ -51,18 +54,31 @@ func New(cfg *Config, opts ...Option) (Locker, error) {
 		logger = logger.With(clog.String("component", "dlock"))
 	}
 
+	var (
+		locker Locker
+		err    error
+	)
 	switch cfg.Driver {
 	case DriverRedis:
 		if opt.redisConnector == nil {
 			return nil, xerrors.New("dlock: redis connector is required, use WithRedisConnector")
 		}
-		return newRedis(opt.redisConnector, cfg, logger)
+		locker, err = newRedis(opt.redisConnector, cfg, logger)
 	case DriverEtcd:
 		if opt.etcdConnector == nil {
 			return nil, xerrors.New("dlock: etcd connector is required, use WithEtcdConnector")
 		}
-		return newEtcd(opt.etcdConnector, cfg, logger)
+		locker, err = newEtcd(opt.etcdConnector, cfg, logger)
 	default:
 		return nil, xerrors.New("dlock: unsupported driver: " + string(cfg.Driver))
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 注入 Meter 或配置了告警阈值时，在 Lock/Unlock 间计时
+	if opt.meter != nil || cfg.HoldWarnThreshold > 0 {
+		locker = newObserved(locker, logger, opt.meter, cfg.HoldWarnThreshold)
+	}
+	return locker, nil
 }
@@ -18,6 +18,9 @@ var (
 	// ErrOwnershipLost 锁所有权丢失
 	ErrOwnershipLost = xerrors.New("dlock: ownership lost")
 
+	// ErrLockTimeout 在 WaitTimeout 内未获取到锁
+	ErrLockTimeout = xerrors.New("dlock: lock wait timeout")
+
 	// ErrInvalidTTL TTL 配置非法
 	ErrInvalidTTL = xerrors.New("dlock: invalid ttl")
 )
@@ -111,7 +111,7 @@ func (l *etcdLocker) lock(ctx context.Context, key string, try bool, opts ...Loc
 		// 使用官方 TryLock API 而不是超时 hack
 		lockErr = mutex.TryLock(ctx)
 	} else {
-		lockErr = mutex.Lock(ctx)
+		lockErr = l.lockWithWaitTimeout(ctx, mutex, resolveWaitTimeout(opts...))
 	}
 
 	if lockErr != nil {
@@ -122,6 +122,9 @@ func (l *etcdLocker) lock(ctx context.Context, key string, try bool, opts ...Loc
 		if lockErr == concurrency.ErrLocked {
 			return concurrency.ErrLocked
 		}
+		if lockErr == ErrLockTimeout {
+			return xerrors.Wrapf(ErrLockTimeout, "key: %s", key)
+		}
 		return xerrors.Wrap(lockErr, "failed to lock")
 	}
 
@@ -149,6 +152,22 @@ func (l *etcdLocker) lock(ctx context.Context, key string, try bool, opts ...Loc
 	return nil
 }
 
+// lockWithWaitTimeout 阻塞加锁，超过 waitTimeout 返回 ErrLockTimeout
+func (l *etcdLocker) lockWithWaitTimeout(ctx context.Context, mutex *concurrency.Mutex, waitTimeout time.Duration) error {
+	if waitTimeout <= 0 {
+		return mutex.Lock(ctx)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, waitTimeout)
+	defer cancel()
+
+	err := mutex.Lock(waitCtx)
+	if err != nil && ctx.Err() == nil && waitCtx.Err() == context.DeadlineExceeded {
+		return ErrLockTimeout
+	}
+	return err
+}
+
 func (l *etcdLocker) Unlock(ctx context.Context, key string) error {
 	l.mu.Lock()
 	entry, exists := l.locks[key]
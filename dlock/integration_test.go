@@ -621,3 +621,62 @@ func TestEtcdLocker_WithTTLRejectsSubSecond(t *testing.T) {
 	err = locker.Lock(ctx, "test:"+testkit.NewID(), WithTTL(1500*time.Millisecond))
 	require.ErrorIs(t, err, ErrInvalidTTL)
 }
+
+func TestRedisLocker_Lock_WaitTimeout(t *testing.T) {
+	ctx, cancel := testkit.NewContext(t, 30*time.Second)
+	defer cancel()
+
+	conn := testkit.NewRedisContainerConnector(t)
+	locker1 := newRedisLockerWithConn(t, conn)
+	defer locker1.Close()
+	locker2 := newRedisLockerWithConn(t, conn)
+	defer locker2.Close()
+
+	key := "test:" + testkit.NewID()
+	require.NoError(t, locker1.Lock(ctx, key))
+
+	start := time.Now()
+	err := locker2.Lock(ctx, key, WithWaitTimeout(500*time.Millisecond))
+	elapsed := time.Since(start)
+	require.ErrorIs(t, err, ErrLockTimeout)
+	require.GreaterOrEqual(t, elapsed, 500*time.Millisecond)
+	require.Less(t, elapsed, 2*time.Second)
+
+	// 等待期间 ctx 取消应立即返回 ctx 错误，而不是 ErrLockTimeout
+	cancelCtx, cancelWait := context.WithCancel(ctx)
+	time.AfterFunc(100*time.Millisecond, cancelWait)
+	start = time.Now()
+	err = locker2.Lock(cancelCtx, key, WithWaitTimeout(5*time.Second))
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotErrorIs(t, err, ErrLockTimeout)
+	require.Less(t, time.Since(start), time.Second)
+
+	require.NoError(t, locker1.Unlock(ctx, key))
+}
+
+func TestEtcdLocker_Lock_WaitTimeout(t *testing.T) {
+	ctx, cancel := testkit.NewContext(t, 30*time.Second)
+	defer cancel()
+
+	conn := testkit.NewEtcdContainerConnector(t)
+	locker1 := newEtcdLockerWithConn(t, conn)
+	defer locker1.Close()
+	locker2 := newEtcdLockerWithConn(t, conn)
+	defer locker2.Close()
+
+	key := "test:" + testkit.NewID()
+	require.NoError(t, locker1.Lock(ctx, key))
+
+	start := time.Now()
+	err := locker2.Lock(ctx, key, WithWaitTimeout(500*time.Millisecond))
+	require.ErrorIs(t, err, ErrLockTimeout)
+	require.GreaterOrEqual(t, time.Since(start), 500*time.Millisecond)
+
+	cancelCtx, cancelWait := context.WithCancel(ctx)
+	time.AfterFunc(100*time.Millisecond, cancelWait)
+	err = locker2.Lock(cancelCtx, key, WithWaitTimeout(5*time.Second))
+	require.ErrorIs(t, err, context.Canceled)
+	require.NotErrorIs(t, err, ErrLockTimeout)
+
+	require.NoError(t, locker1.Unlock(ctx, key))
+}
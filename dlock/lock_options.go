@@ -5,8 +5,9 @@ import "time"
 // lockOptions Lock 操作的选项配置
 // 用于 Lock() 和 TryLock() 方法的运行时参数
 type lockOptions struct {
-	TTL    time.Duration
-	ttlSet bool
+	TTL         time.Duration
+	ttlSet      bool
+	WaitTimeout time.Duration
 }
 
 // LockOption Lock 操作的选项函数
@@ -29,3 +30,28 @@ func WithTTL(d time.Duration) LockOption {
 		o.ttlSet = true
 	}
 }
+
+// WithWaitTimeout 设置 Lock 的最长等待时间（仅 Lock 模式有效）
+// 在 d 内未获取到锁时返回 ErrLockTimeout；等待期间 ctx 取消则返回 ctx.Err()，
+// 两者可以通过 errors.Is 区分。d <= 0 表示不限制，仅受 ctx 控制。
+// 重试间隔仍使用配置中的 RetryInterval。
+//
+// 使用示例:
+//
+//	err := locker.Lock(ctx, "key", dlock.WithWaitTimeout(500*time.Millisecond))
+//	if errors.Is(err, dlock.ErrLockTimeout) {
+//	    // 锁被长时间占用
+//	}
+func WithWaitTimeout(d time.Duration) LockOption {
+	return func(o *lockOptions) {
+		o.WaitTimeout = d
+	}
+}
+
+func resolveWaitTimeout(opts ...LockOption) time.Duration {
+	options := &lockOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return max(options.WaitTimeout, 0)
+}
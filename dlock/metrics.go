@@ -0,0 +1,16 @@
+package dlock
+
+// 指标名称常量
+const (
+	// MetricHoldDuration 锁持有时长（秒），从加锁成功到释放
+	MetricHoldDuration = "dlock_hold_duration_seconds"
+
+	// MetricAcquireWait 加锁等待时长（秒），从调用 Lock/TryLock 到加锁成功
+	MetricAcquireWait = "dlock_acquire_wait_seconds"
+)
+
+// 标签名称常量
+const (
+	// LabelKey 锁 key 标签
+	LabelKey = "key"
+)
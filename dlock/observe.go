@@ -0,0 +1,132 @@
+package dlock
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// observedLocker 锁观测包装（非导出）
+//
+// 在 Lock/Unlock 之间计时：加锁成功时记录等待时长，释放时记录持有时长；
+// 持有时长超过 HoldWarnThreshold 时打 warn，便于发现忘记释放或临界区过长的锁。
+type observedLocker struct {
+	locker    Locker
+	logger    clog.Logger
+	threshold time.Duration
+	owner     string
+	now       func() time.Time
+
+	holdHistogram metrics.Histogram
+	waitHistogram metrics.Histogram
+
+	mu       sync.Mutex
+	acquired map[string]time.Time
+}
+
+// newObserved 包装已有 Locker，增加持有时长指标与超长持有告警
+func newObserved(locker Locker, logger clog.Logger, meter metrics.Meter, threshold time.Duration) Locker {
+	l := &observedLocker{
+		locker:    locker,
+		logger:    logger,
+		threshold: threshold,
+		owner:     defaultOwner(),
+		now:       time.Now,
+		acquired:  make(map[string]time.Time),
+	}
+	if meter != nil {
+		l.holdHistogram, _ = meter.Histogram(MetricHoldDuration, "Duration a distributed lock is held", metrics.WithUnit("s"))
+		l.waitHistogram, _ = meter.Histogram(MetricAcquireWait, "Time spent waiting to acquire a distributed lock", metrics.WithUnit("s"))
+	}
+	return l
+}
+
+// defaultOwner 返回当前进程的持有者标识（hostname:pid）
+func defaultOwner() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// Lock 阻塞式加锁，成功后记录等待时长并开始计时
+func (l *observedLocker) Lock(ctx context.Context, key string, opts ...LockOption) error {
+	start := l.now()
+	if err := l.locker.Lock(ctx, key, opts...); err != nil {
+		return err
+	}
+	l.onAcquired(ctx, key, start)
+	return nil
+}
+
+// TryLock 非阻塞式加锁，成功后记录等待时长并开始计时
+func (l *observedLocker) TryLock(ctx context.Context, key string, opts ...LockOption) (bool, error) {
+	start := l.now()
+	ok, err := l.locker.TryLock(ctx, key, opts...)
+	if err != nil || !ok {
+		return ok, err
+	}
+	l.onAcquired(ctx, key, start)
+	return true, nil
+}
+
+// Unlock 释放锁并记录持有时长
+func (l *observedLocker) Unlock(ctx context.Context, key string) error {
+	err := l.locker.Unlock(ctx, key)
+
+	// 底层实现无论释放成功与否都会移除本地持有状态，这里同步结束计时
+	l.mu.Lock()
+	acquiredAt, ok := l.acquired[key]
+	delete(l.acquired, key)
+	l.mu.Unlock()
+	if ok {
+		l.onReleased(ctx, key, l.now().Sub(acquiredAt))
+	}
+	return err
+}
+
+// Close 释放所有锁，并为仍持有的锁记录持有时长
+func (l *observedLocker) Close() error {
+	err := l.locker.Close()
+
+	l.mu.Lock()
+	acquired := l.acquired
+	l.acquired = make(map[string]time.Time)
+	l.mu.Unlock()
+
+	now := l.now()
+	for key, acquiredAt := range acquired {
+		l.onReleased(context.Background(), key, now.Sub(acquiredAt))
+	}
+	return err
+}
+
+func (l *observedLocker) onAcquired(ctx context.Context, key string, start time.Time) {
+	now := l.now()
+	l.mu.Lock()
+	l.acquired[key] = now
+	l.mu.Unlock()
+
+	if l.waitHistogram != nil {
+		l.waitHistogram.Record(ctx, now.Sub(start).Seconds())
+	}
+}
+
+func (l *observedLocker) onReleased(ctx context.Context, key string, held time.Duration) {
+	if l.holdHistogram != nil {
+		l.holdHistogram.Record(ctx, held.Seconds(), metrics.L(LabelKey, key))
+	}
+	if l.threshold > 0 && held > l.threshold && l.logger != nil {
+		l.logger.WarnContext(ctx, "lock held too long",
+			clog.String("key", key),
+			clog.Duration("held", held),
+			clog.Duration("threshold", l.threshold),
+			clog.String("owner", l.owner))
+	}
+}
@@ -0,0 +1,164 @@
+package dlock
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// fakeLocker 仅记录本地持有状态的 Locker，不依赖 Redis/Etcd
+type fakeLocker struct {
+	held map[string]bool
+}
+
+func (f *fakeLocker) Lock(ctx context.Context, key string, opts ...LockOption) error {
+	if f.held[key] {
+		return ErrLockAlreadyHeld
+	}
+	f.held[key] = true
+	return nil
+}
+
+func (f *fakeLocker) TryLock(ctx context.Context, key string, opts ...LockOption) (bool, error) {
+	if f.held[key] {
+		return false, nil
+	}
+	f.held[key] = true
+	return true, nil
+}
+
+func (f *fakeLocker) Unlock(ctx context.Context, key string) error {
+	if !f.held[key] {
+		return ErrLockNotHeld
+	}
+	delete(f.held, key)
+	return nil
+}
+
+func (f *fakeLocker) Close() error {
+	f.held = make(map[string]bool)
+	return nil
+}
+
+// recordingMeter 记录直方图观测值的测试 Meter
+type recordingMeter struct {
+	metrics.Meter
+	mu      sync.Mutex
+	records map[string][]histogramRecord
+}
+
+type histogramRecord struct {
+	val    float64
+	labels []metrics.Label
+}
+
+func newRecordingMeter() *recordingMeter {
+	return &recordingMeter{Meter: metrics.Discard(), records: make(map[string][]histogramRecord)}
+}
+
+func (m *recordingMeter) Histogram(name, desc string, opts ...metrics.MetricOption) (metrics.Histogram, error) {
+	return &recordingHistogram{meter: m, name: name}, nil
+}
+
+func (m *recordingMeter) get(name string) []histogramRecord {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]histogramRecord(nil), m.records[name]...)
+}
+
+type recordingHistogram struct {
+	meter *recordingMeter
+	name  string
+}
+
+func (h *recordingHistogram) Record(ctx context.Context, val float64, labels ...metrics.Label) {
+	h.meter.mu.Lock()
+	defer h.meter.mu.Unlock()
+	h.meter.records[h.name] = append(h.meter.records[h.name], histogramRecord{val: val, labels: labels})
+}
+
+func TestObservedLocker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("持锁超过阈值后 Unlock 触发 warn 并记录持有时长", func(t *testing.T) {
+		logPath := filepath.Join(t.TempDir(), "dlock.log")
+		logger, err := clog.New(&clog.Config{Level: "info", Format: "json", Output: logPath})
+		require.NoError(t, err)
+		defer logger.Close()
+
+		meter := newRecordingMeter()
+		l := newObserved(&fakeLocker{held: make(map[string]bool)}, logger, meter, time.Second).(*observedLocker)
+		now := time.Now()
+		l.now = func() time.Time { return now }
+
+		require.NoError(t, l.Lock(ctx, "order:1"))
+		now = now.Add(3 * time.Second)
+		require.NoError(t, l.Unlock(ctx, "order:1"))
+		logger.Flush()
+
+		holds := meter.get(MetricHoldDuration)
+		require.Len(t, holds, 1)
+		require.InDelta(t, 3.0, holds[0].val, 1e-9)
+		require.Equal(t, []metrics.Label{metrics.L(LabelKey, "order:1")}, holds[0].labels)
+		require.Len(t, meter.get(MetricAcquireWait), 1)
+
+		data, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		out := string(data)
+		require.Contains(t, out, "lock held too long")
+		require.Contains(t, out, `"key":"order:1"`)
+		require.Contains(t, out, `"owner":"`+l.owner+`"`)
+	})
+
+	t.Run("未超过阈值不告警", func(t *testing.T) {
+		logPath := filepath.Join(t.TempDir(), "dlock.log")
+		logger, err := clog.New(&clog.Config{Level: "info", Format: "json", Output: logPath})
+		require.NoError(t, err)
+		defer logger.Close()
+
+		meter := newRecordingMeter()
+		l := newObserved(&fakeLocker{held: make(map[string]bool)}, logger, meter, time.Minute)
+
+		ok, err := l.TryLock(ctx, "order:2")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.NoError(t, l.Unlock(ctx, "order:2"))
+		logger.Flush()
+
+		require.Len(t, meter.get(MetricHoldDuration), 1)
+		data, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		require.False(t, strings.Contains(string(data), "lock held too long"))
+	})
+
+	t.Run("加锁失败与未持有的 Unlock 不记录", func(t *testing.T) {
+		meter := newRecordingMeter()
+		l := newObserved(&fakeLocker{held: map[string]bool{"busy": true}}, nil, meter, time.Second)
+
+		ok, err := l.TryLock(ctx, "busy")
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.ErrorIs(t, l.Unlock(ctx, "missing"), ErrLockNotHeld)
+		require.Empty(t, meter.get(MetricAcquireWait))
+		require.Empty(t, meter.get(MetricHoldDuration))
+	})
+
+	t.Run("Close 为仍持有的锁记录持有时长", func(t *testing.T) {
+		meter := newRecordingMeter()
+		l := newObserved(&fakeLocker{held: make(map[string]bool)}, nil, meter, 0)
+
+		require.NoError(t, l.Lock(ctx, "a"))
+		require.NoError(t, l.Lock(ctx, "b"))
+		require.NoError(t, l.Close())
+		require.Len(t, meter.get(MetricHoldDuration), 2)
+	})
+}
@@ -3,6 +3,7 @@ package dlock
 import (
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/connector"
+	"github.com/ceyewan/genesis/metrics"
 )
 
 // Option DLock 组件初始化选项函数
@@ -11,6 +12,7 @@ type Option func(*options)
 // options 选项结构（内部使用，小写）
 type options struct {
 	logger         clog.Logger
+	meter          metrics.Meter
 	redisConnector connector.RedisConnector
 	etcdConnector  connector.EtcdConnector
 }
@@ -25,6 +27,16 @@ func WithLogger(l clog.Logger) Option {
 	}
 }
 
+// WithMeter 注入指标 Meter
+// 注入后上报 dlock_hold_duration_seconds 与 dlock_acquire_wait_seconds 直方图
+func WithMeter(m metrics.Meter) Option {
+	return func(o *options) {
+		if m != nil {
+			o.meter = m
+		}
+	}
+}
+
 // WithRedisConnector 注入 Redis 连接器
 func WithRedisConnector(conn connector.RedisConnector) Option {
 	return func(o *options) {
@@ -108,6 +108,13 @@ func (l *redisLocker) lockWithRetry(ctx context.Context, key string, tryOnce boo
 		retryInterval = 100 * time.Millisecond
 	}
 
+	var timeout <-chan time.Time
+	if waitTimeout := resolveWaitTimeout(opts...); waitTimeout > 0 {
+		timer := time.NewTimer(waitTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
 	for {
 		entry, err := l.acquireLock(ctx, key, opts...)
 		if err != nil {
@@ -124,6 +131,8 @@ func (l *redisLocker) lockWithRetry(ctx context.Context, key string, tryOnce boo
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-timeout:
+			return xerrors.Wrapf(ErrLockTimeout, "key: %s", key)
 		case <-time.After(retryInterval):
 			continue
 		}
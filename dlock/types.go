@@ -29,6 +29,10 @@ type Config struct {
 
 	// RetryInterval 加锁重试间隔 (仅 Lock 模式有效)
 	RetryInterval time.Duration `json:"retry_interval" yaml:"retry_interval"`
+
+	// HoldWarnThreshold 锁持有时长告警阈值，释放时超过该值打 warn 日志
+	// 0 表示不告警
+	HoldWarnThreshold time.Duration `json:"hold_warn_threshold" yaml:"hold_warn_threshold"`
 }
 
 func (c *Config) setDefaults() {
@@ -50,6 +54,9 @@ func (c *Config) validate() error {
 	if c.Driver == "" {
 		return xerrors.New("dlock: driver is required")
 	}
+	if c.HoldWarnThreshold < 0 {
+		return xerrors.New("dlock: hold_warn_threshold must not be negative")
+	}
 	switch c.Driver {
 	case DriverRedis, DriverEtcd:
 		if c.Driver == DriverEtcd {
@@ -68,9 +75,11 @@ type Locker interface {
 	// Lock 阻塞式加锁
 	// 成功返回 nil，失败返回错误
 	// 如果上下文取消，返回 context.Canceled 或 context.DeadlineExceeded
+	// 如果超过 WithWaitTimeout 设置的等待时间，返回 ErrLockTimeout
 	//
 	// opts 支持的选项:
 	//   - WithTTL(duration): 设置锁的超时时间
+	//   - WithWaitTimeout(duration): 设置最长等待时间
 	Lock(ctx context.Context, key string, opts ...LockOption) error
 
 	// TryLock 非阻塞式尝试加锁
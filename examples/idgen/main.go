@@ -110,30 +110,22 @@ func snowflakeAllocatorExample() {
 		return
 	}
 
-	workerID, err := allocator.Allocate(ctx)
+	// 4. 使用分配的 WorkerID 创建 Snowflake，租约丢失后 Next 返回 ErrLeaseExpired
+	sf, release, err := idgen.NewGeneratorWithAllocator(ctx, &idgen.GeneratorConfig{
+		Mode: idgen.GeneratorModeSingleDC,
+	}, allocator, idgen.WithLogger(logger))
 	if err != nil {
-		log.Printf("Failed to allocate WorkerID: %v\n", err)
+		log.Printf("Failed to create Snowflake: %v\n", err)
 		return
 	}
-	defer allocator.Stop()
-
-	// 监听保活失败
-	go func() {
-		if err := <-allocator.KeepAlive(ctx); err != nil {
-			log.Printf("WorkerID 租约保活失败: %v\n", err)
-		}
-	}()
+	defer release()
 
-	// 4. 使用分配的 WorkerID 创建 Snowflake
-	sf, err := idgen.NewGenerator(&idgen.GeneratorConfig{
-		Mode:     idgen.GeneratorModeSingleDC,
-		WorkerID: workerID,
-	})
+	id, err := sf.Next()
 	if err != nil {
-		log.Printf("Failed to create Snowflake: %v\n", err)
+		log.Printf("Failed to generate Snowflake ID: %v\n", err)
 		return
 	}
-
+	_, _, workerID, _ := idgen.ParseGeneratorID(id, idgen.GeneratorModeSingleDC)
 	fmt.Printf("自动分配的 WorkerID: %d\n", workerID)
 
 	fmt.Println("生成 5 个 Snowflake ID:")
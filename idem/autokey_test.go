@@ -0,0 +1,136 @@
+package idem
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGinMiddleware_AutoKey(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	idemComp := newScopeTestIdem(t, "test:idem:autokey:")
+
+	var calls int32
+	r := gin.New()
+	r.Use(gin.HandlerFunc(idemComp.GinMiddleware(WithAutoKey(nil)).(func(*gin.Context))))
+	r.POST("/orders", func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		c.String(http.StatusOK, "created:"+string(body))
+	})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// handler 仍能读取完整 body
+	w := post(`{"item":"a"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, `created:{"item":"a"}`, w.Body.String())
+
+	// 相同 body 视为重复请求，直接复用结果
+	w = post(`{"item":"a"}`)
+	require.Equal(t, `created:{"item":"a"}`, w.Body.String())
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 不同 body 各自执行
+	w = post(`{"item":"b"}`)
+	require.Equal(t, `created:{"item":"b"}`, w.Body.String())
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestGinMiddleware_AutoKeyHeaderPriority(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	idemComp := newScopeTestIdem(t, "test:idem:autokey-header:")
+
+	var calls int32
+	r := gin.New()
+	r.Use(gin.HandlerFunc(idemComp.GinMiddleware(WithAutoKey(nil)).(func(*gin.Context))))
+	r.POST("/orders", func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		c.String(http.StatusOK, "ok")
+	})
+
+	// 显式幂等键优先于自动生成，相同 body 不同键各自执行
+	for _, key := range []string{"k1", "k2"} {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("same"))
+		req.Header.Set("X-Idempotency-Key", key)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestGinMiddleware_AutoKeyBodyLimit(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	idemComp := newScopeTestIdem(t, "test:idem:autokey-limit:")
+
+	var calls int32
+	r := gin.New()
+	r.Use(gin.HandlerFunc(idemComp.GinMiddleware(WithAutoKey(RequestHashKeyWithLimit(8))).(func(*gin.Context))))
+	r.POST("/orders", func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		c.String(http.StatusOK, string(body))
+	})
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// 超过上限时不做幂等处理，handler 仍读到完整 body
+	large := strings.Repeat("x", 32)
+	for range 2 {
+		w := post(large)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, large, w.Body.String())
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 上限以内照常去重
+	post("12345678")
+	post("12345678")
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestGinMiddleware_AutoKeyScope(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	idemComp := newScopeTestIdem(t, "test:idem:autokey-scope:")
+
+	var calls int32
+	r := gin.New()
+	r.Use(gin.HandlerFunc(idemComp.GinMiddleware(WithAutoKey(nil), WithScopeHeader("X-Tenant-ID")).(func(*gin.Context))))
+	r.POST("/orders", func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		c.String(http.StatusOK, "ok")
+	})
+
+	// 不同租户提交相同内容互不命中
+	for _, tenant := range []string{"tenant-a", "tenant-b", "tenant-a"} {
+		req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader("same"))
+		req.Header.Set("X-Tenant-ID", tenant)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,90 @@
+package idem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/cache/serializer"
+)
+
+// doOrder 含 time.Time 与嵌套结构的结果类型
+type doOrder struct {
+	ID        string
+	CreatedAt time.Time
+	Buyer     doBuyer
+	Items     []doItem
+	Tags      map[string]string
+}
+
+type doBuyer struct {
+	Name    string
+	Address *doAddress
+}
+
+type doAddress struct {
+	City string
+}
+
+type doItem struct {
+	SKU   string
+	Price float64
+}
+
+func TestDo_Serializer(t *testing.T) {
+	serializers := map[string]serializer.Serializer{
+		"json":    &serializer.JSONSerializer{},
+		"msgpack": &serializer.MessagePackSerializer{},
+	}
+
+	for name, s := range serializers {
+		t.Run(name, func(t *testing.T) {
+			idemComp, err := New(&Config{
+				Driver:     DriverMemory,
+				Prefix:     "test:idem:do:" + name + ":",
+				DefaultTTL: time.Minute,
+				LockTTL:    time.Second,
+			}, WithSerializer(s))
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			createdAt := time.Date(2026, 3, 1, 8, 30, 15, 123456789, time.FixedZone("CST", 8*3600))
+			calls := 0
+			create := func(ctx context.Context) (*doOrder, error) {
+				calls++
+				return &doOrder{
+					ID:        "ord-1",
+					CreatedAt: createdAt,
+					Buyer:     doBuyer{Name: "alice", Address: &doAddress{City: "Hangzhou"}},
+					Items:     []doItem{{SKU: "A", Price: 9.9}, {SKU: "B", Price: 0.1}},
+					Tags:      map[string]string{"channel": "app"},
+				}, nil
+			}
+
+			first, err := Do(ctx, idemComp, "order:create", create)
+			require.NoError(t, err)
+			second, err := Do(ctx, idemComp, "order:create", create)
+			require.NoError(t, err)
+
+			require.Equal(t, 1, calls, "第二次命中缓存")
+			require.Equal(t, first, second)
+			require.True(t, createdAt.Equal(second.CreatedAt), "时间还原到纳秒精度")
+			require.Equal(t, "Hangzhou", second.Buyer.Address.City)
+			require.Equal(t, []doItem{{SKU: "A", Price: 9.9}, {SKU: "B", Price: 0.1}}, second.Items)
+		})
+	}
+
+	t.Run("dest 必须是非 nil 指针", func(t *testing.T) {
+		idemComp, err := New(&Config{Driver: DriverMemory, Prefix: "test:idem:do:dest:"})
+		require.NoError(t, err)
+
+		fn := func(ctx context.Context) (any, error) { return 1, nil }
+		var n int
+		require.ErrorIs(t, idemComp.ExecuteInto(context.Background(), "k", fn, n), ErrInvalidDest)
+		require.ErrorIs(t, idemComp.ExecuteInto(context.Background(), "k", fn, (*int)(nil)), ErrInvalidDest)
+		require.NoError(t, idemComp.ExecuteInto(context.Background(), "k", fn, &n))
+		require.Equal(t, 1, n)
+	})
+}
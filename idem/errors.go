@@ -16,6 +16,9 @@ var (
 	// ErrLockLost 表示执行过程中丢失了幂等锁
 	ErrLockLost = xerrors.New("idem: lock lost during execution")
 
+	// ErrInvalidDest ExecuteInto 的 dest 不是非 nil 指针
+	ErrInvalidDest = xerrors.New("idem: dest must be a non-nil pointer")
+
 	// ErrResultNotFound 结果未找到（内部使用）
 	ErrResultNotFound = xerrors.New("idem: result not found")
 )
@@ -7,10 +7,20 @@
 //   - 业务执行失败不会缓存结果，后续允许重试
 //
 // 当前组件提供四个入口：
-//   - Execute：手动幂等执行，适合业务逻辑直接调用
+//   - Execute / Do：手动幂等执行，适合业务逻辑直接调用；Do 按调用方的类型还原缓存结果
 //   - Consume：消息消费去重，只关心“是否已执行”
 //   - GinMiddleware：HTTP 幂等中间件
 //   - UnaryServerInterceptor：gRPC 一元服务端幂等拦截器
+//   - StreamServerInterceptor：gRPC 流式服务端幂等拦截器（只缓存流的终态）
+//
+// 多租户场景下可通过 WithScope 在 context 中注入作用域（租户/用户），不同作用域的
+// 相同幂等键互不命中；中间件和拦截器也可通过 WithScopeHeader、WithScopeFunc、
+// WithScopeMetadataKey 从请求中提取作用域。
+//
+// GinMiddleware 可通过 WithAutoKey 在请求未携带幂等键时按请求内容（默认为方法、路径与
+// body 的哈希）自动生成幂等键。
+//
+// 结果默认以 JSON 编解码，可通过 WithSerializer 替换为 cache/serializer 的其他实现（如 msgpack）。
 //
 // 组件同时支持 Redis 和 Memory 两种后端。Redis 适合分布式环境，Memory 适合单机、
 // 本地开发和测试。
@@ -32,10 +42,11 @@ import (
 
 // Idempotency 幂等性组件核心接口
 //
-// 支持三种使用方式：
+// 支持四种使用方式：
 // 1. Execute: 手动调用，适合业务层直接使用
 // 2. GinMiddleware: Gin 框架中间件，自动处理 HTTP 请求幂等性
 // 3. UnaryServerInterceptor: gRPC 一元拦截器，处理单次 RPC 调用幂等性
+// 4. StreamServerInterceptor: gRPC 流式拦截器，处理流建立的幂等性
 type Idempotency interface {
 	// Execute 执行幂等操作
 	//
@@ -46,14 +57,20 @@ type Idempotency interface {
 	//
 	// 参数：
 	//   - ctx: 上下文，用于取消和超时控制
-	//   - key: 幂等性键，全局唯一标识这次操作
+	//   - key: 幂等性键，全局唯一标识这次操作；ctx 通过 WithScope 携带作用域时按作用域隔离
 	//   - fn: 业务逻辑函数，只在第一次请求时执行
 	//
 	// 返回：
-	//   - 执行结果或缓存的结果。为保证首次执行与缓存命中的类型一致，返回值会经过同一套 JSON 编解码规范化。
+	//   - 执行结果或缓存的结果。为保证首次执行与缓存命中的类型一致，返回值会经过同一套序列化器（默认 JSON）编解码规范化。
 	//   - 错误：ErrKeyEmpty、上下文错误、锁丢失错误等
 	Execute(ctx context.Context, key string, fn func(ctx context.Context) (any, error)) (any, error)
 
+	// ExecuteInto 与 Execute 语义相同，但把结果还原到调用方提供的 dest（非 nil 指针）
+	//
+	// 首次执行与缓存命中都会经过序列化器编解码后写入 dest，结构体、time.Time 等类型
+	// 可以按原类型还原，而不是退化为 map[string]any。通常通过泛型函数 Do 调用。
+	ExecuteInto(ctx context.Context, key string, fn func(ctx context.Context) (any, error), dest any) error
+
 	// Consume 用于消息消费的幂等处理
 	//
 	// 工作流程：
@@ -111,9 +128,46 @@ type Idempotency interface {
 	//   - gRPC 一元服务端拦截器
 	//
 	// 注意：
-	//   只支持一元 RPC 调用，不支持流式 RPC（因为流式交互的复杂性）。
+	//   只支持一元 RPC 调用，流式 RPC 使用 StreamServerInterceptor。
 	//   当前默认只缓存成功的 proto.Message 响应。
 	UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor
+
+	// StreamServerInterceptor 创建 gRPC 流式服务端拦截器
+	//
+	// 使用示例：
+	//   server := grpc.NewServer(
+	//       grpc.StreamInterceptor(idem.StreamServerInterceptor()),
+	//   )
+	//
+	// 工作原理：
+	//   1. 从流建立时的 gRPC metadata 提取 x-idem-key
+	//   2. 使用分布式锁防止同一幂等键的流并发执行
+	//   3. 如果缓存命中，不调用 handler，直接以缓存的终态结束流（或按配置拒绝）
+	//   4. 如果未命中，执行流 handler 并缓存终态
+	//
+	// 注意：
+	//   流的响应序列不会被缓存，重复流只能拿到终态（状态码与描述）。
+	//   默认只缓存 OK 终态，可通过 WithStreamCachedCodes 缓存确定性失败。
+	StreamServerInterceptor(opts ...InterceptorOption) grpc.StreamServerInterceptor
+}
+
+// Do 以 T 类型执行幂等操作，缓存命中时按 T 还原结果
+//
+// 使用示例：
+//
+//	order, err := idem.Do(ctx, idemp, "order:create:"+reqID, func(ctx context.Context) (*Order, error) {
+//	    return svc.CreateOrder(ctx, req)
+//	})
+func Do[T any](ctx context.Context, i Idempotency, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := i.ExecuteInto(ctx, key, func(ctx context.Context) (any, error) {
+		return fn(ctx)
+	}, &result)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return result, nil
 }
 
 // ========================================
@@ -174,7 +228,7 @@ func New(cfg *Config, opts ...Option) (Idempotency, error) {
 				clog.Duration("default_ttl", cfg.DefaultTTL),
 				clog.Duration("lock_ttl", cfg.LockTTL))
 		}
-		return newIdempotency(cfg, newRedisStore(opt.redisConn, cfg.Prefix), logger), nil
+		return newIdempotency(cfg, newRedisStore(opt.redisConn, cfg.Prefix), logger, opt.serializer), nil
 	case DriverMemory:
 		if logger != nil {
 			logger.Info("creating idem component",
@@ -183,7 +237,7 @@ func New(cfg *Config, opts ...Option) (Idempotency, error) {
 				clog.Duration("default_ttl", cfg.DefaultTTL),
 				clog.Duration("lock_ttl", cfg.LockTTL))
 		}
-		return newIdempotency(cfg, newMemoryStore(cfg.Prefix), logger), nil
+		return newIdempotency(cfg, newMemoryStore(cfg.Prefix), logger, opt.serializer), nil
 	default:
 		return nil, xerrors.New("idem: unsupported driver: " + string(cfg.Driver))
 	}
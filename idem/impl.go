@@ -2,49 +2,71 @@ package idem
 
 import (
 	"context"
-	"encoding/json"
+	"reflect"
 	"time"
 
+	"github.com/ceyewan/genesis/cache/serializer"
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/xerrors"
 )
 
 // idem 幂等性组件实现（非导出）
 type idem struct {
-	cfg    *Config
-	store  Store
-	logger clog.Logger
+	cfg        *Config
+	store      Store
+	logger     clog.Logger
+	serializer serializer.Serializer // Execute / ExecuteInto 结果的编解码
 }
 
 const processedMarker = "1"
 
 // newIdempotency 创建幂等性组件实例（内部函数）
-func newIdempotency(cfg *Config, store Store, logger clog.Logger) Idempotency {
+func newIdempotency(cfg *Config, store Store, logger clog.Logger, s serializer.Serializer) Idempotency {
+	if s == nil {
+		s = &serializer.JSONSerializer{}
+	}
 	return &idem{
-		cfg:    cfg,
-		store:  store,
-		logger: logger,
+		cfg:        cfg,
+		store:      store,
+		logger:     logger,
+		serializer: s,
 	}
 }
 
 // Execute 执行幂等操作
 func (i *idem) Execute(ctx context.Context, key string, fn func(ctx context.Context) (any, error)) (any, error) {
+	var result any
+	if err := i.ExecuteInto(ctx, key, fn, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ExecuteInto 执行幂等操作，并把结果经序列化器还原到 dest
+func (i *idem) ExecuteInto(ctx context.Context, key string, fn func(ctx context.Context) (any, error), dest any) error {
 	if key == "" {
-		return nil, ErrKeyEmpty
+		return ErrKeyEmpty
 	}
+	if v := reflect.ValueOf(dest); v.Kind() != reflect.Pointer || v.IsNil() {
+		return xerrors.Wrapf(ErrInvalidDest, "got %T", dest)
+	}
+	key = scopedKey(ctx, key)
 
-	cachedResult, token, locked, err := i.loadResultOrAcquireLock(ctx, key, decodeJSONResult)
+	decode := func(cached []byte, logger clog.Logger, key string) (any, error) {
+		return nil, i.decodeResult(cached, dest, key)
+	}
+	_, token, locked, err := i.loadResultOrAcquireLock(ctx, key, decode)
 	if err != nil {
 		if i.logger != nil {
 			i.logger.Error("failed to wait for result or lock", clog.Error(err), clog.String("key", key))
 		}
-		return nil, err
+		return err
 	}
 	if !locked {
 		if i.logger != nil {
 			i.logger.Debug("idem cache hit", clog.String("key", key))
 		}
-		return cachedResult, nil
+		return nil
 	}
 
 	lockReleased := false
@@ -69,19 +91,26 @@ func (i *idem) Execute(ctx context.Context, key string, fn func(ctx context.Cont
 		if i.logger != nil {
 			i.logger.Error("execution failed", clog.Error(err), clog.String("key", key))
 		}
-		return nil, err
+		return err
 	}
 
 	if refreshErr := collectRefreshError(refreshErrCh); refreshErr != nil {
 		if i.logger != nil {
 			i.logger.Error("lock refresh failed during execution", clog.Error(refreshErr), clog.String("key", key))
 		}
-		return nil, refreshErr
+		return refreshErr
 	}
 
-	normalizedResult, resultBytes, err := normalizeJSONResult(result, i.logger, key)
+	// 首次执行的结果同样经过编解码，保证与缓存命中时还原的值一致
+	resultBytes, err := i.serializer.Marshal(result)
 	if err != nil {
-		return nil, err
+		if i.logger != nil {
+			i.logger.Error("failed to marshal result", clog.Error(err), clog.String("key", key))
+		}
+		return xerrors.Wrap(err, "failed to marshal result")
+	}
+	if err := i.decodeResult(resultBytes, dest, key); err != nil {
+		return err
 	}
 
 	// 保存结果
@@ -89,7 +118,7 @@ func (i *idem) Execute(ctx context.Context, key string, fn func(ctx context.Cont
 		if i.logger != nil {
 			i.logger.Error("failed to set result", clog.Error(err), clog.String("key", key))
 		}
-		return nil, err
+		return err
 	}
 	lockReleased = true
 
@@ -97,7 +126,7 @@ func (i *idem) Execute(ctx context.Context, key string, fn func(ctx context.Cont
 		i.logger.Debug("execution completed and cached", clog.String("key", key))
 	}
 
-	return normalizedResult, nil
+	return nil
 }
 
 // Consume 用于消息消费的幂等处理
@@ -105,6 +134,7 @@ func (i *idem) Consume(ctx context.Context, key string, ttl time.Duration, fn fu
 	if key == "" {
 		return false, ErrKeyEmpty
 	}
+	key = scopedKey(ctx, key)
 
 	if ttl <= 0 {
 		ttl = i.cfg.DefaultTTL
@@ -297,32 +327,16 @@ func (i *idem) startLockRefresh(key string, token LockToken, onFailure context.C
 	return stop, errCh
 }
 
-func decodeJSONResult(cached []byte, logger clog.Logger, key string) (any, error) {
-	var result any
-	if err := json.Unmarshal(cached, &result); err != nil {
-		if logger != nil {
-			logger.Error("failed to unmarshal cached result", clog.Error(err), clog.String("key", key))
-		}
-		return nil, xerrors.Wrap(err, "failed to unmarshal cached result")
-	}
-	return result, nil
-}
-
-func normalizeJSONResult(result any, logger clog.Logger, key string) (any, []byte, error) {
-	resultBytes, err := json.Marshal(result)
-	if err != nil {
-		if logger != nil {
-			logger.Error("failed to marshal result", clog.Error(err), clog.String("key", key))
+// decodeResult 先清零 dest 再用序列化器还原，避免损坏结果的残留字段
+func (i *idem) decodeResult(data []byte, dest any, key string) error {
+	reflect.ValueOf(dest).Elem().SetZero()
+	if err := i.serializer.Unmarshal(data, dest); err != nil {
+		if i.logger != nil {
+			i.logger.Error("failed to unmarshal cached result", clog.Error(err), clog.String("key", key))
 		}
-		return nil, nil, xerrors.Wrap(err, "failed to marshal result")
-	}
-
-	normalizedResult, err := decodeJSONResult(resultBytes, logger, key)
-	if err != nil {
-		return nil, nil, err
+		return xerrors.Wrap(err, "failed to unmarshal cached result")
 	}
-
-	return normalizedResult, resultBytes, nil
+	return nil
 }
 
 func (i *idem) deleteCorruptedResult(ctx context.Context, key string) error {
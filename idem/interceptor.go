@@ -2,9 +2,13 @@ package idem
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -21,36 +25,15 @@ import (
 //	)
 func (i *idem) UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor {
 	// 应用选项
-	opt := interceptorOptions{
-		metadataKey: "x-idem-key",
-		shouldCache: func(msg proto.Message) bool {
-			return true
-		},
-	}
-	for _, o := range opts {
-		o(&opt)
-	}
+	opt := applyInterceptorOptions(opts...)
 
 	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
-		// 从 metadata 获取幂等键
-		md, ok := metadata.FromIncomingContext(ctx)
+		ctx, key, ok := grpcIdemKey(ctx, opt)
 		if !ok {
-			// 没有 metadata，直接调用 handler
-			return handler(ctx, req)
-		}
-
-		keys := md.Get(opt.metadataKey)
-		if len(keys) == 0 {
 			// 没有幂等键，直接调用 handler
 			return handler(ctx, req)
 		}
 
-		key := keys[0]
-		if key == "" {
-			// 幂等键为空，直接调用 handler
-			return handler(ctx, req)
-		}
-
 		if i.logger != nil {
 			i.logger.Debug("gRPC call with idem key",
 				clog.String("key", key),
@@ -128,6 +111,165 @@ func (i *idem) UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServe
 	}
 }
 
+// replayedTrailerKey 回放缓存终态时附带的 trailer 键，客户端可据此识别重复流
+const replayedTrailerKey = "x-idem-replayed"
+
+// StreamServerInterceptor 创建 gRPC 流式服务端拦截器
+// 对流的建立做幂等判定：同一幂等键只会真正执行一次流 handler
+//
+// 流场景无法缓存完整的响应序列，因此只缓存流的终态（gRPC 状态码与描述）：
+//   - handler 正常结束时缓存 OK 终态
+//   - handler 失败时默认不缓存，允许重试；WithStreamCachedCodes 指定的状态码会被缓存
+//
+// 重复键的流不会调用 handler，默认直接以缓存的终态结束，并在 trailer 中附带
+// x-idem-replayed: true；WithStreamRejectDuplicate 开启后改为返回 codes.AlreadyExists。
+//
+// 使用示例:
+//
+//	s := grpc.NewServer(
+//	    grpc.StreamInterceptor(idem.StreamServerInterceptor()),
+//	)
+func (i *idem) StreamServerInterceptor(opts ...InterceptorOption) grpc.StreamServerInterceptor {
+	opt := applyInterceptorOptions(opts...)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, key, ok := grpcIdemKey(ss.Context(), opt)
+		if !ok {
+			// 没有幂等键，直接调用 handler
+			return handler(srv, ss)
+		}
+
+		if i.logger != nil {
+			i.logger.Debug("gRPC stream with idem key",
+				clog.String("key", key),
+				clog.String("method", info.FullMethod))
+		}
+
+		cachedStatus, token, locked, err := i.loadResultOrAcquireLock(ctx, key, decodeCachedStreamStatus)
+		if err != nil {
+			if i.logger != nil {
+				i.logger.Error("failed to wait for gRPC stream idem result", clog.Error(err), clog.String("key", key))
+			}
+			return err
+		}
+		if !locked {
+			if i.logger != nil {
+				i.logger.Debug("idem cache hit for gRPC stream", clog.String("key", key))
+			}
+			if opt.rejectDuplicateStream {
+				return status.Error(codes.AlreadyExists, "idem: duplicate stream")
+			}
+			ss.SetTrailer(metadata.Pairs(replayedTrailerKey, "true"))
+			return cachedStatus.(*status.Status).Err()
+		}
+
+		lockReleased := false
+		defer func() {
+			if lockReleased {
+				return
+			}
+			if err := i.store.Unlock(ctx, key, token); err != nil {
+				if i.logger != nil {
+					i.logger.Error("failed to unlock idem key", clog.Error(err), clog.String("key", key))
+				}
+			}
+		}()
+		execCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		stopRefresh, refreshErrCh := i.startLockRefresh(key, token, cancel)
+		defer stopRefresh()
+
+		err = handler(srv, &idemServerStream{ServerStream: ss, ctx: execCtx})
+
+		if refreshErr := collectRefreshError(refreshErrCh); refreshErr != nil {
+			if i.logger != nil {
+				i.logger.Error("lock refresh failed during gRPC stream", clog.Error(refreshErr), clog.String("key", key))
+			}
+			return refreshErr
+		}
+
+		st := status.Convert(err)
+		if st.Code() != codes.OK && !slices.Contains(opt.streamCachedCodes, st.Code()) {
+			return err
+		}
+		data, marshalErr := json.Marshal(cachedStreamStatus{Code: uint32(st.Code()), Message: st.Message()})
+		if marshalErr != nil {
+			if i.logger != nil {
+				i.logger.Error("failed to encode gRPC stream status", clog.Error(marshalErr), clog.String("key", key))
+			}
+			return err
+		}
+		if setErr := i.store.SetResult(ctx, key, data, i.cfg.DefaultTTL, token); setErr != nil {
+			if i.logger != nil {
+				i.logger.Error("failed to cache gRPC stream status", clog.Error(setErr), clog.String("key", key))
+			}
+		} else {
+			lockReleased = true
+		}
+		return err
+	}
+}
+
+// idemServerStream 替换流的 context，使锁续期失败时能取消流 handler
+type idemServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *idemServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// cachedStreamStatus 流终态的缓存格式
+type cachedStreamStatus struct {
+	Code    uint32 `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func decodeCachedStreamStatus(cached []byte, _ clog.Logger, _ string) (any, error) {
+	var cs cachedStreamStatus
+	if err := json.Unmarshal(cached, &cs); err != nil {
+		return nil, err
+	}
+	return status.New(codes.Code(cs.Code), cs.Message), nil
+}
+
+// applyInterceptorOptions 应用拦截器选项并设置默认值
+func applyInterceptorOptions(opts ...InterceptorOption) interceptorOptions {
+	opt := interceptorOptions{
+		metadataKey: "x-idem-key",
+		shouldCache: func(msg proto.Message) bool {
+			return true
+		},
+	}
+	for _, o := range opts {
+		o(&opt)
+	}
+	return opt
+}
+
+// grpcIdemKey 从 incoming metadata 提取幂等键，并按作用域改写
+// 返回注入作用域后的 ctx；没有 metadata 或幂等键为空时 ok 为 false
+func grpcIdemKey(ctx context.Context, opt interceptorOptions) (context.Context, string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, "", false
+	}
+
+	keys := md.Get(opt.metadataKey)
+	if len(keys) == 0 || keys[0] == "" {
+		return ctx, "", false
+	}
+
+	if opt.scopeMetadataKey != "" {
+		if scopes := md.Get(opt.scopeMetadataKey); len(scopes) > 0 && scopes[0] != "" {
+			ctx = WithScope(ctx, scopes[0])
+		}
+	}
+	return ctx, scopedKey(ctx, keys[0]), true
+}
+
 func decodeCachedGRPCResponse(cachedResp []byte, _ clog.Logger, _ string) (any, error) {
 	var anyMsg anypb.Any
 	if err := proto.Unmarshal(cachedResp, &anyMsg); err != nil {
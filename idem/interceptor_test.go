@@ -3,12 +3,15 @@ package idem
 import (
 	"context"
 	"errors"
+	"io"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
@@ -164,6 +167,150 @@ func TestUnaryServerInterceptor(t *testing.T) {
 	})
 }
 
+// memoryServerStream 内存 gRPC 服务端流，记录发送的消息与 trailer
+type memoryServerStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	sent    []any
+	trailer metadata.MD
+}
+
+func newMemoryServerStream(key string) *memoryServerStream {
+	ctx := context.Background()
+	if key != "" {
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-idem-key", key))
+	}
+	return &memoryServerStream{ctx: ctx}
+}
+
+func (s *memoryServerStream) Context() context.Context     { return s.ctx }
+func (s *memoryServerStream) SetTrailer(md metadata.MD)    { s.trailer = metadata.Join(s.trailer, md) }
+func (s *memoryServerStream) SetHeader(metadata.MD) error  { return nil }
+func (s *memoryServerStream) SendHeader(metadata.MD) error { return nil }
+func (s *memoryServerStream) RecvMsg(any) error            { return io.EOF }
+func (s *memoryServerStream) SendMsg(m any) error {
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	newIdem := func(t *testing.T) Idempotency {
+		t.Helper()
+		idemComp, err := New(&Config{
+			Driver:     DriverMemory,
+			Prefix:     "test:idem:stream:" + testkit.NewID() + ":",
+			DefaultTTL: time.Minute,
+			LockTTL:    5 * time.Second,
+		})
+		if err != nil {
+			t.Fatalf("failed to create idem: %v", err)
+		}
+		return idemComp
+	}
+
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Stream", IsServerStream: true}
+
+	var execCount int32
+	handler := func(_ any, ss grpc.ServerStream) error {
+		atomic.AddInt32(&execCount, 1)
+		return ss.SendMsg(wrapperspb.String("chunk"))
+	}
+
+	t.Run("Duplicate Stream Replays Status", func(t *testing.T) {
+		atomic.StoreInt32(&execCount, 0)
+		interceptor := newIdem(t).StreamServerInterceptor()
+
+		first := newMemoryServerStream("stream-1")
+		if err := interceptor(nil, first, info, handler); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(first.sent) != 1 {
+			t.Errorf("expected 1 message on first stream, got %d", len(first.sent))
+		}
+
+		second := newMemoryServerStream("stream-1")
+		if err := interceptor(nil, second, info, handler); err != nil {
+			t.Fatalf("expected cached OK status, got %v", err)
+		}
+		if got := atomic.LoadInt32(&execCount); got != 1 {
+			t.Errorf("expected exec count 1, got %d", got)
+		}
+		if len(second.sent) != 0 {
+			t.Errorf("expected no message on replayed stream, got %d", len(second.sent))
+		}
+		if v := second.trailer.Get("x-idem-replayed"); len(v) != 1 || v[0] != "true" {
+			t.Errorf("expected replayed trailer, got %v", second.trailer)
+		}
+	})
+
+	t.Run("Reject Duplicate", func(t *testing.T) {
+		atomic.StoreInt32(&execCount, 0)
+		interceptor := newIdem(t).StreamServerInterceptor(WithStreamRejectDuplicate())
+
+		if err := interceptor(nil, newMemoryServerStream("stream-2"), info, handler); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		err := interceptor(nil, newMemoryServerStream("stream-2"), info, handler)
+		if status.Code(err) != codes.AlreadyExists {
+			t.Errorf("expected AlreadyExists, got %v", err)
+		}
+		if got := atomic.LoadInt32(&execCount); got != 1 {
+			t.Errorf("expected exec count 1, got %d", got)
+		}
+	})
+
+	t.Run("Failure Not Cached", func(t *testing.T) {
+		interceptor := newIdem(t).StreamServerInterceptor()
+		var calls int32
+		failing := func(_ any, _ grpc.ServerStream) error {
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.Unavailable, "backend down")
+		}
+
+		for range 2 {
+			err := interceptor(nil, newMemoryServerStream("stream-3"), info, failing)
+			if status.Code(err) != codes.Unavailable {
+				t.Errorf("expected Unavailable, got %v", err)
+			}
+		}
+		if got := atomic.LoadInt32(&calls); got != 2 {
+			t.Errorf("expected failed stream to be retried, got %d calls", got)
+		}
+	})
+
+	t.Run("Cached Failure Code", func(t *testing.T) {
+		interceptor := newIdem(t).StreamServerInterceptor(WithStreamCachedCodes(codes.InvalidArgument))
+		var calls int32
+		invalid := func(_ any, _ grpc.ServerStream) error {
+			atomic.AddInt32(&calls, 1)
+			return status.Error(codes.InvalidArgument, "bad request")
+		}
+
+		_ = interceptor(nil, newMemoryServerStream("stream-4"), info, invalid)
+		err := interceptor(nil, newMemoryServerStream("stream-4"), info, invalid)
+		if st := status.Convert(err); st.Code() != codes.InvalidArgument || st.Message() != "bad request" {
+			t.Errorf("expected cached InvalidArgument status, got %v", err)
+		}
+		if got := atomic.LoadInt32(&calls); got != 1 {
+			t.Errorf("expected exec count 1, got %d", got)
+		}
+	})
+
+	t.Run("No Key", func(t *testing.T) {
+		atomic.StoreInt32(&execCount, 0)
+		interceptor := newIdem(t).StreamServerInterceptor()
+
+		for range 2 {
+			if err := interceptor(nil, newMemoryServerStream(""), info, handler); err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+		}
+		if got := atomic.LoadInt32(&execCount); got != 2 {
+			t.Errorf("expected exec count 2, got %d", got)
+		}
+	})
+}
+
 // 辅助函数，确保 anypb 能够工作
 func init() {
 	// 注册 wrapper 类型（通常由 protoc 生成代码自动完成）
@@ -4,7 +4,10 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"io"
 	"net"
 	"net/http"
 
@@ -37,13 +40,20 @@ func (i *idem) GinMiddleware(opts ...MiddlewareOption) any {
 	}
 
 	return func(c *gin.Context) {
-		// 从请求头获取幂等键
+		// 从请求头获取幂等键，未携带时按 WithAutoKey 生成
 		key := c.GetHeader(opt.headerKey)
+		if key == "" && opt.autoKey != nil {
+			key = opt.autoKey(c)
+		}
 		if key == "" {
 			// 没有幂等键，直接放行
 			c.Next()
 			return
 		}
+		if scope := opt.extractScope(c); scope != "" {
+			c.Request = c.Request.WithContext(WithScope(c.Request.Context(), scope))
+		}
+		key = scopedKey(c.Request.Context(), key)
 
 		cachedResp, token, locked, err := i.loadResultOrAcquireLock(c.Request.Context(), key, decodeCachedHTTPResponse)
 		if err != nil {
@@ -117,6 +127,70 @@ func (i *idem) GinMiddleware(opts ...MiddlewareOption) any {
 	}
 }
 
+// defaultAutoKeyMaxBody RequestHashKey 读取 body 的默认上限（1 MiB）
+const defaultAutoKeyMaxBody int64 = 1 << 20
+
+// RequestHashKey 基于请求方法、路径（含查询参数）与 body 的 SHA-256 生成幂等键，是 WithAutoKey 的默认实现。
+//
+// 等价于 RequestHashKeyWithLimit(1 MiB)。哈希不含作用域，多租户场景需同时配置
+// WithScopeHeader 或 WithScopeFunc，否则不同租户提交相同内容会命中同一结果。
+func RequestHashKey(c *gin.Context) string {
+	return hashRequest(c, defaultAutoKeyMaxBody)
+}
+
+// RequestHashKeyWithLimit 返回 body 读取上限为 maxBody 字节的 RequestHashKey，maxBody<=0 时使用默认的 1 MiB。
+//
+// body 会被读入内存并放回 c.Request.Body，后续 handler 仍可正常读取；body 超过上限或读取失败时
+// 返回空字符串（不做幂等处理），已读取的部分同样放回，不影响 handler。
+//
+// 使用示例:
+//
+//	idemComp.GinMiddleware(idem.WithAutoKey(idem.RequestHashKeyWithLimit(4 << 20)))
+func RequestHashKeyWithLimit(maxBody int64) func(c *gin.Context) string {
+	if maxBody <= 0 {
+		maxBody = defaultAutoKeyMaxBody
+	}
+	return func(c *gin.Context) string {
+		return hashRequest(c, maxBody)
+	}
+}
+
+// hashRequest 计算请求哈希，body 最多读取 maxBody 字节
+func hashRequest(c *gin.Context, maxBody int64) string {
+	h := sha256.New()
+	h.Write([]byte(c.Request.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Request.URL.RequestURI()))
+	h.Write([]byte{0})
+
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		// 多读一个字节用于判断是否超过上限
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBody+1))
+		if err != nil || int64(len(body)) > maxBody {
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+			return ""
+		}
+		_ = c.Request.Body.Close()
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+	}
+	return "auto:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// extractScope 按配置从请求中提取幂等作用域
+func (o *middlewareOptions) extractScope(c *gin.Context) string {
+	if o.scopeFunc != nil {
+		return o.scopeFunc(c)
+	}
+	if o.scopeHeader != "" {
+		return c.GetHeader(o.scopeHeader)
+	}
+	return ""
+}
+
 type cachedHTTPResponse struct {
 	Status int         `json:"status"`
 	Header http.Header `json:"header"`
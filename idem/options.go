@@ -1,10 +1,13 @@
 package idem
 
 import (
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ceyewan/genesis/cache/serializer"
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/connector"
-
-	"google.golang.org/protobuf/proto"
 )
 
 // Option 组件初始化选项函数
@@ -18,20 +21,29 @@ type InterceptorOption func(*interceptorOptions)
 
 // options 组件初始化选项配置（内部使用，小写）
 type options struct {
-	logger    clog.Logger
-	redisConn connector.RedisConnector
+	logger     clog.Logger
+	redisConn  connector.RedisConnector
+	serializer serializer.Serializer
 }
 
 // middlewareOptions Gin 中间件选项配置（内部使用，小写）
 type middlewareOptions struct {
 	headerKey   string // 幂等键的 HTTP 头名称，默认 "X-Idempotency-Key"
 	shouldCache func(status int) bool
+	scopeHeader string                      // 作用域的 HTTP 头名称，为空表示不从请求头提取
+	scopeFunc   func(c *gin.Context) string // 自定义作用域提取函数，优先于 scopeHeader
+	autoKey     func(c *gin.Context) string // 请求未携带幂等键头时生成幂等键，为 nil 表示直接放行
 }
 
 // interceptorOptions gRPC 拦截器选项配置（内部使用，小写）
 type interceptorOptions struct {
-	metadataKey string // 幂等键的 gRPC metadata 键名，默认 "x-idem-key"
-	shouldCache func(msg proto.Message) bool
+	metadataKey      string // 幂等键的 gRPC metadata 键名，默认 "x-idem-key"
+	shouldCache      func(msg proto.Message) bool
+	scopeMetadataKey string // 作用域的 gRPC metadata 键名，为空表示不从 metadata 提取
+
+	// 以下仅对 StreamServerInterceptor 生效
+	rejectDuplicateStream bool         // 重复键的流直接拒绝，而不是回放缓存的终态
+	streamCachedCodes     []codes.Code // 除 OK 外需要缓存的终态状态码
 }
 
 // WithLogger 设置 Logger。
@@ -41,6 +53,18 @@ func WithLogger(logger clog.Logger) Option {
 	}
 }
 
+// WithSerializer 设置 Execute / Do 结果的序列化器，默认 JSON。
+//
+// 复用 cache/serializer 的抽象，可传入 &serializer.MessagePackSerializer{} 或自定义实现。
+// 更换序列化器后旧格式的缓存结果通常无法解析，会被视为损坏结果删除并重新执行。
+func WithSerializer(s serializer.Serializer) Option {
+	return func(o *options) {
+		if s != nil {
+			o.serializer = s
+		}
+	}
+}
+
 // WithRedisConnector 注入 Redis 连接器。
 func WithRedisConnector(conn connector.RedisConnector) Option {
 	return func(o *options) {
@@ -70,6 +94,39 @@ func WithHTTPStatusCacheFunc(fn func(status int) bool) MiddlewareOption {
 	}
 }
 
+// WithScopeHeader 设置 Gin 中间件从指定 HTTP 头提取幂等作用域（如 "X-Tenant-ID"）。
+// 未设置时仅使用请求 context 中通过 WithScope 注入的作用域。
+func WithScopeHeader(header string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		if header != "" {
+			o.scopeHeader = header
+		}
+	}
+}
+
+// WithScopeFunc 设置 Gin 中间件的作用域提取函数，优先于 WithScopeHeader。
+// 适合从认证中间件写入的 Claims 中提取租户或用户 ID。返回空字符串表示不隔离。
+func WithScopeFunc(fn func(c *gin.Context) string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		if fn != nil {
+			o.scopeFunc = fn
+		}
+	}
+}
+
+// WithAutoKey 设置 Gin 中间件在请求未携带幂等键头时自动生成幂等键。
+// fn 为 nil 时使用 RequestHashKey（方法 + 路径 + body 哈希，body 上限 1 MiB），适合天然幂等的写接口；
+// fn 返回空字符串表示该请求不做幂等处理。请求头中的幂等键始终优先。
+// 生成的键同样按作用域隔离，多租户场景应同时配置 WithScopeHeader 或 WithScopeFunc。
+func WithAutoKey(fn func(c *gin.Context) string) MiddlewareOption {
+	return func(o *middlewareOptions) {
+		if fn == nil {
+			fn = RequestHashKey
+		}
+		o.autoKey = fn
+	}
+}
+
 // WithMetadataKey 设置 gRPC 拦截器的幂等键 metadata 键名。
 // 默认为 "x-idem-key"。
 func WithMetadataKey(metadataKey string) InterceptorOption {
@@ -89,3 +146,32 @@ func WithGRPCResponseCacheFunc(fn func(msg proto.Message) bool) InterceptorOptio
 		}
 	}
 }
+
+// WithScopeMetadataKey 设置 gRPC 拦截器从指定 metadata 键提取幂等作用域（如 "x-tenant-id"）。
+// 未设置或 metadata 中不存在时，使用 context 中通过 WithScope 注入的作用域。
+func WithScopeMetadataKey(metadataKey string) InterceptorOption {
+	return func(o *interceptorOptions) {
+		if metadataKey != "" {
+			o.scopeMetadataKey = metadataKey
+		}
+	}
+}
+
+// WithStreamRejectDuplicate 设置流式拦截器拒绝重复幂等键的流。
+// 默认回放缓存的终态；开启后已完成的幂等键再次建立流时返回 codes.AlreadyExists。
+// 仅对 StreamServerInterceptor 生效。
+func WithStreamRejectDuplicate() InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.rejectDuplicateStream = true
+	}
+}
+
+// WithStreamCachedCodes 设置流式拦截器额外缓存的失败状态码。
+// 默认只缓存 OK 终态，失败允许重试；对确定性失败（如 codes.InvalidArgument）
+// 可通过该选项缓存，重复键的流直接返回相同的状态。
+// 仅对 StreamServerInterceptor 生效。
+func WithStreamCachedCodes(cs ...codes.Code) InterceptorOption {
+	return func(o *interceptorOptions) {
+		o.streamCachedCodes = append(o.streamCachedCodes, cs...)
+	}
+}
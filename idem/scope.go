@@ -0,0 +1,60 @@
+package idem
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// scopeKey 是 context 中作用域值的键类型
+type scopeKey struct{}
+
+// WithScope 返回携带幂等作用域的 context。
+//
+// 作用域用于多租户或按用户隔离幂等键：不同作用域下的相同幂等键互不命中，
+// 内部存储键格式为 "\x00s:{len(scope)}:{scope}:{key}"。scope 为空时不做任何隔离。
+//
+// 使用示例：
+//
+//	ctx = idem.WithScope(ctx, tenantID)
+//	result, err := idemComp.Execute(ctx, "create:order:1", fn)
+func WithScope(ctx context.Context, scope string) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// ScopeFromContext 从 context 中提取幂等作用域，不存在时返回空字符串。
+func ScopeFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	scope, _ := ctx.Value(scopeKey{}).(string)
+	return scope
+}
+
+const (
+	// reservedKeyByte 保留前缀的首字节。HTTP 头与 gRPC metadata 都不允许 NUL，
+	// 业务键几乎不会以它开头，因此保留前缀不会影响已有的无作用域键
+	reservedKeyByte = "\x00"
+	// scopedKeyPrefix 带作用域的存储键前缀
+	scopedKeyPrefix = reservedKeyByte + "s:"
+	// escapedKeyPrefix 以保留字节开头的无作用域键的转义前缀
+	escapedKeyPrefix = reservedKeyByte + "u:"
+)
+
+// scopedKey 将 context 中的作用域编码进幂等键
+//
+// 编码保证不同的 (scope, key) 组合不会得到相同的存储键：
+//   - 有作用域：\x00s:{len(scope)}:{scope}:{key}，长度前缀消除 scope 与 key 中冒号带来的歧义
+//   - 无作用域且以 \x00 开头：加 \x00u: 转义，避免客户端构造出与某个作用域相同的存储键
+//   - 其余无作用域键保持原样（包括以 s:、u: 开头的键），与未引入作用域前的存储键逐字节一致，
+//     升级后已有的幂等记录照常命中
+func scopedKey(ctx context.Context, key string) string {
+	scope := ScopeFromContext(ctx)
+	if scope == "" {
+		if strings.HasPrefix(key, reservedKeyByte) {
+			return escapedKeyPrefix + key
+		}
+		return key
+	}
+	return scopedKeyPrefix + strconv.Itoa(len(scope)) + ":" + scope + ":" + key
+}
@@ -0,0 +1,231 @@
+package idem
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func newScopeTestIdem(t *testing.T, prefix string) Idempotency {
+	t.Helper()
+
+	idemComp, err := New(&Config{
+		Driver:     DriverMemory,
+		Prefix:     prefix,
+		DefaultTTL: time.Minute,
+		LockTTL:    time.Second,
+	})
+	require.NoError(t, err)
+	return idemComp
+}
+
+func TestExecute_ScopeIsolation(t *testing.T) {
+	t.Parallel()
+
+	idemComp := newScopeTestIdem(t, "test:idem:scope:")
+	key := "create:order:1"
+
+	var calls int32
+	run := func(ctx context.Context, value string) any {
+		result, err := idemComp.Execute(ctx, key, func(ctx context.Context) (any, error) {
+			atomic.AddInt32(&calls, 1)
+			return value, nil
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	tenantA := WithScope(context.Background(), "tenant-a")
+	tenantB := WithScope(context.Background(), "tenant-b")
+
+	require.Equal(t, "a-1", run(tenantA, "a-1"))
+	require.Equal(t, "b-1", run(tenantB, "b-1"))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 同作用域同键复用结果
+	require.Equal(t, "a-1", run(tenantA, "a-2"))
+	require.Equal(t, "b-1", run(tenantB, "b-2"))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 无作用域与有作用域互不命中
+	require.Equal(t, "global", run(context.Background(), "global"))
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestConsume_ScopeIsolation(t *testing.T) {
+	t.Parallel()
+
+	idemComp := newScopeTestIdem(t, "test:idem:scope-consume:")
+	fn := func(ctx context.Context) error { return nil }
+
+	executed, err := idemComp.Consume(WithScope(context.Background(), "user-1"), "msg-1", 0, fn)
+	require.NoError(t, err)
+	require.True(t, executed)
+
+	executed, err = idemComp.Consume(WithScope(context.Background(), "user-2"), "msg-1", 0, fn)
+	require.NoError(t, err)
+	require.True(t, executed)
+
+	executed, err = idemComp.Consume(WithScope(context.Background(), "user-1"), "msg-1", 0, fn)
+	require.NoError(t, err)
+	require.False(t, executed)
+}
+
+func TestGinMiddleware_ScopeHeader(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	idemComp := newScopeTestIdem(t, "test:idem:scope-http:")
+
+	var calls int32
+	r := gin.New()
+	r.Use(gin.HandlerFunc(idemComp.GinMiddleware(WithScopeHeader("X-Tenant-ID")).(func(*gin.Context))))
+	r.POST("/orders", func(c *gin.Context) {
+		n := atomic.AddInt32(&calls, 1)
+		c.JSON(http.StatusOK, gin.H{"call": n})
+	})
+
+	do := func(tenant string) string {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set("X-Idempotency-Key", "same-key")
+		req.Header.Set("X-Tenant-ID", tenant)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	first := do("tenant-a")
+	second := do("tenant-b")
+	require.NotEqual(t, first, second)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	require.Equal(t, first, do("tenant-a"))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestGinMiddleware_ScopeFunc(t *testing.T) {
+	t.Parallel()
+
+	gin.SetMode(gin.TestMode)
+	idemComp := newScopeTestIdem(t, "test:idem:scope-func:")
+
+	var calls int32
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-User"))
+		c.Next()
+	})
+	r.Use(gin.HandlerFunc(idemComp.GinMiddleware(WithScopeFunc(func(c *gin.Context) string {
+		return c.GetString("user_id")
+	})).(func(*gin.Context))))
+	r.POST("/orders", func(c *gin.Context) {
+		atomic.AddInt32(&calls, 1)
+		c.Status(http.StatusOK)
+	})
+
+	for _, user := range []string{"u1", "u2", "u1"} {
+		req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+		req.Header.Set("X-Idempotency-Key", "same-key")
+		req.Header.Set("X-User", user)
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestUnaryServerInterceptor_ScopeMetadataKey(t *testing.T) {
+	t.Parallel()
+
+	idemComp := newScopeTestIdem(t, "test:idem:scope-grpc:")
+	interceptor := idemComp.UnaryServerInterceptor(WithScopeMetadataKey("x-tenant-id"))
+
+	var calls int32
+	handler := func(ctx context.Context, req any) (any, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return wrapperspb.Int32(n), nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Create"}
+
+	call := func(tenant string) int32 {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+			"x-idem-key", "same-key",
+			"x-tenant-id", tenant,
+		))
+		resp, err := interceptor(ctx, nil, info, handler)
+		require.NoError(t, err)
+		return resp.(*wrapperspb.Int32Value).GetValue()
+	}
+
+	require.Equal(t, int32(1), call("tenant-a"))
+	require.Equal(t, int32(2), call("tenant-b"))
+	require.Equal(t, int32(1), call("tenant-a"))
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestScopedKey_Unambiguous(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		scope, key string
+	}{
+		{"", "tenant-a:order-1"},
+		{"tenant-a", "order-1"},
+		{"a", "b:c"},
+		{"a:b", "c"},
+		{"", "s:8:tenant-a:order-1"},
+		{"", "\x00s:8:tenant-a:order-1"},
+		{"", "\x00u:\x00s:8:tenant-a:order-1"},
+		{"", "plain"},
+	}
+	seen := make(map[string]int)
+	for i, c := range cases {
+		ctx := context.Background()
+		if c.scope != "" {
+			ctx = WithScope(ctx, c.scope)
+		}
+		stored := scopedKey(ctx, c.key)
+		if j, ok := seen[stored]; ok {
+			t.Fatalf("case %d %+v and case %d %+v share stored key %q", i, c, j, cases[j], stored)
+		}
+		seen[stored] = i
+	}
+
+	// 不以保留字节开头的无作用域键保持原样，兼容已有存储
+	for _, key := range []string{"plain", "s:8:tenant-a:order-1", "u:order-1"} {
+		require.Equal(t, key, scopedKey(context.Background(), key))
+	}
+}
+
+func TestExecute_UnscopedKeyCannotHitScopedResult(t *testing.T) {
+	t.Parallel()
+
+	idemComp := newScopeTestIdem(t, "test:idem:scope-collision:")
+	run := func(ctx context.Context, key, value string) any {
+		result, err := idemComp.Execute(ctx, key, func(ctx context.Context) (any, error) {
+			return value, nil
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	tenantA := WithScope(context.Background(), "tenant-a")
+	require.Equal(t, "secret-of-a", run(tenantA, "x", "secret-of-a"))
+
+	// 客户端在无作用域的请求里伪造 "{scope}:{key}" 或编码后的存储键，都不能命中 tenant-a 的结果
+	require.Equal(t, "attacker-1", run(context.Background(), "tenant-a:x", "attacker-1"))
+	require.Equal(t, "attacker-2", run(context.Background(), "\x00s:8:tenant-a:x", "attacker-2"))
+	require.Equal(t, "attacker-3", run(context.Background(), "s:8:tenant-a:x", "attacker-3"))
+
+	// scope 与 key 中的冒号不会互相串位
+	require.Equal(t, "a|b:c", run(WithScope(context.Background(), "a"), "b:c", "a|b:c"))
+	require.Equal(t, "a:b|c", run(WithScope(context.Background(), "a:b"), "c", "a:b|c"))
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"math/rand/v2"
-	"sync"
 	"time"
 
 	"github.com/ceyewan/genesis/clog"
@@ -14,48 +13,26 @@ import (
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
 
-// ========================================
-// Allocator 接口 (WorkerID Allocation)
-// ========================================
-
-// Allocator WorkerID 分配器接口
-// 用于在集群环境中自动分配唯一的 WorkerID，避免手动配置冲突
-type Allocator interface {
-	// Allocate 分配 WorkerID（阻塞直到分配成功）
-	Allocate(ctx context.Context) (int64, error)
-
-	// KeepAlive 启动后台保活并返回错误通道
-	// 保活失败时会向返回的通道发送错误
-	KeepAlive(ctx context.Context) <-chan error
-
-	// Stop 停止保活并释放资源
-	Stop()
-}
-
 // ========================================
 // 统一工厂函数
 // ========================================
 
-// NewAllocator 创建 WorkerID 分配器
+// NewAllocator 创建基于 Redis/Etcd 租约的 WorkerIDAllocator
 // 根据 cfg.Driver 选择 redis 或 etcd 实现
 //
+// 每次 Allocate 抢占一个空闲 WorkerID 并在后台保活，保活失败时通过 WorkerLease.Lost 通知，
+// WorkerLease.Release 停止保活并释放 WorkerID。通常直接交给 NewGeneratorWithAllocator 使用。
+//
 // 使用示例:
 //
-//	// Redis 分配器
 //	allocator, _ := idgen.NewAllocator(&idgen.AllocatorConfig{
 //	    Driver: "redis",
 //	    MaxID:  512,
 //	}, idgen.WithRedisConnector(redisConn))
 //
-//	workerID, _ := allocator.Allocate(ctx)
-//	defer allocator.Stop()
-//
-//	go func() {
-//	    if err := <-allocator.KeepAlive(ctx); err != nil {
-//	        // 处理保活失败
-//	    }
-//	}()
-func NewAllocator(cfg *AllocatorConfig, opts ...Option) (Allocator, error) {
+//	gen, release, _ := idgen.NewGeneratorWithAllocator(ctx, &idgen.GeneratorConfig{}, allocator)
+//	defer release()
+func NewAllocator(cfg *AllocatorConfig, opts ...Option) (WorkerIDAllocator, error) {
 	if cfg == nil {
 		return nil, xerrors.WithCode(ErrInvalidInput, "config_nil")
 	}
@@ -93,22 +70,63 @@ func NewAllocator(cfg *AllocatorConfig, opts ...Option) (Allocator, error) {
 // Redis 实现
 // ========================================
 
+const (
+	// redisAllocateScript 从 offset 开始环形遍历，原子分配 WorkerID
+	redisAllocateScript = `
+		local prefix = KEYS[1]
+		local value = ARGV[1]
+		local ttl = tonumber(ARGV[2])
+		local max_id = tonumber(ARGV[3])
+		local offset = tonumber(ARGV[4])
+
+		-- 从 offset 开始环形遍历
+		for i = 0, max_id - 1 do
+			local id = (offset + i) % max_id
+			local key = prefix .. ":" .. id
+			if redis.call("SET", key, value, "NX", "EX", ttl) then
+				return id
+			end
+		end
+		return -1
+	`
+
+	// redisRenewScript 仅当 key 仍属于本实例时续期
+	redisRenewScript = `
+		local key = KEYS[1]
+		local expected = ARGV[1]
+		local ttl = tonumber(ARGV[2])
+
+		local current = redis.call("GET", key)
+		if not current or current ~= expected then
+			return 0
+		end
+
+		redis.call("EXPIRE", key, ttl)
+		return 1
+	`
+
+	// redisReleaseScript 仅当 key 仍属于本实例时删除
+	redisReleaseScript = `
+		local key = KEYS[1]
+		local expected = ARGV[1]
+
+		local current = redis.call("GET", key)
+		if current and current == expected then
+			return redis.call("DEL", key)
+		end
+		return 0
+	`
+)
+
 // redisAllocator Redis 实现的 WorkerID 分配器
 type redisAllocator struct {
 	redis  connector.RedisConnector
 	cfg    *AllocatorConfig
 	logger clog.Logger
-
-	mu            sync.Mutex
-	stopOnce      sync.Once
-	instanceID    int64
-	instanceValue string
-	redisKey      string
-	stopCh        chan struct{}
 }
 
 // newRedisAllocator 创建 Redis 分配器
-func newRedisAllocator(cfg *AllocatorConfig, redis connector.RedisConnector, logger clog.Logger) (Allocator, error) {
+func newRedisAllocator(cfg *AllocatorConfig, redis connector.RedisConnector, logger clog.Logger) (WorkerIDAllocator, error) {
 	if logger == nil {
 		logger = clog.Discard()
 	}
@@ -117,206 +135,110 @@ func newRedisAllocator(cfg *AllocatorConfig, redis connector.RedisConnector, log
 		redis:  redis,
 		cfg:    cfg,
 		logger: logger.With(clog.String("component", "allocator")),
-		stopCh: make(chan struct{}),
 	}, nil
 }
 
-// Allocate 分配 WorkerID（使用随机起点遍历优化并发性能）
-func (a *redisAllocator) Allocate(ctx context.Context) (int64, error) {
-	a.mu.Lock()
-	if a.redisKey != "" {
-		defer a.mu.Unlock()
-		return 0, xerrors.WithCode(ErrAlreadyAllocated, "worker_id_already_allocated")
-	}
-	defer a.mu.Unlock()
-
-	if a.redis == nil {
-		return 0, xerrors.WithCode(ErrConnectorNil, "redis_connector_required")
-	}
-
+// Allocate 分配 WorkerID 并启动后台保活（使用随机起点遍历优化并发性能）
+func (a *redisAllocator) Allocate(ctx context.Context) (*WorkerLease, error) {
 	client := a.redis.GetClient()
 	if client == nil {
-		return 0, xerrors.WithCode(ErrConnectorNil, "redis_client_required")
+		return nil, xerrors.WithCode(ErrConnectorNil, "redis_client_required")
 	}
 
 	// 随机起点，减少并发冲突
 	offset := rand.Int64N(int64(a.cfg.MaxID))
 
-	// Lua 脚本：从 offset 开始环形遍历，原子分配 WorkerID
-	script := `
-		local prefix = KEYS[1]
-		local value = ARGV[1]
-		local ttl = tonumber(ARGV[2])
-		local max_id = tonumber(ARGV[3])
-		local offset = tonumber(ARGV[4])
-
-		-- 从 offset 开始环形遍历
-		for i = 0, max_id - 1 do
-			local id = (offset + i) % max_id
-			local key = prefix .. ":" .. id
-			if redis.call("SET", key, value, "NX", "EX", ttl) then
-				return id
-			end
-		end
-		return -1
-	`
-
-	ttl := a.cfg.TTL
 	value := fmt.Sprintf("instance:%d:%d", time.Now().UnixNano(), rand.Uint64())
-	result, err := client.Eval(ctx, script, []string{a.cfg.KeyPrefix}, value, ttl, a.cfg.MaxID, offset).Result()
+	result, err := client.Eval(ctx, redisAllocateScript, []string{a.cfg.KeyPrefix}, value, a.cfg.TTL, a.cfg.MaxID, offset).Result()
 	if err != nil {
-		if a.logger != nil {
-			a.logger.Error("redis eval failed",
-				clog.Error(err),
-				clog.String("key_prefix", a.cfg.KeyPrefix),
-			)
-		}
-		return 0, xerrors.Wrap(err, "redis_eval_failed")
+		a.logger.Error("redis eval failed",
+			clog.Error(err),
+			clog.String("key_prefix", a.cfg.KeyPrefix),
+		)
+		return nil, xerrors.Wrap(err, "redis_eval_failed")
 	}
 
 	id, ok := result.(int64)
 	if !ok || id < 0 {
-		return 0, xerrors.WithCode(ErrWorkerIDExhausted, "no_available_worker_id")
+		return nil, xerrors.WithCode(ErrWorkerIDExhausted, "no_available_worker_id")
 	}
 
-	a.instanceID = id
-	a.instanceValue = value
-	a.redisKey = fmt.Sprintf("%s:%d", a.cfg.KeyPrefix, id)
-
+	key := fmt.Sprintf("%s:%d", a.cfg.KeyPrefix, id)
 	a.logger.Info("worker id allocated",
 		clog.Int64("worker_id", id),
-		clog.String("key", a.redisKey),
+		clog.String("key", key),
 	)
 
-	return id, nil
+	return startLease(id,
+		func(ctx context.Context) error { return a.keepAlive(ctx, key, value) },
+		func() { a.release(id, key, value) },
+	), nil
 }
 
-// KeepAlive 启动后台保活并返回错误通道。
-func (a *redisAllocator) KeepAlive(ctx context.Context) <-chan error {
-	errCh := make(chan error, 1)
-
-	if a.redis == nil {
-		errCh <- xerrors.WithCode(ErrConnectorNil, "redis_connector_required")
-		return errCh
-	}
-
+// keepAlive 每 TTL/3 续期一次，直到 ctx 取消或租约丢失
+//
+// 续期请求失败时继续重试；若下一次重试前 key 就会过期，则视为租约丢失，
+// 以便在其他实例能够抢占该 WorkerID 之前通知调用方。
+func (a *redisAllocator) keepAlive(ctx context.Context, key, value string) error {
 	client := a.redis.GetClient()
 	if client == nil {
-		errCh <- xerrors.WithCode(ErrConnectorNil, "redis_client_required")
-		return errCh
-	}
-
-	a.mu.Lock()
-	redisKey := a.redisKey
-	instanceValue := a.instanceValue
-	a.mu.Unlock()
-	if redisKey == "" || instanceValue == "" {
-		errCh <- xerrors.WithCode(ErrInvalidInput, "allocate_must_be_called_first")
-		return errCh
+		return xerrors.WithCode(ErrConnectorNil, "redis_client_required")
 	}
 
-	go func() {
-		ticker := time.NewTicker(time.Duration(a.cfg.TTL/3) * time.Second)
-		defer ticker.Stop()
-
-		script := `
-			local key = KEYS[1]
-			local expected = ARGV[1]
-			local ttl = tonumber(ARGV[2])
-
-			local current = redis.call("GET", key)
-			if not current or current ~= expected then
-				return 0
-			end
-
-			redis.call("EXPIRE", key, ttl)
-			return 1
-		`
-
-		for {
-			select {
-			case <-a.stopCh:
-				return
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				result, err := client.Eval(
-					context.Background(),
-					script,
-					[]string{redisKey},
-					instanceValue,
-					a.cfg.TTL,
-				).Result()
-				if err != nil {
-					a.logger.Error("keep alive failed",
-						clog.Error(err),
-						clog.String("key", redisKey),
-					)
-					select {
-					case errCh <- xerrors.Wrap(err, "keep_alive_failed"):
-					default:
-					}
-					return
-				}
-
-				ok, okType := result.(int64)
-				if !okType || ok != 1 {
-					a.logger.Error("worker id ownership lost",
-						clog.String("key", redisKey),
-					)
-					select {
-					case errCh <- xerrors.WithCode(ErrLeaseExpired, "worker_id_ownership_lost"):
-					default:
-					}
-					return
-				}
-			}
+	ttl := time.Duration(a.cfg.TTL) * time.Second
+	interval := max(ttl/3, 100*time.Millisecond)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	renewedAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
 		}
-	}()
-
-	return errCh
-}
 
-// Stop 停止保活并释放资源
-func (a *redisAllocator) Stop() {
-	a.stopOnce.Do(func() {
-		close(a.stopCh)
-
-		if a.redis == nil {
-			return
-		}
-
-		client := a.redis.GetClient()
-		if client == nil {
-			return
+		result, err := client.Eval(ctx, redisRenewScript, []string{key}, value, a.cfg.TTL).Result()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if time.Since(renewedAt)+interval >= ttl {
+				a.logger.Error("keep alive failed",
+					clog.Error(err),
+					clog.String("key", key),
+				)
+				return xerrors.Wrap(err, "keep_alive_failed")
+			}
+			a.logger.Warn("keep alive failed, will retry",
+				clog.Error(err),
+				clog.String("key", key),
+			)
+			continue
 		}
 
-		a.mu.Lock()
-		redisKey := a.redisKey
-		instanceValue := a.instanceValue
-		instanceID := a.instanceID
-		a.mu.Unlock()
-		if redisKey == "" || instanceValue == "" {
-			return
+		if n, ok := result.(int64); !ok || n != 1 {
+			a.logger.Error("worker id ownership lost",
+				clog.String("key", key),
+			)
+			return xerrors.WithCode(ErrLeaseExpired, "worker_id_ownership_lost")
 		}
+		renewedAt = time.Now()
+	}
+}
 
-		script := `
-			local key = KEYS[1]
-			local expected = ARGV[1]
-
-			local current = redis.call("GET", key)
-			if current and current == expected then
-				return redis.call("DEL", key)
-			end
-			return 0
-		`
+// release 删除仍属于本实例的 key
+func (a *redisAllocator) release(id int64, key, value string) {
+	client := a.redis.GetClient()
+	if client == nil {
+		return
+	}
 
-		_, _ = client.Eval(context.Background(), script, []string{redisKey}, instanceValue).Result()
-		a.logger.Info("worker id released",
-			clog.Int64("worker_id", instanceID),
-			clog.String("key", redisKey),
-		)
-	})
+	_, _ = client.Eval(context.Background(), redisReleaseScript, []string{key}, value).Result()
+	a.logger.Info("worker id released",
+		clog.Int64("worker_id", id),
+		clog.String("key", key),
+	)
 }
 
 // ========================================
@@ -328,17 +250,10 @@ type etcdAllocator struct {
 	client *clientv3.Client
 	cfg    *AllocatorConfig
 	logger clog.Logger
-
-	mu       sync.Mutex
-	stopOnce sync.Once
-	leaseID  clientv3.LeaseID
-	workerID int64
-	etcdKey  string
-	stopCh   chan struct{}
 }
 
 // newEtcdAllocator 创建 Etcd 分配器
-func newEtcdAllocator(cfg *AllocatorConfig, etcdConn connector.EtcdConnector, logger clog.Logger) (Allocator, error) {
+func newEtcdAllocator(cfg *AllocatorConfig, etcdConn connector.EtcdConnector, logger clog.Logger) (WorkerIDAllocator, error) {
 	if logger == nil {
 		logger = clog.Discard()
 	}
@@ -347,30 +262,20 @@ func newEtcdAllocator(cfg *AllocatorConfig, etcdConn connector.EtcdConnector, lo
 		client: etcdConn.GetClient(),
 		cfg:    cfg,
 		logger: logger.With(clog.String("component", "allocator")),
-		stopCh: make(chan struct{}),
 	}, nil
 }
 
-// Allocate 分配 WorkerID（使用随机起点遍历优化并发性能）
-func (a *etcdAllocator) Allocate(ctx context.Context) (int64, error) {
-	a.mu.Lock()
-	if a.leaseID != 0 || a.etcdKey != "" {
-		defer a.mu.Unlock()
-		return 0, xerrors.WithCode(ErrAlreadyAllocated, "worker_id_already_allocated")
-	}
-	defer a.mu.Unlock()
-
+// Allocate 分配 WorkerID 并启动后台保活（使用随机起点遍历优化并发性能）
+func (a *etcdAllocator) Allocate(ctx context.Context) (*WorkerLease, error) {
 	if a.client == nil {
-		return 0, xerrors.WithCode(ErrConnectorNil, "etcd_client_required")
+		return nil, xerrors.WithCode(ErrConnectorNil, "etcd_client_required")
 	}
 
 	// 创建 Lease
 	lease, err := a.client.Grant(ctx, int64(a.cfg.TTL))
 	if err != nil {
-		if a.logger != nil {
-			a.logger.Error("etcd grant lease failed", clog.Error(err))
-		}
-		return 0, xerrors.Wrap(err, "etcd_grant_failed")
+		a.logger.Error("etcd grant lease failed", clog.Error(err))
+		return nil, xerrors.Wrap(err, "etcd_grant_failed")
 	}
 
 	value := fmt.Sprintf("host:%d", time.Now().UnixNano())
@@ -380,7 +285,7 @@ func (a *etcdAllocator) Allocate(ctx context.Context) (int64, error) {
 
 	// 从 offset 开始环形遍历，尝试抢占 WorkerID
 	for i := 0; i < a.cfg.MaxID; i++ {
-		id := (offset + i) % a.cfg.MaxID
+		id := int64((offset + i) % a.cfg.MaxID)
 		key := fmt.Sprintf("%s:%d", a.cfg.KeyPrefix, id)
 
 		// 使用事务实现 CAS：如果 key 不存在（ModRevision == 0），则创建
@@ -390,125 +295,74 @@ func (a *etcdAllocator) Allocate(ctx context.Context) (int64, error) {
 			Commit()
 		if err != nil {
 			// 清理已创建的 Lease
-			if _, revokeErr := a.client.Revoke(context.Background(), lease.ID); revokeErr != nil {
-				if a.logger != nil {
-					a.logger.Warn("etcd revoke lease failed during cleanup", clog.Error(revokeErr))
-				}
-			}
-			if a.logger != nil {
-				a.logger.Error("etcd txn failed",
-					clog.Error(err),
-					clog.String("key", key),
-				)
-			}
-			return 0, xerrors.Wrap(err, "etcd_txn_failed")
+			a.revoke(lease.ID)
+			a.logger.Error("etcd txn failed",
+				clog.Error(err),
+				clog.String("key", key),
+			)
+			return nil, xerrors.Wrap(err, "etcd_txn_failed")
 		}
 
 		if resp.Succeeded {
-			a.leaseID = lease.ID
-			a.workerID = int64(id)
-			a.etcdKey = key
-
 			a.logger.Info("worker id allocated",
-				clog.Int64("worker_id", int64(id)),
+				clog.Int64("worker_id", id),
 				clog.String("key", key),
 				clog.Int64("lease_id", int64(lease.ID)),
 			)
 
-			return int64(id), nil
+			return startLease(id,
+				func(ctx context.Context) error { return a.keepAlive(ctx, lease.ID) },
+				func() {
+					// 撤销 Lease，关联的 key 会自动删除
+					_, _ = a.client.Revoke(context.Background(), lease.ID)
+					a.logger.Info("worker id released",
+						clog.Int64("worker_id", id),
+						clog.String("key", key),
+						clog.Int64("lease_id", int64(lease.ID)),
+					)
+				},
+			), nil
 		}
 	}
 
 	// 所有 ID 都被占用，清理 Lease
-	if _, revokeErr := a.client.Revoke(context.Background(), lease.ID); revokeErr != nil {
-		if a.logger != nil {
-			a.logger.Warn("etcd revoke lease failed during cleanup", clog.Error(revokeErr))
-		}
-	}
-	return 0, xerrors.WithCode(ErrWorkerIDExhausted, "no_available_worker_id")
+	a.revoke(lease.ID)
+	return nil, xerrors.WithCode(ErrWorkerIDExhausted, "no_available_worker_id")
 }
 
-// KeepAlive 启动后台保活并返回错误通道。
-func (a *etcdAllocator) KeepAlive(ctx context.Context) <-chan error {
-	errCh := make(chan error, 1)
-
-	if a.client == nil {
-		errCh <- xerrors.WithCode(ErrConnectorNil, "etcd_client_required")
-		return errCh
-	}
-
-	a.mu.Lock()
-	leaseID := a.leaseID
-	a.mu.Unlock()
-	if leaseID == 0 {
-		errCh <- xerrors.WithCode(ErrInvalidInput, "allocate_must_be_called_first")
-		return errCh
+// keepAlive 依赖 etcd 客户端自动续约，直到 ctx 取消或租约失效
+func (a *etcdAllocator) keepAlive(ctx context.Context, leaseID clientv3.LeaseID) error {
+	kaCh, err := a.client.KeepAlive(ctx, leaseID)
+	if err != nil {
+		a.logger.Error("etcd keep alive failed",
+			clog.Error(err),
+			clog.Int64("lease_id", int64(leaseID)),
+		)
+		return xerrors.Wrap(err, "keep_alive_failed")
 	}
 
-	go func() {
-		// 启动 KeepAlive
-		kaCh, err := a.client.KeepAlive(ctx, leaseID)
-		if err != nil {
-			a.logger.Error("etcd keep alive failed",
-				clog.Error(err),
-				clog.Int64("lease_id", int64(leaseID)),
-			)
-			select {
-			case errCh <- xerrors.Wrap(err, "keep_alive_failed"):
-			default:
-			}
-			return
-		}
-
-		for {
-			select {
-			case <-a.stopCh:
-				return
-			case <-ctx.Done():
-				return
-			case ka, ok := <-kaCh:
-				if !ok || ka == nil {
-					// KeepAlive 通道关闭或返回 nil，表示租约已失效
-					a.logger.Error("lease expired",
-						clog.Int64("lease_id", int64(leaseID)),
-					)
-					select {
-					case errCh <- xerrors.WithCode(ErrLeaseExpired, "lease_expired"):
-					default:
-					}
-					return
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ka, ok := <-kaCh:
+			if !ok || ka == nil {
+				if ctx.Err() != nil {
+					return nil
 				}
+				// KeepAlive 通道关闭或返回 nil，表示租约已失效
+				a.logger.Error("lease expired",
+					clog.Int64("lease_id", int64(leaseID)),
+				)
+				return xerrors.WithCode(ErrLeaseExpired, "lease_expired")
 			}
 		}
-	}()
-
-	return errCh
+	}
 }
 
-// Stop 停止保活并释放资源
-func (a *etcdAllocator) Stop() {
-	a.stopOnce.Do(func() {
-		close(a.stopCh)
-
-		if a.client == nil {
-			return
-		}
-
-		a.mu.Lock()
-		leaseID := a.leaseID
-		workerID := a.workerID
-		etcdKey := a.etcdKey
-		a.mu.Unlock()
-		if leaseID == 0 {
-			return
-		}
-
-		// 撤销 Lease，关联的 key 会自动删除
-		_, _ = a.client.Revoke(context.Background(), leaseID)
-		a.logger.Info("worker id released",
-			clog.Int64("worker_id", workerID),
-			clog.String("key", etcdKey),
-			clog.Int64("lease_id", int64(leaseID)),
-		)
-	})
+// revoke 分配失败时清理已创建的 Lease
+func (a *etcdAllocator) revoke(leaseID clientv3.LeaseID) {
+	if _, err := a.client.Revoke(context.Background(), leaseID); err != nil {
+		a.logger.Warn("etcd revoke lease failed during cleanup", clog.Error(err))
+	}
 }
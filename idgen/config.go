@@ -1,6 +1,8 @@
 package idgen
 
 import (
+	"time"
+
 	"github.com/ceyewan/genesis/xerrors"
 )
 
@@ -17,6 +19,9 @@ const (
 
 	// GeneratorModeMultiDC 使用 41bit 时间戳 + 5bit datacenter + 5bit worker + 12bit sequence。
 	GeneratorModeMultiDC GeneratorMode = "multi_dc"
+
+	// GeneratorModeCustom 使用 TimestampBits + WorkerBits + SequenceBits 自定义位布局，不含 datacenter 字段。
+	GeneratorModeCustom GeneratorMode = "custom"
 )
 
 // GeneratorConfig ID 生成器配置 (Snowflake)
@@ -31,6 +36,17 @@ type GeneratorConfig struct {
 	// DatacenterID 数据中心 ID。
 	// single_dc 模式下必须为 0，multi_dc 模式范围 [0, 31]。
 	DatacenterID int64 `yaml:"datacenter_id" json:"datacenter_id"`
+
+	// Epoch 时间字段的起点，零值使用 2024-01-01T00:00:00Z，不能晚于当前时间。
+	// 所有模式均生效，解析 ID 时须使用相同的 Epoch。
+	Epoch time.Time `yaml:"epoch" json:"epoch"`
+
+	// TimestampBits、WorkerBits、SequenceBits 仅 custom 模式使用，三者均需大于 0 且总和为 63。
+	// WorkerID 范围 [0, 2^WorkerBits-1]，每毫秒最多生成 2^SequenceBits 个 ID，
+	// 时间字段可用 2^TimestampBits 毫秒（41bit 约 69 年）。
+	TimestampBits int `yaml:"timestamp_bits" json:"timestamp_bits"`
+	WorkerBits    int `yaml:"worker_bits" json:"worker_bits"`
+	SequenceBits  int `yaml:"sequence_bits" json:"sequence_bits"`
 }
 
 func (c *GeneratorConfig) setDefaults() {
@@ -55,10 +71,28 @@ func (c *GeneratorConfig) validate() error {
 		if c.DatacenterID < 0 || c.DatacenterID > 31 {
 			return xerrors.WithCode(ErrInvalidInput, "datacenter_id_out_of_range")
 		}
+	case GeneratorModeCustom:
+		if c.TimestampBits <= 0 || c.WorkerBits <= 0 || c.SequenceBits <= 0 ||
+			c.TimestampBits+c.WorkerBits+c.SequenceBits != idBits {
+			return xerrors.WithCode(ErrInvalidInput, "invalid_bit_layout")
+		}
+		if c.WorkerID < 0 || c.WorkerID > c.layout().maxWorker() {
+			return xerrors.WithCode(ErrInvalidInput, "worker_id_out_of_range")
+		}
+		if c.DatacenterID != 0 {
+			return xerrors.WithCode(ErrInvalidInput, "datacenter_id_must_be_zero")
+		}
 	default:
 		return xerrors.WithCode(ErrInvalidInput, "unsupported_generator_mode")
 	}
 
+	if c.Mode != GeneratorModeCustom && (c.TimestampBits != 0 || c.WorkerBits != 0 || c.SequenceBits != 0) {
+		return xerrors.WithCode(ErrInvalidInput, "bit_layout_requires_custom_mode")
+	}
+	if c.Epoch.After(time.Now()) {
+		return xerrors.WithCode(ErrInvalidInput, "epoch_in_future")
+	}
+
 	return nil
 }
 
@@ -6,9 +6,6 @@ var (
 	// ErrConnectorNil 连接器为空
 	ErrConnectorNil = xerrors.New("idgen: connector is nil")
 
-	// ErrAlreadyAllocated WorkerID 已分配
-	ErrAlreadyAllocated = xerrors.New("idgen: worker id already allocated")
-
 	// ErrWorkerIDExhausted WorkerID 已耗尽
 	ErrWorkerIDExhausted = xerrors.New("idgen: no available worker id")
 
@@ -18,6 +15,6 @@ var (
 	// ErrInvalidInput 无效的输入
 	ErrInvalidInput = xerrors.New("idgen: invalid input")
 
-	// ErrLeaseExpired Etcd Lease 已过期
+	// ErrLeaseExpired WorkerID 租约已丢失（过期或被其他实例占用）
 	ErrLeaseExpired = xerrors.New("idgen: lease expired")
 )
@@ -14,16 +14,19 @@
 //   - 需要同一业务键下严格递增时使用 Sequencer
 //   - 需要为多个实例自动分配 WorkerID 时使用 Allocator
 //
-// Generator 当前支持两种位布局模式：
+// Generator 当前支持三种位布局模式：
 //
 //   - single_dc: 41bit 时间戳、10bit worker、12bit sequence
 //   - multi_dc: 41bit 时间戳、5bit datacenter、5bit worker、12bit sequence
+//   - custom: TimestampBits + WorkerBits + SequenceBits 自定义，三者之和为 63
 //
-// 时间字段使用固定自定义 epoch 2024-01-01T00:00:00Z，调用 Next 或 NextString 时会显式返回错误，
+// 时间字段默认使用 epoch 2024-01-01T00:00:00Z，可通过 GeneratorConfig.Epoch 自定义；
+// 自定义 Epoch 或 custom 模式的 ID 需用 ParseGeneratorIDWithConfig 解析。调用 Next 或 NextString 时会显式返回错误，
 // 以便调用方在时钟回拨等异常情况下做出停机、告警或重试决策。
 //
-// Sequencer 当前只支持 Redis。Allocator 支持 Redis 和 Etcd，其中 KeepAlive 会启动后台保活并返回错误通道，
-// Stop 负责释放租约资源，且实现应被视为幂等清理方法。
+// Sequencer 当前只支持 Redis。WorkerIDAllocator 是可插拔的 WorkerID 来源，NewAllocator 提供 Redis 和 Etcd 实现，
+// 分配后在后台保活并通过 WorkerLease.Lost 报告租约丢失；也可以用 WorkerIDAllocatorFunc 接入 StatefulSet 序号、
+// 环境变量等自定义来源。NewGeneratorWithAllocator 消费该接口，租约丢失后 Generator 拒绝继续生成 ID。
 //
 // 示例：
 //
@@ -64,4 +67,12 @@ type Sequencer interface {
 	// SetIfNotExists 仅当键不存在时设置序列号的值
 	// 返回 true 表示设置成功，false 表示键已存在
 	SetIfNotExists(ctx context.Context, key string, value int64) (bool, error)
+
+	// Current 返回序列号的当前值，不消费号码；键不存在时返回 0
+	Current(ctx context.Context, key string) (int64, error)
+
+	// Reset 将序列号重置为 value，之后 Next 从 value 之后继续；value 为 0 时删除该键
+	// 警告：仅用于管理和修复场景，重置到更小的值会导致已发放的号码被重复发放，
+	// 调用方应自行做好权限校验，每次重置都会记录 Warn 日志便于审计
+	Reset(ctx context.Context, key string, value int64) error
 }
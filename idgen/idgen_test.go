@@ -2,12 +2,15 @@ package idgen
 
 import (
 	"context"
+	"errors"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/ceyewan/genesis/metrics"
+	"github.com/ceyewan/genesis/xerrors"
 )
 
 type testCounter struct {
@@ -39,6 +42,10 @@ func (m *testMeter) Histogram(name, desc string, opts ...metrics.MetricOption) (
 	return nil, nil
 }
 
+func (m *testMeter) Push(ctx context.Context) error {
+	return nil
+}
+
 func (m *testMeter) Shutdown(ctx context.Context) error {
 	return nil
 }
@@ -361,6 +368,129 @@ func TestSnowflake_Uniqueness_Unit(t *testing.T) {
 	}
 }
 
+func TestSnowflake_CustomLayout_Unit(t *testing.T) {
+	t.Parallel()
+
+	cfg := &GeneratorConfig{
+		Mode:          GeneratorModeCustom,
+		WorkerID:      1<<16 - 1,
+		Epoch:         time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		TimestampBits: 39,
+		WorkerBits:    16,
+		SequenceBits:  8,
+	}
+	gen, err := NewGenerator(cfg)
+	require.NoError(t, err)
+
+	// 8bit sequence 每毫秒只有 256 个，20000 个 ID 会多次跨毫秒
+	start := time.Now().UnixMilli()
+	seen := make(map[int64]struct{}, 20000)
+	var lastID int64
+	for range 20000 {
+		id, err := gen.Next()
+		require.NoError(t, err)
+		require.Greater(t, id, lastID, "ID 单调递增")
+		_, dup := seen[id]
+		require.False(t, dup, "ID 唯一")
+		seen[id] = struct{}{}
+		lastID = id
+	}
+	end := time.Now().UnixMilli()
+
+	timestamp, datacenterID, workerID, sequence, err := ParseGeneratorIDWithConfig(lastID, cfg)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, timestamp, start)
+	require.LessOrEqual(t, timestamp, end)
+	require.EqualValues(t, 0, datacenterID)
+	require.EqualValues(t, 1<<16-1, workerID)
+	require.Less(t, sequence, int64(1<<8))
+
+	t.Run("WorkerID 上限由 WorkerBits 决定", func(t *testing.T) {
+		over := *cfg
+		over.WorkerID = 1 << 16
+		_, err := NewGenerator(&over)
+		require.ErrorIs(t, err, ErrInvalidInput)
+		require.Equal(t, "worker_id_out_of_range", xerrors.GetCode(err))
+	})
+}
+
+func TestSnowflake_CustomEpoch_Unit(t *testing.T) {
+	t.Parallel()
+
+	cfg := &GeneratorConfig{
+		Mode:         GeneratorModeMultiDC,
+		WorkerID:     3,
+		DatacenterID: 2,
+		Epoch:        time.Now().Add(-time.Hour),
+	}
+	gen, err := NewGenerator(cfg)
+	require.NoError(t, err)
+
+	id, err := gen.Next()
+	require.NoError(t, err)
+	timestamp, datacenterID, workerID, _, err := ParseGeneratorIDWithConfig(id, cfg)
+	require.NoError(t, err)
+	require.InDelta(t, time.Now().UnixMilli(), timestamp, 1000)
+	require.EqualValues(t, 2, datacenterID)
+	require.EqualValues(t, 3, workerID)
+
+	// 用默认 epoch 解析会得到错误的时间
+	defaultTimestamp, _, _, _ := ParseGeneratorID(id, GeneratorModeMultiDC)
+	require.NotEqual(t, timestamp, defaultTimestamp)
+}
+
+func TestGeneratorConfig_InvalidLayout_Unit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cfg  GeneratorConfig
+		code string
+	}{
+		{
+			name: "总和超过 63",
+			cfg:  GeneratorConfig{Mode: GeneratorModeCustom, TimestampBits: 42, WorkerBits: 10, SequenceBits: 12},
+			code: "invalid_bit_layout",
+		},
+		{
+			name: "总和不足 63",
+			cfg:  GeneratorConfig{Mode: GeneratorModeCustom, TimestampBits: 41, WorkerBits: 8, SequenceBits: 12},
+			code: "invalid_bit_layout",
+		},
+		{
+			name: "位宽为 0",
+			cfg:  GeneratorConfig{Mode: GeneratorModeCustom, TimestampBits: 51, WorkerBits: 0, SequenceBits: 12},
+			code: "invalid_bit_layout",
+		},
+		{
+			name: "custom 模式不支持 DatacenterID",
+			cfg:  GeneratorConfig{Mode: GeneratorModeCustom, TimestampBits: 41, WorkerBits: 10, SequenceBits: 12, DatacenterID: 1},
+			code: "datacenter_id_must_be_zero",
+		},
+		{
+			name: "内置模式不接受位宽配置",
+			cfg:  GeneratorConfig{Mode: GeneratorModeSingleDC, TimestampBits: 41, WorkerBits: 10, SequenceBits: 12},
+			code: "bit_layout_requires_custom_mode",
+		},
+		{
+			name: "epoch 晚于当前时间",
+			cfg:  GeneratorConfig{Mode: GeneratorModeMultiDC, Epoch: time.Now().Add(time.Hour)},
+			code: "epoch_in_future",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGenerator(&tt.cfg)
+			require.ErrorIs(t, err, ErrInvalidInput)
+			require.Equal(t, tt.code, xerrors.GetCode(err))
+
+			_, _, _, _, err = ParseGeneratorIDWithConfig(1, &tt.cfg)
+			require.ErrorIs(t, err, ErrInvalidInput)
+		})
+	}
+}
+
 // ========================================
 // Sequencer 配置单元测试
 // ========================================
@@ -428,6 +558,164 @@ func TestAllocatorConfig_Unit(t *testing.T) {
 	})
 }
 
+// memoryWorkerIDAllocator 内存实现的 WorkerIDAllocator，用于单元测试
+type memoryWorkerIDAllocator struct {
+	next     int64
+	err      error
+	lost     chan error
+	released []int64
+}
+
+func (a *memoryWorkerIDAllocator) Allocate(_ context.Context) (*WorkerLease, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	id := a.next
+	a.next++
+	lease := &WorkerLease{
+		WorkerID: id,
+		Release:  func() { a.released = append(a.released, id) },
+	}
+	if a.lost != nil {
+		lease.Lost = a.lost
+	}
+	return lease, nil
+}
+
+func TestNewGeneratorWithAllocator_Unit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("allocated worker id is used", func(t *testing.T) {
+		alloc := &memoryWorkerIDAllocator{next: 7}
+		gen, release, err := NewGeneratorWithAllocator(ctx, &GeneratorConfig{
+			Mode:     GeneratorModeMultiDC,
+			WorkerID: 1,
+		}, alloc)
+		require.NoError(t, err)
+
+		id, err := gen.Next()
+		require.NoError(t, err)
+		_, _, workerID, _ := ParseGeneratorID(id, GeneratorModeMultiDC)
+		require.Equal(t, int64(7), workerID)
+
+		release()
+		require.Equal(t, []int64{7}, alloc.released)
+	})
+
+	t.Run("allocation failure returns error", func(t *testing.T) {
+		allocErr := errors.New("no ordinal")
+		_, _, err := NewGeneratorWithAllocator(ctx, &GeneratorConfig{}, &memoryWorkerIDAllocator{err: allocErr})
+		require.ErrorIs(t, err, allocErr)
+	})
+
+	t.Run("out of range worker id is released", func(t *testing.T) {
+		alloc := &memoryWorkerIDAllocator{next: 32}
+		_, _, err := NewGeneratorWithAllocator(ctx, &GeneratorConfig{Mode: GeneratorModeMultiDC}, alloc)
+		require.ErrorIs(t, err, ErrInvalidInput)
+		require.Equal(t, []int64{32}, alloc.released)
+	})
+
+	t.Run("nil allocator returns error", func(t *testing.T) {
+		_, _, err := NewGeneratorWithAllocator(ctx, &GeneratorConfig{}, nil)
+		require.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("WorkerIDAllocatorFunc", func(t *testing.T) {
+		alloc := WorkerIDAllocatorFunc(func(context.Context) (*WorkerLease, error) {
+			return &WorkerLease{WorkerID: 3}, nil
+		})
+		gen, release, err := NewGeneratorWithAllocator(ctx, &GeneratorConfig{Mode: GeneratorModeSingleDC}, alloc)
+		require.NoError(t, err)
+		defer release()
+
+		id, err := gen.Next()
+		require.NoError(t, err)
+		_, _, workerID, _ := ParseGeneratorID(id, GeneratorModeSingleDC)
+		require.Equal(t, int64(3), workerID)
+	})
+
+	t.Run("lease lost stops generator", func(t *testing.T) {
+		alloc := &memoryWorkerIDAllocator{next: 5, lost: make(chan error, 1)}
+		gen, release, err := NewGeneratorWithAllocator(ctx, &GeneratorConfig{}, alloc)
+		require.NoError(t, err)
+		defer release()
+
+		_, err = gen.Next()
+		require.NoError(t, err)
+
+		alloc.lost <- errors.New("ownership lost")
+		require.Eventually(t, func() bool {
+			_, err := gen.Next()
+			return errors.Is(err, ErrLeaseExpired)
+		}, time.Second, 10*time.Millisecond)
+		_, err = gen.NextString()
+		require.ErrorIs(t, err, ErrLeaseExpired)
+
+		release()
+		release()
+		require.Equal(t, []int64{5}, alloc.released)
+	})
+}
+
+func TestNewLegacyAllocator_Unit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("allocate keep alive and stop", func(t *testing.T) {
+		alloc := &memoryWorkerIDAllocator{next: 4, lost: make(chan error, 1)}
+		legacy := NewLegacyAllocator(alloc)
+
+		id, err := legacy.Allocate(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int64(4), id)
+
+		_, err = legacy.Allocate(ctx)
+		require.ErrorIs(t, err, ErrInvalidInput)
+
+		lostErr := errors.New("ownership lost")
+		alloc.lost <- lostErr
+		require.ErrorIs(t, <-legacy.KeepAlive(ctx), lostErr)
+
+		legacy.Stop()
+		require.Equal(t, []int64{4}, alloc.released)
+	})
+
+	t.Run("keep alive before allocate", func(t *testing.T) {
+		legacy := NewLegacyAllocator(&memoryWorkerIDAllocator{})
+		require.ErrorIs(t, <-legacy.KeepAlive(ctx), ErrInvalidInput)
+		legacy.Stop()
+	})
+}
+
+func TestStartLease_Unit(t *testing.T) {
+	t.Run("keep alive failure is reported", func(t *testing.T) {
+		lostErr := errors.New("lease gone")
+		released := 0
+		lease := startLease(9, func(context.Context) error { return lostErr }, func() { released++ })
+		require.Equal(t, int64(9), lease.WorkerID)
+		require.ErrorIs(t, <-lease.Lost, lostErr)
+
+		lease.Release()
+		lease.Release()
+		require.Equal(t, 1, released)
+	})
+
+	t.Run("release stops keep alive without reporting", func(t *testing.T) {
+		released := 0
+		lease := startLease(1, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, func() { released++ })
+
+		lease.Release()
+		require.Equal(t, 1, released)
+		select {
+		case err := <-lease.Lost:
+			t.Fatalf("unexpected lost error: %v", err)
+		default:
+		}
+	})
+}
+
 // ========================================
 // 错误码单元测试
 // ========================================
@@ -2,10 +2,12 @@ package idgen
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
 
 	"github.com/ceyewan/genesis/testkit"
 )
@@ -412,6 +414,93 @@ func TestSequencer_SetIfNotExists_Integration(t *testing.T) {
 	})
 }
 
+func TestSequencer_Current_Integration(t *testing.T) {
+	gen := setupSequencer(t)
+	ctx := context.Background()
+
+	t.Run("Current on missing key returns 0", func(t *testing.T) {
+		cur, err := gen.Current(ctx, "key:current:missing")
+		require.NoError(t, err)
+		require.Zero(t, cur)
+	})
+
+	t.Run("Current does not advance sequence", func(t *testing.T) {
+		key := "key:current"
+
+		require.NoError(t, gen.Set(ctx, key, 10))
+		for range 3 {
+			cur, err := gen.Current(ctx, key)
+			require.NoError(t, err)
+			require.Equal(t, int64(10), cur)
+		}
+
+		seq, err := gen.Next(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, int64(11), seq)
+
+		// Next 之后 Current 反映新值
+		cur, err := gen.Current(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, seq, cur)
+
+		_, err = gen.NextBatch(ctx, key, 5)
+		require.NoError(t, err)
+		cur, err = gen.Current(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, int64(16), cur)
+	})
+}
+
+func TestSequencer_Reset_Integration(t *testing.T) {
+	gen := setupSequencer(t)
+	ctx := context.Background()
+
+	t.Run("Next continues from reset value", func(t *testing.T) {
+		key := "key:reset"
+
+		for range 5 {
+			_, err := gen.Next(ctx, key)
+			require.NoError(t, err)
+		}
+
+		require.NoError(t, gen.Reset(ctx, key, 1000))
+		cur, err := gen.Current(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, int64(1000), cur)
+
+		seq, err := gen.Next(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, int64(1001), seq)
+	})
+
+	t.Run("Reset to 0 removes key", func(t *testing.T) {
+		key := "key:reset:zero"
+
+		_, err := gen.Next(ctx, key)
+		require.NoError(t, err)
+		require.NoError(t, gen.Reset(ctx, key, 0))
+
+		cur, err := gen.Current(ctx, key)
+		require.NoError(t, err)
+		require.Zero(t, cur)
+
+		seq, err := gen.Next(ctx, key)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), seq)
+	})
+
+	t.Run("Reset missing key", func(t *testing.T) {
+		require.NoError(t, gen.Reset(ctx, "key:reset:missing", 50))
+		seq, err := gen.Next(ctx, "key:reset:missing")
+		require.NoError(t, err)
+		require.Equal(t, int64(51), seq)
+	})
+
+	t.Run("Reset negative value should fail", func(t *testing.T) {
+		require.ErrorIs(t, gen.Reset(ctx, "key:reset:neg", -1), ErrInvalidInput)
+	})
+}
+
 // ========================================
 // Allocator 集成测试（使用 testkit）
 // ========================================
@@ -431,31 +520,21 @@ func TestRedisAllocator_Integration(t *testing.T) {
 			t.Fatalf("Failed to create allocator: %v", err)
 		}
 
-		instanceID, err := allocator.Allocate(ctx)
+		lease, err := allocator.Allocate(ctx)
 		if err != nil {
 			t.Fatalf("Failed to allocate: %v", err)
 		}
-		defer allocator.Stop()
+		defer lease.Release()
 
-		if instanceID < 0 || instanceID >= 100 {
-			t.Errorf("Expected instanceID in [0, 100), got %d", instanceID)
+		if lease.WorkerID < 0 || lease.WorkerID >= 100 {
+			t.Errorf("Expected instanceID in [0, 100), got %d", lease.WorkerID)
 		}
 	})
 
 	t.Run("Multiple allocations get unique IDs", func(t *testing.T) {
 		ctx := context.Background()
 
-		alloc1, err := NewAllocator(&AllocatorConfig{
-			Driver:    "redis",
-			KeyPrefix: "test:unique",
-			MaxID:     50,
-			TTL:       30,
-		}, WithRedisConnector(redis))
-		if err != nil {
-			t.Fatalf("Failed to create allocator: %v", err)
-		}
-
-		alloc2, err := NewAllocator(&AllocatorConfig{
+		allocator, err := NewAllocator(&AllocatorConfig{
 			Driver:    "redis",
 			KeyPrefix: "test:unique",
 			MaxID:     50,
@@ -465,20 +544,20 @@ func TestRedisAllocator_Integration(t *testing.T) {
 			t.Fatalf("Failed to create allocator: %v", err)
 		}
 
-		id1, err := alloc1.Allocate(ctx)
+		lease1, err := allocator.Allocate(ctx)
 		if err != nil {
 			t.Fatalf("Failed to allocate first ID: %v", err)
 		}
-		defer alloc1.Stop()
+		defer lease1.Release()
 
-		id2, err := alloc2.Allocate(ctx)
+		lease2, err := allocator.Allocate(ctx)
 		if err != nil {
 			t.Fatalf("Failed to allocate second ID: %v", err)
 		}
-		defer alloc2.Stop()
+		defer lease2.Release()
 
-		if id1 == id2 {
-			t.Errorf("Expected unique IDs, got both %d", id1)
+		if lease1.WorkerID == lease2.WorkerID {
+			t.Errorf("Expected unique IDs, got both %d", lease1.WorkerID)
 		}
 	})
 
@@ -486,143 +565,118 @@ func TestRedisAllocator_Integration(t *testing.T) {
 		ctx := context.Background()
 		maxID := 5
 
+		allocator, err := NewAllocator(&AllocatorConfig{
+			Driver:    "redis",
+			KeyPrefix: "test:exhaust",
+			MaxID:     maxID,
+			TTL:       30,
+		}, WithRedisConnector(redis))
+		require.NoError(t, err)
+
 		// 分配所有 ID
-		allocators := make([]Allocator, 0, maxID)
+		leases := make([]*WorkerLease, 0, maxID)
 		for i := range maxID {
-			alloc, err := NewAllocator(&AllocatorConfig{
-				Driver:    "redis",
-				KeyPrefix: "test:exhaust",
-				MaxID:     maxID,
-				TTL:       30,
-			}, WithRedisConnector(redis))
-			if err != nil {
-				t.Fatalf("Failed to create allocator: %v", err)
-			}
-
-			_, err = alloc.Allocate(ctx)
+			lease, err := allocator.Allocate(ctx)
 			if err != nil {
 				t.Fatalf("Failed to allocate ID %d: %v", i, err)
 			}
-			allocators = append(allocators, alloc)
+			leases = append(leases, lease)
 		}
 
-		// 清理
-		for _, alloc := range allocators {
-			alloc.Stop()
-		}
-
-		// 等待 Redis 释放
-		// time.Sleep(100 * time.Millisecond)
+		_, err = allocator.Allocate(ctx)
+		require.ErrorIs(t, err, ErrWorkerIDExhausted)
 
-		// 再次分配应该成功（因为前面的 allocator 已经 stop）
-		alloc, err := NewAllocator(&AllocatorConfig{
-			Driver:    "redis",
-			KeyPrefix: "test:exhaust",
-			MaxID:     maxID,
-			TTL:       30,
-		}, WithRedisConnector(redis))
-		if err != nil {
-			t.Fatalf("Failed to create allocator: %v", err)
+		// 清理
+		for _, lease := range leases {
+			lease.Release()
 		}
 
-		_, err = alloc.Allocate(ctx)
+		// 再次分配应该成功（因为前面的租约已经释放）
+		lease, err := allocator.Allocate(ctx)
 		if err != nil {
 			t.Errorf("Expected to allocate ID after cleanup, got error: %v", err)
 		}
-		defer alloc.Stop()
+		defer lease.Release()
 	})
 
-	t.Run("KeepAlive before allocate returns error", func(t *testing.T) {
+	t.Run("Release is idempotent", func(t *testing.T) {
 		ctx := context.Background()
 		allocator, err := NewAllocator(&AllocatorConfig{
 			Driver:    "redis",
-			KeyPrefix: "test:keepalive:pre",
+			KeyPrefix: "test:stop:idempotent",
 			MaxID:     10,
 			TTL:       30,
 		}, WithRedisConnector(redis))
 		require.NoError(t, err)
 
-		errCh := allocator.KeepAlive(ctx)
-		require.Error(t, <-errCh)
+		lease, err := allocator.Allocate(ctx)
+		require.NoError(t, err)
+
+		require.NotPanics(t, func() {
+			lease.Release()
+			lease.Release()
+		})
 	})
 
-	t.Run("Allocate twice returns error", func(t *testing.T) {
+	t.Run("Lost reports ownership loss", func(t *testing.T) {
 		ctx := context.Background()
 		allocator, err := NewAllocator(&AllocatorConfig{
 			Driver:    "redis",
-			KeyPrefix: "test:allocate:twice",
-			MaxID:     10,
-			TTL:       30,
+			KeyPrefix: "test:ownership:loss",
+			MaxID:     1,
+			TTL:       3,
 		}, WithRedisConnector(redis))
 		require.NoError(t, err)
-		defer allocator.Stop()
 
-		_, err = allocator.Allocate(ctx)
+		lease, err := allocator.Allocate(ctx)
 		require.NoError(t, err)
+		defer lease.Release()
 
-		_, err = allocator.Allocate(ctx)
-		require.Error(t, err)
-	})
+		key := "test:ownership:loss:0"
+		client := redis.GetClient()
+		require.NoError(t, client.Set(ctx, key, "other-instance", 3*time.Second).Err())
 
-	t.Run("Stop is idempotent", func(t *testing.T) {
-		ctx := context.Background()
-		allocator, err := NewAllocator(&AllocatorConfig{
-			Driver:    "redis",
-			KeyPrefix: "test:stop:idempotent",
-			MaxID:     10,
-			TTL:       30,
-		}, WithRedisConnector(redis))
-		require.NoError(t, err)
+		select {
+		case lostErr := <-lease.Lost:
+			require.ErrorIs(t, lostErr, ErrLeaseExpired)
+		case <-time.After(5 * time.Second):
+			t.Fatal("expected lease lost")
+		}
 
-		_, err = allocator.Allocate(ctx)
-		require.NoError(t, err)
+		value, getErr := client.Get(ctx, key).Result()
+		require.NoError(t, getErr)
+		require.Equal(t, "other-instance", value)
 
-		require.NotPanics(t, func() {
-			allocator.Stop()
-			allocator.Stop()
-		})
+		lease.Release()
+
+		value, getErr = client.Get(ctx, key).Result()
+		require.NoError(t, getErr)
+		require.Equal(t, "other-instance", value)
+		require.EqualValues(t, 0, lease.WorkerID)
 	})
 
-	t.Run("KeepAlive reports ownership loss", func(t *testing.T) {
+	t.Run("Generator stops after lease lost", func(t *testing.T) {
 		ctx := context.Background()
 		allocator, err := NewAllocator(&AllocatorConfig{
 			Driver:    "redis",
-			KeyPrefix: "test:ownership:loss",
+			KeyPrefix: "test:generator:loss",
 			MaxID:     1,
 			TTL:       3,
 		}, WithRedisConnector(redis))
 		require.NoError(t, err)
-		defer allocator.Stop()
 
-		id, err := allocator.Allocate(ctx)
+		gen, release, err := NewGeneratorWithAllocator(ctx, &GeneratorConfig{}, allocator)
 		require.NoError(t, err)
+		defer release()
 
-		ra, ok := allocator.(*redisAllocator)
-		require.True(t, ok)
-
-		client := redis.GetClient()
-		require.NoError(t, client.Set(ctx, ra.redisKey, "other-instance", time.Duration(ra.cfg.TTL)*time.Second).Err())
+		_, err = gen.Next()
+		require.NoError(t, err)
 
-		errCh := allocator.KeepAlive(ctx)
+		require.NoError(t, redis.GetClient().Set(ctx, "test:generator:loss:0", "other-instance", 3*time.Second).Err())
 		require.Eventually(t, func() bool {
-			select {
-			case keepAliveErr := <-errCh:
-				return keepAliveErr != nil
-			default:
-				return false
-			}
+			_, err := gen.Next()
+			return errors.Is(err, ErrLeaseExpired)
 		}, 5*time.Second, 100*time.Millisecond)
-
-		value, getErr := client.Get(ctx, ra.redisKey).Result()
-		require.NoError(t, getErr)
-		require.Equal(t, "other-instance", value)
-
-		allocator.Stop()
-
-		value, getErr = client.Get(ctx, ra.redisKey).Result()
-		require.NoError(t, getErr)
-		require.Equal(t, "other-instance", value)
-		require.EqualValues(t, 0, id)
 	})
 }
 
@@ -638,89 +692,79 @@ func TestEtcdAllocator_Integration(t *testing.T) {
 			TTL:       30,
 		}, WithEtcdConnector(etcd))
 		require.NoError(t, err)
-		defer allocator.Stop()
 
-		instanceID, err := allocator.Allocate(ctx)
+		lease, err := allocator.Allocate(ctx)
 		require.NoError(t, err)
-		require.GreaterOrEqual(t, instanceID, int64(0))
-		require.Less(t, instanceID, int64(100))
+		defer lease.Release()
+		require.GreaterOrEqual(t, lease.WorkerID, int64(0))
+		require.Less(t, lease.WorkerID, int64(100))
 	})
 
 	t.Run("Multiple allocations get unique IDs", func(t *testing.T) {
 		ctx := context.Background()
-		alloc1, err := NewAllocator(&AllocatorConfig{
-			Driver:    "etcd",
-			KeyPrefix: "test:unique:etcd",
-			MaxID:     20,
-			TTL:       30,
-		}, WithEtcdConnector(etcd))
-		require.NoError(t, err)
-		defer alloc1.Stop()
-
-		alloc2, err := NewAllocator(&AllocatorConfig{
+		allocator, err := NewAllocator(&AllocatorConfig{
 			Driver:    "etcd",
 			KeyPrefix: "test:unique:etcd",
 			MaxID:     20,
 			TTL:       30,
 		}, WithEtcdConnector(etcd))
 		require.NoError(t, err)
-		defer alloc2.Stop()
 
-		id1, err := alloc1.Allocate(ctx)
+		lease1, err := allocator.Allocate(ctx)
 		require.NoError(t, err)
-		id2, err := alloc2.Allocate(ctx)
+		defer lease1.Release()
+		lease2, err := allocator.Allocate(ctx)
 		require.NoError(t, err)
-		require.NotEqual(t, id1, id2)
+		defer lease2.Release()
+		require.NotEqual(t, lease1.WorkerID, lease2.WorkerID)
 	})
 
-	t.Run("KeepAlive before allocate returns error", func(t *testing.T) {
+	t.Run("Release is idempotent", func(t *testing.T) {
 		ctx := context.Background()
 		allocator, err := NewAllocator(&AllocatorConfig{
 			Driver:    "etcd",
-			KeyPrefix: "test:keepalive:pre:etcd",
+			KeyPrefix: "test:stop:idempotent:etcd",
 			MaxID:     10,
 			TTL:       30,
 		}, WithEtcdConnector(etcd))
 		require.NoError(t, err)
 
-		errCh := allocator.KeepAlive(ctx)
-		require.Error(t, <-errCh)
+		lease, err := allocator.Allocate(ctx)
+		require.NoError(t, err)
+
+		require.NotPanics(t, func() {
+			lease.Release()
+			lease.Release()
+		})
 	})
 
-	t.Run("Allocate twice returns error", func(t *testing.T) {
+	t.Run("Lost reports revoked lease", func(t *testing.T) {
 		ctx := context.Background()
 		allocator, err := NewAllocator(&AllocatorConfig{
 			Driver:    "etcd",
-			KeyPrefix: "test:allocate:twice:etcd",
-			MaxID:     10,
-			TTL:       30,
+			KeyPrefix: "test:lease:loss:etcd",
+			MaxID:     1,
+			TTL:       5,
 		}, WithEtcdConnector(etcd))
 		require.NoError(t, err)
-		defer allocator.Stop()
 
-		_, err = allocator.Allocate(ctx)
+		lease, err := allocator.Allocate(ctx)
 		require.NoError(t, err)
+		defer lease.Release()
 
-		_, err = allocator.Allocate(ctx)
-		require.Error(t, err)
-	})
-
-	t.Run("Stop is idempotent", func(t *testing.T) {
-		ctx := context.Background()
-		allocator, err := NewAllocator(&AllocatorConfig{
-			Driver:    "etcd",
-			KeyPrefix: "test:stop:idempotent:etcd",
-			MaxID:     10,
-			TTL:       30,
-		}, WithEtcdConnector(etcd))
+		// 从外部撤销租约，模拟租约过期
+		client := etcd.GetClient()
+		resp, err := client.Get(ctx, "test:lease:loss:etcd:0")
 		require.NoError(t, err)
-
-		_, err = allocator.Allocate(ctx)
+		require.Len(t, resp.Kvs, 1)
+		_, err = client.Revoke(ctx, clientv3.LeaseID(resp.Kvs[0].Lease))
 		require.NoError(t, err)
 
-		require.NotPanics(t, func() {
-			allocator.Stop()
-			allocator.Stop()
-		})
+		select {
+		case lostErr := <-lease.Lost:
+			require.ErrorIs(t, lostErr, ErrLeaseExpired)
+		case <-time.After(10 * time.Second):
+			t.Fatal("expected lease lost")
+		}
 	})
 }
@@ -0,0 +1,55 @@
+package idgen
+
+// idBits Snowflake ID 可用的总位数（最高位为符号位，保持 ID 为正数）
+const idBits = 63
+
+// bitLayout Snowflake ID 的位布局：时间戳 | datacenter | worker | sequence
+type bitLayout struct {
+	epochMilli     int64
+	timestampBits  uint
+	datacenterBits uint
+	workerBits     uint
+	sequenceBits   uint
+}
+
+// layout 根据 Mode 与位宽配置返回位布局，调用前应已通过 validate
+func (c *GeneratorConfig) layout() bitLayout {
+	l := bitLayout{epochMilli: genesisEpochMilli}
+	if !c.Epoch.IsZero() {
+		l.epochMilli = c.Epoch.UnixMilli()
+	}
+	switch c.Mode {
+	case GeneratorModeSingleDC:
+		l.timestampBits, l.workerBits, l.sequenceBits = 41, 10, 12
+	case GeneratorModeCustom:
+		l.timestampBits, l.workerBits, l.sequenceBits = uint(c.TimestampBits), uint(c.WorkerBits), uint(c.SequenceBits)
+	default:
+		l.timestampBits, l.datacenterBits, l.workerBits, l.sequenceBits = 41, 5, 5, 12
+	}
+	return l
+}
+
+func (l bitLayout) maxTimestamp() int64  { return 1<<l.timestampBits - 1 }
+func (l bitLayout) maxDatacenter() int64 { return 1<<l.datacenterBits - 1 }
+func (l bitLayout) maxWorker() int64     { return 1<<l.workerBits - 1 }
+func (l bitLayout) maxSequence() int64   { return 1<<l.sequenceBits - 1 }
+
+// compose 按布局拼装 ID，timestamp 为相对 epoch 的毫秒数
+func (l bitLayout) compose(timestamp, datacenterID, workerID, sequence int64) int64 {
+	workerShift := l.sequenceBits
+	datacenterShift := workerShift + l.workerBits
+	timestampShift := datacenterShift + l.datacenterBits
+	return timestamp<<timestampShift | datacenterID<<datacenterShift | workerID<<workerShift | sequence
+}
+
+// parse 按布局拆解 ID，返回的 timestamp 为绝对 Unix 毫秒时间戳
+func (l bitLayout) parse(id int64) (timestamp, datacenterID, workerID, sequence int64) {
+	workerShift := l.sequenceBits
+	datacenterShift := workerShift + l.workerBits
+	timestampShift := datacenterShift + l.datacenterBits
+	timestamp = id>>timestampShift + l.epochMilli
+	datacenterID = id >> datacenterShift & l.maxDatacenter()
+	workerID = id >> workerShift & l.maxWorker()
+	sequence = id & l.maxSequence()
+	return timestamp, datacenterID, workerID, sequence
+}
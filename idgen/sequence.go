@@ -2,9 +2,12 @@ package idgen
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/redis/go-redis/v9"
+
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/connector"
 	"github.com/ceyewan/genesis/metrics"
@@ -296,3 +299,72 @@ func (r *redisSequencer) SetIfNotExists(ctx context.Context, key string, value i
 
 	return result, nil
 }
+
+// Current 读取序列号的当前值
+func (r *redisSequencer) Current(ctx context.Context, key string) (int64, error) {
+	redisKey := r.buildKey(key)
+	client := r.redis.GetClient()
+
+	value, err := client.Get(ctx, redisKey).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		if r.logger != nil {
+			r.logger.Error("failed to get current sequence",
+				clog.Error(err),
+				clog.String("redis_key", redisKey),
+				clog.String("key", key),
+			)
+		}
+		return 0, xerrors.Wrap(err, "redis_get_failed")
+	}
+
+	return value, nil
+}
+
+// Reset 重置序列号，返回前记录重置前后的值用于审计
+func (r *redisSequencer) Reset(ctx context.Context, key string, value int64) error {
+	if value < 0 {
+		return xerrors.WithCode(ErrInvalidInput, "negative_value")
+	}
+
+	redisKey := r.buildKey(key)
+	client := r.redis.GetClient()
+
+	// previous 为重置前的值，键不存在时为空
+	var (
+		previous string
+		err      error
+	)
+	if value == 0 {
+		previous, err = client.GetDel(ctx, redisKey).Result()
+	} else {
+		previous, err = client.SetArgs(ctx, redisKey, value, redis.SetArgs{
+			Get: true,
+			TTL: time.Duration(r.cfg.TTL) * time.Second,
+		}).Result()
+	}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		if r.logger != nil {
+			r.logger.Error("failed to reset sequence",
+				clog.Error(err),
+				clog.String("redis_key", redisKey),
+				clog.String("key", key),
+				clog.Int64("value", value),
+			)
+		}
+		return xerrors.Wrap(err, "redis_reset_failed")
+	}
+
+	if r.logger != nil {
+		r.logger.Warn("sequence reset",
+			clog.String("redis_key", redisKey),
+			clog.String("key", key),
+			clog.String("previous", previous),
+			clog.Int64("value", value),
+		)
+	}
+
+	return nil
+}
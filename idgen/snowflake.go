@@ -12,12 +12,9 @@ import (
 )
 
 const (
-	// genesisEpochMilli 自定义 epoch，避免直接消耗 Unix 时间戳的 41bit 窗口。
+	// genesisEpochMilli 默认 epoch，避免直接消耗 Unix 时间戳的 41bit 窗口。
 	genesisEpochMilli = int64(1704067200000) // 2024-01-01T00:00:00Z
 
-	// maxRelativeTimestamp Snowflake 时间字段上限（41 bit）。
-	maxRelativeTimestamp = int64(1<<41 - 1)
-
 	// maxClockBackwards 最大容忍的时钟回拨时间 (1秒)
 	maxClockBackwards = 1000 * time.Millisecond
 	// smallClockBackwards 微小回拨阈值 (5ms)，在此范围内尝试复用 lastTime
@@ -31,14 +28,16 @@ const (
 // snowflake 雪花算法生成器
 // 实现 Generator 接口，提供高性能的分布式有序 ID 生成能力
 type snowflake struct {
-	// state 包含 48bit lastTime 和 12bit sequence
+	// state 高位为 lastTime，低 sequenceBits 位为 sequence
 	// 使用 atomic 操作保证并发安全
 	state      atomic.Uint64
-	mode       GeneratorMode
+	layout     bitLayout
 	workerID   int64
 	dcID       int64
 	logger     clog.Logger
 	genCounter metrics.Counter
+	// leaseErr WorkerID 租约丢失后的错误，非 nil 时拒绝生成 ID
+	leaseErr atomic.Pointer[error]
 }
 
 func (s *snowflake) recordGenerated() {
@@ -80,7 +79,7 @@ func NewGenerator(cfg *GeneratorConfig, opts ...Option) (Generator, error) {
 	genCounter, _ := meter.Counter(MetricSnowflakeGenerated, "雪花算法 ID 生成总数")
 
 	sf := &snowflake{
-		mode:       cfg.Mode,
+		layout:     cfg.layout(),
 		workerID:   cfg.WorkerID,
 		dcID:       cfg.DatacenterID,
 		logger:     logger.With(clog.String("component", "generator")),
@@ -91,22 +90,44 @@ func NewGenerator(cfg *GeneratorConfig, opts ...Option) (Generator, error) {
 		clog.String("mode", string(cfg.Mode)),
 		clog.Int64("worker_id", cfg.WorkerID),
 		clog.Int64("datacenter_id", cfg.DatacenterID),
+		clog.Int64("epoch", sf.layout.epochMilli),
 	)
 
 	return sf, nil
 }
 
+// watchLease 等待 WorkerID 租约丢失，丢失后拒绝继续生成 ID；stop 关闭时退出
+func (s *snowflake) watchLease(lost <-chan error, stop <-chan struct{}) {
+	select {
+	case cause := <-lost:
+		err := xerrors.Wrapf(ErrLeaseExpired, "worker id %d", s.workerID)
+		if cause != nil {
+			err = xerrors.Wrapf(ErrLeaseExpired, "worker id %d: %v", s.workerID, cause)
+		}
+		s.leaseErr.Store(&err)
+		s.logger.Error("worker id lease lost, generator stopped",
+			clog.Int64("worker_id", s.workerID),
+			clog.Error(err),
+		)
+	case <-stop:
+	}
+}
+
 // nextInt64 生成 int64 ID（内部方法）
 func (s *snowflake) nextInt64() (int64, error) {
+	if err := s.leaseErr.Load(); err != nil {
+		return 0, *err
+	}
+	seqBits, maxSeq := s.layout.sequenceBits, s.layout.maxSequence()
 	for {
 		oldState := s.state.Load()
-		lastTime := int64(oldState >> 12)
-		sequence := int64(oldState & 0xFFF)
-		now := time.Now().UnixMilli() - genesisEpochMilli
+		lastTime := int64(oldState >> seqBits)
+		sequence := int64(oldState) & maxSeq
+		now := time.Now().UnixMilli() - s.layout.epochMilli
 		if now < 0 {
 			return 0, xerrors.WithCode(ErrInvalidInput, "time_before_epoch")
 		}
-		if now > maxRelativeTimestamp {
+		if now > s.layout.maxTimestamp() {
 			return 0, xerrors.WithCode(ErrInvalidInput, "timestamp_overflow")
 		}
 
@@ -116,7 +137,7 @@ func (s *snowflake) nextInt64() (int64, error) {
 
 			if drift <= smallClockBackwards {
 				// 1. 微小回拨 (<= 5ms): 尝试复用 lastTime
-				if sequence < 0xFFF {
+				if sequence < maxSeq {
 					now = lastTime
 				} else {
 					// 序列号已满，必须等待
@@ -135,7 +156,7 @@ func (s *snowflake) nextInt64() (int64, error) {
 
 		newSequence := int64(0)
 		if now == lastTime {
-			newSequence = (sequence + 1) & 0xFFF
+			newSequence = (sequence + 1) & maxSeq
 			if newSequence == 0 {
 				// 序列号溢出，等待下一毫秒
 				time.Sleep(time.Millisecond)
@@ -144,10 +165,9 @@ func (s *snowflake) nextInt64() (int64, error) {
 		}
 
 		// 尝试更新状态
-		newState := (uint64(now) << 12) | uint64(newSequence)
+		newState := (uint64(now) << seqBits) | uint64(newSequence)
 		if s.state.CompareAndSwap(oldState, newState) {
-			id := composeGeneratorID(now, s.mode, s.dcID, s.workerID, newSequence)
-			return id, nil
+			return s.layout.compose(now, s.dcID, s.workerID, newSequence), nil
 		}
 		// CAS 失败，重试
 	}
@@ -175,27 +195,27 @@ func (s *snowflake) NextString() (string, error) {
 	return fmt.Sprintf("%d", id), nil
 }
 
-func composeGeneratorID(timestamp int64, mode GeneratorMode, dcID, workerID, sequence int64) int64 {
-	switch mode {
-	case GeneratorModeSingleDC:
-		return (timestamp << 22) | (workerID << 12) | sequence
-	default:
-		return (timestamp << 22) | (dcID << 17) | (workerID << 12) | sequence
+// ParseGeneratorID 解析默认 epoch 下 single_dc / multi_dc 模式的 Snowflake ID，返回其组成部分。
+// timestamp 为绝对 Unix 毫秒时间戳。自定义 Epoch 或 custom 模式请使用 ParseGeneratorIDWithConfig。
+func ParseGeneratorID(id int64, mode GeneratorMode) (timestamp, datacenterID, workerID, sequence int64) {
+	cfg := GeneratorConfig{Mode: mode}
+	if mode != GeneratorModeSingleDC {
+		cfg.Mode = GeneratorModeMultiDC
 	}
+	return cfg.layout().parse(id)
 }
 
-// ParseGeneratorID 解析 Snowflake ID，返回其组成部分。
-// timestamp 为绝对 Unix 毫秒时间戳。
-func ParseGeneratorID(id int64, mode GeneratorMode) (timestamp, datacenterID, workerID, sequence int64) {
-	timestamp = (id >> 22) + genesisEpochMilli
-	switch mode {
-	case GeneratorModeSingleDC:
-		datacenterID = 0
-		workerID = (id >> 12) & 0x3FF
-	default:
-		datacenterID = (id >> 17) & 0x1F
-		workerID = (id >> 12) & 0x1F
+// ParseGeneratorIDWithConfig 按生成时使用的配置（Mode、Epoch、位宽）解析 Snowflake ID。
+// timestamp 为绝对 Unix 毫秒时间戳，配置无效时返回 ErrInvalidInput。
+func ParseGeneratorIDWithConfig(id int64, cfg *GeneratorConfig) (timestamp, datacenterID, workerID, sequence int64, err error) {
+	if cfg == nil {
+		return 0, 0, 0, 0, xerrors.WithCode(ErrInvalidInput, "config_nil")
+	}
+	c := *cfg
+	c.setDefaults()
+	if err := c.validate(); err != nil {
+		return 0, 0, 0, 0, err
 	}
-	sequence = id & 0xFFF
-	return timestamp, datacenterID, workerID, sequence
+	timestamp, datacenterID, workerID, sequence = c.layout().parse(id)
+	return timestamp, datacenterID, workerID, sequence, nil
 }
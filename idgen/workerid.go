@@ -0,0 +1,232 @@
+package idgen
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// ========================================
+// WorkerIDAllocator 接口 (Pluggable WorkerID Allocation)
+// ========================================
+
+// WorkerIDAllocator 可插拔的 WorkerID 分配接口
+//
+// 它不关心分配结果存放在哪里：NewAllocator 创建的 Redis/Etcd 分配器、K8s StatefulSet 序号、
+// 环境变量都可以实现该接口。每次 Allocate 返回一个独立的 WorkerLease。
+type WorkerIDAllocator interface {
+	Allocate(ctx context.Context) (*WorkerLease, error)
+}
+
+// WorkerLease 一次 WorkerID 分配的结果
+type WorkerLease struct {
+	// WorkerID 分配到的 WorkerID
+	WorkerID int64
+
+	// Lost 租约丢失时收到一个错误，之后该 WorkerID 可能已被其他实例占用；
+	// nil 表示租约不会丢失（如 StatefulSet 序号、环境变量）
+	Lost <-chan error
+
+	// Release 停止保活并归还 WorkerID，调用方应在不再生成 ID 时调用；
+	// 实现需保证可重复调用，nil 表示无需归还
+	Release func()
+}
+
+// WorkerIDAllocatorFunc 函数适配器，便于以闭包形式实现 WorkerIDAllocator
+//
+// 使用示例:
+//
+//	alloc := idgen.WorkerIDAllocatorFunc(func(ctx context.Context) (*idgen.WorkerLease, error) {
+//	    id, err := strconv.ParseInt(os.Getenv("WORKER_ID"), 10, 64)
+//	    return &idgen.WorkerLease{WorkerID: id}, err
+//	})
+type WorkerIDAllocatorFunc func(ctx context.Context) (*WorkerLease, error)
+
+// Allocate 实现 WorkerIDAllocator 接口
+func (f WorkerIDAllocatorFunc) Allocate(ctx context.Context) (*WorkerLease, error) {
+	return f(ctx)
+}
+
+// startLease 在后台运行 keepAlive 并返回 WorkerLease
+//
+// keepAlive 阻塞直到 ctx 取消（返回值被忽略）或租约丢失（返回错误，写入 Lost）；
+// Release 先停止保活并等待其退出，再调用 release 归还 WorkerID。
+func startLease(workerID int64, keepAlive func(ctx context.Context) error, release func()) *WorkerLease {
+	ctx, cancel := context.WithCancel(context.Background())
+	lost := make(chan error, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := keepAlive(ctx); err != nil && ctx.Err() == nil {
+			lost <- err
+		}
+	}()
+
+	var once sync.Once
+	return &WorkerLease{
+		WorkerID: workerID,
+		Lost:     lost,
+		Release: func() {
+			once.Do(func() {
+				cancel()
+				<-done
+				release()
+			})
+		},
+	}
+}
+
+// NewGeneratorWithAllocator 通过 WorkerIDAllocator 分配 WorkerID 并创建 Generator
+//
+// cfg.WorkerID 会被分配结果覆盖。分配失败或结果超出当前位布局范围时返回错误，
+// 且已分配的 WorkerID 会被立即释放。租约丢失后 Generator 拒绝继续生成 ID，
+// Next 返回 ErrLeaseExpired，避免与接手该 WorkerID 的实例产生重复 ID。
+// 返回的 release 应在 Generator 不再使用时调用。
+//
+// 使用示例:
+//
+//	gen, release, err := idgen.NewGeneratorWithAllocator(ctx, &idgen.GeneratorConfig{
+//	    Mode: idgen.GeneratorModeSingleDC,
+//	}, alloc)
+//	if err != nil {
+//	    return err
+//	}
+//	defer release()
+func NewGeneratorWithAllocator(ctx context.Context, cfg *GeneratorConfig, allocator WorkerIDAllocator, opts ...Option) (Generator, func(), error) {
+	if cfg == nil {
+		return nil, nil, xerrors.WithCode(ErrInvalidInput, "config_nil")
+	}
+	if allocator == nil {
+		return nil, nil, xerrors.WithCode(ErrInvalidInput, "allocator_nil")
+	}
+
+	lease, err := allocator.Allocate(ctx)
+	if err != nil {
+		return nil, nil, xerrors.Wrap(err, "allocate_worker_id_failed")
+	}
+	if lease == nil {
+		return nil, nil, xerrors.WithCode(ErrInvalidInput, "lease_nil")
+	}
+	release := lease.Release
+	if release == nil {
+		release = func() {}
+	}
+
+	genCfg := *cfg
+	genCfg.WorkerID = lease.WorkerID
+	gen, err := NewGenerator(&genCfg, opts...)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	if lease.Lost == nil {
+		return gen, release, nil
+	}
+
+	stop := make(chan struct{})
+	go gen.(*snowflake).watchLease(lease.Lost, stop)
+
+	var once sync.Once
+	return gen, func() {
+		once.Do(func() {
+			close(stop)
+			release()
+		})
+	}, nil
+}
+
+// ========================================
+// Allocator 旧接口 (Deprecated)
+// ========================================
+
+// Allocator 旧版 WorkerID 分配器接口，保留一个版本供存量代码迁移
+//
+// Deprecated: 使用 WorkerIDAllocator 与 WorkerLease；NewAllocator 现在直接返回 WorkerIDAllocator，
+// 旧代码可用 NewLegacyAllocator 包装后继续使用 Allocate/KeepAlive/Stop。
+type Allocator interface {
+	// Allocate 分配 WorkerID（阻塞直到分配成功）
+	Allocate(ctx context.Context) (int64, error)
+
+	// KeepAlive 返回错误通道，租约丢失时发送错误
+	KeepAlive(ctx context.Context) <-chan error
+
+	// Stop 停止保活并释放资源
+	Stop()
+}
+
+// NewLegacyAllocator 将 WorkerIDAllocator 包装为旧版 Allocator
+//
+// 同一个 Allocator 只能 Allocate 一次，Stop 释放该次分配的 WorkerID。
+//
+// Deprecated: 直接使用 WorkerIDAllocator.Allocate 返回的 WorkerLease，
+// 或交给 NewGeneratorWithAllocator 管理租约。
+func NewLegacyAllocator(allocator WorkerIDAllocator) Allocator {
+	return &legacyAllocator{allocator: allocator}
+}
+
+// legacyAllocator 基于 WorkerLease 实现旧版 Allocator
+type legacyAllocator struct {
+	allocator WorkerIDAllocator
+
+	mu    sync.Mutex
+	lease *WorkerLease
+}
+
+// Allocate 实现 Allocator 接口
+func (a *legacyAllocator) Allocate(ctx context.Context) (int64, error) {
+	if a.allocator == nil {
+		return 0, xerrors.WithCode(ErrInvalidInput, "allocator_nil")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.lease != nil {
+		return 0, xerrors.WithCode(ErrInvalidInput, "worker_id_already_allocated")
+	}
+
+	lease, err := a.allocator.Allocate(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if lease == nil {
+		return 0, xerrors.WithCode(ErrInvalidInput, "lease_nil")
+	}
+	a.lease = lease
+	return lease.WorkerID, nil
+}
+
+// KeepAlive 实现 Allocator 接口，转发 WorkerLease.Lost 中的错误
+func (a *legacyAllocator) KeepAlive(ctx context.Context) <-chan error {
+	errCh := make(chan error, 1)
+
+	a.mu.Lock()
+	lease := a.lease
+	a.mu.Unlock()
+	if lease == nil {
+		errCh <- xerrors.WithCode(ErrInvalidInput, "allocate_must_be_called_first")
+		return errCh
+	}
+	if lease.Lost == nil {
+		return errCh
+	}
+
+	go func() {
+		select {
+		case err := <-lease.Lost:
+			errCh <- err
+		case <-ctx.Done():
+		}
+	}()
+	return errCh
+}
+
+// Stop 实现 Allocator 接口，释放已分配的 WorkerID
+func (a *legacyAllocator) Stop() {
+	a.mu.Lock()
+	lease := a.lease
+	a.mu.Unlock()
+	if lease != nil && lease.Release != nil {
+		lease.Release()
+	}
+}
@@ -9,12 +9,29 @@ import (
 // Config 定义全局 metrics 初始化参数。
 //
 // 当前实现采用 Prometheus exporter，并可选在同一进程内暴露 /metrics HTTP 端点。
+//
+// EnableRuntime 会开启 OpenTelemetry contrib 提供的全量 runtime 指标；
+// 若只需要部分 runtime 指标或需要自定义采集间隔，使用 Runtime 细粒度配置，
+// Runtime 非 nil 时优先于 EnableRuntime。
+//
+// 批处理任务等无法被 pull 的短生命周期进程可配置 PushgatewayURL，通过 Meter.Push
+// 主动推送指标，Shutdown 时也会自动推送一次（DeleteOnShutdown 时改为删除分组）。
 type Config struct {
-	ServiceName   string `mapstructure:"service_name"`
-	Version       string `mapstructure:"version"`
-	Port          int    `mapstructure:"port"`
-	Path          string `mapstructure:"path"`
-	EnableRuntime bool   `mapstructure:"enable_runtime"`
+	ServiceName   string          `mapstructure:"service_name"`
+	Version       string          `mapstructure:"version"`
+	Port          int             `mapstructure:"port"`
+	Path          string          `mapstructure:"path"`
+	EnableRuntime bool            `mapstructure:"enable_runtime"`
+	Runtime       *RuntimeMetrics `mapstructure:"runtime"`
+
+	// PushgatewayURL Pushgateway 地址，例如 http://pushgateway:9091，为空时不启用推送
+	PushgatewayURL string `mapstructure:"pushgateway_url"`
+	// JobName 推送使用的 job 标签，默认使用 ServiceName
+	JobName string `mapstructure:"job_name"`
+	// PushGrouping 额外的分组标签（如 instance），不能包含 job
+	PushGrouping map[string]string `mapstructure:"push_grouping"`
+	// DeleteOnShutdown Shutdown 时从 Pushgateway 删除该分组，而不是推送最后一次指标
+	DeleteOnShutdown bool `mapstructure:"delete_on_shutdown"`
 }
 
 func (c *Config) validate() error {
@@ -30,7 +47,10 @@ func (c *Config) validate() error {
 	if c.Path != "" && !strings.HasPrefix(c.Path, "/") {
 		return xerrors.New("path must start with /")
 	}
-	return nil
+	if c.Runtime != nil && c.Runtime.Interval < 0 {
+		return xerrors.New("runtime interval must be greater than or equal to 0")
+	}
+	return c.validatePushgateway()
 }
 
 // NewDevDefaultConfig 开发环境默认配置
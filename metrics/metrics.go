@@ -21,7 +21,9 @@ import (
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/xerrors"
 
+	promclient "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
 	"go.opentelemetry.io/contrib/instrumentation/runtime"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -45,24 +47,44 @@ func Discard() Meter {
 //
 // 当 Config 指定了 Port 和 Path 时，New 还会启动一个 Prometheus HTTP 暴露端点。
 // 若监听端口失败，New 会直接返回错误，而不是在后台异步失败。
-func New(cfg *Config) (Meter, error) {
+//
+// opts 支持的选项:
+//   - WithResource(res): 使用外部 resource，便于与 trace 共享
+func New(cfg *Config, opts ...Option) (Meter, error) {
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
 
 	logger := defaultLogger()
 
-	res, err := resource.New(context.Background(),
-		resource.WithAttributes(
-			semconv.ServiceNameKey.String(cfg.ServiceName),
-			semconv.ServiceVersionKey.String(cfg.Version),
-		),
+	res := applyOptions(opts...).resource
+	if res == nil {
+		var err error
+		res, err = resource.New(context.Background(),
+			resource.WithAttributes(
+				semconv.ServiceNameKey.String(cfg.ServiceName),
+				semconv.ServiceVersionKey.String(cfg.Version),
+			),
+		)
+		if err != nil {
+			return nil, xerrors.Wrap(err, "create resource")
+		}
+	}
+
+	// 配置了 Pushgateway 时使用私有 Registry，推送内容只包含本 Meter 的指标；
+	// /metrics 端点同时暴露默认 Registry 与私有 Registry。
+	var (
+		exporterOpts []prometheus.Option
+		registry     *promclient.Registry
+		gatherer     promclient.Gatherer = promclient.DefaultGatherer
 	)
-	if err != nil {
-		return nil, xerrors.Wrap(err, "create resource")
+	if cfg.PushgatewayURL != "" {
+		registry = promclient.NewRegistry()
+		exporterOpts = append(exporterOpts, prometheus.WithRegisterer(registry))
+		gatherer = promclient.Gatherers{promclient.DefaultGatherer, registry}
 	}
 
-	prometheusExporter, err := prometheus.New()
+	prometheusExporter, err := prometheus.New(exporterOpts...)
 	if err != nil {
 		return nil, xerrors.Wrap(err, "create prometheus exporter")
 	}
@@ -77,7 +99,7 @@ func New(cfg *Config) (Meter, error) {
 	if cfg.Port > 0 && cfg.Path != "" {
 		addr := fmt.Sprintf(":%d", cfg.Port)
 		mux := http.NewServeMux()
-		mux.Handle(cfg.Path, promhttp.Handler())
+		mux.Handle(cfg.Path, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
 		httpServer = &http.Server{Addr: addr, Handler: mux}
 		ln, err := net.Listen("tcp", addr)
 		if err != nil {
@@ -94,20 +116,31 @@ func New(cfg *Config) (Meter, error) {
 		}()
 	}
 
-	if cfg.EnableRuntime {
+	switch {
+	case cfg.Runtime != nil:
+		if cfg.Runtime.enabled() {
+			collector := newRuntimeCollector(*cfg.Runtime)
+			if err := collector.register(mp.Meter("genesis/runtime")); err != nil {
+				logger.Error("runtime metrics start failed", clog.Error(err))
+			}
+		}
+	case cfg.EnableRuntime:
 		if err := runtime.Start(runtime.WithMeterProvider(mp)); err != nil {
 			logger.Error("runtime metrics start failed", clog.Error(err))
 		}
 	}
 
-	otelMeter := mp.Meter("genesis")
-	return &meterImpl{
-		meter:      otelMeter,
+	impl := &meterImpl{
+		meter:      mp.Meter("genesis"),
 		provider:   mp,
 		config:     cfg,
 		httpServer: httpServer,
 		logger:     logger,
-	}, nil
+	}
+	if registry != nil {
+		impl.pusher = newPusher(cfg, registry)
+	}
+	return impl, nil
 }
 
 type meterImpl struct {
@@ -115,6 +148,7 @@ type meterImpl struct {
 	provider   *sdkmetric.MeterProvider
 	config     *Config
 	httpServer *http.Server
+	pusher     *push.Pusher
 	logger     clog.Logger
 }
 
@@ -176,6 +210,9 @@ func (m *meterImpl) Histogram(name, desc string, opts ...MetricOption) (Histogra
 }
 
 func (m *meterImpl) Shutdown(ctx context.Context) error {
+	// 必须在关闭 provider 之前推送，否则采集不到任何指标
+	pushErr := m.flushPush(ctx)
+
 	var serverErr error
 	if m.httpServer != nil {
 		if err := m.httpServer.Shutdown(ctx); err != nil && !xerrors.Is(err, http.ErrServerClosed) {
@@ -190,7 +227,7 @@ func (m *meterImpl) Shutdown(ctx context.Context) error {
 	if otel.GetMeterProvider() == m.provider {
 		otel.SetMeterProvider(metricnoop.NewMeterProvider())
 	}
-	return xerrors.Combine(serverErr, providerErr)
+	return xerrors.Combine(pushErr, serverErr, providerErr)
 }
 
 type counterImpl struct {
@@ -259,6 +296,10 @@ func (n *noopMeter) Histogram(name, desc string, opts ...MetricOption) (Histogra
 	return &noopHistogram{}, nil
 }
 
+func (n *noopMeter) Push(ctx context.Context) error {
+	return nil
+}
+
 func (n *noopMeter) Shutdown(ctx context.Context) error {
 	return nil
 }
@@ -0,0 +1,30 @@
+package metrics
+
+import "go.opentelemetry.io/otel/sdk/resource"
+
+// Option 配置 New 的可选行为
+type Option func(*options)
+
+// options 内部选项（内部使用，小写）
+type options struct {
+	resource *resource.Resource
+}
+
+// WithResource 使用外部构建的 resource 代替由 ServiceName / Version 生成的默认 resource。
+//
+// 用于让 metrics 与 trace 共享同一份 resource（服务名、版本、环境等属性）。nil 时忽略。
+func WithResource(res *resource.Resource) Option {
+	return func(o *options) {
+		if res != nil {
+			o.resource = res
+		}
+	}
+}
+
+func applyOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"context"
+	"net/url"
+	"sort"
+
+	"github.com/ceyewan/genesis/xerrors"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+	"github.com/prometheus/common/expfmt"
+)
+
+// validatePushgateway 校验 Pushgateway 相关配置
+func (c *Config) validatePushgateway() error {
+	if c.PushgatewayURL == "" {
+		if c.JobName != "" || len(c.PushGrouping) > 0 || c.DeleteOnShutdown {
+			return xerrors.New("pushgateway_url is required when push options are set")
+		}
+		return nil
+	}
+	u, err := url.Parse(c.PushgatewayURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return xerrors.New("pushgateway_url must be an absolute http(s) url")
+	}
+	for name := range c.PushGrouping {
+		if name == "" || name == "job" {
+			return xerrors.New("push grouping label must not be empty or job")
+		}
+	}
+	return nil
+}
+
+// jobName 返回推送使用的 job 标签，未配置时使用 ServiceName
+func (c *Config) jobName() string {
+	if c.JobName != "" {
+		return c.JobName
+	}
+	return c.ServiceName
+}
+
+// newPusher 基于私有 Registry 创建 Pusher
+//
+// 推送只包含当前 Meter 的指标，不包含默认 Registry 中的 Go/进程指标，
+// 避免多个 Meter 或其他库注册的指标被一并推送。
+func newPusher(cfg *Config, gatherer promclient.Gatherer) *push.Pusher {
+	p := push.New(cfg.PushgatewayURL, cfg.jobName()).
+		Gatherer(gatherer).
+		Format(expfmt.NewFormat(expfmt.TypeTextPlain))
+
+	// 分组标签按名称排序，保证 URL 稳定
+	names := make([]string, 0, len(cfg.PushGrouping))
+	for name := range cfg.PushGrouping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p = p.Grouping(name, cfg.PushGrouping[name])
+	}
+	return p
+}
+
+// Push 将当前所有指标推送到 Pushgateway
+//
+// 使用 PUT 语义：同一 job 与分组标签下的旧指标会被整体替换。
+// 未配置 PushgatewayURL 时返回错误。
+func (m *meterImpl) Push(ctx context.Context) error {
+	if m.pusher == nil {
+		return xerrors.New("pushgateway is not configured")
+	}
+	if err := m.pusher.PushContext(ctx); err != nil {
+		return xerrors.Wrap(err, "push metrics")
+	}
+	return nil
+}
+
+// flushPush 在 Shutdown 时推送最后一次指标，或按配置删除分组
+func (m *meterImpl) flushPush(ctx context.Context) error {
+	if m.pusher == nil {
+		return nil
+	}
+	if m.config.DeleteOnShutdown {
+		if err := m.pusher.Delete(); err != nil {
+			return xerrors.Wrap(err, "delete pushed metrics")
+		}
+		return nil
+	}
+	return m.Push(ctx)
+}
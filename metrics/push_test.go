@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// pushRequest 假 Pushgateway 收到的一次请求
+type pushRequest struct {
+	method string
+	path   string
+	body   string
+}
+
+// fakePushgateway 记录所有请求的假 Pushgateway
+type fakePushgateway struct {
+	mu       sync.Mutex
+	requests []pushRequest
+}
+
+func (g *fakePushgateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	g.mu.Lock()
+	g.requests = append(g.requests, pushRequest{method: r.Method, path: r.URL.Path, body: string(body)})
+	g.mu.Unlock()
+	// 与真实 Pushgateway 一致：DELETE 返回 202，PUT/POST 返回 200
+	if r.Method == http.MethodDelete {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (g *fakePushgateway) all() []pushRequest {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]pushRequest(nil), g.requests...)
+}
+
+func TestPush(t *testing.T) {
+	gateway := &fakePushgateway{}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	meter, err := New(&Config{
+		ServiceName:    "batch-service",
+		PushgatewayURL: server.URL,
+		JobName:        "nightly_report",
+		PushGrouping:   map[string]string{"instance": "worker-1"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx := context.Background()
+	counter, err := meter.Counter("report_rows_total", "processed rows")
+	if err != nil {
+		t.Fatalf("Counter() error = %v", err)
+	}
+	counter.Add(ctx, 42, L("table", "orders"))
+
+	if err := meter.Push(ctx); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	reqs := gateway.all()
+	if len(reqs) != 1 {
+		t.Fatalf("requests = %d, want 1", len(reqs))
+	}
+	req := reqs[0]
+	if req.method != http.MethodPut {
+		t.Errorf("method = %s, want PUT", req.method)
+	}
+	if want := "/metrics/job/nightly_report/instance/worker-1"; req.path != want {
+		t.Errorf("path = %s, want %s", req.path, want)
+	}
+	if !strings.Contains(req.body, `report_rows_total{`) || !strings.Contains(req.body, `table="orders"`) {
+		t.Errorf("body missing counter sample:\n%s", req.body)
+	}
+	if !strings.Contains(req.body, " 42") {
+		t.Errorf("body missing counter value 42:\n%s", req.body)
+	}
+	if strings.Contains(req.body, "go_goroutines") {
+		t.Errorf("body should not contain default registry metrics:\n%s", req.body)
+	}
+
+	// Shutdown 时自动推送一次
+	if err := meter.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	reqs = gateway.all()
+	if len(reqs) != 2 || reqs[1].method != http.MethodPut {
+		t.Fatalf("expected final PUT on shutdown, got %+v", reqs)
+	}
+}
+
+func TestPushDeleteOnShutdown(t *testing.T) {
+	gateway := &fakePushgateway{}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	meter, err := New(&Config{
+		ServiceName:      "batch-service",
+		PushgatewayURL:   server.URL,
+		DeleteOnShutdown: true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := meter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	reqs := gateway.all()
+	if len(reqs) != 1 {
+		t.Fatalf("requests = %d, want 1", len(reqs))
+	}
+	if reqs[0].method != http.MethodDelete {
+		t.Errorf("method = %s, want DELETE", reqs[0].method)
+	}
+	// 未配置 JobName 时使用 ServiceName
+	if want := "/metrics/job/batch-service"; reqs[0].path != want {
+		t.Errorf("path = %s, want %s", reqs[0].path, want)
+	}
+}
+
+func TestPushErrors(t *testing.T) {
+	meter, err := New(&Config{ServiceName: "test-service"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer meter.Shutdown(context.Background())
+
+	if err := meter.Push(context.Background()); err == nil {
+		t.Error("Push() without pushgateway should return error")
+	}
+
+	invalid := []*Config{
+		{ServiceName: "svc", PushgatewayURL: "pushgateway:9091"},
+		{ServiceName: "svc", JobName: "job"},
+		{ServiceName: "svc", PushgatewayURL: "http://localhost:9091", PushGrouping: map[string]string{"job": "x"}},
+	}
+	for _, cfg := range invalid {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) expected error", cfg)
+		}
+	}
+
+	// Pushgateway 返回错误时 Push 应返回错误
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	failing, err := New(&Config{ServiceName: "svc", PushgatewayURL: server.URL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := failing.Push(context.Background()); err == nil {
+		t.Error("Push() expected error from failing pushgateway")
+	}
+	if err := failing.Shutdown(context.Background()); err == nil {
+		t.Error("Shutdown() expected push error")
+	}
+}
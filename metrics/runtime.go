@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"context"
+	goruntime "runtime"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// RuntimeMetrics 定义进程/runtime 指标的细粒度开关。
+//
+// 每个开关对应一组独立注册的采集回调，未开启的分组不会暴露任何指标序列。
+// Interval 控制底层 runtime 数据的最小刷新间隔：在间隔内的多次抓取会复用上一次
+// 的快照，避免 runtime.ReadMemStats 的 STW 开销随抓取频率放大。为 0 时每次抓取都重新读取。
+type RuntimeMetrics struct {
+	GC         bool          `mapstructure:"gc"`
+	Goroutines bool          `mapstructure:"goroutines"`
+	Memory     bool          `mapstructure:"memory"`
+	Threads    bool          `mapstructure:"threads"`
+	Interval   time.Duration `mapstructure:"interval"`
+}
+
+func (r *RuntimeMetrics) enabled() bool {
+	return r != nil && (r.GC || r.Goroutines || r.Memory || r.Threads)
+}
+
+// runtimeSnapshot 一次 runtime 数据读取的结果
+type runtimeSnapshot struct {
+	gcCount      uint32
+	gcPauseTotal time.Duration
+	goroutines   int
+	heapAlloc    uint64
+	heapInuse    uint64
+	sys          uint64
+	threads      int
+}
+
+// runtimeCollector 按配置采集 runtime 指标，并按 Interval 缓存快照
+type runtimeCollector struct {
+	cfg RuntimeMetrics
+
+	mu       sync.Mutex
+	last     time.Time
+	snapshot runtimeSnapshot
+
+	now  func() time.Time
+	read func(cfg RuntimeMetrics) runtimeSnapshot
+}
+
+func newRuntimeCollector(cfg RuntimeMetrics) *runtimeCollector {
+	return &runtimeCollector{
+		cfg:  cfg,
+		now:  time.Now,
+		read: readRuntimeSnapshot,
+	}
+}
+
+// load 返回当前快照，距离上次读取不足 Interval 时复用缓存
+func (c *runtimeCollector) load() runtimeSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if !c.last.IsZero() && c.cfg.Interval > 0 && now.Sub(c.last) < c.cfg.Interval {
+		return c.snapshot
+	}
+	c.snapshot = c.read(c.cfg)
+	c.last = now
+	return c.snapshot
+}
+
+// register 在 meter 上注册已开启分组的指标与采集回调
+func (c *runtimeCollector) register(meter metric.Meter) error {
+	var (
+		observables []metric.Observable
+		callbacks   []func(metric.Observer, runtimeSnapshot)
+	)
+
+	if c.cfg.GC {
+		gcCount, err := meter.Int64ObservableCounter("runtime_gc_count",
+			metric.WithDescription("已完成的 GC 次数"))
+		if err != nil {
+			return xerrors.Wrap(err, "create gc count metric")
+		}
+		gcPause, err := meter.Float64ObservableCounter("runtime_gc_pause_seconds",
+			metric.WithDescription("GC 累计 STW 暂停时长（秒）"))
+		if err != nil {
+			return xerrors.Wrap(err, "create gc pause metric")
+		}
+		observables = append(observables, gcCount, gcPause)
+		callbacks = append(callbacks, func(o metric.Observer, s runtimeSnapshot) {
+			o.ObserveInt64(gcCount, int64(s.gcCount))
+			o.ObserveFloat64(gcPause, s.gcPauseTotal.Seconds())
+		})
+	}
+
+	if c.cfg.Goroutines {
+		goroutines, err := meter.Int64ObservableGauge("runtime_goroutines",
+			metric.WithDescription("当前 goroutine 数量"))
+		if err != nil {
+			return xerrors.Wrap(err, "create goroutines metric")
+		}
+		observables = append(observables, goroutines)
+		callbacks = append(callbacks, func(o metric.Observer, s runtimeSnapshot) {
+			o.ObserveInt64(goroutines, int64(s.goroutines))
+		})
+	}
+
+	if c.cfg.Memory {
+		heapAlloc, err := meter.Int64ObservableGauge("runtime_memory_heap_alloc_bytes",
+			metric.WithDescription("堆上已分配且未释放的字节数"))
+		if err != nil {
+			return xerrors.Wrap(err, "create heap alloc metric")
+		}
+		heapInuse, err := meter.Int64ObservableGauge("runtime_memory_heap_inuse_bytes",
+			metric.WithDescription("使用中的堆 span 字节数"))
+		if err != nil {
+			return xerrors.Wrap(err, "create heap inuse metric")
+		}
+		sys, err := meter.Int64ObservableGauge("runtime_memory_sys_bytes",
+			metric.WithDescription("从操作系统获取的总字节数"))
+		if err != nil {
+			return xerrors.Wrap(err, "create sys memory metric")
+		}
+		observables = append(observables, heapAlloc, heapInuse, sys)
+		callbacks = append(callbacks, func(o metric.Observer, s runtimeSnapshot) {
+			o.ObserveInt64(heapAlloc, int64(s.heapAlloc))
+			o.ObserveInt64(heapInuse, int64(s.heapInuse))
+			o.ObserveInt64(sys, int64(s.sys))
+		})
+	}
+
+	if c.cfg.Threads {
+		threads, err := meter.Int64ObservableGauge("runtime_threads",
+			metric.WithDescription("进程创建的 OS 线程数量"))
+		if err != nil {
+			return xerrors.Wrap(err, "create threads metric")
+		}
+		observables = append(observables, threads)
+		callbacks = append(callbacks, func(o metric.Observer, s runtimeSnapshot) {
+			o.ObserveInt64(threads, int64(s.threads))
+		})
+	}
+
+	if len(observables) == 0 {
+		return nil
+	}
+
+	_, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		snapshot := c.load()
+		for _, cb := range callbacks {
+			cb(o, snapshot)
+		}
+		return nil
+	}, observables...)
+	if err != nil {
+		return xerrors.Wrap(err, "register runtime metrics callback")
+	}
+	return nil
+}
+
+// readRuntimeSnapshot 只读取已开启分组需要的数据
+func readRuntimeSnapshot(cfg RuntimeMetrics) runtimeSnapshot {
+	var s runtimeSnapshot
+	if cfg.GC || cfg.Memory {
+		var ms goruntime.MemStats
+		goruntime.ReadMemStats(&ms)
+		s.gcCount = ms.NumGC
+		s.gcPauseTotal = time.Duration(ms.PauseTotalNs)
+		s.heapAlloc = ms.HeapAlloc
+		s.heapInuse = ms.HeapInuse
+		s.sys = ms.Sys
+	}
+	if cfg.Goroutines {
+		s.goroutines = goruntime.NumGoroutine()
+	}
+	if cfg.Threads {
+		if p := pprof.Lookup("threadcreate"); p != nil {
+			s.threads = p.Count()
+		}
+	}
+	return s
+}
@@ -0,0 +1,132 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func collectMetricNames(t *testing.T, reader *sdkmetric.ManualReader) []string {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	var names []string
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			names = append(names, m.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRuntimeCollectorOnlyGC(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer mp.Shutdown(context.Background())
+
+	collector := newRuntimeCollector(RuntimeMetrics{GC: true})
+	if err := collector.register(mp.Meter("test")); err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+
+	names := collectMetricNames(t, reader)
+	want := []string{"runtime_gc_count", "runtime_gc_pause_seconds"}
+	if len(names) != len(want) {
+		t.Fatalf("metric names = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("metric names = %v, want %v", names, want)
+		}
+	}
+}
+
+func TestRuntimeCollectorAllGroups(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer mp.Shutdown(context.Background())
+
+	collector := newRuntimeCollector(RuntimeMetrics{GC: true, Goroutines: true, Memory: true, Threads: true})
+	if err := collector.register(mp.Meter("test")); err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+
+	names := collectMetricNames(t, reader)
+	if len(names) != 7 {
+		t.Fatalf("metric names = %v, want 7 series", names)
+	}
+}
+
+func TestRuntimeCollectorInterval(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	defer mp.Shutdown(context.Background())
+
+	now := time.Unix(0, 0)
+	reads := 0
+	collector := newRuntimeCollector(RuntimeMetrics{Goroutines: true, Interval: 10 * time.Second})
+	collector.now = func() time.Time { return now }
+	collector.read = func(cfg RuntimeMetrics) runtimeSnapshot {
+		reads++
+		return runtimeSnapshot{goroutines: reads}
+	}
+	if err := collector.register(mp.Meter("test")); err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+
+	goroutines := func() int64 {
+		var rm metricdata.ResourceMetrics
+		if err := reader.Collect(context.Background(), &rm); err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		gauge := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Gauge[int64])
+		return gauge.DataPoints[0].Value
+	}
+
+	if got := goroutines(); got != 1 {
+		t.Fatalf("first collect = %d, want 1", got)
+	}
+
+	now = now.Add(5 * time.Second)
+	if got := goroutines(); got != 1 {
+		t.Fatalf("collect within interval = %d, want cached 1", got)
+	}
+
+	now = now.Add(6 * time.Second)
+	if got := goroutines(); got != 2 {
+		t.Fatalf("collect after interval = %d, want 2", got)
+	}
+	if reads != 2 {
+		t.Fatalf("reads = %d, want 2", reads)
+	}
+}
+
+func TestNewWithRuntimeMetrics(t *testing.T) {
+	meter, err := New(&Config{
+		ServiceName: "test-service",
+		Runtime:     &RuntimeMetrics{GC: true, Interval: time.Second},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := meter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	_, err = New(&Config{
+		ServiceName: "test-service",
+		Runtime:     &RuntimeMetrics{GC: true, Interval: -time.Second},
+	})
+	if err == nil {
+		t.Fatal("New() error = nil, want invalid runtime interval")
+	}
+}
@@ -25,9 +25,12 @@ type Meter interface {
 	Counter(name, desc string, opts ...MetricOption) (Counter, error)
 	Gauge(name, desc string, opts ...MetricOption) (Gauge, error)
 	Histogram(name, desc string, opts ...MetricOption) (Histogram, error)
+	// Push 将当前所有指标推送到 Pushgateway，未配置 PushgatewayURL 时返回错误。
+	Push(ctx context.Context) error
 	// Shutdown 释放 Meter 持有的资源。
 	//
-	// 当前实现会关闭内部 HTTP 服务并关闭底层 MeterProvider。
+	// 当前实现会关闭内部 HTTP 服务并关闭底层 MeterProvider；配置了 Pushgateway 时，
+	// 关闭前会先推送最后一次指标（或按 DeleteOnShutdown 删除分组）。
 	// 若当前全局 MeterProvider 仍指向该实例，Shutdown 还会将其重置为 no-op provider。
 	// 它不是幂等承诺接口，调用方应按“谁创建，谁关闭”原则调用一次。
 	Shutdown(ctx context.Context) error
@@ -0,0 +1,93 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+// ackTimeoutMessage 为消息附加本地 ack 超时
+//
+// Handler 在超时时间内未返回时，由计时器自动 Nak 并取消消息 Context；
+// 此后 Handler 再调用 Ack/Nak 会返回 ErrAckTimeout，避免对已重投的消息重复确认。
+type ackTimeoutMessage struct {
+	Message
+	ctx    context.Context
+	cancel context.CancelFunc
+	timer  *time.Timer
+
+	mu       sync.Mutex
+	settled  bool // Handler 已自行 Ack/Nak
+	timedOut bool // 已因超时自动 Nak
+}
+
+// newAckTimeoutMessage 包装消息并启动超时计时，超时后调用 onTimeout 汇报 Nak 结果
+func newAckTimeoutMessage(msg Message, timeout time.Duration, onTimeout func(nakErr error)) *ackTimeoutMessage {
+	ctx, cancel := context.WithCancel(msg.Context())
+	m := &ackTimeoutMessage{
+		Message: msg,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	m.timer = time.AfterFunc(timeout, func() {
+		m.mu.Lock()
+		if m.settled {
+			m.mu.Unlock()
+			return
+		}
+		m.timedOut = true
+		m.mu.Unlock()
+
+		m.cancel()
+		onTimeout(m.Message.Nak())
+	})
+	return m
+}
+
+func (m *ackTimeoutMessage) Context() context.Context {
+	return m.ctx
+}
+
+func (m *ackTimeoutMessage) Ack() error {
+	return m.settle(m.Message.Ack)
+}
+
+func (m *ackTimeoutMessage) Nak() error {
+	return m.settle(m.Message.Nak)
+}
+
+// settle 在未超时的前提下执行确认操作
+func (m *ackTimeoutMessage) settle(fn func() error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.timedOut {
+		return ErrAckTimeout
+	}
+	m.settled = true
+	return fn()
+}
+
+// stop 在 Handler 返回后停止计时并释放 Context
+func (m *ackTimeoutMessage) stop() {
+	m.timer.Stop()
+	m.cancel()
+}
+
+// startAckTimeout 为单条消息启动本地 ack 超时计时（内部使用）
+func (m *mq) startAckTimeout(topic string, msg Message, timeout time.Duration) *ackTimeoutMessage {
+	return newAckTimeoutMessage(msg, timeout, func(nakErr error) {
+		fields := []clog.Field{
+			clog.String("topic", topic),
+			clog.String("msg_id", msg.ID()),
+			clog.Duration("ack_timeout", timeout),
+		}
+		// Redis Stream 不支持 Nak，消息会在 PendingIdle 后被 XAUTOCLAIM 重新认领
+		if nakErr != nil && !errors.Is(nakErr, ErrNotSupported) {
+			fields = append(fields, clog.Error(nakErr))
+		}
+		m.logger.Warn("handler exceeded ack timeout, message nak'ed", fields...)
+	})
+}
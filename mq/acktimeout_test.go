@@ -0,0 +1,158 @@
+package mq
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// redeliveryTransport 模拟支持 Nak 重投的 Transport：每次投递在独立 goroutine 中执行
+type redeliveryTransport struct {
+	mockTransport
+
+	handler Handler
+	ctx     context.Context
+
+	deliveries atomic.Int32
+	acks       atomic.Int32
+	naks       atomic.Int32
+}
+
+func (r *redeliveryTransport) Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error {
+	r.deliver(&redeliveryMessage{transport: r, topic: topic, data: data})
+	return nil
+}
+
+func (r *redeliveryTransport) Subscribe(ctx context.Context, topic string, handler Handler, opts SubscribeOptions) (Subscription, error) {
+	r.handler = handler
+	r.ctx = ctx
+	r.lastSubscribeOpts = opts
+	return newFlakySubscription(ctx, handler), nil
+}
+
+func (r *redeliveryTransport) deliver(msg *redeliveryMessage) {
+	r.deliveries.Add(1)
+	go func() { _ = r.handler(msg) }()
+}
+
+type redeliveryMessage struct {
+	transport *redeliveryTransport
+	topic     string
+	data      []byte
+}
+
+func (m *redeliveryMessage) Context() context.Context { return m.transport.ctx }
+func (m *redeliveryMessage) Topic() string            { return m.topic }
+func (m *redeliveryMessage) Data() []byte             { return m.data }
+func (m *redeliveryMessage) Headers() Headers         { return nil }
+func (m *redeliveryMessage) ID() string               { return "msg-1" }
+
+func (m *redeliveryMessage) Ack() error {
+	m.transport.acks.Add(1)
+	return nil
+}
+
+func (m *redeliveryMessage) Nak() error {
+	m.transport.naks.Add(1)
+	m.transport.deliver(m)
+	return nil
+}
+
+// atomicMessage 在计时器 goroutine 中被确认，需要并发安全地记录 Ack/Nak
+type atomicMessage struct {
+	mockMessage
+	acked atomic.Bool
+	naked atomic.Bool
+}
+
+func (m *atomicMessage) Ack() error {
+	m.acked.Store(true)
+	return nil
+}
+
+func (m *atomicMessage) Nak() error {
+	m.naked.Store(true)
+	return nil
+}
+
+func TestMQ_AckTimeout(t *testing.T) {
+	t.Run("Handler 超时后自动 Nak 并重投，成功后不再重投", func(t *testing.T) {
+		transport := &redeliveryTransport{}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		const ackTimeout = 50 * time.Millisecond
+		var attempts atomic.Int32
+		var firstCtxErr atomic.Value
+		var wg sync.WaitGroup
+		wg.Add(1)
+
+		sub, err := m.Subscribe(ctx, "orders.created", func(msg Message) error {
+			if attempts.Add(1) == 1 {
+				defer wg.Done()
+				time.Sleep(3 * ackTimeout)
+				firstCtxErr.Store(msg.Context().Err())
+				return nil
+			}
+			return nil
+		}, WithAutoAck(), WithAckTimeout(ackTimeout))
+		require.NoError(t, err)
+		defer sub.Unsubscribe()
+		require.Equal(t, ackTimeout, transport.lastSubscribeOpts.AckTimeout)
+
+		require.NoError(t, m.Publish(ctx, "orders.created", []byte("order-1")))
+
+		// 等首次投递的 Handler 返回，期间已超时 Nak 并被重投成功
+		wg.Wait()
+		require.Eventually(t, func() bool {
+			return transport.acks.Load() == 1
+		}, time.Second, 10*time.Millisecond)
+
+		// 超时的 Context 被取消，迟到的 AutoAck 被拒绝而不是确认已重投的消息
+		require.ErrorIs(t, firstCtxErr.Load().(error), context.Canceled)
+		require.Equal(t, int32(1), transport.naks.Load())
+
+		// 成功确认后不再重投
+		time.Sleep(3 * ackTimeout)
+		require.Equal(t, int32(2), transport.deliveries.Load())
+		require.Equal(t, int32(2), attempts.Load())
+		require.Equal(t, int32(1), transport.acks.Load())
+	})
+
+	t.Run("超时后 Handler 的手动确认返回 ErrAckTimeout", func(t *testing.T) {
+		testMsg := &atomicMessage{}
+		m := &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: string(DriverNATSJetStream)}
+		var ackErr error
+		wrapped := m.wrapHandler("test.topic", func(msg Message) error {
+			time.Sleep(30 * time.Millisecond)
+			ackErr = msg.Ack()
+			return nil
+		}, SubscribeOptions{AckTimeout: 10 * time.Millisecond})
+
+		require.NoError(t, wrapped(testMsg))
+		require.ErrorIs(t, ackErr, ErrAckTimeout)
+		require.True(t, testMsg.naked.Load())
+		require.False(t, testMsg.acked.Load())
+	})
+
+	t.Run("Handler 在超时前返回不触发 Nak", func(t *testing.T) {
+		testMsg := &atomicMessage{}
+		m := &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: string(DriverNATSJetStream)}
+		wrapped := m.wrapHandler("test.topic", func(msg Message) error {
+			return nil
+		}, SubscribeOptions{AutoAck: true, AckTimeout: 20 * time.Millisecond})
+
+		require.NoError(t, wrapped(testMsg))
+		time.Sleep(40 * time.Millisecond)
+		require.True(t, testMsg.acked.Load())
+		require.False(t, testMsg.naked.Load())
+	})
+}
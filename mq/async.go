@@ -0,0 +1,152 @@
+package mq
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// PublishCallback 异步发布结果回调，err 为 nil 表示 broker 已确认
+type PublishCallback func(err error)
+
+// AsyncDriver 由支持原生异步发布确认的 Driver 实现
+//
+// 仅在 Capabilities().AsyncPublish 为 true 时被调用，否则 PublishAsync 同步发布后立即回调。
+type AsyncDriver interface {
+	// PublishAsync 发送消息后立即返回，broker 确认或失败后调用 callback（恰好一次）
+	PublishAsync(ctx context.Context, topic string, data []byte, opts PublishOptions, callback PublishCallback)
+}
+
+// pendingTracker 记录未完成的异步发布，供 Flush 等待
+type pendingTracker struct {
+	mu   sync.Mutex
+	n    int
+	idle chan struct{} // n 归零时关闭
+}
+
+func (p *pendingTracker) add() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.n == 0 {
+		p.idle = make(chan struct{})
+	}
+	p.n++
+}
+
+func (p *pendingTracker) done() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.n--
+	if p.n == 0 {
+		close(p.idle)
+	}
+}
+
+// wait 等待所有未完成的发布结束
+func (p *pendingTracker) wait(ctx context.Context) error {
+	p.mu.Lock()
+	if p.n == 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	idle := p.idle
+	p.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// PublishAsync 异步发布消息
+func (m *mq) PublishAsync(ctx context.Context, topic string, data []byte, callback PublishCallback, opts ...PublishOption) {
+	if callback == nil {
+		callback = func(error) {}
+	}
+	if m.closed.Load() {
+		callback(ErrClosed)
+		return
+	}
+	if m.throttle != nil {
+		if err := m.throttle.wait(ctx, topic); err != nil {
+			callback(err)
+			return
+		}
+		if err := m.throttle.acquire(ctx, topic); err != nil {
+			callback(err)
+			return
+		}
+	}
+
+	o := defaultPublishOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ctx, span := m.startPublishSpan(ctx, topic, &o)
+	m.pending.add()
+	start := time.Now()
+	complete := func(err error) {
+		defer m.pending.done()
+		if m.throttle != nil {
+			defer m.throttle.release()
+		}
+		m.recordPublishMetrics(ctx, topic, err, time.Since(start))
+		endSpan(span, err)
+		callback(err)
+	}
+
+	if at, ok := m.transport.(AsyncDriver); ok && m.transport.Capabilities().AsyncPublish {
+		at.PublishAsync(ctx, topic, data, o, complete)
+		return
+	}
+	complete(m.transport.Publish(ctx, topic, data, o))
+}
+
+// Flush 等待所有异步发布完成回调
+func (m *mq) Flush(ctx context.Context) error {
+	return m.pending.wait(ctx)
+}
+
+// PublishAsync 通过 JetStream 原生异步发布，收到 PubAck 后回调
+func (t *natsJetStreamTransport) PublishAsync(ctx context.Context, topic string, data []byte, opts PublishOptions, callback PublishCallback) {
+	future, err := t.js.PublishMsgAsync(&nats.Msg{
+		Subject: topic,
+		Data:    data,
+		Header:  headersToNATS(opts.Headers),
+	})
+	if err != nil {
+		callback(err)
+		return
+	}
+
+	go func() {
+		select {
+		case <-future.Ok():
+			callback(nil)
+		case err := <-future.Err():
+			callback(err)
+		case <-ctx.Done():
+			callback(ctx.Err())
+		}
+	}()
+}
+
+// PublishAsync 通过 Kafka 生产者异步发送，分区 leader 确认后回调
+func (t *kafkaTransport) PublishAsync(ctx context.Context, topic string, data []byte, opts PublishOptions, callback PublishCallback) {
+	rec := &kgo.Record{
+		Topic: topic,
+		Value: data,
+	}
+	for k, v := range opts.Headers {
+		rec.Headers = append(rec.Headers, kgo.RecordHeader{Key: k, Value: []byte(v)})
+	}
+	t.client.Produce(ctx, rec, func(_ *kgo.Record, err error) {
+		callback(err)
+	})
+}
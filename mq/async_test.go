@@ -0,0 +1,128 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// ackingTransport 模拟原生异步确认：Publish 立即返回，由 ack 统一确认或拒绝
+type ackingTransport struct {
+	mockTransport
+
+	mu      sync.Mutex
+	pending []func()
+}
+
+var errRejected = errors.New("broker rejected")
+
+func (a *ackingTransport) Capabilities() Capabilities {
+	return Capabilities{AsyncPublish: true}
+}
+
+func (a *ackingTransport) PublishAsync(ctx context.Context, topic string, data []byte, opts PublishOptions, callback PublishCallback) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.pending = append(a.pending, func() {
+		if topic == "rejected" {
+			callback(errRejected)
+			return
+		}
+		callback(nil)
+	})
+}
+
+// ack 在后台确认所有已发送的消息
+func (a *ackingTransport) ack() {
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = nil
+	a.mu.Unlock()
+	for _, done := range pending {
+		go done()
+	}
+}
+
+func TestMQ_PublishAsync(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Flush 等待全部确认", func(t *testing.T) {
+		transport := &ackingTransport{}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+
+		var succeeded, failed atomic.Int32
+		for range 20 {
+			m.PublishAsync(ctx, "orders", []byte("x"), func(err error) {
+				if err != nil {
+					failed.Add(1)
+					return
+				}
+				succeeded.Add(1)
+			})
+		}
+		require.Zero(t, succeeded.Load(), "PublishAsync 应立即返回，不等待确认")
+
+		// 未确认时 Flush 随 ctx 超时
+		shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		require.ErrorIs(t, m.Flush(shortCtx), context.DeadlineExceeded)
+
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			transport.ack()
+		}()
+		require.NoError(t, m.Flush(ctx))
+		require.EqualValues(t, 20, succeeded.Load())
+		require.Zero(t, failed.Load())
+	})
+
+	t.Run("broker 拒绝时回调带错误", func(t *testing.T) {
+		transport := &ackingTransport{}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+
+		errs := make(chan error, 2)
+		m.PublishAsync(ctx, "orders", nil, func(err error) { errs <- err })
+		m.PublishAsync(ctx, "rejected", nil, func(err error) { errs <- err })
+		transport.ack()
+		require.NoError(t, m.Flush(ctx))
+
+		close(errs)
+		var got []error
+		for err := range errs {
+			got = append(got, err)
+		}
+		require.Len(t, got, 2)
+		require.ElementsMatch(t, []error{nil, errRejected}, got)
+	})
+
+	t.Run("无原生异步确认时同步发布后回调", func(t *testing.T) {
+		transport := &mockTransport{publishError: errRejected}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+
+		var got error
+		m.PublishAsync(ctx, "orders", []byte("x"), func(err error) { got = err }, WithHeader("k", "v"))
+		require.ErrorIs(t, got, errRejected)
+		require.True(t, transport.publishCalled)
+		require.Equal(t, "v", transport.lastPublishOpts.Headers["k"])
+		require.NoError(t, m.Flush(ctx))
+	})
+
+	t.Run("关闭后立即回调 ErrClosed", func(t *testing.T) {
+		m := newMQ(&ackingTransport{}, clog.Discard(), metrics.Discard())
+		require.NoError(t, m.Close())
+
+		var got error
+		m.PublishAsync(ctx, "orders", nil, func(err error) { got = err })
+		require.ErrorIs(t, got, ErrClosed)
+		m.PublishAsync(ctx, "orders", nil, nil)
+		require.NoError(t, m.Flush(ctx))
+	})
+}
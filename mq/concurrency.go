@@ -0,0 +1,167 @@
+package mq
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// fixedConcurrency 供不支持动态并发的订阅嵌入，SetConcurrency 返回 ErrNotSupported
+type fixedConcurrency struct{}
+
+func (fixedConcurrency) SetConcurrency(int) error {
+	return xerrors.Wrap(ErrNotSupported, "subscription created without WithConcurrency")
+}
+
+// workerPool 可动态伸缩的消息处理 worker 池
+//
+// Driver 的投递 goroutine 通过无缓冲 channel 把消息交给空闲 worker，
+// 所有 worker 忙碌时投递方阻塞，在途消息数不超过 worker 数，不会有消息滞留在缓冲中。
+type workerPool struct {
+	handler Handler
+	msgs    chan Message
+	closed  chan struct{}
+
+	mu        sync.Mutex
+	stops     []chan struct{} // 每个 worker 一个停止信号
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+	running   atomic.Int32
+}
+
+func newWorkerPool(handler Handler, n int) *workerPool {
+	p := &workerPool{
+		handler: handler,
+		msgs:    make(chan Message),
+		closed:  make(chan struct{}),
+	}
+	_ = p.resize(n)
+	return p
+}
+
+// dispatch 把消息交给空闲 worker，池关闭后直接丢弃（消息未确认，由后端重投）
+func (p *workerPool) dispatch(msg Message) error {
+	select {
+	case p.msgs <- msg:
+		return nil
+	case <-p.closed:
+		return ErrClosed
+	}
+}
+
+// resize 调整 worker 数量
+//
+// 增加时立即启动新 worker；减少时通知多余 worker 在处理完手头消息后退出，不等待其结束。
+func (p *workerPool) resize(n int) error {
+	if n <= 0 {
+		return xerrors.Wrapf(ErrInvalidConfig, "concurrency must be positive, got %d", n)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	select {
+	case <-p.closed:
+		return ErrClosed
+	default:
+	}
+
+	for len(p.stops) < n {
+		stop := make(chan struct{})
+		p.stops = append(p.stops, stop)
+		p.running.Add(1)
+		p.wg.Go(func() { p.work(stop) })
+	}
+	for len(p.stops) > n {
+		last := len(p.stops) - 1
+		close(p.stops[last])
+		p.stops = p.stops[:last]
+	}
+	return nil
+}
+
+func (p *workerPool) work(stop <-chan struct{}) {
+	defer p.running.Add(-1)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-p.closed:
+			return
+		case msg := <-p.msgs:
+			_ = p.handler(msg)
+		}
+	}
+}
+
+// shutdown 停止接收消息并通知所有 worker 退出，可重复调用
+func (p *workerPool) shutdown() {
+	p.closeOnce.Do(func() {
+		p.mu.Lock()
+		close(p.closed)
+		p.stops = nil
+		p.mu.Unlock()
+	})
+}
+
+// close 停止 worker 池并等待所有 worker 退出
+func (p *workerPool) close() {
+	p.shutdown()
+	p.wg.Wait()
+}
+
+// concurrentSubscription 通过 worker 池并发处理消息的订阅
+type concurrentSubscription struct {
+	Subscription
+	pool *workerPool
+	done chan struct{}
+}
+
+// newConcurrentSubscription 启动 n 个 worker，再以 subscribe 建立底层订阅
+func newConcurrentSubscription(handler Handler, n int, subscribe func(Handler) (Subscription, error)) (*concurrentSubscription, error) {
+	pool := newWorkerPool(handler, n)
+	sub, err := subscribe(pool.dispatch)
+	if err != nil {
+		pool.close()
+		return nil, err
+	}
+
+	s := &concurrentSubscription{
+		Subscription: sub,
+		pool:         pool,
+		done:         make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		<-sub.Done()
+		pool.close()
+	}()
+	return s, nil
+}
+
+// SetConcurrency 调整处理消息的 worker 数量
+func (s *concurrentSubscription) SetConcurrency(n int) error {
+	return s.pool.resize(n)
+}
+
+// Unsubscribe 停止 worker 池并取消底层订阅
+//
+// 先停止派发，避免阻塞在 dispatch 上的投递 goroutine 拖住底层订阅的退出；
+// 不等待正在处理的消息，可通过 Done 等待所有 worker 退出。
+func (s *concurrentSubscription) Unsubscribe() error {
+	s.pool.shutdown()
+	return s.Subscription.Unsubscribe()
+}
+
+// Done 底层订阅结束且所有 worker 退出后关闭
+func (s *concurrentSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// subscribeConcurrently 以 worker 池包装 handler 后订阅（内部使用）
+func (m *mq) subscribeConcurrently(ctx context.Context, topic string, handler Handler, o SubscribeOptions) (Subscription, error) {
+	return newConcurrentSubscription(handler, o.Concurrency, func(h Handler) (Subscription, error) {
+		return newResubscribingSubscription(ctx, m.transport, topic, h, o, m.logger)
+	})
+}
@@ -0,0 +1,108 @@
+package mq
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// gatedHandler 阻塞处理消息直到收到放行信号，记录在途数与已处理数
+type gatedHandler struct {
+	release   chan struct{}
+	inflight  atomic.Int32
+	processed atomic.Int32
+}
+
+func newGatedHandler() *gatedHandler {
+	return &gatedHandler{release: make(chan struct{})}
+}
+
+func (h *gatedHandler) handle(Message) error {
+	h.inflight.Add(1)
+	<-h.release
+	h.inflight.Add(-1)
+	h.processed.Add(1)
+	return nil
+}
+
+// publishAsync 并发发布 n 条消息，返回等待全部发布完成的函数
+func publishAsync(t *testing.T, m MQ, topic string, n int) func() {
+	t.Helper()
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			_ = m.Publish(context.Background(), topic, nil)
+		})
+	}
+	return wg.Wait
+}
+
+func TestSubscription_SetConcurrency(t *testing.T) {
+	m := newMQ(&routingTransport{}, clog.Discard(), metrics.Discard())
+	h := newGatedHandler()
+
+	sub, err := m.Subscribe(context.Background(), "jobs", h.handle, WithConcurrency(2))
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+	pool := sub.(*concurrentSubscription).pool
+
+	// 初始并发 2：5 条消息只有 2 条在处理
+	wait := publishAsync(t, m, "jobs", 5)
+	require.Eventually(t, func() bool { return h.inflight.Load() == 2 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	require.EqualValues(t, 2, h.inflight.Load())
+
+	// 调到 5：剩余消息立即被新 worker 接手
+	require.NoError(t, sub.SetConcurrency(5))
+	require.Eventually(t, func() bool { return h.inflight.Load() == 5 }, time.Second, 5*time.Millisecond)
+	wait()
+
+	// 忙碌时调回 1：多余 worker 处理完手头消息后退出
+	require.NoError(t, sub.SetConcurrency(1))
+	for range 5 {
+		h.release <- struct{}{}
+	}
+	require.Eventually(t, func() bool { return pool.running.Load() == 1 }, time.Second, 5*time.Millisecond)
+	require.EqualValues(t, 5, h.processed.Load(), "缩容不丢消息")
+
+	// 并发 1：新消息逐条处理
+	wait = publishAsync(t, m, "jobs", 3)
+	for range 3 {
+		require.Eventually(t, func() bool { return h.inflight.Load() == 1 }, time.Second, 5*time.Millisecond)
+		time.Sleep(10 * time.Millisecond)
+		require.EqualValues(t, 1, h.inflight.Load())
+		h.release <- struct{}{}
+	}
+	wait()
+	require.Eventually(t, func() bool { return h.processed.Load() == 8 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, sub.Unsubscribe())
+	waitTimeout(t, sub.Done(), time.Second)
+	require.Zero(t, pool.running.Load())
+}
+
+func TestSubscription_SetConcurrencyErrors(t *testing.T) {
+	m := newMQ(&routingTransport{}, clog.Discard(), metrics.Discard())
+	ctx := context.Background()
+	noop := func(Message) error { return nil }
+
+	sub, err := m.Subscribe(ctx, "jobs", noop)
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+	require.ErrorIs(t, sub.SetConcurrency(2), ErrNotSupported, "未开启 WithConcurrency 时不支持调整")
+
+	sub, err = m.Subscribe(ctx, "jobs", noop, WithConcurrency(1))
+	require.NoError(t, err)
+	require.ErrorIs(t, sub.SetConcurrency(0), ErrInvalidConfig)
+
+	require.NoError(t, sub.Unsubscribe())
+	waitTimeout(t, sub.Done(), time.Second)
+	require.ErrorIs(t, sub.SetConcurrency(2), ErrClosed)
+}
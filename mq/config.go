@@ -6,28 +6,34 @@ import (
 	"github.com/ceyewan/genesis/xerrors"
 )
 
-// Driver 驱动类型
-type Driver string
+// DriverType 内置驱动类型
+type DriverType string
 
 const (
 	// DriverNATSJetStream NATS JetStream 驱动（持久化，支持 Ack/Nak 重投）
-	DriverNATSJetStream Driver = "nats_jetstream"
+	DriverNATSJetStream DriverType = "nats_jetstream"
 
 	// DriverRedisStream Redis Stream 驱动（持久化，Consumer Group）
-	DriverRedisStream Driver = "redis_stream"
+	DriverRedisStream DriverType = "redis_stream"
+
+	// DriverKafka Kafka 驱动（持久化，Consumer Group + offset 提交）
+	DriverKafka DriverType = "kafka"
 )
 
 // Config MQ 配置
 type Config struct {
-	// Driver 底层驱动类型，必填
-	// 可选值：nats_jetstream, redis_stream
-	Driver Driver `json:"driver" yaml:"driver" mapstructure:"driver"`
+	// Driver 底层驱动类型，未通过 WithDriver 注入自定义驱动时必填
+	// 可选值：nats_jetstream, redis_stream, kafka；使用自定义驱动时仅作为指标与日志中的驱动名
+	Driver DriverType `json:"driver" yaml:"driver" mapstructure:"driver"`
 
 	// JetStream JetStream 特有配置（仅 DriverNATSJetStream 时生效）
 	JetStream *JetStreamConfig `json:"jetstream,omitempty" yaml:"jetstream,omitempty" mapstructure:"jetstream"`
 
 	// RedisStream Redis Stream 特有配置（仅 DriverRedisStream 时生效）
 	RedisStream *RedisStreamConfig `json:"redis_stream,omitempty" yaml:"redis_stream,omitempty" mapstructure:"redis_stream"`
+
+	// Kafka Kafka 特有配置（仅 DriverKafka 时生效）
+	Kafka *KafkaConfig `json:"kafka,omitempty" yaml:"kafka,omitempty" mapstructure:"kafka"`
 }
 
 // JetStreamConfig JetStream 特有配置
@@ -58,6 +64,23 @@ type RedisStreamConfig struct {
 	PendingIdle time.Duration `json:"pending_idle" yaml:"pending_idle" mapstructure:"pending_idle"`
 }
 
+// Kafka 消费组起始位置
+const (
+	// KafkaOffsetLatest 从最新位置开始消费，只接收订阅之后的新消息
+	KafkaOffsetLatest = "latest"
+	// KafkaOffsetEarliest 从最早的保留消息开始消费
+	KafkaOffsetEarliest = "earliest"
+)
+
+// KafkaConfig Kafka 特有配置
+type KafkaConfig struct {
+	// OffsetReset 消费组没有已提交 offset 时的起始位置，默认 "latest"
+	// 可选值：latest, earliest
+	// 注意：消费组首次启动即处理失败时尚无已提交 offset，latest 下重启会跳过这些消息，
+	// 需要"失败必重消费"的场景应设为 earliest
+	OffsetReset string `json:"offset_reset" yaml:"offset_reset" mapstructure:"offset_reset"`
+}
+
 // setDefaults 设置默认值
 func (c *Config) setDefaults() {
 	if c.JetStream == nil {
@@ -76,6 +99,13 @@ func (c *Config) setDefaults() {
 	if c.RedisStream.PendingIdle == 0 {
 		c.RedisStream.PendingIdle = 30 * time.Second
 	}
+
+	if c.Kafka == nil {
+		c.Kafka = &KafkaConfig{}
+	}
+	if c.Kafka.OffsetReset == "" {
+		c.Kafka.OffsetReset = KafkaOffsetLatest
+	}
 }
 
 // validate 验证配置
@@ -87,6 +117,12 @@ func (c *Config) validate() error {
 	switch c.Driver {
 	case DriverNATSJetStream, DriverRedisStream:
 		return nil
+	case DriverKafka:
+		if c.Kafka != nil && c.Kafka.OffsetReset != "" &&
+			c.Kafka.OffsetReset != KafkaOffsetLatest && c.Kafka.OffsetReset != KafkaOffsetEarliest {
+			return xerrors.Wrapf(ErrInvalidConfig, "unsupported kafka offset_reset: %s", c.Kafka.OffsetReset)
+		}
+		return nil
 	default:
 		return xerrors.Wrapf(ErrInvalidConfig, "unsupported driver: %s", c.Driver)
 	}
@@ -0,0 +1,227 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// memoryDriver 只依赖导出类型实现的内存 Driver，模拟在 mq 包外接入新后端
+type memoryDriver struct {
+	mu     sync.Mutex
+	seq    int
+	subs   map[string][]*memorySubscription
+	next   map[string]int // topic/group -> 下一个轮询下标
+	closed bool
+}
+
+func newMemoryDriver() *memoryDriver {
+	return &memoryDriver{subs: make(map[string][]*memorySubscription), next: make(map[string]int)}
+}
+
+func (d *memoryDriver) Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return ErrClosed
+	}
+	d.seq++
+	id := strconv.Itoa(d.seq)
+
+	// 独立订阅各收一份，同一 QueueGroup 内轮询选一个
+	var targets []*memorySubscription
+	groups := make(map[string][]*memorySubscription)
+	for _, sub := range d.subs[topic] {
+		if !sub.IsActive() {
+			continue
+		}
+		if sub.group == "" {
+			targets = append(targets, sub)
+			continue
+		}
+		groups[sub.group] = append(groups[sub.group], sub)
+	}
+	for group, members := range groups {
+		key := topic + "/" + group
+		targets = append(targets, members[d.next[key]%len(members)])
+		d.next[key]++
+	}
+	d.mu.Unlock()
+
+	for _, sub := range targets {
+		_ = sub.handler(&memoryMessage{ctx: sub.ctx, id: id, topic: topic, data: data, headers: opts.Headers.Clone()})
+	}
+	return nil
+}
+
+func (d *memoryDriver) Subscribe(ctx context.Context, topic string, handler Handler, opts SubscribeOptions) (Subscription, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return nil, ErrClosed
+	}
+	sub := &memorySubscription{ctx: ctx, group: opts.QueueGroup, handler: handler, done: make(chan struct{})}
+	d.subs[topic] = append(d.subs[topic], sub)
+	return sub, nil
+}
+
+func (d *memoryDriver) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+func (d *memoryDriver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.closed = true
+	return nil
+}
+
+type memorySubscription struct {
+	ctx     context.Context
+	group   string
+	handler Handler
+	once    sync.Once
+	done    chan struct{}
+}
+
+func (s *memorySubscription) Unsubscribe() error {
+	s.once.Do(func() { close(s.done) })
+	return nil
+}
+
+func (s *memorySubscription) Done() <-chan struct{} { return s.done }
+
+func (s *memorySubscription) IsActive() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *memorySubscription) SetConcurrency(int) error { return ErrNotSupported }
+
+type memoryMessage struct {
+	ctx     context.Context
+	id      string
+	topic   string
+	data    []byte
+	headers Headers
+
+	mu    sync.Mutex
+	acked bool
+	naked bool
+}
+
+func (m *memoryMessage) Context() context.Context { return m.ctx }
+func (m *memoryMessage) Topic() string            { return m.topic }
+func (m *memoryMessage) Data() []byte             { return m.data }
+func (m *memoryMessage) Headers() Headers         { return m.headers.Clone() }
+func (m *memoryMessage) ID() string               { return m.id }
+
+func (m *memoryMessage) Ack() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.acked = true
+	return nil
+}
+
+func (m *memoryMessage) Nak() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.naked = true
+	return nil
+}
+
+func TestNew_WithDriver(t *testing.T) {
+	ctx := context.Background()
+
+	newMemoryMQ := func(t *testing.T, cfg *Config) (MQ, *memoryDriver) {
+		t.Helper()
+		driver := newMemoryDriver()
+		m, err := New(cfg, WithDriver(driver), WithLogger(clog.Discard()), WithMeter(metrics.Discard()))
+		require.NoError(t, err)
+		return m, driver
+	}
+
+	t.Run("自定义驱动跑通 Publish/Subscribe", func(t *testing.T) {
+		m, _ := newMemoryMQ(t, &Config{Driver: "memory"})
+		defer m.Close()
+
+		var got []*memoryMessage
+		sub, err := m.Subscribe(ctx, "orders.created", func(msg Message) error {
+			got = append(got, msg.(*memoryMessage))
+			return nil
+		}, WithAutoAck())
+		require.NoError(t, err)
+		defer sub.Unsubscribe()
+
+		require.NoError(t, m.Publish(ctx, "orders.created", []byte("order-1"), WithHeader("trace-id", "t1")))
+		require.Len(t, got, 1)
+		require.Equal(t, "order-1", string(got[0].Data()))
+		require.Equal(t, "t1", got[0].Headers().Get("trace-id"))
+		require.True(t, got[0].acked, "AutoAck should be applied by the core, not the driver")
+	})
+
+	t.Run("QueueGroup 负载均衡且失败自动 Nak", func(t *testing.T) {
+		m, _ := newMemoryMQ(t, &Config{})
+		defer m.Close()
+
+		var mu sync.Mutex
+		counts := map[string]int{}
+		var msgs []*memoryMessage
+		for _, name := range []string{"a", "b"} {
+			_, err := m.Subscribe(ctx, "jobs", func(msg Message) error {
+				mu.Lock()
+				defer mu.Unlock()
+				counts[name]++
+				msgs = append(msgs, msg.(*memoryMessage))
+				return errors.New("retry later")
+			}, WithQueueGroup("workers"), WithAutoAck())
+			require.NoError(t, err)
+		}
+
+		for i := range 4 {
+			require.NoError(t, m.Publish(ctx, "jobs", []byte(strconv.Itoa(i))))
+		}
+		require.Equal(t, map[string]int{"a": 2, "b": 2}, counts)
+		for _, msg := range msgs {
+			require.True(t, msg.naked)
+		}
+	})
+
+	t.Run("未声明的能力按不支持处理", func(t *testing.T) {
+		m, _ := newMemoryMQ(t, &Config{})
+		defer m.Close()
+
+		_, err := m.SubscribeBatch(ctx, "jobs", func([]Message) error { return nil }, 10, time.Second)
+		require.ErrorIs(t, err, ErrNotSupported)
+
+		err = m.PublishInTransaction(ctx, "jobs", []byte("x"), func() error { return nil })
+		require.ErrorIs(t, err, ErrNotSupported)
+
+		done := make(chan error, 1)
+		m.PublishAsync(ctx, "jobs", []byte("x"), func(err error) { done <- err })
+		require.NoError(t, <-done)
+	})
+
+	t.Run("Close 关闭驱动", func(t *testing.T) {
+		m, driver := newMemoryMQ(t, &Config{})
+		require.NoError(t, m.Close())
+		require.True(t, driver.closed)
+	})
+
+	t.Run("未注入驱动时仍校验 Config.Driver", func(t *testing.T) {
+		_, err := New(&Config{Driver: "memory"})
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+}
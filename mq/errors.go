@@ -16,6 +16,21 @@ var (
 	// ErrSubscriptionClosed 订阅已关闭
 	ErrSubscriptionClosed = xerrors.New("mq: subscription closed")
 
+	// ErrAckTimeout Handler 超过 AckTimeout 未返回，消息已被自动 Nak
+	ErrAckTimeout = xerrors.New("mq: ack timeout exceeded")
+
+	// ErrHandlerTimeout Handler 超过 HandlerTimeout，msg.Context() 已被取消
+	ErrHandlerTimeout = xerrors.New("mq: handler timeout exceeded")
+
+	// ErrPublishThrottled 发布超过速率限制或异步发布 pending 数达到上限（仅 WithPublishRejectWhenThrottled 时返回）
+	ErrPublishThrottled = xerrors.New("mq: publish throttled")
+
+	// ErrSchemaViolation 消息 payload 不符合 schema
+	ErrSchemaViolation = xerrors.New("mq: schema violation")
+
+	// ErrHalfMessageExpired 事务消息的半消息已过期，本地事务耗时过长
+	ErrHalfMessageExpired = xerrors.New("mq: half message expired")
+
 	// ErrPanicRecovered Handler panic 已恢复
 	ErrPanicRecovered = xerrors.New("mq: handler panic recovered")
 )
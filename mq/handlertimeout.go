@@ -0,0 +1,37 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// deadlineMessage 为消息附加带处理超时的 Context
+type deadlineMessage struct {
+	Message
+	ctx context.Context
+}
+
+func (m *deadlineMessage) Context() context.Context {
+	return m.ctx
+}
+
+// withHandlerTimeout 为消息 Context 附加 deadline，超时原因为 ErrHandlerTimeout
+//
+// 返回的 cancel 必须在 Handler 返回后调用以释放计时器。
+func withHandlerTimeout(msg Message, timeout time.Duration) (*deadlineMessage, context.CancelFunc) {
+	ctx, cancel := context.WithTimeoutCause(msg.Context(), timeout, ErrHandlerTimeout)
+	return &deadlineMessage{Message: msg, ctx: ctx}, cancel
+}
+
+// handlerTimeoutError Handler 因处理超时返回错误时，附加 ErrHandlerTimeout 便于识别
+//
+// Handler 在超时后仍返回 nil 视为处理成功；只有上游 Context 取消（非本地超时）时原样返回。
+func handlerTimeoutError(ctx context.Context, err error) error {
+	if err == nil || !errors.Is(context.Cause(ctx), ErrHandlerTimeout) || errors.Is(err, ErrHandlerTimeout) {
+		return err
+	}
+	return xerrors.Combine(ErrHandlerTimeout, err)
+}
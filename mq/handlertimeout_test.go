@@ -0,0 +1,104 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+func TestMQ_HandlerTimeout(t *testing.T) {
+	newTestMQ := func() *mq {
+		return &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: string(DriverNATSJetStream)}
+	}
+
+	t.Run("超时取消 Context，Handler 提前返回并 Nak", func(t *testing.T) {
+		testMsg := &atomicMessage{}
+		var cause error
+		start := time.Now()
+		wrapped := newTestMQ().wrapHandler("test.topic", func(msg Message) error {
+			select {
+			case <-msg.Context().Done():
+				cause = context.Cause(msg.Context())
+				return msg.Context().Err()
+			case <-time.After(time.Second):
+				return nil
+			}
+		}, SubscribeOptions{AutoAck: true, HandlerTimeout: 20 * time.Millisecond})
+
+		err := wrapped(testMsg)
+		require.Less(t, time.Since(start), 500*time.Millisecond, "Handler 感知取消后提前返回")
+		require.ErrorIs(t, cause, ErrHandlerTimeout)
+		require.ErrorIs(t, err, ErrHandlerTimeout)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.True(t, testMsg.naked.Load())
+		require.False(t, testMsg.acked.Load())
+	})
+
+	t.Run("超时前完成正常 Ack", func(t *testing.T) {
+		testMsg := &atomicMessage{}
+		var deadline bool
+		wrapped := newTestMQ().wrapHandler("test.topic", func(msg Message) error {
+			_, deadline = msg.Context().Deadline()
+			return nil
+		}, SubscribeOptions{AutoAck: true, HandlerTimeout: time.Second})
+
+		require.NoError(t, wrapped(testMsg))
+		require.True(t, deadline, "传给 Handler 的 Context 带 deadline")
+		require.True(t, testMsg.acked.Load())
+		require.False(t, testMsg.naked.Load())
+	})
+
+	t.Run("超时后仍返回 nil 视为成功", func(t *testing.T) {
+		testMsg := &atomicMessage{}
+		wrapped := newTestMQ().wrapHandler("test.topic", func(msg Message) error {
+			<-msg.Context().Done()
+			return nil
+		}, SubscribeOptions{AutoAck: true, HandlerTimeout: 10 * time.Millisecond})
+
+		require.NoError(t, wrapped(testMsg))
+		require.True(t, testMsg.acked.Load())
+	})
+
+	t.Run("超时内的普通错误不附加 ErrHandlerTimeout", func(t *testing.T) {
+		bizErr := errors.New("biz failed")
+		wrapped := newTestMQ().wrapHandler("test.topic", func(msg Message) error {
+			return bizErr
+		}, SubscribeOptions{HandlerTimeout: time.Second})
+
+		err := wrapped(&atomicMessage{})
+		require.ErrorIs(t, err, bizErr)
+		require.NotErrorIs(t, err, ErrHandlerTimeout)
+	})
+
+	t.Run("配合 WithRetry 超时后停止重试", func(t *testing.T) {
+		var attempts atomic.Int32
+		handler := WithRetry(RetryConfig{MaxRetries: 10, InitialBackoff: 15 * time.Millisecond, MaxBackoff: time.Second}, nil)(func(msg Message) error {
+			attempts.Add(1)
+			return errors.New("db busy")
+		})
+		testMsg := &atomicMessage{}
+		wrapped := newTestMQ().wrapHandler("test.topic", handler, SubscribeOptions{AutoAck: true, HandlerTimeout: 40 * time.Millisecond})
+
+		err := wrapped(testMsg)
+		require.ErrorIs(t, err, ErrHandlerTimeout)
+		require.Less(t, attempts.Load(), int32(11))
+		require.True(t, testMsg.naked.Load())
+	})
+
+	t.Run("Subscribe 透传选项", func(t *testing.T) {
+		transport := &redeliveryTransport{}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+		sub, err := m.Subscribe(context.Background(), "orders.created", func(msg Message) error { return nil },
+			WithHandlerTimeout(time.Second))
+		require.NoError(t, err)
+		defer sub.Unsubscribe()
+		require.Equal(t, time.Second, transport.lastSubscribeOpts.HandlerTimeout)
+	})
+}
@@ -6,17 +6,27 @@ import (
 	"sync/atomic"
 	"time"
 
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/metrics"
+	"github.com/ceyewan/genesis/trace"
+	"github.com/ceyewan/genesis/xerrors"
 )
 
 // mq 是 MQ 接口的实现
 type mq struct {
-	transport Transport
-	logger    clog.Logger
-	meter     metrics.Meter
-	driver    Driver
-	closed    atomic.Bool
+	transport     Driver
+	logger        clog.Logger
+	meter         metrics.Meter
+	tracer        oteltrace.Tracer             // 未注入 TracerProvider 时为 nil，不创建 span
+	traceRelation trace.MessagingTraceRelation // consumer span 与 producer span 的关系
+	driver        string
+	txOutbox      *gorm.DB
+	closed        atomic.Bool
+	pending       pendingTracker
+	throttle      *publishThrottle // 未配置发布限流与 pending 上限时为 nil
 }
 
 // Publish 发布消息
@@ -25,6 +35,12 @@ func (m *mq) Publish(ctx context.Context, topic string, data []byte, opts ...Pub
 		return ErrClosed
 	}
 
+	if m.throttle != nil {
+		if err := m.throttle.wait(ctx, topic); err != nil {
+			return err
+		}
+	}
+
 	// 应用选项
 	o := defaultPublishOptions()
 	for _, opt := range opts {
@@ -32,11 +48,13 @@ func (m *mq) Publish(ctx context.Context, topic string, data []byte, opts ...Pub
 	}
 
 	// 发布消息
+	ctx, span := m.startPublishSpan(ctx, topic, &o)
 	start := time.Now()
 	err := m.transport.Publish(ctx, topic, data, o)
 
 	// 记录指标
 	m.recordPublishMetrics(ctx, topic, err, time.Since(start))
+	endSpan(span, err)
 
 	return err
 }
@@ -54,7 +72,66 @@ func (m *mq) Subscribe(ctx context.Context, topic string, handler Handler, opts
 	}
 
 	wrappedHandler := m.wrapHandler(topic, handler, o)
-	return m.transport.Subscribe(ctx, topic, wrappedHandler, o)
+	if o.Concurrency > 0 {
+		return m.subscribeConcurrently(ctx, topic, wrappedHandler, o)
+	}
+	sub, err := newResubscribingSubscription(ctx, m.transport, topic, wrappedHandler, o, m.logger)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// SubscribeMany 以同一个 handler 订阅多个主题
+func (m *mq) SubscribeMany(ctx context.Context, topics []string, handler Handler, opts ...SubscribeOption) (Subscription, error) {
+	if m.closed.Load() {
+		return nil, ErrClosed
+	}
+	if len(topics) == 0 {
+		return nil, xerrors.Wrap(ErrInvalidConfig, "topics is empty")
+	}
+
+	seen := make(map[string]struct{}, len(topics))
+	subs := make([]Subscription, 0, len(topics))
+	for _, topic := range topics {
+		if _, ok := seen[topic]; ok {
+			continue
+		}
+		seen[topic] = struct{}{}
+
+		sub, err := m.Subscribe(ctx, topic, handler, opts...)
+		if err != nil {
+			for _, s := range subs {
+				_ = s.Unsubscribe()
+			}
+			return nil, xerrors.Wrapf(err, "subscribe %s failed", topic)
+		}
+		subs = append(subs, sub)
+	}
+	return newMultiSubscription(subs), nil
+}
+
+// SubscribeBatch 批量订阅消息
+func (m *mq) SubscribeBatch(ctx context.Context, topic string, handler BatchHandler, batchSize int, maxWait time.Duration, opts ...SubscribeOption) (Subscription, error) {
+	if m.closed.Load() {
+		return nil, ErrClosed
+	}
+	if batchSize <= 0 || maxWait <= 0 {
+		return nil, xerrors.Wrap(ErrInvalidConfig, "batch size and max wait must be positive")
+	}
+
+	bt, ok := m.transport.(BatchDriver)
+	if !ok || !m.transport.Capabilities().Batch {
+		return nil, xerrors.Wrapf(ErrNotSupported, "subscribe batch on driver %s", m.driver)
+	}
+
+	// 应用选项
+	o := defaultSubscribeOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return bt.SubscribeBatch(ctx, topic, m.wrapBatchHandler(topic, handler), batchSize, maxWait, o)
 }
 
 // Close 关闭 MQ（幂等）
@@ -66,11 +143,43 @@ func (m *mq) Close() error {
 }
 
 // wrapHandler 包装 Handler，添加统一的指标、日志和自动确认逻辑
-func (m *mq) wrapHandler(topic string, handler Handler, opts subscribeOptions) Handler {
-	return func(msg Message) error {
+func (m *mq) wrapHandler(topic string, handler Handler, opts SubscribeOptions) Handler {
+	return func(msg Message) (err error) {
 		start := time.Now()
+		// 从消息头串联上游 trace，Handler 通过 msg.Context() 拿到 consumer span
+		msg, span := m.startConsumeSpan(topic, msg, opts)
+		defer func() { endSpan(span, err) }()
+		// Schema 校验：不符合的消息直接进死信，不调用 Handler，也不走自动确认
+		if opts.Schema != nil {
+			if rejected, err := m.validateSchema(topic, msg, opts); rejected {
+				m.recordConsumeMetrics(msg.Context(), topic, ErrSchemaViolation)
+				return err
+			}
+		}
+		// 本地 ack 超时：Handler 超时未返回时自动 Nak，之后的 Ack/Nak 返回 ErrAckTimeout
+		if opts.AckTimeout > 0 {
+			tm := m.startAckTimeout(topic, msg, opts.AckTimeout)
+			defer tm.stop()
+			msg = tm
+		}
+		// 处理超时：Handler 通过 msg.Context() 感知 deadline，超时返回的错误附加 ErrHandlerTimeout
+		if opts.HandlerTimeout > 0 {
+			dm, cancel := withHandlerTimeout(msg, opts.HandlerTimeout)
+			defer cancel()
+			msg = dm
+		}
 		// 执行用户 Handler
-		err := handler(msg)
+		err = handler(msg)
+		if opts.HandlerTimeout > 0 {
+			err = handlerTimeoutError(msg.Context(), err)
+			if errors.Is(err, ErrHandlerTimeout) {
+				m.logger.Warn("handler exceeded handler timeout",
+					clog.String("topic", topic),
+					clog.String("msg_id", msg.ID()),
+					clog.Duration("handler_timeout", opts.HandlerTimeout),
+				)
+			}
+		}
 		// 在 handler 执行后记录指标，才能带上处理结果
 		m.recordConsumeMetrics(msg.Context(), topic, err)
 		m.recordHandleDuration(msg.Context(), topic, time.Since(start))
@@ -78,7 +187,8 @@ func (m *mq) wrapHandler(topic string, handler Handler, opts subscribeOptions) H
 		// 自动确认逻辑（统一在上层处理）
 		if opts.AutoAck {
 			if err == nil {
-				if ackErr := msg.Ack(); ackErr != nil {
+				// 超时后消息已被 Nak 重投，ErrAckTimeout 是预期结果，不记录错误
+				if ackErr := msg.Ack(); ackErr != nil && !errors.Is(ackErr, ErrAckTimeout) {
 					m.logger.Error("auto ack failed",
 						clog.String("topic", topic),
 						clog.String("msg_id", msg.ID()),
@@ -88,7 +198,7 @@ func (m *mq) wrapHandler(topic string, handler Handler, opts subscribeOptions) H
 			} else {
 				// Handler 返回错误时调用 Nak 触发重新投递
 				// 注意：Redis Stream 的 Nak 返回 ErrNotSupported，这是预期行为，不记录错误
-				if nakErr := msg.Nak(); nakErr != nil && !errors.Is(nakErr, ErrNotSupported) {
+				if nakErr := msg.Nak(); nakErr != nil && !errors.Is(nakErr, ErrNotSupported) && !errors.Is(nakErr, ErrAckTimeout) {
 					m.logger.Error("auto nak failed",
 						clog.String("topic", topic),
 						clog.String("msg_id", msg.ID()),
@@ -101,13 +211,39 @@ func (m *mq) wrapHandler(topic string, handler Handler, opts subscribeOptions) H
 	}
 }
 
+// wrapBatchHandler 包装 BatchHandler，按消息记录消费指标、按批次记录处理耗时
+func (m *mq) wrapBatchHandler(topic string, handler BatchHandler) BatchHandler {
+	return func(msgs []Message) error {
+		if len(msgs) == 0 {
+			return nil
+		}
+		msgs, span := m.startBatchConsumeSpan(topic, msgs)
+		start := time.Now()
+		err := handler(msgs)
+		endSpan(span, err)
+		ctx := msgs[0].Context()
+		for range msgs {
+			m.recordConsumeMetrics(ctx, topic, err)
+		}
+		m.recordHandleDuration(ctx, topic, time.Since(start))
+		if err != nil {
+			m.logger.Warn("batch handler failed",
+				clog.String("topic", topic),
+				clog.Int("batch_size", len(msgs)),
+				clog.Error(err),
+			)
+		}
+		return err
+	}
+}
+
 // recordPublishMetrics 记录发布指标
 func (m *mq) recordPublishMetrics(ctx context.Context, topic string, err error, duration time.Duration) {
 	status := "success"
 	if err != nil {
 		status = "error"
 	}
-	driver := string(m.driver)
+	driver := m.driver
 
 	if counter, counterErr := m.meter.Counter(MetricPublishTotal, "Total number of messages published"); counterErr == nil {
 		counter.Inc(ctx, metrics.L(LabelTopic, topic), metrics.L(LabelStatus, status), metrics.L(LabelDriver, driver))
@@ -125,13 +261,13 @@ func (m *mq) recordConsumeMetrics(ctx context.Context, topic string, err error)
 		status = "error"
 	}
 	if counter, counterErr := m.meter.Counter(MetricConsumeTotal, "Total number of messages consumed"); counterErr == nil {
-		counter.Inc(ctx, metrics.L(LabelTopic, topic), metrics.L(LabelStatus, status), metrics.L(LabelDriver, string(m.driver)))
+		counter.Inc(ctx, metrics.L(LabelTopic, topic), metrics.L(LabelStatus, status), metrics.L(LabelDriver, m.driver))
 	}
 }
 
 // recordHandleDuration 记录处理耗时
 func (m *mq) recordHandleDuration(ctx context.Context, topic string, duration time.Duration) {
 	if histogram, err := m.meter.Histogram(MetricHandleDuration, "Message handler duration in seconds", metrics.WithUnit("s")); err == nil {
-		histogram.Record(ctx, duration.Seconds(), metrics.L(LabelTopic, topic), metrics.L(LabelDriver, string(m.driver)))
+		histogram.Record(ctx, duration.Seconds(), metrics.L(LabelTopic, topic), metrics.L(LabelDriver, m.driver))
 	}
 }
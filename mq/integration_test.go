@@ -1,13 +1,16 @@
 package mq
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
 
+	"github.com/ceyewan/genesis/connector"
 	"github.com/ceyewan/genesis/testkit"
 )
 
@@ -76,6 +79,40 @@ func TestJetStreamPublishSubscribeIntegration(t *testing.T) {
 	waitTimeout(t, done, 3*time.Second)
 }
 
+func TestJetStreamPublishAsyncIntegration(t *testing.T) {
+	ctx, cancel := testkit.NewContext(t, 10*time.Second)
+	defer cancel()
+
+	mq := newJetStreamMQ(t)
+	subject := uniqueSubject()
+
+	// 订阅时自动创建 Stream，之后发布才会被确认
+	sub, err := mq.Subscribe(ctx, subject, func(msg Message) error { return nil })
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sub.Unsubscribe() })
+
+	var mu sync.Mutex
+	var errs []error
+	for i := range 20 {
+		mq.PublishAsync(ctx, subject, fmt.Appendf(nil, "msg-%d", i), func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		})
+	}
+	require.NoError(t, mq.Flush(ctx))
+	require.Len(t, errs, 20)
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	// 没有 Stream 承接的 subject 会被 broker 拒绝
+	var rejected error
+	mq.PublishAsync(ctx, fmt.Sprintf("nostream%s.event", testkit.NewID()), []byte("x"), func(err error) { rejected = err })
+	require.NoError(t, mq.Flush(ctx))
+	require.Error(t, rejected)
+}
+
 func TestJetStreamHeadersIntegration(t *testing.T) {
 	ctx, cancel := testkit.NewContext(t, 5*time.Second)
 	defer cancel()
@@ -221,3 +258,260 @@ func TestJetStreamDurableResumeIntegration(t *testing.T) {
 
 	waitTimeout(t, second, 5*time.Second)
 }
+
+func TestJetStreamWildcardSubscribeIntegration(t *testing.T) {
+	ctx, cancel := testkit.NewContext(t, 10*time.Second)
+	defer cancel()
+
+	mq := newJetStreamMQ(t)
+	prefix := "o" + testkit.NewID()
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	received := make(chan struct{}, 3)
+	sub, err := mq.Subscribe(ctx, prefix+".*", func(msg Message) error {
+		mu.Lock()
+		got = append(got, msg.Topic())
+		mu.Unlock()
+		received <- struct{}{}
+		return nil
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sub.Unsubscribe() })
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, mq.Publish(ctx, prefix+".created", []byte("1")))
+	require.NoError(t, mq.Publish(ctx, prefix+".paid", []byte("2")))
+	require.NoError(t, mq.Publish(ctx, prefix+".eu.created", []byte("3")))
+
+	for range 2 {
+		waitTimeout(t, received, 5*time.Second)
+	}
+	select {
+	case <-received:
+		t.Fatal("topic not matching the wildcard should not be received")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.ElementsMatch(t, []string{prefix + ".created", prefix + ".paid"}, got)
+}
+
+func TestJetStreamSubscribeManyIntegration(t *testing.T) {
+	ctx, cancel := testkit.NewContext(t, 10*time.Second)
+	defer cancel()
+
+	mq := newJetStreamMQ(t)
+	orders := uniqueSubject()
+	payments := uniqueSubject()
+
+	var (
+		mu  sync.Mutex
+		got = map[string]string{}
+	)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	sub, err := mq.SubscribeMany(ctx, []string{orders, payments}, func(msg Message) error {
+		mu.Lock()
+		got[msg.Topic()] = string(msg.Data())
+		mu.Unlock()
+		wg.Done()
+		return nil
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sub.Unsubscribe() })
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, mq.Publish(ctx, orders, []byte("order")))
+	require.NoError(t, mq.Publish(ctx, payments, []byte("payment")))
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	waitTimeout(t, done, 5*time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, map[string]string{orders: "order", payments: "payment"}, got)
+}
+
+// =============================================================================
+// Kafka
+// =============================================================================
+
+func newKafkaMQ(t *testing.T) MQ {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	kit := testkit.NewKit(t)
+	kafkaCfg := testkit.NewKafkaContainerConfig(t)
+	kafkaCfg.AllowAutoTopicCreate = true
+	conn, err := connector.NewKafka(kafkaCfg, connector.WithLogger(kit.Logger))
+	require.NoError(t, err)
+	require.NoError(t, conn.Connect(t.Context()))
+	t.Cleanup(func() { _ = conn.Close() })
+
+	mq, err := New(&Config{
+		Driver: DriverKafka,
+		Kafka:  &KafkaConfig{OffsetReset: KafkaOffsetEarliest},
+	},
+		WithKafkaConnector(conn),
+		WithLogger(kit.Logger),
+		WithMeter(kit.Meter),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mq.Close() })
+
+	return mq
+}
+
+func TestKafkaManualCommitIntegration(t *testing.T) {
+	ctx, cancel := testkit.NewContext(t, 60*time.Second)
+	defer cancel()
+
+	mq := newKafkaMQ(t)
+	topic := fmt.Sprintf("t%s", testkit.NewID())
+	group := uniqueGroup()
+
+	require.NoError(t, mq.Publish(ctx, topic, []byte("order-1")))
+
+	// 第一个消费者处理失败，offset 不应被提交
+	failed := make(chan struct{})
+	var once sync.Once
+	sub1, err := mq.Subscribe(ctx, topic, func(msg Message) error {
+		once.Do(func() { close(failed) })
+		return errors.New("handler failed")
+	}, WithQueueGroup(group), WithManualCommit(), WithAutoAck())
+	require.NoError(t, err)
+	waitTimeout(t, failed, 30*time.Second)
+	require.NoError(t, sub1.Unsubscribe())
+	<-sub1.Done()
+
+	// 模拟重启：同组新消费者应重新消费到该消息
+	redelivered := make(chan struct{})
+	sub2, err := mq.Subscribe(ctx, topic, func(msg Message) error {
+		if string(msg.Data()) == "order-1" {
+			close(redelivered)
+		}
+		return nil
+	}, WithQueueGroup(group), WithManualCommit(), WithAutoAck())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sub2.Unsubscribe() })
+
+	waitTimeout(t, redelivered, 30*time.Second)
+}
+
+func TestKafkaPublishAsyncIntegration(t *testing.T) {
+	ctx, cancel := testkit.NewContext(t, 60*time.Second)
+	defer cancel()
+
+	mq := newKafkaMQ(t)
+	topic := fmt.Sprintf("t%s", testkit.NewID())
+
+	var mu sync.Mutex
+	var errs []error
+	for i := range 20 {
+		mq.PublishAsync(ctx, topic, fmt.Appendf(nil, "msg-%d", i), func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		})
+	}
+	require.NoError(t, mq.Flush(ctx))
+	require.Len(t, errs, 20)
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestKafkaSubscribeBatchIntegration(t *testing.T) {
+	ctx, cancel := testkit.NewContext(t, 60*time.Second)
+	defer cancel()
+
+	mq := newKafkaMQ(t)
+	topic := fmt.Sprintf("t%s", testkit.NewID())
+
+	for i := range 7 {
+		require.NoError(t, mq.Publish(ctx, topic, fmt.Appendf(nil, "m%d", i)))
+	}
+
+	sizes := make(chan int, 10)
+	sub, err := mq.SubscribeBatch(ctx, topic, func(msgs []Message) error {
+		sizes <- len(msgs)
+		return nil
+	}, 3, 2*time.Second, WithQueueGroup(uniqueGroup()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sub.Unsubscribe() })
+
+	var got []int
+	for len(got) < 3 {
+		select {
+		case n := <-sizes:
+			got = append(got, n)
+		case <-time.After(30 * time.Second):
+			t.Fatalf("timeout waiting for batches, got %v", got)
+		}
+	}
+	require.Equal(t, []int{3, 3, 1}, got)
+}
+
+func TestRedisStreamTransactionIntegration(t *testing.T) {
+	ctx, cancel := testkit.NewContext(t, 30*time.Second)
+	defer cancel()
+
+	kit := testkit.NewKit(t)
+	redisConn := testkit.NewRedisContainerConnector(t)
+	mq, err := New(&Config{Driver: DriverRedisStream},
+		WithRedisConnector(redisConn),
+		WithLogger(kit.Logger),
+		WithMeter(kit.Meter),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = mq.Close() })
+
+	topic := uniqueSubject()
+	received := make(chan Message, 2)
+	sub, err := mq.Subscribe(ctx, topic, func(msg Message) error {
+		received <- msg
+		return nil
+	}, WithQueueGroup(uniqueGroup()), WithAutoAck())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = sub.Unsubscribe() })
+
+	// 本地事务失败：半消息被删除，消费端收不到
+	err = mq.PublishInTransaction(ctx, topic, []byte("rollback"), func() error {
+		return errors.New("local tx failed")
+	})
+	require.Error(t, err)
+
+	// 本地事务成功：半消息在事务期间不可见，提交后消费端收到
+	err = mq.PublishInTransaction(ctx, topic, []byte("commit"), func() error {
+		n, err := redisConn.GetClient().XLen(ctx, topic).Result()
+		require.NoError(t, err)
+		require.Zero(t, n, "半消息不应出现在 Stream 中")
+		return nil
+	}, WithHeader("trace-id", "tx"))
+	require.NoError(t, err)
+
+	select {
+	case msg := <-received:
+		require.Equal(t, "commit", string(msg.Data()))
+		require.Equal(t, "tx", msg.Headers().Get("trace-id"))
+	case <-time.After(10 * time.Second):
+		t.Fatal("timeout waiting for committed message")
+	}
+	select {
+	case msg := <-received:
+		t.Fatalf("unexpected message: %s", msg.Data())
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	keys, err := redisConn.GetClient().Keys(ctx, redisHalfKeyPrefix+"*").Result()
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
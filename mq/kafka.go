@@ -0,0 +1,386 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/connector"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// kafkaConsumer 订阅使用的 Kafka 消费端能力（*kgo.Client 实现，测试中可替换）
+type kafkaConsumer interface {
+	PollRecords(ctx context.Context, maxPollRecords int) kgo.Fetches
+	CommitRecords(ctx context.Context, rs ...*kgo.Record) error
+	SetOffsets(setOffsets map[string]map[int32]kgo.EpochOffset)
+	Close()
+}
+
+// kafkaTransport Kafka 驱动实现
+//
+// 发布复用 Connector 的共享客户端；每个订阅基于共享客户端的配置单独创建消费客户端，
+// 以便各自加入 consumer group 并独立控制 offset 提交。
+type kafkaTransport struct {
+	client *kgo.Client
+	cfg    *KafkaConfig
+	logger clog.Logger
+
+	// newConsumer 创建订阅使用的消费客户端
+	newConsumer func(opts ...kgo.Opt) (kafkaConsumer, error)
+}
+
+// newKafkaTransport 创建 Kafka Driver
+func newKafkaTransport(conn connector.KafkaConnector, cfg *KafkaConfig, logger clog.Logger) *kafkaTransport {
+	client := conn.GetClient()
+	return &kafkaTransport{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+		newConsumer: func(opts ...kgo.Opt) (kafkaConsumer, error) {
+			return kgo.NewClient(append(client.Opts(), opts...)...)
+		},
+	}
+}
+
+// Publish 发布消息
+func (t *kafkaTransport) Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error {
+	rec := &kgo.Record{
+		Topic: topic,
+		Value: data,
+	}
+	for k, v := range opts.Headers {
+		rec.Headers = append(rec.Headers, kgo.RecordHeader{Key: k, Value: []byte(v)})
+	}
+	return t.client.ProduceSync(ctx, rec).FirstErr()
+}
+
+// Subscribe 订阅消息
+func (t *kafkaTransport) Subscribe(ctx context.Context, topic string, handler Handler, opts SubscribeOptions) (Subscription, error) {
+	if opts.ManualCommit && opts.QueueGroup == "" {
+		return nil, xerrors.Wrap(ErrInvalidConfig, "manual commit requires queue group")
+	}
+
+	consumer, err := t.newConsumer(t.consumerOpts(topic, opts.QueueGroup, opts.ManualCommit)...)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "create kafka consumer failed")
+	}
+
+	return t.start(ctx, consumer, func(subCtx context.Context, sub *kafkaSubscription) {
+		t.consume(subCtx, topic, consumer, opts, handler, sub)
+	}), nil
+}
+
+// SubscribeBatch 批量订阅消息
+//
+// 设置 QueueGroup 时总是关闭自动提交，整批处理成功后才批量提交 offset。
+func (t *kafkaTransport) SubscribeBatch(ctx context.Context, topic string, handler BatchHandler, batchSize int, maxWait time.Duration, opts SubscribeOptions) (Subscription, error) {
+	consumer, err := t.newConsumer(t.consumerOpts(topic, opts.QueueGroup, true)...)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "create kafka consumer failed")
+	}
+
+	return t.start(ctx, consumer, func(subCtx context.Context, sub *kafkaSubscription) {
+		t.consumeBatch(subCtx, topic, consumer, opts.QueueGroup != "", batchSize, maxWait, handler, sub)
+	}), nil
+}
+
+// consumerOpts 构造消费客户端选项
+func (t *kafkaTransport) consumerOpts(topic, group string, manualCommit bool) []kgo.Opt {
+	opts := []kgo.Opt{kgo.ConsumeTopics(topic)}
+	if isWildcardTopic(topic) {
+		// 通配符转换为正则订阅，新建的匹配 topic 会在元数据刷新后自动加入
+		opts = []kgo.Opt{kgo.ConsumeTopics(topicRegexp(topic)), kgo.ConsumeRegex()}
+	}
+	if group == "" {
+		// 广播模式没有消费进度，与其他驱动一致只读订阅之后的新消息
+		return append(opts, kgo.ConsumeResetOffset(kgo.NewOffset().AtEnd()))
+	}
+
+	opts = append(opts, kgo.ConsumerGroup(group))
+	if t.cfg.OffsetReset == KafkaOffsetEarliest {
+		opts = append(opts, kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()))
+	} else {
+		opts = append(opts, kgo.ConsumeResetOffset(kgo.NewOffset().AtEnd()))
+	}
+	if manualCommit {
+		opts = append(opts, kgo.DisableAutoCommit())
+	}
+	return opts
+}
+
+// start 启动消费 goroutine，退出时关闭消费客户端（离开 consumer group）
+func (t *kafkaTransport) start(ctx context.Context, consumer kafkaConsumer, run func(context.Context, *kafkaSubscription)) *kafkaSubscription {
+	subCtx, cancel := context.WithCancel(ctx)
+	sub := &kafkaSubscription{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	sub.active.Store(true)
+
+	go func() {
+		defer func() {
+			consumer.Close()
+			sub.once.Do(func() { close(sub.done) })
+		}()
+		run(subCtx, sub)
+	}()
+
+	return sub
+}
+
+// consume 逐条消费
+//
+// 消息被 Nak 后回退分区到该消息，并跳过本次拉取中该分区剩余的消息，
+// 保证同一分区内失败消息之后的消息不会先于它被提交。
+func (t *kafkaTransport) consume(ctx context.Context, topic string, consumer kafkaConsumer, opts SubscribeOptions, handler Handler, sub *kafkaSubscription) {
+	for {
+		fetches := consumer.PollRecords(ctx, opts.BatchSize)
+		if ctx.Err() != nil || fetches.IsClientClosed() {
+			return
+		}
+		t.checkFetchErrors(topic, fetches, sub)
+
+		fetches.EachPartition(func(p kgo.FetchTopicPartition) {
+			for _, rec := range p.Records {
+				msg := &kafkaMessage{
+					ctx:          ctx,
+					record:       rec,
+					consumer:     consumer,
+					manualCommit: opts.ManualCommit,
+				}
+				// 错误已在上层 wrapHandler 中处理
+				_ = handler(msg)
+				if msg.nacked.Load() {
+					rewindKafkaRecords(consumer, rec)
+					return
+				}
+			}
+		})
+	}
+}
+
+// consumeBatch 批量消费
+func (t *kafkaTransport) consumeBatch(ctx context.Context, topic string, consumer kafkaConsumer, commit bool, batchSize int, maxWait time.Duration, handler BatchHandler, sub *kafkaSubscription) {
+	for {
+		records := t.collectBatch(ctx, topic, consumer, batchSize, maxWait, sub)
+		// 订阅取消时未处理的批次不提交，重启后重新消费
+		if ctx.Err() != nil {
+			return
+		}
+		if len(records) == 0 {
+			continue
+		}
+
+		msgs := make([]Message, len(records))
+		for i, rec := range records {
+			msgs[i] = &kafkaMessage{ctx: ctx, record: rec, consumer: consumer, batch: true}
+		}
+
+		if err := handler(msgs); err != nil {
+			rewindKafkaRecords(consumer, records...)
+			continue
+		}
+		if commit {
+			if err := consumer.CommitRecords(context.Background(), records...); err != nil {
+				t.logger.Error("commit batch offsets failed",
+					clog.String("topic", topic),
+					clog.Int("batch_size", len(records)),
+					clog.Error(err),
+				)
+			}
+		}
+	}
+}
+
+// collectBatch 攒批：凑满 batchSize 条，或第一条消息到达后等待满 maxWait 即返回
+func (t *kafkaTransport) collectBatch(ctx context.Context, topic string, consumer kafkaConsumer, batchSize int, maxWait time.Duration, sub *kafkaSubscription) []*kgo.Record {
+	var records []*kgo.Record
+	poll := func(pollCtx context.Context) bool {
+		fetches := consumer.PollRecords(pollCtx, batchSize-len(records))
+		if ctx.Err() != nil || fetches.IsClientClosed() {
+			return false
+		}
+		t.checkFetchErrors(topic, fetches, sub)
+		fetches.EachRecord(func(rec *kgo.Record) {
+			records = append(records, rec)
+		})
+		return true
+	}
+
+	// 阻塞等待第一条消息
+	for len(records) == 0 {
+		if !poll(ctx) {
+			return records
+		}
+	}
+
+	// 从第一条消息到达起最多再等 maxWait
+	waitCtx, cancel := context.WithTimeout(ctx, maxWait)
+	defer cancel()
+	for len(records) < batchSize && waitCtx.Err() == nil {
+		if !poll(waitCtx) {
+			break
+		}
+	}
+	return records
+}
+
+// checkFetchErrors 记录拉取错误并更新订阅健康状态
+//
+// 连接类错误由 kgo 内部重试，这里只负责暴露状态，不结束订阅。
+func (t *kafkaTransport) checkFetchErrors(topic string, fetches kgo.Fetches, sub *kafkaSubscription) {
+	healthy := true
+	for _, fe := range fetches.Errors() {
+		if errors.Is(fe.Err, context.Canceled) || errors.Is(fe.Err, context.DeadlineExceeded) {
+			continue
+		}
+		healthy = false
+		t.logger.Error("kafka fetch failed",
+			clog.String("topic", topic),
+			clog.Int("partition", int(fe.Partition)),
+			clog.Error(fe.Err),
+		)
+	}
+	sub.active.Store(healthy)
+}
+
+// rewindKafkaRecords 把消息所在分区回退到其中最早的 offset，使其重新投递
+func rewindKafkaRecords(consumer kafkaConsumer, records ...*kgo.Record) {
+	offsets := make(map[string]map[int32]kgo.EpochOffset)
+	for _, rec := range records {
+		partitions, ok := offsets[rec.Topic]
+		if !ok {
+			partitions = make(map[int32]kgo.EpochOffset)
+			offsets[rec.Topic] = partitions
+		}
+		if cur, ok := partitions[rec.Partition]; ok && cur.Offset <= rec.Offset {
+			continue
+		}
+		partitions[rec.Partition] = kgo.EpochOffset{Epoch: rec.LeaderEpoch, Offset: rec.Offset}
+	}
+	consumer.SetOffsets(offsets)
+}
+
+// Capabilities 返回驱动支持的可选能力
+func (t *kafkaTransport) Capabilities() Capabilities {
+	return Capabilities{Batch: true, AsyncPublish: true}
+}
+
+// Close 关闭 Driver
+func (t *kafkaTransport) Close() error {
+	return nil
+}
+
+// ==================== Message 实现 ====================
+
+// kafkaMessage Kafka 消息实现
+type kafkaMessage struct {
+	ctx      context.Context
+	record   *kgo.Record
+	consumer kafkaConsumer
+
+	// manualCommit Ack 时同步提交 offset
+	manualCommit bool
+	// batch 批量模式下由组件统一确认
+	batch  bool
+	nacked atomic.Bool
+}
+
+func (m *kafkaMessage) Context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+func (m *kafkaMessage) Topic() string {
+	return m.record.Topic
+}
+
+func (m *kafkaMessage) Data() []byte {
+	return m.record.Value
+}
+
+func (m *kafkaMessage) Headers() Headers {
+	if len(m.record.Headers) == 0 {
+		return nil
+	}
+	h := make(Headers, len(m.record.Headers))
+	for _, rh := range m.record.Headers {
+		h[rh.Key] = string(rh.Value)
+	}
+	return h
+}
+
+func (m *kafkaMessage) Ack() error {
+	if m.batch {
+		return ErrNotSupported
+	}
+	if !m.manualCommit {
+		// 自动提交模式下 offset 由客户端周期提交
+		return nil
+	}
+	return m.consumer.CommitRecords(context.Background(), m.record)
+}
+
+func (m *kafkaMessage) Nak() error {
+	if m.batch {
+		return ErrNotSupported
+	}
+	// 实际回退在 Handler 返回后由消费循环执行，避免与拉取并发修改 offset
+	m.nacked.Store(true)
+	return nil
+}
+
+func (m *kafkaMessage) ID() string {
+	return fmt.Sprintf("%s:%d:%d", m.record.Topic, m.record.Partition, m.record.Offset)
+}
+
+// ==================== Subscription 实现 ====================
+
+// kafkaSubscription Kafka 订阅实现
+//
+// 连接异常由 kgo 客户端内部重连，订阅不会异常终止，因此 cause 始终为 nil。
+type kafkaSubscription struct {
+	fixedConcurrency
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+
+	// active 最近一次拉取是否成功，连接异常期间为 false
+	active atomic.Bool
+}
+
+func (s *kafkaSubscription) healthy() bool {
+	return s.active.Load()
+}
+
+func (s *kafkaSubscription) cause() error {
+	return nil
+}
+
+func (s *kafkaSubscription) Unsubscribe() error {
+	s.cancel()
+	return nil
+}
+
+func (s *kafkaSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *kafkaSubscription) IsActive() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return s.healthy()
+	}
+}
@@ -0,0 +1,239 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// fakeKafkaConsumer 模拟单分区 Kafka 消费客户端：SetOffsets 回退后从指定 offset 重新拉取
+type fakeKafkaConsumer struct {
+	topic  string
+	notify chan struct{}
+
+	mu        sync.Mutex
+	log       []*kgo.Record
+	next      int
+	committed []int64
+	rewinds   []int64
+	closed    bool
+}
+
+func newFakeKafkaConsumer(topic string) *fakeKafkaConsumer {
+	return &fakeKafkaConsumer{topic: topic, notify: make(chan struct{}, 1)}
+}
+
+// produce 追加消息到分区末尾
+func (c *fakeKafkaConsumer) produce(values ...string) {
+	c.mu.Lock()
+	for _, v := range values {
+		c.log = append(c.log, &kgo.Record{Topic: c.topic, Value: []byte(v), Offset: int64(len(c.log))})
+	}
+	c.mu.Unlock()
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (c *fakeKafkaConsumer) PollRecords(ctx context.Context, maxPollRecords int) kgo.Fetches {
+	for {
+		c.mu.Lock()
+		if c.next < len(c.log) {
+			end := min(c.next+maxPollRecords, len(c.log))
+			records := c.log[c.next:end]
+			c.next = end
+			c.mu.Unlock()
+			return kgo.Fetches{{Topics: []kgo.FetchTopic{{
+				Topic:      c.topic,
+				Partitions: []kgo.FetchPartition{{Records: records}},
+			}}}}
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return kgo.NewErrFetch(ctx.Err())
+		case <-c.notify:
+		}
+	}
+}
+
+func (c *fakeKafkaConsumer) CommitRecords(ctx context.Context, rs ...*kgo.Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, r := range rs {
+		c.committed = append(c.committed, r.Offset)
+	}
+	return nil
+}
+
+func (c *fakeKafkaConsumer) SetOffsets(setOffsets map[string]map[int32]kgo.EpochOffset) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	offset := setOffsets[c.topic][0].Offset
+	c.rewinds = append(c.rewinds, offset)
+	c.next = int(offset)
+}
+
+func (c *fakeKafkaConsumer) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+}
+
+func (c *fakeKafkaConsumer) snapshot() (committed, rewinds []int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]int64(nil), c.committed...), append([]int64(nil), c.rewinds...)
+}
+
+func newFakeKafkaMQ(consumer *fakeKafkaConsumer) MQ {
+	transport := &kafkaTransport{
+		cfg:    &KafkaConfig{OffsetReset: KafkaOffsetLatest},
+		logger: clog.Discard(),
+		newConsumer: func(opts ...kgo.Opt) (kafkaConsumer, error) {
+			return consumer, nil
+		},
+	}
+	return &mq{transport: transport, logger: clog.Discard(), meter: metrics.Discard(), driver: string(DriverKafka)}
+}
+
+func TestKafka_ManualCommit(t *testing.T) {
+	t.Run("Handler 失败不提交并从失败消息重投", func(t *testing.T) {
+		consumer := newFakeKafkaConsumer("orders")
+		mq := newFakeKafkaMQ(consumer)
+
+		var (
+			mu        sync.Mutex
+			processed []string
+			failed    bool
+		)
+		done := make(chan struct{})
+		sub, err := mq.Subscribe(context.Background(), "orders", func(msg Message) error {
+			mu.Lock()
+			defer mu.Unlock()
+			processed = append(processed, string(msg.Data()))
+			if string(msg.Data()) == "b" && !failed {
+				failed = true
+				return errors.New("transient")
+			}
+			if string(msg.Data()) == "c" {
+				close(done)
+			}
+			return nil
+		}, WithQueueGroup("g"), WithManualCommit(), WithAutoAck())
+		require.NoError(t, err)
+
+		consumer.produce("a", "b", "c")
+		waitTimeout(t, done, time.Second)
+		require.NoError(t, sub.Unsubscribe())
+		<-sub.Done()
+
+		mu.Lock()
+		require.Equal(t, []string{"a", "b", "b", "c"}, processed)
+		mu.Unlock()
+		committed, rewinds := consumer.snapshot()
+		require.Equal(t, []int64{0, 1, 2}, committed)
+		require.Equal(t, []int64{1}, rewinds)
+		require.True(t, consumer.closed)
+	})
+
+	t.Run("未设置消费组时拒绝", func(t *testing.T) {
+		mq := newFakeKafkaMQ(newFakeKafkaConsumer("orders"))
+
+		_, err := mq.Subscribe(context.Background(), "orders", func(msg Message) error { return nil }, WithManualCommit())
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+}
+
+func TestKafka_SubscribeBatch(t *testing.T) {
+	t.Run("按 batchSize 聚合多次拉取", func(t *testing.T) {
+		consumer := newFakeKafkaConsumer("events")
+		mq := newFakeKafkaMQ(consumer)
+
+		batches := make(chan []string, 10)
+		sub, err := mq.SubscribeBatch(context.Background(), "events", func(msgs []Message) error {
+			values := make([]string, len(msgs))
+			for i, msg := range msgs {
+				values[i] = string(msg.Data())
+			}
+			batches <- values
+			return nil
+		}, 3, time.Second, WithQueueGroup("g"))
+		require.NoError(t, err)
+		defer func() { _ = sub.Unsubscribe() }()
+
+		consumer.produce("1")
+		time.Sleep(20 * time.Millisecond)
+		consumer.produce("2", "3", "4")
+
+		require.Equal(t, []string{"1", "2", "3"}, <-batches)
+		var committed []int64
+		require.Eventually(t, func() bool {
+			committed, _ = consumer.snapshot()
+			return len(committed) == 3
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, []int64{0, 1, 2}, committed)
+
+		// 不足 batchSize 时等满 maxWait 交付
+		select {
+		case batch := <-batches:
+			require.Equal(t, []string{"4"}, batch)
+		case <-time.After(2 * time.Second):
+			t.Fatal("timeout waiting for partial batch")
+		}
+	})
+
+	t.Run("批处理失败整批不提交并回退重投", func(t *testing.T) {
+		consumer := newFakeKafkaConsumer("events")
+		mq := newFakeKafkaMQ(consumer)
+
+		var attempts int
+		done := make(chan struct{})
+		sub, err := mq.SubscribeBatch(context.Background(), "events", func(msgs []Message) error {
+			attempts++
+			require.ErrorIs(t, msgs[0].Ack(), ErrNotSupported)
+			if attempts == 1 {
+				return errors.New("batch failed")
+			}
+			close(done)
+			return nil
+		}, 2, time.Second, WithQueueGroup("g"))
+		require.NoError(t, err)
+
+		consumer.produce("x", "y")
+		waitTimeout(t, done, time.Second)
+		require.NoError(t, sub.Unsubscribe())
+		<-sub.Done()
+
+		committed, rewinds := consumer.snapshot()
+		require.Equal(t, []int64{0}, rewinds)
+		require.Equal(t, []int64{0, 1}, committed)
+	})
+}
+
+func TestKafkaMessage(t *testing.T) {
+	msg := &kafkaMessage{record: &kgo.Record{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    42,
+		Value:     []byte("payload"),
+		Headers:   []kgo.RecordHeader{{Key: "trace-id", Value: []byte("abc")}},
+	}}
+
+	require.Equal(t, "orders:2:42", msg.ID())
+	require.Equal(t, "orders", msg.Topic())
+	require.Equal(t, []byte("payload"), msg.Data())
+	require.Equal(t, Headers{"trace-id": "abc"}, msg.Headers())
+	require.NotNil(t, msg.Context())
+	require.NoError(t, msg.Ack()) // 自动提交模式下 Ack 无操作
+}
@@ -8,7 +8,7 @@ import (
 // Headers 消息元数据（键值对）
 //
 // 用于传递 trace、业务标签等元信息。
-// 注意：MQ 不做自动注入/提取，业务自行处理。
+// 注入 WithTracer 后，MQ 会自动写入和提取 trace 头；其余元信息由业务自行处理。
 type Headers map[string]string
 
 // Clone 返回 Headers 的深拷贝
@@ -46,7 +46,7 @@ type Message interface {
 	// 该上下文继承自 Subscribe 调用时的 ctx，可用于：
 	//   - 超时控制
 	//   - 取消传播
-	//   - 传递 trace 信息（需业务自行注入）
+	//   - 传递 trace 信息（注入 WithTracer 时已带 consumer span）
 	Context() context.Context
 
 	// Topic 获取消息主题
@@ -63,6 +63,7 @@ type Message interface {
 	// 不同后端行为：
 	//   - NATS JetStream: 发送 Ack 到服务端，消息从 pending 移除
 	//   - Redis Stream: Consumer Group 模式下执行 XACK；广播模式下无操作
+	//   - Kafka: WithManualCommit 下同步提交该消息的 offset；自动提交模式下无操作
 	Ack() error
 
 	// Nak 拒绝消息，请求重投
@@ -71,6 +72,7 @@ type Message interface {
 	//   - NATS JetStream: 触发消息立即重投
 	//   - Redis Stream: 返回 ErrNotSupported；消息留在 Pending 列表，
 	//     由 XAUTOCLAIM 在 PendingIdle 超时后重新认领
+	//   - Kafka: 不提交 offset，并把分区回退到该消息，从该消息起重新投递
 	//
 	// 调用方应通过 errors.Is(err, ErrNotSupported) 区分"不支持"与真实错误。
 	// AutoAck 模式下 ErrNotSupported 会被自动忽略。
@@ -81,6 +83,7 @@ type Message interface {
 	// 不同后端返回值：
 	//   - NATS JetStream: "<stream>:<sequence>"（如 "S-orders:42"）
 	//   - Redis Stream: 消息 ID（如 "1700000000000-0"）
+	//   - Kafka: "<topic>:<partition>:<offset>"（如 "orders:0:42"）
 	ID() string
 }
 
@@ -98,6 +101,15 @@ type Message interface {
 // 避免消息无限重投。
 type Handler func(msg Message) error
 
+// BatchHandler 批量消息处理函数
+//
+// 返回值：
+//   - nil: 整批处理成功，组件统一确认（Kafka 批量提交 offset）
+//   - error: 整批处理失败，组件不确认并回退到批内最早的消息重新投递
+//
+// 失败重投以整批为单位，handler 需要保证幂等。
+type BatchHandler func(msgs []Message) error
+
 // Subscription 订阅句柄
 //
 // 用于管理订阅的生命周期。
@@ -114,4 +126,15 @@ type Subscription interface {
 	//   <-sub.Done()
 	//   sub.Unsubscribe()
 	Done() <-chan struct{}
+
+	// IsActive 报告订阅当前是否在正常消费
+	//
+	// 底层连接断开、订阅正在自动重建或订阅已结束时返回 false。
+	IsActive() bool
+
+	// SetConcurrency 运行中调整并发处理消息的 worker 数
+	//
+	// 增加时立即启动新 worker；减少时多余 worker 处理完手头消息后退出，不丢消息。
+	// 仅对通过 WithConcurrency 创建的订阅有效，否则返回 ErrNotSupported；n<=0 返回 ErrInvalidConfig。
+	SetConcurrency(n int) error
 }
@@ -223,6 +223,7 @@ func WithLogging(logger clog.Logger) Middleware {
 // WithRecover 创建 panic 恢复中间件
 //
 // 捕获 Handler 中的 panic，转换为错误返回，避免整个消费者崩溃。
+// panic 值与触发位置的堆栈通过 clog.PanicValue 结构化记录。
 func WithRecover(logger clog.Logger) Middleware {
 	return func(next Handler) Handler {
 		return func(msg Message) (err error) {
@@ -231,7 +232,7 @@ func WithRecover(logger clog.Logger) Middleware {
 					logger.Error("message handler panic recovered",
 						clog.String("topic", msg.Topic()),
 						clog.String("msg_id", msg.ID()),
-						clog.Any("panic", r),
+						clog.PanicValue(r),
 					)
 					err = ErrPanicRecovered
 				}
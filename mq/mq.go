@@ -1,28 +1,39 @@
-// Package mq 提供消息队列组件，支持 NATS JetStream 和 Redis Stream 两种后端。
+// Package mq 提供消息队列组件，支持 NATS JetStream、Redis Stream 和 Kafka 三种后端。
 //
 // MQ 组件是 Genesis L2 业务层组件，提供统一的发布-订阅接入方式，但不伪装成
-// 两个驱动完全一致的语义。
+// 各驱动完全一致的语义。
 // 设计原则：
 //   - 简单优于复杂：核心接口精简，通过 Option 扩展能力
 //   - 显式优于隐式：不做自动注入，用户完全掌控消息流
-//   - 语义明确：两个驱动都提供持久化和 At-least-once 投递，但 Ack/Nak、
+//   - 语义明确：各驱动都提供持久化和 At-least-once 投递，但 Ack/Nak、
 //     QueueGroup、Durable、BatchSize 等细节保留各自差异
+//   - 易于扩展：核心逻辑只依赖 Driver 接口，新后端实现 Driver 后通过 WithDriver 注入
+//
+// 注入 WithTracer 后，发布时自动创建 producer span 并把 trace 上下文写入消息头，
+// 消费时自动提取并创建 consumer span，Handler 通过 msg.Context() 获得串联好的链路。
+//
+// WithPublishRateLimit 与 WithMaxPendingPublish 为发布端提供限流与背压，避免突发流量打垮 broker。
 package mq
 
 import (
 	"context"
+	"time"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
 
 	"github.com/ceyewan/genesis/clog"
 	"github.com/ceyewan/genesis/connector"
 	"github.com/ceyewan/genesis/metrics"
+	"github.com/ceyewan/genesis/trace"
 	"github.com/ceyewan/genesis/xerrors"
 )
 
 // MQ 消息队列核心接口
 //
 // 提供统一的发布订阅入口，并保留底层驱动的语义差异。
-// 当前支持的后端：NATS JetStream、Redis Stream。
-// 两者均提供持久化和 At-least-once 投递，但 Nak 语义不同，详见 Message.Nak()。
+// 当前支持的后端：NATS JetStream、Redis Stream、Kafka。
+// 三者均提供持久化和 At-least-once 投递，但 Nak 语义不同，详见 Message.Nak()。
 type MQ interface {
 	// Publish 发布消息到指定主题
 	//
@@ -33,6 +44,33 @@ type MQ interface {
 	//   - opts: 发布选项（Headers 等）
 	Publish(ctx context.Context, topic string, data []byte, opts ...PublishOption) error
 
+	// PublishAsync 异步发布消息，立即返回
+	//
+	// broker 确认或发布失败后调用 callback（恰好一次，可能在其他 goroutine 中执行），
+	// err 为 nil 表示消息已落地。ctx 结束时尚未确认的消息以 ctx.Err() 回调，但消息仍可能已被 broker 接收。
+	//   - NATS JetStream：使用原生异步发布，收到 PubAck 后回调
+	//   - Kafka：使用生产者异步发送，按客户端的 acks 配置确认后回调
+	//   - Redis Stream：无原生异步确认，同步 XADD 后立即回调
+	//
+	// callback 可为 nil；MQ 已关闭时立即以 ErrClosed 回调。
+	// 配置 WithPublishRateLimit / WithMaxPendingPublish 后，超限时默认阻塞到放行或 ctx 结束，不再立即返回。
+	PublishAsync(ctx context.Context, topic string, data []byte, callback PublishCallback, opts ...PublishOption)
+
+	// Flush 等待所有已发起的 PublishAsync 完成回调
+	//
+	// ctx 结束时返回 ctx.Err()。Close 不会等待未完成的异步发布，需要时应先调用 Flush。
+	Flush(ctx context.Context) error
+
+	// PublishInTransaction 发布事务消息（本地事务 + 消息二阶段提交）
+	//
+	// 先写入对消费者不可见的半消息，再执行 localTx：localTx 返回 nil 时提交半消息使其可见，
+	// 返回 error 或 panic 时删除半消息，并原样返回 localTx 的错误。
+	//   - Redis Stream：半消息暂存在独立 Hash 中，提交时由 Lua 脚本原子地移入 Stream
+	//   - NATS JetStream、Kafka：无原生半消息，需通过 WithTransactionOutbox 降级为 outbox，否则返回 ErrNotSupported
+	//
+	// localTx 提交成功但半消息提交失败时返回错误，此时本地事务不会回滚，调用方需自行补偿。
+	PublishInTransaction(ctx context.Context, topic string, data []byte, localTx func() error, opts ...PublishOption) error
+
 	// Subscribe 订阅主题并处理消息
 	//
 	// Handler 签名：func(msg Message) error
@@ -40,11 +78,30 @@ type MQ interface {
 	//
 	// 参数：
 	//   - ctx: 订阅生命周期上下文，取消时自动停止订阅
-	//   - topic: 订阅主题
+	//   - topic: 订阅主题，支持通配符："*" 匹配一段，">" 匹配剩余一段或多段（如 orders.*）
 	//   - handler: 消息处理函数
 	//   - opts: 订阅选项（QueueGroup、AutoAck 等）
+	//
+	// 通配符订阅时 msg.Topic() 返回实际匹配到的主题。
 	Subscribe(ctx context.Context, topic string, handler Handler, opts ...SubscribeOption) (Subscription, error)
 
+	// SubscribeMany 以同一个 handler 订阅多个主题
+	//
+	// 每个主题独立订阅（可含通配符），返回的 Subscription 统一管理全部订阅：
+	// Unsubscribe 取消全部，全部结束后 Done 关闭，全部活跃时 IsActive 为 true。
+	// 任一主题订阅失败时已建立的订阅会被取消并返回错误。
+	SubscribeMany(ctx context.Context, topics []string, handler Handler, opts ...SubscribeOption) (Subscription, error)
+
+	// SubscribeBatch 批量订阅主题
+	//
+	// 攒够 batchSize 条消息，或自第一条消息到达起等待满 maxWait 后，
+	// 把整批消息一次交给 handler。handler 返回 nil 时整批确认（Kafka 为批量提交 offset），
+	// 返回 error 时整批不确认并回退重投。批量模式下由组件统一确认，
+	// 批内消息的 Ack/Nak 返回 ErrNotSupported；WithAutoAck、WithAckTimeout、WithHandlerTimeout、WithSchema 不生效。
+	//
+	// 当前仅 Kafka 驱动支持，其他驱动返回 ErrNotSupported。
+	SubscribeBatch(ctx context.Context, topic string, handler BatchHandler, batchSize int, maxWait time.Duration, opts ...SubscribeOption) (Subscription, error)
+
 	// Close 关闭 MQ 客户端
 	// 注意：底层连接由 Connector 管理，此方法仅释放 MQ 内部资源
 	Close() error
@@ -52,10 +109,11 @@ type MQ interface {
 
 // New 创建 MQ 实例
 //
-// 根据 Config.Driver 选择底层 Transport 实现。
-// 必需依赖通过 Option 注入：
+// 通过 WithDriver 注入自定义驱动时直接使用该驱动，Config.Driver 仅作为驱动名；
+// 否则根据 Config.Driver 选择内置驱动，必需依赖通过 Option 注入：
 //   - NATS JetStream: WithNATSConnector
 //   - Redis Stream: WithRedisConnector
+//   - Kafka: WithKafkaConnector
 //
 // 示例：
 //
@@ -68,28 +126,46 @@ func New(cfg *Config, opts ...Option) (MQ, error) {
 	}
 
 	cfg.setDefaults()
-	if err := cfg.validate(); err != nil {
-		return nil, err
-	}
-
 	o := applyOptions(opts...)
 
-	// 创建 Transport
-	transport, err := newTransport(cfg, o)
+	driver, name := o.driver, string(cfg.Driver)
+	if driver == nil {
+		if err := cfg.validate(); err != nil {
+			return nil, err
+		}
+		var err error
+		if driver, err = newTransport(cfg, o); err != nil {
+			return nil, err
+		}
+	} else if name == "" {
+		name = customDriverName
+	}
+
+	throttle, err := newPublishThrottle(o)
 	if err != nil {
 		return nil, err
 	}
 
-	return &mq{
-		transport: transport,
-		logger:    o.logger,
-		meter:     o.meter,
-		driver:    cfg.Driver,
-	}, nil
+	m := &mq{
+		throttle:      throttle,
+		transport:     driver,
+		logger:        o.logger,
+		meter:         o.meter,
+		traceRelation: o.traceRelation,
+		driver:        name,
+		txOutbox:      o.txOutbox,
+	}
+	if o.tracer != nil {
+		m.tracer = o.tracer.Tracer(tracerName)
+	}
+	return m, nil
 }
 
-// newTransport 根据配置创建对应的 Transport 实现
-func newTransport(cfg *Config, o *options) (Transport, error) {
+// customDriverName 自定义驱动未在 Config.Driver 中命名时使用的驱动名
+const customDriverName = "custom"
+
+// newTransport 根据配置创建对应的内置 Driver 实现
+func newTransport(cfg *Config, o *options) (Driver, error) {
 	switch cfg.Driver {
 	case DriverNATSJetStream:
 		if o.natsConnector == nil {
@@ -103,6 +179,12 @@ func newTransport(cfg *Config, o *options) (Transport, error) {
 		}
 		return newRedisStreamTransport(o.redisConnector, cfg.RedisStream, o.logger), nil
 
+	case DriverKafka:
+		if o.kafkaConnector == nil {
+			return nil, xerrors.New("Kafka connector required, use WithKafkaConnector")
+		}
+		return newKafkaTransport(o.kafkaConnector, cfg.Kafka, o.logger), nil
+
 	default:
 		return nil, xerrors.Wrapf(ErrInvalidConfig, "unsupported driver: %s", cfg.Driver)
 	}
@@ -125,6 +207,10 @@ func applyOptions(opts ...Option) *options {
 		o.meter = metrics.Discard()
 	}
 
+	if o.traceRelation == "" {
+		o.traceRelation = trace.MessagingTraceRelationChildOf
+	}
+
 	return o
 }
 
@@ -136,6 +222,15 @@ type options struct {
 	meter          metrics.Meter
 	natsConnector  connector.NATSConnector
 	redisConnector connector.RedisConnector
+	kafkaConnector connector.KafkaConnector
+	txOutbox       *gorm.DB
+	driver         Driver
+	tracer         oteltrace.TracerProvider
+	traceRelation  trace.MessagingTraceRelation
+
+	publishLimit      *publishLimit
+	maxPendingPublish int
+	publishReject     bool
 }
 
 // WithLogger 注入日志记录器
@@ -156,6 +251,29 @@ func WithMeter(m metrics.Meter) Option {
 	}
 }
 
+// WithTracer 注入 TracerProvider，自动串联发布与消费链路
+//
+// 注入后 Publish / PublishAsync / PublishInTransaction 会创建 producer span 并把 trace 上下文写入消息头；
+// 消费时从消息头提取上游并创建 consumer span，Handler 通过 msg.Context() 即可拿到该 span，无需手动处理 header。
+// 消息头的注入与提取使用全局 TextMapPropagator（由 trace.Init / trace.Discard 安装）。
+func WithTracer(tp oteltrace.TracerProvider) Option {
+	return func(o *options) {
+		if tp != nil {
+			o.tracer = tp
+		}
+	}
+}
+
+// WithTraceRelation 设置 consumer span 与 producer span 的关系
+//
+// 默认 trace.MessagingTraceRelationChildOf，发布与消费串成同一条 trace；
+// 广播、批处理等一条消息触发多路消费的场景可改为 trace.MessagingTraceRelationLink，消费端各自开启新 trace 并以 link 关联上游。
+func WithTraceRelation(relation trace.MessagingTraceRelation) Option {
+	return func(o *options) {
+		o.traceRelation = relation
+	}
+}
+
 // WithNATSConnector 注入 NATS 连接器（用于 NATS Core / JetStream）
 func WithNATSConnector(conn connector.NATSConnector) Option {
 	return func(o *options) {
@@ -169,3 +287,48 @@ func WithRedisConnector(conn connector.RedisConnector) Option {
 		o.redisConnector = conn
 	}
 }
+
+// WithDriver 注入自定义驱动
+//
+// 用于接入内置驱动之外的后端（如 RabbitMQ），设置后忽略 Config.Driver 对应的内置驱动及连接器。
+// MQ.Close 会调用 driver.Close。
+func WithDriver(driver Driver) Option {
+	return func(o *options) {
+		o.driver = driver
+	}
+}
+
+// WithPublishRateLimit 限制 Publish / PublishAsync 的发布速率，保护 broker 免受突发流量冲击
+//
+// 使用进程内令牌桶：每秒 rate 条，允许 burst 条突发，所有主题共享同一个桶。
+// 默认超过速率时阻塞到拿到令牌或 ctx 结束，配合 WithPublishRejectWhenThrottled 改为立即返回 ErrPublishThrottled。
+// rate、burst 必须为正数，否则 New 返回 ErrInvalidConfig。
+func WithPublishRateLimit(rate float64, burst int) Option {
+	return func(o *options) {
+		o.publishLimit = &publishLimit{rate: rate, burst: burst}
+	}
+}
+
+// WithMaxPendingPublish 限制未完成回调的 PublishAsync 数量，实现背压
+//
+// 达到上限时 PublishAsync 阻塞到有消息确认或 ctx 结束（此时以 ctx 错误回调），
+// 配合 WithPublishRejectWhenThrottled 改为立即以 ErrPublishThrottled 回调。n <= 0 表示不限制。
+func WithMaxPendingPublish(n int) Option {
+	return func(o *options) {
+		o.maxPendingPublish = n
+	}
+}
+
+// WithPublishRejectWhenThrottled 发布超过速率或 pending 达到上限时立即返回 ErrPublishThrottled，而不是阻塞等待
+func WithPublishRejectWhenThrottled() Option {
+	return func(o *options) {
+		o.publishReject = true
+	}
+}
+
+// WithKafkaConnector 注入 Kafka 连接器（用于 Kafka）
+func WithKafkaConnector(conn connector.KafkaConnector) Option {
+	return func(o *options) {
+		o.kafkaConnector = conn
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/connector"
 	"github.com/ceyewan/genesis/metrics"
 )
 
@@ -31,6 +32,7 @@ func TestConfig(t *testing.T) {
 		require.Equal(t, "S-", cfg.JetStream.StreamPrefix)
 		require.Equal(t, 30*time.Second, cfg.JetStream.AckWait)
 		require.Equal(t, 30*time.Second, cfg.RedisStream.PendingIdle)
+		require.Equal(t, KafkaOffsetLatest, cfg.Kafka.OffsetReset)
 	})
 
 	t.Run("validate 验证配置 - 成功", func(t *testing.T) {
@@ -46,6 +48,10 @@ func TestConfig(t *testing.T) {
 				name: "Redis Stream",
 				cfg:  &Config{Driver: DriverRedisStream},
 			},
+			{
+				name: "Kafka",
+				cfg:  &Config{Driver: DriverKafka, Kafka: &KafkaConfig{OffsetReset: KafkaOffsetEarliest}},
+			},
 		}
 
 		for _, tt := range tests {
@@ -64,10 +70,16 @@ func TestConfig(t *testing.T) {
 		})
 
 		t.Run("不支持的驱动", func(t *testing.T) {
-			cfg := &Config{Driver: Driver("unknown")}
+			cfg := &Config{Driver: DriverType("unknown")}
 			err := cfg.validate()
 			require.Error(t, err)
 		})
+
+		t.Run("Kafka 起始位置非法", func(t *testing.T) {
+			cfg := &Config{Driver: DriverKafka, Kafka: &KafkaConfig{OffsetReset: "middle"}}
+			err := cfg.validate()
+			require.ErrorIs(t, err, ErrInvalidConfig)
+		})
 	})
 }
 
@@ -78,11 +90,12 @@ func TestConfig(t *testing.T) {
 func TestDriverConstants(t *testing.T) {
 	tests := []struct {
 		name   string
-		driver Driver
+		driver DriverType
 		want   string
 	}{
 		{"NATS JetStream", DriverNATSJetStream, "nats_jetstream"},
 		{"Redis Stream", DriverRedisStream, "redis_stream"},
+		{"Kafka", DriverKafka, "kafka"},
 	}
 
 	for _, tt := range tests {
@@ -104,7 +117,7 @@ func TestNew(t *testing.T) {
 	})
 
 	t.Run("驱动不支持", func(t *testing.T) {
-		mq, err := New(&Config{Driver: Driver("unknown")})
+		mq, err := New(&Config{Driver: DriverType("unknown")})
 		require.Error(t, err)
 		require.Nil(t, mq)
 	})
@@ -121,6 +134,12 @@ func TestNew(t *testing.T) {
 		require.Nil(t, mq)
 	})
 
+	t.Run("缺少 Kafka 连接器", func(t *testing.T) {
+		mq, err := New(&Config{Driver: DriverKafka})
+		require.Error(t, err)
+		require.Nil(t, mq)
+	})
+
 	t.Run("成功创建 NATS JetStream", func(t *testing.T) {
 		mq, err := New(
 			&Config{Driver: DriverNATSJetStream},
@@ -403,6 +422,36 @@ func TestMQ_Close(t *testing.T) {
 	})
 }
 
+// ============================================================
+// SubscribeBatch 测试
+// ============================================================
+
+func TestMQ_SubscribeBatch(t *testing.T) {
+	t.Run("驱动不支持时返回 ErrNotSupported", func(t *testing.T) {
+		mq := newMQ(&mockTransport{}, clog.Discard(), metrics.Discard())
+
+		_, err := mq.SubscribeBatch(context.Background(), "topic", func(msgs []Message) error { return nil }, 10, time.Second)
+		require.ErrorIs(t, err, ErrNotSupported)
+	})
+
+	t.Run("批量参数非法", func(t *testing.T) {
+		mq := newMQ(&mockTransport{}, clog.Discard(), metrics.Discard())
+
+		_, err := mq.SubscribeBatch(context.Background(), "topic", func(msgs []Message) error { return nil }, 0, time.Second)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+		_, err = mq.SubscribeBatch(context.Background(), "topic", func(msgs []Message) error { return nil }, 10, 0)
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	t.Run("关闭后返回 ErrClosed", func(t *testing.T) {
+		mq := newMQ(&mockTransport{}, clog.Discard(), metrics.Discard())
+
+		require.NoError(t, mq.Close())
+		_, err := mq.SubscribeBatch(context.Background(), "topic", func(msgs []Message) error { return nil }, 10, time.Second)
+		require.ErrorIs(t, err, ErrClosed)
+	})
+}
+
 // ============================================================
 // AutoAck 行为测试
 // ============================================================
@@ -410,10 +459,10 @@ func TestMQ_Close(t *testing.T) {
 func TestMQ_AutoAckBehavior(t *testing.T) {
 	t.Run("AutoAck 模式 Handler 成功时自动 Ack", func(t *testing.T) {
 		testMsg := &mockMessage{}
-		m := &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: DriverNATSJetStream}
+		m := &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: string(DriverNATSJetStream)}
 		wrapped := m.wrapHandler("test.topic", func(msg Message) error {
 			return nil
-		}, subscribeOptions{AutoAck: true})
+		}, SubscribeOptions{AutoAck: true})
 
 		err := wrapped(testMsg)
 		require.NoError(t, err)
@@ -423,10 +472,10 @@ func TestMQ_AutoAckBehavior(t *testing.T) {
 
 	t.Run("AutoAck 模式 Handler 失败时自动 Nak", func(t *testing.T) {
 		testMsg := &mockMessage{}
-		m := &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: DriverNATSJetStream}
+		m := &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: string(DriverNATSJetStream)}
 		wrapped := m.wrapHandler("test.topic", func(msg Message) error {
 			return errors.New("handler failed")
-		}, subscribeOptions{AutoAck: true})
+		}, SubscribeOptions{AutoAck: true})
 
 		err := wrapped(testMsg)
 		require.Error(t, err)
@@ -436,10 +485,10 @@ func TestMQ_AutoAckBehavior(t *testing.T) {
 
 	t.Run("ManualAck 模式不自动调用 Ack/Nak", func(t *testing.T) {
 		testMsg := &mockMessage{}
-		m := &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: DriverNATSJetStream}
+		m := &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: string(DriverNATSJetStream)}
 		wrapped := m.wrapHandler("test.topic", func(msg Message) error {
 			return nil
-		}, subscribeOptions{AutoAck: false})
+		}, SubscribeOptions{AutoAck: false})
 
 		err := wrapped(testMsg)
 		require.NoError(t, err)
@@ -450,10 +499,10 @@ func TestMQ_AutoAckBehavior(t *testing.T) {
 	t.Run("AutoAck 模式 Nak 返回 ErrNotSupported 时不应记录错误", func(t *testing.T) {
 		// 模拟 Redis 消息，Nak 返回 ErrNotSupported
 		testMsg := &mockMessageNakNotSupported{}
-		m := &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: DriverRedisStream}
+		m := &mq{logger: clog.Discard(), meter: metrics.Discard(), driver: string(DriverRedisStream)}
 		wrapped := m.wrapHandler("test.topic", func(msg Message) error {
 			return errors.New("handler failed")
-		}, subscribeOptions{AutoAck: true})
+		}, SubscribeOptions{AutoAck: true})
 
 		// 不应 panic，ErrNotSupported 应被静默忽略
 		err := wrapped(testMsg)
@@ -522,6 +571,7 @@ func TestDefaultOptions(t *testing.T) {
 		require.Empty(t, opts.QueueGroup)
 		require.Empty(t, opts.DurableName)
 		require.Equal(t, 0, opts.MaxInflight)
+		require.False(t, opts.ManualCommit)
 	})
 }
 
@@ -546,7 +596,7 @@ func TestLabelConstants(t *testing.T) {
 // Mock 实现（用于测试）
 // ============================================================
 
-// mockTransport 是 Transport 的 mock 实现
+// mockTransport 是 Driver 的 mock 实现
 type mockTransport struct {
 	publishCalled     bool
 	subscribeCalled   bool
@@ -556,12 +606,12 @@ type mockTransport struct {
 	closeError        error
 	lastTopic         string
 	lastData          []byte
-	lastPublishOpts   publishOptions
-	lastSubscribeOpts subscribeOptions
+	lastPublishOpts   PublishOptions
+	lastSubscribeOpts SubscribeOptions
 	handler           Handler
 }
 
-func (m *mockTransport) Publish(ctx context.Context, topic string, data []byte, opts publishOptions) error {
+func (m *mockTransport) Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error {
 	m.publishCalled = true
 	m.lastTopic = topic
 	m.lastData = data
@@ -569,7 +619,7 @@ func (m *mockTransport) Publish(ctx context.Context, topic string, data []byte,
 	return m.publishError
 }
 
-func (m *mockTransport) Subscribe(subscribeCtx context.Context, topic string, handler Handler, opts subscribeOptions) (Subscription, error) {
+func (m *mockTransport) Subscribe(subscribeCtx context.Context, topic string, handler Handler, opts SubscribeOptions) (Subscription, error) {
 	m.subscribeCalled = true
 	m.handler = handler
 	m.lastSubscribeOpts = opts
@@ -579,6 +629,10 @@ func (m *mockTransport) Subscribe(subscribeCtx context.Context, topic string, ha
 	return &mockSubscription{}, nil
 }
 
+func (m *mockTransport) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
 func (m *mockTransport) Close() error {
 	m.closeCalled = true
 	return m.closeError
@@ -586,6 +640,8 @@ func (m *mockTransport) Close() error {
 
 // mockSubscription 是 Subscription 的 mock 实现
 type mockSubscription struct {
+	fixedConcurrency
+
 	unsubscribed bool
 }
 
@@ -600,6 +656,10 @@ func (m *mockSubscription) Done() <-chan struct{} {
 	return ch
 }
 
+func (m *mockSubscription) IsActive() bool {
+	return false
+}
+
 // mockMessage 是 Message 的 mock 实现
 type mockMessage struct {
 	ackCalled bool
@@ -659,6 +719,10 @@ func (m *mockNATSConnector) Name() string {
 	return "mock-nats"
 }
 
+func (m *mockNATSConnector) Stats() connector.ConnStats {
+	return connector.ConnStats{Name: "mock-nats"}
+}
+
 func (m *mockNATSConnector) GetClient() *nats.Conn {
 	return &nats.Conn{}
 }
@@ -686,6 +750,10 @@ func (m *mockRedisConnector) Name() string {
 	return "mock-redis"
 }
 
+func (m *mockRedisConnector) Stats() connector.ConnStats {
+	return connector.ConnStats{Name: "mock-redis"}
+}
+
 func (m *mockRedisConnector) GetClient() *redis.Client {
 	return &redis.Client{}
 }
@@ -704,11 +772,11 @@ func (m *mockMessageNakNotSupported) Nak() error {
 }
 
 // newMQ 创建一个用于测试的 MQ 实例
-func newMQ(transport Transport, logger clog.Logger, meter metrics.Meter) MQ {
+func newMQ(transport Driver, logger clog.Logger, meter metrics.Meter) MQ {
 	return &mq{
 		transport: transport,
 		logger:    logger,
 		meter:     meter,
-		driver:    DriverNATSJetStream,
+		driver:    string(DriverNATSJetStream),
 	}
 }
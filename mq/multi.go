@@ -0,0 +1,60 @@
+package mq
+
+import "github.com/ceyewan/genesis/xerrors"
+
+// multiSubscription 将多个订阅组合为一个 Subscription，供 SubscribeMany 使用
+type multiSubscription struct {
+	subs []Subscription
+	done chan struct{}
+}
+
+func newMultiSubscription(subs []Subscription) *multiSubscription {
+	s := &multiSubscription{
+		subs: subs,
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(s.done)
+		for _, sub := range subs {
+			<-sub.Done()
+		}
+	}()
+	return s
+}
+
+// Unsubscribe 取消全部订阅，返回合并后的错误
+func (s *multiSubscription) Unsubscribe() error {
+	var errs []error
+	for _, sub := range s.subs {
+		if err := sub.Unsubscribe(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return xerrors.Combine(errs...)
+}
+
+// Done 全部订阅结束后关闭
+func (s *multiSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// IsActive 全部订阅都在正常消费时返回 true
+func (s *multiSubscription) IsActive() bool {
+	for _, sub := range s.subs {
+		if !sub.IsActive() {
+			return false
+		}
+	}
+	return true
+}
+
+// SetConcurrency 调整每个订阅的 worker 数，返回合并后的错误
+func (s *multiSubscription) SetConcurrency(n int) error {
+	var errs []error
+	for _, sub := range s.subs {
+		if err := sub.SetConcurrency(n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return xerrors.Combine(errs...)
+}
@@ -2,6 +2,7 @@ package mq
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"regexp"
 	"strings"
@@ -15,14 +16,14 @@ import (
 	"github.com/ceyewan/genesis/xerrors"
 )
 
-// natsJetStreamTransport NATS JetStream 传输层实现
+// natsJetStreamTransport NATS JetStream 驱动实现
 type natsJetStreamTransport struct {
 	js     jetstream.JetStream
 	cfg    *JetStreamConfig
 	logger clog.Logger
 }
 
-// newNATSJetStreamTransport 创建 JetStream Transport
+// newNATSJetStreamTransport 创建 JetStream Driver
 func newNATSJetStreamTransport(conn connector.NATSConnector, cfg *JetStreamConfig, logger clog.Logger) (*natsJetStreamTransport, error) {
 	js, err := jetstream.New(conn.GetClient())
 	if err != nil {
@@ -43,7 +44,7 @@ func newNATSJetStreamTransport(conn connector.NATSConnector, cfg *JetStreamConfi
 }
 
 // Publish 发布消息
-func (t *natsJetStreamTransport) Publish(ctx context.Context, topic string, data []byte, opts publishOptions) error {
+func (t *natsJetStreamTransport) Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error {
 	if len(opts.Headers) == 0 {
 		_, err := t.js.Publish(ctx, topic, data)
 		return err
@@ -83,7 +84,12 @@ func headersFromNATS(nh nats.Header) Headers {
 }
 
 // Subscribe 订阅消息
-func (t *natsJetStreamTransport) Subscribe(ctx context.Context, topic string, handler Handler, opts subscribeOptions) (Subscription, error) {
+func (t *natsJetStreamTransport) Subscribe(ctx context.Context, topic string, handler Handler, opts SubscribeOptions) (Subscription, error) {
+	// Stream 按第一段划分，通配符只能出现在第一段之后
+	if isWildcardTopic(strings.Split(topic, ".")[0]) {
+		return nil, xerrors.Wrapf(ErrInvalidConfig, "wildcard topic %s must start with a literal segment", topic)
+	}
+
 	// 自动创建/更新 Stream（如果配置开启）
 	if t.cfg.AutoCreateStream {
 		if err := t.ensureStream(ctx, topic); err != nil {
@@ -112,8 +118,10 @@ func (t *natsJetStreamTransport) Subscribe(ctx context.Context, topic string, ha
 		consumerCfg.Durable = sanitizeName(opts.DurableName)
 	}
 
-	// 设置 AckWait（等待 Ack 的超时时间）
-	if t.cfg.AckWait > 0 {
+	// 设置 AckWait（等待 Ack 的超时时间），订阅级 AckTimeout 优先
+	if opts.AckTimeout > 0 {
+		consumerCfg.AckWait = opts.AckTimeout
+	} else if t.cfg.AckWait > 0 {
 		consumerCfg.AckWait = t.cfg.AckWait
 	}
 
@@ -129,6 +137,7 @@ func (t *natsJetStreamTransport) Subscribe(ctx context.Context, topic string, ha
 	}
 
 	// 启动消费
+	sub := newJetStreamSubscription(ctx, t.js.Conn())
 	cons, err := consumer.Consume(func(msg jetstream.Msg) {
 		m := &jetStreamMessage{
 			msg:     msg,
@@ -137,15 +146,29 @@ func (t *natsJetStreamTransport) Subscribe(ctx context.Context, topic string, ha
 		}
 		// 错误已在上层 wrapHandler 中处理
 		_ = handler(m)
-	})
+	}, jetstream.ConsumeErrHandler(func(_ jetstream.ConsumeContext, err error) {
+		// Consumer 被删除（如服务端重启丢失了非持久化 consumer）后无法自行恢复，
+		// 终止当前订阅，交由上层重建
+		if errors.Is(err, jetstream.ErrConsumerDeleted) || errors.Is(err, jetstream.ErrConsumerNotFound) {
+			t.logger.Warn("consumer lost", clog.String("topic", topic), clog.Error(err))
+			sub.interrupt(err)
+		}
+	}))
 	if err != nil {
+		sub.cancel()
 		return nil, xerrors.Wrap(err, "start consuming failed")
 	}
 
-	return newJetStreamSubscription(cons, ctx), nil
+	sub.start(cons)
+	return sub, nil
 }
 
-// Close 关闭 Transport
+// Capabilities 返回驱动支持的可选能力
+func (t *natsJetStreamTransport) Capabilities() Capabilities {
+	return Capabilities{AsyncPublish: true}
+}
+
+// Close 关闭 Driver
 func (t *natsJetStreamTransport) Close() error {
 	return nil
 }
@@ -184,7 +207,7 @@ func (t *natsJetStreamTransport) ensureStream(ctx context.Context, topic string)
 		// 需要添加新的 subject
 		// 重要：使用原有配置的全量拷贝，只修改 Subjects，避免其他配置（Storage/Retention/MaxMsgs等）被重置
 		updatedConfig := info.Config
-		updatedConfig.Subjects = append(updatedConfig.Subjects, topic)
+		updatedConfig.Subjects = mergeSubjects(info.Config.Subjects, topic)
 		_, err = t.js.UpdateStream(ctx, updatedConfig)
 		if err != nil {
 			return xerrors.Wrapf(err, "update stream %s to add subject %s failed", streamName, topic)
@@ -207,28 +230,18 @@ func (t *natsJetStreamTransport) ensureStream(ctx context.Context, topic string)
 	return nil
 }
 
-// matchesWildcard 检查通配符 subject 是否匹配 topic
-// 例如 "orders.*" 匹配 "orders.created"
-func matchesWildcard(pattern, topic string) bool {
-	// 简单实现：处理 * 和 > 通配符
-	if pattern == topic {
-		return true
-	}
-	patternParts := strings.Split(pattern, ".")
-	topicParts := strings.Split(topic, ".")
-
-	for i, p := range patternParts {
-		if p == ">" {
-			return true // > 匹配剩余所有
-		}
-		if i >= len(topicParts) {
-			return false
-		}
-		if p != "*" && p != topicParts[i] {
-			return false
+// mergeSubjects 将 topic 加入 subjects
+//
+// topic 为通配符时移除已被其覆盖的 subject，JetStream 不允许同一 Stream 内的 subject 相互重叠。
+func mergeSubjects(subjects []string, topic string) []string {
+	merged := make([]string, 0, len(subjects)+1)
+	for _, sub := range subjects {
+		if isWildcardTopic(topic) && matchesWildcard(topic, sub) {
+			continue
 		}
+		merged = append(merged, sub)
 	}
-	return len(patternParts) == len(topicParts)
+	return append(merged, topic)
 }
 
 // sanitizeName 清理名称，移除不合法字符
@@ -286,30 +299,64 @@ func (m *jetStreamMessage) ID() string {
 
 // jetStreamSubscription JetStream 订阅实现
 type jetStreamSubscription struct {
+	fixedConcurrency
+
 	cons   jetstream.ConsumeContext
+	conn   *nats.Conn
 	ctx    context.Context
 	cancel context.CancelFunc
 	done   chan struct{}
 	once   sync.Once
+
+	mu  sync.Mutex
+	err error
 }
 
-func newJetStreamSubscription(cons jetstream.ConsumeContext, parentCtx context.Context) *jetStreamSubscription {
+func newJetStreamSubscription(parentCtx context.Context, conn *nats.Conn) *jetStreamSubscription {
 	ctx, cancel := context.WithCancel(parentCtx)
-	s := &jetStreamSubscription{
-		cons:   cons,
+	return &jetStreamSubscription{
+		conn:   conn,
 		ctx:    ctx,
 		cancel: cancel,
 		done:   make(chan struct{}),
 	}
+}
+
+// start 绑定 ConsumeContext 并监控订阅生命周期
+func (s *jetStreamSubscription) start(cons jetstream.ConsumeContext) {
+	s.cons = cons
 
 	go func() {
-		<-ctx.Done()
-		s.cons.Stop()
+		select {
+		case <-s.ctx.Done():
+			s.cons.Stop()
+		case <-s.cons.Closed():
+			// 非主动取消导致的消费结束视为异常中断
+			s.interrupt(ErrSubscriptionClosed)
+		}
 		<-s.cons.Closed()
 		s.once.Do(func() { close(s.done) })
 	}()
+}
 
-	return s
+// interrupt 记录异常终止原因并停止订阅
+func (s *jetStreamSubscription) interrupt(err error) {
+	s.mu.Lock()
+	if s.err == nil && s.ctx.Err() == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+	s.cancel()
+}
+
+func (s *jetStreamSubscription) healthy() bool {
+	return s.conn != nil && s.conn.IsConnected()
+}
+
+func (s *jetStreamSubscription) cause() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
 }
 
 func (s *jetStreamSubscription) Unsubscribe() error {
@@ -320,3 +367,12 @@ func (s *jetStreamSubscription) Unsubscribe() error {
 func (s *jetStreamSubscription) Done() <-chan struct{} {
 	return s.done
 }
+
+func (s *jetStreamSubscription) IsActive() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return s.healthy()
+	}
+}
@@ -1,19 +1,21 @@
 package mq
 
+import "time"
+
 // ==================== 发布选项 ====================
 
 // PublishOption 发布选项
-type PublishOption func(*publishOptions)
+type PublishOption func(*PublishOptions)
 
-// publishOptions 发布选项（内部使用）
-type publishOptions struct {
+// PublishOptions 发布选项，由 PublishOption 构建后传给 Driver
+type PublishOptions struct {
 	// Headers 消息头
 	Headers Headers
 }
 
 // defaultPublishOptions 返回默认发布选项
-func defaultPublishOptions() publishOptions {
-	return publishOptions{}
+func defaultPublishOptions() PublishOptions {
+	return PublishOptions{}
 }
 
 // WithHeaders 设置消息头
@@ -24,14 +26,14 @@ func defaultPublishOptions() publishOptions {
 //	    "trace-id": "abc123",
 //	}))
 func WithHeaders(h Headers) PublishOption {
-	return func(o *publishOptions) {
+	return func(o *PublishOptions) {
 		o.Headers = h.Clone()
 	}
 }
 
 // WithHeader 设置单个消息头
 func WithHeader(key, value string) PublishOption {
-	return func(o *publishOptions) {
+	return func(o *PublishOptions) {
 		if o.Headers == nil {
 			o.Headers = make(Headers)
 		}
@@ -42,10 +44,10 @@ func WithHeader(key, value string) PublishOption {
 // ==================== 订阅选项 ====================
 
 // SubscribeOption 订阅选项
-type SubscribeOption func(*subscribeOptions)
+type SubscribeOption func(*SubscribeOptions)
 
-// subscribeOptions 订阅选项（内部使用）
-type subscribeOptions struct {
+// SubscribeOptions 订阅选项，由 SubscribeOption 构建后传给 Driver
+type SubscribeOptions struct {
 	// QueueGroup 队列组名称（用于负载均衡）
 	// 同一组内的消费者竞争消费消息
 	QueueGroup string
@@ -68,13 +70,39 @@ type subscribeOptions struct {
 	// MaxInflight 最大在途消息数
 	// JetStream: MaxAckPending
 	MaxInflight int
+
+	// AckTimeout 本地 ack 超时，Handler 超过该时间未返回时自动 Nak
+	// JetStream: 同时对齐 consumer 的 AckWait
+	AckTimeout time.Duration
+
+	// HandlerTimeout Handler 的处理超时，超时后取消 msg.Context()
+	HandlerTimeout time.Duration
+
+	// Schema 消息 schema 校验器，不符合的消息直接进死信
+	Schema SchemaValidator
+
+	// SchemaDeadLetter schema 校验失败时的死信主题，默认 "<topic>.DLQ"
+	SchemaDeadLetter string
+
+	// ResubscribeInterval 自动重订阅的重试间隔
+	ResubscribeInterval time.Duration
+
+	// OnResubscribe 自动重订阅回调，每次重订阅尝试后调用
+	OnResubscribe func(ResubscribeEvent)
+
+	// ManualCommit 关闭 offset 自动提交，Ack 时才提交（仅 Kafka 有效）
+	ManualCommit bool
+
+	// Concurrency 并发处理消息的 worker 数，0 表示在投递 goroutine 中串行处理
+	Concurrency int
 }
 
 // defaultSubscribeOptions 返回默认订阅选项
-func defaultSubscribeOptions() subscribeOptions {
-	return subscribeOptions{
-		AutoAck:   false, // 默认手动确认
-		BatchSize: 10,
+func defaultSubscribeOptions() SubscribeOptions {
+	return SubscribeOptions{
+		AutoAck:             false, // 默认手动确认
+		BatchSize:           10,
+		ResubscribeInterval: time.Second,
 	}
 }
 
@@ -86,10 +114,11 @@ func defaultSubscribeOptions() subscribeOptions {
 // 驱动映射（语义有差异）：
 //   - NATS JetStream: 映射为 durable consumer 名称，多实例共享同一 durable 实现负载均衡
 //   - Redis Stream: 映射为 consumer group 名称，组是持久化进度的承载体
+//   - Kafka: 映射为 consumer group 名称，offset 按组提交
 //
 // 注意：两者"持久化"的载体不同，JetStream 持久化在 durable consumer，Redis 持久化在 group。
 func WithQueueGroup(name string) SubscribeOption {
-	return func(o *subscribeOptions) {
+	return func(o *SubscribeOptions) {
 		o.QueueGroup = name
 	}
 }
@@ -104,7 +133,7 @@ func WithQueueGroup(name string) SubscribeOption {
 //   - 对于无法恢复的错误，应该 Ack() 而非 Nak()，避免无限循环
 //   - 建议配合 WithRetry 中间件在应用层重试
 func WithManualAck() SubscribeOption {
-	return func(o *subscribeOptions) {
+	return func(o *SubscribeOptions) {
 		o.AutoAck = false
 	}
 }
@@ -114,7 +143,7 @@ func WithManualAck() SubscribeOption {
 // 启用后 Handler 返回 nil 时自动 Ack，返回 error 时自动 Nak。
 // 注意：这会改变默认行为，确保业务逻辑能正确处理。
 func WithAutoAck() SubscribeOption {
-	return func(o *subscribeOptions) {
+	return func(o *SubscribeOptions) {
 		o.AutoAck = true
 	}
 }
@@ -126,10 +155,11 @@ func WithAutoAck() SubscribeOption {
 //     未设置 WithQueueGroup 时生效，设置后 WithQueueGroup 优先。
 //   - Redis Stream: 映射为 consumer name，是同一 group 内消费者实例的标识；
 //     需与 WithQueueGroup 配合使用，单独设置无持久化效果。
+//   - Kafka: 无效，消费进度只由 WithQueueGroup 对应的 consumer group 承载。
 //
 // 如需跨驱动共享消费进度，请使用 WithQueueGroup。
 func WithDurable(name string) SubscribeOption {
-	return func(o *subscribeOptions) {
+	return func(o *SubscribeOptions) {
 		o.DurableName = name
 	}
 }
@@ -141,9 +171,10 @@ func WithDurable(name string) SubscribeOption {
 //
 // 驱动支持情况：
 //   - Redis Stream：有效，对应 XREADGROUP COUNT / XREAD COUNT 参数。
+//   - Kafka：有效，对应单次 PollRecords 的最大条数。
 //   - JetStream：当前实现使用 consumer.Consume() 推送模式，此参数无效。
 func WithBatchSize(size int) SubscribeOption {
-	return func(o *subscribeOptions) {
+	return func(o *SubscribeOptions) {
 		if size > 0 {
 			o.BatchSize = size
 		}
@@ -155,9 +186,118 @@ func WithBatchSize(size int) SubscribeOption {
 // 限制未确认消息的数量，用于背压控制。
 // 仅 JetStream 有效（对应 MaxAckPending）。
 func WithMaxInflight(n int) SubscribeOption {
-	return func(o *subscribeOptions) {
+	return func(o *SubscribeOptions) {
 		if n > 0 {
 			o.MaxInflight = n
 		}
 	}
 }
+
+// WithAckTimeout 设置本地 ack 超时
+//
+// Handler 超过 d 未返回时，组件自动 Nak 该消息以触发重投，取消 msg.Context()，
+// 并记录 warn 日志；此后 Handler 再调用 Ack/Nak 会返回 ErrAckTimeout。
+//
+// 驱动映射：
+//   - NATS JetStream: Nak 立即重投，同时把 consumer 的 AckWait 设为 d，与服务端超时对齐
+//   - Redis Stream: 不支持 Nak，消息留在 Pending 列表，由 XAUTOCLAIM 在 PendingIdle 后重新认领
+func WithAckTimeout(d time.Duration) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		if d > 0 {
+			o.AckTimeout = d
+		}
+	}
+}
+
+// WithHandlerTimeout 设置 Handler 的处理超时
+//
+// 传给 Handler 的 msg.Context() 带 d 的 deadline，超时后 Context 被取消（context.Cause 为
+// ErrHandlerTimeout），Handler 应感知取消并尽快返回。此后 Handler 返回的错误会附加
+// ErrHandlerTimeout，按普通失败处理：AutoAck 模式下 Nak 重投，WithRetry 在 Context 取消后不再重试。
+// Handler 在超时后仍返回 nil 视为处理成功。
+//
+// 与 WithAckTimeout 的区别：HandlerTimeout 只取消 Context、由 Handler 配合返回，
+// 不会在 Handler 之外强制 Nak；两者可以同时使用，通常 HandlerTimeout 小于 AckTimeout。
+func WithHandlerTimeout(d time.Duration) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		if d > 0 {
+			o.HandlerTimeout = d
+		}
+	}
+}
+
+// WithSchema 设置消息 schema 校验
+//
+// 收到消息后先校验 payload，不符合 schema 时不调用 Handler，而是把原始 payload
+// 发送到死信主题（附带 x-original-topic、x-error 头）、记录 warn 日志并确认原消息。
+// 死信主题默认为 "<topic>.DLQ"，可通过 WithSchemaDeadLetter 修改。
+//
+// 内置校验器：NewJSONSchemaValidator、NewProtoValidator。
+func WithSchema(v SchemaValidator) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.Schema = v
+	}
+}
+
+// WithSchemaDeadLetter 设置 schema 校验失败时的死信主题
+func WithSchemaDeadLetter(topic string) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.SchemaDeadLetter = topic
+	}
+}
+
+// WithResubscribeInterval 设置自动重订阅的重试间隔
+//
+// 底层连接断开导致订阅异常终止时，组件会按此间隔重建订阅，直到成功或订阅被取消。
+// 默认值：1s
+func WithResubscribeInterval(d time.Duration) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		if d > 0 {
+			o.ResubscribeInterval = d
+		}
+	}
+}
+
+// WithOnResubscribe 设置自动重订阅回调
+//
+// 每次重订阅尝试后调用，event.Err 为 nil 表示订阅已恢复。
+// 回调在订阅的监控 goroutine 中同步执行，不应长时间阻塞。
+func WithOnResubscribe(fn func(event ResubscribeEvent)) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.OnResubscribe = fn
+	}
+}
+
+// WithManualCommit 关闭 offset 自动提交（仅 Kafka 有效）
+//
+// 默认 Kafka 驱动按周期自动提交已拉取的 offset，Handler 失败或进程崩溃时，
+// 已提交但未处理成功的消息会丢失。开启后只有 msg.Ack() 才同步提交该消息的 offset，
+// 配合 WithAutoAck 即"Handler 成功后才提交"；msg.Nak() 不提交并回退分区重投。
+//
+// 注意：
+//   - 必须配合 WithQueueGroup 使用，offset 提交在 consumer group 上
+//   - Kafka offset 按分区单调提交，Ack 后面的消息会隐式确认同分区前面所有消息；
+//     既不 Ack 也不 Nak 的消息在后续消息 Ack 后不会再重投
+func WithManualCommit() SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.ManualCommit = true
+	}
+}
+
+// WithConcurrency 设置并发处理消息的 worker 数
+//
+// 开启后消息交给 n 个 worker 并发处理，运行中可通过 Subscription.SetConcurrency 调整。
+// 所有 worker 忙碌时投递方阻塞等待，在途消息数不超过 worker 数。
+// 不设置时 Handler 在驱动的投递 goroutine 中串行执行，SetConcurrency 返回 ErrNotSupported。
+//
+// 注意：
+//   - 并发处理不保证消息顺序（Kafka 同一分区内的顺序也会被打乱）
+//   - Kafka 开启 WithManualCommit 时，offset 按分区单调提交，后面的消息先 Ack 会隐式确认前面的消息
+//   - 对 SubscribeBatch 不生效
+func WithConcurrency(n int) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		if n > 0 {
+			o.Concurrency = n
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+const (
+	// defaultOutboxInterval relay 默认轮询间隔
+	defaultOutboxInterval = time.Second
+	// defaultOutboxBatchSize relay 单次轮询默认投递条数
+	defaultOutboxBatchSize = 100
+)
+
+// OutboxMessage outbox 表记录
+//
+// 业务事务内通过 OutboxPublish 写入，由 OutboxRelay 异步投递到 MQ。
+// SentAt 为空表示尚未投递成功；Prepared 为 true 表示 PublishInTransaction 的半消息，
+// 本地事务结束前不会被 relay 投递。
+type OutboxMessage struct {
+	ID        uint64     `gorm:"primaryKey;autoIncrement"`
+	Topic     string     `gorm:"size:255;not null"`
+	Payload   []byte     `gorm:"not null"`
+	Headers   string     `gorm:"type:text"`
+	CreatedAt time.Time  `gorm:"not null"`
+	SentAt    *time.Time `gorm:"index"`
+	Attempts  int        `gorm:"not null;default:0"`
+	LastError string     `gorm:"type:text"`
+	Prepared  bool       `gorm:"not null;default:false"`
+}
+
+// TableName 实现 gorm.Tabler
+func (OutboxMessage) TableName() string {
+	return "mq_outbox"
+}
+
+// MigrateOutbox 创建或更新 outbox 表结构
+func MigrateOutbox(db *gorm.DB) error {
+	if db == nil {
+		return xerrors.Wrap(ErrInvalidConfig, "outbox: db is nil")
+	}
+	if err := db.AutoMigrate(&OutboxMessage{}); err != nil {
+		return xerrors.Wrap(err, "outbox: migrate table")
+	}
+	return nil
+}
+
+// OutboxPublish 在业务事务内把消息写入 outbox 表
+//
+// tx 应为业务事务（如 db.Transaction 回调中的 tx），消息与业务数据同时提交或回滚。
+// 消息不会立即发出，而是由 OutboxRelay 在事务提交后投递。
+// opts 中的 Headers 随消息一起保存。
+//
+// 示例：
+//
+//	err := database.Transaction(ctx, func(ctx context.Context, tx *gorm.DB) error {
+//	    if err := tx.Create(&order).Error; err != nil {
+//	        return err
+//	    }
+//	    return mq.OutboxPublish(ctx, tx, "orders.created", payload)
+//	})
+func OutboxPublish(ctx context.Context, tx *gorm.DB, topic string, data []byte, opts ...PublishOption) error {
+	if tx == nil {
+		return xerrors.Wrap(ErrInvalidConfig, "outbox: tx is nil")
+	}
+	if topic == "" {
+		return xerrors.Wrap(ErrInvalidConfig, "outbox: topic is empty")
+	}
+
+	o := defaultPublishOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	msg, err := newOutboxMessage(topic, data, o)
+	if err != nil {
+		return err
+	}
+	if err := tx.WithContext(ctx).Create(msg).Error; err != nil {
+		return xerrors.Wrapf(err, "outbox: insert message for topic %s", topic)
+	}
+	return nil
+}
+
+// newOutboxMessage 构造待写入的 outbox 记录，Headers 序列化为 JSON
+func newOutboxMessage(topic string, data []byte, opts PublishOptions) (*OutboxMessage, error) {
+	msg := &OutboxMessage{
+		Topic:     topic,
+		Payload:   data,
+		CreatedAt: time.Now(),
+	}
+	if len(opts.Headers) > 0 {
+		headers, err := json.Marshal(opts.Headers)
+		if err != nil {
+			return nil, xerrors.Wrap(err, "outbox: marshal headers")
+		}
+		msg.Headers = string(headers)
+	}
+	return msg, nil
+}
+
+// OutboxOption OutboxRelay 选项
+type OutboxOption func(*outboxOptions)
+
+// outboxOptions OutboxRelay 选项（内部使用）
+type outboxOptions struct {
+	interval  time.Duration
+	batchSize int
+	logger    clog.Logger
+}
+
+// WithOutboxInterval 设置 relay 轮询间隔，默认 1s
+func WithOutboxInterval(d time.Duration) OutboxOption {
+	return func(o *outboxOptions) {
+		if d > 0 {
+			o.interval = d
+		}
+	}
+}
+
+// WithOutboxBatchSize 设置 relay 单次轮询最多投递的消息数，默认 100
+func WithOutboxBatchSize(n int) OutboxOption {
+	return func(o *outboxOptions) {
+		if n > 0 {
+			o.batchSize = n
+		}
+	}
+}
+
+// WithOutboxLogger 设置 relay 日志记录器
+func WithOutboxLogger(l clog.Logger) OutboxOption {
+	return func(o *outboxOptions) {
+		if l != nil {
+			o.logger = l.WithNamespace("outbox")
+		}
+	}
+}
+
+// OutboxRelay 轮询 outbox 表并把未投递的消息发布到 MQ
+//
+// 投递语义为至少一次：消息发布成功后才标记已发，若在两步之间崩溃，
+// 重启后会再次投递，消费方应保证幂等。按 ID 顺序投递，某条失败时
+// 本轮停止，下轮从该条重试，避免后写入的消息越过失败的消息。
+//
+// 多个 relay 实例同时运行时同一条消息可能被重复投递，建议每个 outbox 表只运行一个 relay。
+type OutboxRelay struct {
+	db   *gorm.DB
+	pub  MQ
+	opts outboxOptions
+}
+
+// NewOutboxRelay 创建 outbox relay
+//
+// db 为业务库连接（outbox 表所在库），pub 为投递目标 MQ。
+func NewOutboxRelay(db *gorm.DB, pub MQ, opts ...OutboxOption) (*OutboxRelay, error) {
+	if db == nil {
+		return nil, xerrors.Wrap(ErrInvalidConfig, "outbox: db is nil")
+	}
+	if pub == nil {
+		return nil, xerrors.Wrap(ErrInvalidConfig, "outbox: mq is nil")
+	}
+
+	o := outboxOptions{
+		interval:  defaultOutboxInterval,
+		batchSize: defaultOutboxBatchSize,
+		logger:    clog.Discard(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &OutboxRelay{db: db, pub: pub, opts: o}, nil
+}
+
+// Run 按轮询间隔持续投递，直到 ctx 取消
+//
+// 单轮投递出错只记录日志，下一轮继续重试；ctx 取消时返回 nil。
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.opts.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RelayOnce(ctx); err != nil && ctx.Err() == nil {
+			r.opts.logger.Warn("outbox relay failed", clog.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce 投递一批未发送的消息，返回成功投递的条数
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	var pending []OutboxMessage
+	err := r.db.WithContext(ctx).
+		Where("sent_at IS NULL AND prepared = ?", false).
+		Order("id").
+		Limit(r.opts.batchSize).
+		Find(&pending).Error
+	if err != nil {
+		return 0, xerrors.Wrap(err, "outbox: query pending messages")
+	}
+
+	sent := 0
+	for i := range pending {
+		if err := r.relay(ctx, &pending[i]); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// relay 投递单条消息并更新其状态
+func (r *OutboxRelay) relay(ctx context.Context, msg *OutboxMessage) error {
+	var opts []PublishOption
+	if msg.Headers != "" {
+		var headers Headers
+		if err := json.Unmarshal([]byte(msg.Headers), &headers); err != nil {
+			return xerrors.Wrapf(err, "outbox: unmarshal headers of message %d", msg.ID)
+		}
+		opts = append(opts, WithHeaders(headers))
+	}
+
+	if pubErr := r.pub.Publish(ctx, msg.Topic, msg.Payload, opts...); pubErr != nil {
+		err := r.db.WithContext(context.WithoutCancel(ctx)).Model(&OutboxMessage{}).
+			Where("id = ?", msg.ID).
+			Updates(map[string]any{
+				"attempts":   gorm.Expr("attempts + 1"),
+				"last_error": pubErr.Error(),
+			}).Error
+		if err != nil {
+			r.opts.logger.Warn("outbox: record publish failure", clog.Uint64("id", msg.ID), clog.Error(err))
+		}
+		return xerrors.Wrapf(pubErr, "outbox: publish message %d to %s", msg.ID, msg.Topic)
+	}
+
+	// 已发布但标记失败时，下一轮会重复投递（至少一次）
+	err := r.db.WithContext(context.WithoutCancel(ctx)).Model(&OutboxMessage{}).
+		Where("id = ?", msg.ID).
+		Update("sent_at", time.Now()).Error
+	if err != nil {
+		return xerrors.Wrapf(err, "outbox: mark message %d sent", msg.ID)
+	}
+	return nil
+}
+
+// Purge 删除 SentAt 早于 before 的已投递消息，返回删除条数
+func (r *OutboxRelay) Purge(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("sent_at IS NOT NULL AND sent_at < ?", before).
+		Delete(&OutboxMessage{})
+	if result.Error != nil {
+		return 0, xerrors.Wrap(result.Error, "outbox: purge sent messages")
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,187 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+	"github.com/ceyewan/genesis/testkit"
+)
+
+// outboxOrder outbox 测试用的业务表
+type outboxOrder struct {
+	ID   uint `gorm:"primaryKey"`
+	Item string
+}
+
+// recordingTransport 记录发布消息的 Transport，fail 非 nil 时发布失败
+type recordingTransport struct {
+	mockTransport
+
+	mu        sync.Mutex
+	fail      error
+	published []string
+	headers   []Headers
+}
+
+func (r *recordingTransport) Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail != nil {
+		return r.fail
+	}
+	r.published = append(r.published, topic+":"+string(data))
+	r.headers = append(r.headers, opts.Headers)
+	return nil
+}
+
+func (r *recordingTransport) setFail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fail = err
+}
+
+func (r *recordingTransport) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.published...)
+}
+
+func newOutboxTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := testkit.NewSQLiteDB(t)
+	require.NoError(t, MigrateOutbox(db))
+	require.NoError(t, db.AutoMigrate(&outboxOrder{}))
+	t.Cleanup(func() { _ = db.Migrator().DropTable(&OutboxMessage{}, &outboxOrder{}) })
+	return db
+}
+
+func newRecordingMQ(transport *recordingTransport) MQ {
+	return &mq{transport: transport, logger: clog.Discard(), meter: metrics.Discard(), driver: string(DriverNATSJetStream)}
+}
+
+func TestOutbox(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("事务回滚时消息不发送", func(t *testing.T) {
+		db := newOutboxTestDB(t)
+		transport := &recordingTransport{}
+		relay, err := NewOutboxRelay(db, newRecordingMQ(transport))
+		require.NoError(t, err)
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			require.NoError(t, tx.Create(&outboxOrder{Item: "book"}).Error)
+			require.NoError(t, OutboxPublish(ctx, tx, "orders.created", []byte("book")))
+			return errors.New("rollback")
+		})
+		require.Error(t, err)
+
+		sent, err := relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		require.Zero(t, sent)
+		require.Empty(t, transport.snapshot())
+	})
+
+	t.Run("提交后 relay 最终投递", func(t *testing.T) {
+		db := newOutboxTestDB(t)
+		transport := &recordingTransport{}
+		relay, err := NewOutboxRelay(db, newRecordingMQ(transport), WithOutboxInterval(10*time.Millisecond))
+		require.NoError(t, err)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() { done <- relay.Run(runCtx) }()
+		defer func() {
+			cancel()
+			require.NoError(t, <-done)
+		}()
+
+		for _, item := range []string{"a", "b"} {
+			err := db.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Create(&outboxOrder{Item: item}).Error; err != nil {
+					return err
+				}
+				return OutboxPublish(ctx, tx, "orders.created", []byte(item), WithHeader("trace-id", item))
+			})
+			require.NoError(t, err)
+		}
+
+		require.Eventually(t, func() bool {
+			return len(transport.snapshot()) == 2
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, []string{"orders.created:a", "orders.created:b"}, transport.snapshot())
+		require.Equal(t, Headers{"trace-id": "a"}, transport.headers[0])
+
+		var pending int64
+		require.NoError(t, db.Model(&OutboxMessage{}).Where("sent_at IS NULL").Count(&pending).Error)
+		require.Zero(t, pending)
+	})
+
+	t.Run("relay 崩溃重启不丢未发消息", func(t *testing.T) {
+		db := newOutboxTestDB(t)
+		for _, item := range []string{"a", "b", "c"} {
+			require.NoError(t, db.Transaction(func(tx *gorm.DB) error {
+				return OutboxPublish(ctx, tx, "orders.created", []byte(item))
+			}))
+		}
+
+		// 第一个 relay 投递一条后 MQ 故障，随即退出
+		transport := &recordingTransport{}
+		relay, err := NewOutboxRelay(db, newRecordingMQ(transport), WithOutboxBatchSize(1))
+		require.NoError(t, err)
+		sent, err := relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, sent)
+
+		transport.setFail(errors.New("broker down"))
+		_, err = relay.RelayOnce(ctx)
+		require.Error(t, err)
+
+		var failed OutboxMessage
+		require.NoError(t, db.Where("sent_at IS NULL").Order("id").First(&failed).Error)
+		require.Equal(t, 1, failed.Attempts)
+		require.Equal(t, "broker down", failed.LastError)
+
+		// 重启后的 relay 从未发送的消息继续
+		restarted, err := NewOutboxRelay(db, newRecordingMQ(transport))
+		require.NoError(t, err)
+		transport.setFail(nil)
+		sent, err = restarted.RelayOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 2, sent)
+		require.Equal(t, []string{"orders.created:a", "orders.created:b", "orders.created:c"}, transport.snapshot())
+	})
+
+	t.Run("Purge 清理已投递消息", func(t *testing.T) {
+		db := newOutboxTestDB(t)
+		transport := &recordingTransport{}
+		relay, err := NewOutboxRelay(db, newRecordingMQ(transport))
+		require.NoError(t, err)
+
+		require.NoError(t, OutboxPublish(ctx, db, "orders.created", []byte("a")))
+		_, err = relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		require.NoError(t, OutboxPublish(ctx, db, "orders.created", []byte("b")))
+
+		purged, err := relay.Purge(ctx, time.Now().Add(time.Second))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, purged)
+
+		var remaining int64
+		require.NoError(t, db.Model(&OutboxMessage{}).Count(&remaining).Error)
+		require.EqualValues(t, 1, remaining, "未投递的消息不会被清理")
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		require.ErrorIs(t, OutboxPublish(ctx, nil, "t", nil), ErrInvalidConfig)
+		_, err := NewOutboxRelay(nil, newRecordingMQ(&recordingTransport{}))
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+}
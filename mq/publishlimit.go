@@ -0,0 +1,87 @@
+package mq
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// publishLimit 发布速率配置
+type publishLimit struct {
+	rate  float64 // 每秒放行条数
+	burst int     // 允许的突发条数
+}
+
+// publishThrottle 发布限流与异步发布背压（内部使用）
+type publishThrottle struct {
+	limiter *rate.Limiter // 所有主题共享的令牌桶，未配置速率时为 nil
+	slots   chan struct{} // 异步发布的 pending 名额，未配置上限时为 nil
+	reject  bool          // 超限时返回 ErrPublishThrottled 而非阻塞
+}
+
+// newPublishThrottle 根据选项创建限流器，未配置限流与 pending 上限时返回 nil
+func newPublishThrottle(o *options) (*publishThrottle, error) {
+	if o.publishLimit == nil && o.maxPendingPublish <= 0 {
+		return nil, nil
+	}
+
+	t := &publishThrottle{reject: o.publishReject}
+	if o.publishLimit != nil {
+		if o.publishLimit.rate <= 0 || o.publishLimit.burst <= 0 {
+			return nil, xerrors.Wrapf(ErrInvalidConfig, "publish rate limit: rate and burst must be positive, got rate=%v burst=%d",
+				o.publishLimit.rate, o.publishLimit.burst)
+		}
+		t.limiter = rate.NewLimiter(rate.Limit(o.publishLimit.rate), o.publishLimit.burst)
+	}
+	if o.maxPendingPublish > 0 {
+		t.slots = make(chan struct{}, o.maxPendingPublish)
+	}
+	return t, nil
+}
+
+// wait 获取 1 个发布令牌，reject 模式下令牌不足立即返回 ErrPublishThrottled
+func (t *publishThrottle) wait(ctx context.Context, topic string) error {
+	if t.limiter == nil {
+		return nil
+	}
+	if t.reject {
+		if !t.limiter.Allow() {
+			return xerrors.Wrapf(ErrPublishThrottled, "publish rate exceeded on %s", topic)
+		}
+		return nil
+	}
+	if err := t.limiter.Wait(ctx); err != nil {
+		return xerrors.Wrapf(err, "wait publish rate limit on %s", topic)
+	}
+	return nil
+}
+
+// acquire 占用 1 个异步发布的 pending 名额，reject 模式下名额用尽立即返回 ErrPublishThrottled
+func (t *publishThrottle) acquire(ctx context.Context, topic string) error {
+	if t.slots == nil {
+		return nil
+	}
+	if t.reject {
+		select {
+		case t.slots <- struct{}{}:
+			return nil
+		default:
+			return xerrors.Wrapf(ErrPublishThrottled, "pending async publish reached %d on %s", cap(t.slots), topic)
+		}
+	}
+	select {
+	case t.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return xerrors.Wrapf(ctx.Err(), "wait pending async publish slot on %s", topic)
+	}
+}
+
+// release 归还 acquire 占用的名额
+func (t *publishThrottle) release() {
+	if t.slots != nil {
+		<-t.slots
+	}
+}
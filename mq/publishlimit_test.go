@@ -0,0 +1,142 @@
+package mq
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// countingTransport 统计发布条数，异步发布时记录同时未确认的最大条数
+type countingTransport struct {
+	mockTransport
+
+	published   atomic.Int32
+	outstanding atomic.Int32
+	maxOutstand atomic.Int32
+}
+
+func (c *countingTransport) Capabilities() Capabilities {
+	return Capabilities{AsyncPublish: true}
+}
+
+func (c *countingTransport) Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error {
+	c.published.Add(1)
+	return nil
+}
+
+// PublishAsync 模拟 broker 在 5ms 后确认
+func (c *countingTransport) PublishAsync(ctx context.Context, topic string, data []byte, opts PublishOptions, callback PublishCallback) {
+	n := c.outstanding.Add(1)
+	for {
+		old := c.maxOutstand.Load()
+		if n <= old || c.maxOutstand.CompareAndSwap(old, n) {
+			break
+		}
+	}
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		c.published.Add(1)
+		c.outstanding.Add(-1)
+		callback(nil)
+	}()
+}
+
+func TestMQ_PublishRateLimit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("超过速率时阻塞且不丢消息", func(t *testing.T) {
+		transport := &countingTransport{}
+		m, err := New(&Config{}, WithDriver(transport), WithPublishRateLimit(100, 10))
+		require.NoError(t, err)
+		defer m.Close()
+
+		start := time.Now()
+		for range 40 {
+			require.NoError(t, m.Publish(ctx, "orders", []byte("x")))
+		}
+		// 突发 10 条后按每秒 100 条放行，剩余 30 条至少耗时约 300ms
+		require.GreaterOrEqual(t, time.Since(start), 250*time.Millisecond)
+		require.EqualValues(t, 40, transport.published.Load())
+	})
+
+	t.Run("阻塞等待随 ctx 结束", func(t *testing.T) {
+		m, err := New(&Config{}, WithDriver(&countingTransport{}), WithPublishRateLimit(1, 1))
+		require.NoError(t, err)
+		defer m.Close()
+
+		require.NoError(t, m.Publish(ctx, "orders", []byte("x")))
+		shortCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		require.Error(t, m.Publish(shortCtx, "orders", []byte("x")))
+	})
+
+	t.Run("拒绝模式立即返回 ErrPublishThrottled", func(t *testing.T) {
+		transport := &countingTransport{}
+		m, err := New(&Config{}, WithDriver(transport), WithPublishRateLimit(1, 5), WithPublishRejectWhenThrottled())
+		require.NoError(t, err)
+		defer m.Close()
+
+		var throttled int
+		for range 20 {
+			if err := m.Publish(ctx, "orders", []byte("x")); err != nil {
+				require.ErrorIs(t, err, ErrPublishThrottled)
+				throttled++
+			}
+		}
+		require.EqualValues(t, 5, transport.published.Load())
+		require.Equal(t, 15, throttled)
+	})
+
+	t.Run("无效配置", func(t *testing.T) {
+		_, err := New(&Config{}, WithDriver(&countingTransport{}), WithPublishRateLimit(0, 10))
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+}
+
+func TestMQ_MaxPendingPublish(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pending 不超过上限且不丢消息", func(t *testing.T) {
+		transport := &countingTransport{}
+		m, err := New(&Config{}, WithDriver(transport), WithMaxPendingPublish(5), WithPublishRateLimit(2000, 50))
+		require.NoError(t, err)
+		defer m.Close()
+
+		var succeeded atomic.Int32
+		for range 100 {
+			m.PublishAsync(ctx, "orders", []byte("x"), func(err error) {
+				if err == nil {
+					succeeded.Add(1)
+				}
+			})
+		}
+		require.NoError(t, m.Flush(ctx))
+		require.EqualValues(t, 100, succeeded.Load())
+		require.EqualValues(t, 100, transport.published.Load())
+		require.LessOrEqual(t, transport.maxOutstand.Load(), int32(5))
+	})
+
+	t.Run("拒绝模式名额用尽时回调 ErrPublishThrottled", func(t *testing.T) {
+		transport := &ackingTransport{} // 不调用 ack，消息一直未确认
+		m, err := New(&Config{}, WithDriver(transport), WithMaxPendingPublish(3), WithPublishRejectWhenThrottled())
+		require.NoError(t, err)
+		defer m.Close()
+
+		errs := make(chan error, 4)
+		for range 4 {
+			m.PublishAsync(ctx, "orders", []byte("x"), func(err error) { errs <- err })
+		}
+		require.ErrorIs(t, <-errs, ErrPublishThrottled)
+
+		transport.ack()
+		require.NoError(t, m.Flush(ctx))
+		m.PublishAsync(ctx, "orders", []byte("x"), func(err error) { errs <- err })
+		transport.ack()
+		for range 4 {
+			require.NoError(t, <-errs, "确认后名额释放")
+		}
+	})
+}
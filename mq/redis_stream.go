@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -20,14 +21,14 @@ const (
 	redisFieldHeaders = "headers"
 )
 
-// redisStreamTransport Redis Stream 传输层实现
+// redisStreamTransport Redis Stream 驱动实现
 type redisStreamTransport struct {
 	client *redis.Client
 	cfg    *RedisStreamConfig
 	logger clog.Logger
 }
 
-// newRedisStreamTransport 创建 Redis Stream Transport
+// newRedisStreamTransport 创建 Redis Stream Driver
 func newRedisStreamTransport(conn connector.RedisConnector, cfg *RedisStreamConfig, logger clog.Logger) *redisStreamTransport {
 	return &redisStreamTransport{
 		client: conn.GetClient(),
@@ -37,17 +38,10 @@ func newRedisStreamTransport(conn connector.RedisConnector, cfg *RedisStreamConf
 }
 
 // Publish 发布消息
-func (t *redisStreamTransport) Publish(ctx context.Context, topic string, data []byte, opts publishOptions) error {
-	values := map[string]any{
-		redisFieldPayload: data,
-	}
-
-	if len(opts.Headers) > 0 {
-		headersJSON, err := json.Marshal(opts.Headers)
-		if err != nil {
-			return xerrors.Wrap(err, "marshal headers failed")
-		}
-		values[redisFieldHeaders] = headersJSON
+func (t *redisStreamTransport) Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error {
+	values, err := t.streamValues(data, opts)
+	if err != nil {
+		return err
 	}
 
 	args := &redis.XAddArgs{
@@ -62,13 +56,34 @@ func (t *redisStreamTransport) Publish(ctx context.Context, topic string, data [
 	return t.client.XAdd(ctx, args).Err()
 }
 
+// streamValues 构造 Stream 消息字段：payload 与 JSON 编码的 headers
+func (t *redisStreamTransport) streamValues(data []byte, opts PublishOptions) (map[string]any, error) {
+	values := map[string]any{
+		redisFieldPayload: data,
+	}
+
+	if len(opts.Headers) > 0 {
+		headersJSON, err := json.Marshal(opts.Headers)
+		if err != nil {
+			return nil, xerrors.Wrap(err, "marshal headers failed")
+		}
+		values[redisFieldHeaders] = headersJSON
+	}
+	return values, nil
+}
+
 // Subscribe 订阅消息
-func (t *redisStreamTransport) Subscribe(ctx context.Context, topic string, handler Handler, opts subscribeOptions) (Subscription, error) {
+func (t *redisStreamTransport) Subscribe(ctx context.Context, topic string, handler Handler, opts SubscribeOptions) (Subscription, error) {
+	if isWildcardTopic(topic) {
+		return t.subscribePattern(ctx, topic, handler, opts)
+	}
+
 	subCtx, cancel := context.WithCancel(ctx)
 	sub := &redisStreamSubscription{
 		cancel: cancel,
 		done:   make(chan struct{}),
 	}
+	sub.active.Store(true)
 
 	// Consumer Group 创建在 goroutine 外执行，确保错误能在初始化阶段暴露
 	if opts.QueueGroup != "" {
@@ -84,9 +99,9 @@ func (t *redisStreamTransport) Subscribe(ctx context.Context, topic string, hand
 		}()
 
 		if opts.QueueGroup != "" {
-			t.consumeWithGroup(subCtx, topic, opts, handler)
+			t.consumeWithGroup(subCtx, topic, opts, handler, sub)
 		} else {
-			t.consumeBroadcast(subCtx, topic, opts, handler)
+			t.consumeBroadcast(subCtx, topic, "$", opts, handler, sub)
 		}
 	}()
 
@@ -98,12 +113,19 @@ func isGroupExistsError(err error) bool {
 	return strings.Contains(err.Error(), "BUSYGROUP")
 }
 
+// isNoGroupError 判断是否为 Consumer Group 不存在错误
+//
+// Redis 重启且未开启持久化时 stream 与 group 会一起丢失，需要重建订阅。
+func isNoGroupError(err error) bool {
+	return strings.HasPrefix(err.Error(), "NOGROUP")
+}
+
 // consumeWithGroup Consumer Group 模式消费
 //
 // 实现策略：
 // 1. 首先尝试 claim 超时的 Pending 消息（避免消费者崩溃后消息卡死）
 // 2. 然后读取新消息
-func (t *redisStreamTransport) consumeWithGroup(ctx context.Context, topic string, opts subscribeOptions, handler Handler) {
+func (t *redisStreamTransport) consumeWithGroup(ctx context.Context, topic string, opts SubscribeOptions, handler Handler, sub *redisStreamSubscription) {
 	group := opts.QueueGroup
 	consumer := opts.DurableName
 	if consumer == "" {
@@ -141,12 +163,20 @@ func (t *redisStreamTransport) consumeWithGroup(ctx context.Context, topic strin
 		}).Result()
 		if err != nil {
 			if err == redis.Nil || err == context.Canceled {
+				sub.active.Store(err == redis.Nil)
 				continue
 			}
+			sub.active.Store(false)
+			if isNoGroupError(err) {
+				// group 已丢失，结束当前订阅交由上层重建（重建时会重新创建 group）
+				sub.setCause(err)
+				return
+			}
 			t.logger.Error("XReadGroup failed", clog.String("topic", topic), clog.Error(err))
 			time.Sleep(time.Second) // 避免忙轮询
 			continue
 		}
+		sub.active.Store(true)
 
 		for _, stream := range streams {
 			for _, msg := range stream.Messages {
@@ -206,8 +236,10 @@ func (t *redisStreamTransport) claimPendingMessages(
 }
 
 // consumeBroadcast 广播模式消费
-func (t *redisStreamTransport) consumeBroadcast(ctx context.Context, topic string, opts subscribeOptions, handler Handler) {
-	lastID := "$" // 只读新消息
+//
+// startID 为起始位置，"$" 表示只读订阅之后的新消息。
+func (t *redisStreamTransport) consumeBroadcast(ctx context.Context, topic, startID string, opts SubscribeOptions, handler Handler, sub *redisStreamSubscription) {
+	lastID := startID
 
 	for {
 		select {
@@ -223,12 +255,15 @@ func (t *redisStreamTransport) consumeBroadcast(ctx context.Context, topic strin
 		}).Result()
 		if err != nil {
 			if err == redis.Nil || err == context.Canceled {
+				sub.active.Store(err == redis.Nil)
 				continue
 			}
+			sub.active.Store(false)
 			t.logger.Error("XRead failed", clog.String("topic", topic), clog.Error(err))
 			time.Sleep(time.Second)
 			continue
 		}
+		sub.active.Store(true)
 
 		for _, stream := range streams {
 			for _, msg := range stream.Messages {
@@ -297,7 +332,12 @@ func (t *redisStreamTransport) decodeHeaders(v any) Headers {
 	return h
 }
 
-// Close 关闭 Transport
+// Capabilities 返回驱动支持的可选能力
+func (t *redisStreamTransport) Capabilities() Capabilities {
+	return Capabilities{Transaction: true}
+}
+
+// Close 关闭 Driver
 func (t *redisStreamTransport) Close() error {
 	return nil
 }
@@ -357,9 +397,33 @@ func (m *redisStreamMessage) ID() string {
 
 // redisStreamSubscription Redis Stream 订阅实现
 type redisStreamSubscription struct {
+	fixedConcurrency
+
 	cancel context.CancelFunc
 	done   chan struct{}
 	once   sync.Once
+
+	// active 最近一次读取是否成功，连接异常期间为 false
+	active atomic.Bool
+
+	mu  sync.Mutex
+	err error
+}
+
+func (s *redisStreamSubscription) setCause(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func (s *redisStreamSubscription) healthy() bool {
+	return s.active.Load()
+}
+
+func (s *redisStreamSubscription) cause() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
 }
 
 func (s *redisStreamSubscription) Unsubscribe() error {
@@ -370,3 +434,12 @@ func (s *redisStreamSubscription) Unsubscribe() error {
 func (s *redisStreamSubscription) Done() <-chan struct{} {
 	return s.done
 }
+
+func (s *redisStreamSubscription) IsActive() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return s.healthy()
+	}
+}
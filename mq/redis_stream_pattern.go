@@ -0,0 +1,132 @@
+package mq
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+const (
+	// redisPatternRefreshInterval 通配符订阅重新扫描匹配 Stream 的间隔
+	redisPatternRefreshInterval = 5 * time.Second
+	// redisPatternScanCount 单次 SCAN 的建议返回数量
+	redisPatternScanCount = 100
+)
+
+// subscribePattern 通配符订阅
+//
+// Redis Stream 没有原生的模式订阅，这里定期 SCAN 匹配的 Stream，
+// 为每个 Stream 启动独立的消费 goroutine，消息的 Topic() 为实际的 Stream 名。
+// 订阅时已存在的 Stream 只消费之后的新消息；之后新出现的 Stream 从头消费，
+// 避免丢失发现前写入的消息。任一 Stream 的 group 丢失时整个订阅结束，交由上层重建。
+func (t *redisStreamTransport) subscribePattern(ctx context.Context, pattern string, handler Handler, opts SubscribeOptions) (Subscription, error) {
+	streams, err := t.scanStreams(ctx, pattern)
+	if err != nil {
+		return nil, xerrors.Wrapf(err, "scan streams for %s failed", pattern)
+	}
+
+	subCtx, cancel := context.WithCancel(ctx)
+	sub := &redisStreamSubscription{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	sub.active.Store(true)
+
+	var wg sync.WaitGroup
+	known := make(map[string]struct{}, len(streams))
+	start := func(stream, startID string) error {
+		if opts.QueueGroup != "" {
+			if err := t.client.XGroupCreateMkStream(subCtx, stream, opts.QueueGroup, startID).Err(); err != nil && !isGroupExistsError(err) {
+				return xerrors.Wrapf(err, "create consumer group for %s failed", stream)
+			}
+		}
+		known[stream] = struct{}{}
+		wg.Go(func() {
+			if opts.QueueGroup != "" {
+				t.consumeWithGroup(subCtx, stream, opts, handler, sub)
+			} else {
+				t.consumeBroadcast(subCtx, stream, startID, opts, handler, sub)
+			}
+			if sub.cause() != nil {
+				cancel()
+			}
+		})
+		return nil
+	}
+
+	for _, stream := range streams {
+		if err := start(stream, "$"); err != nil {
+			cancel()
+			wg.Wait()
+			return nil, err
+		}
+	}
+
+	go func() {
+		defer func() {
+			wg.Wait()
+			sub.once.Do(func() { close(sub.done) })
+		}()
+
+		ticker := time.NewTicker(redisPatternRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-subCtx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			streams, err := t.scanStreams(subCtx, pattern)
+			if err != nil {
+				if subCtx.Err() == nil {
+					t.logger.Warn("scan streams failed", clog.String("pattern", pattern), clog.Error(err))
+				}
+				continue
+			}
+			for _, stream := range streams {
+				if _, ok := known[stream]; ok {
+					continue
+				}
+				if err := start(stream, "0"); err != nil {
+					t.logger.Warn("subscribe matched stream failed", clog.String("stream", stream), clog.Error(err))
+					continue
+				}
+				t.logger.Info("subscribed matched stream", clog.String("pattern", pattern), clog.String("stream", stream))
+			}
+		}
+	}()
+
+	return sub, nil
+}
+
+// scanStreams 返回与通配符 pattern 匹配的全部 Stream
+func (t *redisStreamTransport) scanStreams(ctx context.Context, pattern string) ([]string, error) {
+	match := topicGlob(pattern)
+	// SCAN 可能重复返回同一个 key
+	seen := make(map[string]struct{})
+	var (
+		streams []string
+		cursor  uint64
+	)
+	for {
+		keys, next, err := t.client.ScanType(ctx, cursor, match, redisPatternScanCount, "stream").Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range keys {
+			if _, ok := seen[key]; ok || !matchesWildcard(pattern, key) {
+				continue
+			}
+			seen[key] = struct{}{}
+			streams = append(streams, key)
+		}
+		cursor = next
+		if cursor == 0 {
+			return streams, nil
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package mq
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+// ResubscribeEvent 自动重订阅事件
+type ResubscribeEvent struct {
+	// Topic 订阅主题
+	Topic string
+	// Attempt 本轮恢复中的第几次重订阅尝试，从 1 开始
+	Attempt int
+	// Cause 触发重订阅的底层错误
+	Cause error
+	// Err 本次重订阅的结果，nil 表示订阅已恢复
+	Err error
+}
+
+// subscriptionHealth 由内置驱动的订阅实现，向上层报告健康状态（内部使用）
+//
+// 未实现该接口的订阅结束后不会被自动重建。
+type subscriptionHealth interface {
+	// healthy 报告订阅当前是否在正常消费
+	healthy() bool
+	// cause 返回订阅异常终止的原因；主动取消或正常结束时返回 nil
+	cause() error
+}
+
+// resubscribingSubscription 在底层订阅异常终止时自动重建订阅
+//
+// 重建时复用同一个 handler 与订阅选项（QueueGroup、Durable 等），
+// 因此消费组与消费进度保持不变。
+type resubscribingSubscription struct {
+	fixedConcurrency
+
+	transport Driver
+	topic     string
+	handler   Handler
+	opts      SubscribeOptions
+	logger    clog.Logger
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu         sync.Mutex
+	current    Subscription
+	recovering atomic.Bool
+}
+
+func newResubscribingSubscription(
+	ctx context.Context,
+	transport Driver,
+	topic string,
+	handler Handler,
+	opts SubscribeOptions,
+	logger clog.Logger,
+) (*resubscribingSubscription, error) {
+	subCtx, cancel := context.WithCancel(ctx)
+	sub, err := transport.Subscribe(subCtx, topic, handler, opts)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	s := &resubscribingSubscription{
+		transport: transport,
+		topic:     topic,
+		handler:   handler,
+		opts:      opts,
+		logger:    logger,
+		ctx:       subCtx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		current:   sub,
+	}
+	go s.supervise()
+	return s, nil
+}
+
+// supervise 等待底层订阅结束，异常终止时重建订阅
+func (s *resubscribingSubscription) supervise() {
+	defer close(s.done)
+
+	for {
+		sub := s.currentSubscription()
+		<-sub.Done()
+
+		if s.ctx.Err() != nil {
+			return
+		}
+		health, ok := sub.(subscriptionHealth)
+		if !ok || health.cause() == nil {
+			return
+		}
+
+		if !s.resubscribe(health.cause()) {
+			return
+		}
+	}
+}
+
+// resubscribe 按固定间隔重试订阅，直到成功或订阅被取消
+func (s *resubscribingSubscription) resubscribe(cause error) bool {
+	s.recovering.Store(true)
+	defer s.recovering.Store(false)
+
+	s.logger.Warn("subscription interrupted, resubscribing",
+		clog.String("topic", s.topic),
+		clog.Error(cause),
+	)
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-s.ctx.Done():
+			return false
+		case <-time.After(s.opts.ResubscribeInterval):
+		}
+
+		sub, err := s.transport.Subscribe(s.ctx, s.topic, s.handler, s.opts)
+		s.notify(ResubscribeEvent{Topic: s.topic, Attempt: attempt, Cause: cause, Err: err})
+		if err != nil {
+			s.logger.Warn("resubscribe failed",
+				clog.String("topic", s.topic),
+				clog.Int("attempt", attempt),
+				clog.Error(err),
+			)
+			continue
+		}
+
+		s.mu.Lock()
+		s.current = sub
+		s.mu.Unlock()
+
+		s.logger.Info("subscription restored",
+			clog.String("topic", s.topic),
+			clog.Int("attempt", attempt),
+		)
+		return true
+	}
+}
+
+func (s *resubscribingSubscription) notify(event ResubscribeEvent) {
+	if s.opts.OnResubscribe != nil {
+		s.opts.OnResubscribe(event)
+	}
+}
+
+func (s *resubscribingSubscription) currentSubscription() Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+func (s *resubscribingSubscription) Unsubscribe() error {
+	s.cancel()
+	return s.currentSubscription().Unsubscribe()
+}
+
+func (s *resubscribingSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *resubscribingSubscription) IsActive() bool {
+	if s.ctx.Err() != nil || s.recovering.Load() {
+		return false
+	}
+
+	sub := s.currentSubscription()
+	select {
+	case <-sub.Done():
+		return false
+	default:
+	}
+	if health, ok := sub.(subscriptionHealth); ok {
+		return health.healthy()
+	}
+	return true
+}
@@ -0,0 +1,207 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// flakyTransport 模拟可断线的 Transport：Publish 直接投递给当前订阅
+type flakyTransport struct {
+	mockTransport
+
+	mu             sync.Mutex
+	current        *flakySubscription
+	subscribeCount int
+	failNext       int
+	opts           []SubscribeOptions
+}
+
+func (f *flakyTransport) Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error {
+	f.mu.Lock()
+	sub := f.current
+	f.mu.Unlock()
+	if sub == nil || !sub.IsActive() {
+		return errors.New("no active subscription")
+	}
+	return sub.handler(&mockMessage{})
+}
+
+func (f *flakyTransport) Subscribe(ctx context.Context, topic string, handler Handler, opts SubscribeOptions) (Subscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.subscribeCount++
+	f.opts = append(f.opts, opts)
+	if f.failNext > 0 {
+		f.failNext--
+		return nil, errors.New("connection refused")
+	}
+
+	sub := newFlakySubscription(ctx, handler)
+	f.current = sub
+	return sub, nil
+}
+
+// disconnect 模拟底层连接断开，当前订阅异常终止
+func (f *flakyTransport) disconnect(failAttempts int) {
+	f.mu.Lock()
+	f.failNext = failAttempts
+	sub := f.current
+	f.mu.Unlock()
+	sub.interrupt(errors.New("connection lost"))
+}
+
+func (f *flakyTransport) subscribeCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.subscribeCount
+}
+
+type flakySubscription struct {
+	fixedConcurrency
+
+	handler Handler
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+func newFlakySubscription(ctx context.Context, handler Handler) *flakySubscription {
+	subCtx, cancel := context.WithCancel(ctx)
+	s := &flakySubscription{handler: handler, cancel: cancel, done: make(chan struct{})}
+	go func() {
+		<-subCtx.Done()
+		close(s.done)
+	}()
+	return s
+}
+
+func (s *flakySubscription) interrupt(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	s.cancel()
+}
+
+func (s *flakySubscription) Unsubscribe() error {
+	s.cancel()
+	return nil
+}
+
+func (s *flakySubscription) Done() <-chan struct{} { return s.done }
+
+func (s *flakySubscription) IsActive() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *flakySubscription) healthy() bool { return true }
+
+func (s *flakySubscription) cause() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func TestMQ_Resubscribe(t *testing.T) {
+	t.Run("连接断开后自动重订阅并继续消费", func(t *testing.T) {
+		transport := &flakyTransport{}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+
+		var received sync.WaitGroup
+		var eventsMu sync.Mutex
+		var events []ResubscribeEvent
+
+		sub, err := m.Subscribe(context.Background(), "orders.created", func(msg Message) error {
+			received.Done()
+			return nil
+		},
+			WithQueueGroup("workers"),
+			WithResubscribeInterval(10*time.Millisecond),
+			WithOnResubscribe(func(event ResubscribeEvent) {
+				eventsMu.Lock()
+				events = append(events, event)
+				eventsMu.Unlock()
+			}),
+		)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = sub.Unsubscribe() })
+		require.True(t, sub.IsActive())
+
+		received.Add(1)
+		require.NoError(t, m.Publish(context.Background(), "orders.created", []byte("1")))
+		received.Wait()
+
+		// 断开连接，且首次重订阅失败
+		transport.disconnect(1)
+		require.Eventually(t, func() bool { return transport.subscribeCalls() == 3 }, time.Second, 5*time.Millisecond)
+		require.Eventually(t, sub.IsActive, time.Second, 5*time.Millisecond)
+
+		received.Add(1)
+		require.NoError(t, m.Publish(context.Background(), "orders.created", []byte("2")))
+		received.Wait()
+
+		eventsMu.Lock()
+		require.Len(t, events, 2)
+		require.Equal(t, "orders.created", events[0].Topic)
+		require.Equal(t, 1, events[0].Attempt)
+		require.Error(t, events[0].Err)
+		require.EqualError(t, events[0].Cause, "connection lost")
+		require.Equal(t, 2, events[1].Attempt)
+		require.NoError(t, events[1].Err)
+		eventsMu.Unlock()
+
+		// 重订阅保持同一 QueueGroup
+		transport.mu.Lock()
+		for _, opts := range transport.opts {
+			require.Equal(t, "workers", opts.QueueGroup)
+		}
+		transport.mu.Unlock()
+	})
+
+	t.Run("重订阅期间 IsActive 为 false", func(t *testing.T) {
+		transport := &flakyTransport{}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+
+		sub, err := m.Subscribe(context.Background(), "orders.created", func(msg Message) error { return nil },
+			WithResubscribeInterval(time.Hour))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = sub.Unsubscribe() })
+
+		transport.disconnect(0)
+		require.Eventually(t, func() bool { return !sub.IsActive() }, time.Second, 5*time.Millisecond)
+		require.Error(t, m.Publish(context.Background(), "orders.created", []byte("1")))
+	})
+
+	t.Run("主动取消不会重订阅", func(t *testing.T) {
+		transport := &flakyTransport{}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+
+		sub, err := m.Subscribe(context.Background(), "orders.created", func(msg Message) error { return nil },
+			WithResubscribeInterval(time.Millisecond))
+		require.NoError(t, err)
+
+		require.NoError(t, sub.Unsubscribe())
+		select {
+		case <-sub.Done():
+		case <-time.After(time.Second):
+			t.Fatal("subscription not done")
+		}
+		require.False(t, sub.IsActive())
+		require.Equal(t, 1, transport.subscribeCalls())
+	})
+}
@@ -0,0 +1,458 @@
+package mq
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ========================================
+// Schema 校验 (Schema Validation)
+// ========================================
+
+// SchemaValidator 校验消息 payload 是否符合 schema
+//
+// 校验失败时应返回包装了 ErrSchemaViolation 的错误，错误信息会写入死信消息的 x-error 头。
+type SchemaValidator interface {
+	Validate(data []byte) error
+}
+
+// SchemaValidatorFunc 函数适配器，便于以闭包形式实现 SchemaValidator
+type SchemaValidatorFunc func(data []byte) error
+
+// Validate 实现 SchemaValidator 接口
+func (f SchemaValidatorFunc) Validate(data []byte) error {
+	return f(data)
+}
+
+// NewProtoValidator 创建基于 protobuf 描述符的校验器
+//
+// payload 必须能按 desc 反序列化，proto2 的 required 字段必须存在，已知字段的
+// wire type 必须与描述符一致。未知字段不视为违规，以兼容生产者先于消费者升级 schema 的场景。
+//
+// 使用示例:
+//
+//	v := mq.NewProtoValidator((&orderpb.OrderCreated{}).ProtoReflect().Descriptor())
+func NewProtoValidator(desc protoreflect.MessageDescriptor) SchemaValidator {
+	return SchemaValidatorFunc(func(data []byte) error {
+		msg := dynamicpb.NewMessage(desc)
+		if err := proto.Unmarshal(data, msg); err != nil {
+			return xerrors.Wrapf(ErrSchemaViolation, "decode %s: %v", desc.FullName(), err)
+		}
+		return checkProtoWireTypes(msg)
+	})
+}
+
+// checkProtoWireTypes 检查已知字段是否因 wire type 不符而落入 unknown fields
+//
+// proto.Unmarshal 遇到 wire type 不符的已知字段时不会报错，而是当作未知字段保留。
+func checkProtoWireTypes(msg protoreflect.Message) error {
+	fields := msg.Descriptor().Fields()
+	unknown := msg.GetUnknown()
+	for len(unknown) > 0 {
+		num, typ, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return xerrors.Wrapf(ErrSchemaViolation, "decode %s: malformed unknown field", msg.Descriptor().FullName())
+		}
+		if fd := fields.ByNumber(num); fd != nil {
+			return xerrors.Wrapf(ErrSchemaViolation, "%s.%s: unexpected wire type %d", msg.Descriptor().FullName(), fd.Name(), typ)
+		}
+		m := protowire.ConsumeFieldValue(num, typ, unknown[n:])
+		if m < 0 {
+			return xerrors.Wrapf(ErrSchemaViolation, "decode %s: malformed unknown field", msg.Descriptor().FullName())
+		}
+		unknown = unknown[n+m:]
+	}
+
+	var err error
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Message() == nil {
+			return true
+		}
+		switch {
+		case fd.IsList():
+			for i := 0; i < v.List().Len() && err == nil; i++ {
+				err = checkProtoWireTypes(v.List().Get(i).Message())
+			}
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					err = checkProtoWireTypes(mv.Message())
+					return err == nil
+				})
+			}
+		default:
+			err = checkProtoWireTypes(v.Message())
+		}
+		return err == nil
+	})
+	return err
+}
+
+// NewJSONSchemaValidator 创建 JSON Schema 校验器
+//
+// 支持 JSON Schema 的常用子集：type、properties、required、additionalProperties、
+// items、enum、const、minimum、maximum、exclusiveMinimum、exclusiveMaximum、
+// minLength、maxLength、pattern、minItems、maxItems。
+// $schema、$id、title、description 等注解关键字会被忽略；
+// 其他关键字（如 $ref、oneOf）不支持，创建时返回 ErrInvalidConfig，避免"看似校验、实则放行"。
+func NewJSONSchemaValidator(schema []byte) (SchemaValidator, error) {
+	var raw any
+	if err := json.Unmarshal(schema, &raw); err != nil {
+		return nil, xerrors.Wrapf(ErrInvalidConfig, "parse json schema: %v", err)
+	}
+	s, err := compileJSONSchema(raw, "$")
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// jsonSchema 编译后的 JSON Schema 节点
+type jsonSchema struct {
+	types                      []string
+	properties                 map[string]*jsonSchema
+	required                   []string
+	additional                 *jsonSchema
+	noAdditional               bool
+	items                      *jsonSchema
+	enum                       []any
+	hasConst                   bool
+	constValue                 any
+	minimum, maximum           *float64
+	exclusiveMin, exclusiveMax *float64
+	minLength, maxLength       *int
+	pattern                    *regexp.Regexp
+	minItems, maxItems         *int
+}
+
+// jsonSchemaAnnotations 不参与校验的注解关键字
+var jsonSchemaAnnotations = map[string]struct{}{
+	"$schema": {}, "$id": {}, "$comment": {}, "title": {}, "description": {},
+	"default": {}, "examples": {}, "deprecated": {}, "readOnly": {}, "writeOnly": {},
+}
+
+func compileJSONSchema(raw any, path string) (*jsonSchema, error) {
+	if b, ok := raw.(bool); ok {
+		// true 接受任意值，false 拒绝任意值
+		if b {
+			return &jsonSchema{}, nil
+		}
+		return &jsonSchema{enum: []any{}}, nil
+	}
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return nil, xerrors.Wrapf(ErrInvalidConfig, "json schema %s: must be an object or boolean", path)
+	}
+
+	invalid := func(keyword string) error {
+		return xerrors.Wrapf(ErrInvalidConfig, "json schema %s: invalid %s", path, keyword)
+	}
+
+	s := &jsonSchema{}
+	for key, val := range obj {
+		var err error
+		switch key {
+		case "type":
+			switch t := val.(type) {
+			case string:
+				s.types = []string{t}
+			case []any:
+				for _, item := range t {
+					name, ok := item.(string)
+					if !ok {
+						return nil, invalid(key)
+					}
+					s.types = append(s.types, name)
+				}
+			default:
+				return nil, invalid(key)
+			}
+		case "properties":
+			props, ok := val.(map[string]any)
+			if !ok {
+				return nil, invalid(key)
+			}
+			s.properties = make(map[string]*jsonSchema, len(props))
+			for name, sub := range props {
+				if s.properties[name], err = compileJSONSchema(sub, path+"."+name); err != nil {
+					return nil, err
+				}
+			}
+		case "required":
+			list, ok := val.([]any)
+			if !ok {
+				return nil, invalid(key)
+			}
+			for _, item := range list {
+				name, ok := item.(string)
+				if !ok {
+					return nil, invalid(key)
+				}
+				s.required = append(s.required, name)
+			}
+		case "additionalProperties":
+			if b, ok := val.(bool); ok {
+				s.noAdditional = !b
+				continue
+			}
+			if s.additional, err = compileJSONSchema(val, path+".*"); err != nil {
+				return nil, err
+			}
+		case "items":
+			if s.items, err = compileJSONSchema(val, path+"[]"); err != nil {
+				return nil, err
+			}
+		case "enum":
+			list, ok := val.([]any)
+			if !ok {
+				return nil, invalid(key)
+			}
+			s.enum = list
+		case "const":
+			s.hasConst, s.constValue = true, val
+		case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum":
+			n, ok := val.(float64)
+			if !ok {
+				return nil, invalid(key)
+			}
+			switch key {
+			case "minimum":
+				s.minimum = &n
+			case "maximum":
+				s.maximum = &n
+			case "exclusiveMinimum":
+				s.exclusiveMin = &n
+			default:
+				s.exclusiveMax = &n
+			}
+		case "minLength", "maxLength", "minItems", "maxItems":
+			n, ok := val.(float64)
+			if !ok || n < 0 || n != math.Trunc(n) {
+				return nil, invalid(key)
+			}
+			v := int(n)
+			switch key {
+			case "minLength":
+				s.minLength = &v
+			case "maxLength":
+				s.maxLength = &v
+			case "minItems":
+				s.minItems = &v
+			default:
+				s.maxItems = &v
+			}
+		case "pattern":
+			expr, ok := val.(string)
+			if !ok {
+				return nil, invalid(key)
+			}
+			if s.pattern, err = regexp.Compile(expr); err != nil {
+				return nil, invalid(key)
+			}
+		default:
+			if _, ok := jsonSchemaAnnotations[key]; !ok {
+				return nil, xerrors.Wrapf(ErrInvalidConfig, "json schema %s: unsupported keyword %q", path, key)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Validate 实现 SchemaValidator 接口
+func (s *jsonSchema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return xerrors.Wrapf(ErrSchemaViolation, "invalid json: %v", err)
+	}
+	if dec.More() {
+		return xerrors.Wrap(ErrSchemaViolation, "invalid json: trailing data")
+	}
+	return s.validate(v, "$")
+}
+
+func (s *jsonSchema) validate(v any, path string) error {
+	violation := func(format string, args ...any) error {
+		return xerrors.Wrap(ErrSchemaViolation, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(s.types) > 0 && !matchJSONType(v, s.types) {
+		return violation("expected type %v, got %s", s.types, jsonTypeOf(v))
+	}
+	if s.enum != nil && !containsJSONValue(s.enum, v) {
+		return violation("value not in enum")
+	}
+	if s.hasConst && !reflect.DeepEqual(s.constValue, v) {
+		return violation("value does not match const")
+	}
+
+	switch val := v.(type) {
+	case float64:
+		if s.minimum != nil && val < *s.minimum {
+			return violation("%v is less than minimum %v", val, *s.minimum)
+		}
+		if s.maximum != nil && val > *s.maximum {
+			return violation("%v is greater than maximum %v", val, *s.maximum)
+		}
+		if s.exclusiveMin != nil && val <= *s.exclusiveMin {
+			return violation("%v is not greater than %v", val, *s.exclusiveMin)
+		}
+		if s.exclusiveMax != nil && val >= *s.exclusiveMax {
+			return violation("%v is not less than %v", val, *s.exclusiveMax)
+		}
+	case string:
+		n := utf8.RuneCountInString(val)
+		if s.minLength != nil && n < *s.minLength {
+			return violation("length %d is less than minLength %d", n, *s.minLength)
+		}
+		if s.maxLength != nil && n > *s.maxLength {
+			return violation("length %d is greater than maxLength %d", n, *s.maxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			return violation("does not match pattern %q", s.pattern.String())
+		}
+	case []any:
+		if s.minItems != nil && len(val) < *s.minItems {
+			return violation("%d items is less than minItems %d", len(val), *s.minItems)
+		}
+		if s.maxItems != nil && len(val) > *s.maxItems {
+			return violation("%d items is greater than maxItems %d", len(val), *s.maxItems)
+		}
+		if s.items != nil {
+			for i, item := range val {
+				if err := s.items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		for _, name := range s.required {
+			if _, ok := val[name]; !ok {
+				return violation("missing required property %q", name)
+			}
+		}
+		// 按名称排序，保证报告的违规字段稳定
+		names := make([]string, 0, len(val))
+		for name := range val {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			sub, ok := s.properties[name]
+			switch {
+			case ok:
+			case s.additional != nil:
+				sub = s.additional
+			case s.noAdditional:
+				return violation("additional property %q is not allowed", name)
+			default:
+				continue
+			}
+			if err := sub.validate(val[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func jsonTypeOf(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func matchJSONType(v any, types []string) bool {
+	actual := jsonTypeOf(v)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsJSONValue(values []any, v any) bool {
+	for _, candidate := range values {
+		if reflect.DeepEqual(candidate, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateSchema 校验消息，不符合 schema 时发送到死信队列并确认原消息
+//
+// 返回 true 表示消息已被拒绝，调用方不应再调用 Handler。
+// 死信发送成功后确认原消息并返回 nil；发送失败时返回错误，原消息不确认，等待重投。
+func (m *mq) validateSchema(topic string, msg Message, opts SubscribeOptions) (bool, error) {
+	verr := opts.Schema.Validate(msg.Data())
+	if verr == nil {
+		return false, nil
+	}
+
+	dlTopic := opts.SchemaDeadLetter
+	if dlTopic == "" {
+		dlTopic = topic + ".DLQ"
+	}
+
+	headers := msg.Headers().Clone()
+	if headers == nil {
+		headers = make(Headers)
+	}
+	headers.Set("x-original-topic", topic)
+	headers.Set("x-error", verr.Error())
+
+	if err := m.Publish(msg.Context(), dlTopic, msg.Data(), WithHeaders(headers)); err != nil {
+		m.logger.Error("failed to send invalid message to dead letter queue",
+			clog.String("topic", topic),
+			clog.String("dlq_topic", dlTopic),
+			clog.String("msg_id", msg.ID()),
+			clog.Error(err),
+		)
+		return true, err
+	}
+
+	m.logger.Warn("message rejected by schema, sent to dead letter queue",
+		clog.String("topic", topic),
+		clog.String("dlq_topic", dlTopic),
+		clog.String("msg_id", msg.ID()),
+		clog.Error(verr),
+	)
+
+	if err := msg.Ack(); err != nil {
+		m.logger.Error("failed to ack message after sending to DLQ",
+			clog.String("msg_id", msg.ID()),
+			clog.Error(err),
+		)
+	}
+	return true, nil
+}
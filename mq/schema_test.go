@@ -0,0 +1,156 @@
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// dataMessage 携带自定义 payload 的 mock 消息
+type dataMessage struct {
+	mockMessage
+	data []byte
+}
+
+func (m *dataMessage) Data() []byte { return m.data }
+
+const orderSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["id", "amount"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "string", "pattern": "^ord-[0-9]+$"},
+		"amount": {"type": "number", "exclusiveMinimum": 0},
+		"status": {"enum": ["created", "paid"]},
+		"items": {"type": "array", "minItems": 1, "items": {"type": "integer"}}
+	}
+}`
+
+func TestMQ_Schema(t *testing.T) {
+	validator, err := NewJSONSchemaValidator([]byte(orderSchema))
+	require.NoError(t, err)
+
+	t.Run("不符合 schema 的消息进死信且不调用 Handler", func(t *testing.T) {
+		transport := &mockTransport{}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+
+		called := false
+		_, err := m.Subscribe(context.Background(), "orders.created", func(msg Message) error {
+			called = true
+			return nil
+		}, WithAutoAck(), WithSchema(validator))
+		require.NoError(t, err)
+
+		msg := &dataMessage{data: []byte(`{"id": "ord-1", "amount": -5}`)}
+		require.NoError(t, transport.handler(msg))
+
+		require.False(t, called, "handler should not be called for invalid message")
+		require.True(t, transport.publishCalled)
+		require.Equal(t, "orders.created.DLQ", transport.lastTopic)
+		require.Equal(t, msg.data, transport.lastData)
+		require.Equal(t, "orders.created", transport.lastPublishOpts.Headers.Get("x-original-topic"))
+		require.Contains(t, transport.lastPublishOpts.Headers.Get("x-error"), "$.amount")
+		require.Equal(t, "abc123", transport.lastPublishOpts.Headers.Get("trace-id"))
+		require.True(t, msg.ackCalled, "invalid message should be acked after sent to DLQ")
+		require.False(t, msg.nakCalled)
+	})
+
+	t.Run("符合 schema 的消息正常处理", func(t *testing.T) {
+		transport := &mockTransport{}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+
+		var got []byte
+		_, err := m.Subscribe(context.Background(), "orders.created", func(msg Message) error {
+			got = msg.Data()
+			return nil
+		}, WithAutoAck(), WithSchema(validator), WithSchemaDeadLetter("orders.invalid"))
+		require.NoError(t, err)
+
+		msg := &dataMessage{data: []byte(`{"id": "ord-1", "amount": 9.5, "status": "paid", "items": [1, 2]}`)}
+		require.NoError(t, transport.handler(msg))
+
+		require.Equal(t, msg.data, got)
+		require.False(t, transport.publishCalled)
+		require.True(t, msg.ackCalled)
+	})
+
+	t.Run("死信发送失败时不确认原消息", func(t *testing.T) {
+		transport := &mockTransport{publishError: ErrClosed}
+		m := newMQ(transport, clog.Discard(), metrics.Discard())
+
+		_, err := m.Subscribe(context.Background(), "orders.created", func(msg Message) error {
+			t.Fatal("handler should not be called")
+			return nil
+		}, WithSchema(validator), WithSchemaDeadLetter("orders.invalid"))
+		require.NoError(t, err)
+
+		msg := &dataMessage{data: []byte(`not json`)}
+		require.ErrorIs(t, transport.handler(msg), ErrClosed)
+		require.Equal(t, "orders.invalid", transport.lastTopic)
+		require.False(t, msg.ackCalled)
+	})
+}
+
+func TestJSONSchemaValidator(t *testing.T) {
+	validator, err := NewJSONSchemaValidator([]byte(orderSchema))
+	require.NoError(t, err)
+
+	valid := []string{
+		`{"id": "ord-1", "amount": 1}`,
+		`{"id": "ord-42", "amount": 0.01, "items": [3]}`,
+	}
+	for _, data := range valid {
+		require.NoError(t, validator.Validate([]byte(data)), data)
+	}
+
+	invalid := map[string]string{
+		"非 JSON":      `{"id":`,
+		"类型错误":        `[]`,
+		"缺少必填字段":      `{"id": "ord-1"}`,
+		"pattern 不匹配": `{"id": "x-1", "amount": 1}`,
+		"枚举不匹配":       `{"id": "ord-1", "amount": 1, "status": "unknown"}`,
+		"不允许额外字段":     `{"id": "ord-1", "amount": 1, "extra": true}`,
+		"数组元素类型错误":    `{"id": "ord-1", "amount": 1, "items": [1.5]}`,
+		"数组为空":        `{"id": "ord-1", "amount": 1, "items": []}`,
+		"尾随数据":        `{"id": "ord-1", "amount": 1} {}`,
+	}
+	for name, data := range invalid {
+		require.ErrorIs(t, validator.Validate([]byte(data)), ErrSchemaViolation, name)
+	}
+
+	// 不支持的关键字在创建时报错
+	_, err = NewJSONSchemaValidator([]byte(`{"oneOf": [{"type": "string"}]}`))
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	_, err = NewJSONSchemaValidator([]byte(`{"type": 1}`))
+	require.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestProtoValidator(t *testing.T) {
+	validator := NewProtoValidator((&durationpb.Duration{}).ProtoReflect().Descriptor())
+
+	data, err := proto.Marshal(durationpb.New(3 * time.Second))
+	require.NoError(t, err)
+	require.NoError(t, validator.Validate(data))
+
+	require.ErrorIs(t, validator.Validate([]byte("not a protobuf payload")), ErrSchemaViolation)
+
+	// 未知字段（生产者新增字段）不视为违规
+	withUnknown := protowire.AppendTag(append([]byte(nil), data...), 99, protowire.VarintType)
+	withUnknown = protowire.AppendVarint(withUnknown, 1)
+	require.NoError(t, validator.Validate(withUnknown))
+
+	// 类型不匹配：Struct 的字段 1 是 map，按 Duration 解码时 wire type 不符
+	other, err := proto.Marshal(&structpb.Struct{Fields: map[string]*structpb.Value{"k": structpb.NewStringValue("v")}})
+	require.NoError(t, err)
+	require.ErrorIs(t, validator.Validate(other), ErrSchemaViolation)
+}
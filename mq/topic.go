@@ -0,0 +1,79 @@
+package mq
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 主题通配符统一采用 NATS 语法，按 "." 分段：
+//   - "*" 匹配恰好一段，如 orders.* 匹配 orders.created，不匹配 orders.eu.created
+//   - ">" 只能出现在末尾，匹配剩余一段或多段，如 orders.> 匹配 orders.eu.created
+//
+// 各驱动的实现方式：NATS 原生支持；Kafka 转换为正则订阅；Redis Stream 定期扫描匹配的 Stream。
+
+// isWildcardTopic 判断 topic 是否包含通配符段
+func isWildcardTopic(topic string) bool {
+	for _, part := range strings.Split(topic, ".") {
+		if part == "*" || part == ">" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWildcard 检查通配符 subject 是否匹配 topic
+// 例如 "orders.*" 匹配 "orders.created"
+func matchesWildcard(pattern, topic string) bool {
+	if pattern == topic {
+		return true
+	}
+	patternParts := strings.Split(pattern, ".")
+	topicParts := strings.Split(topic, ".")
+
+	for i, p := range patternParts {
+		if p == ">" {
+			return i < len(topicParts) // > 至少匹配一段
+		}
+		if i >= len(topicParts) {
+			return false
+		}
+		if p != "*" && p != topicParts[i] {
+			return false
+		}
+	}
+	return len(patternParts) == len(topicParts)
+}
+
+// topicRegexp 将通配符 topic 转换为等价的锚定正则表达式
+func topicRegexp(topic string) string {
+	parts := strings.Split(topic, ".")
+	for i, p := range parts {
+		switch p {
+		case "*":
+			parts[i] = `[^.]+`
+		case ">":
+			parts[i] = `.+`
+		default:
+			parts[i] = regexp.QuoteMeta(p)
+		}
+	}
+	return "^" + strings.Join(parts, `\.`) + "$"
+}
+
+// redisGlobEscaper 转义 Redis glob 中的特殊字符
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`, `]`, `\]`)
+
+// topicGlob 将通配符 topic 转换为 Redis SCAN MATCH 使用的 glob
+//
+// glob 的 "*" 会跨越 "."，结果只用于初筛，需再经 matchesWildcard 精确匹配。
+func topicGlob(topic string) string {
+	parts := strings.Split(topic, ".")
+	for i, p := range parts {
+		if p == "*" || p == ">" {
+			parts[i] = "*"
+		} else {
+			parts[i] = redisGlobEscaper.Replace(p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,225 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+func TestIsWildcardTopic(t *testing.T) {
+	require.True(t, isWildcardTopic("orders.*"))
+	require.True(t, isWildcardTopic("orders.>"))
+	require.True(t, isWildcardTopic("orders.*.created"))
+	require.False(t, isWildcardTopic("orders.created"))
+	require.False(t, isWildcardTopic("orders.cre*ted"), "段内的 * 不是通配符")
+}
+
+func TestMatchesWildcard(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		want    bool
+	}{
+		{"orders.*", "orders.created", true},
+		{"orders.*", "orders.paid", true},
+		{"orders.*", "orders.eu.created", false},
+		{"orders.*", "payments.created", false},
+		{"orders.>", "orders.eu.created", true},
+		{"orders.>", "orders", false},
+		{"orders.*.created", "orders.eu.created", true},
+		{"orders.*.created", "orders.eu.paid", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+"|"+tt.topic, func(t *testing.T) {
+			require.Equal(t, tt.want, matchesWildcard(tt.pattern, tt.topic))
+		})
+	}
+}
+
+func TestTopicRegexp(t *testing.T) {
+	star := regexp.MustCompile(topicRegexp("orders.*"))
+	require.True(t, star.MatchString("orders.created"))
+	require.False(t, star.MatchString("orders.eu.created"))
+	require.False(t, star.MatchString("xorders.created"))
+
+	tail := regexp.MustCompile(topicRegexp("orders.>"))
+	require.True(t, tail.MatchString("orders.eu.created"))
+	require.False(t, tail.MatchString("orders"))
+
+	literal := regexp.MustCompile(topicRegexp("a+b.*"))
+	require.True(t, literal.MatchString("a+b.x"))
+	require.False(t, literal.MatchString("aab.x"), "字面量段中的正则元字符需要转义")
+}
+
+func TestTopicGlob(t *testing.T) {
+	require.Equal(t, "orders.*", topicGlob("orders.*"))
+	require.Equal(t, "orders.*.created", topicGlob("orders.*.created"))
+	require.Equal(t, "orders.*", topicGlob("orders.>"))
+	require.Equal(t, `a\?b.*`, topicGlob("a?b.*"))
+}
+
+func TestMergeSubjects(t *testing.T) {
+	require.Equal(t, []string{"orders.created", "orders.paid"}, mergeSubjects([]string{"orders.created"}, "orders.paid"))
+	require.Equal(t, []string{"orders.eu.created", "orders.*"},
+		mergeSubjects([]string{"orders.created", "orders.eu.created", "orders.paid"}, "orders.*"))
+}
+
+// ============================================================
+// SubscribeMany 测试
+// ============================================================
+
+// routingTransport 按通配符规则在内存中分发消息的 Transport
+type routingTransport struct {
+	mu        sync.Mutex
+	subs      []*routingSubscription
+	failTopic string
+}
+
+func (r *routingTransport) Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error {
+	r.mu.Lock()
+	subs := append([]*routingSubscription(nil), r.subs...)
+	r.mu.Unlock()
+
+	for _, sub := range subs {
+		if sub.active() && matchesWildcard(sub.topic, topic) {
+			_ = sub.handler(&routedMessage{topic: topic, data: data})
+		}
+	}
+	return nil
+}
+
+func (r *routingTransport) Subscribe(ctx context.Context, topic string, handler Handler, opts SubscribeOptions) (Subscription, error) {
+	if topic == r.failTopic {
+		return nil, errors.New("subscribe failed")
+	}
+	sub := &routingSubscription{topic: topic, handler: handler, done: make(chan struct{})}
+	r.mu.Lock()
+	r.subs = append(r.subs, sub)
+	r.mu.Unlock()
+	return sub, nil
+}
+
+func (r *routingTransport) Capabilities() Capabilities {
+	return Capabilities{}
+}
+
+func (r *routingTransport) Close() error {
+	return nil
+}
+
+type routingSubscription struct {
+	fixedConcurrency
+
+	topic   string
+	handler Handler
+	done    chan struct{}
+	once    sync.Once
+}
+
+func (s *routingSubscription) active() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *routingSubscription) Unsubscribe() error {
+	s.once.Do(func() { close(s.done) })
+	return nil
+}
+
+func (s *routingSubscription) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *routingSubscription) IsActive() bool {
+	return s.active()
+}
+
+type routedMessage struct {
+	mockMessage
+	topic string
+	data  []byte
+}
+
+func (m *routedMessage) Topic() string {
+	return m.topic
+}
+
+func (m *routedMessage) Data() []byte {
+	return m.data
+}
+
+func TestMQ_SubscribeWildcard(t *testing.T) {
+	mq := newMQ(&routingTransport{}, clog.Discard(), metrics.Discard())
+	ctx := context.Background()
+
+	var got []string
+	sub, err := mq.Subscribe(ctx, "orders.*", func(msg Message) error {
+		got = append(got, msg.Topic())
+		return nil
+	})
+	require.NoError(t, err)
+	defer sub.Unsubscribe()
+
+	require.NoError(t, mq.Publish(ctx, "orders.created", nil))
+	require.NoError(t, mq.Publish(ctx, "orders.paid", nil))
+	require.NoError(t, mq.Publish(ctx, "payments.created", nil))
+
+	require.Equal(t, []string{"orders.created", "orders.paid"}, got)
+}
+
+func TestMQ_SubscribeMany(t *testing.T) {
+	t.Run("多主题各自收到消息", func(t *testing.T) {
+		mq := newMQ(&routingTransport{}, clog.Discard(), metrics.Discard())
+		ctx := context.Background()
+
+		var got []string
+		sub, err := mq.SubscribeMany(ctx, []string{"orders.created", "payments.*", "orders.created"}, func(msg Message) error {
+			got = append(got, msg.Topic()+":"+string(msg.Data()))
+			return nil
+		})
+		require.NoError(t, err)
+		require.True(t, sub.IsActive())
+
+		require.NoError(t, mq.Publish(ctx, "orders.created", []byte("1")))
+		require.NoError(t, mq.Publish(ctx, "payments.done", []byte("2")))
+		require.NoError(t, mq.Publish(ctx, "orders.paid", []byte("3")))
+		require.Equal(t, []string{"orders.created:1", "payments.done:2"}, got, "重复主题只订阅一次，不匹配的主题收不到")
+
+		require.NoError(t, sub.Unsubscribe())
+		waitTimeout(t, sub.Done(), time.Second)
+		require.False(t, sub.IsActive())
+
+		require.NoError(t, mq.Publish(ctx, "orders.created", []byte("4")))
+		require.Len(t, got, 2)
+	})
+
+	t.Run("主题为空", func(t *testing.T) {
+		mq := newMQ(&routingTransport{}, clog.Discard(), metrics.Discard())
+		_, err := mq.SubscribeMany(context.Background(), nil, func(msg Message) error { return nil })
+		require.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	t.Run("部分失败时取消已建立的订阅", func(t *testing.T) {
+		transport := &routingTransport{failTopic: "payments.*"}
+		mq := newMQ(transport, clog.Discard(), metrics.Discard())
+
+		sub, err := mq.SubscribeMany(context.Background(), []string{"orders.created", "payments.*"}, func(msg Message) error { return nil })
+		require.Error(t, err)
+		require.Nil(t, sub)
+
+		require.Len(t, transport.subs, 1)
+		require.False(t, transport.subs[0].IsActive())
+	})
+}
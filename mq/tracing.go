@@ -0,0 +1,101 @@
+package mq
+
+import (
+	"context"
+
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/ceyewan/genesis/trace"
+)
+
+// tracerName mq 创建 span 时使用的 instrumentation 名称
+const tracerName = "github.com/ceyewan/genesis/mq"
+
+// tracedMessage 把消费 span 所在的 ctx 挂到消息上，Handler 通过 msg.Context() 即可拿到
+type tracedMessage struct {
+	Message
+	ctx context.Context
+}
+
+func (m *tracedMessage) Context() context.Context {
+	return m.ctx
+}
+
+// startPublishSpan 创建 producer span 并把 trace 上下文写入消息头，未注入 tracer 时原样返回
+//
+// ctx 本身没有 span 而消息头已携带 trace 上下文时（如 outbox relay 转发），以消息头中的上游为父，
+// 避免链路在转发处断开。
+func (m *mq) startPublishSpan(ctx context.Context, topic string, o *PublishOptions) (context.Context, oteltrace.Span) {
+	if m.tracer == nil {
+		return ctx, nil
+	}
+	if len(o.Headers) > 0 && !oteltrace.SpanContextFromContext(ctx).IsValid() {
+		ctx = trace.Extract(ctx, o.Headers)
+	}
+
+	ctx, span, headers := trace.StartProducerSpan(ctx, m.tracer, trace.SpanNameMQPublish(topic), trace.MessagingMeta{
+		System:      m.driver,
+		Destination: topic,
+		Operation:   trace.MessagingOperationPublish,
+	})
+	if o.Headers == nil {
+		o.Headers = make(Headers, len(headers))
+	}
+	for k, v := range headers {
+		o.Headers[k] = v
+	}
+	return ctx, span
+}
+
+// startConsumeSpan 从消息头提取上游 trace 并创建 consumer span，返回挂上 span 的消息
+func (m *mq) startConsumeSpan(topic string, msg Message, opts SubscribeOptions) (Message, oteltrace.Span) {
+	if m.tracer == nil {
+		return msg, nil
+	}
+	ctx, span := trace.StartConsumerSpanFromHeaders(msg.Context(), m.tracer, trace.SpanNameMQConsume(topic), msg.Headers(), trace.MessagingMeta{
+		System:        m.driver,
+		Destination:   topic,
+		Operation:     trace.MessagingOperationProcess,
+		ConsumerGroup: opts.QueueGroup,
+		TraceRelation: m.traceRelation,
+	})
+	return &tracedMessage{Message: msg, ctx: ctx}, span
+}
+
+// startBatchConsumeSpan 为一批消息创建一个 consumer span，通过 link 关联每条消息的上游 span
+func (m *mq) startBatchConsumeSpan(topic string, msgs []Message) ([]Message, oteltrace.Span) {
+	if m.tracer == nil {
+		return msgs, nil
+	}
+
+	links := make([]oteltrace.Link, 0, len(msgs))
+	for _, msg := range msgs {
+		remote := oteltrace.SpanContextFromContext(trace.Extract(context.Background(), msg.Headers()))
+		if remote.IsValid() {
+			links = append(links, oteltrace.Link{SpanContext: remote})
+		}
+	}
+	ctx, span := trace.StartConsumerSpanFromHeaders(msgs[0].Context(), m.tracer, trace.SpanNameMQConsume(topic), nil, trace.MessagingMeta{
+		System:      m.driver,
+		Destination: topic,
+		Operation:   trace.MessagingOperationProcess,
+	})
+	for _, link := range links {
+		span.AddLink(link)
+	}
+
+	traced := make([]Message, len(msgs))
+	for i, msg := range msgs {
+		traced[i] = &tracedMessage{Message: msg, ctx: ctx}
+	}
+	return traced, span
+}
+
+// endSpan 按结果标记并结束 span，span 为 nil 时为空操作
+func endSpan(span oteltrace.Span, err error) {
+	if span == nil {
+		return
+	}
+	trace.MarkSpanError(span, err)
+	span.End()
+}
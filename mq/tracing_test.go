@@ -0,0 +1,126 @@
+package mq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/ceyewan/genesis/trace"
+)
+
+// setupTracing 创建记录 span 的内存 TracerProvider，并安装全局 TraceContext 传播器
+func setupTracing(t *testing.T) (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(prev)
+		_ = tp.Shutdown(context.Background())
+	})
+	return tp, recorder
+}
+
+// spanByKind 返回第一个指定类型的已结束 span
+func spanByKind(t *testing.T, recorder *tracetest.SpanRecorder, kind oteltrace.SpanKind) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, s := range recorder.Ended() {
+		if s.SpanKind() == kind {
+			return s
+		}
+	}
+	t.Fatalf("no %s span recorded", kind)
+	return nil
+}
+
+func TestTracing_PublishConsume(t *testing.T) {
+	tp, recorder := setupTracing(t)
+	m, err := New(&Config{}, WithDriver(newMemoryDriver()), WithTracer(tp))
+	require.NoError(t, err)
+	defer m.Close()
+
+	var handlerSpan oteltrace.SpanContext
+	var headers Headers
+	_, err = m.Subscribe(context.Background(), "orders.created", func(msg Message) error {
+		handlerSpan = oteltrace.SpanContextFromContext(msg.Context())
+		headers = msg.Headers()
+		return nil
+	}, WithAutoAck())
+	require.NoError(t, err)
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "create order")
+	require.NoError(t, m.Publish(ctx, "orders.created", []byte("1"), WithHeader("biz", "order")))
+	parent.End()
+
+	producer := spanByKind(t, recorder, oteltrace.SpanKindProducer)
+	consumer := spanByKind(t, recorder, oteltrace.SpanKindConsumer)
+
+	// publish 挂在调用方 span 下，consume 是 publish 的子 span，三者同属一条 trace
+	require.Equal(t, trace.SpanNameMQPublish("orders.created"), producer.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), producer.Parent().SpanID())
+	require.Equal(t, producer.SpanContext().TraceID(), consumer.SpanContext().TraceID())
+	require.Equal(t, producer.SpanContext().SpanID(), consumer.Parent().SpanID())
+
+	// Handler 的 ctx 已带 consumer span，业务头不受影响
+	require.Equal(t, consumer.SpanContext().SpanID(), handlerSpan.SpanID())
+	require.Equal(t, "order", headers.Get("biz"))
+	require.NotEmpty(t, headers.Get("traceparent"))
+}
+
+func TestTracing_LinkRelation(t *testing.T) {
+	tp, recorder := setupTracing(t)
+	m, err := New(&Config{}, WithDriver(newMemoryDriver()), WithTracer(tp),
+		WithTraceRelation(trace.MessagingTraceRelationLink))
+	require.NoError(t, err)
+	defer m.Close()
+
+	_, err = m.Subscribe(context.Background(), "orders.created", func(Message) error { return nil }, WithAutoAck())
+	require.NoError(t, err)
+	require.NoError(t, m.Publish(context.Background(), "orders.created", []byte("1")))
+
+	producer := spanByKind(t, recorder, oteltrace.SpanKindProducer)
+	consumer := spanByKind(t, recorder, oteltrace.SpanKindConsumer)
+
+	require.NotEqual(t, producer.SpanContext().TraceID(), consumer.SpanContext().TraceID())
+	require.Len(t, consumer.Links(), 1)
+	require.Equal(t, producer.SpanContext().TraceID(), consumer.Links()[0].SpanContext.TraceID())
+	require.Equal(t, producer.SpanContext().SpanID(), consumer.Links()[0].SpanContext.SpanID())
+}
+
+func TestTracing_HandlerError(t *testing.T) {
+	tp, recorder := setupTracing(t)
+	m, err := New(&Config{}, WithDriver(newMemoryDriver()), WithTracer(tp))
+	require.NoError(t, err)
+	defer m.Close()
+
+	_, err = m.Subscribe(context.Background(), "orders.created", func(Message) error { return ErrInvalidConfig })
+	require.NoError(t, err)
+	require.NoError(t, m.Publish(context.Background(), "orders.created", []byte("1")))
+
+	consumer := spanByKind(t, recorder, oteltrace.SpanKindConsumer)
+	require.Equal(t, "Error", consumer.Status().Code.String())
+}
+
+func TestTracing_Disabled(t *testing.T) {
+	setupTracing(t)
+	m, err := New(&Config{}, WithDriver(newMemoryDriver()))
+	require.NoError(t, err)
+	defer m.Close()
+
+	var headers Headers
+	_, err = m.Subscribe(context.Background(), "orders.created", func(msg Message) error {
+		headers = msg.Headers()
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, m.Publish(context.Background(), "orders.created", []byte("1")))
+	require.Empty(t, headers.Get("traceparent"), "未注入 tracer 时不写入 trace 头")
+}
@@ -0,0 +1,247 @@
+package mq
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+const (
+	// redisHalfKeyPrefix Redis Stream 半消息的暂存 key 前缀
+	redisHalfKeyPrefix = "mq:half:"
+	// redisHalfMessageTTL 半消息暂存时长，本地事务超过该时长未结束时提交失败
+	redisHalfMessageTTL = 10 * time.Minute
+)
+
+// txTransport 由支持事务消息（半消息）的内置驱动实现（内部使用）
+//
+// 仅在 Capabilities().Transaction 为 true 时被调用；其余驱动在配置 WithTransactionOutbox 后
+// 以 outbox 表暂存半消息，否则返回 ErrNotSupported。
+type txTransport interface {
+	// PrepareMessage 写入对消费者不可见的半消息
+	PrepareMessage(ctx context.Context, topic string, data []byte, opts PublishOptions) (halfMessage, error)
+}
+
+// halfMessage 已写入但尚未对消费者可见的半消息（内部使用）
+type halfMessage interface {
+	// Commit 使消息对消费者可见
+	Commit(ctx context.Context) error
+	// Rollback 删除半消息
+	Rollback(ctx context.Context) error
+}
+
+// WithTransactionOutbox 为不支持半消息的驱动启用 outbox 降级
+//
+// db 中需已通过 MigrateOutbox 建表。PublishInTransaction 把半消息写入 outbox 表（prepared 状态，
+// relay 不会投递），本地事务成功后转为待投递并立即尝试发布，发布失败时由 OutboxRelay 重试。
+func WithTransactionOutbox(db *gorm.DB) Option {
+	return func(o *options) {
+		o.txOutbox = db
+	}
+}
+
+// PublishInTransaction 发布事务消息
+func (m *mq) PublishInTransaction(ctx context.Context, topic string, data []byte, localTx func() error, opts ...PublishOption) (err error) {
+	if m.closed.Load() {
+		return ErrClosed
+	}
+	if localTx == nil {
+		return xerrors.Wrap(ErrInvalidConfig, "local transaction is nil")
+	}
+
+	o := defaultPublishOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	// span 覆盖半消息准备、本地事务与提交，trace 上下文随半消息一起写入
+	ctx, span := m.startPublishSpan(ctx, topic, &o)
+	defer func() { endSpan(span, err) }()
+
+	half, err := m.prepareMessage(ctx, topic, data, o)
+	if err != nil {
+		return err
+	}
+
+	// 本地事务结束后 ctx 可能已取消，提交与回滚仍需完成
+	finishCtx := context.WithoutCancel(ctx)
+	finished := false
+	defer func() {
+		// localTx panic：回滚半消息后继续向上抛出
+		if !finished {
+			m.rollbackHalf(finishCtx, topic, half)
+		}
+	}()
+	txErr := localTx()
+	finished = true
+
+	if txErr != nil {
+		m.rollbackHalf(finishCtx, topic, half)
+		return txErr
+	}
+
+	start := time.Now()
+	err = half.Commit(finishCtx)
+	m.recordPublishMetrics(ctx, topic, err, time.Since(start))
+	if err != nil {
+		return xerrors.Wrapf(err, "commit transactional message to %s", topic)
+	}
+	return nil
+}
+
+// prepareMessage 优先使用驱动原生半消息，其次降级为 outbox
+func (m *mq) prepareMessage(ctx context.Context, topic string, data []byte, opts PublishOptions) (halfMessage, error) {
+	if tt, ok := m.transport.(txTransport); ok && m.transport.Capabilities().Transaction {
+		half, err := tt.PrepareMessage(ctx, topic, data, opts)
+		if err != nil {
+			return nil, xerrors.Wrapf(err, "prepare transactional message to %s", topic)
+		}
+		return half, nil
+	}
+	if m.txOutbox == nil {
+		return nil, xerrors.Wrapf(ErrNotSupported, "transactional publish on driver %s without WithTransactionOutbox", m.driver)
+	}
+	return prepareOutboxMessage(ctx, m.txOutbox, m, m.logger, topic, data, opts)
+}
+
+// rollbackHalf 回滚半消息，失败只记录日志（Redis 半消息会自然过期，outbox 记录保持 prepared 不会被投递）
+func (m *mq) rollbackHalf(ctx context.Context, topic string, half halfMessage) {
+	if err := half.Rollback(ctx); err != nil {
+		m.logger.Warn("rollback transactional message failed",
+			clog.String("topic", topic),
+			clog.Error(err),
+		)
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Redis Stream：半消息暂存在独立的 Hash 中，提交时原子地移入 Stream
+// -----------------------------------------------------------------------------
+
+// commitHalfScript 读取并删除暂存的半消息后 XADD 到 Stream；半消息不存在（已过期）时返回 false。
+//
+// KEYS[1]=半消息 key，KEYS[2]=Stream；ARGV[1]=MaxLen，ARGV[2]="1" 表示近似裁剪。
+var commitHalfScript = redis.NewScript(`
+	local fields = redis.call("HGETALL", KEYS[1])
+	if #fields == 0 then
+		return false
+	end
+	redis.call("DEL", KEYS[1])
+	local args = {KEYS[2]}
+	if tonumber(ARGV[1]) > 0 then
+		table.insert(args, "MAXLEN")
+		if ARGV[2] == "1" then
+			table.insert(args, "~")
+		end
+		table.insert(args, ARGV[1])
+	end
+	table.insert(args, "*")
+	for _, v in ipairs(fields) do
+		table.insert(args, v)
+	end
+	return redis.call("XADD", unpack(args))
+`)
+
+// PrepareMessage 把消息暂存到 mq:half:<topic>:<id>，消费者读取 Stream 时看不到
+func (t *redisStreamTransport) PrepareMessage(ctx context.Context, topic string, data []byte, opts PublishOptions) (halfMessage, error) {
+	values, err := t.streamValues(data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, xerrors.Wrap(err, "generate half message id")
+	}
+	key := redisHalfKeyPrefix + topic + ":" + hex.EncodeToString(b[:])
+
+	pipe := t.client.TxPipeline()
+	pipe.HSet(ctx, key, values)
+	pipe.PExpire(ctx, key, redisHalfMessageTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, xerrors.Wrap(err, "store half message")
+	}
+	return &redisHalfMessage{transport: t, topic: topic, key: key}, nil
+}
+
+// redisHalfMessage Redis Stream 半消息
+type redisHalfMessage struct {
+	transport *redisStreamTransport
+	topic     string
+	key       string
+}
+
+func (h *redisHalfMessage) Commit(ctx context.Context) error {
+	approx := "0"
+	if h.transport.cfg.Approximate {
+		approx = "1"
+	}
+	err := commitHalfScript.Run(ctx, h.transport.client,
+		[]string{h.key, h.topic},
+		strconv.FormatInt(h.transport.cfg.MaxLen, 10), approx,
+	).Err()
+	if xerrors.Is(err, redis.Nil) {
+		return xerrors.Wrapf(ErrHalfMessageExpired, "half message %s", h.key)
+	}
+	return err
+}
+
+func (h *redisHalfMessage) Rollback(ctx context.Context) error {
+	return h.transport.client.Del(ctx, h.key).Err()
+}
+
+// -----------------------------------------------------------------------------
+// Outbox 降级：半消息以 prepared 状态写入 outbox 表
+// -----------------------------------------------------------------------------
+
+// prepareOutboxMessage 写入 prepared 状态的 outbox 记录
+func prepareOutboxMessage(ctx context.Context, db *gorm.DB, pub MQ, logger clog.Logger, topic string, data []byte, opts PublishOptions) (halfMessage, error) {
+	msg, err := newOutboxMessage(topic, data, opts)
+	if err != nil {
+		return nil, err
+	}
+	msg.Prepared = true
+	if err := db.WithContext(ctx).Create(msg).Error; err != nil {
+		return nil, xerrors.Wrapf(err, "outbox: insert half message for topic %s", topic)
+	}
+	relay := &OutboxRelay{db: db, pub: pub, opts: outboxOptions{logger: logger}}
+	return &outboxHalfMessage{relay: relay, msg: msg}, nil
+}
+
+// outboxHalfMessage outbox 表中的半消息
+type outboxHalfMessage struct {
+	relay *OutboxRelay
+	msg   *OutboxMessage
+}
+
+// Commit 把记录转为待投递后立即发布；立即发布失败不返回错误，由 OutboxRelay 重试
+func (h *outboxHalfMessage) Commit(ctx context.Context) error {
+	err := h.relay.db.WithContext(ctx).Model(&OutboxMessage{}).
+		Where("id = ?", h.msg.ID).
+		Update("prepared", false).Error
+	if err != nil {
+		return xerrors.Wrapf(err, "outbox: commit half message %d", h.msg.ID)
+	}
+	if err := h.relay.relay(ctx, h.msg); err != nil {
+		h.relay.opts.logger.Warn("outbox: immediate publish failed, relay will retry", clog.Uint64("id", h.msg.ID), clog.Error(err))
+	}
+	return nil
+}
+
+func (h *outboxHalfMessage) Rollback(ctx context.Context) error {
+	err := h.relay.db.WithContext(ctx).
+		Where("id = ? AND prepared = ?", h.msg.ID, true).
+		Delete(&OutboxMessage{}).Error
+	if err != nil {
+		return xerrors.Wrapf(err, "outbox: rollback half message %d", h.msg.ID)
+	}
+	return nil
+}
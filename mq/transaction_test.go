@@ -0,0 +1,131 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+func newTxOutboxMQ(transport *recordingTransport, db *gorm.DB) MQ {
+	return &mq{transport: transport, logger: clog.Discard(), meter: metrics.Discard(), driver: string(DriverNATSJetStream), txOutbox: db}
+}
+
+func TestMQ_PublishInTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("本地事务成功后消费端收到消息", func(t *testing.T) {
+		db := newOutboxTestDB(t)
+		transport := &recordingTransport{}
+		m := newTxOutboxMQ(transport, db)
+
+		err := m.PublishInTransaction(ctx, "orders.created", []byte("book"), func() error {
+			// 半消息已落库但对 relay 不可见
+			var prepared OutboxMessage
+			require.NoError(t, db.First(&prepared).Error)
+			require.True(t, prepared.Prepared)
+			require.Empty(t, transport.snapshot())
+			return db.Create(&outboxOrder{Item: "book"}).Error
+		}, WithHeader("trace-id", "t1"))
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"orders.created:book"}, transport.snapshot())
+		require.Equal(t, Headers{"trace-id": "t1"}, transport.headers[0])
+
+		var msg OutboxMessage
+		require.NoError(t, db.First(&msg).Error)
+		require.False(t, msg.Prepared)
+		require.NotNil(t, msg.SentAt)
+	})
+
+	t.Run("本地事务失败时消费端收不到消息", func(t *testing.T) {
+		db := newOutboxTestDB(t)
+		transport := &recordingTransport{}
+		m := newTxOutboxMQ(transport, db)
+
+		txErr := errors.New("insufficient stock")
+		err := m.PublishInTransaction(ctx, "orders.created", []byte("book"), func() error {
+			return txErr
+		})
+		require.ErrorIs(t, err, txErr)
+		require.Empty(t, transport.snapshot())
+
+		var count int64
+		require.NoError(t, db.Model(&OutboxMessage{}).Count(&count).Error)
+		require.Zero(t, count, "半消息已删除")
+	})
+
+	t.Run("本地事务 panic 时回滚半消息", func(t *testing.T) {
+		db := newOutboxTestDB(t)
+		transport := &recordingTransport{}
+		m := newTxOutboxMQ(transport, db)
+
+		require.PanicsWithValue(t, "boom", func() {
+			_ = m.PublishInTransaction(ctx, "orders.created", []byte("book"), func() error {
+				panic("boom")
+			})
+		})
+		require.Empty(t, transport.snapshot())
+
+		var count int64
+		require.NoError(t, db.Model(&OutboxMessage{}).Count(&count).Error)
+		require.Zero(t, count)
+	})
+
+	t.Run("立即发布失败由 relay 重试", func(t *testing.T) {
+		db := newOutboxTestDB(t)
+		transport := &recordingTransport{}
+		transport.setFail(errors.New("broker down"))
+		m := newTxOutboxMQ(transport, db)
+
+		require.NoError(t, m.PublishInTransaction(ctx, "orders.created", []byte("book"), func() error { return nil }))
+		require.Empty(t, transport.snapshot())
+
+		transport.setFail(nil)
+		relay, err := NewOutboxRelay(db, m)
+		require.NoError(t, err)
+		sent, err := relay.RelayOnce(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, sent)
+		require.Equal(t, []string{"orders.created:book"}, transport.snapshot())
+	})
+
+	t.Run("relay 不投递未结束的半消息", func(t *testing.T) {
+		db := newOutboxTestDB(t)
+		transport := &recordingTransport{}
+		m := newTxOutboxMQ(transport, db)
+		relay, err := NewOutboxRelay(db, m)
+		require.NoError(t, err)
+
+		require.NoError(t, m.PublishInTransaction(ctx, "orders.created", []byte("book"), func() error {
+			sent, err := relay.RelayOnce(ctx)
+			require.NoError(t, err)
+			require.Zero(t, sent)
+			return nil
+		}))
+		require.Equal(t, []string{"orders.created:book"}, transport.snapshot())
+	})
+
+	t.Run("不支持半消息且未配置 outbox", func(t *testing.T) {
+		m := newMQ(&mockTransport{}, clog.Discard(), metrics.Discard())
+		called := false
+		err := m.PublishInTransaction(ctx, "orders.created", []byte("book"), func() error {
+			called = true
+			return nil
+		})
+		require.ErrorIs(t, err, ErrNotSupported)
+		require.False(t, called, "半消息写入失败时不执行本地事务")
+	})
+
+	t.Run("参数校验与关闭", func(t *testing.T) {
+		m := newMQ(&mockTransport{}, clog.Discard(), metrics.Discard())
+		require.ErrorIs(t, m.PublishInTransaction(ctx, "t", nil, nil), ErrInvalidConfig)
+		require.NoError(t, m.Close())
+		require.ErrorIs(t, m.PublishInTransaction(ctx, "t", nil, func() error { return nil }), ErrClosed)
+	})
+}
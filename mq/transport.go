@@ -1,23 +1,57 @@
 package mq
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
-// Transport 底层传输层接口（内部使用）
+// Driver 消息队列后端驱动接口
 //
-// 定义了 MQ 后端必须实现的核心能力。不支持的操作应返回 ErrNotSupported。
-type Transport interface {
+// MQ 的核心逻辑（中间件、指标、Ack 超时、自动重订阅、并发消费等）只依赖该接口，
+// 内置的 NATS JetStream、Redis Stream、Kafka 驱动均实现了它；接入新的后端只需实现 Driver
+// 并通过 WithDriver 注入，无需修改 mq 包。不支持的操作应返回 ErrNotSupported。
+type Driver interface {
 	// Publish 发布消息
-	Publish(ctx context.Context, topic string, data []byte, opts publishOptions) error
+	Publish(ctx context.Context, topic string, data []byte, opts PublishOptions) error
 
 	// Subscribe 订阅消息
 	//
 	// 实现要求：
 	//   - 将 subscribeCtx 传递给 Message.Context()
 	//   - 支持 QueueGroup 负载均衡
-	Subscribe(subscribeCtx context.Context, topic string, handler Handler, opts subscribeOptions) (Subscription, error)
+	Subscribe(subscribeCtx context.Context, topic string, handler Handler, opts SubscribeOptions) (Subscription, error)
 
-	// Close 关闭 Transport
+	// Capabilities 返回驱动支持的可选能力
+	Capabilities() Capabilities
+
+	// Close 关闭 Driver
 	//
-	// 注意：底层连接由 Connector 管理，此方法仅释放 Transport 内部资源。
+	// 注意：底层连接由 Connector 管理，此方法仅释放 Driver 内部资源。
 	Close() error
 }
+
+// Capabilities 驱动可选能力声明
+//
+// 声明为 true 的能力还需要驱动实现对应的扩展接口，否则按不支持处理。
+type Capabilities struct {
+	// Batch 支持批量消费，需实现 BatchDriver；不支持时 SubscribeBatch 返回 ErrNotSupported
+	Batch bool
+
+	// AsyncPublish 支持原生异步发布确认，需实现 AsyncDriver；不支持时 PublishAsync 同步发布后立即回调
+	AsyncPublish bool
+
+	// Transaction 支持原生半消息；不支持时 PublishInTransaction 依赖 WithTransactionOutbox 降级
+	Transaction bool
+}
+
+// BatchDriver 由支持批量消费的 Driver 实现
+//
+// 仅在 Capabilities().Batch 为 true 时被调用。
+type BatchDriver interface {
+	// SubscribeBatch 批量订阅消息
+	//
+	// 实现要求：
+	//   - 攒够 batchSize 条或首条消息到达后等待满 maxWait 即交付 handler
+	//   - handler 成功后统一确认整批，失败时不确认并回退重投
+	SubscribeBatch(subscribeCtx context.Context, topic string, handler BatchHandler, batchSize int, maxWait time.Duration, opts SubscribeOptions) (Subscription, error)
+}
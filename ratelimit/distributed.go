@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 
@@ -20,6 +21,7 @@ const luaScript = `
 -- ARGV[1]: 速率 (rate, 每秒允许的请求数)
 -- ARGV[2]: 桶容量 (capacity, 峰值/并发容量)
 -- ARGV[3]: 本次请求需要消耗的令牌数 (tokens_to_consume)
+-- 返回: {是否允许, 剩余令牌数, 被拒绝时需等待的微秒数}
 
 local rate = tonumber(ARGV[1])
 local capacity = tonumber(ARGV[2])
@@ -53,12 +55,14 @@ if new_refreshed <= allow_at_most then
   -- 计算剩余可用令牌数
   local remaining_tokens = math.floor((allow_at_most - new_refreshed) / interval_per_token)
   
-  return {1, remaining_tokens}
+  return {1, remaining_tokens, 0}
 else
   -- 令牌不足，拒绝请求
   local remaining_tokens = math.floor((allow_at_most - next_available_time) / interval_per_token)
+  -- 等到 new_refreshed 不超过 allow_at_most 所需的时间
+  local retry_after = math.ceil((new_refreshed - allow_at_most) * 1000000)
   
-  return {0, remaining_tokens}
+  return {0, remaining_tokens, retry_after}
 end
 `
 
@@ -72,6 +76,7 @@ type distributedLimiter struct {
 	// 指标
 	allowedCounter metrics.Counter
 	deniedCounter  metrics.Counter
+	metricLabels   []metrics.Label
 }
 
 // newDistributed 创建分布式限流器（内部函数）
@@ -99,6 +104,7 @@ func newDistributed(
 	}
 
 	// 初始化指标
+	l.metricLabels = []metrics.Label{metrics.L(LabelMode, "distributed")}
 	if meter != nil {
 		l.allowedCounter, _ = meter.Counter(MetricAllowed, "Number of allowed requests")
 		l.deniedCounter, _ = meter.Counter(MetricDenied, "Number of denied requests")
@@ -118,16 +124,27 @@ func (l *distributedLimiter) Allow(ctx context.Context, key string, limit Limit)
 
 // AllowN 尝试获取 N 个令牌
 func (l *distributedLimiter) AllowN(ctx context.Context, key string, limit Limit, n int) (bool, error) {
+	res, err := l.allowN(ctx, key, limit, n)
+	return res.Allowed, err
+}
+
+// AllowWithResult 尝试获取 1 个令牌并返回详细结果
+func (l *distributedLimiter) AllowWithResult(ctx context.Context, key string, limit Limit) (Result, error) {
+	return l.allowN(ctx, key, limit, 1)
+}
+
+// allowN 执行 Lua 脚本并解析允许结果、剩余令牌数与等待时间
+func (l *distributedLimiter) allowN(ctx context.Context, key string, limit Limit, n int) (Result, error) {
 	if key == "" {
-		return false, ErrKeyEmpty
+		return Result{}, ErrKeyEmpty
 	}
 
 	if limit.Rate <= 0 || limit.Burst <= 0 {
-		return false, ErrInvalidLimit
+		return Result{}, ErrInvalidLimit
 	}
 
 	if n <= 0 {
-		return false, ErrInvalidLimit
+		return Result{}, ErrInvalidLimit
 	}
 
 	// 构建 Redis key
@@ -141,18 +158,18 @@ func (l *distributedLimiter) AllowN(ctx context.Context, key string, limit Limit
 				clog.String("key", key),
 				clog.Error(err))
 		}
-		return false, xerrors.Wrap(err, "execute lua script")
+		return Result{}, xerrors.Wrap(err, "execute lua script")
 	}
 
 	// 解析结果
 	resultSlice, ok := result.([]any)
-	if !ok || len(resultSlice) != 2 {
-		return false, xerrors.New("invalid lua script result")
+	if !ok || len(resultSlice) != 3 {
+		return Result{}, xerrors.New("invalid lua script result")
 	}
 
 	allowed, ok := resultSlice[0].(int64)
 	if !ok {
-		return false, xerrors.New("invalid allowed value")
+		return Result{}, xerrors.New("invalid allowed value")
 	}
 
 	remaining, ok := resultSlice[1].(int64)
@@ -160,16 +177,26 @@ func (l *distributedLimiter) AllowN(ctx context.Context, key string, limit Limit
 		remaining = 0
 	}
 
+	retryAfter, ok := resultSlice[2].(int64)
+	if !ok {
+		retryAfter = 0
+	}
+
 	isAllowed := allowed == 1
+	res := Result{Allowed: isAllowed, Limit: limit, Remaining: max(int(remaining), 0)}
+	if !isAllowed {
+		res.RetryAfter = time.Duration(retryAfter) * time.Microsecond
+		res.Reason = ReasonDistributedTokenBucket
+	}
 
 	// 记录指标
 	if isAllowed {
 		if l.allowedCounter != nil {
-			l.allowedCounter.Inc(ctx, metrics.L(LabelMode, "distributed"))
+			l.allowedCounter.Inc(ctx, l.metricLabels...)
 		}
 	} else {
 		if l.deniedCounter != nil {
-			l.deniedCounter.Inc(ctx, metrics.L(LabelMode, "distributed"))
+			l.deniedCounter.Inc(ctx, l.metricLabels...)
 		}
 	}
 
@@ -183,7 +210,7 @@ func (l *distributedLimiter) AllowN(ctx context.Context, key string, limit Limit
 			clog.Int("requested", n))
 	}
 
-	return isAllowed, nil
+	return res, nil
 }
 
 func (l *distributedLimiter) buildKey(key string, limit Limit) string {
@@ -196,6 +223,11 @@ func (l *distributedLimiter) buildKey(key string, limit Limit) string {
 	)
 }
 
+// AllowWithLimitFn 按 key 动态决定限流规则并获取 1 个令牌
+func (l *distributedLimiter) AllowWithLimitFn(ctx context.Context, key string, fn KeyLimitFunc) (bool, error) {
+	return allowWithLimitFn(ctx, l, key, fn)
+}
+
 // Wait 阻塞等待直到获取 1 个令牌
 // 注意：分布式模式不支持 Wait 操作
 func (l *distributedLimiter) Wait(ctx context.Context, key string, limit Limit) error {
@@ -203,6 +235,11 @@ func (l *distributedLimiter) Wait(ctx context.Context, key string, limit Limit)
 	return ErrNotSupported
 }
 
+// markDryRun 为允许/拒绝指标追加 dry_run="true" 标签，实现 dryRunMarker
+func (l *distributedLimiter) markDryRun() {
+	l.metricLabels = append(l.metricLabels, metrics.L(LabelDryRun, "true"))
+}
+
 // Close 释放资源（分布式连接由 Connector 管理）
 func (l *distributedLimiter) Close() error {
 	return nil
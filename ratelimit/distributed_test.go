@@ -77,6 +77,51 @@ func TestDistributedLimiter_Allow_Basic(t *testing.T) {
 	})
 }
 
+func TestDistributedLimiter_AllowWithLimitFn(t *testing.T) {
+	limiter := newDistributedLimiter(t)
+
+	vip, normal := "vip:alice", "user:bob"
+
+	// 突发容量各自生效
+	assert.Equal(t, 5, countAllowed(t, limiter, vip, 10))
+	assert.Equal(t, 2, countAllowed(t, limiter, normal, 10))
+
+	// 200ms 内 vip 按 50/s 补满，普通用户按 2/s 补不足 1 个
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, 5, countAllowed(t, limiter, vip, 10))
+	assert.Equal(t, 0, countAllowed(t, limiter, normal, 10))
+}
+
+func TestDistributedLimiter_AllowWithResult(t *testing.T) {
+	limiter := newDistributedLimiter(t)
+	ctx := context.Background()
+	limit := Limit{Rate: 10, Burst: 3}
+
+	// 通过时 Remaining 递减
+	for want := 2; want >= 0; want-- {
+		res, err := limiter.AllowWithResult(ctx, "result:user:1", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, want, res.Remaining)
+		assert.Zero(t, res.RetryAfter)
+		assert.Empty(t, res.Reason)
+	}
+
+	// 被拒时 RetryAfter 不超过补充 1 个令牌的间隔（100ms）
+	res, err := limiter.AllowWithResult(ctx, "result:user:1", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Zero(t, res.Remaining)
+	assert.Greater(t, res.RetryAfter, time.Duration(0))
+	assert.LessOrEqual(t, res.RetryAfter, 100*time.Millisecond)
+	assert.Equal(t, ReasonDistributedTokenBucket, res.Reason)
+
+	time.Sleep(res.RetryAfter + 5*time.Millisecond)
+	res, err = limiter.AllowWithResult(ctx, "result:user:1", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+}
+
 func TestDistributedLimiter_AllowN(t *testing.T) {
 	limiter := newDistributedLimiter(t)
 	ctx := context.Background()
@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"context"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// dryRunLimiter 监控模式限流器（非导出）
+//
+// 正常执行底层限流判断，但始终放行；本应被拒绝的请求只计入
+// ratelimit_would_block_total 指标并记录日志，用于上线前评估阈值。
+// 底层限流器的允许/拒绝指标带 dry_run="true" 标签，与真实拒绝区分。
+type dryRunLimiter struct {
+	limiter Limiter
+	logger  clog.Logger
+
+	wouldBlockCounter metrics.Counter
+}
+
+// dryRunMarker 由内置限流器实现，进入监控模式时为自身指标追加 dry_run 标签
+type dryRunMarker interface {
+	markDryRun()
+}
+
+// newDryRun 包装已有限流器为监控模式
+func newDryRun(limiter Limiter, logger clog.Logger, meter metrics.Meter) Limiter {
+	if m, ok := limiter.(dryRunMarker); ok {
+		m.markDryRun()
+	}
+	l := &dryRunLimiter{
+		limiter: limiter,
+		logger:  logger,
+	}
+	if meter != nil {
+		l.wouldBlockCounter, _ = meter.Counter(MetricWouldBlock, "Number of requests that would be denied in dry-run mode")
+	}
+	if logger != nil {
+		logger.Info("rate limiter running in dry-run mode")
+	}
+	return l
+}
+
+// Allow 执行限流判断并始终放行
+func (l *dryRunLimiter) Allow(ctx context.Context, key string, limit Limit) (bool, error) {
+	return l.AllowN(ctx, key, limit, 1)
+}
+
+// AllowN 执行限流判断并始终放行，系统错误原样返回
+func (l *dryRunLimiter) AllowN(ctx context.Context, key string, limit Limit, n int) (bool, error) {
+	allowed, err := l.limiter.AllowN(ctx, key, limit, n)
+	if err != nil {
+		return false, err
+	}
+	if !allowed {
+		l.recordWouldBlock(ctx, key, limit, n)
+	}
+	return true, nil
+}
+
+// recordWouldBlock 记录本应被拒绝的请求
+func (l *dryRunLimiter) recordWouldBlock(ctx context.Context, key string, limit Limit, n int) {
+	if l.wouldBlockCounter != nil {
+		l.wouldBlockCounter.Inc(ctx, metrics.L(LabelKey, key))
+	}
+	if l.logger != nil {
+		l.logger.Warn("rate limit would block (dry-run)",
+			clog.String("key", key),
+			clog.Float64("rate", limit.Rate),
+			clog.Int("burst", limit.Burst),
+			clog.Int("requested", n))
+	}
+}
+
+// AllowWithResult 执行限流判断并始终放行
+//
+// 本应被拒绝时同样计入指标并记录日志，返回结果中 Allowed 为 true，RetryAfter 与 Reason 清空。
+func (l *dryRunLimiter) AllowWithResult(ctx context.Context, key string, limit Limit) (Result, error) {
+	res, err := l.limiter.AllowWithResult(ctx, key, limit)
+	if err != nil {
+		return Result{}, err
+	}
+	if !res.Allowed {
+		l.recordWouldBlock(ctx, key, limit, 1)
+	}
+	return Result{Allowed: true, Limit: res.Limit, Remaining: res.Remaining}, nil
+}
+
+// AllowWithLimitFn 按 key 动态决定限流规则并获取 1 个令牌
+func (l *dryRunLimiter) AllowWithLimitFn(ctx context.Context, key string, fn KeyLimitFunc) (bool, error) {
+	return allowWithLimitFn(ctx, l, key, fn)
+}
+
+// Wait 监控模式下不阻塞，按 Allow 判断并记录后立即返回
+func (l *dryRunLimiter) Wait(ctx context.Context, key string, limit Limit) error {
+	_, err := l.AllowN(ctx, key, limit, 1)
+	return err
+}
+
+// Close 释放底层限流器资源
+func (l *dryRunLimiter) Close() error {
+	return l.limiter.Close()
+}
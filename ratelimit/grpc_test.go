@@ -38,6 +38,15 @@ func (l *sequenceLimiter) AllowN(ctx context.Context, key string, limit Limit, n
 	return l.Allow(ctx, key, limit)
 }
 
+func (l *sequenceLimiter) AllowWithLimitFn(ctx context.Context, key string, fn KeyLimitFunc) (bool, error) {
+	return l.Allow(ctx, key, fn(key))
+}
+
+func (l *sequenceLimiter) AllowWithResult(ctx context.Context, key string, limit Limit) (Result, error) {
+	allowed, err := l.Allow(ctx, key, limit)
+	return Result{Allowed: allowed, Limit: limit}, err
+}
+
 func (l *sequenceLimiter) Wait(ctx context.Context, key string, limit Limit) error {
 	return nil
 }
@@ -59,6 +68,14 @@ func (l *errorLimiter) AllowN(ctx context.Context, key string, limit Limit, n in
 	return false, l.err
 }
 
+func (l *errorLimiter) AllowWithLimitFn(ctx context.Context, key string, fn KeyLimitFunc) (bool, error) {
+	return false, l.err
+}
+
+func (l *errorLimiter) AllowWithResult(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{}, l.err
+}
+
 func (l *errorLimiter) Wait(ctx context.Context, key string, limit Limit) error {
 	return l.err
 }
@@ -14,12 +14,18 @@ const (
 	// MetricErrors 限流器错误数 (Counter)
 	MetricErrors = "ratelimit_errors_total"
 
+	// MetricWouldBlock 监控模式下本应被拒绝的请求数 (Counter)
+	MetricWouldBlock = "ratelimit_would_block_total"
+
 	// LabelMode 模式标签 (standalone/distributed)
 	LabelMode = "mode"
 
 	// LabelKey 限流键标签
 	LabelKey = "key"
 
+	// LabelDryRun 监控模式标签，dry-run 下底层限流器的允许/拒绝指标带 dry_run="true"
+	LabelDryRun = "dry_run"
+
 	// LabelErrorType 错误类型标签
 	LabelErrorType = "error_type"
 )
@@ -2,7 +2,10 @@ package ratelimit
 
 import (
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -93,7 +96,7 @@ func GinMiddleware(limiter Limiter, opts *GinMiddlewareOptions) gin.HandlerFunc
 		}
 
 		// 检查是否允许请求
-		allowed, err := limiter.Allow(c.Request.Context(), key, limit)
+		res, err := limiter.AllowWithResult(c.Request.Context(), key, limit)
 		if err != nil {
 			if logger != nil {
 				logger.Warn("Rate limiter middleware check failed",
@@ -111,13 +114,20 @@ func GinMiddleware(limiter Limiter, opts *GinMiddlewareOptions) gin.HandlerFunc
 			return
 		}
 
-		if !allowed {
+		if withHeaders {
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		}
+
+		if !res.Allowed {
 			if withHeaders {
-				c.Header("X-RateLimit-Remaining", "0")
+				c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(res.RetryAfter)))
 			}
-			// 被限流，返回 429
-			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
-				"error": "rate limit exceeded",
+			// 被限流，返回结构化的 429 响应体
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, RateLimitResponse{
+				Error:        "rate limit exceeded",
+				Reason:       res.Reason,
+				Remaining:    res.Remaining,
+				RetryAfterMs: res.RetryAfter.Milliseconds(),
 			})
 			return
 		}
@@ -126,6 +136,19 @@ func GinMiddleware(limiter Limiter, opts *GinMiddlewareOptions) gin.HandlerFunc
 	}
 }
 
+// RateLimitResponse Gin 中间件被限流时返回的 429 响应体
+type RateLimitResponse struct {
+	Error        string `json:"error"`          // 固定为 "rate limit exceeded"
+	Reason       string `json:"reason"`         // 触发的限流算法，见 Reason 常量
+	Remaining    int    `json:"remaining"`      // 剩余令牌数
+	RetryAfterMs int64  `json:"retry_after_ms"` // 建议的重试等待时间（毫秒）
+}
+
+// retryAfterSeconds 把等待时间向上取整为 Retry-After 头的秒数，至少为 1
+func retryAfterSeconds(d time.Duration) int {
+	return max(int(math.Ceil(d.Seconds())), 1)
+}
+
 // formatLimit 格式化限流规则为字符串
 func formatLimit(limit Limit) string {
 	return fmt.Sprintf("rate=%.2f, burst=%d", limit.Rate, limit.Burst)
@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -363,6 +364,45 @@ func TestGinMiddleware_WithHeaders(t *testing.T) {
 		assert.Equal(t, "0", w2.Header().Get("X-RateLimit-Remaining"))
 	})
 
+	t.Run("剩余数递减，被限流时返回结构化响应体", func(t *testing.T) {
+		limiter := newTestLimiter(t)
+		router := setupTestRouter()
+
+		router.Use(GinMiddleware(limiter, &GinMiddlewareOptions{
+			WithHeaders: true,
+			KeyFunc: func(c *gin.Context) string {
+				return "structured-client"
+			},
+			LimitFunc: func(c *gin.Context) Limit {
+				return Limit{Rate: 0.5, Burst: 2}
+			},
+		}))
+
+		router.GET("/test", func(c *gin.Context) {
+			c.String(http.StatusOK, "ok")
+		})
+
+		for _, want := range []string{"1", "0"} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, want, w.Header().Get("X-RateLimit-Remaining"))
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "2", w.Header().Get("Retry-After"))
+
+		var body RateLimitResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "rate limit exceeded", body.Error)
+		assert.Equal(t, ReasonStandaloneTokenBucket, body.Reason)
+		assert.Zero(t, body.Remaining)
+		assert.Greater(t, body.RetryAfterMs, int64(1900))
+		assert.LessOrEqual(t, body.RetryAfterMs, int64(2000))
+	})
+
 	t.Run("不启用响应头时不设置头", func(t *testing.T) {
 		limiter := newTestLimiter(t)
 		router := setupTestRouter()
@@ -4,11 +4,13 @@
 // 1. 进程内的轻量限流；
 // 2. 基于 Redis 的集群共享限流。
 //
-// 这个包的核心能力是非阻塞的 `Allow` / `AllowN` 检查。单机模式使用
+// 这个包的核心能力是非阻塞的 `Allow` / `AllowN` 检查，`AllowWithResult` 额外返回
+// 剩余令牌数、恢复时间与拒绝原因。单机模式使用
 // `golang.org/x/time/rate`，分布式模式使用 Redis Lua 脚本维护共享桶状态。
 //
 // 分布式模式有几个重要语义：
 // - 桶状态按 `key + limit` 隔离，不同 `Rate/Burst` 不会共享同一个 Redis 键。
+// - `AllowWithLimitFn` 按 key 返回不同配额时，每个 key 使用自己的桶和参数。
 // - 脚本使用 Redis `TIME` 作为统一时钟，避免多节点本地时钟漂移破坏限流精度。
 // - `Wait` 不是分布式能力，调用会返回 `ErrNotSupported`。
 //
@@ -58,6 +60,26 @@ type Limit struct {
 	Burst int     // 令牌桶容量（突发最大请求数）
 }
 
+// Result 单次限流检查的详细结果
+type Result struct {
+	Allowed    bool          // 是否允许
+	Limit      Limit         // 本次检查使用的限流规则
+	Remaining  int           // 本次检查后桶内剩余的令牌数（向下取整）
+	RetryAfter time.Duration // 被拒绝时距离令牌足够的等待时间，允许时为 0
+	Reason     string        // 被拒绝时的原因，标识触发的限流算法，允许时为空
+}
+
+// 拒绝原因
+const (
+	// ReasonStandaloneTokenBucket 单机令牌桶令牌不足
+	ReasonStandaloneTokenBucket = "standalone_token_bucket"
+	// ReasonDistributedTokenBucket 分布式（Redis）令牌桶令牌不足
+	ReasonDistributedTokenBucket = "distributed_token_bucket"
+)
+
+// KeyLimitFunc 按限流键返回限流规则，用于不同 key 使用不同配额（如 VIP 用户配额更高）。
+type KeyLimitFunc func(key string) Limit
+
 // ErrorPolicy 定义限流检查出错时的处理策略。
 type ErrorPolicy string
 
@@ -89,6 +111,29 @@ type Limiter interface {
 	// AllowN 尝试获取 N 个令牌（非阻塞）
 	AllowN(ctx context.Context, key string, limit Limit, n int) (bool, error)
 
+	// AllowWithLimitFn 尝试获取 1 个令牌，限流规则由 fn(key) 决定（非阻塞）
+	// 每个 key 使用各自的规则建桶，分布式模式下同样按 key + limit 隔离
+	//
+	// 使用示例:
+	//
+	//	allowed, err := limiter.AllowWithLimitFn(ctx, "user:123", func(key string) ratelimit.Limit {
+	//	    if isVIP(key) {
+	//	        return ratelimit.Limit{Rate: 100, Burst: 200}
+	//	    }
+	//	    return ratelimit.Limit{Rate: 10, Burst: 20}
+	//	})
+	AllowWithLimitFn(ctx context.Context, key string, fn KeyLimitFunc) (bool, error)
+
+	// AllowWithResult 尝试获取 1 个令牌（非阻塞），返回剩余令牌数、恢复时间与拒绝原因
+	//
+	// 使用示例:
+	//
+	//	res, err := limiter.AllowWithResult(ctx, "user:123", ratelimit.Limit{Rate: 10, Burst: 20})
+	//	if err == nil && !res.Allowed {
+	//	    w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(res.RetryAfter.Seconds()))))
+	//	}
+	AllowWithResult(ctx context.Context, key string, limit Limit) (Result, error)
+
 	// Wait 阻塞等待直到获取 1 个令牌
 	Wait(ctx context.Context, key string, limit Limit) error
 
@@ -120,6 +165,10 @@ type Config struct {
 
 	// Distributed 分布式限流配置
 	Distributed *DistributedConfig `json:"distributed" yaml:"distributed"`
+
+	// DryRun 监控模式：照常计算限流结果但始终放行，
+	// 本应被拒绝的请求计入 ratelimit_would_block_total 并记录日志
+	DryRun bool `json:"dry_run" yaml:"dry_run"`
 }
 
 // StandaloneConfig 单机限流配置
@@ -226,6 +275,16 @@ func (noop *noopLimiter) AllowN(ctx context.Context, key string, limit Limit, n
 	return true, nil
 }
 
+// AllowWithLimitFn 始终返回 true（允许通过）
+func (noop *noopLimiter) AllowWithLimitFn(ctx context.Context, key string, fn KeyLimitFunc) (bool, error) {
+	return true, nil
+}
+
+// AllowWithResult 始终允许，剩余令牌数视为桶容量
+func (noop *noopLimiter) AllowWithResult(ctx context.Context, key string, limit Limit) (Result, error) {
+	return Result{Allowed: true, Limit: limit, Remaining: limit.Burst}, nil
+}
+
 // Wait 始终返回 nil
 func (noop *noopLimiter) Wait(ctx context.Context, key string, limit Limit) error {
 	return nil
@@ -236,6 +295,17 @@ func (noop *noopLimiter) Close() error {
 	return nil
 }
 
+// allowWithLimitFn 按 fn 解析 key 的限流规则后获取 1 个令牌
+func allowWithLimitFn(ctx context.Context, l Limiter, key string, fn KeyLimitFunc) (bool, error) {
+	if key == "" {
+		return false, ErrKeyEmpty
+	}
+	if fn == nil {
+		return false, ErrInvalidLimit
+	}
+	return l.AllowN(ctx, key, fn(key), 1)
+}
+
 // New 根据配置创建限流器
 //
 // 使用示例:
@@ -279,16 +349,28 @@ func New(cfg *Config, opts ...Option) (Limiter, error) {
 
 	logger := o.logger.With(clog.String("component", "ratelimit"))
 
+	var (
+		limiter Limiter
+		err     error
+	)
 	switch cfg.Driver {
 	case DriverStandalone:
-		return newStandalone(cfg.Standalone, logger, o.meter)
+		limiter, err = newStandalone(cfg.Standalone, logger, o.meter)
 	case DriverDistributed:
 		// 使用 Option 中注入的 redisConn
 		if o.redisConn == nil {
 			return nil, xerrors.WithCode(ErrConnectorNil, "redis_connector_required_for_distributed_mode")
 		}
-		return newDistributed(cfg.Distributed, o.redisConn, logger, o.meter)
+		limiter, err = newDistributed(cfg.Distributed, o.redisConn, logger, o.meter)
 	default:
 		return nil, xerrors.New("ratelimit: unsupported driver: " + string(cfg.Driver))
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.DryRun {
+		return newDryRun(limiter, logger, o.meter), nil
+	}
+	return limiter, nil
 }
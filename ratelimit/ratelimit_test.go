@@ -2,12 +2,14 @@ package ratelimit
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
 )
 
 // ============================================================
@@ -27,6 +29,10 @@ func TestDiscard(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, allowed, "Discard limiter should always allow")
 
+	allowed, err = limiter.AllowWithLimitFn(context.Background(), "any-key", nil)
+	require.NoError(t, err)
+	require.True(t, allowed, "Discard limiter should always allow")
+
 	require.NoError(t, limiter.Wait(context.Background(), "any-key", Limit{Rate: 1, Burst: 1}))
 	require.NoError(t, limiter.Close())
 }
@@ -142,3 +148,115 @@ func BenchmarkStandaloneLimiter(b *testing.B) {
 		limiter.Allow(ctx, "key", Limit{Rate: 10000, Burst: 10000})
 	}
 }
+
+// ============================================================
+// DryRun 模式测试
+// ============================================================
+
+// countingMeter 记录 Counter 累计值的测试 Meter，按 "name/label=value" 聚合
+type countingMeter struct {
+	metrics.Meter
+	mu     sync.Mutex
+	counts map[string]float64
+}
+
+func newCountingMeter() *countingMeter {
+	return &countingMeter{Meter: metrics.Discard(), counts: make(map[string]float64)}
+}
+
+func (m *countingMeter) Counter(name, desc string, opts ...metrics.MetricOption) (metrics.Counter, error) {
+	return &countingCounter{meter: m, name: name}, nil
+}
+
+func (m *countingMeter) get(name string, label metrics.Label) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[name+"/"+label.Key+"="+label.Value]
+}
+
+type countingCounter struct {
+	meter *countingMeter
+	name  string
+}
+
+func (c *countingCounter) Inc(ctx context.Context, labels ...metrics.Label) {
+	c.Add(ctx, 1, labels...)
+}
+
+func (c *countingCounter) Add(ctx context.Context, val float64, labels ...metrics.Label) {
+	c.meter.mu.Lock()
+	defer c.meter.mu.Unlock()
+	for _, l := range labels {
+		c.meter.counts[c.name+"/"+l.Key+"="+l.Value] += val
+	}
+}
+
+func TestNew_DryRun(t *testing.T) {
+	ctx := context.Background()
+	limit := Limit{Rate: 0.001, Burst: 2}
+
+	t.Run("dry-run 下超限请求仍放行并计数", func(t *testing.T) {
+		meter := newCountingMeter()
+		limiter, err := New(&Config{Driver: DriverStandalone, DryRun: true}, WithMeter(meter))
+		require.NoError(t, err)
+		defer limiter.Close()
+
+		for range 5 {
+			allowed, err := limiter.Allow(ctx, "user:1", limit)
+			require.NoError(t, err)
+			require.True(t, allowed)
+		}
+
+		require.Equal(t, float64(3), meter.get(MetricWouldBlock, metrics.L(LabelKey, "user:1")))
+		require.Equal(t, float64(3), meter.get(MetricDenied, metrics.L(LabelDryRun, "true")), "底层拒绝指标带 dry_run 标签")
+		require.Equal(t, float64(2), meter.get(MetricAllowed, metrics.L(LabelDryRun, "true")))
+		require.NoError(t, limiter.Wait(ctx, "user:1", limit))
+		require.Equal(t, float64(4), meter.get(MetricWouldBlock, metrics.L(LabelKey, "user:1")))
+	})
+
+	t.Run("dry-run 下 AllowWithResult 始终允许", func(t *testing.T) {
+		meter := newCountingMeter()
+		limiter, err := New(&Config{Driver: DriverStandalone, DryRun: true}, WithMeter(meter))
+		require.NoError(t, err)
+		defer limiter.Close()
+
+		for range 3 {
+			res, err := limiter.AllowWithResult(ctx, "user:1", limit)
+			require.NoError(t, err)
+			require.True(t, res.Allowed)
+			require.Zero(t, res.RetryAfter)
+			require.Empty(t, res.Reason)
+		}
+		require.Equal(t, float64(1), meter.get(MetricWouldBlock, metrics.L(LabelKey, "user:1")))
+	})
+
+	t.Run("关闭 dry-run 后真正拒绝", func(t *testing.T) {
+		meter := newCountingMeter()
+		limiter, err := New(&Config{Driver: DriverStandalone}, WithMeter(meter))
+		require.NoError(t, err)
+		defer limiter.Close()
+
+		denied := 0
+		for range 5 {
+			allowed, err := limiter.Allow(ctx, "user:1", limit)
+			require.NoError(t, err)
+			if !allowed {
+				denied++
+			}
+		}
+
+		require.Equal(t, 3, denied)
+		require.Zero(t, meter.get(MetricWouldBlock, metrics.L(LabelKey, "user:1")))
+		require.Equal(t, float64(3), meter.get(MetricDenied, metrics.L(LabelMode, "standalone")))
+		require.Zero(t, meter.get(MetricDenied, metrics.L(LabelDryRun, "true")))
+	})
+
+	t.Run("dry-run 下系统错误原样返回", func(t *testing.T) {
+		limiter, err := New(&Config{Driver: DriverStandalone, DryRun: true})
+		require.NoError(t, err)
+		defer limiter.Close()
+
+		_, err = limiter.Allow(ctx, "", limit)
+		require.ErrorIs(t, err, ErrKeyEmpty)
+	})
+}
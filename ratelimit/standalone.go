@@ -29,6 +29,7 @@ type standaloneLimiter struct {
 	// 指标
 	allowedCounter metrics.Counter
 	deniedCounter  metrics.Counter
+	metricLabels   []metrics.Label
 }
 
 // newStandalone 创建单机限流器（内部函数）
@@ -49,6 +50,7 @@ func newStandalone(
 	}
 
 	// 初始化指标
+	l.metricLabels = []metrics.Label{metrics.L(LabelMode, "standalone")}
 	if meter != nil {
 		l.allowedCounter, _ = meter.Counter(MetricAllowed, "Number of allowed requests")
 		l.deniedCounter, _ = meter.Counter(MetricDenied, "Number of denied requests")
@@ -76,35 +78,56 @@ func (l *standaloneLimiter) Allow(ctx context.Context, key string, limit Limit)
 
 // AllowN 尝试获取 N 个令牌
 func (l *standaloneLimiter) AllowN(ctx context.Context, key string, limit Limit, n int) (bool, error) {
+	res, err := l.allowN(ctx, key, limit, n)
+	return res.Allowed, err
+}
+
+// AllowWithResult 尝试获取 1 个令牌并返回详细结果
+func (l *standaloneLimiter) AllowWithResult(ctx context.Context, key string, limit Limit) (Result, error) {
+	return l.allowN(ctx, key, limit, 1)
+}
+
+// allowN 通过预约令牌判断是否允许：需要等待则取消预约并拒绝，等待时间即 RetryAfter
+func (l *standaloneLimiter) allowN(ctx context.Context, key string, limit Limit, n int) (Result, error) {
 	if key == "" {
-		return false, ErrKeyEmpty
+		return Result{}, ErrKeyEmpty
 	}
 
 	if limit.Rate <= 0 || limit.Burst <= 0 {
-		return false, ErrInvalidLimit
+		return Result{}, ErrInvalidLimit
 	}
 
 	if n <= 0 {
-		return false, ErrInvalidLimit
+		return Result{}, ErrInvalidLimit
 	}
 
 	// 获取或创建 limiter
 	wrapper := l.getLimiter(key, limit)
 
 	// 尝试获取令牌
+	res := Result{Allowed: true, Limit: limit}
 	wrapper.mu.Lock()
-	allowed := wrapper.limiter.AllowN(time.Now(), n)
-	wrapper.lastSeen = time.Now()
+	now := time.Now()
+	if r := wrapper.limiter.ReserveN(now, n); !r.OK() {
+		// n 超过桶容量，永远无法满足
+		res = Result{Limit: limit, RetryAfter: rate.InfDuration, Reason: ReasonStandaloneTokenBucket}
+	} else if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		res = Result{Limit: limit, RetryAfter: delay, Reason: ReasonStandaloneTokenBucket}
+	}
+	res.Remaining = max(int(wrapper.limiter.TokensAt(now)), 0)
+	wrapper.lastSeen = now
 	wrapper.mu.Unlock()
+	allowed := res.Allowed
 
 	// 记录指标
 	if allowed {
 		if l.allowedCounter != nil {
-			l.allowedCounter.Inc(ctx, metrics.L(LabelMode, "standalone"))
+			l.allowedCounter.Inc(ctx, l.metricLabels...)
 		}
 	} else {
 		if l.deniedCounter != nil {
-			l.deniedCounter.Inc(ctx, metrics.L(LabelMode, "standalone"))
+			l.deniedCounter.Inc(ctx, l.metricLabels...)
 		}
 	}
 
@@ -112,12 +135,18 @@ func (l *standaloneLimiter) AllowN(ctx context.Context, key string, limit Limit,
 		l.logger.Debug("rate limit check",
 			clog.String("key", key),
 			clog.Bool("allowed", allowed),
+			clog.Int("remaining", res.Remaining),
 			clog.Float64("rate", limit.Rate),
 			clog.Int("burst", limit.Burst),
 			clog.Int("requested", n))
 	}
 
-	return allowed, nil
+	return res, nil
+}
+
+// AllowWithLimitFn 按 key 动态决定限流规则并获取 1 个令牌
+func (l *standaloneLimiter) AllowWithLimitFn(ctx context.Context, key string, fn KeyLimitFunc) (bool, error) {
+	return allowWithLimitFn(ctx, l, key, fn)
 }
 
 // Wait 阻塞等待直到获取 1 个令牌
@@ -204,6 +233,11 @@ func (l *standaloneLimiter) cleanup(interval, idleTimeout time.Duration) {
 	}
 }
 
+// markDryRun 为允许/拒绝指标追加 dry_run="true" 标签，实现 dryRunMarker
+func (l *standaloneLimiter) markDryRun() {
+	l.metricLabels = append(l.metricLabels, metrics.L(LabelDryRun, "true"))
+}
+
 // Close 关闭限流器
 func (l *standaloneLimiter) Close() error {
 	select {
@@ -2,6 +2,7 @@ package ratelimit
 
 import (
 	"context"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -454,6 +455,100 @@ func TestStandaloneLimiter_DifferentLimits(t *testing.T) {
 	})
 }
 
+// ============================================================
+// 按 key 差异化限流规则测试
+// ============================================================
+
+// tieredLimit 模拟按用户等级返回配额：vip 高配额，其余低配额
+func tieredLimit(key string) Limit {
+	if strings.HasPrefix(key, "vip:") {
+		return Limit{Rate: 50, Burst: 5}
+	}
+	return Limit{Rate: 2, Burst: 2}
+}
+
+// countAllowed 连续请求 n 次，返回被允许的次数
+func countAllowed(t *testing.T, limiter Limiter, key string, n int) int {
+	t.Helper()
+	count := 0
+	for range n {
+		allowed, err := limiter.AllowWithLimitFn(context.Background(), key, tieredLimit)
+		require.NoError(t, err)
+		if allowed {
+			count++
+		}
+	}
+	return count
+}
+
+func TestStandaloneLimiter_AllowWithLimitFn(t *testing.T) {
+	// 延长空闲超时，避免等待补充令牌期间桶被清理
+	limiter := newStandaloneLimiter(t, withTestIdleTimeout(time.Minute))
+	defer limiter.Close()
+
+	t.Run("不同 key 按各自配额限流", func(t *testing.T) {
+		vip, normal := "vip:alice", "user:bob"
+
+		// 突发容量各自生效
+		assert.Equal(t, 5, countAllowed(t, limiter, vip, 10))
+		assert.Equal(t, 2, countAllowed(t, limiter, normal, 10))
+
+		// 200ms 内 vip 按 50/s 补满，普通用户按 2/s 补不足 1 个
+		time.Sleep(200 * time.Millisecond)
+		assert.Equal(t, 5, countAllowed(t, limiter, vip, 10))
+		assert.Equal(t, 0, countAllowed(t, limiter, normal, 10))
+	})
+
+	t.Run("参数校验", func(t *testing.T) {
+		_, err := limiter.AllowWithLimitFn(context.Background(), "", tieredLimit)
+		assert.ErrorIs(t, err, ErrKeyEmpty)
+
+		_, err = limiter.AllowWithLimitFn(context.Background(), "user:1", nil)
+		assert.ErrorIs(t, err, ErrInvalidLimit)
+
+		_, err = limiter.AllowWithLimitFn(context.Background(), "user:1", func(string) Limit { return Limit{} })
+		assert.ErrorIs(t, err, ErrInvalidLimit)
+	})
+}
+
+func TestStandaloneLimiter_AllowWithResult(t *testing.T) {
+	limiter := newStandaloneLimiter(t, withTestIdleTimeout(time.Minute))
+	defer limiter.Close()
+	ctx := context.Background()
+	limit := Limit{Rate: 10, Burst: 3}
+
+	// 通过时 Remaining 递减
+	for want := 2; want >= 0; want-- {
+		res, err := limiter.AllowWithResult(ctx, "user:1", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed)
+		assert.Equal(t, want, res.Remaining)
+		assert.Zero(t, res.RetryAfter)
+		assert.Empty(t, res.Reason)
+		assert.Equal(t, limit, res.Limit)
+	}
+
+	// 被拒时 RetryAfter 不超过补充 1 个令牌的间隔（100ms）
+	res, err := limiter.AllowWithResult(ctx, "user:1", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Zero(t, res.Remaining)
+	assert.Greater(t, res.RetryAfter, time.Duration(0))
+	assert.LessOrEqual(t, res.RetryAfter, 100*time.Millisecond)
+	assert.Equal(t, ReasonStandaloneTokenBucket, res.Reason)
+
+	// 被拒的请求不消耗令牌：等待 RetryAfter 后可以通过
+	time.Sleep(res.RetryAfter + 5*time.Millisecond)
+	res, err = limiter.AllowWithResult(ctx, "user:1", limit)
+	require.NoError(t, err)
+	assert.True(t, res.Allowed)
+
+	_, err = limiter.AllowWithResult(ctx, "", limit)
+	assert.ErrorIs(t, err, ErrKeyEmpty)
+	_, err = limiter.AllowWithResult(ctx, "user:1", Limit{})
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+}
+
 // ============================================================
 // 限流精确性测试
 // ============================================================
@@ -16,6 +16,9 @@ type Config struct {
 
 	// RetryInterval 重连/重试间隔，默认 1s
 	RetryInterval time.Duration `yaml:"retry_interval" json:"retry_interval"`
+
+	// EndpointsRefreshInterval EndpointsWatcher 定期全量刷新间隔，默认 30s
+	EndpointsRefreshInterval time.Duration `yaml:"endpoints_refresh_interval" json:"endpoints_refresh_interval"`
 }
 
 // Validate 验证配置有效性
@@ -32,5 +35,8 @@ func (c *Config) validate() error {
 	if c.RetryInterval < 0 {
 		return xerrors.New("registry: invalid retry_interval, must be non-negative")
 	}
+	if c.EndpointsRefreshInterval < 0 {
+		return xerrors.New("registry: invalid endpoints_refresh_interval, must be non-negative")
+	}
 	return nil
 }
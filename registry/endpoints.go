@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+// LookupEndpoints 返回服务当前实例的 host:port 列表
+func (r *etcdRegistry) LookupEndpoints(ctx context.Context, serviceName string) ([]string, error) {
+	instances, err := r.GetService(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	return instanceEndpoints(instances), nil
+}
+
+// EndpointsWatcher 监听服务端点列表变化
+//
+// 由 Watch 事件驱动刷新，并按 Config.EndpointsRefreshInterval 定期全量刷新兜底；
+// 列表内容变化时才推送。通道只保留最新的一份列表，消费慢时旧列表会被覆盖。
+func (r *etcdRegistry) EndpointsWatcher(ctx context.Context, serviceName string) (<-chan []string, error) {
+	if err := r.ensureOpen(); err != nil {
+		return nil, err
+	}
+	if serviceName == "" {
+		return nil, ErrInvalidServiceInstance
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	events, err := r.Watch(watchCtx, serviceName)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	out := make(chan []string, 1)
+	r.wg.Go(func() {
+		defer cancel()
+		w := &endpointsWatcher{
+			serviceName: serviceName,
+			lookup: func(ctx context.Context) ([]string, error) {
+				return r.LookupEndpoints(ctx, serviceName)
+			},
+			events:   events,
+			interval: r.cfg.EndpointsRefreshInterval,
+			logger:   r.logger,
+			out:      out,
+		}
+		w.run(watchCtx)
+	})
+	return out, nil
+}
+
+// endpointsWatcher 把实例事件转换为端点列表推送
+type endpointsWatcher struct {
+	serviceName string
+	lookup      func(ctx context.Context) ([]string, error)
+	events      <-chan ServiceEvent
+	interval    time.Duration
+	logger      clog.Logger
+	out         chan []string
+
+	last []string
+	sent bool
+}
+
+func (w *endpointsWatcher) run(ctx context.Context) {
+	defer close(w.out)
+
+	w.refresh(ctx)
+
+	var tick <-chan time.Time
+	if w.interval > 0 {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-w.events:
+			if !ok {
+				return
+			}
+			w.refresh(ctx)
+		case <-tick:
+			w.refresh(ctx)
+		}
+	}
+}
+
+// refresh 全量查询端点，与上次推送的列表不同时推送
+func (w *endpointsWatcher) refresh(ctx context.Context) {
+	endpoints, err := w.lookup(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Warn("failed to refresh endpoints",
+				clog.String("service_name", w.serviceName),
+				clog.Error(err))
+		}
+		return
+	}
+	if w.sent && slices.Equal(endpoints, w.last) {
+		return
+	}
+	w.last = endpoints
+	w.sent = true
+
+	// 丢弃未被消费的旧列表，只保留最新
+	select {
+	case <-w.out:
+	default:
+	}
+	w.out <- slices.Clone(endpoints)
+}
+
+// instanceEndpoints 提取实例的 host:port 列表，去重并排序
+func instanceEndpoints(instances []*ServiceInstance) []string {
+	endpoints := make([]string, 0, len(instances))
+	for _, inst := range instances {
+		for _, endpoint := range inst.Endpoints {
+			if addr := parseGRPCEndpoint(endpoint); addr != "" {
+				endpoints = append(endpoints, addr)
+			}
+		}
+	}
+	slices.Sort(endpoints)
+	return slices.Compact(endpoints)
+}
@@ -21,6 +21,9 @@ var (
 	// ErrInvalidTTL 无效的 TTL
 	ErrInvalidTTL = xerrors.New("invalid ttl")
 
+	// ErrNotReady 实例在 WithReadyCheck 的超时时间内未就绪
+	ErrNotReady = xerrors.New("service not ready")
+
 	// ErrLeaseExpired 租约已过期
 	ErrLeaseExpired = xerrors.New("lease expired")
 
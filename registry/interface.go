@@ -15,11 +15,24 @@ type Registry interface {
 	//
 	// service.Endpoints 必须全部是 gRPC 地址，只接受 `grpc://host:port` 或 `host:port`。
 	// ttl 为 0 时使用 Config.DefaultTTL；ttl 大于 0 时必须至少为 1 秒。
-	Register(ctx context.Context, service *ServiceInstance, ttl time.Duration) error
+	// 传入 WithReadyCheck 时先等待实例就绪再写入 Etcd。
+	Register(ctx context.Context, service *ServiceInstance, ttl time.Duration, opts ...RegisterOption) error
 
 	// Deregister 注销服务实例。
 	Deregister(ctx context.Context, serviceID string) error
 
+	// UpdateMetadata 原地更新已注册实例的元数据（整体替换）。
+	//
+	// 实例保持注册状态，Watch 收到 PUT 事件而不是先删后加；只能更新本 registry 注册的实例。
+	// 常用于按负载调整 Metadata["weight"]，配合 WithWeightedRoundRobin 改变分流比例。
+	UpdateMetadata(ctx context.Context, serviceID string, metadata map[string]string) error
+
+	// SelfRegister 自动生成本实例 endpoint 并注册，返回实际注册的实例。
+	//
+	// AutoDetectIP 为 true 时探测本机可路由 IP（排除 loopback 与容器网桥，可用 PreferInterface 指定网卡），
+	// 否则使用 opts.Host。ctx 结束时自动注销，适合传入 signal.NotifyContext 返回的 ctx。
+	SelfRegister(ctx context.Context, opts RegisterOptions) (*ServiceInstance, error)
+
 	// --- 服务发现 ---
 
 	// GetService 获取服务实例列表。
@@ -31,6 +44,22 @@ type Registry interface {
 	// 基于快照与本地已知状态做 diff，并补发必要事件。
 	Watch(ctx context.Context, serviceName string) (<-chan ServiceEvent, error)
 
+	// LookupEndpoints 返回服务当前实例的 host:port 列表（已去重、排序）。
+	//
+	// 面向只接受地址列表的非 gRPC 客户端，例如 HTTP 客户端或自定义连接池。
+	LookupEndpoints(ctx context.Context, serviceName string) ([]string, error)
+
+	// EndpointsWatcher 监听服务的 host:port 列表。
+	//
+	// 通道首先推送当前列表，之后在实例变化或定期刷新发现差异时推送新列表；
+	// ctx 结束或 registry 关闭后通道关闭。
+	EndpointsWatcher(ctx context.Context, serviceName string) (<-chan []string, error)
+
+	// Topology 返回服务依赖图：服务名 -> 该服务各实例声明的 Dependencies（已去重、排序）。
+	//
+	// 只包含当前已注册的服务，无依赖的服务对应空切片；被依赖但未注册的服务不单独成项。
+	Topology(ctx context.Context) (map[string][]string, error)
+
 	// --- gRPC 集成 ---
 
 	// GetConnection 获取指定服务的 gRPC 连接。
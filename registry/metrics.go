@@ -0,0 +1,120 @@
+package registry
+
+import (
+	"context"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+// 指标名称常量
+const (
+	// MetricServiceInstances 服务当前实例数（Gauge），在 GetService、Watch 事件、Register、Deregister 后刷新
+	MetricServiceInstances = "registry_service_instances"
+
+	// MetricWatchEvents Watch 推送的事件数（Counter）
+	MetricWatchEvents = "registry_watch_events_total"
+
+	// MetricRegister 本 registry 成功注册的实例数（Counter）
+	MetricRegister = "registry_register_total"
+
+	// MetricDeregister 本 registry 成功注销的实例数（Counter）
+	MetricDeregister = "registry_deregister_total"
+)
+
+// 标签名称常量
+const (
+	// LabelService 服务名标签
+	LabelService = "service"
+
+	// LabelType Watch 事件类型标签，取值为 PUT / DELETE
+	LabelType = "type"
+)
+
+// registryMetrics 注册发现指标，未注入 Meter 时为 nil，所有方法均为空操作
+type registryMetrics struct {
+	instances    metrics.Gauge
+	watchEvents  metrics.Counter
+	registered   metrics.Counter
+	deregistered metrics.Counter
+}
+
+// newRegistryMetrics 基于 Meter 创建指标，meter 为 nil 时返回 nil
+func newRegistryMetrics(meter metrics.Meter) (*registryMetrics, error) {
+	if meter == nil {
+		return nil, nil
+	}
+
+	instances, err := meter.Gauge(MetricServiceInstances, "Number of instances currently registered for a service")
+	if err != nil {
+		return nil, err
+	}
+	watchEvents, err := meter.Counter(MetricWatchEvents, "Total number of service events delivered by Watch")
+	if err != nil {
+		return nil, err
+	}
+	registered, err := meter.Counter(MetricRegister, "Total number of service instances registered")
+	if err != nil {
+		return nil, err
+	}
+	deregistered, err := meter.Counter(MetricDeregister, "Total number of service instances deregistered")
+	if err != nil {
+		return nil, err
+	}
+
+	return &registryMetrics{
+		instances:    instances,
+		watchEvents:  watchEvents,
+		registered:   registered,
+		deregistered: deregistered,
+	}, nil
+}
+
+// setInstances 设置服务当前实例数
+func (m *registryMetrics) setInstances(ctx context.Context, serviceName string, count int) {
+	if m == nil {
+		return
+	}
+	m.instances.Set(ctx, float64(count), metrics.L(LabelService, serviceName))
+}
+
+// watchEvent 记录一次 Watch 推送的事件
+func (m *registryMetrics) watchEvent(ctx context.Context, serviceName string, typ EventType) {
+	if m == nil {
+		return
+	}
+	m.watchEvents.Inc(ctx, metrics.L(LabelService, serviceName), metrics.L(LabelType, string(typ)))
+}
+
+// register 记录一次成功注册
+func (m *registryMetrics) register(ctx context.Context, serviceName string) {
+	if m == nil {
+		return
+	}
+	m.registered.Inc(ctx, metrics.L(LabelService, serviceName))
+}
+
+// deregister 记录一次成功注销
+func (m *registryMetrics) deregister(ctx context.Context, serviceName string) {
+	if m == nil {
+		return
+	}
+	m.deregistered.Inc(ctx, metrics.L(LabelService, serviceName))
+}
+
+// refreshInstances 从 Etcd 读取服务当前实例数并更新 Gauge，未注入 Meter 时不发起请求
+func (r *etcdRegistry) refreshInstances(ctx context.Context, serviceName string) {
+	if r.metrics == nil {
+		return
+	}
+	resp, err := r.client.Get(ctx, r.buildPrefix(serviceName), clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		r.logger.Debug("failed to count service instances",
+			clog.String("service_name", serviceName),
+			clog.Error(err))
+		return
+	}
+	r.metrics.setInstances(ctx, serviceName, int(resp.Count))
+}
@@ -1,6 +1,9 @@
 package registry
 
-import "github.com/ceyewan/genesis/clog"
+import (
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
 
 // Option 组件初始化选项函数
 type Option func(*options)
@@ -8,6 +11,7 @@ type Option func(*options)
 // options 选项结构
 type options struct {
 	logger clog.Logger
+	meter  metrics.Meter
 }
 
 // WithLogger 注入日志记录器
@@ -19,3 +23,14 @@ func WithLogger(l clog.Logger) Option {
 		}
 	}
 }
+
+// WithMeter 注入指标 Meter
+// 注入后上报 registry_service_instances、registry_watch_events_total、
+// registry_register_total 与 registry_deregister_total
+func WithMeter(m metrics.Meter) Option {
+	return func(o *options) {
+		if m != nil {
+			o.meter = m
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package registry
+
+import (
+	"context"
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// readyCheckInterval 就绪检查的轮询间隔
+const readyCheckInterval = 100 * time.Millisecond
+
+// RegisterOption Register 的可选参数
+type RegisterOption func(*registerCallOptions)
+
+// registerCallOptions Register 的可选参数（内部使用）
+type registerCallOptions struct {
+	readyCheck   func() bool
+	readyTimeout time.Duration
+}
+
+// WithReadyCheck 注册前等待实例就绪
+//
+// Register 会轮询 fn，直到返回 true 才写入 Etcd，避免把流量导向尚未就绪的实例，
+// 例如 fn 检查 gRPC health 状态为 SERVING。timeout 内仍未就绪返回 ErrNotReady，
+// timeout<=0 时只受 ctx 控制。
+func WithReadyCheck(fn func() bool, timeout time.Duration) RegisterOption {
+	return func(o *registerCallOptions) {
+		if fn != nil {
+			o.readyCheck = fn
+			o.readyTimeout = timeout
+		}
+	}
+}
+
+// waitReady 轮询 fn 直到返回 true、超时或 ctx 结束
+func (r *etcdRegistry) waitReady(ctx context.Context, serviceID string, fn func() bool, timeout time.Duration) error {
+	if fn() {
+		return nil
+	}
+	r.logger.Info("waiting for service ready before register",
+		clog.String("service_id", serviceID),
+		clog.Duration("timeout", timeout))
+
+	start := time.Now()
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	ticker := time.NewTicker(readyCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-r.stopChan:
+			return ErrRegistryClosed
+		case <-deadline:
+			return xerrors.Wrapf(ErrNotReady, "service %s not ready after %s", serviceID, timeout)
+		case <-ticker.C:
+			if fn() {
+				r.logger.Info("service ready",
+					clog.String("service_id", serviceID),
+					clog.Duration("waited", time.Since(start)))
+				return nil
+			}
+		}
+	}
+}
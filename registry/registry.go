@@ -5,7 +5,8 @@
 // 因此进程内只允许存在一个 active registry 实例。
 //
 // 这个组件当前有三个核心能力：
-//   - Register / Deregister：把服务实例注册到 Etcd，并用 lease 管理实例生命周期
+//   - Register / Deregister：把服务实例注册到 Etcd，并用 lease 管理实例生命周期；
+//     WithReadyCheck 可等待实例就绪后再注册
 //   - GetService / Watch：获取实例列表，并订阅服务实例变化
 //   - GetConnection：把服务发现结果接入 gRPC resolver，返回可用于 RPC 的 ClientConn
 //
@@ -26,6 +27,13 @@
 // Watch 会在 Etcd compaction 后回到最新快照，并基于快照与本地已知状态做 diff，
 // 补发必要的 PUT / DELETE 事件，尽量维持事件流语义。
 //
+// UpdateMetadata 沿用原租约原地更新实例元数据。resolver 会把 Metadata["weight"]
+// 作为地址权重，配合 WithWeightedRoundRobin 按权重分流，权重变化无需重建连接。
+//
+// ServiceInstance.Dependencies 声明实例调用的下游服务，Topology 汇总为"服务名 -> 依赖服务"的依赖图。
+//
+// WithMeter 注入 metrics.Meter 后，registry 上报服务实例数、Watch 事件数以及注册/注销次数。
+//
 // Close 会停止后台 watch / keepalive 任务，并尽力撤销当前 registry 创建的 lease。
 // 如果 lease 撤销失败，Close 会把错误返回给调用方，而不是只写日志。
 package registry
@@ -36,6 +44,7 @@ import (
 	"fmt"
 	"maps"
 	"net"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -58,7 +67,7 @@ import (
 // 参数:
 //   - conn: Etcd 连接器
 //   - cfg: Registry 配置
-//   - opts: 可选参数 (Logger)
+//   - opts: 可选参数 (Logger、Meter)
 //
 // 使用示例:
 //
@@ -100,6 +109,9 @@ func New(conn connector.EtcdConnector, cfg *Config, opts ...Option) (Registry, e
 	if cfg.RetryInterval == 0 {
 		cfg.RetryInterval = 1 * time.Second
 	}
+	if cfg.EndpointsRefreshInterval == 0 {
+		cfg.EndpointsRefreshInterval = 30 * time.Second
+	}
 
 	if opt.logger == nil {
 		logger, err := clog.New(&clog.Config{
@@ -114,10 +126,16 @@ func New(conn connector.EtcdConnector, cfg *Config, opts ...Option) (Registry, e
 		}
 	}
 
+	m, err := newRegistryMetrics(opt.meter)
+	if err != nil {
+		return nil, xerrors.Wrap(err, "create registry metrics failed")
+	}
+
 	r := &etcdRegistry{
 		client:     client,
 		cfg:        cfg,
 		logger:     opt.logger,
+		metrics:    m,
 		keepAlives: make(map[string]*leaseKeepAlive),
 		watchers:   make(map[uint64]context.CancelFunc),
 		stopChan:   make(chan struct{}),
@@ -145,6 +163,8 @@ type etcdRegistry struct {
 	client *clientv3.Client
 	cfg    *Config
 	logger clog.Logger
+	// metrics 注册发现指标，未注入 Meter 时为 nil
+	metrics *registryMetrics
 
 	// 后台任务管理
 	keepAlives map[string]*leaseKeepAlive    // serviceID -> keepAlive info
@@ -168,7 +188,7 @@ func (r *etcdRegistry) ensureOpen() error {
 }
 
 // Register 注册服务实例
-func (r *etcdRegistry) Register(ctx context.Context, service *ServiceInstance, ttl time.Duration) error {
+func (r *etcdRegistry) Register(ctx context.Context, service *ServiceInstance, ttl time.Duration, opts ...RegisterOption) error {
 	if err := r.ensureOpen(); err != nil {
 		return err
 	}
@@ -186,6 +206,17 @@ func (r *etcdRegistry) Register(ctx context.Context, service *ServiceInstance, t
 		return ErrInvalidTTL
 	}
 
+	var o registerCallOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// 等待就绪期间不持有锁，不阻塞其他实例的注册与注销
+	if o.readyCheck != nil {
+		if err := r.waitReady(ctx, service.ID, o.readyCheck, o.readyTimeout); err != nil {
+			return err
+		}
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -258,6 +289,9 @@ func (r *etcdRegistry) Register(ctx context.Context, service *ServiceInstance, t
 	r.wg.Add(1)
 	go r.monitorKeepAlive(ka)
 
+	r.metrics.register(ctx, service.Name)
+	r.refreshInstances(ctx, service.Name)
+
 	r.logger.Info("service registered",
 		clog.String("service_id", service.ID),
 		clog.String("service_name", service.Name),
@@ -282,6 +316,7 @@ func (r *etcdRegistry) Deregister(ctx context.Context, serviceID string) error {
 		return ErrServiceNotFound
 	}
 	leaseID := ka.leaseID
+	serviceName := ka.serviceName
 	// 取消 KeepAlive 协程
 	atomic.StoreUint32(&ka.closed, 1)
 	ka.cancel()
@@ -296,12 +331,76 @@ func (r *etcdRegistry) Deregister(ctx context.Context, serviceID string) error {
 		return xerrors.Wrap(err, "revoke lease failed")
 	}
 
+	r.metrics.deregister(ctx, serviceName)
+	r.refreshInstances(ctx, serviceName)
+
 	r.logger.Info("service deregistered",
 		clog.String("service_id", serviceID))
 
 	return nil
 }
 
+// UpdateMetadata 原地更新已注册实例的元数据
+//
+// 沿用原租约重写实例 key，Watch 只收到一次 PUT 事件，不会出现先删后加。
+func (r *etcdRegistry) UpdateMetadata(ctx context.Context, serviceID string, metadata map[string]string) error {
+	if err := r.ensureOpen(); err != nil {
+		return err
+	}
+	if serviceID == "" {
+		return ErrInvalidServiceInstance
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ka, exists := r.keepAlives[serviceID]
+	if !exists {
+		return ErrServiceNotFound
+	}
+	key := r.buildKey(ka.serviceName, serviceID)
+
+	resp, err := r.client.Get(ctx, key)
+	if err != nil {
+		return xerrors.Wrap(err, "get service failed")
+	}
+	if len(resp.Kvs) == 0 {
+		return ErrServiceNotFound
+	}
+
+	var service ServiceInstance
+	if err := json.Unmarshal(resp.Kvs[0].Value, &service); err != nil {
+		return xerrors.Wrap(err, "unmarshal service failed")
+	}
+	service.Metadata = maps.Clone(metadata)
+
+	value, err := json.Marshal(&service)
+	if err != nil {
+		return xerrors.Wrap(err, "marshal service failed")
+	}
+
+	// 只有 key 仍挂在本实例租约上时才写入，避免租约过期后写出一个无租约的僵尸实例
+	txnResp, err := r.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.LeaseValue(key), "=", ka.leaseID)).
+		Then(clientv3.OpPut(key, string(value), clientv3.WithLease(ka.leaseID))).
+		Commit()
+	if err != nil {
+		r.logger.Error("failed to update service metadata",
+			clog.String("key", key),
+			clog.Error(err))
+		return xerrors.Wrap(err, "update service metadata failed")
+	}
+	if !txnResp.Succeeded {
+		return ErrServiceNotFound
+	}
+
+	r.logger.Info("service metadata updated",
+		clog.String("service_id", serviceID),
+		clog.String("service_name", ka.serviceName))
+
+	return nil
+}
+
 // GetService 获取服务实例列表
 func (r *etcdRegistry) GetService(ctx context.Context, serviceName string) ([]*ServiceInstance, error) {
 	if err := r.ensureOpen(); err != nil {
@@ -332,6 +431,7 @@ func (r *etcdRegistry) GetService(ctx context.Context, serviceName string) ([]*S
 		}
 		instances = append(instances, &instance)
 	}
+	r.metrics.setInstances(ctx, serviceName, len(instances))
 
 	return instances, nil
 }
@@ -427,6 +527,7 @@ func (r *etcdRegistry) Watch(ctx context.Context, serviceName string) (<-chan Se
 										clog.String("service_name", serviceName),
 										clog.Error(err))
 								}
+								r.metrics.setInstances(watchCtx, serviceName, len(resp.Kvs))
 							}
 							break innerLoop
 						}
@@ -478,10 +579,14 @@ func (r *etcdRegistry) Watch(ctx context.Context, serviceName string) (<-chan Se
 						// 发送事件
 						select {
 						case eventCh <- event:
+							r.metrics.watchEvent(watchCtx, serviceName, event.Type)
 						case <-watchCtx.Done():
 							return
 						}
 					}
+					if len(wresp.Events) > 0 {
+						r.refreshInstances(watchCtx, serviceName)
+					}
 				}
 			}
 
@@ -690,6 +795,9 @@ func validateServiceInstance(service *ServiceInstance) error {
 			return xerrors.Wrapf(ErrInvalidServiceInstance, "invalid grpc endpoint: %s", endpoint)
 		}
 	}
+	if slices.Contains(service.Dependencies, "") {
+		return xerrors.Wrap(ErrInvalidServiceInstance, "dependency name cannot be empty")
+	}
 	return nil
 }
 
@@ -718,6 +826,9 @@ func cloneServiceInstance(service *ServiceInstance) *ServiceInstance {
 		Version:   service.Version,
 		Endpoints: append([]string(nil), service.Endpoints...),
 	}
+	if len(service.Dependencies) > 0 {
+		cloned.Dependencies = slices.Clone(service.Dependencies)
+	}
 	if len(service.Metadata) > 0 {
 		cloned.Metadata = make(map[string]string, len(service.Metadata))
 		maps.Copy(cloned.Metadata, service.Metadata)
@@ -751,6 +862,7 @@ func (r *etcdRegistry) emitSnapshotDiff(
 		}
 		select {
 		case eventCh <- ServiceEvent{Type: EventTypePut, Service: cloneServiceInstance(instance)}:
+			r.metrics.watchEvent(ctx, serviceName, EventTypePut)
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -768,6 +880,7 @@ func (r *etcdRegistry) emitSnapshotDiff(
 				Name: serviceName,
 			},
 		}:
+			r.metrics.watchEvent(ctx, serviceName, EventTypeDelete)
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -790,6 +903,9 @@ func serviceInstancesEqual(a, b *ServiceInstance) bool {
 	if len(a.Endpoints) != len(b.Endpoints) || len(a.Metadata) != len(b.Metadata) {
 		return false
 	}
+	if !slices.Equal(a.Dependencies, b.Dependencies) {
+		return false
+	}
 	for i := range a.Endpoints {
 		if a.Endpoints[i] != b.Endpoints[i] {
 			return false
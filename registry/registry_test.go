@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,11 +15,14 @@ import (
 	mvccpb "go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/resolver"
 	"google.golang.org/grpc/serviceconfig"
 
 	"github.com/ceyewan/genesis/connector"
+	"github.com/ceyewan/genesis/metrics"
 	"github.com/ceyewan/genesis/testkit"
 )
 
@@ -874,3 +881,623 @@ func TestNamespaceIsolation(t *testing.T) {
 		t.Errorf("Expected 0 instances in ns2 (different namespace), got %d", len(resp.Kvs))
 	}
 }
+
+// TestLookupEndpoints 测试端点列表查询与监听
+func TestLookupEndpoints(t *testing.T) {
+	reg := setupRegistry(t, "/test/endpoints")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, reg.Register(ctx, &ServiceInstance{
+		ID:        "ep-001",
+		Name:      "endpoints-test",
+		Endpoints: []string{"grpc://127.0.0.1:9001"},
+	}, 10*time.Second))
+
+	endpoints, err := reg.LookupEndpoints(ctx, "endpoints-test")
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1:9001"}, endpoints)
+
+	watchCh, err := reg.EndpointsWatcher(ctx, "endpoints-test")
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1:9001"}, receiveEndpoints(t, watchCh))
+
+	require.NoError(t, reg.Register(ctx, &ServiceInstance{
+		ID:        "ep-002",
+		Name:      "endpoints-test",
+		Endpoints: []string{"127.0.0.1:9002"},
+	}, 10*time.Second))
+	require.Equal(t, []string{"127.0.0.1:9001", "127.0.0.1:9002"}, receiveEndpoints(t, watchCh))
+
+	require.NoError(t, reg.Deregister(ctx, "ep-001"))
+	require.Equal(t, []string{"127.0.0.1:9002"}, receiveEndpoints(t, watchCh))
+
+	cancel()
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-watchCh:
+			return !ok
+		default:
+			return false
+		}
+	}, 2*time.Second, 20*time.Millisecond)
+}
+
+func receiveEndpoints(t *testing.T, ch <-chan []string) []string {
+	t.Helper()
+	select {
+	case endpoints, ok := <-ch:
+		require.True(t, ok, "endpoints channel closed")
+		return endpoints
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for endpoints")
+		return nil
+	}
+}
+
+// TestEndpointsWatcher 测试端点推送逻辑（不依赖 Etcd）
+func TestEndpointsWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	current := []string{"10.0.0.1:80"}
+	var mu sync.Mutex
+	events := make(chan ServiceEvent, 1)
+	w := &endpointsWatcher{
+		serviceName: "fake",
+		lookup: func(ctx context.Context) ([]string, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Clone(current), nil
+		},
+		events:   events,
+		interval: 20 * time.Millisecond,
+		logger:   testkit.NewLogger(),
+		out:      make(chan []string, 1),
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.run(ctx)
+	}()
+
+	require.Equal(t, []string{"10.0.0.1:80"}, receiveEndpoints(t, w.out))
+
+	// 事件触发刷新
+	mu.Lock()
+	current = []string{"10.0.0.1:80", "10.0.0.2:80"}
+	mu.Unlock()
+	events <- ServiceEvent{Type: EventTypePut}
+	require.Equal(t, []string{"10.0.0.1:80", "10.0.0.2:80"}, receiveEndpoints(t, w.out))
+
+	// 无事件时由定期刷新发现变化
+	mu.Lock()
+	current = []string{"10.0.0.2:80"}
+	mu.Unlock()
+	require.Equal(t, []string{"10.0.0.2:80"}, receiveEndpoints(t, w.out))
+
+	// 列表未变化时不推送
+	select {
+	case endpoints := <-w.out:
+		t.Fatalf("unexpected push: %v", endpoints)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// 事件通道关闭后输出通道关闭
+	close(events)
+	<-done
+	_, ok := <-w.out
+	require.False(t, ok)
+}
+
+func TestInstanceEndpoints(t *testing.T) {
+	endpoints := instanceEndpoints([]*ServiceInstance{
+		{ID: "a", Endpoints: []string{"grpc://10.0.0.2:80", "10.0.0.1:80"}},
+		{ID: "b", Endpoints: []string{"10.0.0.1:80", "http://10.0.0.3:80"}},
+	})
+	require.Equal(t, []string{"10.0.0.1:80", "10.0.0.2:80"}, endpoints)
+}
+
+func TestDetectIP(t *testing.T) {
+	ipNet := func(cidr string) net.Addr {
+		ip, n, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		n.IP = ip
+		return n
+	}
+	up := net.FlagUp | net.FlagBroadcast
+	ifaces := []netInterface{
+		{Name: "lo", Flags: net.FlagUp | net.FlagLoopback, Addrs: []net.Addr{ipNet("127.0.0.1/8")}},
+		{Name: "docker0", Flags: up, Addrs: []net.Addr{ipNet("172.17.0.1/16")}},
+		{Name: "br-1a2b3c", Flags: up, Addrs: []net.Addr{ipNet("172.18.0.1/16")}},
+		{Name: "eth1", Flags: net.FlagBroadcast, Addrs: []net.Addr{ipNet("10.0.9.9/24")}},
+		{Name: "eth0", Flags: up, Addrs: []net.Addr{ipNet("fe80::1/64"), ipNet("2001:db8::10/64"), ipNet("192.168.1.10/24")}},
+		{Name: "wlan0", Flags: up, Addrs: []net.Addr{ipNet("10.1.2.3/24")}},
+	}
+
+	tests := []struct {
+		name    string
+		ifaces  []netInterface
+		prefer  string
+		want    string
+		wantErr bool
+	}{
+		{name: "skips loopback, bridges and down interfaces", ifaces: ifaces, want: "192.168.1.10"},
+		{name: "prefer interface", ifaces: ifaces, prefer: "wlan0", want: "10.1.2.3"},
+		{name: "prefer virtual interface explicitly", ifaces: ifaces, prefer: "docker0", want: "172.17.0.1"},
+		{name: "prefer down interface", ifaces: ifaces, prefer: "eth1", wantErr: true},
+		{name: "prefer missing interface", ifaces: ifaces, prefer: "eth9", wantErr: true},
+		{name: "ipv6 fallback", ifaces: []netInterface{
+			{Name: "eth0", Flags: up, Addrs: []net.Addr{ipNet("fe80::1/64"), ipNet("2001:db8::10/64")}},
+		}, want: "2001:db8::10"},
+		{name: "ip addr type", ifaces: []netInterface{
+			{Name: "ens3", Flags: up, Addrs: []net.Addr{&net.IPAddr{IP: net.ParseIP("10.0.0.5")}}},
+		}, want: "10.0.0.5"},
+		{name: "only loopback", ifaces: ifaces[:1], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip, err := detectIP(tt.ifaces, tt.prefer)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidServiceInstance)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, ip.String())
+		})
+	}
+}
+
+func TestBuildSelfInstance(t *testing.T) {
+	lister := func() ([]netInterface, error) {
+		return []netInterface{{
+			Name:  "eth0",
+			Flags: net.FlagUp,
+			Addrs: []net.Addr{&net.IPNet{IP: net.ParseIP("10.0.0.8"), Mask: net.CIDRMask(24, 32)}},
+		}}, nil
+	}
+
+	t.Run("auto detect ip", func(t *testing.T) {
+		service, err := buildSelfInstance(RegisterOptions{
+			ServiceName:  "order-service",
+			Version:      "v1",
+			Port:         9090,
+			AutoDetectIP: true,
+		}, lister)
+		require.NoError(t, err)
+		require.Equal(t, "order-service-10.0.0.8-9090", service.ID)
+		require.Equal(t, []string{"grpc://10.0.0.8:9090"}, service.Endpoints)
+		require.NoError(t, validateServiceInstance(service))
+	})
+
+	t.Run("explicit host and ipv6", func(t *testing.T) {
+		service, err := buildSelfInstance(RegisterOptions{
+			ServiceName: "order-service",
+			ID:          "order-1",
+			Port:        9090,
+			Host:        "2001:db8::1",
+		}, lister)
+		require.NoError(t, err)
+		require.Equal(t, "order-1", service.ID)
+		require.Equal(t, []string{"grpc://[2001:db8::1]:9090"}, service.Endpoints)
+		require.NoError(t, validateServiceInstance(service))
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		for _, opts := range []RegisterOptions{
+			{Port: 9090, Host: "10.0.0.1"},
+			{ServiceName: "svc", Host: "10.0.0.1"},
+			{ServiceName: "svc", Port: 70000, Host: "10.0.0.1"},
+			{ServiceName: "svc", Port: 9090},
+		} {
+			_, err := buildSelfInstance(opts, lister)
+			require.ErrorIs(t, err, ErrInvalidServiceInstance)
+		}
+	})
+}
+
+func TestSelfRegister(t *testing.T) {
+	reg := setupRegistry(t, "/test/self-register")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service, err := reg.SelfRegister(ctx, RegisterOptions{
+		ServiceName: "self-service",
+		Port:        9100,
+		Host:        "10.0.0.1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"grpc://10.0.0.1:9100"}, service.Endpoints)
+
+	instances, err := reg.GetService(context.Background(), "self-service")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	require.Equal(t, service.ID, instances[0].ID)
+
+	// ctx 结束后自动注销
+	cancel()
+	require.Eventually(t, func() bool {
+		instances, err := reg.GetService(context.Background(), "self-service")
+		return err == nil && len(instances) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestRegisterReadyCheck(t *testing.T) {
+	reg := setupRegistry(t, "/test/ready-check")
+	ctx := context.Background()
+
+	var ready atomic.Bool
+	time.AfterFunc(500*time.Millisecond, func() { ready.Store(true) })
+
+	service := &ServiceInstance{
+		ID:        "ready-service-001",
+		Name:      "ready-service",
+		Endpoints: []string{"grpc://127.0.0.1:9200"},
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- reg.Register(ctx, service, 10*time.Second, WithReadyCheck(ready.Load, 5*time.Second))
+	}()
+
+	// fn 变为 true 之前实例不应出现在 etcd 中
+	require.Never(t, func() bool {
+		if ready.Load() {
+			return false
+		}
+		instances, err := reg.GetService(ctx, "ready-service")
+		return err == nil && len(instances) > 0
+	}, 400*time.Millisecond, 50*time.Millisecond)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("register did not return after service became ready")
+	}
+	require.True(t, ready.Load())
+
+	instances, err := reg.GetService(ctx, "ready-service")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	require.Equal(t, service.ID, instances[0].ID)
+}
+
+func TestWaitReady(t *testing.T) {
+	r := &etcdRegistry{logger: testkit.NewLogger(), stopChan: make(chan struct{})}
+	ctx := context.Background()
+
+	t.Run("超时返回 ErrNotReady", func(t *testing.T) {
+		err := r.waitReady(ctx, "svc", func() bool { return false }, 200*time.Millisecond)
+		require.ErrorIs(t, err, ErrNotReady)
+	})
+
+	t.Run("已就绪立即返回", func(t *testing.T) {
+		require.NoError(t, r.waitReady(ctx, "svc", func() bool { return true }, time.Millisecond))
+	})
+
+	t.Run("ctx 取消", func(t *testing.T) {
+		cancelCtx, cancel := context.WithTimeout(ctx, 150*time.Millisecond)
+		defer cancel()
+		err := r.waitReady(cancelCtx, "svc", func() bool { return false }, 0)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestUpdateMetadata(t *testing.T) {
+	reg := setupRegistry(t, "/test/update-metadata")
+	ctx := context.Background()
+
+	service := &ServiceInstance{
+		ID:        "weight-001",
+		Name:      "weight-test",
+		Version:   "1.0.0",
+		Metadata:  map[string]string{MetadataWeight: "1", "zone": "a"},
+		Endpoints: []string{"grpc://127.0.0.1:9100"},
+	}
+	require.NoError(t, reg.Register(ctx, service, 10*time.Second))
+	defer reg.Deregister(ctx, service.ID)
+
+	eventCh, err := reg.Watch(ctx, "weight-test")
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, reg.UpdateMetadata(ctx, service.ID, map[string]string{MetadataWeight: "5"}))
+
+	// Watch 只收到一次 PUT，不会先删后加
+	event := waitForRegistryEvent(t, eventCh, 2*time.Second)
+	require.Equal(t, EventTypePut, event.Type)
+	require.Equal(t, service.ID, event.Service.ID)
+	require.Equal(t, map[string]string{MetadataWeight: "5"}, event.Service.Metadata)
+	select {
+	case extra := <-eventCh:
+		t.Fatalf("unexpected extra event: %s", extra.Type)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	instances, err := reg.GetService(ctx, "weight-test")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	require.Equal(t, "5", instances[0].Metadata[MetadataWeight])
+	require.Equal(t, service.Endpoints, instances[0].Endpoints)
+
+	require.ErrorIs(t, reg.UpdateMetadata(ctx, "not-registered", nil), ErrServiceNotFound)
+	require.ErrorIs(t, reg.UpdateMetadata(ctx, "", nil), ErrInvalidServiceInstance)
+}
+
+func TestBuildTopology(t *testing.T) {
+	topology := buildTopology([]*ServiceInstance{
+		{ID: "gw-1", Name: "gateway", Dependencies: []string{"user-service", "order-service"}},
+		{ID: "gw-2", Name: "gateway", Dependencies: []string{"order-service", "user-service", "order-service"}},
+		{ID: "order-1", Name: "order-service", Dependencies: []string{"user-service"}},
+		{ID: "user-1", Name: "user-service"},
+	})
+
+	require.Equal(t, map[string][]string{
+		"gateway":       {"order-service", "user-service"},
+		"order-service": {"user-service"},
+		"user-service":  {},
+	}, topology)
+	require.NotNil(t, topology["user-service"], "无依赖的服务为空切片而非 nil")
+
+	require.ErrorIs(t, validateServiceInstance(&ServiceInstance{
+		ID:           "bad-1",
+		Name:         "bad",
+		Endpoints:    []string{"127.0.0.1:9000"},
+		Dependencies: []string{""},
+	}), ErrInvalidServiceInstance)
+}
+
+func TestTopology(t *testing.T) {
+	reg := setupRegistry(t, "/test/topology")
+	ctx := context.Background()
+
+	services := []*ServiceInstance{
+		{ID: "gw-1", Name: "gateway", Endpoints: []string{"127.0.0.1:9201"}, Dependencies: []string{"user-service", "order-service"}},
+		{ID: "gw-2", Name: "gateway", Endpoints: []string{"127.0.0.1:9202"}, Dependencies: []string{"order-service", "order-service"}},
+		{ID: "order-1", Name: "order-service", Endpoints: []string{"127.0.0.1:9203"}, Dependencies: []string{"user-service"}},
+		{ID: "user-1", Name: "user-service", Endpoints: []string{"127.0.0.1:9204"}},
+	}
+	for _, service := range services {
+		require.NoError(t, reg.Register(ctx, service, 10*time.Second))
+	}
+
+	topology, err := reg.Topology(ctx)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"gateway":       {"order-service", "user-service"},
+		"order-service": {"user-service"},
+		"user-service":  {},
+	}, topology)
+
+	// 依赖声明随实例存取，UpdateMetadata 不影响依赖
+	require.NoError(t, reg.UpdateMetadata(ctx, "order-1", map[string]string{"zone": "b"}))
+	instances, err := reg.GetService(ctx, "order-service")
+	require.NoError(t, err)
+	require.Len(t, instances, 1)
+	require.Equal(t, []string{"user-service"}, instances[0].Dependencies)
+
+	// 注销后服务从依赖图中移除
+	require.NoError(t, reg.Deregister(ctx, "order-1"))
+	topology, err = reg.Topology(ctx)
+	require.NoError(t, err)
+	require.NotContains(t, topology, "order-service")
+	require.Contains(t, topology, "gateway")
+}
+
+// stubBalancer 只接收状态更新的 balancer，用于单独测试 weightedBalancer
+type stubBalancer struct {
+	balancer.Balancer
+}
+
+func (stubBalancer) UpdateClientConnState(balancer.ClientConnState) error { return nil }
+
+// namedSubConn 带名字的 SubConn 桩
+type namedSubConn struct {
+	balancer.SubConn
+	addr string
+}
+
+func TestResolverWeightedRoundRobin(t *testing.T) {
+	cc := &testResolverClientConn{}
+	r := &etcdResolver{
+		registry:    &etcdRegistry{logger: testkit.NewLogger()},
+		serviceName: "weighted-test",
+		cc:          cc,
+		localCache:  map[string]resolver.Address{},
+		initialized: true,
+	}
+	instance := func(id, addr, weight string) *ServiceInstance {
+		return &ServiceInstance{
+			ID:        id,
+			Name:      "weighted-test",
+			Metadata:  map[string]string{MetadataWeight: weight},
+			Endpoints: []string{"grpc://" + addr},
+		}
+	}
+
+	pb := &weightedPickerBuilder{}
+	b := &weightedBalancer{Balancer: stubBalancer{}, pb: pb}
+	a := &namedSubConn{addr: "10.0.0.1:9000"}
+	c := &namedSubConn{addr: "10.0.0.2:9000"}
+
+	// 按 resolver 推送的状态生成 picker，统计 100 次选择的分布
+	pickCounts := func() map[string]int {
+		t.Helper()
+		require.NoError(t, b.UpdateClientConnState(balancer.ClientConnState{ResolverState: *cc.lastState}))
+		// base balancer 会沿用首次创建 SubConn 时的地址，这里不带权重属性模拟该情况
+		picker := pb.Build(base.PickerBuildInfo{ReadySCs: map[balancer.SubConn]base.SubConnInfo{
+			a: {Address: resolver.Address{Addr: a.addr}},
+			c: {Address: resolver.Address{Addr: c.addr}},
+		}})
+		counts := make(map[string]int)
+		for range 100 {
+			res, err := picker.Pick(balancer.PickInfo{})
+			require.NoError(t, err)
+			counts[res.SubConn.(*namedSubConn).addr]++
+		}
+		return counts
+	}
+
+	r.handleEvent(ServiceEvent{Type: EventTypePut, Service: instance("a", a.addr, "1")})
+	r.handleEvent(ServiceEvent{Type: EventTypePut, Service: instance("c", c.addr, "1")})
+	require.Equal(t, map[string]int{a.addr: 50, c.addr: 50}, pickCounts())
+
+	// 实例 c 调高权重，同一实例的地址被覆盖而不是新增
+	r.handleEvent(ServiceEvent{Type: EventTypePut, Service: instance("c", c.addr, "3")})
+	require.Len(t, cc.lastState.Addresses, 2)
+	require.Equal(t, map[string]int{a.addr: 25, c.addr: 75}, pickCounts())
+}
+
+func TestParseWeight(t *testing.T) {
+	require.EqualValues(t, 1, parseWeight(nil))
+	require.EqualValues(t, 1, parseWeight(map[string]string{MetadataWeight: "0"}))
+	require.EqualValues(t, 1, parseWeight(map[string]string{MetadataWeight: "abc"}))
+	require.EqualValues(t, 7, parseWeight(map[string]string{MetadataWeight: "7"}))
+}
+
+// recordingMeter 记录 Gauge 最新值与 Counter 累计值的测试 Meter，按 "name{k=v,...}" 聚合
+type recordingMeter struct {
+	metrics.Meter
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+func newRecordingMeter() *recordingMeter {
+	return &recordingMeter{Meter: metrics.Discard(), values: make(map[string]float64)}
+}
+
+func (m *recordingMeter) Counter(name, desc string, opts ...metrics.MetricOption) (metrics.Counter, error) {
+	return &recordingMetric{meter: m, name: name}, nil
+}
+
+func (m *recordingMeter) Gauge(name, desc string, opts ...metrics.MetricOption) (metrics.Gauge, error) {
+	return &recordingMetric{meter: m, name: name}, nil
+}
+
+func (m *recordingMeter) get(name string, labels ...metrics.Label) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.values[metricKey(name, labels)]
+}
+
+func metricKey(name string, labels []metrics.Label) string {
+	key := name + "{"
+	for i, l := range labels {
+		if i > 0 {
+			key += ","
+		}
+		key += l.Key + "=" + l.Value
+	}
+	return key + "}"
+}
+
+// recordingMetric 同时实现 Counter 与 Gauge
+type recordingMetric struct {
+	meter *recordingMeter
+	name  string
+}
+
+func (c *recordingMetric) update(labels []metrics.Label, fn func(float64) float64) {
+	c.meter.mu.Lock()
+	defer c.meter.mu.Unlock()
+	key := metricKey(c.name, labels)
+	c.meter.values[key] = fn(c.meter.values[key])
+}
+
+func (c *recordingMetric) Inc(ctx context.Context, labels ...metrics.Label) {
+	c.Add(ctx, 1, labels...)
+}
+
+func (c *recordingMetric) Add(ctx context.Context, val float64, labels ...metrics.Label) {
+	c.update(labels, func(v float64) float64 { return v + val })
+}
+
+func (c *recordingMetric) Dec(ctx context.Context, labels ...metrics.Label) {
+	c.update(labels, func(v float64) float64 { return v - 1 })
+}
+
+func (c *recordingMetric) Set(ctx context.Context, val float64, labels ...metrics.Label) {
+	c.update(labels, func(float64) float64 { return val })
+}
+
+// TestRegistryMetrics 测试注入 Meter 后的实例数与事件指标
+func TestRegistryMetrics(t *testing.T) {
+	meter := newRecordingMeter()
+	reg, err := New(setupEtcdConn(t), &Config{
+		Namespace:     "/test/metrics",
+		DefaultTTL:    10 * time.Second,
+		RetryInterval: 500 * time.Millisecond,
+	}, WithLogger(testkit.NewLogger()), WithMeter(meter))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = reg.Close() })
+
+	ctx := context.Background()
+	service := metrics.L(LabelService, "metrics-test")
+	// Watch 协程也会刷新实例数，因此按最终值断言
+	requireInstances := func(want float64) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			return meter.get(MetricServiceInstances, service) == want
+		}, 2*time.Second, 10*time.Millisecond)
+	}
+
+	eventCh, err := reg.Watch(ctx, "metrics-test")
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	newInstance := func(id string) *ServiceInstance {
+		return &ServiceInstance{ID: id, Name: "metrics-test", Endpoints: []string{"grpc://127.0.0.1:9100"}}
+	}
+
+	// 注册后实例数上升
+	require.NoError(t, reg.Register(ctx, newInstance("metrics-001"), 10*time.Second))
+	requireInstances(1)
+	require.NoError(t, reg.Register(ctx, newInstance("metrics-002"), 10*time.Second))
+	requireInstances(2)
+	require.Equal(t, 2.0, meter.get(MetricRegister, service))
+
+	// 注销后实例数下降
+	require.NoError(t, reg.Deregister(ctx, "metrics-001"))
+	requireInstances(1)
+	require.Equal(t, 1.0, meter.get(MetricDeregister, service))
+
+	for range 3 {
+		waitForRegistryEvent(t, eventCh, 2*time.Second)
+	}
+	require.Eventually(t, func() bool {
+		return meter.get(MetricWatchEvents, service, metrics.L(LabelType, string(EventTypePut))) == 2 &&
+			meter.get(MetricWatchEvents, service, metrics.L(LabelType, string(EventTypeDelete))) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	list, err := reg.GetService(ctx, "metrics-test")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	requireInstances(1)
+}
+
+// TestRegistryMetricsSnapshotDiff 测试 compaction 补发事件同样计入 Watch 事件指标
+func TestRegistryMetricsSnapshotDiff(t *testing.T) {
+	meter := newRecordingMeter()
+	m, err := newRegistryMetrics(meter)
+	require.NoError(t, err)
+	reg := &etcdRegistry{logger: testkit.NewLogger(), metrics: m}
+
+	known := map[string]*ServiceInstance{"svc-001": {ID: "svc-001", Name: "diff-test"}}
+	kvs := []*mvccpb.KeyValue{
+		mustKV(t, &ServiceInstance{ID: "svc-002", Name: "diff-test", Endpoints: []string{"grpc://127.0.0.1:19002"}}),
+		mustKV(t, &ServiceInstance{ID: "svc-003", Name: "diff-test", Endpoints: []string{"grpc://127.0.0.1:19003"}}),
+	}
+	eventCh := make(chan ServiceEvent, 4)
+	require.NoError(t, reg.emitSnapshotDiff(context.Background(), "diff-test", eventCh, known, kvs))
+
+	service := metrics.L(LabelService, "diff-test")
+	require.Equal(t, 2.0, meter.get(MetricWatchEvents, service, metrics.L(LabelType, string(EventTypePut))))
+	require.Equal(t, 1.0, meter.get(MetricWatchEvents, service, metrics.L(LabelType, string(EventTypeDelete))))
+
+	// 未注入 Meter 时指标为 nil，调用安全
+	var none *registryMetrics
+	none.setInstances(context.Background(), "diff-test", 1)
+	none.watchEvent(context.Background(), "diff-test", EventTypePut)
+}
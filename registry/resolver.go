@@ -162,11 +162,7 @@ func (r *etcdResolver) initializeCache() {
 			if addr != "" {
 				// 使用 instanceID 作为 key，一个实例可能有多个 endpoint
 				key := instance.ID + "_" + addr
-				r.localCache[key] = resolver.Address{
-					Addr:       addr,
-					ServerName: instance.Name,
-					Attributes: nil,
-				}
+				r.localCache[key] = newResolverAddress(instance, addr)
 			}
 		}
 	}
@@ -191,16 +187,12 @@ func (r *etcdResolver) handleEvent(event ServiceEvent) {
 
 	switch event.Type {
 	case EventTypePut:
-		// 服务注册或更新
+		// 服务注册或更新（包括 UpdateMetadata 调整权重），覆盖同一实例的地址
 		for _, endpoint := range event.Service.Endpoints {
 			addr := parseGRPCEndpoint(endpoint)
 			if addr != "" {
 				key := event.Service.ID + "_" + addr
-				r.localCache[key] = resolver.Address{
-					Addr:       addr,
-					ServerName: event.Service.Name,
-					Attributes: nil,
-				}
+				r.localCache[key] = newResolverAddress(event.Service, addr)
 			}
 		}
 		r.registry.logger.Debug("resolver cache updated (PUT)",
@@ -260,6 +252,14 @@ func (r *etcdResolver) Close() {
 	r.cancel()
 }
 
+// newResolverAddress 构建 resolver 地址，携带实例权重
+func newResolverAddress(instance *ServiceInstance, addr string) resolver.Address {
+	return withWeight(resolver.Address{
+		Addr:       addr,
+		ServerName: instance.Name,
+	}, parseWeight(instance.Metadata))
+}
+
 // parseGRPCEndpoint 解析 gRPC endpoint 地址。
 // 支持格式: grpc://host:port, host:port
 func parseGRPCEndpoint(endpoint string) string {
@@ -0,0 +1,219 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// deregisterTimeout ctx 结束后自动注销使用的超时时间
+const deregisterTimeout = 5 * time.Second
+
+// virtualInterfacePrefixes 自动探测 IP 时跳过的虚拟网卡名称前缀（容器网桥、veth 等）
+var virtualInterfacePrefixes = []string{"docker", "br-", "veth", "virbr", "cni", "flannel"}
+
+// RegisterOptions SelfRegister 的参数
+type RegisterOptions struct {
+	// ServiceName 服务名称，必填
+	ServiceName string
+	// ID 实例 ID，留空时使用 "{ServiceName}-{Host}-{Port}"
+	ID string
+	// Version 版本号
+	Version string
+	// Metadata 元数据
+	Metadata map[string]string
+	// Dependencies 本服务调用的下游服务名，用于 Topology
+	Dependencies []string
+	// Port 服务监听端口，必填
+	Port int
+	// Scheme 地址协议前缀，默认 "grpc"；registry 当前只接受 gRPC 地址
+	Scheme string
+	// Host 显式指定注册地址，AutoDetectIP 为 false 时必填
+	Host string
+	// AutoDetectIP 自动探测本机可路由 IP，排除 loopback 与容器网桥，优先 IPv4
+	AutoDetectIP bool
+	// PreferInterface 只从指定网卡探测地址，如 "eth0"
+	PreferInterface string
+	// TTL 租约时长，0 表示使用 Config.DefaultTTL
+	TTL time.Duration
+	// ReadyCheck 非空时等待其返回 true 再注册，语义同 WithReadyCheck
+	ReadyCheck func() bool
+	// ReadyTimeout 等待就绪的超时时间，0 表示只受 ctx 控制
+	ReadyTimeout time.Duration
+}
+
+// SelfRegister 按 opts 生成本实例的 endpoint 并注册
+//
+// AutoDetectIP 为 true 时自动探测本机 IP，否则使用 opts.Host。注册成功后，
+// ctx 结束（例如 signal.NotifyContext 收到退出信号）时自动注销；Close 同样会撤销租约。
+// 返回实际注册的服务实例。
+func (r *etcdRegistry) SelfRegister(ctx context.Context, opts RegisterOptions) (*ServiceInstance, error) {
+	if err := r.ensureOpen(); err != nil {
+		return nil, err
+	}
+
+	service, err := buildSelfInstance(opts, listInterfaces)
+	if err != nil {
+		return nil, err
+	}
+	var regOpts []RegisterOption
+	if opts.ReadyCheck != nil {
+		regOpts = append(regOpts, WithReadyCheck(opts.ReadyCheck, opts.ReadyTimeout))
+	}
+	if err := r.Register(ctx, service, opts.TTL, regOpts...); err != nil {
+		return nil, err
+	}
+	r.logger.Info("service self registered",
+		clog.String("service_id", service.ID),
+		clog.String("endpoint", service.Endpoints[0]))
+
+	r.wg.Go(func() {
+		select {
+		case <-ctx.Done():
+		case <-r.stopChan:
+			// Close 会统一撤销租约
+			return
+		}
+		deregCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deregisterTimeout)
+		defer cancel()
+		if err := r.Deregister(deregCtx, service.ID); err != nil && !errors.Is(err, ErrServiceNotFound) && !errors.Is(err, ErrRegistryClosed) {
+			r.logger.Warn("failed to deregister self registered service",
+				clog.String("service_id", service.ID),
+				clog.Error(err))
+		}
+	})
+	return cloneServiceInstance(service), nil
+}
+
+// buildSelfInstance 根据 RegisterOptions 构建服务实例
+func buildSelfInstance(opts RegisterOptions, lister func() ([]netInterface, error)) (*ServiceInstance, error) {
+	if opts.ServiceName == "" {
+		return nil, xerrors.Wrap(ErrInvalidServiceInstance, "service name is required")
+	}
+	if opts.Port <= 0 || opts.Port > 65535 {
+		return nil, xerrors.Wrapf(ErrInvalidServiceInstance, "invalid port: %d", opts.Port)
+	}
+
+	host := opts.Host
+	if opts.AutoDetectIP {
+		ifaces, err := lister()
+		if err != nil {
+			return nil, xerrors.Wrap(err, "list network interfaces failed")
+		}
+		ip, err := detectIP(ifaces, opts.PreferInterface)
+		if err != nil {
+			return nil, err
+		}
+		host = ip.String()
+	}
+	if host == "" {
+		return nil, xerrors.Wrap(ErrInvalidServiceInstance, "host is required when AutoDetectIP is false")
+	}
+
+	scheme := opts.Scheme
+	if scheme == "" {
+		scheme = "grpc"
+	}
+	port := strconv.Itoa(opts.Port)
+
+	id := opts.ID
+	if id == "" {
+		id = fmt.Sprintf("%s-%s-%s", opts.ServiceName, host, port)
+	}
+	return &ServiceInstance{
+		ID:           id,
+		Name:         opts.ServiceName,
+		Version:      opts.Version,
+		Metadata:     opts.Metadata,
+		Endpoints:    []string{scheme + "://" + net.JoinHostPort(host, port)},
+		Dependencies: opts.Dependencies,
+	}, nil
+}
+
+// netInterface 参与 IP 探测的网卡信息，便于测试注入
+type netInterface struct {
+	Name  string
+	Flags net.Flags
+	Addrs []net.Addr
+}
+
+// listInterfaces 读取本机网卡列表
+func listInterfaces() ([]netInterface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]netInterface, 0, len(ifaces))
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		result = append(result, netInterface{Name: iface.Name, Flags: iface.Flags, Addrs: addrs})
+	}
+	return result, nil
+}
+
+// detectIP 从网卡列表中选出本机可路由 IP
+//
+// 跳过未启用、loopback 和容器网桥等虚拟网卡，以及 loopback、link-local 地址；
+// 按网卡顺序优先返回 IPv4，没有 IPv4 时返回第一个 IPv6 全局单播地址。
+// prefer 非空时只在该网卡上查找，且不再按名称排除。
+func detectIP(ifaces []netInterface, prefer string) (net.IP, error) {
+	var fallback net.IP
+	for _, iface := range ifaces {
+		if prefer != "" {
+			if iface.Name != prefer {
+				continue
+			}
+		} else if isVirtualInterface(iface.Name) {
+			continue
+		}
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+
+		for _, addr := range iface.Addrs {
+			var ip net.IP
+			switch v := addr.(type) {
+			case *net.IPNet:
+				ip = v.IP
+			case *net.IPAddr:
+				ip = v.IP
+			}
+			if ip == nil || !ip.IsGlobalUnicast() {
+				continue
+			}
+			if ip4 := ip.To4(); ip4 != nil {
+				return ip4, nil
+			}
+			if fallback == nil {
+				fallback = ip
+			}
+		}
+	}
+
+	if fallback != nil {
+		return fallback, nil
+	}
+	if prefer != "" {
+		return nil, xerrors.Wrapf(ErrInvalidServiceInstance, "no routable address on interface %s", prefer)
+	}
+	return nil, xerrors.Wrap(ErrInvalidServiceInstance, "no routable address found")
+}
+
+func isVirtualInterface(name string) bool {
+	for _, prefix := range virtualInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -9,6 +9,9 @@ type ServiceInstance struct {
 	Version   string            `json:"version"`   // 版本号
 	Metadata  map[string]string `json:"metadata"`  // 元数据 (Region, Zone, Weight, Group 等)
 	Endpoints []string          `json:"endpoints"` // 服务地址列表 (如 grpc://192.168.1.10:9090)
+
+	// Dependencies 本服务调用的下游服务名，由 Topology 汇总为依赖图
+	Dependencies []string `json:"dependencies,omitempty"`
 }
 
 // ServiceEvent 表示一次服务变化事件。
@@ -0,0 +1,55 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Topology 汇总命名空间下所有已注册服务声明的依赖
+func (r *etcdRegistry) Topology(ctx context.Context) (map[string][]string, error) {
+	if err := r.ensureOpen(); err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Get(ctx, r.cfg.Namespace+"/", clientv3.WithPrefix())
+	if err != nil {
+		r.logger.Error("failed to get topology", clog.Error(err))
+		return nil, xerrors.Wrap(err, "get topology failed")
+	}
+
+	instances := make([]*ServiceInstance, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var instance ServiceInstance
+		if err := json.Unmarshal(kv.Value, &instance); err != nil {
+			r.logger.Warn("failed to unmarshal service instance",
+				clog.String("key", string(kv.Key)),
+				clog.Error(err))
+			continue
+		}
+		instances = append(instances, &instance)
+	}
+	return buildTopology(instances), nil
+}
+
+// buildTopology 按服务名合并各实例的依赖声明，依赖去重并排序
+//
+// 每个已注册服务都有一项，无依赖时为空切片；被依赖但未注册的服务不单独成项。
+func buildTopology(instances []*ServiceInstance) map[string][]string {
+	topology := make(map[string][]string)
+	for _, instance := range instances {
+		topology[instance.Name] = append(topology[instance.Name], instance.Dependencies...)
+	}
+	for name, deps := range topology {
+		slices.Sort(deps)
+		topology[name] = slices.Compact(deps)
+		if topology[name] == nil {
+			topology[name] = []string{}
+		}
+	}
+	return topology
+}
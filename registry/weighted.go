@@ -0,0 +1,155 @@
+package registry
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+)
+
+const (
+	// MetadataWeight 实例权重所在的元数据键，值为正整数，缺省或非法时按 1 处理
+	MetadataWeight = "weight"
+
+	// WeightedRoundRobin 按实例权重分流的负载均衡策略名称
+	WeightedRoundRobin = "genesis_weighted_round_robin"
+
+	defaultWeight uint32 = 1
+)
+
+func init() {
+	balancer.Register(&weightedBuilder{})
+}
+
+// WithWeightedRoundRobin 返回启用权重分流的 DialOption，可直接传给 GetConnection
+//
+// 流量按 Metadata["weight"] 的比例平滑轮询分配到各实例，通过 UpdateMetadata
+// 调整权重后，resolver 推送新状态即时生效，已有连接保持不变。
+func WithWeightedRoundRobin() grpc.DialOption {
+	return grpc.WithDefaultServiceConfig(fmt.Sprintf(`{"loadBalancingConfig":[{"%s":{}}]}`, WeightedRoundRobin))
+}
+
+// parseWeight 从元数据解析权重
+func parseWeight(metadata map[string]string) uint32 {
+	w, err := strconv.ParseUint(metadata[MetadataWeight], 10, 32)
+	if err != nil || w == 0 {
+		return defaultWeight
+	}
+	return uint32(w)
+}
+
+// weightAttrKey resolver.Address.BalancerAttributes 中权重的键
+type weightAttrKey struct{}
+
+// withWeight 把权重写入地址的 BalancerAttributes
+//
+// BalancerAttributes 不参与地址比较，权重变化不会导致 SubConn 重建。
+func withWeight(addr resolver.Address, weight uint32) resolver.Address {
+	addr.BalancerAttributes = addr.BalancerAttributes.WithValue(weightAttrKey{}, weight)
+	return addr
+}
+
+// addressWeight 读取地址上的权重
+func addressWeight(addr resolver.Address) uint32 {
+	if w, ok := addr.BalancerAttributes.Value(weightAttrKey{}).(uint32); ok && w > 0 {
+		return w
+	}
+	return defaultWeight
+}
+
+// weightedBuilder 构建权重轮询 balancer
+type weightedBuilder struct{}
+
+func (weightedBuilder) Name() string {
+	return WeightedRoundRobin
+}
+
+func (weightedBuilder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	pb := &weightedPickerBuilder{weights: make(map[string]uint32)}
+	return &weightedBalancer{
+		Balancer: base.NewBalancerBuilder(WeightedRoundRobin, pb, base.Config{HealthCheck: true}).Build(cc, opts),
+		pb:       pb,
+	}
+}
+
+// weightedBalancer 在 base balancer 之上记录每个地址的最新权重
+//
+// base balancer 按地址复用 SubConn，picker 拿到的仍是首次创建时的地址，
+// 因此权重单独保存，由 picker 按 Addr 查找。
+type weightedBalancer struct {
+	balancer.Balancer
+	pb *weightedPickerBuilder
+}
+
+func (b *weightedBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	weights := make(map[string]uint32, len(s.ResolverState.Addresses))
+	for _, addr := range s.ResolverState.Addresses {
+		weights[addr.Addr] = addressWeight(addr)
+	}
+	b.pb.setWeights(weights)
+	// base balancer 每次都会重新生成 picker，权重变化随之生效
+	return b.Balancer.UpdateClientConnState(s)
+}
+
+// weightedPickerBuilder 按最新权重构建 picker
+type weightedPickerBuilder struct {
+	mu      sync.RWMutex
+	weights map[string]uint32 // Addr -> weight
+}
+
+func (pb *weightedPickerBuilder) setWeights(weights map[string]uint32) {
+	pb.mu.Lock()
+	pb.weights = weights
+	pb.mu.Unlock()
+}
+
+func (pb *weightedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	pb.mu.RLock()
+	defer pb.mu.RUnlock()
+	p := &weightedPicker{items: make([]*weightedItem, 0, len(info.ReadySCs))}
+	for sc, sci := range info.ReadySCs {
+		w, ok := pb.weights[sci.Address.Addr]
+		if !ok {
+			w = addressWeight(sci.Address)
+		}
+		p.items = append(p.items, &weightedItem{sc: sc, weight: int64(w)})
+		p.total += int64(w)
+	}
+	return p
+}
+
+type weightedItem struct {
+	sc      balancer.SubConn
+	weight  int64
+	current int64
+}
+
+// weightedPicker 平滑加权轮询（nginx smooth weighted round-robin）
+type weightedPicker struct {
+	mu    sync.Mutex
+	items []*weightedItem
+	total int64
+}
+
+func (p *weightedPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var best *weightedItem
+	for _, item := range p.items {
+		item.current += item.weight
+		if best == nil || item.current > best.current {
+			best = item
+		}
+	}
+	best.current -= p.total
+	return balancer.PickResult{SubConn: best.sc}, nil
+}
@@ -0,0 +1,63 @@
+package telemetry
+
+import (
+	"strings"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// Config 定义一次性初始化日志、指标、链路追踪所需的参数。
+//
+// 服务名、版本、环境写入共享的 OpenTelemetry resource，trace 与 metrics 使用同一份 resource；
+// Endpoint 为空时不导出 trace，只在本地生成 TraceID（等同于 trace.Discard）。
+type Config struct {
+	ServiceName string `mapstructure:"service_name"` // 服务名，必填
+	Version     string `mapstructure:"version"`      // 服务版本，写入 service.version
+	Environment string `mapstructure:"environment"`  // 部署环境，写入 deployment.environment，并作为日志 env 字段
+
+	Endpoint string  `mapstructure:"endpoint"` // OTLP gRPC trace 导出地址，为空时不导出
+	Insecure bool    `mapstructure:"insecure"` // OTLP 连接是否关闭 TLS
+	Sampler  float64 `mapstructure:"sampler"`  // trace 采样率 (0, 1]，为 0 时使用 1.0
+	Batcher  string  `mapstructure:"batcher"`  // batch 或 simple，默认 batch
+
+	MetricsPort   int    `mapstructure:"metrics_port"`   // Prometheus 端口，<= 0 时不启动 HTTP 服务
+	MetricsPath   string `mapstructure:"metrics_path"`   // Prometheus 路径，默认 /metrics
+	EnableRuntime bool   `mapstructure:"enable_runtime"` // 是否开启 runtime 指标
+
+	Log *clog.Config `mapstructure:"log"` // 日志配置，为 nil 时使用 JSON 格式输出到 stdout
+}
+
+// NewDevDefaultConfig 开发环境默认配置：本地 collector、console 彩色日志、9090 暴露指标
+func NewDevDefaultConfig(serviceName string) *Config {
+	return &Config{
+		ServiceName: serviceName,
+		Version:     "dev",
+		Environment: "dev",
+		Endpoint:    "localhost:4317",
+		Insecure:    true,
+		Sampler:     1.0,
+		MetricsPort: 9090,
+		MetricsPath: "/metrics",
+		Log:         clog.NewDevDefaultConfig(""),
+	}
+}
+
+func (c *Config) setDefaults() {
+	if c.Sampler == 0 {
+		c.Sampler = 1.0
+	}
+	if c.MetricsPath == "" {
+		c.MetricsPath = "/metrics"
+	}
+}
+
+func (c *Config) validate() error {
+	if c == nil {
+		return xerrors.New("config is required")
+	}
+	if strings.TrimSpace(c.ServiceName) == "" {
+		return xerrors.New("service_name is required")
+	}
+	return nil
+}
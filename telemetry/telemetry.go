@@ -0,0 +1,134 @@
+// Package telemetry 一次性初始化 Genesis 的日志、指标与链路追踪。
+//
+// 各服务启动时通常都要重复初始化 clog、metrics、trace 三件套，并手动把它们关联起来。
+// Bootstrap 按同一份 Config 完成这三步：
+//   - trace 与 metrics 共享同一份 resource（service.name / service.version / deployment.environment）；
+//   - logger 开启 WithTraceContext，带 span 的 ctx 打出的日志自动包含 trace_id / span_id；
+//   - 返回的 shutdown 聚合关闭 meter、tracer provider 与 logger，可重复调用。
+//
+// telemetry 沿用 trace 与 metrics 的全局模式：Bootstrap 会安装全局 TracerProvider、
+// 传播器与 MeterProvider，应在应用启动时只调用一次。
+//
+// 典型用法：
+//
+//	logger, meter, tracer, shutdown, err := telemetry.Bootstrap(&telemetry.Config{
+//	    ServiceName: "order-service",
+//	    Environment: "prod",
+//	    Endpoint:    "otel-collector:4317",
+//	    MetricsPort: 9090,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer shutdown(context.Background())
+package telemetry
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+	"github.com/ceyewan/genesis/trace"
+	"github.com/ceyewan/genesis/xerrors"
+)
+
+// Bootstrap 按 cfg 初始化 logger、meter 与 tracer，返回聚合的 shutdown 函数。
+//
+// 任一组件初始化失败时，已初始化的组件会被关闭后再返回错误。
+// shutdown 依次关闭 meter、tracer provider 与 logger，合并返回所有错误；重复调用只执行一次，
+// 之后返回第一次的结果。
+func Bootstrap(cfg *Config) (clog.Logger, metrics.Meter, oteltrace.Tracer, func(ctx context.Context) error, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, nil, nil, nil, err
+	}
+	cfg.setDefaults()
+
+	res, err := newResource(cfg)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	var traceShutdown func(context.Context) error
+	if cfg.Endpoint == "" {
+		traceShutdown, err = trace.Discard(cfg.ServiceName, trace.WithResource(res))
+	} else {
+		traceShutdown, err = trace.Init(&trace.Config{
+			ServiceName: cfg.ServiceName,
+			Endpoint:    cfg.Endpoint,
+			Sampler:     cfg.Sampler,
+			Batcher:     cfg.Batcher,
+			Insecure:    cfg.Insecure,
+		}, trace.WithResource(res))
+	}
+	if err != nil {
+		return nil, nil, nil, nil, xerrors.Wrap(err, "init trace")
+	}
+
+	meter, err := metrics.New(&metrics.Config{
+		ServiceName:   cfg.ServiceName,
+		Version:       cfg.Version,
+		Port:          cfg.MetricsPort,
+		Path:          cfg.MetricsPath,
+		EnableRuntime: cfg.EnableRuntime,
+	}, metrics.WithResource(res))
+	if err != nil {
+		_ = traceShutdown(context.Background())
+		return nil, nil, nil, nil, xerrors.Wrap(err, "init metrics")
+	}
+
+	logCfg := cfg.Log
+	if logCfg == nil {
+		logCfg = &clog.Config{Level: "info", Format: "json", Output: "stdout"}
+	}
+	logger, err := clog.New(logCfg, clog.WithNamespace(cfg.ServiceName), clog.WithTraceContext())
+	if err != nil {
+		_ = meter.Shutdown(context.Background())
+		_ = traceShutdown(context.Background())
+		return nil, nil, nil, nil, xerrors.Wrap(err, "init logger")
+	}
+	if cfg.Environment != "" {
+		logger = logger.With(clog.String("env", cfg.Environment))
+	}
+
+	tracer := otel.GetTracerProvider().Tracer(cfg.ServiceName)
+
+	var (
+		once        sync.Once
+		shutdownErr error
+	)
+	shutdown := func(ctx context.Context) error {
+		once.Do(func() {
+			logger.Flush()
+			shutdownErr = xerrors.Combine(
+				meter.Shutdown(ctx),
+				traceShutdown(ctx),
+				logger.Close(),
+			)
+		})
+		return shutdownErr
+	}
+	return logger, meter, tracer, shutdown, nil
+}
+
+// newResource 构建 trace 与 metrics 共享的 resource
+func newResource(cfg *Config) (*resource.Resource, error) {
+	attrs := []attribute.KeyValue{semconv.ServiceNameKey.String(cfg.ServiceName)}
+	if cfg.Version != "" {
+		attrs = append(attrs, semconv.ServiceVersionKey.String(cfg.Version))
+	}
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentKey.String(cfg.Environment))
+	}
+	res, err := resource.New(context.Background(), resource.WithAttributes(attrs...))
+	if err != nil {
+		return nil, xerrors.Wrap(err, "create resource")
+	}
+	return res, nil
+}
@@ -0,0 +1,86 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+
+	"github.com/ceyewan/genesis/clog"
+	"github.com/ceyewan/genesis/metrics"
+)
+
+func TestBootstrapValidatesConfig(t *testing.T) {
+	for _, cfg := range []*Config{nil, {}, {ServiceName: "  "}} {
+		_, _, _, _, err := Bootstrap(cfg)
+		require.Error(t, err, "Bootstrap(%+v) 应返回校验错误", cfg)
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	logger, meter, tracer, shutdown, err := Bootstrap(&Config{
+		ServiceName: "order-service",
+		Version:     "v1.2.3",
+		Environment: "test",
+		Log:         &clog.Config{Level: "info", Format: "json", Output: logPath},
+	})
+	require.NoError(t, err)
+
+	tracerProvider, meterProvider := otel.GetTracerProvider(), otel.GetMeterProvider()
+
+	ctx, span := tracer.Start(context.Background(), "work")
+	require.True(t, span.SpanContext().IsValid())
+
+	t.Run("tracer 与 metrics 共享 resource", func(t *testing.T) {
+		ro, ok := span.(sdktrace.ReadOnlySpan)
+		require.True(t, ok, "span %T 不是 sdk span", span)
+		want := map[string]string{
+			string(semconv.ServiceNameKey):           "order-service",
+			string(semconv.ServiceVersionKey):        "v1.2.3",
+			string(semconv.DeploymentEnvironmentKey): "test",
+		}
+		for _, kv := range ro.Resource().Attributes() {
+			if v, ok := want[string(kv.Key)]; ok && kv.Value.AsString() == v {
+				delete(want, string(kv.Key))
+			}
+		}
+		require.Empty(t, want, "resource 缺少属性")
+	})
+
+	t.Run("meter 可用", func(t *testing.T) {
+		counter, err := meter.Counter("bootstrap_test_total", "test counter")
+		require.NoError(t, err)
+		counter.Inc(ctx, metrics.L("result", "ok"))
+	})
+
+	logger.InfoContext(ctx, "order created")
+	span.End()
+
+	require.NoError(t, shutdown(context.Background()))
+
+	t.Run("logger 自动带 trace", func(t *testing.T) {
+		data, err := os.ReadFile(logPath)
+		require.NoError(t, err)
+		line := string(data)
+		for _, want := range []string{
+			`"trace_id":"` + span.SpanContext().TraceID().String() + `"`,
+			`"span_id":"` + span.SpanContext().SpanID().String() + `"`,
+			`"env":"test"`,
+			`order-service`,
+		} {
+			require.Contains(t, line, want)
+		}
+	})
+
+	t.Run("shutdown 关闭所有组件且幂等", func(t *testing.T) {
+		require.True(t, otel.GetTracerProvider() != tracerProvider, "shutdown 后应重置全局 TracerProvider")
+		require.True(t, otel.GetMeterProvider() != meterProvider, "shutdown 后应重置全局 MeterProvider")
+		require.NoError(t, shutdown(context.Background()), "重复 shutdown")
+	})
+}
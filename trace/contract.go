@@ -8,6 +8,16 @@ const (
 	AttrMessagingConsumerGroup = "messaging.consumer.group"
 )
 
+const (
+	// HTTP 客户端语义属性键
+	AttrHTTPRequestMethod      = "http.request.method"
+	AttrHTTPResponseStatusCode = "http.response.status_code"
+	AttrURLFull                = "url.full"
+	AttrServerAddress          = "server.address"
+	// AttrHTTPClientDuration 请求耗时（秒）
+	AttrHTTPClientDuration = "http.client.request.duration"
+)
+
 const (
 	// 常见的消息系统
 	MessagingSystemNATS = "nats"
@@ -45,3 +55,11 @@ func SpanNameMQConsume(destination string) string {
 	}
 	return "mq.consume " + destination
 }
+
+// SpanNameHTTPClient 返回出站 HTTP 请求的标准 Span Name
+func SpanNameHTTPClient(method string) string {
+	if method == "" {
+		return "HTTP"
+	}
+	return "HTTP " + method
+}
@@ -0,0 +1,72 @@
+package trace
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// HTTPTransport 返回带链路追踪的 http.RoundTripper，next 为 nil 时使用 http.DefaultTransport
+//
+// 每个出站请求都会以 ctx 中的当前 span 为父创建一个 client span，并通过全局传播器
+// 把 traceparent 等头注入请求（不修改调用方传入的 *http.Request）。span 记录方法、
+// URL、状态码与耗时；5xx 响应和传输错误会把 span 标记为错误。
+//
+// 耗时统计到收到响应头为止，不包含读取响应体的时间。
+//
+//	client := &http.Client{Transport: trace.HTTPTransport(nil)}
+func HTTPTransport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &httpTransport{next: next}
+}
+
+// httpTransport 出站 HTTP 请求追踪包装
+type httpTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *httpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := normalizeContext(req.Context())
+	tracer := normalizeTracer(nil)
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	ctx, span := tracer.Start(ctx, SpanNameHTTPClient(method),
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(
+			attribute.String(AttrHTTPRequestMethod, method),
+			attribute.String(AttrURLFull, req.URL.Redacted()),
+			attribute.String(AttrServerAddress, req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	// RoundTripper 不应修改原请求，克隆后再注入传播头
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	span.SetAttributes(attribute.Float64(AttrHTTPClientDuration, time.Since(start).Seconds()))
+	if err != nil {
+		MarkSpanError(span, err)
+		return resp, err
+	}
+
+	span.SetAttributes(attribute.Int(AttrHTTPResponseStatusCode, resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", resp.StatusCode))
+	}
+	return resp, nil
+}
@@ -0,0 +1,118 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+func TestHTTPTransport(t *testing.T) {
+	tracer, recorder := setupTracerForTest(t)
+
+	var gotTraceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceparent = r.Header.Get("traceparent")
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: HTTPTransport(nil)}
+
+	call := func(path string) (oteltrace.SpanContext, sdktrace.ReadOnlySpan) {
+		t.Helper()
+		before := len(recorder.Ended())
+
+		ctx, parent := tracer.Start(context.Background(), "handler")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatalf("NewRequest() error = %v", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		_ = resp.Body.Close()
+		parent.End()
+
+		if req.Header.Get("traceparent") != "" {
+			t.Fatalf("original request should not be modified")
+		}
+
+		ended := recorder.Ended()[before:]
+		if len(ended) != 2 {
+			t.Fatalf("ended spans = %d, want 2", len(ended))
+		}
+		return parent.SpanContext(), ended[0]
+	}
+
+	t.Run("注入 traceparent 并创建子 span", func(t *testing.T) {
+		parentSC, span := call("/ok")
+
+		if gotTraceparent == "" {
+			t.Fatalf("traceparent header should be injected")
+		}
+		if span.SpanKind() != oteltrace.SpanKindClient {
+			t.Fatalf("span kind = %v, want client", span.SpanKind())
+		}
+		if span.Name() != SpanNameHTTPClient(http.MethodGet) {
+			t.Fatalf("span name = %q, want %q", span.Name(), SpanNameHTTPClient(http.MethodGet))
+		}
+		if span.Parent().SpanID() != parentSC.SpanID() || span.SpanContext().TraceID() != parentSC.TraceID() {
+			t.Fatalf("client span should be a child of the current span")
+		}
+		if want := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"; gotTraceparent != want {
+			t.Fatalf("traceparent = %q, want %q", gotTraceparent, want)
+		}
+		if span.Status().Code == codes.Error {
+			t.Fatalf("2xx span should not be marked as error")
+		}
+		if got := spanAttr(span, AttrHTTPResponseStatusCode); got.AsInt64() != http.StatusOK {
+			t.Fatalf("status code attr = %v, want 200", got.AsInt64())
+		}
+		if got := spanAttr(span, AttrHTTPClientDuration); got.AsFloat64() <= 0 {
+			t.Fatalf("duration attr = %v, want > 0", got.AsFloat64())
+		}
+	})
+
+	t.Run("5xx 标记错误", func(t *testing.T) {
+		_, span := call("/fail")
+
+		if span.Status().Code != codes.Error {
+			t.Fatalf("span status = %v, want error", span.Status().Code)
+		}
+		if got := spanAttr(span, AttrHTTPResponseStatusCode); got.AsInt64() != http.StatusInternalServerError {
+			t.Fatalf("status code attr = %v, want 500", got.AsInt64())
+		}
+	})
+
+	t.Run("传输错误标记错误", func(t *testing.T) {
+		before := len(recorder.Ended())
+		req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1:1", nil)
+		if _, err := client.Do(req); err == nil {
+			t.Fatalf("Do() error = nil, want connection error")
+		}
+		ended := recorder.Ended()[before:]
+		if len(ended) != 1 || ended[0].Status().Code != codes.Error {
+			t.Fatalf("transport error should end one error span")
+		}
+	})
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key string) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if string(kv.Key) == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
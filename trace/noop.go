@@ -18,8 +18,8 @@ import (
 // Discard 仍然采用全局模式：它会安装全局 TracerProvider 和全局传播器。
 // 因此它不是“局部无副作用”的 helper，而是“安装一个不导出的全局 provider”。
 // 返回的 shutdown 在关闭该 provider 后，会在必要时把全局 tracing 状态重置为
-// 安全默认值。
-func Discard(serviceName string) (func(context.Context) error, error) {
+// 安全默认值。opts 与 Init 相同，例如 WithSlowSpanLog。
+func Discard(serviceName string, opts ...Option) (func(context.Context) error, error) {
 	ctx := context.Background()
 
 	resOpts := []resource.Option{}
@@ -34,10 +34,13 @@ func Discard(serviceName string) (func(context.Context) error, error) {
 		return nil, xerrors.Wrap(err, "create resource")
 	}
 
-	tp := sdktrace.NewTracerProvider(
+	tpOpts := []sdktrace.TracerProviderOption{
 		sdktrace.WithResource(res),
 		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(1.0))),
-	)
+	}
+	tpOpts = append(tpOpts, applyOptions(opts...).tracerProviderOptions()...)
+
+	tp := sdktrace.NewTracerProvider(tpOpts...)
 
 	otel.SetTracerProvider(tp)
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
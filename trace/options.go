@@ -0,0 +1,64 @@
+package trace
+
+import (
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+// Option 配置 Init / Discard 的可选行为
+type Option func(*options)
+
+// options 内部选项（内部使用，小写）
+type options struct {
+	spanProcessors []sdktrace.SpanProcessor
+	resource       *resource.Resource
+}
+
+// WithSlowSpanLog 在 span 结束时，若耗时达到 threshold，用 logger 记录一条 Warn 日志。
+//
+// 日志包含 trace_id、span_id、span_name 和 duration 字段，便于从日志反查慢链路。
+// 基于 SpanProcessor 实现，只对被采样的 span 生效。threshold<=0 或 logger 为 nil 时忽略。
+func WithSlowSpanLog(threshold time.Duration, logger clog.Logger) Option {
+	return func(o *options) {
+		if threshold <= 0 || logger == nil {
+			return
+		}
+		o.spanProcessors = append(o.spanProcessors, newSlowSpanProcessor(threshold, logger))
+	}
+}
+
+// WithResource 使用外部构建的 resource 代替默认的 service.name resource。
+//
+// 用于让 trace 与 metrics 共享同一份 resource（服务名、版本、环境等属性）。nil 时忽略。
+func WithResource(res *resource.Resource) Option {
+	return func(o *options) {
+		if res != nil {
+			o.resource = res
+		}
+	}
+}
+
+func applyOptions(opts ...Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// tracerProviderOptions 将选项转换为 TracerProvider 配置
+func (o *options) tracerProviderOptions() []sdktrace.TracerProviderOption {
+	tpOpts := make([]sdktrace.TracerProviderOption, 0, len(o.spanProcessors)+1)
+	for _, sp := range o.spanProcessors {
+		tpOpts = append(tpOpts, sdktrace.WithSpanProcessor(sp))
+	}
+	// 追加在默认 resource 之后，后设置的 WithResource 生效
+	if o.resource != nil {
+		tpOpts = append(tpOpts, sdktrace.WithResource(o.resource))
+	}
+	return tpOpts
+}
@@ -0,0 +1,45 @@
+package trace
+
+import (
+	"context"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+// slowSpanProcessor 在 span 结束时检查耗时，超过阈值则记录 Warn 日志
+type slowSpanProcessor struct {
+	threshold time.Duration
+	logger    clog.Logger
+}
+
+func newSlowSpanProcessor(threshold time.Duration, logger clog.Logger) *slowSpanProcessor {
+	return &slowSpanProcessor{
+		threshold: threshold,
+		logger:    logger,
+	}
+}
+
+func (p *slowSpanProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (p *slowSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	duration := s.EndTime().Sub(s.StartTime())
+	if duration < p.threshold {
+		return
+	}
+
+	sc := s.SpanContext()
+	p.logger.Warn("Slow span detected",
+		clog.String("trace_id", sc.TraceID().String()),
+		clog.String("span_id", sc.SpanID().String()),
+		clog.String("span_name", s.Name()),
+		clog.Duration("duration", duration),
+		clog.Duration("threshold", p.threshold),
+	)
+}
+
+func (p *slowSpanProcessor) Shutdown(context.Context) error { return nil }
+
+func (p *slowSpanProcessor) ForceFlush(context.Context) error { return nil }
@@ -0,0 +1,67 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+
+	"github.com/ceyewan/genesis/clog"
+)
+
+func TestWithSlowSpanLog(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "trace.log")
+	logger, err := clog.New(&clog.Config{Level: "debug", Format: "json", Output: logPath})
+	if err != nil {
+		t.Fatalf("clog.New() error = %v", err)
+	}
+	defer logger.Close()
+
+	shutdown, err := Discard("test-service", WithSlowSpanLog(50*time.Millisecond, logger))
+	if err != nil {
+		t.Fatalf("Discard() error = %v", err)
+	}
+	defer shutdown(context.Background())
+
+	tracer := otel.Tracer("test")
+
+	_, fast := tracer.Start(context.Background(), "fast-op")
+	fast.End()
+
+	_, slow := tracer.Start(context.Background(), "slow-op")
+	time.Sleep(60 * time.Millisecond)
+	slow.End()
+
+	logger.Flush()
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("read log file error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("log lines = %d, want 1: %s", len(lines), data)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("unmarshal log entry error = %v", err)
+	}
+	if entry["span_name"] != "slow-op" {
+		t.Fatalf("span_name = %v, want slow-op", entry["span_name"])
+	}
+	if entry["trace_id"] != slow.SpanContext().TraceID().String() {
+		t.Fatalf("trace_id = %v, want %s", entry["trace_id"], slow.SpanContext().TraceID())
+	}
+	if entry["level"] != "WARN" {
+		t.Fatalf("level = %v, want WARN", entry["level"])
+	}
+	if _, ok := entry["duration"]; !ok {
+		t.Fatalf("duration field missing: %v", entry)
+	}
+}
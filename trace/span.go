@@ -0,0 +1,65 @@
+package trace
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// 以下函数操作 ctx 中的当前 span，便于业务代码在不持有 span 变量时补充链路信息。
+// ctx 中没有 span 或 span 未被采样时均为安全的 no-op。
+
+// Event 在当前 span 上添加名为 name 的事件
+func Event(ctx context.Context, name string, attrs ...attribute.KeyValue) {
+	span := recordingSpan(ctx)
+	if span == nil {
+		return
+	}
+	span.AddEvent(name, oteltrace.WithAttributes(attrs...))
+}
+
+// SetAttributes 为当前 span 设置属性
+func SetAttributes(ctx context.Context, attrs ...attribute.KeyValue) {
+	span := recordingSpan(ctx)
+	if span == nil {
+		return
+	}
+	span.SetAttributes(attrs...)
+}
+
+// RecordError 在当前 span 上记录 exception 事件，并把 span 状态设为 Error
+//
+// err 为 nil 时不做任何操作；attrs 附加到 exception 事件上。
+func RecordError(ctx context.Context, err error, attrs ...attribute.KeyValue) {
+	span := recordingSpan(ctx)
+	if span == nil || err == nil {
+		return
+	}
+	span.RecordError(err, oteltrace.WithAttributes(attrs...))
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Success 把当前 span 状态设为 Ok
+//
+// 按 OpenTelemetry 约定，Ok 会覆盖此前设置的 Error 状态，只应在确认操作成功时调用。
+func Success(ctx context.Context) {
+	span := recordingSpan(ctx)
+	if span == nil {
+		return
+	}
+	span.SetStatus(codes.Ok, "")
+}
+
+// recordingSpan 返回 ctx 中正在记录的 span，没有时返回 nil
+func recordingSpan(ctx context.Context) oteltrace.Span {
+	if ctx == nil {
+		return nil
+	}
+	span := oteltrace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return nil
+	}
+	return span
+}
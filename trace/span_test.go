@@ -0,0 +1,82 @@
+package trace
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.20.0"
+)
+
+func TestSpanHelpers(t *testing.T) {
+	tracer, recorder := setupTracerForTest(t)
+
+	t.Run("event and attributes", func(t *testing.T) {
+		ctx, span := tracer.Start(context.Background(), "event")
+		Event(ctx, "cache.miss", attribute.String("key", "user:1"))
+		SetAttributes(ctx, attribute.Int("user.id", 1))
+		Success(ctx)
+		span.End()
+
+		spans := recorder.Ended()
+		got := spans[len(spans)-1]
+		if len(got.Events()) != 1 || got.Events()[0].Name != "cache.miss" {
+			t.Fatalf("events = %+v, want single cache.miss event", got.Events())
+		}
+		if attrs := got.Events()[0].Attributes; len(attrs) != 1 || attrs[0] != attribute.String("key", "user:1") {
+			t.Fatalf("event attributes = %v", attrs)
+		}
+		if !hasAttribute(got.Attributes(), attribute.Int("user.id", 1)) {
+			t.Fatalf("span attributes = %v, want user.id=1", got.Attributes())
+		}
+		if got.Status().Code != codes.Ok {
+			t.Fatalf("status = %v, want Ok", got.Status())
+		}
+	})
+
+	t.Run("record error", func(t *testing.T) {
+		ctx, span := tracer.Start(context.Background(), "error")
+		RecordError(ctx, nil)
+		RecordError(ctx, errors.New("db timeout"), attribute.String("db.table", "orders"))
+		span.End()
+
+		spans := recorder.Ended()
+		got := spans[len(spans)-1]
+		if got.Status().Code != codes.Error || got.Status().Description != "db timeout" {
+			t.Fatalf("status = %+v, want Error(db timeout)", got.Status())
+		}
+		if len(got.Events()) != 1 || got.Events()[0].Name != semconv.ExceptionEventName {
+			t.Fatalf("events = %+v, want single exception event", got.Events())
+		}
+		attrs := got.Events()[0].Attributes
+		if !hasAttribute(attrs, semconv.ExceptionMessageKey.String("db timeout")) ||
+			!hasAttribute(attrs, attribute.String("db.table", "orders")) {
+			t.Fatalf("exception attributes = %v", attrs)
+		}
+	})
+
+	t.Run("no span is no-op", func(t *testing.T) {
+		before := len(recorder.Ended())
+		ctx := context.Background()
+		Event(ctx, "ignored")
+		SetAttributes(ctx, attribute.Bool("ignored", true))
+		RecordError(ctx, errors.New("ignored"))
+		Success(ctx)
+		//nolint:staticcheck // 验证 nil ctx 同样安全
+		RecordError(nil, errors.New("ignored"))
+		if after := len(recorder.Ended()); after != before {
+			t.Fatalf("ended spans = %d, want %d", after, before)
+		}
+	})
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, kv := range attrs {
+		if kv == want {
+			return true
+		}
+	}
+	return false
+}
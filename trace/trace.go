@@ -45,22 +45,26 @@ func Extract(ctx context.Context, carrier map[string]string) context.Context {
 //
 // 返回的 shutdown 会关闭底层 provider；若当前全局 TracerProvider 仍指向该
 // 实例，还会将全局 tracing 状态重置为安全默认值。
-func Init(cfg *Config) (func(context.Context) error, error) {
+//
+// opts 支持的选项:
+//   - WithSlowSpanLog(threshold, logger): 慢 span 自动记录日志
+//   - WithResource(res): 使用外部 resource，便于与 metrics 共享
+func Init(cfg *Config, opts ...Option) (func(context.Context) error, error) {
 	if err := validateConfig(cfg); err != nil {
 		return nil, err
 	}
 
 	ctx := context.Background()
 
-	opts := []otlptracegrpc.Option{
+	exporterOpts := []otlptracegrpc.Option{
 		otlptracegrpc.WithEndpoint(cfg.Endpoint),
 		otlptracegrpc.WithTimeout(5 * time.Second),
 	}
 	if cfg.Insecure {
-		opts = append(opts, otlptracegrpc.WithInsecure())
+		exporterOpts = append(exporterOpts, otlptracegrpc.WithInsecure())
 	}
 
-	exporter, err := otlptracegrpc.New(ctx, opts...)
+	exporter, err := otlptracegrpc.New(ctx, exporterOpts...)
 	if err != nil {
 		return nil, xerrors.Wrap(err, "create otlp exporter")
 	}
@@ -84,6 +88,7 @@ func Init(cfg *Config) (func(context.Context) error, error) {
 	} else {
 		tpOpts = append(tpOpts, sdktrace.WithBatcher(exporter))
 	}
+	tpOpts = append(tpOpts, applyOptions(opts...).tracerProviderOptions()...)
 
 	tp := sdktrace.NewTracerProvider(tpOpts...)
 	otel.SetTracerProvider(tp)
@@ -0,0 +1,136 @@
+package xerrors
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// grpcErrorDomain ToGRPCStatus 写入 ErrorInfo 时使用的 domain
+const grpcErrorDomain = "genesis"
+
+// grpcMapping 哨兵错误与 gRPC code 的对应关系，按顺序匹配
+var grpcMapping = []struct {
+	sentinel error
+	code     codes.Code
+}{
+	{ErrNotFound, codes.NotFound},
+	{ErrInvalidInput, codes.InvalidArgument},
+	{ErrConflict, codes.Aborted},
+	{ErrTimeout, codes.DeadlineExceeded},
+	{ErrUnavailable, codes.Unavailable},
+	{context.DeadlineExceeded, codes.DeadlineExceeded},
+	{context.Canceled, codes.Canceled},
+}
+
+// ToGRPCStatus 把错误转换为 gRPC status，用于服务端拦截器统一转换返回值。
+//
+// 转换规则：
+//   - nil 返回 OK
+//   - 匹配哨兵错误时使用对应 code：ErrNotFound→NotFound、ErrInvalidInput→InvalidArgument、
+//     ErrConflict→Aborted、ErrTimeout→DeadlineExceeded、ErrUnavailable→Unavailable，context 错误同理
+//   - 错误链上已带有 gRPC status 时沿用其 code
+//   - 其余错误映射为 Internal
+//
+// status 的 message 为 err.Error()；错误链上有 WithCode 错误码时，以 ErrorInfo.Reason 写入 details。
+func ToGRPCStatus(err error) *status.Status {
+	if err == nil {
+		return status.New(codes.OK, "")
+	}
+
+	code := codes.Internal
+	matched := false
+	for _, m := range grpcMapping {
+		if errors.Is(err, m.sentinel) {
+			code, matched = m.code, true
+			break
+		}
+	}
+	if !matched {
+		if st, ok := status.FromError(err); ok {
+			code = st.Code()
+		}
+	}
+
+	st := status.New(code, err.Error())
+	if reason := GetCode(err); reason != "" {
+		if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: grpcErrorDomain}); detailErr == nil {
+			st = detailed
+		}
+	}
+	return st
+}
+
+// FromGRPCStatus 把 gRPC 调用返回的错误还原为 xerrors 错误，用于客户端拦截器。
+//
+// 还原后的错误 message 与 status 一致，并按 code 匹配对应的哨兵错误，
+// 例如 NotFound 可被 Is(err, ErrNotFound) 命中；details 中的错误码通过 GetCode 取回。
+// 无法识别的 code 不匹配任何哨兵；err 为 nil 或 status 为 OK 时返回 nil，非 gRPC 错误原样返回。
+func FromGRPCStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	if st.Code() == codes.OK {
+		return nil
+	}
+
+	restored := &grpcError{status: st, msg: st.Message(), sentinel: sentinelForCode(st.Code())}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.GetDomain() == grpcErrorDomain && info.GetReason() != "" {
+			// message 已由 CodedError 带上 "[code] " 前缀，去掉后避免重复
+			restored.msg = strings.TrimPrefix(restored.msg, "["+info.GetReason()+"] ")
+			return &CodedError{Code: info.GetReason(), Cause: restored}
+		}
+	}
+	return restored
+}
+
+// sentinelForCode 返回 gRPC code 对应的哨兵错误
+func sentinelForCode(code codes.Code) error {
+	switch code {
+	case codes.NotFound:
+		return ErrNotFound
+	case codes.InvalidArgument:
+		return ErrInvalidInput
+	case codes.Aborted:
+		return ErrConflict
+	case codes.DeadlineExceeded:
+		return ErrTimeout
+	case codes.Unavailable:
+		return ErrUnavailable
+	case codes.Canceled:
+		return context.Canceled
+	default:
+		return nil
+	}
+}
+
+// grpcError 由 gRPC status 还原的错误
+//
+// 实现 GRPCStatus，再次跨 gRPC 边界透传时保留原 status。
+type grpcError struct {
+	status   *status.Status
+	msg      string
+	sentinel error
+}
+
+func (e *grpcError) Error() string {
+	return e.msg
+}
+
+func (e *grpcError) Unwrap() error {
+	return e.sentinel
+}
+
+// GRPCStatus 返回原始 status
+func (e *grpcError) GRPCStatus() *status.Status {
+	return e.status
+}
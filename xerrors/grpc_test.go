@@ -0,0 +1,101 @@
+package xerrors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToGRPCStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"nil", nil, codes.OK},
+		{"not found", Wrap(ErrNotFound, "find user"), codes.NotFound},
+		{"invalid input", ErrInvalidInput, codes.InvalidArgument},
+		{"conflict", ErrConflict, codes.Aborted},
+		{"timeout", ErrTimeout, codes.DeadlineExceeded},
+		{"unavailable", Retryable(ErrUnavailable), codes.Unavailable},
+		{"context deadline", context.DeadlineExceeded, codes.DeadlineExceeded},
+		{"context canceled", context.Canceled, codes.Canceled},
+		{"grpc status", Wrap(status.Error(codes.PermissionDenied, "denied"), "call"), codes.PermissionDenied},
+		{"unknown", errors.New("boom"), codes.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := ToGRPCStatus(tt.err)
+			if st.Code() != tt.want {
+				t.Errorf("ToGRPCStatus(%v).Code() = %v，期望 %v", tt.err, st.Code(), tt.want)
+			}
+			if tt.err != nil && st.Message() != tt.err.Error() {
+				t.Errorf("ToGRPCStatus(%v).Message() = %q，期望 %q", tt.err, st.Message(), tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestGRPCStatusRoundTrip(t *testing.T) {
+	for _, sentinel := range []error{ErrNotFound, ErrInvalidInput, ErrConflict, ErrTimeout, ErrUnavailable, context.Canceled} {
+		original := Wrap(sentinel, "load order")
+		restored := FromGRPCStatus(ToGRPCStatus(original).Err())
+		if !Is(restored, sentinel) {
+			t.Errorf("往返后 Is(%v, %v) = false，期望 true", restored, sentinel)
+		}
+		if restored.Error() != original.Error() {
+			t.Errorf("往返后 Error() = %q，期望 %q", restored.Error(), original.Error())
+		}
+	}
+
+	// 错误码通过 details 传递
+	coded := WithCode(Wrap(ErrNotFound, "user 42"), "USER_NOT_FOUND")
+	st := ToGRPCStatus(coded)
+	if len(st.Details()) != 1 {
+		t.Fatalf("len(Details()) = %d，期望 1", len(st.Details()))
+	}
+	restored := FromGRPCStatus(st.Err())
+	if code := GetCode(restored); code != "USER_NOT_FOUND" {
+		t.Errorf("GetCode(restored) = %q，期望 %q", code, "USER_NOT_FOUND")
+	}
+	if !Is(restored, ErrNotFound) {
+		t.Error("Is(restored, ErrNotFound) = false，期望 true")
+	}
+	if restored.Error() != coded.Error() {
+		t.Errorf("restored.Error() = %q，期望 %q", restored.Error(), coded.Error())
+	}
+
+	// 再次跨边界透传时 code、message 与 details 不变
+	again := ToGRPCStatus(restored)
+	if again.Code() != codes.NotFound || again.Message() != st.Message() || len(again.Details()) != 1 {
+		t.Errorf("透传后 status = %v，期望与 %v 一致", again, st)
+	}
+}
+
+func TestFromGRPCStatus(t *testing.T) {
+	if err := FromGRPCStatus(nil); err != nil {
+		t.Errorf("FromGRPCStatus(nil) = %v，期望 nil", err)
+	}
+	if err := FromGRPCStatus(status.Error(codes.OK, "")); err != nil {
+		t.Errorf("FromGRPCStatus(OK) = %v，期望 nil", err)
+	}
+
+	plain := errors.New("plain")
+	if err := FromGRPCStatus(plain); err != plain {
+		t.Errorf("FromGRPCStatus(plain) = %v，期望原样返回", err)
+	}
+
+	// 未知 code 不匹配任何哨兵，但保留 status
+	err := FromGRPCStatus(status.Error(codes.Internal, "boom"))
+	for _, sentinel := range []error{ErrNotFound, ErrInvalidInput, ErrTimeout, ErrUnavailable} {
+		if Is(err, sentinel) {
+			t.Errorf("Is(Internal, %v) = true，期望 false", sentinel)
+		}
+	}
+	if st, _ := status.FromError(err); st.Code() != codes.Internal || st.Message() != "boom" {
+		t.Errorf("status.FromError(restored) = %v，期望 Internal: boom", st)
+	}
+}
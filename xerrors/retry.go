@@ -0,0 +1,135 @@
+package xerrors
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 通用哨兵错误，带有默认的可重试性：
+// ErrTimeout、ErrUnavailable 默认可重试，ErrInvalidInput、ErrNotFound、ErrConflict 默认不可重试
+// （ErrConflict 需要调用方重新读取最新数据后再决定是否重试）。
+//
+// 组件可以通过 errors.Join 或 Wrap 让自身的错误同时匹配这些哨兵，从而获得默认判定。
+var (
+	ErrTimeout      = errors.New("timeout")
+	ErrUnavailable  = errors.New("unavailable")
+	ErrInvalidInput = errors.New("invalid input")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+)
+
+// retryableError 为错误附加显式的可重试标记。
+type retryableError struct {
+	err       error
+	retryable bool
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// Retryable 将错误标记为可重试，保留原错误链。
+//
+// Retryable(nil) 会返回 nil。
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err, retryable: true}
+}
+
+// NonRetryable 将错误标记为不可重试，可用于覆盖哨兵错误的默认判定。
+//
+// NonRetryable(nil) 会返回 nil。
+func NonRetryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err, retryable: false}
+}
+
+// IsRetryable 沿错误链判断错误是否可重试。
+//
+// 判定顺序：
+//   - 错误链上最外层的 Retryable / NonRetryable 标记优先
+//   - 其次匹配 ErrTimeout、ErrUnavailable、context.DeadlineExceeded，视为可重试
+//   - 其余错误（包括 ErrInvalidInput、ErrNotFound、context.Canceled）均不可重试
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var marked *retryableError
+	if errors.As(err, &marked) {
+		return marked.retryable
+	}
+	return errors.Is(err, ErrTimeout) ||
+		errors.Is(err, ErrUnavailable) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// RetryPolicy 描述 Retry 的重试策略，零值字段使用 DefaultRetryPolicy 中的值。
+type RetryPolicy struct {
+	MaxAttempts    int           // 最大尝试次数（含首次），默认 3
+	InitialBackoff time.Duration // 首次重试前的等待时间，默认 100ms
+	MaxBackoff     time.Duration // 单次等待上限，默认 5s
+	Multiplier     float64       // 退避倍数，默认 2
+}
+
+// DefaultRetryPolicy 默认重试策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+	if p.InitialBackoff <= 0 {
+		p.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+	return p
+}
+
+// Retry 按 policy 执行 fn，仅在 IsRetryable 判定为可重试时进行指数退避重试。
+//
+// 不可重试的错误会立即返回；尝试次数耗尽时返回最后一次的错误。
+// ctx 在等待期间被取消时，返回值同时匹配最后一次错误与 ctx.Err()。
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	policy = policy.withDefaults()
+	backoff := policy.InitialBackoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil || !IsRetryable(err) || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+
+		backoff = time.Duration(float64(backoff) * policy.Multiplier)
+		if backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
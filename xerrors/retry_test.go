@@ -0,0 +1,122 @@
+package xerrors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	base := errors.New("base error")
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"普通错误", base, false},
+		{"Retryable 标记", Retryable(base), true},
+		{"Retryable 标记后再包装", Wrap(Retryable(base), "ctx"), true},
+		{"ErrTimeout", Wrap(ErrTimeout, "query"), true},
+		{"ErrUnavailable", Wrap(ErrUnavailable, "dial"), true},
+		{"context.DeadlineExceeded", Wrap(context.DeadlineExceeded, "call"), true},
+		{"ErrInvalidInput", Wrap(ErrInvalidInput, "parse"), false},
+		{"ErrNotFound", Wrap(ErrNotFound, "lookup"), false},
+		{"ErrConflict", Wrap(ErrConflict, "update"), false},
+		{"context.Canceled", context.Canceled, false},
+		{"NonRetryable 覆盖 ErrTimeout", NonRetryable(ErrTimeout), false},
+		{"Retryable 覆盖 ErrNotFound", Retryable(ErrNotFound), true},
+		{"最外层标记优先", NonRetryable(Retryable(base)), false},
+		{"Join 中的哨兵", Join(base, ErrUnavailable), true},
+	}
+
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable() = %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 标记不应破坏错误链
+	if !errors.Is(Retryable(base), base) {
+		t.Error("errors.Is(Retryable(base), base) = false，期望 true")
+	}
+	if Retryable(base).Error() != base.Error() {
+		t.Errorf("Retryable(base).Error() = %q，期望 %q", Retryable(base).Error(), base.Error())
+	}
+	if Retryable(nil) != nil || NonRetryable(nil) != nil {
+		t.Error("Retryable(nil) / NonRetryable(nil) 应返回 nil")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+	}
+
+	// 可重试错误应重试直到成功
+	calls := 0
+	err := Retry(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return Wrap(ErrUnavailable, "dial")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Retry() = %v，期望 nil", err)
+	}
+	if calls != 3 {
+		t.Errorf("调用次数 = %d，期望 3", calls)
+	}
+
+	// 不可重试错误应立即返回
+	calls = 0
+	err = Retry(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return Wrap(ErrInvalidInput, "parse")
+	})
+	if !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("Retry() = %v，期望 ErrInvalidInput", err)
+	}
+	if calls != 1 {
+		t.Errorf("调用次数 = %d，期望 1", calls)
+	}
+
+	// 尝试次数耗尽时返回最后一次的错误
+	calls = 0
+	err = Retry(context.Background(), policy, func(ctx context.Context) error {
+		calls++
+		return Retryable(errors.New("flaky"))
+	})
+	if err == nil || err.Error() != "flaky" {
+		t.Errorf("Retry() = %v，期望 flaky", err)
+	}
+	if calls != 3 {
+		t.Errorf("调用次数 = %d，期望 3", calls)
+	}
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour}
+
+	calls := 0
+	err := Retry(ctx, policy, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return ErrTimeout
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Retry() = %v，期望包含 context.Canceled", err)
+	}
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Retry() = %v，期望包含 ErrTimeout", err)
+	}
+	if calls != 1 {
+		t.Errorf("调用次数 = %d，期望 1", calls)
+	}
+}
@@ -7,8 +7,10 @@
 //   - 使用 WithCode / GetCode 为错误补充一个轻量的机器可读错误码
 //   - 使用 Collector / Combine 简化多步骤校验和多错误合并
 //   - 使用 Must / MustOK 处理初始化阶段的“失败即 panic”场景
+//   - 使用 Retryable / IsRetryable / Retry 统一“哪些错误可重试”的判定
+//   - 使用 ToGRPCStatus / FromGRPCStatus 在 gRPC 边界两侧转换哨兵错误
 //
-// xerrors 刻意保持克制。它当前不提供 stack trace、错误分类体系、并发安全的错误
+// xerrors 刻意保持克制。它当前不提供 stack trace、完整的错误分类体系、并发安全的错误
 // 聚合器，也不试图替应用统一建模所有协议层错误。对大多数业务代码来说，它更像
 // 是“标准库 errors 的工程补充层”，而不是“另一套错误系统”。
 package xerrors